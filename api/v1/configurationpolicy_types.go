@@ -6,6 +6,7 @@ package v1
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -42,6 +43,11 @@ const (
 	Inform RemediationAction = "Inform"
 )
 
+// IsPruning returns true if the behavior deletes objects when the policy is deleted.
+func (b PruneObjectBehavior) IsPruning() bool {
+	return b == "DeleteAll" || b == "DeleteIfCreated"
+}
+
 func (ra RemediationAction) IsInform() bool {
 	return strings.EqualFold(string(ra), string(Inform))
 }
@@ -65,6 +71,9 @@ const (
 
 	// Terminating is a ComplianceState
 	Terminating ComplianceState = "Terminating"
+
+	// Pending is a ComplianceState for a policy waiting on its dependencies
+	Pending ComplianceState = "Pending"
 )
 
 // Condition is the base struct for representing resource conditions
@@ -117,13 +126,15 @@ func (t Target) String() string {
 // spec is changed, or if the list of namespaces selected by the policy changes, the policy may be
 // evaluated regardless of the settings here.
 type EvaluationInterval struct {
-	//+kubebuilder:validation:Pattern=`^(?:(?:(?:[0-9]+(?:.[0-9])?)(?:h|m|s|(?:ms)|(?:us)|(?:ns)))|never)+$`
-	// The minimum elapsed time before a ConfigurationPolicy is reevaluated when in the compliant state. Set this to
-	// "never" to disable reevaluation when in the compliant state.
+	//+kubebuilder:validation:Pattern=`^(?:(?:(?:(?:[0-9]+(?:.[0-9])?)(?:h|m|s|(?:ms)|(?:us)|(?:ns)))|never)+|(?:(?:CRON_TZ|TZ)=\S+ +)?(?:@[a-z]+|\S+(?: +\S+){4}))$`
+	// The minimum elapsed time before a ConfigurationPolicy is reevaluated when in the compliant state, or a cron
+	// expression (e.g. "0 2 * * *") for when it's reevaluated. The cron expression is in UTC unless it's prefixed with
+	// "CRON_TZ=<time zone> ". Set this to "never" to disable reevaluation when in the compliant state.
 	Compliant string `json:"compliant,omitempty"`
-	//+kubebuilder:validation:Pattern=`^(?:(?:(?:[0-9]+(?:.[0-9])?)(?:h|m|s|(?:ms)|(?:us)|(?:ns)))|never)+$`
-	// The minimum elapsed time before a ConfigurationPolicy is reevaluated when in the noncompliant state. Set this to
-	// "never" to disable reevaluation when in the noncompliant state.
+	//+kubebuilder:validation:Pattern=`^(?:(?:(?:(?:[0-9]+(?:.[0-9])?)(?:h|m|s|(?:ms)|(?:us)|(?:ns)))|never)+|(?:(?:CRON_TZ|TZ)=\S+ +)?(?:@[a-z]+|\S+(?: +\S+){4}))$`
+	// The minimum elapsed time before a ConfigurationPolicy is reevaluated when in the noncompliant state, or a cron
+	// expression (e.g. "0 2 * * *") for when it's reevaluated. The cron expression is in UTC unless it's prefixed with
+	// "CRON_TZ=<time zone> ". Set this to "never" to disable reevaluation when in the noncompliant state.
 	NonCompliant string `json:"noncompliant,omitempty"`
 }
 
@@ -160,6 +171,43 @@ func (e EvaluationInterval) GetNonCompliantInterval() (time.Duration, error) {
 	return e.parseInterval(e.NonCompliant)
 }
 
+// nextEvaluation returns the time of the next evaluation based on the input interval, which is either a duration or a
+// cron expression, and the time of the last evaluation. ErrIsNever is returned when the string is set to "never".
+func (e EvaluationInterval) nextEvaluation(interval string, lastEvaluated time.Time) (time.Time, error) {
+	if !isCron(interval) {
+		parsedInterval, err := e.parseInterval(interval)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return lastEvaluated.Add(parsedInterval), nil
+	}
+
+	schedule, err := parseCron(interval)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := schedule.next(lastEvaluated)
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("the cron expression %s never matches", interval)
+	}
+
+	return next, nil
+}
+
+// GetNextCompliantEvaluation returns when a ConfigurationPolicy last evaluated at the input time is reevaluated when in
+// the compliant state. ErrIsNever is returned when the Compliant interval is set to "never".
+func (e EvaluationInterval) GetNextCompliantEvaluation(lastEvaluated time.Time) (time.Time, error) {
+	return e.nextEvaluation(e.Compliant, lastEvaluated)
+}
+
+// GetNextNonCompliantEvaluation returns when a ConfigurationPolicy last evaluated at the input time is reevaluated when
+// in the noncompliant state. ErrIsNever is returned when the NonCompliant interval is set to "never".
+func (e EvaluationInterval) GetNextNonCompliantEvaluation(lastEvaluated time.Time) (time.Time, error) {
+	return e.nextEvaluation(e.NonCompliant, lastEvaluated)
+}
+
 // ConfigurationPolicySpec defines the desired state of ConfigurationPolicy
 type ConfigurationPolicySpec struct {
 	Severity          Severity          `json:"severity,omitempty"` // low, medium, high
@@ -168,23 +216,235 @@ type ConfigurationPolicySpec struct {
 	// spec.objectTemplates. All selector rules are ANDed. If 'include' is not provided but
 	// 'matchLabels' and/or 'matchExpressions' are, 'include' will behave as if ['*'] were given. If
 	// 'matchExpressions' and 'matchLabels' are both not provided, 'include' must be provided to
-	// retrieve namespaces.
+	// retrieve namespaces. Managed cluster templates may be used in the values, in which case an
+	// 'include', 'exclude', or 'matchExpressions' value that resolves to a comma separated list is
+	// split into multiple entries. The ConfigMaps referenced by 'fromConfigMap' with literal
+	// namespace and name arguments are watched, and the templates are resolved again when they
+	// change. Templates that use other functions are resolved again at each evaluation loop of the
+	// controller. The policy is evaluated when the result changes.
 	NamespaceSelector Target `json:"namespaceSelector,omitempty"`
 	// 'object-templates' and 'object-templates-raw' are arrays of objects for the configuration
 	// policy to check, create, modify, or delete on the cluster. 'object-templates' is an array
 	// of objects, while 'object-templates-raw' is a string containing an array of objects in
 	// YAML format. Only one of the two object-templates variables can be set in a given
-	// configurationPolicy.
+	// configurationPolicy. The templates in them can refer to '.PolicyName' and '.PolicyNamespace', and
+	// the ones that refer to '.ObjectNamespace' are rendered once per namespace selected by the
+	// namespaceSelector, which must be set. Those object templates are skipped when no namespaces
+	// are selected.
 	ObjectTemplates []*ObjectTemplate `json:"object-templates,omitempty"`
 	// 'object-templates' and 'object-templates-raw' are arrays of objects for the configuration
 	// policy to check, create, modify, or delete on the cluster. 'object-templates' is an array
 	// of objects, while 'object-templates-raw' is a string containing an array of objects in
 	// YAML format. Only one of the two object-templates variables can be set in a given
-	// configurationPolicy.
-	ObjectTemplatesRaw string             `json:"object-templates-raw,omitempty"`
-	EvaluationInterval EvaluationInterval `json:"evaluationInterval,omitempty"`
+	// configurationPolicy. The templates in them can refer to '.PolicyName' and '.PolicyNamespace', and
+	// the ones that refer to '.ObjectNamespace' are rendered once per namespace selected by the
+	// namespaceSelector, which must be set. Those object templates are skipped when no namespaces
+	// are selected.
+	ObjectTemplatesRaw string `json:"object-templates-raw,omitempty"`
+	// 'objectTemplatesRawRef' references a key in a ConfigMap or Secret on the managed cluster that
+	// contains the object templates in the same format as 'object-templates-raw'. This can't be set
+	// with 'object-templates' or 'object-templates-raw'. The referenced object must be in the
+	// namespace of the policy or in a namespace allowed by the controller's
+	// '--raw-ref-allowed-namespaces' flag. The policy is evaluated again when the object changes.
+	ObjectTemplatesRawRef *ObjectTemplatesRawRef `json:"objectTemplatesRawRef,omitempty"`
+	EvaluationInterval    EvaluationInterval     `json:"evaluationInterval,omitempty"`
 	// +kubebuilder:default:=None
 	PruneObjectBehavior PruneObjectBehavior `json:"pruneObjectBehavior,omitempty"`
+	// 'customMessage' configures the status messages and compliance events of the object templates.
+	CustomMessage CustomMessage `json:"customMessage,omitempty"`
+	// 'enforcementMethod' specifies how objects are updated when enforcing. 'Update' (the default)
+	// merges the objectDefinition into the object and updates it. 'ServerSideApply' sends a
+	// server-side apply request with the controller's field manager, and the compliance only
+	// considers the fields set in the objectDefinition so that fields owned by other field managers
+	// are not reported as mismatches. The 'mustonlyhave' complianceType isn't supported with
+	// 'ServerSideApply' since an apply request can't remove the fields missing from the
+	// objectDefinition.
+	EnforcementMethod EnforcementMethod `json:"enforcementMethod,omitempty"`
+	// 'forceConflicts' determines whether the controller takes ownership of fields owned by other
+	// field managers when enforcing with the 'ServerSideApply' enforcement method. When false, these
+	// conflicts are reported as noncompliant.
+	ForceConflicts bool `json:"forceConflicts,omitempty"`
+	// 'orderedEvaluation' evaluates the object templates in the order they are declared. When an
+	// object template is not compliant, the object templates after it are skipped until it becomes
+	// compliant.
+	OrderedEvaluation bool `json:"orderedEvaluation,omitempty"`
+	// 'dependencies' is a list of policies on the managed cluster that must have the specified compliance
+	// state before this policy is evaluated. While a dependency isn't satisfied, the policy has the Pending
+	// compliance state and nothing is enforced.
+	Dependencies []PolicyDependency `json:"dependencies,omitempty"`
+	// 'enforcementSchedule' restricts the enforcement of the policy to recurring windows. Outside of the
+	// windows, an enforce policy is evaluated as if it was inform and the status indicates when
+	// enforcement resumes.
+	EnforcementSchedule *EnforcementSchedule `json:"enforcementSchedule,omitempty"`
+	// 'objectEvents' emits an additional event on the policy for every related object whose compliance
+	// changed, with the object identified in the event annotations. The number of these events per
+	// evaluation is limited and the remaining changes are summarized in a single event.
+	ObjectEvents bool `json:"objectEvents,omitempty"`
+	// 'noncomplianceGracePeriod' is how long the policy must remain noncompliant, such as "5m", before
+	// its noncompliance is reported. Until then, the reported compliance, the compliancy details, and
+	// the events are unchanged and the pending noncompliance is shown in the status. Enforcement isn't
+	// delayed.
+	//+kubebuilder:validation:Pattern=`^(?:(?:[0-9]+(?:.[0-9])?)(?:h|m|s))+$`
+	NoncomplianceGracePeriod string `json:"noncomplianceGracePeriod,omitempty"`
+}
+
+// EnforcementSchedule is a set of recurring windows in which the policy is enforced.
+type EnforcementSchedule struct {
+	// The IANA time zone of the windows, such as "America/New_York". This defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// The recurring windows in which the policy is enforced
+	// +kubebuilder:validation:MinItems=1
+	Windows []EnforcementWindow `json:"windows"`
+}
+
+// EnforcementWindow is a window of time that recurs on the specified days of the week.
+type EnforcementWindow struct {
+	// The days of the week the window starts on. This defaults to every day.
+	Days []Weekday `json:"days,omitempty"`
+	// The time of day the window starts in the 24-hour "HH:MM" format
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// The length of the window, such as "2h" or "48h". It can't be longer than a week.
+	//+kubebuilder:validation:Pattern=`^(?:(?:[0-9]+(?:.[0-9])?)(?:h|m|s))+$`
+	Duration string `json:"duration"`
+}
+
+// Weekday is a day of the week.
+// +kubebuilder:validation:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+type Weekday string
+
+// maxWindowDuration is the longest allowed enforcement window
+const maxWindowDuration = 7 * 24 * time.Hour
+
+// InWindow determines if the input time is within one of the windows of the schedule. The returned time is when this
+// changes, which is the end of the window when in a window, and otherwise, the start of the next window.
+func (s *EnforcementSchedule) InWindow(now time.Time) (inWindow bool, nextChange time.Time, err error) {
+	loc := time.UTC
+
+	if s.TimeZone != "" {
+		loc, err = time.LoadLocation(s.TimeZone)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid timeZone %s: %w", s.TimeZone, err)
+		}
+	}
+
+	weekdays := map[Weekday]time.Weekday{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays[Weekday(day.String())] = day
+	}
+
+	localNow := now.In(loc)
+
+	var activeEnd, nextStart time.Time
+
+	for i, window := range s.Windows {
+		start, err := time.Parse("15:04", window.Start)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("windows[%d] has an invalid start %s", i, window.Start)
+		}
+
+		duration, err := time.ParseDuration(window.Duration)
+		if err != nil || duration <= 0 || duration > maxWindowDuration {
+			return false, time.Time{}, fmt.Errorf(
+				"windows[%d] has an invalid duration %s, it must be a positive duration of at most a week",
+				i, window.Duration,
+			)
+		}
+
+		days := map[time.Weekday]bool{}
+
+		for _, day := range window.Days {
+			weekday, ok := weekdays[day]
+			if !ok {
+				return false, time.Time{}, fmt.Errorf("windows[%d] has an invalid day %s", i, day)
+			}
+
+			days[weekday] = true
+		}
+
+		// A window that started up to a week ago can still be active
+		for offset := -7; offset <= 7; offset++ {
+			windowStart := time.Date(
+				localNow.Year(), localNow.Month(), localNow.Day()+offset, start.Hour(), start.Minute(), 0, 0, loc,
+			)
+
+			if len(days) != 0 && !days[windowStart.Weekday()] {
+				continue
+			}
+
+			windowEnd := windowStart.Add(duration)
+
+			if windowStart.After(localNow) {
+				if nextStart.IsZero() || windowStart.Before(nextStart) {
+					nextStart = windowStart
+				}
+			} else if localNow.Before(windowEnd) && windowEnd.After(activeEnd) {
+				activeEnd = windowEnd
+			}
+		}
+	}
+
+	if !activeEnd.IsZero() {
+		return true, activeEnd, nil
+	}
+
+	return false, nextStart, nil
+}
+
+// PolicyDependency is a policy on the managed cluster that must have the specified compliance state before the
+// ConfigurationPolicy is evaluated.
+type PolicyDependency struct {
+	// The kind of the policy
+	// +kubebuilder:validation:Enum=ConfigurationPolicy;OperatorPolicy
+	// +kubebuilder:default:=ConfigurationPolicy
+	Kind string `json:"kind,omitempty"`
+	// The name of the policy
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The namespace of the policy. This defaults to the namespace of the ConfigurationPolicy.
+	Namespace string `json:"namespace,omitempty"`
+	// The required compliance state of the policy
+	// +kubebuilder:validation:Enum=Compliant;NonCompliant
+	// +kubebuilder:default:=Compliant
+	Compliance ComplianceState `json:"compliance,omitempty"`
+}
+
+// ObjectTemplatesRawRef references a key in a ConfigMap or Secret containing object templates in YAML format.
+type ObjectTemplatesRawRef struct {
+	// The kind of the referenced object
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+	// The name of the referenced object
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The namespace of the referenced object
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// The key in the data of the referenced object that contains the object templates
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// EnforcementMethod specifies how objects are updated when enforcing.
+// +kubebuilder:validation:Enum=Update;ServerSideApply
+type EnforcementMethod string
+
+const (
+	EnforcementMethodUpdate          EnforcementMethod = "Update"
+	EnforcementMethodServerSideApply EnforcementMethod = "ServerSideApply"
+)
+
+// CustomMessage configures Go templates used to customize the message of each object template in the
+// status and in the compliance events. The templates have access to '.DefaultMessage' (the message
+// generated by the controller), '.Objects' (a list with the 'Name' and 'Namespace' of the evaluated
+// objects), and '.Namespaces' (the namespaces the object template was evaluated in). To append to the
+// generated message, include '{{ .DefaultMessage }}' in the template. If a template fails to render, the
+// generated message is used with a warning.
+type CustomMessage struct {
+	// The template to use for the message when the object template is compliant.
+	Compliant string `json:"compliant,omitempty"`
+	// The template to use for the message when the object template is noncompliant.
+	NonCompliant string `json:"noncompliant,omitempty"`
 }
 
 // ObjectTemplate describes how an object should look
@@ -194,33 +454,267 @@ type ObjectTemplate struct {
 
 	MetadataComplianceType MetadataComplianceType `json:"metadataComplianceType,omitempty"`
 
-	// ObjectDefinition defines required fields for the object
+	// LabelsComplianceType overrides the metadataComplianceType for the labels of the object, so that the
+	// labels and annotations can be compared differently. Unset values default to the metadataComplianceType.
+	LabelsComplianceType MetadataComplianceType `json:"labelsComplianceType,omitempty"`
+
+	// AnnotationsComplianceType overrides the metadataComplianceType for the annotations of the object,
+	// such as to tolerate the annotations added by other controllers while the labels are mustonlyhave.
+	// Unset values default to the metadataComplianceType.
+	AnnotationsComplianceType MetadataComplianceType `json:"annotationsComplianceType,omitempty"`
+
+	// ObjectDefinition defines required fields for the object. The status fields are only compared, so they can't be
+	// set when the object is enforced with the musthave or mustonlyhave complianceType. A metadata.namespace of '*'
+	// evaluates a namespaced object in every namespace on the cluster, which is only supported in inform policies
+	// unless the complianceType is mustnothave. A '$patch: delete' directive, as in a strategic merge patch,
+	// requires a field to be absent: a map value of '{"$patch": "delete"}' removes the key, and a list item with
+	// '"$patch": "delete"' removes the items that have its other fields. The directive isn't supported when the
+	// enforcementMethod is ServerSideApply.
 	// +kubebuilder:pruning:PreserveUnknownFields
 	ObjectDefinition runtime.RawExtension `json:"objectDefinition"`
 
+	// KindOnly ignores the apiVersion of the objectDefinition and resolves the kind to the preferred group and
+	// version on the cluster, which is also done when the apiVersion is omitted. The kind must only be served by
+	// one API group.
+	KindOnly bool `json:"kindOnly,omitempty"`
+
 	// RecordDiff specifies whether (and where) to log the diff between the object on the
-	// cluster and the objectDefinition in the policy. Defaults to "None".
+	// cluster and the objectDefinition in the policy. Defaults to "None". "Censored" never generates the diff, not
+	// even for the status of a policy in preview mode, which is useful for large or sensitive objects. The diff of an
+	// object is also never generated when it has the policy.open-cluster-management.io/disable-diff annotation set to
+	// "true". When an enforced mustnothave object template deletes an object, a deletion record with its kind, name,
+	// namespace, and labels is logged, and "Log" adds the YAML of the object, with the Secret values redacted.
 	RecordDiff RecordDiff `json:"recordDiff,omitempty"`
+
+	// DiffFormat is the format of the recorded diff, which is either "Unified" for a unified diff of the YAML of the
+	// objects or "JSONPatch" for an RFC 6902 JSON Patch that transforms the object on the cluster into the
+	// objectDefinition. Defaults to "Unified".
+	DiffFormat DiffFormat `json:"diffFormat,omitempty"`
+
+	// EvaluationInterval overrides the policy's spec.evaluationInterval for this object template. Unset
+	// values default to the policy's values. When set on any object template, each object template is only
+	// reevaluated when its own interval has elapsed. This can't be set in 'object-templates-raw' or
+	// 'objectTemplatesRawRef' since the intervals are checked before the templates are resolved.
+	EvaluationInterval EvaluationInterval `json:"evaluationInterval,omitempty"`
+
+	// IgnoreFields is a list of JSON pointer paths (e.g. '/spec/replicas' or '/metadata/annotations/foo')
+	// that are excluded from the comparison with the objectDefinition, are left untouched when enforcing,
+	// and are omitted from the diff. Paths can only traverse maps, and the '~1' and '~0' escape sequences
+	// represent '/' and '~' in a key.
+	IgnoreFields []string `json:"ignoreFields,omitempty"`
+
+	// UnorderedLists is a list of JSON pointer paths (e.g. '/spec/template/spec/tolerations') to lists that
+	// are compared as sets. The order of the items doesn't matter and duplicate items count as a single item.
+	// When enforcing, the order of the list on the cluster is kept, so reordering it is never reported as a
+	// change. A '*' key matches every item of a list (e.g. '/spec/template/spec/containers/*/env'). This
+	// doesn't apply when the enforcementMethod is ServerSideApply.
+	UnorderedLists []string `json:"unorderedLists,omitempty"`
+
+	// ListMergeKeys maps JSON pointer paths (e.g. '/spec/template/spec/containers') of lists to the field
+	// that identifies their items (e.g. 'name'). The items in the objectDefinition are paired with the items
+	// on the cluster that have the same value for the field rather than by their position, and the order of
+	// the list on the cluster is kept. An empty field identifies the items by their full content. Items on
+	// the cluster that aren't in the objectDefinition are kept with musthave and removed with mustonlyhave.
+	// Paths can only traverse maps. This doesn't apply when the enforcementMethod is ServerSideApply.
+	ListMergeKeys map[string]string `json:"listMergeKeys,omitempty"`
+
+	// ObjectChecks are expectations on fields of the object, such as a regular expression that a value must
+	// match, that are evaluated in addition to the comparison with the objectDefinition. They can only be
+	// used with musthave and mustonlyhave object templates in inform policies since there is no value to
+	// enforce.
+	ObjectChecks []ObjectCheck `json:"objectChecks,omitempty"`
+
+	// DeleteOptions configures the delete requests when enforcing a mustnothave object template.
+	DeleteOptions DeleteOptions `json:"deleteOptions,omitempty"`
+
+	// MinimumObjects is the minimum number of objects that must match the object template for it to be
+	// compliant. This can only be set on object templates without a name in inform policies.
+	// +kubebuilder:validation:Minimum=0
+	MinimumObjects *int32 `json:"minimumObjects,omitempty"`
+
+	// MaximumObjects is the maximum number of objects that can match the object template for it to be
+	// compliant. This can only be set on object templates without a name in inform policies.
+	// +kubebuilder:validation:Minimum=0
+	MaximumObjects *int32 `json:"maximumObjects,omitempty"`
+
+	// ObjectSelector restricts an object template without a name to the objects matching the label
+	// selector. With the mustnothave compliance type, every matching object is noncompliant and is
+	// deleted when enforcing.
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+
+	// CheckExistenceOnly only checks whether the object exists and never compares its contents with the
+	// objectDefinition. When enforcing a musthave object template, the object is created from the
+	// objectDefinition if it's missing but is never updated. This can't be set with the mustonlyhave
+	// compliance type.
+	CheckExistenceOnly bool `json:"checkExistenceOnly,omitempty"`
+
+	// PruneObjectBehavior overrides the policy's spec.pruneObjectBehavior for the objects of this object
+	// template. When set to DeleteAll or DeleteIfCreated, the policy's remediationAction must be enforce.
+	PruneObjectBehavior PruneObjectBehavior `json:"pruneObjectBehavior,omitempty"`
+
+	// RecreateOption determines whether the object is deleted and recreated when enforcing changes to it.
+	// When set to 'IfRequired', the object is recreated only when the update fails because of immutable
+	// fields. When set to 'Always', the object is recreated instead of updated. The object is deleted with
+	// the deleteOptions of the object template. Defaults to 'None', in which case the object is never
+	// recreated.
+	RecreateOption RecreateOption `json:"recreateOption,omitempty"`
+
+	// TolerateFieldManagers is a list of field managers (e.g. 'horizontal-pod-autoscaler') whose fields are
+	// never enforced. When a mismatched field is owned by one of them based on the managedFields of the
+	// object, the field is left as is and is reported as tolerated drift instead of being noncompliant. This
+	// doesn't apply when the enforcementMethod is ServerSideApply.
+	TolerateFieldManagers []string `json:"tolerateFieldManagers,omitempty"`
+
+	// TolerateExternalFields is a list of JSON pointer paths (e.g. '/metadata/annotations/sidecar.istio.io~1*')
+	// to fields added by other controllers, such as admission webhooks, that aren't considered extra fields by
+	// the mustonlyhave complianceType and are never removed when enforcing. Fields set in the objectDefinition
+	// are still compared. A '*' in a key matches any characters, and the '~1' and '~0' escape sequences
+	// represent '/' and '~' in a key. Paths can only traverse maps, so the object is noncompliant when a path
+	// reaches a list, such as '/spec/containers'.
+	TolerateExternalFields []string `json:"tolerateExternalFields,omitempty"`
+}
+
+// GetPruneObjectBehavior returns the pruneObjectBehavior of the object template, which defaults to the input
+// pruneObjectBehavior of the policy.
+func (t *ObjectTemplate) GetPruneObjectBehavior(policyBehavior PruneObjectBehavior) PruneObjectBehavior {
+	if t.PruneObjectBehavior != "" {
+		return t.PruneObjectBehavior
+	}
+
+	return policyBehavior
+}
+
+// DeleteOptions configures how objects are deleted when enforcing a mustnothave object template.
+type DeleteOptions struct {
+	// PropagationPolicy determines how the dependents of the object are deleted. When set to
+	// 'Foreground', the object template stays noncompliant until the object and its dependents are
+	// deleted. When unset, the default propagation policy of the object's kind is used.
+	// +kubebuilder:validation:Enum=Foreground;Background;Orphan
+	PropagationPolicy *metav1.DeletionPropagation `json:"propagationPolicy,omitempty"`
+}
+
+// ObjectCheck is an expectation on a field of the object. Every expectation that is set must be met.
+type ObjectCheck struct {
+	// Path is a JSON pointer path (e.g. '/spec/template/spec/containers/*/image') to the field. A '*' key
+	// matches every item of a list, in which case every item must meet the expectations.
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// Regex is a regular expression that the string value of the field must fully match.
+	Regex string `json:"regex,omitempty"`
+
+	// Minimum is the lowest allowed numeric value of the field.
+	Minimum *int64 `json:"minimum,omitempty"`
+
+	// Maximum is the highest allowed numeric value of the field.
+	Maximum *int64 `json:"maximum,omitempty"`
+
+	// Exists determines whether the field must be set. When unset, the field must be set for the other
+	// expectations to be met.
+	Exists *bool `json:"exists,omitempty"`
+}
+
+// Validate verifies that the object check has a valid path and regular expression, and has at least one
+// expectation.
+func (c ObjectCheck) Validate() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("the objectChecks path %s must start with /", c.Path)
+	}
+
+	if c.Regex == "" && c.Minimum == nil && c.Maximum == nil && c.Exists == nil {
+		return fmt.Errorf("the objectChecks entry for %s must set regex, minimum, maximum, or exists", c.Path)
+	}
+
+	if c.Exists != nil && !*c.Exists && (c.Regex != "" || c.Minimum != nil || c.Maximum != nil) {
+		return fmt.Errorf(
+			"the objectChecks entry for %s can't set regex, minimum, or maximum when exists is false", c.Path,
+		)
+	}
+
+	if c.Minimum != nil && c.Maximum != nil && *c.Minimum > *c.Maximum {
+		return fmt.Errorf("the objectChecks entry for %s has a minimum greater than the maximum", c.Path)
+	}
+
+	if c.Regex != "" {
+		if _, err := regexp.Compile(c.Regex); err != nil {
+			return fmt.Errorf("the objectChecks entry for %s has an invalid regex: %w", c.Path, err)
+		}
+	}
+
+	return nil
 }
 
-// +kubebuilder:validation:Enum=Log;None
+// +kubebuilder:validation:Enum=None;IfRequired;Always
+type RecreateOption string
+
+const (
+	RecreateNone       RecreateOption = "None"
+	RecreateIfRequired RecreateOption = "IfRequired"
+	RecreateAlways     RecreateOption = "Always"
+)
+
+// +kubebuilder:validation:Enum=Log;None;Censored
 type RecordDiff string
 
 const (
-	RecordDiffLog  RecordDiff = "Log"
-	RecordDiffNone RecordDiff = "None"
+	RecordDiffLog      RecordDiff = "Log"
+	RecordDiffNone     RecordDiff = "None"
+	RecordDiffCensored RecordDiff = "Censored"
+)
+
+// +kubebuilder:validation:Enum=Unified;JSONPatch
+type DiffFormat string
+
+const (
+	DiffFormatUnified   DiffFormat = "Unified"
+	DiffFormatJSONPatch DiffFormat = "JSONPatch"
 )
 
 // ConfigurationPolicyStatus defines the observed state of ConfigurationPolicy
 type ConfigurationPolicyStatus struct {
 	ComplianceState   ComplianceState  `json:"compliant,omitempty"`         // Compliant/NonCompliant/UnknownCompliancy
 	CompliancyDetails []TemplateStatus `json:"compliancyDetails,omitempty"` // reason for non-compliancy
+	// Standard conditions of the policy. The Compliant condition reflects the compliance state of the
+	// policy, so that standard tooling such as 'kubectl wait --for=condition=Compliant' can consume it.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 	// An ISO-8601 timestamp of the last time the policy was evaluated
 	LastEvaluated string `json:"lastEvaluated,omitempty"`
 	// The generation of the ConfigurationPolicy object when it was last evaluated
 	LastEvaluatedGeneration int64 `json:"lastEvaluatedGeneration,omitempty"`
+	// The value of the policy.open-cluster-management.io/trigger-evaluation annotation when the policy was last
+	// evaluated, which is used to only evaluate the policy once for each value of the annotation
+	LastTriggeredEvaluation string `json:"lastTriggeredEvaluation,omitempty"`
+	// The noncompliance that isn't reported yet due to the noncomplianceGracePeriod
+	PendingNoncompliance *PendingNoncompliance `json:"pendingNoncompliance,omitempty"`
 	// List of resources processed by the policy
 	RelatedObjects []RelatedObject `json:"relatedObjects,omitempty"`
+	// Debug output of the object templates rendered in the last evaluation, which is only set when the
+	// policy.open-cluster-management.io/debug-rendered-templates annotation is set to "Status". The Secret values are
+	// redacted and the output is truncated to 16 KiB. It isn't recorded when the policy resolves templates, since the
+	// rendered values can come from Secrets.
+	RenderedObjectTemplates string `json:"renderedObjectTemplates,omitempty"`
+}
+
+// GetCondition returns the index and the condition of the input type in the status. The index is -1 if no condition of
+// the type is found.
+func (status ConfigurationPolicyStatus) GetCondition(condType string) (int, metav1.Condition) {
+	for i, cond := range status.Conditions {
+		if cond.Type == condType {
+			return i, cond
+		}
+	}
+
+	return -1, metav1.Condition{}
+}
+
+// PendingNoncompliance is a noncompliance that is reported once it persists past the noncomplianceGracePeriod.
+type PendingNoncompliance struct {
+	// An ISO-8601 timestamp of when the policy was first evaluated as noncompliant
+	Since string `json:"since"`
+	// An ISO-8601 timestamp of when the noncompliance is reported if it persists
+	EffectiveAt string `json:"effectiveAt"`
 }
 
 // CompliancePerClusterStatus contains aggregate status of other policies in cluster
@@ -264,6 +758,24 @@ type TemplateStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 
 	Validity Validity `json:"Validity,omitempty"` // a template can be invalid if it has conflicting roles
+
+	// An ISO-8601 timestamp of the last time the object template was evaluated. This is only set when an
+	// object template in the policy sets its own evaluationInterval.
+	LastEvaluated string `json:"lastEvaluated,omitempty"`
+
+	// Summary counts the objects evaluated by the object template, based on the related objects of its
+	// last evaluation.
+	Summary *TemplateSummary `json:"summary,omitempty"`
+}
+
+// TemplateSummary counts the objects evaluated by an object template by their compliance.
+type TemplateSummary struct {
+	// The number of related objects of the object template
+	Matched int `json:"matched"`
+	// The number of compliant related objects of the object template
+	Compliant int `json:"compliant"`
+	// The number of noncompliant related objects of the object template
+	NonCompliant int `json:"noncompliant"`
 }
 
 // Validity describes if it is valid or not
@@ -356,8 +868,28 @@ type ObjectProperties struct {
 	CreatedByPolicy *bool `json:"createdByPolicy,omitempty"`
 	// Store object UID to help track object ownership for deletion
 	UID string `json:"uid,omitempty"`
+	// The resourceVersion of the object when it was last evaluated
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// The action that would be taken on the object if the policy was not in preview mode
+	PreviewAction PreviewAction `json:"previewAction,omitempty"`
+	// The diff of the update that would be made to the object when the policy is in preview mode
+	Diff string `json:"diff,omitempty"`
+	// The pruneObjectBehavior of the object template when it overrides the policy's pruneObjectBehavior
+	PruneObjectBehavior PruneObjectBehavior `json:"pruneObjectBehavior,omitempty"`
+	// The timestamp (RFC3339) of the last evaluation of the object
+	LastEvaluated string `json:"lastEvaluated,omitempty"`
 }
 
+// PreviewAction is the action that a ConfigurationPolicy in preview mode would take on an object.
+// +kubebuilder:validation:Enum=would create;would update;would delete
+type PreviewAction string
+
+const (
+	PreviewActionCreate PreviewAction = "would create"
+	PreviewActionUpdate PreviewAction = "would update"
+	PreviewActionDelete PreviewAction = "would delete"
+)
+
 func init() {
 	SchemeBuilder.Register(&ConfigurationPolicy{}, &ConfigurationPolicyList{})
 }
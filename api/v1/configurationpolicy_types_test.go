@@ -0,0 +1,190 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnforcementScheduleInWindow(t *testing.T) {
+	t.Parallel()
+
+	weekend := &EnforcementSchedule{
+		TimeZone: "America/New_York",
+		Windows:  []EnforcementWindow{{Days: []Weekday{"Saturday"}, Start: "00:00", Duration: "48h"}},
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	// October 16, 2026 is a Friday
+	friday := time.Date(2026, 10, 16, 12, 0, 0, 0, newYork)
+	saturdayStart := time.Date(2026, 10, 17, 0, 0, 0, 0, newYork)
+	mondayStart := time.Date(2026, 10, 19, 0, 0, 0, 0, newYork)
+
+	tests := map[string]struct {
+		schedule       *EnforcementSchedule
+		now            time.Time
+		wantInWindow   bool
+		wantNextChange time.Time
+		wantErr        string
+	}{
+		"before the window": {
+			schedule:       weekend,
+			now:            friday,
+			wantInWindow:   false,
+			wantNextChange: saturdayStart,
+		},
+		"at the start of the window": {
+			schedule:       weekend,
+			now:            saturdayStart,
+			wantInWindow:   true,
+			wantNextChange: mondayStart,
+		},
+		"in the window spanning days": {
+			schedule:       weekend,
+			now:            time.Date(2026, 10, 18, 23, 59, 0, 0, newYork),
+			wantInWindow:   true,
+			wantNextChange: mondayStart,
+		},
+		"at the end of the window": {
+			schedule:       weekend,
+			now:            mondayStart,
+			wantInWindow:   false,
+			wantNextChange: saturdayStart.AddDate(0, 0, 7),
+		},
+		"daily window in UTC": {
+			schedule: &EnforcementSchedule{
+				Windows: []EnforcementWindow{{Start: "02:00", Duration: "1h30m"}},
+			},
+			now:            time.Date(2026, 10, 16, 3, 0, 0, 0, time.UTC),
+			wantInWindow:   true,
+			wantNextChange: time.Date(2026, 10, 16, 3, 30, 0, 0, time.UTC),
+		},
+		"invalid day": {
+			schedule: &EnforcementSchedule{
+				Windows: []EnforcementWindow{{Days: []Weekday{"Funday"}, Start: "02:00", Duration: "1h"}},
+			},
+			now:     friday,
+			wantErr: "windows[0] has an invalid day Funday",
+		},
+		"duration longer than a week": {
+			schedule: &EnforcementSchedule{
+				Windows: []EnforcementWindow{{Start: "02:00", Duration: "169h"}},
+			},
+			now:     friday,
+			wantErr: "windows[0] has an invalid duration 169h",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			inWindow, nextChange, err := test.schedule.InWindow(test.now)
+			if test.wantErr != "" {
+				assert.ErrorContains(t, err, test.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantInWindow, inWindow)
+			assert.True(t, test.wantNextChange.Equal(nextChange), "got next change %s", nextChange)
+		})
+	}
+}
+
+func TestEvaluationIntervalCron(t *testing.T) {
+	t.Parallel()
+
+	// October 16, 2026 is a Friday
+	friday := time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		interval string
+		want     time.Time
+		wantErr  string
+	}{
+		"nightly": {
+			interval: "0 2 * * *",
+			want:     time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+		},
+		"every 15 minutes": {
+			interval: "*/15 * * * *",
+			want:     time.Date(2026, 10, 16, 12, 45, 0, 0, time.UTC),
+		},
+		"weekdays with names": {
+			interval: "30 9 * * MON-FRI",
+			want:     time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC),
+		},
+		"Sunday as 7": {
+			interval: "0 0 * * 7",
+			want:     time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		},
+		"day of month or day of week": {
+			interval: "0 0 1 * SAT",
+			want:     time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		},
+		"macro": {
+			interval: "@monthly",
+			want:     time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"time zone": {
+			interval: "CRON_TZ=America/New_York 0 2 * * *",
+			want:     time.Date(2026, 10, 17, 2, 0, 0, 0, newYork),
+		},
+		"duration": {
+			interval: "10m",
+			want:     friday.Add(10 * time.Minute),
+		},
+		"too few fields": {
+			interval: "0 2 * *",
+			wantErr:  "it must have the five fields",
+		},
+		"out of range": {
+			interval: "0 24 * * *",
+			wantErr:  "the hour field has an invalid value 24, it must be from 0 to 23",
+		},
+		"invalid time zone": {
+			interval: "CRON_TZ=Mars/Olympus 0 2 * * *",
+			wantErr:  "invalid time zone Mars/Olympus",
+		},
+		"never matches": {
+			interval: "0 0 30 FEB *",
+			wantErr:  "never matches",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			interval := EvaluationInterval{Compliant: test.interval, NonCompliant: test.interval}
+
+			next, err := interval.GetNextCompliantEvaluation(friday)
+			if test.wantErr != "" {
+				assert.ErrorContains(t, err, test.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, test.want.Equal(next), "got next evaluation %s", next)
+
+			next, err = interval.GetNextNonCompliantEvaluation(friday)
+			assert.NoError(t, err)
+			assert.True(t, test.want.Equal(next), "got next evaluation %s", next)
+		})
+	}
+}
@@ -0,0 +1,447 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/yaml"
+)
+
+// rawIntervalMessage explains why the object templates in object-templates-raw can't set evaluationInterval.
+const rawIntervalMessage = "evaluationInterval is only supported in object-templates since the intervals are " +
+	"checked before the templates are resolved. Set spec.evaluationInterval instead."
+
+// rawIntervalKey matches an evaluationInterval key in object-templates-raw that has templates, which can't be
+// unmarshaled before the templates are resolved.
+var rawIntervalKey = regexp.MustCompile(`(?m)^[\s-]*evaluationInterval\s*:`)
+
+//nolint:lll
+//+kubebuilder:webhook:path=/validate-policy-open-cluster-management-io-v1-configurationpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=policy.open-cluster-management.io,resources=configurationpolicies,verbs=create;update,versions=v1,name=vconfigurationpolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ConfigurationPolicy{}
+
+// SetupWebhookWithManager registers the validating webhook for ConfigurationPolicy with the manager.
+func (r *ConfigurationPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
+
+// ValidateCreate implements webhook.Validator.
+func (r *ConfigurationPolicy) ValidateCreate() error {
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (r *ConfigurationPolicy) ValidateUpdate(_ runtime.Object) error {
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator. Deletions are always allowed.
+func (r *ConfigurationPolicy) ValidateDelete() error {
+	return nil
+}
+
+// validate checks the parts of the spec that the CRD schema can't express. Templates are only parsed and
+// never executed, so templates that depend on the hub or the managed cluster don't cause a rejection.
+func (r *ConfigurationPolicy) validate() error {
+	if r.Spec == nil {
+		return nil
+	}
+
+	specPath := field.NewPath("spec")
+	errs := field.ErrorList{}
+
+	templateSources := 0
+
+	if len(r.Spec.ObjectTemplates) != 0 {
+		templateSources++
+	}
+
+	if r.Spec.ObjectTemplatesRaw != "" {
+		templateSources++
+	}
+
+	if r.Spec.ObjectTemplatesRawRef != nil {
+		templateSources++
+	}
+
+	if templateSources > 1 {
+		errs = append(errs, field.Forbidden(
+			specPath,
+			"only one of object-templates, object-templates-raw, and objectTemplatesRawRef can be set",
+		))
+	}
+
+	errs = append(errs, validateEvaluationInterval(r.Spec.EvaluationInterval, specPath.Child("evaluationInterval"))...)
+
+	if r.Spec.EnforcementSchedule != nil {
+		if _, _, err := r.Spec.EnforcementSchedule.InWindow(time.Now()); err != nil {
+			errs = append(errs, field.Invalid(specPath.Child("enforcementSchedule"), "", err.Error()))
+		}
+	}
+
+	for i, dependency := range r.Spec.Dependencies {
+		isConfigPolicy := dependency.Kind == "" || dependency.Kind == "ConfigurationPolicy"
+		inNamespace := dependency.Namespace == "" || dependency.Namespace == r.Namespace
+
+		if isConfigPolicy && inNamespace && dependency.Name == r.Name {
+			errs = append(errs, field.Invalid(
+				specPath.Child("dependencies").Index(i).Child("name"), dependency.Name,
+				"the policy can't depend on itself",
+			))
+		}
+	}
+
+	for i, objectT := range r.Spec.ObjectTemplates {
+		errs = append(errs, validateObjectTemplate(objectT, r.Spec, specPath.Child("object-templates").Index(i))...)
+	}
+
+	if r.Spec.ObjectTemplatesRaw != "" {
+		rawPath := specPath.Child("object-templates-raw")
+
+		if strings.Contains(r.Spec.ObjectTemplatesRaw, "{{") {
+			// The object templates are only known after the templates are resolved on the managed cluster
+			if err := parseTemplate(r.Spec.ObjectTemplatesRaw); err != nil {
+				errs = append(errs, field.Invalid(rawPath, "", "invalid template: "+err.Error()))
+			}
+
+			if rawIntervalKey.MatchString(r.Spec.ObjectTemplatesRaw) {
+				errs = append(errs, field.Forbidden(rawPath, rawIntervalMessage))
+			}
+		} else {
+			objTemps := []*ObjectTemplate{}
+
+			if err := yaml.Unmarshal([]byte(r.Spec.ObjectTemplatesRaw), &objTemps); err != nil {
+				errs = append(errs, field.Invalid(rawPath, "", "invalid YAML: "+err.Error()))
+			} else {
+				for i, objectT := range objTemps {
+					errs = append(errs, validateObjectTemplate(objectT, r.Spec, rawPath.Index(i))...)
+
+					if objectT != nil &&
+						(objectT.EvaluationInterval.Compliant != "" || objectT.EvaluationInterval.NonCompliant != "") {
+						errs = append(errs, field.Forbidden(
+							rawPath.Index(i).Child("evaluationInterval"), rawIntervalMessage,
+						))
+					}
+				}
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(GroupVersion.WithKind("ConfigurationPolicy").GroupKind(), r.Name, errs)
+}
+
+// validateObjectTemplate validates a single object template at the input path.
+func validateObjectTemplate(objectT *ObjectTemplate, spec *ConfigurationPolicySpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if objectT == nil {
+		return append(errs, field.Required(path, "the object template must not be empty"))
+	}
+
+	complianceType := objectT.ComplianceType
+	if !complianceType.IsMustHave() && !complianceType.IsMustOnlyHave() && !complianceType.IsMustNotHave() {
+		errs = append(errs, field.NotSupported(
+			path.Child("complianceType"),
+			complianceType,
+			[]string{"musthave", "mustonlyhave", "mustnothave"},
+		))
+	}
+
+	metadataTypes := []struct {
+		child string
+		value MetadataComplianceType
+	}{
+		{"metadataComplianceType", objectT.MetadataComplianceType},
+		{"labelsComplianceType", objectT.LabelsComplianceType},
+		{"annotationsComplianceType", objectT.AnnotationsComplianceType},
+	}
+
+	for _, metadataType := range metadataTypes {
+		if metadataType.value == "" {
+			continue
+		}
+
+		if value := ComplianceType(metadataType.value); !value.IsMustHave() && !value.IsMustOnlyHave() {
+			errs = append(errs, field.NotSupported(
+				path.Child(metadataType.child),
+				metadataType.value,
+				[]string{"musthave", "mustonlyhave"},
+			))
+		}
+	}
+
+	pruneBehavior := objectT.GetPruneObjectBehavior(spec.PruneObjectBehavior)
+
+	if complianceType.IsMustNotHave() && pruneBehavior.IsPruning() {
+		errs = append(errs, field.Forbidden(
+			path.Child("complianceType"),
+			fmt.Sprintf("mustnothave can't be used with the %s pruneObjectBehavior", pruneBehavior),
+		))
+	}
+
+	if objectT.PruneObjectBehavior.IsPruning() && !spec.RemediationAction.IsEnforce() {
+		errs = append(errs, field.Forbidden(
+			path.Child("pruneObjectBehavior"),
+			fmt.Sprintf("the %s pruneObjectBehavior requires the enforce remediationAction", objectT.PruneObjectBehavior),
+		))
+	}
+
+	// A server-side apply only sets the fields of the objectDefinition, so it can't remove the other fields
+	if complianceType.IsMustOnlyHave() && spec.EnforcementMethod == EnforcementMethodServerSideApply {
+		errs = append(errs, field.Forbidden(
+			path.Child("complianceType"), "mustonlyhave can't be used with the ServerSideApply enforcementMethod",
+		))
+	}
+
+	if objectT.CheckExistenceOnly && complianceType.IsMustOnlyHave() {
+		errs = append(errs, field.Forbidden(
+			path.Child("checkExistenceOnly"), "checkExistenceOnly can't be used with mustonlyhave",
+		))
+	}
+
+	if objectT.MinimumObjects != nil || objectT.MaximumObjects != nil {
+		if complianceType.IsMustNotHave() {
+			errs = append(errs, field.Forbidden(
+				path, "minimumObjects and maximumObjects can't be used with mustnothave",
+			))
+		}
+
+		if objectT.MinimumObjects != nil && objectT.MaximumObjects != nil &&
+			*objectT.MinimumObjects > *objectT.MaximumObjects {
+			errs = append(errs, field.Invalid(
+				path.Child("minimumObjects"), *objectT.MinimumObjects,
+				"minimumObjects can't be greater than maximumObjects",
+			))
+		}
+	}
+
+	if objectT.ObjectSelector != nil || objectT.MinimumObjects != nil || objectT.MaximumObjects != nil {
+		objDef := struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}{}
+
+		// An invalid objectDefinition is reported when it is validated below
+		err := json.Unmarshal(objectT.ObjectDefinition.Raw, &objDef)
+		if err == nil && objDef.Metadata.Name == "" && objDef.Metadata.GenerateName != "" {
+			errs = append(errs, field.Forbidden(
+				path.Child("objectDefinition", "metadata", "generateName"),
+				"generateName can't be used with objectSelector, minimumObjects, or maximumObjects",
+			))
+		}
+	}
+
+	if objectT.ObjectSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(objectT.ObjectSelector); err != nil {
+			errs = append(errs, field.Invalid(path.Child("objectSelector"), objectT.ObjectSelector, err.Error()))
+		}
+	}
+
+	// The status can't be enforced, so it's only compared when the policy is inform. The namespace to create the
+	// object in is ambiguous when the metadata.namespace is "*", so it's also only evaluated when the policy is inform.
+	if spec.RemediationAction.IsEnforce() && !complianceType.IsMustNotHave() {
+		objDef := map[string]interface{}{}
+
+		// An invalid objectDefinition is reported when it is validated below
+		if err := json.Unmarshal(objectT.ObjectDefinition.Raw, &objDef); err == nil {
+			if _, ok := objDef["status"]; ok {
+				errs = append(errs, field.Forbidden(
+					path.Child("objectDefinition", "status"),
+					"the status can only be compared with the inform remediationAction",
+				))
+			}
+
+			if metadata, ok := objDef["metadata"].(map[string]interface{}); ok && metadata["namespace"] == "*" {
+				errs = append(errs, field.Forbidden(
+					path.Child("objectDefinition", "metadata", "namespace"),
+					"the metadata.namespace can only be set to \"*\" with the inform remediationAction; use the "+
+						"namespaceSelector to enforce the object in multiple namespaces",
+				))
+			}
+		}
+	}
+
+	if len(objectT.ObjectChecks) != 0 {
+		if spec.RemediationAction.IsEnforce() {
+			errs = append(errs, field.Forbidden(
+				path.Child("objectChecks"), "objectChecks can only be used with the inform remediationAction",
+			))
+		}
+
+		if complianceType.IsMustNotHave() {
+			errs = append(errs, field.Forbidden(
+				path.Child("objectChecks"), "objectChecks can't be used with mustnothave",
+			))
+		}
+
+		for i, check := range objectT.ObjectChecks {
+			if err := check.Validate(); err != nil {
+				errs = append(errs, field.Invalid(path.Child("objectChecks").Index(i), check.Path, err.Error()))
+			}
+		}
+	}
+
+	errs = append(errs, validateEvaluationInterval(objectT.EvaluationInterval, path.Child("evaluationInterval"))...)
+	errs = append(errs, validateObjectDefinition(objectT.ObjectDefinition, path.Child("objectDefinition"))...)
+
+	return errs
+}
+
+// validateObjectDefinition verifies that the objectDefinition is an object with a kind, and that the templates in
+// its values can be parsed. The apiVersion can be omitted, in which case the kind is resolved on the cluster.
+func validateObjectDefinition(objDef runtime.RawExtension, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if len(objDef.Raw) == 0 {
+		return append(errs, field.Required(path, "the objectDefinition must be set"))
+	}
+
+	unstruct := map[string]interface{}{}
+
+	if err := json.Unmarshal(objDef.Raw, &unstruct); err != nil {
+		return append(errs, field.Invalid(path, "", "the objectDefinition must be an object: "+err.Error()))
+	}
+
+	errs = append(errs, validateAPIVersionAndKind(unstruct, path)...)
+
+	if unstruct["apiVersion"] == "v1" && unstruct["kind"] == "List" {
+		errs = append(errs, validateListItems(unstruct["items"], path.Child("items"))...)
+	}
+
+	return append(errs, validateTemplateValues(unstruct, path)...)
+}
+
+// validateListItems verifies that the items of a List objectDefinition are objects with a kind, and that none of
+// them are Lists since nested Lists aren't expanded.
+func validateListItems(items interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if items == nil {
+		return errs
+	}
+
+	itemList, ok := items.([]interface{})
+	if !ok {
+		return append(errs, field.Invalid(path, "", "the items of a List must be an array"))
+	}
+
+	for i, item := range itemList {
+		itemObj, ok := item.(map[string]interface{})
+		if !ok {
+			errs = append(errs, field.Invalid(path.Index(i), "", "the List item must be an object"))
+
+			continue
+		}
+
+		if itemObj["kind"] == "List" {
+			errs = append(errs, field.Forbidden(path.Index(i).Child("kind"), "nested Lists are not supported"))
+
+			continue
+		}
+
+		errs = append(errs, validateAPIVersionAndKind(itemObj, path.Index(i))...)
+	}
+
+	return errs
+}
+
+// validateAPIVersionAndKind verifies that the object has a kind, and that its apiVersion is a string if it's set.
+func validateAPIVersionAndKind(obj map[string]interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if apiVersion, found := obj["apiVersion"]; found {
+		if _, ok := apiVersion.(string); !ok {
+			errs = append(errs, field.Invalid(path.Child("apiVersion"), apiVersion, "the apiVersion must be a string"))
+		}
+	}
+
+	if kind, ok := obj["kind"].(string); !ok || kind == "" {
+		errs = append(errs, field.Required(path.Child("kind"), "the kind must be set to a string"))
+	}
+
+	return errs
+}
+
+// validateTemplateValues recursively parse-checks every string key and value that contains a template.
+func validateTemplateValues(value interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+
+		// Sort the keys so that the errors are in a consistent order
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			val := typedValue[key]
+
+			if strings.Contains(key, "{{") {
+				if err := parseTemplate(key); err != nil {
+					errs = append(errs, field.Invalid(path.Key(key), key, "invalid template: "+err.Error()))
+				}
+			}
+
+			errs = append(errs, validateTemplateValues(val, path.Child(key))...)
+		}
+	case []interface{}:
+		for i, val := range typedValue {
+			errs = append(errs, validateTemplateValues(val, path.Index(i))...)
+		}
+	case string:
+		if strings.Contains(typedValue, "{{") {
+			if err := parseTemplate(typedValue); err != nil {
+				errs = append(errs, field.Invalid(path, typedValue, "invalid template: "+err.Error()))
+			}
+		}
+	}
+
+	return errs
+}
+
+// parseTemplate parses the input template without executing it. The functions aren't checked since the
+// available functions depend on where the template is resolved.
+func parseTemplate(text string) error {
+	tree := parse.New("objectDefinition")
+	tree.Mode = parse.SkipFuncCheck
+
+	_, err := tree.Parse(text, "{{", "}}", map[string]*parse.Tree{})
+
+	return err
+}
+
+// validateEvaluationInterval verifies that both intervals are durations, cron expressions, or "never".
+func validateEvaluationInterval(interval EvaluationInterval, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	now := time.Now()
+
+	if _, err := interval.GetNextCompliantEvaluation(now); err != nil && !errors.Is(err, ErrIsNever) {
+		errs = append(errs, field.Invalid(path.Child("compliant"), interval.Compliant, err.Error()))
+	}
+
+	if _, err := interval.GetNextNonCompliantEvaluation(now); err != nil && !errors.Is(err, ErrIsNever) {
+		errs = append(errs, field.Invalid(path.Child("noncompliant"), interval.NonCompliant, err.Error()))
+	}
+
+	return errs
+}
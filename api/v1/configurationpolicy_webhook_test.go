@@ -0,0 +1,392 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	configMap := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"}}`
+	one := int32(1)
+	two := int32(2)
+	sixty := int64(60)
+
+	tests := map[string]struct {
+		spec   ConfigurationPolicySpec
+		errMsg string
+	}{
+		"valid object template": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+				}},
+			},
+		},
+		"valid hub and managed cluster templates": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"},"data":{` +
+							`"a":"{{hub fromConfigMap \"\" \"hub-cm\" \"a\" hub}}",` +
+							`"b":"{{ fromSecret \"default\" \"secret\" \"b\" | base64dec }}"}}`,
+					)},
+				}},
+			},
+		},
+		"valid raw templates": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplatesRaw: "{{ range $i := until 2 }}\n- complianceType: musthave\n" +
+					"  objectDefinition:\n    kind: ConfigMap\n{{ end }}",
+			},
+		},
+		"invalid template syntax": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"ConfigMap","data":{"a":"{{ if .Missing }}"}}`,
+					)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectDefinition.data.a: Invalid value",
+		},
+		"kind without an apiVersion": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"kind":"ConfigMap"}`)},
+				}},
+			},
+		},
+		"invalid apiVersion": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":1,"kind":"ConfigMap"}`)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectDefinition.apiVersion: Invalid value",
+		},
+		"missing kind": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1"}`)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectDefinition.kind: Required value",
+		},
+		"invalid complianceType": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "shouldhave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].complianceType: Unsupported value",
+		},
+		"invalid labelsComplianceType": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:            "musthave",
+					LabelsComplianceType:      "mustnothave",
+					AnnotationsComplianceType: "musthave",
+					ObjectDefinition:          runtime.RawExtension{Raw: []byte(configMap)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].labelsComplianceType: Unsupported value",
+		},
+		"mustnothave with pruneObjectBehavior": {
+			spec: ConfigurationPolicySpec{
+				PruneObjectBehavior: "DeleteAll",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "mustnothave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].complianceType: Forbidden",
+		},
+		"mustnothave with an object template pruneObjectBehavior of None": {
+			spec: ConfigurationPolicySpec{
+				PruneObjectBehavior: "DeleteAll",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:      "mustnothave",
+					ObjectDefinition:    runtime.RawExtension{Raw: []byte(configMap)},
+					PruneObjectBehavior: "None",
+				}},
+			},
+		},
+		"object template pruneObjectBehavior with inform": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "inform",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:      "musthave",
+					ObjectDefinition:    runtime.RawExtension{Raw: []byte(configMap)},
+					PruneObjectBehavior: "DeleteIfCreated",
+				}},
+			},
+			errMsg: "spec.object-templates[0].pruneObjectBehavior: Forbidden",
+		},
+		"minimumObjects greater than maximumObjects": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap"}`)},
+					MinimumObjects:   &two,
+					MaximumObjects:   &one,
+				}},
+			},
+			errMsg: "spec.object-templates[0].minimumObjects: Invalid value",
+		},
+		"invalid objectSelector": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap"}`)},
+					ObjectSelector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}},
+					},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectSelector: Invalid value",
+		},
+		"generateName with objectSelector": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"generateName":"cm-"}}`,
+					)},
+					ObjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectDefinition.metadata.generateName: Forbidden",
+		},
+		"valid List": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"List","items":[` + configMap + `]}`,
+					)},
+				}},
+			},
+		},
+		"nested List": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"List","items":[` + configMap +
+							`,{"apiVersion":"v1","kind":"List","items":[]}]}`,
+					)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectDefinition.items[1].kind: Forbidden",
+		},
+		"invalid raw YAML": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplatesRaw: "- complianceType: musthave\n  objectDefinition: [",
+			},
+			errMsg: "spec.object-templates-raw: Invalid value",
+		},
+		"invalid object template in raw YAML": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplatesRaw: "- complianceType: musthave\n  objectDefinition:\n    apiVersion: v1\n",
+			},
+			errMsg: "spec.object-templates-raw[0].objectDefinition.kind: Required value",
+		},
+		"evaluationInterval in raw YAML": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplatesRaw: "- complianceType: musthave\n  evaluationInterval:\n    compliant: 1h\n" +
+					"  objectDefinition:\n    apiVersion: v1\n    kind: ConfigMap\n",
+			},
+			errMsg: "spec.object-templates-raw[0].evaluationInterval: Forbidden",
+		},
+		"evaluationInterval in raw templates": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplatesRaw: "{{ range $i := until 2 }}\n- complianceType: musthave\n" +
+					"  evaluationInterval:\n    compliant: 1h\n  objectDefinition:\n    kind: ConfigMap\n{{ end }}",
+			},
+			errMsg: "spec.object-templates-raw: Forbidden",
+		},
+		"both object-templates and object-templates-raw": {
+			spec: ConfigurationPolicySpec{
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+				}},
+				ObjectTemplatesRaw: "- complianceType: musthave\n",
+			},
+			errMsg: "spec: Forbidden",
+		},
+		"mustonlyhave with ServerSideApply": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "enforce",
+				EnforcementMethod: EnforcementMethodServerSideApply,
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "mustonlyhave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].complianceType: Forbidden",
+		},
+		"dependency on itself": {
+			spec: ConfigurationPolicySpec{
+				Dependencies: []PolicyDependency{{Name: "other"}, {Name: "policy"}},
+			},
+			errMsg: "spec.dependencies[1].name: Invalid value",
+		},
+		"invalid enforcementSchedule time zone": {
+			spec: ConfigurationPolicySpec{
+				EnforcementSchedule: &EnforcementSchedule{
+					TimeZone: "Mars/Olympus_Mons",
+					Windows:  []EnforcementWindow{{Start: "00:00", Duration: "1h"}},
+				},
+			},
+			errMsg: "spec.enforcementSchedule: Invalid value",
+		},
+		"status with inform": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "inform",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"apps/v1","kind":"Deployment","status":{"availableReplicas":1}}`,
+					)},
+				}},
+			},
+		},
+		"status with enforce": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "enforce",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"apps/v1","kind":"Deployment","status":{"availableReplicas":1}}`,
+					)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectDefinition.status: Forbidden",
+		},
+		"all namespaces with inform": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "inform",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"*"}}`,
+					)},
+				}},
+			},
+		},
+		"all namespaces with enforce mustnothave": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "enforce",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "mustnothave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"*"}}`,
+					)},
+				}},
+			},
+		},
+		"all namespaces with enforce": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "enforce",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType: "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(
+						`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"*"}}`,
+					)},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectDefinition.metadata.namespace: Forbidden",
+		},
+		"valid objectChecks": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "inform",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+					ObjectChecks: []ObjectCheck{
+						{Path: "/data/registry", Regex: `registry\.corp/.*`},
+						{Path: "/data/replicas", Minimum: &sixty, Maximum: &sixty},
+					},
+				}},
+			},
+		},
+		"objectChecks with enforce": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "enforce",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+					ObjectChecks:     []ObjectCheck{{Path: "/data/registry", Regex: `registry\.corp/.*`}},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectChecks: Forbidden",
+		},
+		"invalid objectChecks regex": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "inform",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+					ObjectChecks:     []ObjectCheck{{Path: "/data/registry", Regex: `registry(`}},
+				}},
+			},
+			errMsg: "spec.object-templates[0].objectChecks[0]: Invalid value",
+		},
+		"objectChecks without an expectation": {
+			spec: ConfigurationPolicySpec{
+				RemediationAction: "inform",
+				ObjectTemplates: []*ObjectTemplate{{
+					ComplianceType:   "musthave",
+					ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+					ObjectChecks:     []ObjectCheck{{Path: "/data/registry"}},
+				}},
+			},
+			errMsg: "must set regex, minimum, maximum, or exists",
+		},
+		"invalid evaluationInterval": {
+			spec: ConfigurationPolicySpec{
+				EvaluationInterval: EvaluationInterval{Compliant: "never", NonCompliant: "1 hour"},
+			},
+			errMsg: "spec.evaluationInterval.noncompliant: Invalid value",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policy := &ConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+				Spec:       &test.spec,
+			}
+
+			err := policy.validate()
+			if test.errMsg == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.True(t, apierrors.IsInvalid(err))
+			assert.ErrorContains(t, err, test.errMsg)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression in the standard five field format of minute, hour, day of month, month,
+// and day of week. Each field is stored as a bitset of the matching values.
+type cronSchedule struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+	// When either day field is "*", a day must match both fields, otherwise it must match either field, which is the
+	// standard cron behavior.
+	anyDay bool
+	loc    *time.Location
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonths = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdays = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+
+// isCron determines if the input evaluation interval is a cron expression rather than a duration. Durations never
+// contain spaces.
+func isCron(interval string) bool {
+	return strings.HasPrefix(interval, "@") || strings.ContainsAny(interval, " \t")
+}
+
+// parseCron parses a cron expression in the standard five field format. The "@daily" style macros are supported, and
+// the expression can be prefixed with "CRON_TZ=<time zone> " to use a time zone other than UTC.
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	schedule := &cronSchedule{loc: time.UTC}
+
+	if len(fields) != 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		timeZone := fields[0][strings.Index(fields[0], "=")+1:]
+
+		loc, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %s: %w", timeZone, err)
+		}
+
+		schedule.loc = loc
+		fields = fields[1:]
+	}
+
+	if len(fields) == 1 {
+		if macro, ok := cronMacros[fields[0]]; ok {
+			fields = strings.Fields(macro)
+		}
+	}
+
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(
+			"invalid cron expression %s, it must have the five fields minute, hour, day of month, month, and day of "+
+				"week", expr,
+		)
+	}
+
+	var err error
+
+	bounds := []struct {
+		name  string
+		field *uint64
+		min   int
+		max   int
+		names map[string]int
+	}{
+		{"minute", &schedule.minutes, 0, 59, nil},
+		{"hour", &schedule.hours, 0, 23, nil},
+		{"day of month", &schedule.daysOfMonth, 1, 31, nil},
+		{"month", &schedule.months, 1, 12, cronMonths},
+		// 7 is also Sunday
+		{"day of week", &schedule.daysOfWeek, 0, 7, cronWeekdays},
+	}
+
+	for i, bound := range bounds {
+		*bound.field, err = parseCronField(fields[i], bound.min, bound.max, bound.names)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %s, the %s field %w", expr, bound.name, err)
+		}
+	}
+
+	if schedule.daysOfWeek&(1<<7) != 0 {
+		schedule.daysOfWeek |= 1
+	}
+
+	schedule.anyDay = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges, and steps (e.g. "1,5-10,*/15") to a bitset.
+func parseCronField(field string, min int, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+
+	parseValue := func(value string) (int, error) {
+		if number, ok := names[strings.ToUpper(value)]; ok {
+			return number, nil
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil || number < min || number > max {
+			return 0, fmt.Errorf("has an invalid value %s, it must be from %d to %d", value, min, max)
+		}
+
+		return number, nil
+	}
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("has an invalid step %s", stepPart)
+			}
+		}
+
+		start, end := min, max
+
+		if rangePart != "*" {
+			startStr, endStr, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+
+			start, err = parseValue(startStr)
+			if err != nil {
+				return 0, err
+			}
+
+			end = start
+
+			if isRange {
+				end, err = parseValue(endStr)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// A step on a single value (e.g. "5/15") starts from that value
+				end = max
+			}
+
+			if end < start {
+				return 0, fmt.Errorf("has an invalid range %s", rangePart)
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+// next returns the first time after the input time that matches the schedule. The zero time is returned if no time
+// matches within five years, such as for February 30th.
+func (s *cronSchedule) next(after time.Time) time.Time {
+	after = after.In(s.loc)
+	next := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, s.loc)
+	limit := next.AddDate(5, 0, 0)
+
+	for next.Before(limit) {
+		switch {
+		case s.months&(1<<uint(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, s.loc)
+		case s.hours&(1<<uint(next.Hour())) == 0:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, s.loc)
+		case s.minutes&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(day time.Time) bool {
+	dayOfMonth := s.daysOfMonth&(1<<uint(day.Day())) != 0
+	dayOfWeek := s.daysOfWeek&(1<<uint(day.Weekday())) != 0
+
+	if s.anyDay {
+		return dayOfMonth && dayOfWeek
+	}
+
+	return dayOfMonth || dayOfWeek
+}
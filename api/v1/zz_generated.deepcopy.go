@@ -167,7 +167,23 @@ func (in *ConfigurationPolicySpec) DeepCopyInto(out *ConfigurationPolicySpec) {
 			}
 		}
 	}
+	if in.ObjectTemplatesRawRef != nil {
+		in, out := &in.ObjectTemplatesRawRef, &out.ObjectTemplatesRawRef
+		*out = new(ObjectTemplatesRawRef)
+		**out = **in
+	}
 	out.EvaluationInterval = in.EvaluationInterval
+	out.CustomMessage = in.CustomMessage
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]PolicyDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.EnforcementSchedule != nil {
+		in, out := &in.EnforcementSchedule, &out.EnforcementSchedule
+		*out = new(EnforcementSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigurationPolicySpec.
@@ -190,6 +206,18 @@ func (in *ConfigurationPolicyStatus) DeepCopyInto(out *ConfigurationPolicyStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingNoncompliance != nil {
+		in, out := &in.PendingNoncompliance, &out.PendingNoncompliance
+		*out = new(PendingNoncompliance)
+		**out = **in
+	}
 	if in.RelatedObjects != nil {
 		in, out := &in.RelatedObjects, &out.RelatedObjects
 		*out = make([]RelatedObject, len(*in))
@@ -209,6 +237,83 @@ func (in *ConfigurationPolicyStatus) DeepCopy() *ConfigurationPolicyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomMessage) DeepCopyInto(out *CustomMessage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomMessage.
+func (in *CustomMessage) DeepCopy() *CustomMessage {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomMessage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeleteOptions) DeepCopyInto(out *DeleteOptions) {
+	*out = *in
+	if in.PropagationPolicy != nil {
+		in, out := &in.PropagationPolicy, &out.PropagationPolicy
+		*out = new(metav1.DeletionPropagation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeleteOptions.
+func (in *DeleteOptions) DeepCopy() *DeleteOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DeleteOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnforcementSchedule) DeepCopyInto(out *EnforcementSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]EnforcementWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnforcementSchedule.
+func (in *EnforcementSchedule) DeepCopy() *EnforcementSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(EnforcementSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnforcementWindow) DeepCopyInto(out *EnforcementWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnforcementWindow.
+func (in *EnforcementWindow) DeepCopy() *EnforcementWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(EnforcementWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvaluationInterval) DeepCopyInto(out *EvaluationInterval) {
 	*out = *in
@@ -224,6 +329,36 @@ func (in *EvaluationInterval) DeepCopy() *EvaluationInterval {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectCheck) DeepCopyInto(out *ObjectCheck) {
+	*out = *in
+	if in.Minimum != nil {
+		in, out := &in.Minimum, &out.Minimum
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Maximum != nil {
+		in, out := &in.Maximum, &out.Maximum
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Exists != nil {
+		in, out := &in.Exists, &out.Exists
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectCheck.
+func (in *ObjectCheck) DeepCopy() *ObjectCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectMetadata) DeepCopyInto(out *ObjectMetadata) {
 	*out = *in
@@ -279,6 +414,57 @@ func (in *ObjectResource) DeepCopy() *ObjectResource {
 func (in *ObjectTemplate) DeepCopyInto(out *ObjectTemplate) {
 	*out = *in
 	in.ObjectDefinition.DeepCopyInto(&out.ObjectDefinition)
+	out.EvaluationInterval = in.EvaluationInterval
+	if in.IgnoreFields != nil {
+		in, out := &in.IgnoreFields, &out.IgnoreFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnorderedLists != nil {
+		in, out := &in.UnorderedLists, &out.UnorderedLists
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ListMergeKeys != nil {
+		in, out := &in.ListMergeKeys, &out.ListMergeKeys
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ObjectChecks != nil {
+		in, out := &in.ObjectChecks, &out.ObjectChecks
+		*out = make([]ObjectCheck, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.DeleteOptions.DeepCopyInto(&out.DeleteOptions)
+	if in.MinimumObjects != nil {
+		in, out := &in.MinimumObjects, &out.MinimumObjects
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaximumObjects != nil {
+		in, out := &in.MaximumObjects, &out.MaximumObjects
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ObjectSelector != nil {
+		in, out := &in.ObjectSelector, &out.ObjectSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TolerateFieldManagers != nil {
+		in, out := &in.TolerateFieldManagers, &out.TolerateFieldManagers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TolerateExternalFields != nil {
+		in, out := &in.TolerateExternalFields, &out.TolerateExternalFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplate.
@@ -291,6 +477,51 @@ func (in *ObjectTemplate) DeepCopy() *ObjectTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectTemplatesRawRef) DeepCopyInto(out *ObjectTemplatesRawRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTemplatesRawRef.
+func (in *ObjectTemplatesRawRef) DeepCopy() *ObjectTemplatesRawRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectTemplatesRawRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingNoncompliance) DeepCopyInto(out *PendingNoncompliance) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingNoncompliance.
+func (in *PendingNoncompliance) DeepCopy() *PendingNoncompliance {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingNoncompliance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyDependency) DeepCopyInto(out *PolicyDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyDependency.
+func (in *PolicyDependency) DeepCopy() *PolicyDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RelatedObject) DeepCopyInto(out *RelatedObject) {
 	*out = *in
@@ -370,6 +601,11 @@ func (in *TemplateStatus) DeepCopyInto(out *TemplateStatus) {
 		}
 	}
 	in.Validity.DeepCopyInto(&out.Validity)
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(TemplateSummary)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateStatus.
@@ -382,6 +618,21 @@ func (in *TemplateStatus) DeepCopy() *TemplateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSummary) DeepCopyInto(out *TemplateSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSummary.
+func (in *TemplateSummary) DeepCopy() *TemplateSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Validity) DeepCopyInto(out *Validity) {
 	*out = *in
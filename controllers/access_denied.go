@@ -0,0 +1,49 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const reasonAccessDenied = "Access denied by RBAC; unable to evaluate"
+
+// accessDeniedResult returns the noncompliant result of an object template whose objects can't be retrieved since
+// RBAC denies the controller access to them. Whether the objects exist is unknown, so they are never created or
+// deleted in this case, and they aren't reported as missing.
+func accessDeniedResult(
+	objNames []string,
+	namespace string,
+	objDetails objectTemplateDetails,
+	mapping *meta.RESTMapping,
+) (
+	relatedObjects []policyv1.RelatedObject,
+	result objectTmplEvalResult,
+) {
+	result = objectTmplEvalResult{
+		objectNames: objNames,
+		namespace:   namespace,
+		events:      []objectTmplEvalEvent{{false, reasonAccessDenied, ""}},
+	}
+
+	if len(objNames) == 0 {
+		relatedObjects = addCondensedRelatedObjs(
+			mapping.Resource, false, objDetails.kind, namespace, objDetails.isNamespaced, reasonAccessDenied,
+		)
+	} else {
+		relatedObjects = addRelatedObjects(
+			false,
+			mapping.Resource,
+			objDetails.kind,
+			namespace,
+			objDetails.isNamespaced,
+			objNames,
+			reasonAccessDenied,
+			nil,
+		)
+	}
+
+	return relatedObjects, result
+}
@@ -0,0 +1,104 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestHandleObjectsAccessDenied(t *testing.T) {
+	t.Parallel()
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	mapping := &meta.RESTMapping{
+		Resource:         gvr,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+		Scope:            meta.RESTScopeNamespace,
+	}
+
+	tests := map[string]struct {
+		objectT       *policyv1.ObjectTemplate
+		objDetails    objectTemplateDetails
+		expectedNames []string
+	}{
+		"named musthave": {
+			objectT:       &policyv1.ObjectTemplate{ComplianceType: policyv1.MustHave},
+			objDetails:    objectTemplateDetails{kind: "Secret", name: "my-secret", isNamespaced: true},
+			expectedNames: []string{"my-secret"},
+		},
+		"named mustnothave": {
+			objectT:       &policyv1.ObjectTemplate{ComplianceType: policyv1.MustNotHave},
+			objDetails:    objectTemplateDetails{kind: "Secret", name: "my-secret", isNamespaced: true},
+			expectedNames: []string{"my-secret"},
+		},
+		"unnamed mustnothave with an objectSelector": {
+			objectT: &policyv1.ObjectTemplate{
+				ComplianceType: policyv1.MustNotHave,
+				ObjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			},
+			objDetails: objectTemplateDetails{kind: "Secret", isNamespaced: true},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			// Nothing may be created or deleted when it's unknown whether the objects exist
+			client.PrependReactor("*", "secrets", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				t.Errorf("unexpected %s request", action.GetVerb())
+
+				return true, nil, nil
+			})
+			// The reactors prepended last are run first
+			forbidden := func(_ clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, k8serrors.NewForbidden(gvr.GroupResource(), "", errors.New("no RBAC"))
+			}
+			client.PrependReactor("get", "secrets", forbidden)
+			client.PrependReactor("list", "secrets", forbidden)
+
+			r := &ConfigurationPolicyReconciler{TargetK8sDynamicClient: client}
+			policy := &policyv1.ConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+				Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: policyv1.Enforce},
+			}
+			desiredObj := unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"metadata":   map[string]interface{}{"name": test.objDetails.name, "namespace": "default"},
+			}}
+
+			relatedObjects, result := r.handleObjects(
+				test.objectT, "default", test.objDetails, 0, policy, mapping, desiredObj,
+			)
+
+			assert.Equal(t, test.expectedNames, result.objectNames)
+
+			if assert.Len(t, result.events, 1) {
+				assert.False(t, result.events[0].compliant)
+				assert.Equal(t, reasonAccessDenied, result.events[0].reason)
+			}
+
+			if assert.Len(t, relatedObjects, 1) {
+				assert.Equal(t, string(policyv1.NonCompliant), relatedObjects[0].Compliant)
+				assert.Equal(t, reasonAccessDenied, relatedObjects[0].Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	// allNamespacesSentinel is the metadata.namespace of an objectDefinition that is evaluated in every namespace on
+	// the cluster.
+	allNamespacesSentinel = "*"
+	// namespacePageSize is the number of namespaces retrieved per list request so that the namespaces of large
+	// clusters aren't all held in memory at once.
+	namespacePageSize = 500
+)
+
+// listAllNamespaces returns the sorted names of the namespaces on the cluster. The namespaces are listed in pages and
+// only their names are kept. The namespaces restricted by the controller configuration are included since the object
+// templates are still evaluated there, and they're only not enforced.
+func (r *ConfigurationPolicyReconciler) listAllNamespaces() ([]string, error) {
+	namespaces := []string{}
+	listOpts := metav1.ListOptions{Limit: namespacePageSize}
+
+	for {
+		nsList, err := r.TargetK8sClient.CoreV1().Namespaces().List(context.TODO(), listOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ns := range nsList.Items {
+			namespaces = append(namespaces, ns.Name)
+		}
+
+		if nsList.Continue == "" {
+			break
+		}
+
+		listOpts.Continue = nsList.Continue
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// listAllNamespacesObjects lists the objects of an object template with the "*" namespace with cluster-scoped requests
+// rather than requests in each namespace, and returns them by namespace. The list is limited to the named object or to
+// the objects matching the objectSelector like the requests in each namespace, and it's retrieved in pages that are
+// grouped by namespace as they're received so that a whole list response of a large cluster isn't held at once.
+func (r *ConfigurationPolicyReconciler) listAllNamespacesObjects(
+	objectT *policyv1.ObjectTemplate,
+	details objectTemplateDetails,
+	desiredObj unstructured.Unstructured,
+	gvr schema.GroupVersionResource,
+) (map[string][]unstructured.Unstructured, error) {
+	listOpts := metav1.ListOptions{Limit: namespacePageSize}
+
+	if details.name != "" {
+		listOpts.FieldSelector = fields.OneTermEqualSelector("metadata.name", details.name).String()
+	} else if desiredObj.GetGenerateName() == "" && objectT.ObjectSelector != nil {
+		// The objectSelector was validated before the object templates were processed
+		selector, _ := metav1.LabelSelectorAsSelector(objectT.ObjectSelector)
+		listOpts.LabelSelector = selector.String()
+	}
+
+	objects := make(map[string][]unstructured.Unstructured, len(details.namespaces))
+
+	for _, ns := range details.namespaces {
+		objects[ns] = nil
+	}
+
+	for {
+		resList, err := r.TargetK8sDynamicClient.Resource(gvr).List(context.TODO(), listOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range resList.Items {
+			if namespaceObjs, evaluated := objects[obj.GetNamespace()]; evaluated {
+				objects[obj.GetNamespace()] = append(namespaceObjs, obj)
+			}
+		}
+
+		if resList.GetContinue() == "" {
+			break
+		}
+
+		listOpts.Continue = resList.GetContinue()
+	}
+
+	return objects, nil
+}
+
+// getTemplateObject returns the object with the name in the namespace, or nil if it doesn't exist. The object of an
+// object template with the "*" namespace is read from the cluster-scoped list when it's available.
+func (r *ConfigurationPolicyReconciler) getTemplateObject(
+	details objectTemplateDetails, namespace string, name string, gvr schema.GroupVersionResource,
+) (*unstructured.Unstructured, error) {
+	if details.listedObjects == nil {
+		return getObject(details.isNamespaced, namespace, name, gvr, r.TargetK8sDynamicClient)
+	}
+
+	for _, obj := range details.listedObjects[namespace] {
+		if obj.GetName() == name {
+			return obj.DeepCopy(), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getTemplateNamesOfKind is like getNamesOfKind with the less conservative comparison of the unnamed object
+// templates, except that the objects of an object template with the "*" namespace are read from the cluster-scoped
+// list when it's available.
+func (r *ConfigurationPolicyReconciler) getTemplateNamesOfKind(
+	details objectTemplateDetails,
+	desiredObj unstructured.Unstructured,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	labelSelector string,
+	complianceType string,
+) (kindNameList []string, allResourceList []string, err error) {
+	if details.listedObjects == nil {
+		return getNamesOfKind(
+			desiredObj, gvr, details.isNamespaced, namespace, labelSelector, r.TargetK8sDynamicClient,
+			complianceType, true,
+		)
+	}
+
+	// The comparison can modify the objects, so the listed objects are copied
+	resList := (&unstructured.UnstructuredList{Items: details.listedObjects[namespace]}).DeepCopy()
+
+	for _, res := range resList.Items {
+		allResourceList = append(allResourceList, res.GetName())
+	}
+
+	return buildNameList(desiredObj, complianceType, resList, true), allResourceList, nil
+}
+
+// validateAllNamespaces returns an error if an object template with metadata.namespace set to "*" could create
+// objects, since the namespace to create them in is ambiguous. Deleting the objects with mustnothave is allowed.
+func validateAllNamespaces(
+	plc *policyv1.ConfigurationPolicy, objectT *policyv1.ObjectTemplate, objDetails objectTemplateDetails,
+) error {
+	if !objDetails.allNamespaces || objectT.ComplianceType.IsMustNotHave() {
+		return nil
+	}
+
+	if plc.Spec.RemediationAction.IsEnforce() {
+		return errors.New(
+			"metadata.namespace can only be set to \"*\" in inform policies since the namespace to create the " +
+				"object in is ambiguous; use the namespaceSelector to enforce the object in multiple namespaces",
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestListAllNamespaces(t *testing.T) {
+	t.Parallel()
+
+	pages := []*corev1.NamespaceList{
+		{
+			ListMeta: metav1.ListMeta{Continue: "page2"},
+			Items: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			},
+		},
+		{
+			Items: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kube-public"}},
+			},
+		},
+	}
+
+	client := testclient.NewSimpleClientset()
+	requests := 0
+
+	client.PrependReactor("list", "namespaces", func(_ clienttesting.Action) (bool, runtime.Object, error) {
+		page := pages[requests]
+		requests++
+
+		return true, page, nil
+	})
+
+	r := &ConfigurationPolicyReconciler{TargetK8sClient: client, DeniedNamespaces: []string{"kube-*"}}
+
+	// The restricted namespaces are still evaluated, and the objects in them are only not enforced
+	namespaces, err := r.listAllNamespaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app", "default", "kube-public", "kube-system"}, namespaces)
+	assert.Equal(t, 2, requests)
+}
+
+func TestValidateAllNamespaces(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		remediation    policyv1.RemediationAction
+		complianceType policyv1.ComplianceType
+		allNamespaces  bool
+		expectErr      bool
+	}{
+		"inform musthave":      {policyv1.Inform, policyv1.MustHave, true, false},
+		"enforce musthave":     {policyv1.Enforce, policyv1.MustHave, true, true},
+		"enforce mustonlyhave": {policyv1.Enforce, policyv1.MustOnlyHave, true, true},
+		"enforce mustnothave":  {policyv1.Enforce, policyv1.MustNotHave, true, false},
+		"enforce namespace":    {policyv1.Enforce, policyv1.MustHave, false, false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			plc := &policyv1.ConfigurationPolicy{
+				Spec: &policyv1.ConfigurationPolicySpec{RemediationAction: test.remediation},
+			}
+			objectT := &policyv1.ObjectTemplate{ComplianceType: test.complianceType}
+			details := objectTemplateDetails{
+				kind: "ConfigMap", name: "my-configmap", isNamespaced: true, allNamespaces: test.allNamespaces,
+			}
+
+			err := validateAllNamespaces(plc, objectT, details)
+			assert.Equal(t, test.expectErr, err != nil)
+		})
+	}
+}
+
+func TestTemplateNamespacesAllNamespaces(t *testing.T) {
+	t.Parallel()
+
+	details := objectTemplateDetails{
+		kind: "ConfigMap", isNamespaced: true, allNamespaces: true, namespaces: []string{"app", "default"},
+	}
+
+	// The namespaceSelector doesn't apply to the object templates evaluated in all namespaces
+	assert.Equal(t, []string{"app", "default"}, templateNamespaces(details, []string{"selected"}))
+
+	details.namespaces = nil
+	assert.Equal(t, []string{""}, templateNamespaces(details, []string{"selected"}))
+}
+
+func TestListAllNamespacesObjects(t *testing.T) {
+	t.Parallel()
+
+	newConfigMap := func(name, namespace string, labels map[string]string) *unstructured.Unstructured {
+		configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+			"data":       map[string]interface{}{"key": "value"},
+		}}
+		configMap.SetLabels(labels)
+
+		return configMap
+	}
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ConfigMapList"},
+		newConfigMap("my-configmap", "app", map[string]string{"app": "true"}),
+		newConfigMap("other", "app", nil),
+		newConfigMap("my-configmap", "kube-system", map[string]string{"app": "true"}),
+	)
+	r := &ConfigurationPolicyReconciler{TargetK8sDynamicClient: client}
+	details := objectTemplateDetails{
+		kind: "ConfigMap", isNamespaced: true, allNamespaces: true, namespaces: []string{"app", "default"},
+	}
+	desiredObj := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"data":       map[string]interface{}{"key": "value"},
+	}}
+	objectT := &policyv1.ObjectTemplate{
+		ComplianceType: policyv1.MustHave,
+		ObjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "true"}},
+	}
+
+	var err error
+
+	details.listedObjects, err = r.listAllNamespacesObjects(objectT, details, desiredObj, gvr)
+	assert.NoError(t, err)
+
+	// The objects in the namespaces that aren't evaluated are dropped
+	assert.Len(t, details.listedObjects, 2)
+	assert.Len(t, details.listedObjects["app"], 1)
+	assert.Empty(t, details.listedObjects["default"])
+
+	names, allNames, err := r.getTemplateNamesOfKind(details, desiredObj, gvr, "app", "app=true", "musthave")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"my-configmap"}, names)
+	assert.Equal(t, []string{"my-configmap"}, allNames)
+
+	existing, err := r.getTemplateObject(details, "app", "my-configmap", gvr)
+	assert.NoError(t, err)
+	assert.Equal(t, "app", existing.GetNamespace())
+
+	existing, err = r.getTemplateObject(details, "default", "my-configmap", gvr)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// A single cluster-scoped list request is made for all the namespaces
+	if assert.Len(t, client.Actions(), 1) {
+		listAction, ok := client.Actions()[0].(clienttesting.ListAction)
+		if assert.True(t, ok) {
+			assert.Equal(t, "", listAction.GetNamespace())
+			assert.Equal(t, "app=true", listAction.GetListRestrictions().Labels.String())
+		}
+	}
+}
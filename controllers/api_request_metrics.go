@@ -0,0 +1,267 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// recordAPIRequest records the API request of the controller that started at the start time in the API request
+// metrics. The kind label is the resource of the request, such as "configmaps", so that the requests recorded from
+// the clients and from the HTTP requests are labeled the same.
+func recordAPIRequest(controller string, verb string, resource string, start time.Time) {
+	apiRequestsCounter.WithLabelValues(verb, resource, controller).Inc()
+	apiRequestDurationHistogram.WithLabelValues(verb, resource, controller).Observe(time.Since(start).Seconds())
+}
+
+// InstrumentClient returns the client wrapped so that the requests of the controller through it are recorded in the
+// API request metrics. The requests are labeled with the resource of their objects. The reads of the typed objects
+// aren't recorded since the manager client serves them from its cache, so they don't reach the API server.
+func InstrumentClient(c client.Client, controller string) client.Client {
+	return &instrumentedClient{Client: c, controller: controller}
+}
+
+// instrumentedClient records the requests of the controller through the client in the API request metrics.
+type instrumentedClient struct {
+	client.Client
+	controller string
+}
+
+// resource returns the resource of the object, such as "configmaps", or "unknown" when it can't be determined. The List
+// suffix of the lists is removed so that the requests of a kind are labeled the same.
+func (c *instrumentedClient) resource(obj runtime.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil || gvk.Kind == "" {
+		return "unknown"
+	}
+
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	if mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+		return mapping.Resource.Resource
+	}
+
+	plural, _ := meta.UnsafeGuessKindToResource(gvk)
+
+	return plural.Resource
+}
+
+// cached returns whether the reads of the object are served from the manager cache, which is the case for the typed
+// objects.
+func cached(obj runtime.Object) bool {
+	switch obj.(type) {
+	case *unstructured.Unstructured, *unstructured.UnstructuredList:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *instrumentedClient) Get(
+	ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption,
+) error {
+	if !cached(obj) {
+		defer recordAPIRequest(c.controller, "get", c.resource(obj), time.Now())
+	}
+
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *instrumentedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if !cached(list) {
+		defer recordAPIRequest(c.controller, "list", c.resource(list), time.Now())
+	}
+
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *instrumentedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	defer recordAPIRequest(c.controller, "create", c.resource(obj), time.Now())
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *instrumentedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer recordAPIRequest(c.controller, "update", c.resource(obj), time.Now())
+
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *instrumentedClient) Patch(
+	ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption,
+) error {
+	defer recordAPIRequest(c.controller, "patch", c.resource(obj), time.Now())
+
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *instrumentedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	defer recordAPIRequest(c.controller, "delete", c.resource(obj), time.Now())
+
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *instrumentedClient) DeleteAllOf(
+	ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption,
+) error {
+	defer recordAPIRequest(c.controller, "deletecollection", c.resource(obj), time.Now())
+
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *instrumentedClient) Status() client.StatusWriter {
+	return &instrumentedSubResourceWriter{SubResourceWriter: c.Client.Status(), client: c, subResource: "status"}
+}
+
+func (c *instrumentedClient) SubResource(subResource string) client.SubResourceClient {
+	subResourceClient := c.Client.SubResource(subResource)
+
+	return &instrumentedSubResourceClient{
+		instrumentedSubResourceWriter: &instrumentedSubResourceWriter{
+			SubResourceWriter: subResourceClient, client: c, subResource: subResource,
+		},
+		reader: subResourceClient,
+	}
+}
+
+// instrumentedSubResourceWriter records the subresource requests of the controller, such as the status updates, in
+// the API request metrics. They're labeled with the subresource like the HTTP requests, such as "update" and
+// "configmaps/status".
+type instrumentedSubResourceWriter struct {
+	client.SubResourceWriter
+	client      *instrumentedClient
+	subResource string
+}
+
+func (w *instrumentedSubResourceWriter) resource(obj client.Object) string {
+	return w.client.resource(obj) + "/" + w.subResource
+}
+
+func (w *instrumentedSubResourceWriter) Create(
+	ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption,
+) error {
+	defer recordAPIRequest(w.client.controller, "create", w.resource(obj), time.Now())
+
+	return w.SubResourceWriter.Create(ctx, obj, subResource, opts...)
+}
+
+func (w *instrumentedSubResourceWriter) Update(
+	ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption,
+) error {
+	defer recordAPIRequest(w.client.controller, "update", w.resource(obj), time.Now())
+
+	return w.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+func (w *instrumentedSubResourceWriter) Patch(
+	ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption,
+) error {
+	defer recordAPIRequest(w.client.controller, "patch", w.resource(obj), time.Now())
+
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// instrumentedSubResourceClient also records the subresource reads, which aren't served from the manager cache.
+type instrumentedSubResourceClient struct {
+	*instrumentedSubResourceWriter
+	reader client.SubResourceReader
+}
+
+func (c *instrumentedSubResourceClient) Get(
+	ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption,
+) error {
+	defer recordAPIRequest(c.client.controller, "get", c.resource(obj), time.Now())
+
+	return c.reader.Get(ctx, obj, subResource, opts...)
+}
+
+// InstrumentConfig returns a copy of the config whose clients record the API requests of the controller in the API
+// request metrics. The requests are labeled with the resource from their URL.
+func InstrumentConfig(config *rest.Config, controller string) *rest.Config {
+	instrumented := rest.CopyConfig(config)
+	instrumented.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &instrumentedRoundTripper{delegate: rt, controller: controller}
+	})
+
+	return instrumented
+}
+
+// instrumentedRoundTripper records the API requests of the controller in the API request metrics.
+type instrumentedRoundTripper struct {
+	delegate   http.RoundTripper
+	controller string
+}
+
+func (rt *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb, resource := apiRequestVerbAndResource(req)
+
+	defer recordAPIRequest(rt.controller, verb, resource, time.Now())
+
+	return rt.delegate.RoundTrip(req)
+}
+
+// apiRequestVerbAndResource returns the verb and the resource of the API request from its method and URL, such as
+// "list" and "configmaps" for a GET request on /api/v1/namespaces/default/configmaps. The requests that aren't for a
+// resource, such as the discovery requests, have the "discovery" resource.
+func apiRequestVerbAndResource(req *http.Request) (string, string) {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	// Skip the API group and version: /api/<version> or /apis/<group>/<version>
+	switch {
+	case len(segments) > 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) > 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return strings.ToLower(req.Method), "discovery"
+	}
+
+	// Skip the namespace of the namespaced resources, but not of the namespaces themselves and their subresources
+	namespaceSubresource := len(segments) == 3 && (segments[2] == "status" || segments[2] == "finalize")
+	if len(segments) > 2 && segments[0] == "namespaces" && !namespaceSubresource {
+		segments = segments[2:]
+	}
+
+	resource := segments[0]
+	named := len(segments) > 1
+
+	if len(segments) > 2 {
+		resource += "/" + segments[2]
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		switch {
+		case req.URL.Query().Get("watch") == "true" || req.URL.Query().Get("watch") == "1":
+			return "watch", resource
+		case named:
+			return "get", resource
+		default:
+			return "list", resource
+		}
+	case http.MethodPost:
+		return "create", resource
+	case http.MethodPut:
+		return "update", resource
+	case http.MethodPatch:
+		return "patch", resource
+	case http.MethodDelete:
+		if named {
+			return "delete", resource
+		}
+
+		return "deletecollection", resource
+	default:
+		return strings.ToLower(req.Method), resource
+	}
+}
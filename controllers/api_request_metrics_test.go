@@ -0,0 +1,131 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAPIRequestVerbAndResource(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method           string
+		url              string
+		expectedVerb     string
+		expectedResource string
+	}{
+		"get":               {"GET", "/api/v1/namespaces/default/configmaps/cm", "get", "configmaps"},
+		"list":              {"GET", "/apis/apps/v1/namespaces/default/replicasets", "list", "replicasets"},
+		"list all":          {"GET", "/apis/apps/v1/deployments", "list", "deployments"},
+		"watch":             {"GET", "/api/v1/namespaces/default/configmaps?watch=true", "watch", "configmaps"},
+		"get namespace":     {"GET", "/api/v1/namespaces/default", "get", "namespaces"},
+		"namespace status":  {"PUT", "/api/v1/namespaces/default/status", "update", "namespaces/status"},
+		"create":            {"POST", "/api/v1/namespaces/default/events", "create", "events"},
+		"patch":             {"PATCH", "/apis/apps/v1/namespaces/default/deployments/app", "patch", "deployments"},
+		"subresource":       {"PUT", "/apis/apps/v1/namespaces/ns/deployments/app/status", "update", "deployments/status"},
+		"delete":            {"DELETE", "/api/v1/namespaces/default/configmaps/cm", "delete", "configmaps"},
+		"delete collection": {"DELETE", "/api/v1/namespaces/default/configmaps", "deletecollection", "configmaps"},
+		"discovery":         {"GET", "/apis", "get", "discovery"},
+		"version":           {"GET", "/version", "get", "discovery"},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, test.url, nil)
+
+			verb, resource := apiRequestVerbAndResource(req)
+			assert.Equal(t, test.expectedVerb, verb)
+			assert.Equal(t, test.expectedResource, resource)
+		})
+	}
+}
+
+func TestInstrumentClient(t *testing.T) {
+	t.Parallel()
+
+	c := InstrumentClient(fake.NewClientBuilder().Build(), "instrumented-client-test")
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+
+	assert.NoError(t, c.Create(context.TODO(), configMap))
+	assert.NoError(t, c.Status().Update(context.TODO(), configMap))
+	assert.NoError(t, c.SubResource("status").Patch(context.TODO(), configMap, client.MergeFrom(configMap.DeepCopy())))
+
+	// The reads of typed objects are served from the cache, so they aren't recorded
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(configMap), configMap))
+	assert.NoError(t, c.List(context.TODO(), &corev1.ConfigMapList{}))
+
+	unstructuredList := &unstructured.UnstructuredList{}
+	unstructuredList.SetAPIVersion("v1")
+	unstructuredList.SetKind("ConfigMapList")
+
+	assert.NoError(t, c.List(context.TODO(), unstructuredList))
+	assert.NoError(t, c.List(context.TODO(), unstructuredList))
+
+	// The requests are labeled with the resource like the HTTP requests
+	assert.Equal(
+		t, float64(1),
+		testutil.ToFloat64(apiRequestsCounter.WithLabelValues("create", "configmaps", "instrumented-client-test")),
+	)
+	assert.Equal(
+		t, float64(1),
+		testutil.ToFloat64(
+			apiRequestsCounter.WithLabelValues("update", "configmaps/status", "instrumented-client-test"),
+		),
+	)
+	assert.Equal(
+		t, float64(1),
+		testutil.ToFloat64(
+			apiRequestsCounter.WithLabelValues("patch", "configmaps/status", "instrumented-client-test"),
+		),
+	)
+	assert.Equal(
+		t, float64(0),
+		testutil.ToFloat64(apiRequestsCounter.WithLabelValues("get", "configmaps", "instrumented-client-test")),
+	)
+	assert.Equal(
+		t, float64(2),
+		testutil.ToFloat64(apiRequestsCounter.WithLabelValues("list", "configmaps", "instrumented-client-test")),
+	)
+}
+
+func TestInstrumentConfig(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	config := InstrumentConfig(&rest.Config{Host: server.URL}, "instrumented-config-test")
+
+	targetClient, err := kubernetes.NewForConfig(config)
+	assert.NoError(t, err)
+
+	_, err = targetClient.CoreV1().ConfigMaps("default").Get(context.TODO(), "cm", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t, float64(1),
+		testutil.ToFloat64(apiRequestsCounter.WithLabelValues("get", "configmaps", "instrumented-config-test")),
+	)
+}
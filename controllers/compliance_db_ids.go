@@ -0,0 +1,88 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+// complianceEventDBIDs returns the compliance database ID annotations for the compliance events of the policy. The
+// annotations are read again from the policy, since the hub may have updated them after the policy was retrieved for
+// the evaluation, such as when the parent policy was propagated again. The parent policy database ID on the parent
+// policy takes precedence over the copy on the policy, which can be stale. A warning is logged when the parent policy
+// has a database ID but the policy is missing its IDs, which means that they weren't synced to the policy.
+func complianceEventDBIDs(ctx context.Context, c client.Client, policy client.Object) map[string]string {
+	current, ok := policy.DeepCopyObject().(client.Object)
+	if !ok {
+		current = policy
+	} else if err := c.Get(ctx, client.ObjectKeyFromObject(policy), current); err != nil {
+		log.V(2).Info(
+			"Failed to get the policy to refresh its database IDs, using the annotations it was evaluated with",
+			"namespace", policy.GetNamespace(), "policy", policy.GetName(), "error", err.Error(),
+		)
+
+		current = policy
+	}
+
+	annotations := dbIDAnnotations(current.GetAnnotations())
+
+	parentDBID := parentPolicyDBID(ctx, c, current)
+	if parentDBID == "" {
+		return annotations
+	}
+
+	if annotations[common.ParentDBIDAnnotation] == "" || annotations[common.PolicyDBIDAnnotation] == "" {
+		log.Info(
+			"The parent policy has a compliance database ID but the policy is missing its database ID "+
+				"annotations, so they weren't synced from the hub",
+			"namespace", current.GetNamespace(), "policy", current.GetName(),
+			"parentPolicyDBID", parentDBID, "policyDBID", annotations[common.PolicyDBIDAnnotation],
+		)
+	} else if annotations[common.ParentDBIDAnnotation] != parentDBID {
+		log.V(1).Info(
+			"The parent policy database ID on the policy is stale, using the one on the parent policy",
+			"namespace", current.GetNamespace(), "policy", current.GetName(),
+			"staleParentPolicyDBID", annotations[common.ParentDBIDAnnotation], "parentPolicyDBID", parentDBID,
+		)
+	}
+
+	annotations[common.ParentDBIDAnnotation] = parentDBID
+
+	return annotations
+}
+
+// parentPolicyDBID returns the valid parent policy database ID annotation of the parent policy, which is the first
+// owner of the policy. An empty string is returned when the parent policy can't be retrieved or has no valid ID.
+func parentPolicyDBID(ctx context.Context, c client.Client, policy client.Object) string {
+	ownerRefs := policy.GetOwnerReferences()
+	if len(ownerRefs) == 0 {
+		return ""
+	}
+
+	parent := &unstructured.Unstructured{}
+	parent.SetAPIVersion(ownerRefs[0].APIVersion)
+	parent.SetKind(ownerRefs[0].Kind)
+
+	err := c.Get(ctx, types.NamespacedName{Namespace: policy.GetNamespace(), Name: ownerRefs[0].Name}, parent)
+	if err != nil {
+		log.V(2).Info(
+			"Failed to get the parent policy to refresh its database ID",
+			"namespace", policy.GetNamespace(), "parentPolicy", ownerRefs[0].Name, "error", err.Error(),
+		)
+
+		return ""
+	}
+
+	// The parent policy was recreated, so its database ID may not be for this policy yet
+	if ownerRefs[0].UID != "" && parent.GetUID() != ownerRefs[0].UID {
+		return ""
+	}
+
+	return dbIDAnnotations(parent.GetAnnotations())[common.ParentDBIDAnnotation]
+}
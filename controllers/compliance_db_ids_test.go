@@ -0,0 +1,102 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+func TestComplianceEventDBIDs(t *testing.T) {
+	t.Parallel()
+
+	s := runtime.NewScheme()
+	assert.NoError(t, policyv1.AddToScheme(s))
+
+	newParent := func(name string, parentDBID string) *unstructured.Unstructured {
+		parent := &unstructured.Unstructured{}
+		parent.SetAPIVersion("policy.open-cluster-management.io/v1")
+		parent.SetKind("Policy")
+		parent.SetName(name)
+		parent.SetNamespace("managed")
+		parent.SetUID("parent-uid")
+		parent.SetAnnotations(map[string]string{common.ParentDBIDAnnotation: parentDBID})
+
+		return parent
+	}
+
+	newPolicy := func(parentName string, annotations map[string]string) *policyv1.ConfigurationPolicy {
+		return &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "policy",
+				Namespace:   "managed",
+				Annotations: annotations,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "policy.open-cluster-management.io/v1",
+					Kind:       "Policy",
+					Name:       parentName,
+					UID:        "parent-uid",
+				}},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		evaluated *policyv1.ConfigurationPolicy
+		objects   []client.Object
+		expected  map[string]string
+	}{
+		"refreshed from the policy": {
+			evaluated: newPolicy("parent", nil),
+			objects: []client.Object{
+				newPolicy("parent", map[string]string{
+					common.ParentDBIDAnnotation: "124", common.PolicyDBIDAnnotation: "64",
+				}),
+				newParent("parent", "124"),
+			},
+			expected: map[string]string{common.ParentDBIDAnnotation: "124", common.PolicyDBIDAnnotation: "64"},
+		},
+		"stale parent ID": {
+			evaluated: newPolicy("parent", nil),
+			objects: []client.Object{
+				newPolicy("parent", map[string]string{
+					common.ParentDBIDAnnotation: "124", common.PolicyDBIDAnnotation: "64",
+				}),
+				newParent("parent", "125"),
+			},
+			expected: map[string]string{common.ParentDBIDAnnotation: "125", common.PolicyDBIDAnnotation: "64"},
+		},
+		"missing IDs": {
+			evaluated: newPolicy("parent", nil),
+			objects:   []client.Object{newPolicy("parent", nil), newParent("parent", "")},
+			expected:  map[string]string{},
+		},
+		"policy not found": {
+			evaluated: newPolicy("missing-parent", map[string]string{common.PolicyDBIDAnnotation: "64"}),
+			objects:   []client.Object{},
+			expected:  map[string]string{common.PolicyDBIDAnnotation: "64"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := fake.NewClientBuilder().WithScheme(s).WithObjects(test.objects...).Build()
+
+			assert.Equal(t, test.expected, complianceEventDBIDs(context.TODO(), c, test.evaluated))
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
+)
+
+// policyRemovedAction is the action of the final compliance event emitted when a policy is deleted.
+const policyRemovedAction = "PolicyRemoved"
+
+// removedPolicyPredicate returns a predicate that records the last known state of each deleted policy, which its
+// removal compliance event is built from. It doesn't filter out any events.
+func removedPolicyPredicate(cache *complianceEventCache) predicate.Funcs {
+	return predicate.Funcs{
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			if policy, ok := e.Object.DeepCopyObject().(client.Object); ok {
+				cache.removed.Store(policy.GetNamespace()+"/"+policy.GetName(), policy)
+			}
+
+			return true
+		},
+	}
+}
+
+// recordComplianceEventPruned records that the objects of the deleted policy were pruned, which is stated in its
+// removal compliance event.
+func recordComplianceEventPruned(cache *complianceEventCache, namespace string, name string) {
+	cache.pruned.Store(namespace+"/"+name, true)
+}
+
+// removedPolicyDetails returns the group, version, and kind and the last reported compliance of the deleted policy.
+// The group, version, and kind are set from the type since the objects from the cache don't have them.
+func removedPolicyDetails(policy client.Object) (schema.GroupVersionKind, policyv1.ComplianceState) {
+	var gvk schema.GroupVersionKind
+	var compliance policyv1.ComplianceState
+
+	switch typedPolicy := policy.(type) {
+	case *policyv1.ConfigurationPolicy:
+		gvk = policyv1.GroupVersion.WithKind("ConfigurationPolicy")
+		compliance = typedPolicy.Status.ComplianceState
+	case *policyv1beta1.OperatorPolicy:
+		gvk = policyv1beta1.GroupVersion.WithKind("OperatorPolicy")
+		compliance = typedPolicy.Status.ComplianceState
+	}
+
+	if compliance == "" {
+		compliance = policyv1.UnknownCompliancy
+	}
+
+	return gvk, compliance
+}
+
+// newRemovalComplianceEvent returns the final compliance event of the deleted policy. It's built from the identity,
+// the parent policy owner reference, and the last reported compliance of the policy, so it doesn't depend on the
+// compliance events emitted by this instance of the controller. Like every compliance event, the message starts with
+// the compliance so that it's parsed the same way: "<Compliance>; the policy was removed and the objects it created
+// were (not) pruned". It returns nil when the policy has no parent policy.
+func newRemovalComplianceEvent(
+	cache *complianceEventCache, policy client.Object, dbIDs map[string]string, pruned bool, instanceName string,
+) *corev1.Event {
+	ownerRefs := policy.GetOwnerReferences()
+	if len(ownerRefs) == 0 {
+		return nil
+	}
+
+	// The parent policy is the first owner, like in the other compliance events
+	ownerRef := ownerRefs[0]
+	gvk, compliance := removedPolicyDetails(policy)
+	reason := fmt.Sprintf(eventFmtStr, policy.GetNamespace(), policy.GetName())
+	now := complianceEventTime(cache, policy.GetNamespace(), reason)
+
+	cleanup := "not pruned"
+	if pruned {
+		cleanup = "pruned"
+	}
+
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// This event name matches the convention of recorders from client-go
+			Name:        fmt.Sprintf("%v.%x", ownerRef.Name, now.UnixNano()),
+			Namespace:   policy.GetNamespace(),
+			Annotations: complianceEventAnnotations(policy, dbIDs, compliance, compliantConditionType),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       ownerRef.Kind,
+			Namespace:  policy.GetNamespace(), // k8s ensures owners are always in the same namespace
+			Name:       ownerRef.Name,
+			UID:        ownerRef.UID,
+			APIVersion: ownerRef.APIVersion,
+		},
+		Reason: reason,
+		Message: fmt.Sprintf(
+			"%s; the policy was removed and the objects it created were %s", compliance, cleanup,
+		),
+		Source: corev1.EventSource{
+			Component: ControllerName,
+			Host:      instanceName,
+		},
+		FirstTimestamp: metav1.NewTime(now),
+		LastTimestamp:  metav1.NewTime(now),
+		Count:          1,
+		Type:           "Normal",
+		Action:         policyRemovedAction,
+		Related: &corev1.ObjectReference{
+			Kind:       gvk.Kind,
+			Namespace:  policy.GetNamespace(),
+			Name:       policy.GetName(),
+			UID:        policy.GetUID(),
+			APIVersion: gvk.GroupVersion().String(),
+		},
+		ReportingController: ControllerName,
+		ReportingInstance:   instanceName,
+	}
+}
+
+// sendRemovalComplianceEvent emits the final compliance event of the deleted policy, which states its last reported
+// compliance, that it was removed, and whether the objects it created were pruned. The policy is the one recorded by
+// the removedPolicyPredicate when it was deleted, so nothing is emitted if the deletion wasn't observed. Since the
+// policy is gone, a failure is only logged.
+func sendRemovalComplianceEvent(
+	ctx context.Context,
+	c client.Client,
+	cache *complianceEventCache,
+	mode ComplianceEventsMode,
+	instanceName string,
+	namespace string,
+	name string,
+) {
+	_, pruned := cache.pruned.LoadAndDelete(namespace + "/" + name)
+
+	loaded, ok := cache.removed.LoadAndDelete(namespace + "/" + name)
+	if !ok {
+		return
+	}
+
+	policy := loaded.(client.Object)
+
+	removalEvent := newRemovalComplianceEvent(
+		cache, policy, complianceEventDBIDs(ctx, c, policy), pruned, instanceName,
+	)
+	if removalEvent == nil || !mode.createsEvent(removalEvent) {
+		return
+	}
+
+	err := createComplianceEventWithRetries(ctx, c, cache, 0, complianceEventBackoff, removalEvent)
+	if err != nil {
+		log.Error(
+			err, "Failed to emit the compliance event of the removed policy",
+			"namespace", namespace, "policy", name,
+		)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+func TestSendRemovalComplianceEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pruned          bool
+		mode            ComplianceEventsMode
+		expectedMessage string
+	}{
+		"not pruned": {
+			false, ComplianceEventsEnabled,
+			"NonCompliant; the policy was removed and the objects it created were not pruned",
+		},
+		"pruned": {
+			true, ComplianceEventsEnabled,
+			"NonCompliant; the policy was removed and the objects it created were pruned",
+		},
+		"disabled": {false, ComplianceEventsDisabled, ""},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := runtime.NewScheme()
+			assert.NoError(t, policyv1.AddToScheme(s))
+			assert.NoError(t, eventsv1.AddToScheme(s))
+
+			c := fake.NewClientBuilder().WithScheme(s).Build()
+			cache := &complianceEventCache{}
+
+			policy := &policyv1.ConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "policy",
+					Namespace:   "managed",
+					UID:         "policy-uid",
+					Generation:  2,
+					Annotations: map[string]string{common.PolicyDBIDAnnotation: "5", "other": "annotation"},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "policy.open-cluster-management.io/v1",
+						Kind:       "Policy",
+						Name:       "parent",
+						UID:        "parent-uid",
+					}},
+				},
+				Status: policyv1.ConfigurationPolicyStatus{ComplianceState: policyv1.NonCompliant},
+			}
+
+			// Nothing is emitted when the deletion of the policy wasn't observed
+			sendRemovalComplianceEvent(context.TODO(), c, cache, test.mode, "controller", "managed", "policy")
+
+			if test.pruned {
+				recordComplianceEventPruned(cache, "managed", "policy")
+			}
+
+			assert.True(t, removedPolicyPredicate(cache).Delete(event.DeleteEvent{Object: policy}))
+
+			sendRemovalComplianceEvent(context.TODO(), c, cache, test.mode, "controller", "managed", "policy")
+
+			// The removal details are only used once
+			_, removed := cache.removed.Load("managed/policy")
+			assert.False(t, removed)
+
+			_, pruned := cache.pruned.Load("managed/policy")
+			assert.False(t, pruned)
+
+			events := &eventsv1.EventList{}
+			assert.NoError(t, c.List(context.TODO(), events))
+
+			if test.expectedMessage == "" {
+				assert.Empty(t, events.Items)
+
+				return
+			}
+
+			if assert.Len(t, events.Items, 1) {
+				removalEvent := events.Items[0]
+
+				assert.Equal(t, test.expectedMessage, removalEvent.Note)
+				assert.Equal(t, policyRemovedAction, removalEvent.Action)
+				assert.Equal(t, "Normal", removalEvent.Type)
+				assert.Equal(t, "parent", removalEvent.Regarding.Name)
+				assert.Equal(t, "ConfigurationPolicy", removalEvent.Related.Kind)
+				assert.Equal(t, "policy.open-cluster-management.io/v1", removalEvent.Related.APIVersion)
+				assert.Equal(t, "policy-uid", string(removalEvent.Related.UID))
+				assert.Equal(t, "policy: managed/policy", removalEvent.Reason)
+				assert.Equal(t, "controller", removalEvent.ReportingInstance)
+				assert.Equal(
+					t,
+					map[string]string{
+						common.PolicyDBIDAnnotation:       "5",
+						common.PolicyGenerationAnnotation: "2",
+						common.ComplianceStateAnnotation:  "NonCompliant",
+						common.ConditionTypeAnnotation:    compliantConditionType,
+					},
+					removalEvent.Annotations,
+				)
+			}
+		})
+	}
+}
+
+func TestNewRemovalComplianceEventNoParent(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}}
+
+	assert.Nil(t, newRemovalComplianceEvent(&complianceEventCache{}, policy, nil, false, "controller"))
+}
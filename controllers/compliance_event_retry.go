@@ -0,0 +1,173 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxPendingComplianceEvents is the maximum number of compliance events of a policy queued to be resent, beyond which
+// the oldest ones are dropped.
+const maxPendingComplianceEvents = 10
+
+// complianceEventBackoff is the backoff between the attempts to create a compliance event that failed with a
+// transient error, such as the API server throttling the requests.
+var complianceEventBackoff = wait.Backoff{
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    4,
+	Cap:      2 * time.Second,
+}
+
+var errComplianceEventsPending = errors.New(
+	"the compliance event was queued behind the compliance events of the policy that couldn't be created",
+)
+
+// pendingComplianceEvents are the compliance events of a policy that couldn't be created, in the order they were
+// emitted.
+type pendingComplianceEvents struct {
+	lock   sync.Mutex
+	events []*corev1.Event
+}
+
+// complianceEventPermanentError returns whether the compliance event failed to be created in a way that retrying
+// won't fix, such as the namespace being deleted or the controller not being allowed to create events.
+func complianceEventPermanentError(err error) bool {
+	return k8serrors.IsForbidden(err) || k8serrors.IsNotFound(err) || k8serrors.IsInvalid(err) ||
+		k8serrors.IsBadRequest(err)
+}
+
+// complianceEventPolicy returns the namespace/name of the policy of the compliance event.
+func complianceEventPolicy(event *corev1.Event) string {
+	if event.Related == nil {
+		return event.Namespace + "/"
+	}
+
+	return event.Namespace + "/" + event.Related.Name
+}
+
+// createComplianceEventWithRetries calls createComplianceEvent and retries the transient failures with the backoff.
+// The permanent failures aren't retried and the event is counted as dropped. An event that still fails after the
+// retries is counted in the failed compliance events metric.
+func createComplianceEventWithRetries(
+	ctx context.Context,
+	c client.Client,
+	cache *complianceEventCache,
+	dedupWindow time.Duration,
+	backoff wait.Backoff,
+	event *corev1.Event,
+) error {
+	retriable := func(err error) bool {
+		return ctx.Err() == nil && !complianceEventPermanentError(err)
+	}
+
+	err := retry.OnError(backoff, retriable, func() error {
+		return createComplianceEvent(ctx, c, cache, dedupWindow, event)
+	})
+	if err != nil {
+		failedComplianceEventsCounter.WithLabelValues(complianceEventPolicy(event)).Inc()
+
+		if complianceEventPermanentError(err) {
+			droppedComplianceEventsCounter.WithLabelValues(complianceEventPolicy(event)).Inc()
+		}
+	}
+
+	return err
+}
+
+// createOrQueueComplianceEvent creates the compliance event with createComplianceEventWithRetries. When the retries
+// are exhausted, the event is queued to be resent by resendComplianceEvents on the next reconcile, which the returned
+// error triggers. An event of a policy with queued events is queued behind them so that the events stay in order.
+func createOrQueueComplianceEvent(
+	ctx context.Context,
+	c client.Client,
+	cache *complianceEventCache,
+	dedupWindow time.Duration,
+	backoff wait.Backoff,
+	event *corev1.Event,
+) error {
+	loaded, _ := cache.pending.LoadOrStore(event.Namespace+"/"+event.Reason, &pendingComplianceEvents{})
+	pending := loaded.(*pendingComplianceEvents)
+
+	pending.lock.Lock()
+	defer pending.lock.Unlock()
+
+	if len(pending.events) != 0 {
+		pending.queue(event)
+
+		return errComplianceEventsPending
+	}
+
+	err := createComplianceEventWithRetries(ctx, c, cache, dedupWindow, backoff, event)
+	if err != nil && !complianceEventPermanentError(err) {
+		pending.queue(event)
+
+		return fmt.Errorf("the compliance event was queued to be resent: %w", err)
+	}
+
+	return err
+}
+
+// queue appends the event to the pending events, dropping the oldest event when there are already
+// maxPendingComplianceEvents. The lock must be held.
+func (p *pendingComplianceEvents) queue(event *corev1.Event) {
+	if len(p.events) >= maxPendingComplianceEvents {
+		droppedComplianceEventsCounter.WithLabelValues(complianceEventPolicy(p.events[0])).Inc()
+
+		p.events = p.events[1:]
+	}
+
+	p.events = append(p.events, event)
+}
+
+// resendComplianceEvents resends the queued compliance events of the policy in order. When an event still fails with
+// a transient error, it and the following events stay queued and the error is returned. The events that fail with a
+// permanent error are dropped.
+func resendComplianceEvents(
+	ctx context.Context,
+	c client.Client,
+	cache *complianceEventCache,
+	dedupWindow time.Duration,
+	backoff wait.Backoff,
+	namespace string,
+	name string,
+) error {
+	loaded, ok := cache.pending.Load(namespace + "/" + fmt.Sprintf(eventFmtStr, namespace, name))
+	if !ok {
+		return nil
+	}
+
+	pending := loaded.(*pendingComplianceEvents)
+
+	pending.lock.Lock()
+	defer pending.lock.Unlock()
+
+	for len(pending.events) != 0 {
+		err := createComplianceEventWithRetries(ctx, c, cache, dedupWindow, backoff, pending.events[0])
+		if err != nil && !complianceEventPermanentError(err) {
+			return fmt.Errorf("failed to resend the queued compliance events: %w", err)
+		}
+
+		if err != nil {
+			log.Error(
+				err, "Dropping the queued compliance event that can't be created",
+				"namespace", namespace, "policy", name,
+			)
+		}
+
+		pending.events = pending.events[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+var testComplianceEventBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3}
+
+// failingEventClient fails to create the events with err the next failures times. When createOnFailure is set, the
+// events are still created, like when the response of a request that timed out is lost.
+type failingEventClient struct {
+	client.Client
+	failures        int
+	err             error
+	createOnFailure bool
+	attempts        int
+}
+
+func (c *failingEventClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.attempts++
+
+	if c.failures > 0 {
+		c.failures--
+
+		if c.createOnFailure {
+			if err := c.Client.Create(ctx, obj.DeepCopyObject().(client.Object), opts...); err != nil {
+				return err
+			}
+		}
+
+		return c.err
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func newTestPolicyComplianceEvent(name string, timestamp time.Time, message string) *corev1.Event {
+	event := newTestComplianceEvent(name, timestamp, "policy: managed/policy", message)
+	event.Related = &corev1.ObjectReference{Kind: "OperatorPolicy", Namespace: "managed", Name: "policy"}
+
+	return event
+}
+
+func TestCreateComplianceEventWithRetries(t *testing.T) {
+	t.Parallel()
+
+	throttled := k8serrors.NewTooManyRequests("throttled", 1)
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "events"}, "", nil)
+
+	timeout := k8serrors.NewTimeoutError("timed out", 1)
+
+	tests := map[string]struct {
+		failures         int
+		err              error
+		createOnFailure  bool
+		expectedAttempts int
+		expectedEvents   int
+	}{
+		"transient failure recovered": {2, throttled, false, 3, 1},
+		"transient failure exhausted": {3, throttled, false, 3, 0},
+		"permanent failure":           {3, forbidden, false, 1, 0},
+		"created before a timeout":    {1, timeout, true, 2, 1},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &failingEventClient{
+				Client:          fake.NewClientBuilder().Build(),
+				failures:        test.failures,
+				err:             test.err,
+				createOnFailure: test.createOnFailure,
+			}
+
+			err := createComplianceEventWithRetries(
+				context.TODO(), c, &complianceEventCache{}, 0, testComplianceEventBackoff,
+				newTestPolicyComplianceEvent("parent.1", time.Now(), "NonCompliant; violation"),
+			)
+			if test.expectedEvents == 0 {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedAttempts, c.attempts)
+
+			events := &eventsv1.EventList{}
+			assert.NoError(t, c.List(context.TODO(), events))
+			assert.Len(t, events.Items, test.expectedEvents)
+		})
+	}
+}
+
+func TestCreateOrQueueComplianceEvent(t *testing.T) {
+	t.Parallel()
+
+	c := &failingEventClient{
+		Client: fake.NewClientBuilder().Build(), failures: 3, err: k8serrors.NewTooManyRequests("throttled", 1),
+	}
+	cache := &complianceEventCache{}
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// The retries are exhausted, so the event is queued
+	err := createOrQueueComplianceEvent(
+		context.TODO(), c, cache, 0, testComplianceEventBackoff,
+		newTestPolicyComplianceEvent("parent.1", start, "NonCompliant; violation"),
+	)
+	assert.Error(t, err)
+
+	// The next event is queued behind it without being attempted
+	err = createOrQueueComplianceEvent(
+		context.TODO(), c, cache, 0, testComplianceEventBackoff,
+		newTestPolicyComplianceEvent("parent.2", start.Add(time.Minute), "Compliant; notification"),
+	)
+	assert.ErrorIs(t, err, errComplianceEventsPending)
+	assert.Equal(t, 3, c.attempts)
+
+	// The queued events are resent on the next reconcile
+	err = resendComplianceEvents(context.TODO(), c, cache, 0, testComplianceEventBackoff, "managed", "policy")
+	assert.NoError(t, err)
+
+	events := &eventsv1.EventList{}
+	assert.NoError(t, c.List(context.TODO(), events))
+
+	notes := map[string]string{}
+
+	for _, event := range events.Items {
+		notes[event.Name] = event.Note
+	}
+
+	assert.Equal(t, map[string]string{"parent.1": "NonCompliant; violation", "parent.2": "Compliant; notification"}, notes)
+
+	// Nothing is left to resend
+	err = resendComplianceEvents(context.TODO(), c, cache, 0, testComplianceEventBackoff, "managed", "policy")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, c.attempts)
+}
+
+func TestPendingComplianceEventsLimit(t *testing.T) {
+	t.Parallel()
+
+	pending := &pendingComplianceEvents{}
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for i := 0; i <= maxPendingComplianceEvents; i++ {
+		timestamp := start.Add(time.Duration(i) * time.Minute)
+		pending.queue(newTestPolicyComplianceEvent("parent."+timestamp.String(), timestamp, "NonCompliant"))
+	}
+
+	// The oldest event is dropped
+	if assert.Len(t, pending.events, maxPendingComplianceEvents) {
+		assert.True(t, start.Add(time.Minute).Equal(pending.events[0].LastTimestamp.Time))
+	}
+}
+
+func TestComplianceEventMetrics(t *testing.T) {
+	t.Parallel()
+
+	c := &failingEventClient{Client: fake.NewClientBuilder().Build()}
+
+	newEvent := func(name string, message string) *corev1.Event {
+		event := newTestPolicyComplianceEvent(name, time.Now(), message)
+		event.Namespace = "metrics-ns"
+		event.Annotations[common.ComplianceStateAnnotation] = "NonCompliant"
+
+		return event
+	}
+
+	err := createComplianceEventWithRetries(
+		context.TODO(), c, &complianceEventCache{}, 0, testComplianceEventBackoff,
+		newEvent("parent.1", "NonCompliant; violation"),
+	)
+	assert.NoError(t, err)
+
+	c.failures = 3
+	c.err = k8serrors.NewTooManyRequests("throttled", 1)
+
+	err = createComplianceEventWithRetries(
+		context.TODO(), c, &complianceEventCache{}, 0, testComplianceEventBackoff,
+		newEvent("parent.2", "NonCompliant; another violation"),
+	)
+	assert.Error(t, err)
+
+	assert.Equal(
+		t, float64(1),
+		testutil.ToFloat64(emittedComplianceEventsCounter.WithLabelValues("metrics-ns/policy", "NonCompliant")),
+	)
+	assert.Equal(t, float64(1), testutil.ToFloat64(failedComplianceEventsCounter.WithLabelValues("metrics-ns/policy")))
+
+	// A repeat within the deduplication window is suppressed
+	cache := &complianceEventCache{}
+
+	for _, name := range []string{"parent.3", "parent.4"} {
+		err = createComplianceEventWithRetries(
+			context.TODO(), c, cache, time.Hour, testComplianceEventBackoff, newEvent(name, "NonCompliant; violation"),
+		)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(
+		t, float64(1), testutil.ToFloat64(suppressedComplianceEventsCounter.WithLabelValues("metrics-ns/policy")),
+	)
+
+	// The series of a deleted policy are removed
+	forgetComplianceEvents(&complianceEventCache{}, "metrics-ns", "policy")
+
+	assert.Equal(
+		t, float64(0),
+		testutil.ToFloat64(emittedComplianceEventsCounter.WithLabelValues("metrics-ns/policy", "NonCompliant")),
+	)
+	assert.Equal(t, float64(0), testutil.ToFloat64(failedComplianceEventsCounter.WithLabelValues("metrics-ns/policy")))
+	assert.Equal(
+		t, float64(0), testutil.ToFloat64(suppressedComplianceEventsCounter.WithLabelValues("metrics-ns/policy")),
+	)
+}
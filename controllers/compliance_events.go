@@ -0,0 +1,441 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+const (
+	// The maximum lengths of the reason and the note of an events.k8s.io/v1 event
+	eventsV1ReasonLimit = 128
+	eventsV1NoteLimit   = 1024
+	// complianceEventSeriesWindow is how long after its last occurrence a repeated compliance event is counted in the
+	// series of the existing event rather than creating a new event, which matches the client-go events library.
+	complianceEventSeriesWindow = 6 * time.Minute
+	// truncatedEventMessageSuffix is appended to the compliance event messages that are truncated
+	truncatedEventMessageSuffix = "…(truncated; see the policy status for full details)"
+)
+
+// ComplianceEventsMode is how the compliance events of the policies are emitted.
+type ComplianceEventsMode string
+
+const (
+	// ComplianceEventsEnabled creates the compliance events as Kubernetes events, which is the default.
+	ComplianceEventsEnabled ComplianceEventsMode = "enabled"
+	// ComplianceEventsDisabled doesn't emit the compliance events at all.
+	ComplianceEventsDisabled ComplianceEventsMode = "disabled"
+	// ComplianceEventsLogOnly writes the compliance events to the controller log instead of creating Kubernetes
+	// events.
+	ComplianceEventsLogOnly ComplianceEventsMode = "log-only"
+)
+
+// ParseComplianceEventsMode returns the ComplianceEventsMode of the value, or an error if it isn't a valid mode.
+func ParseComplianceEventsMode(value string) (ComplianceEventsMode, error) {
+	switch mode := ComplianceEventsMode(value); mode {
+	case ComplianceEventsEnabled, ComplianceEventsDisabled, ComplianceEventsLogOnly:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"the compliance events mode must be %s, %s, or %s: %s",
+			ComplianceEventsEnabled, ComplianceEventsDisabled, ComplianceEventsLogOnly, value,
+		)
+	}
+}
+
+// createsEvent returns whether the compliance event is created as a Kubernetes event in the mode. In the log-only
+// mode, the event is written to the log instead. An empty mode is the same as ComplianceEventsEnabled.
+func (m ComplianceEventsMode) createsEvent(event *corev1.Event) bool {
+	switch m {
+	case ComplianceEventsDisabled:
+		return false
+	case ComplianceEventsLogOnly:
+		log.Info(
+			"Compliance event",
+			"namespace", event.Namespace,
+			"involvedObject", event.InvolvedObject.Kind+"/"+event.InvolvedObject.Name,
+			"reason", event.Reason,
+			"type", event.Type,
+			"message", event.Message,
+			"annotations", event.Annotations,
+		)
+
+		return false
+	default:
+		return true
+	}
+}
+
+// EventRecorder returns the event recorder wrapped so that the other events emitted on the policies through it, such
+// as the status and the policy conflict events, follow the mode like the compliance events.
+func (m ComplianceEventsMode) EventRecorder(recorder record.EventRecorder) record.EventRecorder {
+	if m == "" || m == ComplianceEventsEnabled {
+		return recorder
+	}
+
+	return &modeEventRecorder{EventRecorder: recorder, mode: m}
+}
+
+// modeEventRecorder drops the events in the ComplianceEventsDisabled mode and writes them to the log in the
+// ComplianceEventsLogOnly mode.
+type modeEventRecorder struct {
+	record.EventRecorder
+	mode ComplianceEventsMode
+}
+
+func (r *modeEventRecorder) Event(object runtime.Object, eventtype string, reason string, message string) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+func (r *modeEventRecorder) Eventf(
+	object runtime.Object, eventtype string, reason string, messageFmt string, args ...interface{},
+) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+func (r *modeEventRecorder) AnnotatedEventf(
+	object runtime.Object,
+	annotations map[string]string,
+	eventtype string,
+	reason string,
+	messageFmt string,
+	args ...interface{},
+) {
+	if r.mode != ComplianceEventsLogOnly {
+		return
+	}
+
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return
+	}
+
+	log.Info(
+		"Event",
+		"namespace", accessor.GetNamespace(),
+		"name", accessor.GetName(),
+		"reason", reason,
+		"type", eventtype,
+		"message", fmt.Sprintf(messageFmt, args...),
+		"annotations", annotations,
+	)
+}
+
+// complianceEventCache tracks the compliance events emitted for the policies.
+type complianceEventCache struct {
+	// series is keyed by the event and its values are *complianceEventSeries.
+	series sync.Map
+	// last has the namespace and reason of the events, which identify the policy, as the key and the values are the
+	// *lastComplianceEvent of the policy.
+	last sync.Map
+	// pending has the same keys as last and the values are the *pendingComplianceEvents of the policy.
+	pending sync.Map
+	// timestamps has the same keys as last and the values are the *complianceEventTimestamp of the policy.
+	timestamps sync.Map
+	// removed has the namespace and name of the deleted policies as the key and the values are the client.Object of
+	// the policy when it was deleted. See removedPolicyPredicate.
+	removed sync.Map
+	// pruned has the same keys as removed and the values are true when the objects of the policy were pruned.
+	pruned sync.Map
+}
+
+// complianceEventTimestamp is the timestamp of the last compliance event of a policy.
+type complianceEventTimestamp struct {
+	lock sync.Mutex
+	last time.Time
+}
+
+// lastComplianceEvent is the last compliance event emitted for a policy.
+type lastComplianceEvent struct {
+	lock       sync.Mutex
+	eventType  string
+	message    string
+	generation string
+	emitted    time.Time
+}
+
+// complianceEventSeries is the last compliance event created for a policy generation with a given reason, type, and
+// message, whose repeats are counted in its series.
+type complianceEventSeries struct {
+	lock         sync.Mutex
+	name         string
+	count        int32
+	lastObserved time.Time
+}
+
+// createComplianceEvent creates the compliance event with the events.k8s.io/v1 API. An exact repeat of the last
+// compliance event of the policy within the dedupWindow isn't emitted at all. Otherwise, when the same compliance
+// event was created for the policy within the complianceEventSeriesWindow, the series of that event is incremented
+// instead, but only when that event is still the last compliance event of the policy so that the order of the
+// compliance history is kept. The deprecated fields of the event must not be set with the events.k8s.io/v1 API, and
+// the API server derives the core/v1 view of the event from the other fields. An event whose reason or message is too
+// long for the events.k8s.io/v1 API is created as a core/v1 event as before so that its message isn't truncated.
+func createComplianceEvent(
+	ctx context.Context,
+	c client.Client,
+	cache *complianceEventCache,
+	dedupWindow time.Duration,
+	event *corev1.Event,
+) error {
+	if complianceEventRepeated(cache, dedupWindow, event) {
+		suppressedComplianceEventsCounter.WithLabelValues(complianceEventPolicy(event)).Inc()
+
+		return nil
+	}
+
+	// Since the names of the compliance events are unique, an event that already exists was created by a previous
+	// attempt whose response was lost, such as when the request timed out
+	if len(event.Reason) > eventsV1ReasonLimit || len(event.Message) > eventsV1NoteLimit {
+		if err := c.Create(ctx, event); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return err
+		}
+
+		complianceEventEmitted(cache, event)
+
+		return nil
+	}
+
+	note := event.Message
+
+	reportingInstance := event.ReportingInstance
+	if reportingInstance == "" {
+		reportingInstance = event.ReportingController
+	}
+
+	now := event.LastTimestamp.Time
+	pruneComplianceEventSeries(&cache.series, now)
+
+	key := string(event.InvolvedObject.UID) + "/" + event.Reason + "/" + event.Type + "/" +
+		event.Annotations[common.PolicyGenerationAnnotation] + "/" + note
+	loaded, _ := cache.series.LoadOrStore(key, &complianceEventSeries{})
+	series := loaded.(*complianceEventSeries)
+
+	series.lock.Lock()
+	defer series.lock.Unlock()
+
+	// A series is only extended while its event is the last compliance event of the policy, so a policy that flaps
+	// back to a previous state gets a new event
+	isLast, _ := matchesLastComplianceEvent(cache, event)
+
+	if series.name != "" && now.Sub(series.lastObserved) < complianceEventSeriesWindow && isLast {
+		count := series.count + 1
+
+		patch, err := json.Marshal(map[string]interface{}{
+			"series": eventsv1.EventSeries{Count: count, LastObservedTime: metav1.NewMicroTime(now)},
+		})
+		if err != nil {
+			return err
+		}
+
+		existing := &eventsv1.Event{ObjectMeta: metav1.ObjectMeta{Name: series.name, Namespace: event.Namespace}}
+
+		err = c.Patch(ctx, existing, client.RawPatch(types.MergePatchType, patch))
+		if err == nil {
+			series.count = count
+			series.lastObserved = now
+
+			complianceEventEmitted(cache, event)
+
+			return nil
+		}
+
+		// The event expired or was deleted, so a new event is created
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	newEvent := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        event.Name,
+			Namespace:   event.Namespace,
+			Annotations: event.Annotations,
+		},
+		EventTime:           metav1.NewMicroTime(now),
+		ReportingController: event.ReportingController,
+		ReportingInstance:   reportingInstance,
+		Action:              event.Action,
+		Reason:              event.Reason,
+		Regarding:           event.InvolvedObject,
+		Related:             event.Related,
+		Note:                note,
+		Type:                event.Type,
+	}
+
+	if err := c.Create(ctx, newEvent); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	series.name = newEvent.Name
+	series.count = 1
+	series.lastObserved = now
+
+	complianceEventEmitted(cache, event)
+
+	return nil
+}
+
+// complianceEventTime returns the timestamp of the next compliance event of the policy, which is the current time
+// truncated to the microsecond precision of the events.k8s.io/v1 API, or a microsecond after the previous compliance
+// event of the policy when the current time isn't later. This keeps the compliance events emitted in quick
+// succession, such as the early compliance events of an OperatorPolicy and its final compliance event, in
+// chronological order.
+func complianceEventTime(cache *complianceEventCache, namespace string, reason string) time.Time {
+	loaded, _ := cache.timestamps.LoadOrStore(namespace+"/"+reason, &complianceEventTimestamp{})
+	timestamp := loaded.(*complianceEventTimestamp)
+
+	timestamp.lock.Lock()
+	defer timestamp.lock.Unlock()
+
+	now := time.Now().Truncate(time.Microsecond)
+	if !now.After(timestamp.last) {
+		now = timestamp.last.Add(time.Microsecond)
+	}
+
+	timestamp.last = now
+
+	return now
+}
+
+// truncateComplianceEventMessage returns the compliance event message shortened to at most maxLength bytes, cut at a
+// word boundary when possible, with truncatedEventMessageSuffix appended when it was shortened. The full message is
+// still in the conditions of the policy. A maxLength of zero or less disables the truncation.
+func truncateComplianceEventMessage(message string, maxLength int) string {
+	if maxLength <= 0 || len(message) <= maxLength {
+		return message
+	}
+
+	truncatedLength := maxLength - len(truncatedEventMessageSuffix)
+	if truncatedLength < 0 {
+		truncatedLength = 0
+	}
+
+	truncated := truncateString(message, truncatedLength)
+
+	if wordEnd := strings.LastIndexByte(truncated, ' '); wordEnd > 0 {
+		truncated = truncated[:wordEnd]
+	}
+
+	return strings.TrimRight(truncated, " ,;") + truncatedEventMessageSuffix
+}
+
+// complianceEventRepeated returns whether the event is the same as the last compliance event emitted for the policy
+// generation within the dedupWindow, in which case it shouldn't be emitted. A dedupWindow of zero or less disables the
+// deduplication.
+func complianceEventRepeated(cache *complianceEventCache, dedupWindow time.Duration, event *corev1.Event) bool {
+	if dedupWindow <= 0 {
+		return false
+	}
+
+	matches, emitted := matchesLastComplianceEvent(cache, event)
+
+	return matches && event.LastTimestamp.Time.Sub(emitted) < dedupWindow
+}
+
+// matchesLastComplianceEvent determines if the event has the same type, message, and policy generation as the last
+// compliance event emitted for the policy, and returns when that event was emitted.
+func matchesLastComplianceEvent(cache *complianceEventCache, event *corev1.Event) (bool, time.Time) {
+	loaded, ok := cache.last.Load(event.Namespace + "/" + event.Reason)
+	if !ok {
+		return false, time.Time{}
+	}
+
+	last := loaded.(*lastComplianceEvent)
+
+	last.lock.Lock()
+	defer last.lock.Unlock()
+
+	matches := last.eventType == event.Type && last.message == event.Message &&
+		last.generation == event.Annotations[common.PolicyGenerationAnnotation]
+
+	return matches, last.emitted
+}
+
+// complianceEventEmitted records the event that was just created, or counted in the series of an existing event, as the
+// last compliance event of the policy and counts it in the emitted compliance events metric.
+func complianceEventEmitted(cache *complianceEventCache, event *corev1.Event) {
+	recordLastComplianceEvent(cache, event)
+
+	compliance := event.Annotations[common.ComplianceStateAnnotation]
+	if compliance == "" {
+		compliance = string(policyv1.UnknownCompliancy)
+	}
+
+	emittedComplianceEventsCounter.WithLabelValues(complianceEventPolicy(event), compliance).Inc()
+}
+
+// recordLastComplianceEvent records the emitted event as the last compliance event of the policy.
+func recordLastComplianceEvent(cache *complianceEventCache, event *corev1.Event) {
+	loaded, _ := cache.last.LoadOrStore(event.Namespace+"/"+event.Reason, &lastComplianceEvent{})
+	last := loaded.(*lastComplianceEvent)
+
+	last.lock.Lock()
+	defer last.lock.Unlock()
+
+	last.eventType = event.Type
+	last.message = event.Message
+	last.generation = event.Annotations[common.PolicyGenerationAnnotation]
+	last.emitted = event.LastTimestamp.Time
+}
+
+// forgetComplianceEvents removes the last compliance event, the last timestamp, the queued compliance events, and the
+// removal details of the deleted policy from the cache, and its series from the compliance event metrics.
+func forgetComplianceEvents(cache *complianceEventCache, namespace string, name string) {
+	key := namespace + "/" + fmt.Sprintf(eventFmtStr, namespace, name)
+
+	_ = emittedComplianceEventsCounter.DeletePartialMatch(prometheus.Labels{"policy": namespace + "/" + name})
+	_ = failedComplianceEventsCounter.DeleteLabelValues(namespace + "/" + name)
+	_ = suppressedComplianceEventsCounter.DeleteLabelValues(namespace + "/" + name)
+
+	cache.last.Delete(key)
+	cache.timestamps.Delete(key)
+	cache.removed.Delete(namespace + "/" + name)
+	cache.pruned.Delete(namespace + "/" + name)
+
+	if loaded, ok := cache.pending.LoadAndDelete(key); ok {
+		pending := loaded.(*pendingComplianceEvents)
+
+		pending.lock.Lock()
+		droppedComplianceEventsCounter.WithLabelValues(namespace + "/" + name).Add(float64(len(pending.events)))
+		pending.lock.Unlock()
+	}
+}
+
+// pruneComplianceEventSeries removes the series that can no longer be repeated from the cache. The series in use are
+// skipped.
+func pruneComplianceEventSeries(seriesCache *sync.Map, now time.Time) {
+	seriesCache.Range(func(key, value any) bool {
+		series := value.(*complianceEventSeries)
+
+		if !series.lock.TryLock() {
+			return true
+		}
+
+		if series.name != "" && now.Sub(series.lastObserved) >= complianceEventSeriesWindow {
+			seriesCache.Delete(key)
+		}
+
+		series.lock.Unlock()
+
+		return true
+	})
+}
@@ -0,0 +1,342 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+func newTestComplianceEvent(name string, timestamp time.Time, reason string, message string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "managed",
+			Annotations: map[string]string{common.PolicyDBIDAnnotation: "5"},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Policy", Namespace: "managed", Name: "parent", UID: "parent-uid",
+		},
+		Reason:              reason,
+		Message:             message,
+		Source:              corev1.EventSource{Component: ControllerName, Host: "controller"},
+		FirstTimestamp:      metav1.NewTime(timestamp),
+		LastTimestamp:       metav1.NewTime(timestamp),
+		Count:               1,
+		Type:                "Warning",
+		Action:              "ComplianceStateUpdate",
+		ReportingController: ControllerName,
+		ReportingInstance:   "controller",
+	}
+}
+
+func TestCreateComplianceEventSeries(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().Build()
+	cache := &complianceEventCache{}
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	reason := "policy: managed/policy"
+
+	err := createComplianceEvent(
+		context.TODO(), c, cache, 0, newTestComplianceEvent("parent.1", start, reason, "NonCompliant; violation"),
+	)
+	assert.NoError(t, err)
+
+	// The repeats within the series window are counted in the series of the first event
+	for i := 1; i <= 2; i++ {
+		timestamp := start.Add(time.Duration(i) * time.Minute)
+		event := newTestComplianceEvent("parent."+timestamp.String(), timestamp, reason, "NonCompliant; violation")
+
+		assert.NoError(t, createComplianceEvent(context.TODO(), c, cache, 0, event))
+	}
+
+	events := &eventsv1.EventList{}
+	assert.NoError(t, c.List(context.TODO(), events))
+
+	if assert.Len(t, events.Items, 1) {
+		event := events.Items[0]
+
+		assert.Equal(t, "parent.1", event.Name)
+		assert.Equal(t, reason, event.Reason)
+		assert.Equal(t, "NonCompliant; violation", event.Note)
+		assert.Equal(t, "parent", event.Regarding.Name)
+		assert.Equal(t, ControllerName, event.ReportingController)
+		assert.Equal(t, "controller", event.ReportingInstance)
+		assert.Equal(t, "5", event.Annotations[common.PolicyDBIDAnnotation])
+		assert.True(t, start.Equal(event.EventTime.Time))
+
+		if assert.NotNil(t, event.Series) {
+			assert.Equal(t, int32(3), event.Series.Count)
+			assert.True(t, start.Add(2*time.Minute).Equal(event.Series.LastObservedTime.Time))
+		}
+
+		// The API server rejects the deprecated fields on the events.k8s.io/v1 API
+		assert.Equal(t, int32(0), event.DeprecatedCount)
+		assert.True(t, event.DeprecatedFirstTimestamp.IsZero())
+		assert.True(t, event.DeprecatedLastTimestamp.IsZero())
+		assert.Equal(t, corev1.EventSource{}, event.DeprecatedSource)
+	}
+
+	// A repeat after the series window creates a new event
+	late := start.Add(2*time.Minute + complianceEventSeriesWindow)
+	err = createComplianceEvent(
+		context.TODO(), c, cache, 0, newTestComplianceEvent("parent.2", late, reason, "NonCompliant; violation"),
+	)
+	assert.NoError(t, err)
+
+	// A different message creates a new event. Going back to the previous message is a new event too rather than a
+	// repeat in the series of the earlier event so that the order of the compliance history is kept.
+	compliant := newTestComplianceEvent("parent.3", late.Add(time.Minute), reason, "Compliant; notification")
+	compliant.Type = "Normal"
+	assert.NoError(t, createComplianceEvent(context.TODO(), c, cache, 0, compliant))
+
+	err = createComplianceEvent(
+		context.TODO(), c, cache, 0,
+		newTestComplianceEvent("parent.4", late.Add(2*time.Minute), reason, "NonCompliant; violation"),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.List(context.TODO(), events))
+	assert.Len(t, events.Items, 4)
+}
+
+func TestCreateComplianceEventLimits(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().Build()
+	cache := &complianceEventCache{}
+	now := time.Now()
+
+	message := "NonCompliant; " + strings.Repeat("violation ", 200)
+	err := createComplianceEvent(
+		context.TODO(), c, cache, 0, newTestComplianceEvent("parent.1", now, "policy: managed/policy", message),
+	)
+	assert.NoError(t, err)
+
+	// A message too long for the events.k8s.io/v1 API is created as a core/v1 event so that it isn't truncated
+	coreEvent := &corev1.Event{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "managed", Name: "parent.1"}, coreEvent))
+	assert.Equal(t, message, coreEvent.Message)
+
+	// A reason too long for the events.k8s.io/v1 API is created as a core/v1 event
+	reason := "policy: managed/" + strings.Repeat("p", eventsV1ReasonLimit)
+	err = createComplianceEvent(
+		context.TODO(), c, cache, 0, newTestComplianceEvent("parent.2", now, reason, "NonCompliant; violation"),
+	)
+	assert.NoError(t, err)
+
+	coreEvent = &corev1.Event{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "managed", Name: "parent.2"}, coreEvent))
+	assert.Equal(t, "NonCompliant; violation", coreEvent.Message)
+	assert.Equal(t, int32(1), coreEvent.Count)
+}
+
+func TestCreateComplianceEventDedup(t *testing.T) {
+	t.Parallel()
+
+	c := fake.NewClientBuilder().Build()
+	cache := &complianceEventCache{}
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	reason := "policy: managed/policy"
+	dedupWindow := 5 * time.Minute
+
+	err := createComplianceEvent(
+		context.TODO(), c, cache, dedupWindow, newTestComplianceEvent("parent.1", start, reason, "NonCompliant; violation"),
+	)
+	assert.NoError(t, err)
+
+	// An exact repeat within the window isn't emitted, so the series isn't incremented either
+	repeat := newTestComplianceEvent("parent.2", start.Add(time.Minute), reason, "NonCompliant; violation")
+	assert.NoError(t, createComplianceEvent(context.TODO(), c, cache, dedupWindow, repeat))
+
+	events := &eventsv1.EventList{}
+	assert.NoError(t, c.List(context.TODO(), events))
+
+	if assert.Len(t, events.Items, 1) {
+		assert.Nil(t, events.Items[0].Series)
+	}
+
+	// A change in the compliance is emitted immediately
+	compliant := newTestComplianceEvent("parent.3", start.Add(2*time.Minute), reason, "Compliant; notification")
+	compliant.Type = "Normal"
+	assert.NoError(t, createComplianceEvent(context.TODO(), c, cache, dedupWindow, compliant))
+
+	// Going back to the previous message is emitted too. It's a new event rather than a repeat in the series of the
+	// first event so that the order of the compliance history is kept.
+	err = createComplianceEvent(
+		context.TODO(), c, cache, dedupWindow,
+		newTestComplianceEvent("parent.4", start.Add(3*time.Minute), reason, "NonCompliant; violation"),
+	)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.List(context.TODO(), events))
+	assert.Len(t, events.Items, 3)
+
+	// A repeat after the window is emitted, which is counted in the series of the last event
+	late := newTestComplianceEvent("parent.5", start.Add(3*time.Minute+dedupWindow), reason, "NonCompliant; violation")
+	assert.NoError(t, createComplianceEvent(context.TODO(), c, cache, dedupWindow, late))
+
+	first := &eventsv1.Event{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "managed", Name: "parent.1"}, first))
+	assert.Nil(t, first.Series)
+
+	last := &eventsv1.Event{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "managed", Name: "parent.4"}, last))
+
+	if assert.NotNil(t, last.Series) {
+		assert.Equal(t, int32(2), last.Series.Count)
+	}
+
+	// A new generation of the policy is emitted as a new event even if the message is the same
+	newGeneration := newTestComplianceEvent(
+		"parent.6", start.Add(4*time.Minute+dedupWindow), reason, "NonCompliant; violation",
+	)
+	newGeneration.Annotations[common.PolicyGenerationAnnotation] = "2"
+	assert.NoError(t, createComplianceEvent(context.TODO(), c, cache, dedupWindow, newGeneration))
+
+	assert.NoError(t, c.List(context.TODO(), events))
+	assert.Len(t, events.Items, 4)
+
+	// A deleted policy is forgotten
+	forgetComplianceEvents(cache, "managed", "policy")
+
+	_, found := cache.last.Load("managed/" + reason)
+	assert.False(t, found)
+}
+
+func TestTruncateComplianceEventMessage(t *testing.T) {
+	t.Parallel()
+
+	message := "NonCompliant; the subscription has violations: first-constraint, second-constraint"
+	listMessage := "NonCompliant; violations: first-constraint, second-constraint, third-constraint, " +
+		"fourth-constraint, fifth-constraint"
+	suffixLength := len(truncatedEventMessageSuffix)
+
+	tests := map[string]struct {
+		message   string
+		maxLength int
+		expected  string
+	}{
+		"short message":       {message, len(message), message},
+		"truncation disabled": {message, 0, message},
+		"word boundary":       {message, suffixLength + 25, "NonCompliant; the" + truncatedEventMessageSuffix},
+		"trailing separator": {
+			listMessage, suffixLength + 46, "NonCompliant; violations: first-constraint" + truncatedEventMessageSuffix,
+		},
+		"no word boundary": {
+			"NonCompliant;" + strings.Repeat("x", 100), suffixLength + 20,
+			"NonCompliant;xxxxxxx" + truncatedEventMessageSuffix,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, truncateComplianceEventMessage(test.message, test.maxLength))
+		})
+	}
+
+	// A maximum length greater than the limit of the events.k8s.io/v1 API is honored
+	truncated := truncateComplianceEventMessage(strings.Repeat("violation ", 300), 2*eventsV1NoteLimit)
+	assert.Greater(t, len(truncated), eventsV1NoteLimit)
+	assert.LessOrEqual(t, len(truncated), 2*eventsV1NoteLimit)
+}
+
+func TestParseComplianceEventsMode(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"enabled", "disabled", "log-only"} {
+		mode, err := ParseComplianceEventsMode(value)
+		assert.NoError(t, err)
+		assert.Equal(t, ComplianceEventsMode(value), mode)
+	}
+
+	_, err := ParseComplianceEventsMode("quiet")
+	assert.ErrorContains(t, err, "the compliance events mode must be enabled, disabled, or log-only: quiet")
+}
+
+func TestComplianceEventsMode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[ComplianceEventsMode]int{
+		"":                       2,
+		ComplianceEventsEnabled:  2,
+		ComplianceEventsDisabled: 0,
+		ComplianceEventsLogOnly:  0,
+	}
+
+	ownerRefs := []metav1.OwnerReference{
+		{APIVersion: "policy.open-cluster-management.io/v1", Kind: "Policy", Name: "parent", UID: "parent-uid"},
+	}
+
+	for mode, expectedEvents := range tests {
+		mode := mode
+		expectedEvents := expectedEvents
+
+		t.Run(string(mode), func(t *testing.T) {
+			t.Parallel()
+
+			c := fake.NewClientBuilder().Build()
+
+			configReconciler := &ConfigurationPolicyReconciler{
+				Client: c, InstanceName: "controller", ComplianceEvents: mode,
+			}
+			configPolicy := &policyv1.ConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "config-policy", Namespace: "managed", OwnerReferences: ownerRefs},
+				Spec:       &policyv1.ConfigurationPolicySpec{ObjectEvents: true},
+				Status:     policyv1.ConfigurationPolicyStatus{ComplianceState: policyv1.NonCompliant},
+			}
+
+			assert.NoError(t, configReconciler.sendComplianceEvent(configPolicy))
+
+			configReconciler.sendObjectEvents(
+				configPolicy, []policyv1.RelatedObject{testRelatedObject("cm", policyv1.NonCompliant)}, nil,
+			)
+
+			operatorReconciler := &OperatorPolicyReconciler{
+				Client: c, InstanceName: "controller", ComplianceEvents: mode,
+			}
+			operatorPolicy := &policyv1beta1.OperatorPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "operator-policy", Namespace: "managed", OwnerReferences: ownerRefs},
+			}
+			condition := metav1.Condition{
+				Type: compliantConditionType, Status: metav1.ConditionFalse, Message: "NonCompliant; missing",
+			}
+
+			assert.NoError(t, operatorReconciler.emitComplianceEvent(context.TODO(), operatorPolicy, condition))
+
+			// The policy compliance events of both kinds of policies are created with the events.k8s.io/v1 API
+			events := &eventsv1.EventList{}
+			assert.NoError(t, c.List(context.TODO(), events))
+			assert.Len(t, events.Items, expectedEvents)
+
+			// The object compliance events are created with the core/v1 API
+			coreEvents := &corev1.EventList{}
+			assert.NoError(t, c.List(context.TODO(), coreEvents))
+			assert.Len(t, coreEvents.Items, expectedEvents/2)
+
+			// The other events on the policies, such as the policy conflict events, follow the mode too
+			recorder := record.NewFakeRecorder(10)
+			mode.EventRecorder(recorder).Event(configPolicy, eventWarning, eventReasonPolicyConflict, "conflict")
+			assert.Len(t, recorder.Events, expectedEvents/2)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+const (
+	defaultComplianceHistoryMinBackoff = time.Second
+	defaultComplianceHistoryMaxBackoff = 5 * time.Minute
+	// The total time that a compliance event is retried for before it's dropped, so that an API outage doesn't keep
+	// the events queued behind it forever
+	defaultComplianceHistoryMaxRetryDuration = 30 * time.Minute
+	// The number of attempts to send a compliance event while the API refuses the credentials. A rotated token is
+	// picked up within a few attempts, so the failures after that are persistent.
+	defaultComplianceHistoryMaxAuthAttempts = 3
+	complianceHistoryRequestTimeout         = 30 * time.Second
+)
+
+var (
+	errComplianceHistoryRejected     = errors.New("the compliance history API rejected the compliance event")
+	errComplianceHistoryUnauthorized = errors.New("the compliance history API refused the credentials")
+)
+
+// complianceHistoryRecord is the compliance event sent to the compliance history API.
+type complianceHistoryRecord struct {
+	Cluster      complianceHistoryCluster `json:"cluster"`
+	ParentPolicy *complianceHistoryID     `json:"parent_policy,omitempty"`
+	Policy       complianceHistoryID      `json:"policy"`
+	Event        complianceHistoryEvent   `json:"event"`
+}
+
+type complianceHistoryCluster struct {
+	Name string `json:"name"`
+}
+
+type complianceHistoryID struct {
+	ID int64 `json:"id"`
+}
+
+type complianceHistoryEvent struct {
+	Compliance string `json:"compliance"`
+	Message    string `json:"message"`
+	Timestamp  string `json:"timestamp"`
+	ReportedBy string `json:"reported_by"`
+}
+
+// ComplianceHistoryReporter sends the compliance events of the policies with the compliance history database ID
+// annotations to the compliance history API. The events are queued in memory and sent in the background so that an
+// API outage doesn't block the policy evaluations. When the queue is full, the new events are dropped. A failed
+// request is retried with an exponential backoff until it succeeds, unless the API rejects the event, the API keeps
+// refusing the credentials, or the event was retried for longer than the maximum retry duration.
+type ComplianceHistoryReporter struct {
+	// The URL of the compliance events API, which must use HTTPS
+	Endpoint    string
+	ClusterName string
+	// The path of the file with the bearer token for the requests, which is read for every request so that a rotated
+	// token is used. No token is sent when it's empty.
+	TokenPath  string
+	client     *http.Client
+	queue      chan complianceHistoryRecord
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	// The total time that a compliance event is retried for and the number of attempts while the API refuses the
+	// credentials
+	maxRetryDuration time.Duration
+	maxAuthAttempts  int
+}
+
+// NewComplianceHistoryReporter returns a ComplianceHistoryReporter for the HTTPS endpoint. When caPath is set, the
+// certificate of the endpoint is verified with the CA certificates in the file instead of the system ones.
+func NewComplianceHistoryReporter(
+	endpoint string, clusterName string, tokenPath string, caPath string, queueSize int,
+) (*ComplianceHistoryReporter, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("the compliance history API URL is invalid: %w", err)
+	}
+
+	if endpointURL.Scheme != "https" {
+		return nil, fmt.Errorf("the compliance history API URL must use HTTPS: %s", endpoint)
+	}
+
+	if queueSize <= 0 {
+		return nil, fmt.Errorf("the compliance history queue size must be greater than 0: %d", queueSize)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caPath != "" {
+		caPEM, err := os.ReadFile(caPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the compliance history API CA file: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("the compliance history API CA file has no valid certificates: %s", caPath)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &ComplianceHistoryReporter{
+		Endpoint:         endpoint,
+		ClusterName:      clusterName,
+		TokenPath:        tokenPath,
+		client:           &http.Client{Transport: transport, Timeout: complianceHistoryRequestTimeout},
+		queue:            make(chan complianceHistoryRecord, queueSize),
+		minBackoff:       defaultComplianceHistoryMinBackoff,
+		maxBackoff:       defaultComplianceHistoryMaxBackoff,
+		maxRetryDuration: defaultComplianceHistoryMaxRetryDuration,
+		maxAuthAttempts:  defaultComplianceHistoryMaxAuthAttempts,
+	}, nil
+}
+
+// ReportEvent queues the compliance event to be sent to the compliance history API. The events without a valid
+// policy database ID annotation are skipped since the API can't associate them with a policy. It never blocks, and
+// it returns whether the event was queued.
+func (c *ComplianceHistoryReporter) ReportEvent(event *corev1.Event, compliance policyv1.ComplianceState) bool {
+	policyID, err := strconv.ParseInt(event.Annotations[common.PolicyDBIDAnnotation], 10, 64)
+	if err != nil {
+		return false
+	}
+
+	record := complianceHistoryRecord{
+		Cluster: complianceHistoryCluster{Name: c.ClusterName},
+		Policy:  complianceHistoryID{ID: policyID},
+		Event: complianceHistoryEvent{
+			Compliance: string(compliance),
+			Message:    event.Message,
+			Timestamp:  event.LastTimestamp.UTC().Format(time.RFC3339Nano),
+			ReportedBy: ControllerName,
+		},
+	}
+
+	if parentID, err := strconv.ParseInt(event.Annotations[common.ParentDBIDAnnotation], 10, 64); err == nil {
+		record.ParentPolicy = &complianceHistoryID{ID: parentID}
+	}
+
+	select {
+	case c.queue <- record:
+		return true
+	default:
+		log.Info(
+			"The compliance history queue is full. Dropping the compliance event.",
+			"namespace", event.Namespace, "policyID", policyID,
+		)
+
+		complianceHistoryDroppedCounter.WithLabelValues("queue_full").Inc()
+
+		return false
+	}
+}
+
+// Start sends the queued compliance events to the compliance history API until the context is canceled.
+func (c *ComplianceHistoryReporter) Start(ctx context.Context) {
+	log.Info("Sending the compliance events to the compliance history API", "endpoint", c.Endpoint)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-c.queue:
+			c.sendWithRetries(ctx, record)
+		}
+	}
+}
+
+// sendWithRetries sends the record until it succeeds, the API rejects it, the API refused the credentials
+// maxAuthAttempts times, the next attempt would be after maxRetryDuration, or the context is canceled. The delay
+// between the attempts doubles up to maxBackoff.
+func (c *ComplianceHistoryReporter) sendWithRetries(ctx context.Context, record complianceHistoryRecord) {
+	backoff := c.minBackoff
+	deadline := time.Now().Add(c.maxRetryDuration)
+	authAttempts := 0
+
+	for {
+		err := c.send(ctx, record)
+
+		switch {
+		case err == nil:
+			complianceHistoryAuthFailureGauge.Set(0)
+
+			return
+		case errors.Is(err, errComplianceHistoryRejected):
+			// The API accepted the credentials since it processed the request
+			complianceHistoryAuthFailureGauge.Set(0)
+			complianceHistoryDroppedCounter.WithLabelValues("rejected").Inc()
+
+			log.Error(err, "Dropping the compliance event", "policyID", record.Policy.ID)
+
+			return
+		case errors.Is(err, errComplianceHistoryUnauthorized):
+			complianceHistoryAuthFailureGauge.Set(1)
+
+			authAttempts++
+			if authAttempts >= c.maxAuthAttempts {
+				complianceHistoryDroppedCounter.WithLabelValues("unauthorized").Inc()
+
+				log.Error(err, "Dropping the compliance event since the compliance history API keeps refusing the "+
+					"credentials", "policyID", record.Policy.ID, "attempts", authAttempts)
+
+				return
+			}
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			complianceHistoryDroppedCounter.WithLabelValues("retries_exhausted").Inc()
+
+			log.Error(err, "Dropping the compliance event since it couldn't be sent within the maximum retry duration",
+				"policyID", record.Policy.ID, "maxRetryDuration", c.maxRetryDuration.String())
+
+			return
+		}
+
+		log.Info(
+			"Failed to send the compliance event to the compliance history API. Will retry.",
+			"policyID", record.Policy.ID, "error", err.Error(), "retryAfter", backoff.String(),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// send sends the record to the compliance history API. An error wrapping errComplianceHistoryRejected is returned
+// when retrying won't help.
+func (c *ComplianceHistoryReporter) send(ctx context.Context, record complianceHistoryRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errComplianceHistoryRejected, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errComplianceHistoryRejected, err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.TokenPath != "" {
+		token, err := os.ReadFile(c.TokenPath)
+		if err != nil {
+			return fmt.Errorf("failed to read the compliance history API token: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	// The compliance event was already recorded
+	case resp.StatusCode == http.StatusConflict:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		// The token may be rotated, so the authentication errors are retried a few times
+		return fmt.Errorf(
+			"%w: unexpected status code %d: %s", errComplianceHistoryUnauthorized, resp.StatusCode, string(respBody),
+		)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(respBody))
+	default:
+		return fmt.Errorf(
+			"%w: unexpected status code %d: %s", errComplianceHistoryRejected, resp.StatusCode, string(respBody),
+		)
+	}
+}
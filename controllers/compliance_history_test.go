@@ -0,0 +1,259 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+func newComplianceEvent(annotations map[string]string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "policy.1", Namespace: "managed", Annotations: annotations,
+		},
+		Message:       "NonCompliant; violation - configmaps [cm] not found in namespace default",
+		LastTimestamp: metav1.NewTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}
+}
+
+func TestComplianceHistoryReportEvent(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewComplianceHistoryReporter("http://compliance-history.local/api/v1/compliance-events", "", "", "", 1)
+	assert.ErrorContains(t, err, "must use HTTPS")
+
+	reporter, err := NewComplianceHistoryReporter(
+		"https://compliance-history.local/api/v1/compliance-events", "cluster1", "", "", 1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the policy database ID, the event isn't queued
+	assert.False(t, reporter.ReportEvent(newComplianceEvent(nil), policyv1.NonCompliant))
+
+	annotations := map[string]string{common.ParentDBIDAnnotation: "3", common.PolicyDBIDAnnotation: "5"}
+	assert.True(t, reporter.ReportEvent(newComplianceEvent(annotations), policyv1.NonCompliant))
+
+	// The queue is full, so the event is dropped rather than blocking
+	assert.False(t, reporter.ReportEvent(newComplianceEvent(annotations), policyv1.NonCompliant))
+
+	record := <-reporter.queue
+	recordJSON, err := json.Marshal(record)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"cluster": {"name": "cluster1"},
+		"parent_policy": {"id": 3},
+		"policy": {"id": 5},
+		"event": {
+			"compliance": "NonCompliant",
+			"message": "NonCompliant; violation - configmaps [cm] not found in namespace default",
+			"timestamp": "2024-01-02T03:04:05Z",
+			"reported_by": "configuration-policy-controller"
+		}
+	}`, string(recordJSON))
+}
+
+func TestComplianceHistorySend(t *testing.T) {
+	t.Parallel()
+
+	var lock sync.Mutex
+
+	statusCodes := []int{http.StatusServiceUnavailable, http.StatusCreated}
+	requests := []*http.Request{}
+	bodies := []string{}
+	received := make(chan struct{}, 10)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		lock.Lock()
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		statusCode := statusCodes[0]
+		if len(statusCodes) > 1 {
+			statusCodes = statusCodes[1:]
+		}
+		lock.Unlock()
+
+		w.WriteHeader(statusCode)
+
+		received <- struct{}{}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.crt")
+	tokenPath := filepath.Join(dir, "token")
+
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caPath, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(tokenPath, []byte("my-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reporter, err := NewComplianceHistoryReporter(server.URL, "cluster1", tokenPath, caPath, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reporter.minBackoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go reporter.Start(ctx)
+
+	annotations := map[string]string{common.PolicyDBIDAnnotation: "5"}
+	assert.True(t, reporter.ReportEvent(newComplianceEvent(annotations), policyv1.NonCompliant))
+
+	// The first request fails with a retryable error, so it's sent again
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the compliance history API request")
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, "Bearer my-token", requests[1].Header.Get("Authorization"))
+		assert.Equal(t, "application/json", requests[1].Header.Get("Content-Type"))
+		assert.Equal(t, bodies[0], bodies[1])
+		assert.Contains(t, bodies[1], `"policy":{"id":5}`)
+		assert.NotContains(t, bodies[1], "parent_policy")
+	}
+}
+
+func TestComplianceHistorySendRejected(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	reporter, err := NewComplianceHistoryReporter(server.URL, "cluster1", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reporter.client = server.Client()
+
+	err = reporter.send(context.Background(), complianceHistoryRecord{Policy: complianceHistoryID{ID: 5}})
+	assert.ErrorIs(t, err, errComplianceHistoryRejected)
+}
+
+// This isn't parallel since it checks the controller-wide compliance history metrics.
+func TestComplianceHistorySendUnauthorized(t *testing.T) {
+	var lock sync.Mutex
+
+	statusCode := http.StatusForbidden
+	requests := 0
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	reporter, err := NewComplianceHistoryReporter(server.URL, "cluster1", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reporter.client = server.Client()
+	reporter.minBackoff = time.Millisecond
+
+	dropped := testutil.ToFloat64(complianceHistoryDroppedCounter.WithLabelValues("unauthorized"))
+
+	// The refused credentials are retried a few times before the event is dropped
+	reporter.sendWithRetries(context.Background(), complianceHistoryRecord{Policy: complianceHistoryID{ID: 5}})
+
+	lock.Lock()
+	assert.Equal(t, defaultComplianceHistoryMaxAuthAttempts, requests)
+	assert.Equal(t, float64(1), testutil.ToFloat64(complianceHistoryAuthFailureGauge))
+	assert.Equal(t, dropped+1, testutil.ToFloat64(complianceHistoryDroppedCounter.WithLabelValues("unauthorized")))
+
+	statusCode = http.StatusCreated
+	lock.Unlock()
+
+	reporter.sendWithRetries(context.Background(), complianceHistoryRecord{Policy: complianceHistoryID{ID: 5}})
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(complianceHistoryAuthFailureGauge))
+}
+
+func TestComplianceHistorySendMaxRetryDuration(t *testing.T) {
+	t.Parallel()
+
+	var lock sync.Mutex
+
+	requests := 0
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		lock.Lock()
+		requests++
+		lock.Unlock()
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reporter, err := NewComplianceHistoryReporter(server.URL, "cluster1", "", "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reporter.client = server.Client()
+	reporter.minBackoff = 10 * time.Millisecond
+	reporter.maxBackoff = 10 * time.Millisecond
+	reporter.maxRetryDuration = 100 * time.Millisecond
+
+	done := make(chan struct{})
+
+	go func() {
+		reporter.sendWithRetries(context.Background(), complianceHistoryRecord{Policy: complianceHistoryID{ID: 5}})
+		close(done)
+	}()
+
+	// The event is dropped once the next attempt would be after the maximum retry duration
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the compliance event to be dropped")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Greater(t, requests, 1)
+	assert.LessOrEqual(t, requests, 11)
+}
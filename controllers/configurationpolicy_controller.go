@@ -8,6 +8,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strconv"
@@ -18,7 +21,9 @@ import (
 	gocmp "github.com/google/go-cmp/cmp"
 	"github.com/prometheus/client_golang/prometheus"
 	templates "github.com/stolostron/go-template-utils/v4/pkg/templates"
+	depclient "github.com/stolostron/kubernetes-dependency-watches/client"
 	"golang.org/x/mod/semver"
+	"golang.org/x/time/rate"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -42,11 +47,14 @@ import (
 	openapivalidation "k8s.io/kubectl/pkg/util/openapi/validation"
 	"k8s.io/kubectl/pkg/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	yaml "sigs.k8s.io/yaml"
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
 	common "open-cluster-management.io/config-policy-controller/pkg/common"
 )
 
@@ -54,6 +62,30 @@ const (
 	ControllerName       string = "configuration-policy-controller"
 	CRDName              string = "configurationpolicies.policy.open-cluster-management.io"
 	pruneObjectFinalizer string = "policy.open-cluster-management.io/delete-related-objects"
+	// previewAnnotation makes an enforce policy only issue dry run requests and report the changes it would make
+	previewAnnotation string = "policy.open-cluster-management.io/preview"
+	// triggerEvaluationAnnotation makes the policy evaluate immediately, regardless of the evaluationInterval, every
+	// time its value changes
+	triggerEvaluationAnnotation string = "policy.open-cluster-management.io/trigger-evaluation"
+	// protectedAnnotation on an object set to "true" prevents enforced mustnothave object templates from deleting it,
+	// unless the controller ignores the annotation
+	protectedAnnotation string = "policy.open-cluster-management.io/protected"
+	// eventReasonDeletionBlocked is the reason of the Kubernetes event emitted on the policy when the deletion of an
+	// object is blocked by the protected annotation
+	eventReasonDeletionBlocked string = "DeletionBlocked"
+	// eventReasonPruneSkipped is the reason of the Kubernetes event emitted on the policy when a related object isn't
+	// pruned since it was replaced after the policy created it
+	eventReasonPruneSkipped string = "PruneSkipped"
+	// pruneSkippedConditionType is the type of the status condition that lists the related objects that weren't
+	// pruned since they were replaced after the policy created them
+	pruneSkippedConditionType string = "PruneSkipped"
+	// purgeMessageNameLimit is the number of deleted object names listed in the compliance message when deleting
+	// the objects matching an objectSelector
+	purgeMessageNameLimit int = 10
+	// maxConditionMessageLength is the maximum length of the message of a metav1.Condition
+	maxConditionMessageLength int = 32768
+	// DefaultFieldManager is the base field manager used for the requests that enforce policies
+	DefaultFieldManager string = "config-policy-controller"
 )
 
 var log = ctrl.Log.WithName(ControllerName)
@@ -69,22 +101,60 @@ var (
 )
 
 const (
-	reasonWantFoundExists    = "Resource found as expected"
-	reasonWantFoundCreated   = "K8s creation success"
-	reasonUpdateSuccess      = "K8s update success"
-	reasonDeleteSuccess      = "K8s deletion success"
-	reasonWantFoundNoMatch   = "Resource found but does not match"
-	reasonWantFoundDNE       = "Resource not found but should exist"
-	reasonWantNotFoundExists = "Resource found but should not exist"
-	reasonWantNotFoundDNE    = "Resource not found as expected"
-	reasonCleanupError       = "Error cleaning up child objects"
+	reasonWantFoundExists     = "Resource found as expected"
+	reasonWantFoundExistsOnly = "Resource found, contents not evaluated"
+	reasonWantFoundCreated    = "K8s creation success"
+	reasonUpdateSuccess       = "K8s update success"
+	reasonDeleteSuccess       = "K8s deletion success"
+	reasonWantFoundNoMatch    = "Resource found but does not match"
+	reasonWantFoundDNE        = "Resource not found but should exist"
+	reasonWantNotFoundExists  = "Resource found but should not exist"
+	reasonWantNotFoundTerm    = "Resource found but is terminating"
+	reasonWantNotFoundDNE     = "Resource not found as expected"
+	reasonCleanupError        = "Error cleaning up child objects"
+	reasonRawTemplatesSource  = "Resource is the source of the object templates"
+	reasonObjectCount         = "Resource count as expected"
+	reasonObjectCountNoMatch  = "Resource count not as expected"
+	reasonPurgeSuccess        = "K8s purge success"
+	reasonPreviewCreate       = "Resource would be created (preview)"
+	reasonPreviewUpdate       = "Resource would be updated (preview)"
+	reasonPreviewDelete       = "Resource would be deleted (preview)"
+	reasonDependenciesUnmet   = "Dependencies are not satisfied"
+	reasonNamespaceRestricted = "Namespace is restricted by controller configuration"
+	reasonKindRestricted      = "Kind is restricted by controller configuration"
+	reasonDeletionProtected   = "Deletion blocked by protection annotation"
 )
 
+// isPreview determines if the policy is in preview mode, in which case enforcing only issues dry run requests and
+// the changes that would be made are reported in the status instead.
+func isPreview(policy *policyv1.ConfigurationPolicy) bool {
+	return strings.EqualFold(policy.GetAnnotations()[previewAnnotation], "true")
+}
+
+// evaluationTriggered determines if the policy's trigger-evaluation annotation has a value that wasn't honored yet,
+// in which case the policy is evaluated immediately regardless of the evaluationInterval.
+func evaluationTriggered(policy *policyv1.ConfigurationPolicy) bool {
+	trigger := policy.GetAnnotations()[triggerEvaluationAnnotation]
+
+	return trigger != "" && trigger != policy.Status.LastTriggeredEvaluation
+}
+
+// policyKey returns the namespace and name of the policy, which is its key in the caches of the controller.
+func policyKey(policy *policyv1.ConfigurationPolicy) string {
+	return policy.Namespace + "/" + policy.Name
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ConfigurationPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(ControllerName).
-		For(&policyv1.ConfigurationPolicy{}).
+		For(
+			&policyv1.ConfigurationPolicy{},
+			builder.WithPredicates(
+				predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}),
+				removedPolicyPredicate(&r.complianceEvents),
+			),
+		).
 		Complete(r)
 }
 
@@ -113,6 +183,9 @@ type ConfigurationPolicyReconciler struct {
 	// Determines if the target Kubernetes cluster supports dry run update requests. When OpenShift <v4.5
 	// support is dropped, this can be removed as it's always true.
 	DryRunSupported bool
+	// Determines the number of Go routines that can evaluate the object templates of a policy concurrently. The object
+	// templates of policies with ordered evaluation are always evaluated one at a time.
+	TemplateEvaluationConcurrency uint8
 	// Determines the number of Go routines that can evaluate policies concurrently.
 	EvaluationConcurrency uint8
 	Scheme                *runtime.Scheme
@@ -120,15 +193,46 @@ type ConfigurationPolicyReconciler struct {
 	// processedPolicyCache has the ConfigurationPolicy UID as the key and the values are a *sync.Map with the keys
 	// as object UIDs and the values as cachedEvaluationResult objects.
 	processedPolicyCache sync.Map
-	InstanceName         string
+	// resolvedSelectorCache has the ConfigurationPolicy namespace and name as the key and the values are the
+	// policyv1.Target namespaceSelector with its templates resolved during the last evaluation.
+	resolvedSelectorCache sync.Map
+	// selectorWatcher watches the ConfigMaps referenced by the namespaceSelector templates.
+	selectorWatcher selectorWatcher
+	// rawRefVersionCache has the ConfigurationPolicy namespace and name as the key and the values are the
+	// resourceVersion of the object referenced by spec.objectTemplatesRawRef during the last evaluation.
+	rawRefVersionCache sync.Map
+	// rawRefWatcher watches the objects referenced by spec.objectTemplatesRawRef.
+	rawRefWatcher rawRefWatcher
+	// timedOutTemplateCache has the ConfigurationPolicy namespace and name as the key and the values are the
+	// *timedOutTemplates of the policy.
+	timedOutTemplateCache sync.Map
+	InstanceName          string
 	// The Kubernetes client to use when evaluating/enforcing policies. Most times, this will be the same cluster
 	// where the controller is running.
 	TargetK8sClient        kubernetes.Interface
 	TargetK8sDynamicClient dynamic.Interface
 	TargetK8sConfig        *rest.Config
 	SelectorReconciler     common.SelectorReconciler
+	// When set, a value received on this channel indicates that a namespaceSelector selection changed, so the
+	// evaluation loop doesn't wait for the remaining update frequency before evaluating the policies again.
+	SelectorUpdates <-chan struct{}
+	// When set, the policies with an object template kind that has no API mapping wait for its CRD to be installed,
+	// and a value received on CRDUpdates indicates that such a CRD was established.
+	CRDWatcher *common.CRDWatcher
+	CRDUpdates <-chan struct{}
+	// When set, a value is sent on this channel when a policy's trigger-evaluation annotation is set to a new value,
+	// so the evaluation loop doesn't wait for the remaining update frequency before evaluating the policies again.
+	EvaluationTriggers chan struct{}
+	// When set, the objects looked up by the templates of the policies are retrieved and watched through this
+	// DynamicWatcher, and TemplateWatches records the policies whose looked up objects changed. Its reconciler must
+	// be TemplateWatches.
+	DynamicWatcher  depclient.DynamicWatcher
+	TemplateWatches *TemplateWatchReconciler
 	// Whether custom metrics collection is enabled
 	EnableMetrics bool
+	// The namespace patterns, besides the namespace of the policy, of the ConfigMaps and Secrets that
+	// spec.objectTemplatesRawRef may reference.
+	RawRefAllowedNamespaces []string
 	discoveryInfo
 	// This is used to fetch and parse OpenAPI documents to perform client-side validation of object definitions.
 	openAPIParser *openapi.CachedOpenAPIParser
@@ -137,6 +241,106 @@ type ConfigurationPolicyReconciler struct {
 	// When true, the controller has detected it is being uninstalled and only basic cleanup should be performed before
 	// exiting.
 	UninstallMode bool
+	// The maximum number of related objects per object template recorded in the status. The omitted objects are
+	// counted in a summary entry. Zero or less disables the limit.
+	MaxRelatedObjectsPerTemplate int
+	// The maximum size in bytes of the JSON representation of a policy status. The condition messages and related
+	// objects are shortened to fit. Zero or less disables the limit.
+	MaxStatusBytes int
+	// The maximum size in bytes of a single objectDefinition in a policy. Larger object templates aren't evaluated.
+	// Zero or less disables the limit.
+	MaxObjectDefinitionBytes int
+	// The maximum size in bytes of all the object templates in a policy combined. Zero or less disables the limit.
+	MaxObjectTemplatesBytes int
+	// The maximum duration of the template resolution of an object template and the maximum size in bytes of its
+	// output. The object templates that exceed a limit are noncompliant and the other object templates are still
+	// evaluated. Zero or less disables the limit.
+	TemplateResolutionTimeout time.Duration
+	MaxTemplateOutputBytes    int
+	// The number of unchanged lines shown around each change in the diffs of the objects. Zero or less uses the
+	// default of one line.
+	DiffContextLines int
+	// When true, the hunk headers of the diffs of the objects include the YAML path of their first changed line.
+	DiffHunkPaths bool
+	// When set, the diffs that the policies record are written to this file instead of the controller log, which
+	// only gets a pointer to it.
+	DiffLog *DiffLogWriter
+	// The maximum size in bytes of the diffs of the mismatched objects appended to the noncompliant compliance events
+	// of the object templates that record their diff. Zero or less disables the diffs in the compliance events.
+	MaxEventDiffBytes int
+	// When set, the compliance events are also sent to the compliance history API.
+	ComplianceHistory *ComplianceHistoryReporter
+	// The base field manager for the requests that enforce policies. The policy name is appended to it so that the
+	// changes can be attributed to a policy. It defaults to DefaultFieldManager.
+	FieldManager string
+	// When a policy updates the same object more than EnforcementConflictThreshold times within
+	// EnforcementConflictWindow without it staying compliant, another controller is assumed to be reverting the
+	// updates, so they are paused with an exponential backoff. Zero or less disables the detection.
+	EnforcementConflictThreshold int
+	EnforcementConflictWindow    time.Duration
+	// enforcementHistoryCache has the ConfigurationPolicy UID as the key and the values are a *sync.Map with the keys
+	// as object UIDs and the values as *enforcementHistory objects.
+	enforcementHistoryCache sync.Map
+	// enforcedFieldsCache has the enforcedObjectKey of the objects enforced by the policies as the key and the values
+	// are a *sync.Map with the policy namespace/name as the key and the *enforcedFields as the value. It's used to
+	// detect when policies enforce conflicting values on the same object.
+	enforcedFieldsCache sync.Map
+	// enforcedFieldsLock is held for writing when an object without enforcing policies is removed from
+	// enforcedFieldsCache, and for reading when a policy's enforced fields are recorded.
+	enforcedFieldsLock sync.RWMutex
+	// When true, the first evaluation of the policies after the controller starts is spread over a window of
+	// StartupJitterPerPolicy times the number of policies, and the evaluationInterval based evaluations are delayed by
+	// a small random offset so that they don't all happen at once.
+	EvaluationJitter       bool
+	StartupJitterPerPolicy time.Duration
+	// startupJitterCache has the ConfigurationPolicy UID as the key and the values are the time.Time until which the
+	// first evaluation of the policy after the controller started is delayed.
+	startupJitterCache sync.Map
+	// The namespace patterns in which objects may be created, updated, or deleted, regardless of the policies. A
+	// namespace must match a pattern in AllowedNamespaces, when set, and not match a pattern in DeniedNamespaces. The
+	// objects in other namespaces are only evaluated as if the policies were inform.
+	AllowedNamespaces []string
+	DeniedNamespaces  []string
+	// The Kind.group patterns of the objects that are never created, updated, or deleted, regardless of the policies.
+	// The objects are only evaluated as if the policies were inform.
+	DeniedKinds []string
+	// When true, enforced mustnothave object templates delete the objects with the protected annotation.
+	IgnoreProtectedAnnotation bool
+	// The maximum number of objects per second that the policies can create, update, or delete, and the burst above
+	// it. The Policy prefixed fields apply to each policy and the others to all policies. A throttled policy continues
+	// its enforcement in the next evaluation loop. Zero or less disables the limit.
+	EnforcementQPS         float32
+	EnforcementBurst       int
+	PolicyEnforcementQPS   float32
+	PolicyEnforcementBurst int
+	// The controller-wide enforcement rate limiter, which is created from EnforcementQPS and EnforcementBurst when it's
+	// first used.
+	enforcementRateLimiter     *rate.Limiter
+	enforcementRateLimiterOnce sync.Once
+	// policyRateLimiterCache has the ConfigurationPolicy namespace/name as the key and the values are the
+	// *rate.Limiter of the policy's enforcement.
+	policyRateLimiterCache sync.Map
+	// throttledPolicyCache has the UIDs of the ConfigurationPolicies whose enforcement was throttled during their last
+	// evaluation as the keys.
+	throttledPolicyCache sync.Map
+	// When set, an event is emitted on each object that a policy creates, updates, or deletes, which identifies the
+	// policy.
+	MutationEvents bool
+	// How the compliance events are emitted. It defaults to ComplianceEventsEnabled.
+	ComplianceEvents ComplianceEventsMode
+	// The maximum length in bytes of the compliance event messages, beyond which they're truncated. Zero or less
+	// disables the truncation.
+	MaxComplianceEventMessageLength int
+	// How long an exact repeat of the last compliance event of a policy isn't emitted. Zero or less disables the
+	// deduplication.
+	ComplianceEventDedupWindow time.Duration
+	// complianceEvents is the cache of the compliance events emitted for the policies. See createComplianceEvent.
+	complianceEvents complianceEventCache
+	// eventDiffCache has the ConfigurationPolicy namespace/name as the key and the values are the *eventDiffs of its
+	// last evaluation.
+	eventDiffCache sync.Map
+	// userErrors counts the user errors of the policies in the policy_user_errors metric.
+	userErrors userErrorTracker
 }
 
 //+kubebuilder:rbac:groups=*,resources=*,verbs=*
@@ -149,18 +353,49 @@ func (r *ConfigurationPolicyReconciler) Reconcile(ctx context.Context, request c
 	err := r.Get(ctx, request.NamespacedName, policy)
 	if k8serrors.IsNotFound(err) {
 		// If the metric was not deleted, that means the policy was never evaluated so it can be ignored.
-		_ = policyEvalSecondsCounter.DeleteLabelValues(request.Name)
-		_ = policyEvalCounter.DeleteLabelValues(request.Name)
+		deletePolicyEvaluationMetrics(request.Name)
+		deletePolicyComplianceMetric(request.Namespace, request.Name)
 		_ = plcTempsProcessSecondsCounter.DeleteLabelValues(request.Name)
 		_ = plcTempsProcessCounter.DeleteLabelValues(request.Name)
+		deleteTemplateResolutionMetrics(request.Name)
 		_ = compareObjEvalCounter.DeletePartialMatch(prometheus.Labels{"config_policy_name": request.Name})
 		_ = compareObjSecondsCounter.DeletePartialMatch(prometheus.Labels{"config_policy_name": request.Name})
 		_ = policyRelatedObjectGauge.DeletePartialMatch(
 			prometheus.Labels{"policy": fmt.Sprintf("%s/%s", request.Namespace, request.Name)})
-		_ = policyUserErrorsCounter.DeletePartialMatch(prometheus.Labels{"template": request.Name})
 		_ = policySystemErrorsCounter.DeletePartialMatch(prometheus.Labels{"template": request.Name})
 
 		r.SelectorReconciler.Stop(request.Name)
+
+		if r.CRDWatcher != nil {
+			r.CRDWatcher.Stop(request.Name)
+		}
+
+		r.resolvedSelectorCache.Delete(request.NamespacedName.String())
+		r.selectorWatcher.stop(request.NamespacedName.String())
+		r.rawRefVersionCache.Delete(request.NamespacedName.String())
+		r.rawRefWatcher.stop(request.NamespacedName.String())
+		r.stopTemplateWatches(request.Namespace, request.Name)
+		r.timedOutTemplateCache.Delete(request.NamespacedName.String())
+		r.pruneEnforcedFields(request.NamespacedName.String(), nil)
+		r.policyRateLimiterCache.Delete(request.NamespacedName.String())
+		r.eventDiffCache.Delete(request.NamespacedName.String())
+		sendRemovalComplianceEvent(
+			ctx, r.Client, &r.complianceEvents, r.ComplianceEvents, r.InstanceName, request.Namespace, request.Name,
+		)
+		forgetComplianceEvents(&r.complianceEvents, request.Namespace, request.Name)
+		r.userErrors.forget("ConfigurationPolicy", request.Namespace, request.Name)
+
+		return reconcile.Result{}, nil
+	}
+
+	if err == nil && r.EvaluationTriggers != nil && evaluationTriggered(policy) {
+		log.V(1).Info("The policy evaluation was triggered by annotation", "policy", request.Name)
+
+		// The channel is buffered, so a pending value already wakes up the evaluation loop
+		select {
+		case r.EvaluationTriggers <- struct{}{}:
+		default:
+		}
 	}
 
 	return reconcile.Result{}, nil
@@ -183,6 +418,8 @@ func (r *ConfigurationPolicyReconciler) PeriodicallyExecConfigPolicies(
 
 	exiting := false
 	deploymentFinalizerRemoved := false
+	crdInstalled := false
+	startupJitterScheduled := false
 
 	for !exiting {
 		if !deploymentFinalizerRemoved {
@@ -201,6 +438,12 @@ func (r *ConfigurationPolicyReconciler) PeriodicallyExecConfigPolicies(
 		cleanupImmediately := r.UninstallMode
 
 		if !r.UninstallMode {
+			select {
+			case <-r.CRDUpdates:
+				crdInstalled = true
+			default:
+			}
+
 			if len(r.apiResourceList) == 0 || len(r.apiGroups) == 0 {
 				discoveryErr := r.refreshDiscoveryInfo()
 
@@ -215,9 +458,16 @@ func (r *ConfigurationPolicyReconciler) PeriodicallyExecConfigPolicies(
 			// cache, the discovery info refresh will be handled there. This periodic refresh is to account for
 			// deleted CRDs or strange edits to the CRD (e.g. converted it from namespaced to not).
 			if time.Since(r.discoveryLastRefreshed) >= waiting {
+				_ = r.refreshDiscoveryInfo()
+			} else if crdInstalled {
+				// A CRD that a policy is waiting for was established, so the cached API mappings are outdated
+				log.V(1).Info("Refreshing the discovery info since a CRD that a policy is waiting for was installed")
+
 				_ = r.refreshDiscoveryInfo()
 			}
 
+			crdInstalled = false
+
 			uninstalling, crdDeleting, err := r.cleanupImmediately()
 			if !uninstalling && !crdDeleting && err != nil {
 				log.Error(err, "Failed to determine if it's time to cleanup immediately")
@@ -250,6 +500,12 @@ func (r *ConfigurationPolicyReconciler) PeriodicallyExecConfigPolicies(
 
 				log.V(1).Info("Processing the policies", "count", len(policiesList.Items))
 
+				if r.EvaluationJitter && !startupJitterScheduled {
+					r.scheduleStartupJitter(policiesList.Items, time.Now())
+
+					startupJitterScheduled = true
+				}
+
 				// Initialize the related object map
 				policyRelatedObjectMap = sync.Map{}
 
@@ -266,6 +522,7 @@ func (r *ConfigurationPolicyReconciler) PeriodicallyExecConfigPolicies(
 					// been processed.
 					if policy.Status.LastEvaluatedGeneration != policy.Generation {
 						r.processedPolicyCache.Delete(policy.GetUID())
+						r.enforcementHistoryCache.Delete(policy.GetUID())
 					}
 
 					if !r.shouldEvaluatePolicy(&policy, cleanupImmediately) {
@@ -294,7 +551,20 @@ func (r *ConfigurationPolicyReconciler) PeriodicallyExecConfigPolicies(
 			remainingSleep := float64(freq) - elapsed
 			sleepTime := time.Duration(remainingSleep) * time.Second
 			log.V(2).Info("Sleeping before reprocessing the configuration policies", "seconds", sleepTime)
-			time.Sleep(sleepTime)
+
+			select {
+			case <-time.After(sleepTime):
+			case <-r.SelectorUpdates:
+				log.V(1).Info("A namespaceSelector selection changed. Reprocessing the configuration policies now.")
+			case <-r.CRDUpdates:
+				log.V(1).Info("A CRD that a policy is waiting for was installed. Reprocessing the configuration " +
+					"policies now.")
+
+				crdInstalled = true
+			case <-r.EvaluationTriggers:
+				log.V(1).Info("A policy evaluation was triggered by annotation. Reprocessing the configuration " +
+					"policies now.")
+			}
 		}
 
 		select {
@@ -312,15 +582,7 @@ func (r *ConfigurationPolicyReconciler) handlePolicyWorker(
 	defer wg.Done()
 
 	for policy := range policyQueue {
-		before := time.Now().UTC()
-
 		r.handleObjectTemplates(*policy)
-
-		duration := time.Now().UTC().Sub(before)
-		seconds := float64(duration) / float64(time.Second)
-
-		policyEvalSecondsCounter.WithLabelValues(policy.Name).Add(seconds)
-		policyEvalCounter.WithLabelValues(policy.Name).Inc()
 	}
 }
 
@@ -395,6 +657,23 @@ func (r *ConfigurationPolicyReconciler) shouldEvaluatePolicy(
 		return true
 	}
 
+	if evaluationTriggered(policy) {
+		log.V(1).Info(
+			"The policy evaluation was triggered by annotation. Will evaluate it now.",
+			"trigger", policy.GetAnnotations()[triggerEvaluationAnnotation],
+		)
+
+		r.startupJitterCache.Delete(policy.GetUID())
+
+		return true
+	}
+
+	if r.startupJitterPending(policy, time.Now()) {
+		log.V(1).Info("Skipping the policy evaluation to spread the evaluations after the controller started")
+
+		return false
+	}
+
 	if policy.Status.LastEvaluatedGeneration != policy.Generation {
 		log.V(1).Info("The policy has been updated. Will evaluate it now.")
 
@@ -414,12 +693,34 @@ func (r *ConfigurationPolicyReconciler) shouldEvaluatePolicy(
 		return true
 	}
 
-	var interval time.Duration
+	if _, throttled := r.throttledPolicyCache.Load(policy.GetUID()); throttled {
+		log.V(1).Info("The policy's enforcement was throttled by the rate limit. Will evaluate it now.")
+
+		return true
+	}
+
+	// The dependencies are read from the cache, so checking them on every loop is cheap
+	if policy.Status.ComplianceState == policyv1.Pending {
+		log.V(1).Info("The policy is waiting for its dependencies. Will evaluate it now.")
+
+		return true
+	}
+
+	if pending := policy.Status.PendingNoncompliance; pending != nil {
+		effectiveAt, err := time.Parse(time.RFC3339, pending.EffectiveAt)
+		if err != nil || !time.Now().Before(effectiveAt) {
+			log.V(1).Info("The policy's noncompliance grace period has passed. Will evaluate it now.")
+
+			return true
+		}
+	}
+
+	var nextEvaluation time.Time
 
 	if policy.Status.ComplianceState == policyv1.Compliant && policy.Spec != nil {
-		interval, err = policy.Spec.EvaluationInterval.GetCompliantInterval()
+		nextEvaluation, err = policy.Spec.EvaluationInterval.GetNextCompliantEvaluation(lastEvaluated)
 	} else if policy.Status.ComplianceState == policyv1.NonCompliant && policy.Spec != nil {
-		interval, err = policy.Spec.EvaluationInterval.GetNonCompliantInterval()
+		nextEvaluation, err = policy.Spec.EvaluationInterval.GetNextNonCompliantEvaluation(lastEvaluated)
 	} else {
 		log.V(1).Info("The policy has an unknown compliance. Will evaluate it now.")
 
@@ -436,6 +737,51 @@ func (r *ConfigurationPolicyReconciler) shouldEvaluatePolicy(
 		return true
 	}
 
+	if r.CRDWatcher != nil && r.CRDWatcher.Installed(policy.Name) {
+		log.V(1).Info("A CRD that the policy was waiting for was installed. Will evaluate it now.")
+
+		return true
+	}
+
+	if r.selectorTemplateChanged(policy) {
+		log.V(1).Info("The resolved namespaceSelector templates changed. Will evaluate it now.")
+
+		return true
+	}
+
+	if r.objectTemplatesRawRefChanged(policy) {
+		log.V(1).Info("The object referenced by spec.objectTemplatesRawRef changed. Will evaluate it now.")
+
+		return true
+	}
+
+	if r.TemplateWatches != nil && r.TemplateWatches.consumeChange(policyKey(policy)) {
+		log.V(1).Info("An object looked up by the policy templates changed. Will evaluate it now.")
+
+		return true
+	}
+
+	if enforcementWindowChanged(policy, lastEvaluated, time.Now()) {
+		log.V(1).Info("The policy entered or left an enforcement window. Will evaluate it now.")
+
+		// The cached results from inform evaluations would otherwise prevent enforcing
+		r.processedPolicyCache.Delete(policy.GetUID())
+
+		return true
+	}
+
+	if usesTemplateEvaluationIntervals(policy) {
+		if i := dueObjectTemplate(policy); i != -1 {
+			log.V(1).Info("An object template reached its evaluation interval. Will evaluate it now.", "index", i)
+
+			return true
+		}
+
+		log.V(1).Info("Skipping the policy evaluation due to no object templates reaching their evaluation interval")
+
+		return false
+	}
+
 	if errors.Is(err, policyv1.ErrIsNever) {
 		log.V(1).Info("Skipping the policy evaluation due to the spec.evaluationInterval value being set to never")
 
@@ -451,7 +797,10 @@ func (r *ConfigurationPolicyReconciler) shouldEvaluatePolicy(
 		return true
 	}
 
-	nextEvaluation := lastEvaluated.Add(interval)
+	if r.EvaluationJitter {
+		nextEvaluation = nextEvaluation.Add(evaluationIntervalJitter(policy, nextEvaluation.Sub(lastEvaluated)))
+	}
+
 	if nextEvaluation.Sub(time.Now().UTC()) > 0 {
 		log.V(1).Info("Skipping the policy evaluation due to the policy not reaching the evaluation interval")
 
@@ -461,179 +810,740 @@ func (r *ConfigurationPolicyReconciler) shouldEvaluatePolicy(
 	return true
 }
 
-type objectTemplateDetails struct {
-	kind         string
-	name         string
-	namespace    string
-	isNamespaced bool
-}
+// scheduleStartupJitter delays the first evaluation of each input policy by a random offset in a window of
+// StartupJitterPerPolicy times the number of policies, so that the API server isn't flooded when the controller starts.
+func (r *ConfigurationPolicyReconciler) scheduleStartupJitter(policies []policyv1.ConfigurationPolicy, now time.Time) {
+	window := r.StartupJitterPerPolicy * time.Duration(len(policies))
+	if window <= 0 {
+		return
+	}
 
-// getObjectTemplateDetails retrieves values from the object templates and returns an array of
-// objects containing the retrieved values.
-// It also gathers namespaces for this policy if necessary:
-//
-//	If a namespaceSelector is present AND objects are namespaced without a namespace specified
-func (r *ConfigurationPolicyReconciler) getObjectTemplateDetails(
-	plc policyv1.ConfigurationPolicy,
-) ([]objectTemplateDetails, []string, bool, error) {
-	templateObjs := make([]objectTemplateDetails, len(plc.Spec.ObjectTemplates))
-	selectedNamespaces := []string{}
-	queryNamespaces := false
+	log.Info("Spreading the first evaluation of the policies", "count", len(policies), "window", window)
 
-	for idx, objectT := range plc.Spec.ObjectTemplates {
-		unstruct, err := unmarshalFromJSON(objectT.ObjectDefinition.Raw)
-		if err != nil {
-			return templateObjs, selectedNamespaces, false, err
-		}
+	for i := range policies {
+		// #nosec G404 -- the offset doesn't need to be cryptographically secure
+		r.startupJitterCache.Store(policies[i].GetUID(), now.Add(time.Duration(rand.Int63n(int64(window)))))
+	}
+}
 
-		templateObjs[idx].isNamespaced = r.isObjectNamespaced(&unstruct, true)
-		// strings.TrimSpace() is needed here because a multi-line value will have '\n' in it
-		templateObjs[idx].kind = strings.TrimSpace(unstruct.GetKind())
-		templateObjs[idx].name = strings.TrimSpace(unstruct.GetName())
-		templateObjs[idx].namespace = strings.TrimSpace(unstruct.GetNamespace())
+// startupJitterPending returns true if the first evaluation of the policy after the controller started is still
+// delayed by scheduleStartupJitter.
+func (r *ConfigurationPolicyReconciler) startupJitterPending(policy *policyv1.ConfigurationPolicy, now time.Time) bool {
+	until, ok := r.startupJitterCache.Load(policy.GetUID())
+	if !ok {
+		return false
+	}
 
-		if templateObjs[idx].isNamespaced && templateObjs[idx].namespace == "" {
-			queryNamespaces = true
-		}
+	if now.Before(until.(time.Time)) {
+		return true
 	}
 
-	// If required, query for namespaces specified in NamespaceSelector for objects to use
-	if queryNamespaces {
-		// Retrieve the namespaces based on filters in NamespaceSelector
-		selector := plc.Spec.NamespaceSelector
-		// If MatchLabels/MatchExpressions/Include were not provided, return no namespaces
-		if selector.MatchLabels == nil && selector.MatchExpressions == nil && len(selector.Include) == 0 {
-			r.SelectorReconciler.Stop(plc.Name)
+	r.startupJitterCache.Delete(policy.GetUID())
 
-			log.V(1).Info("namespaceSelector is empty. Skipping namespace retrieval.")
-		} else {
-			// If an error occurred in the NamespaceSelector, update the policy status and abort
-			var err error
-			selectedNamespaces, err = r.SelectorReconciler.Get(plc.Name, plc.Spec.NamespaceSelector)
-			if err != nil {
-				errMsg := "Error filtering namespaces with provided namespaceSelector"
-				log.Error(
-					err, errMsg,
-					"namespaceSelector", fmt.Sprintf("%+v", selector))
+	return false
+}
 
-				reason := "namespaceSelector error"
-				msg := fmt.Sprintf(
-					"%s: %s", errMsg, err.Error())
-				statusChanged := addConditionToStatus(&plc, -1, false, reason, msg)
-				if statusChanged {
-					r.Recorder.Event(
-						&plc,
-						eventWarning,
-						fmt.Sprintf(plcFmtStr, plc.GetName()),
-						convertPolicyStatusToString(&plc),
-					)
-				}
+// evaluationIntervalJitter returns the offset added to the policy's next evaluationInterval based evaluation so that
+// policies with the same interval don't evaluate at the same time. It's up to a tenth of the interval and at most
+// 30 seconds. It's derived from the policy UID and last evaluation so that it's stable between the evaluation loops
+// but differs on every interval.
+func evaluationIntervalJitter(policy *policyv1.ConfigurationPolicy, interval time.Duration) time.Duration {
+	const maxJitter = 30 * time.Second
 
-				return templateObjs, selectedNamespaces, statusChanged, err
-			}
+	maxOffset := interval / 10
+	if maxOffset > maxJitter {
+		maxOffset = maxJitter
+	}
 
-			if len(selectedNamespaces) == 0 {
-				log.V(1).Info("Fetching namespaces with provided NamespaceSelector returned no namespaces.",
-					"namespaceSelector", fmt.Sprintf("%+v", selector))
-			}
-		}
+	if maxOffset <= 0 {
+		return 0
 	}
 
-	return templateObjs, selectedNamespaces, false, nil
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(string(policy.GetUID()) + policy.Status.LastEvaluated))
+
+	return time.Duration(hash.Sum64() % uint64(maxOffset))
 }
 
-func (r *ConfigurationPolicyReconciler) cleanUpChildObjects(plc policyv1.ConfigurationPolicy,
-	newRelated []policyv1.RelatedObject,
-) []string {
-	deletionFailures := []string{}
+// validateEvaluationInterval returns an error if either interval is not a duration, a cron expression, or "never".
+func validateEvaluationInterval(evaluationInterval policyv1.EvaluationInterval) error {
+	now := time.Now()
 
-	if !plc.Spec.RemediationAction.IsEnforce() {
-		return deletionFailures
+	if _, err := evaluationInterval.GetNextCompliantEvaluation(now); err != nil && !errors.Is(err, policyv1.ErrIsNever) {
+		return fmt.Errorf("compliant: %w", err)
 	}
 
-	// PruneObjectBehavior = none case fall in here
-	if !(string(plc.Spec.PruneObjectBehavior) == "DeleteAll" ||
-		string(plc.Spec.PruneObjectBehavior) == "DeleteIfCreated") {
-		return deletionFailures
+	_, err := evaluationInterval.GetNextNonCompliantEvaluation(now)
+	if err != nil && !errors.Is(err, policyv1.ErrIsNever) {
+		return fmt.Errorf("noncompliant: %w", err)
 	}
 
-	objsToDelete := plc.Status.RelatedObjects
+	return nil
+}
 
-	// When spec is updated and new related objects are created
-	if len(newRelated) != 0 {
-		var objShouldRemoved []policyv1.RelatedObject
+// enforcementWindowChanged determines if an enforce policy entered or left a window of its spec.enforcementSchedule
+// since it was last evaluated.
+func enforcementWindowChanged(policy *policyv1.ConfigurationPolicy, lastEvaluated, now time.Time) bool {
+	if policy.Spec == nil || policy.Spec.EnforcementSchedule == nil || !policy.Spec.RemediationAction.IsEnforce() {
+		return false
+	}
 
-		for _, oldR := range objsToDelete {
-			if !containRelated(newRelated, oldR) {
-				objShouldRemoved = append(objShouldRemoved, oldR)
-			}
-		}
+	_, nextChange, err := policy.Spec.EnforcementSchedule.InWindow(lastEvaluated)
+	if err != nil || nextChange.IsZero() {
+		return false
+	}
 
-		objsToDelete = objShouldRemoved
+	return !now.Before(nextChange)
+}
+
+// effectiveRemediationAction returns the policy's remediationAction, except that an enforce policy outside of the
+// windows of its spec.enforcementSchedule is evaluated as inform. An invalid schedule is reported before the object
+// templates are evaluated.
+func effectiveRemediationAction(policy *policyv1.ConfigurationPolicy, now time.Time) policyv1.RemediationAction {
+	if policy.Spec.EnforcementSchedule == nil || !policy.Spec.RemediationAction.IsEnforce() {
+		return policy.Spec.RemediationAction
 	}
 
-	for _, object := range objsToDelete {
-		// set up client for object deletion
-		gvk := schema.FromAPIVersionAndKind(object.Object.APIVersion, object.Object.Kind)
+	if inWindow, _, err := policy.Spec.EnforcementSchedule.InWindow(now); err == nil && !inWindow {
+		return policyv1.Inform
+	}
 
-		log := log.WithValues("policy", plc.GetName(), "groupVersionKind", gvk.String())
+	return policy.Spec.RemediationAction
+}
 
-		r.lock.RLock()
-		mapper := restmapper.NewDiscoveryRESTMapper(r.apiGroups)
-		r.lock.RUnlock()
+// usesTemplateEvaluationIntervals returns true if any object template in the policy overrides the policy's
+// spec.evaluationInterval. Only spec.object-templates is considered since this is called before the templates are
+// resolved, which is why the intervals aren't supported in object-templates-raw and objectTemplatesRawRef.
+func usesTemplateEvaluationIntervals(policy *policyv1.ConfigurationPolicy) bool {
+	if policy.Spec == nil {
+		return false
+	}
 
-		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
-		if err != nil {
-			log.Error(err, "Could not get resource mapping for child object")
+	for _, objectT := range policy.Spec.ObjectTemplates {
+		if objectT != nil && (objectT.EvaluationInterval.Compliant != "" || objectT.EvaluationInterval.NonCompliant != "") {
+			return true
+		}
+	}
 
-			deletionFailures = append(deletionFailures, gvk.String()+fmt.Sprintf(` "%s" in namespace %s`,
-				object.Object.Metadata.Name, object.Object.Metadata.Namespace))
+	return false
+}
 
-			continue
+// dueObjectTemplate returns the index of the first object template that has reached its evaluation interval, or -1 if
+// none has. The object templates with a List objectDefinition are expanded as when the policy is evaluated so that the
+// indexes match the status of each expanded object template. If the object templates can't be expanded, 0 is returned
+// so that the error is reported by an evaluation.
+func dueObjectTemplate(policy *policyv1.ConfigurationPolicy) int {
+	if policy.Spec == nil {
+		return -1
+	}
+
+	objTemps, err := expandListTemplates(policy.Spec.ObjectTemplates)
+	if err != nil {
+		return 0
+	}
+
+	for i, objectT := range objTemps {
+		if objectTemplateDue(policy, objectT, i) {
+			return i
 		}
+	}
 
-		namespaced := object.Object.Metadata.Namespace != ""
+	return -1
+}
 
-		// determine whether object should be deleted
-		needsDelete := false
+// objectTemplateDue determines if the input object template has reached its evaluation interval. The index is the
+// position of the object template once the List objectDefinitions are expanded, which is the index of its status in
+// status.compliancyDetails. The interval is from the object template's evaluationInterval, with unset values
+// defaulting to the policy's spec.evaluationInterval. The last evaluation is from the object template's status, or the
+// policy's status.lastEvaluated if the object template's is not set.
+func objectTemplateDue(policy *policyv1.ConfigurationPolicy, objectT *policyv1.ObjectTemplate, index int) bool {
+	if policy.Spec == nil || index >= len(policy.Status.CompliancyDetails) ||
+		policy.Status.LastEvaluatedGeneration != policy.Generation {
+		return true
+	}
 
-		existing, err := getObject(
-			namespaced,
-			object.Object.Metadata.Namespace,
-			object.Object.Metadata.Name,
-			mapping.Resource,
-			r.TargetK8sDynamicClient,
-		)
-		if err != nil {
-			log.Error(err, "Failed to get child object")
+	details := policy.Status.CompliancyDetails[index]
 
-			deletionFailures = append(deletionFailures, gvk.String()+fmt.Sprintf(` "%s" in namespace %s`,
-				object.Object.Metadata.Name, object.Object.Metadata.Namespace))
+	lastEvaluatedStr := details.LastEvaluated
+	if lastEvaluatedStr == "" {
+		lastEvaluatedStr = policy.Status.LastEvaluated
+	}
 
-			continue
-		}
+	lastEvaluated, err := time.Parse(time.RFC3339, lastEvaluatedStr)
+	if err != nil {
+		return true
+	}
 
-		// object does not exist, no deletion logic needed
-		if existing == nil {
-			continue
+	evaluationInterval := policy.Spec.EvaluationInterval
+
+	if objectT != nil {
+		if objectT.EvaluationInterval.Compliant != "" {
+			evaluationInterval.Compliant = objectT.EvaluationInterval.Compliant
 		}
 
-		if string(plc.Spec.PruneObjectBehavior) == "DeleteAll" {
-			needsDelete = true
-		} else if string(plc.Spec.PruneObjectBehavior) == "DeleteIfCreated" {
-			// if prune behavior is DeleteIfCreated, we need to check whether createdByPolicy
-			// is true and the UID is not stale
-			if object.Properties != nil &&
-				object.Properties.CreatedByPolicy != nil &&
-				*object.Properties.CreatedByPolicy &&
-				object.Properties.UID == string(existing.GetUID()) {
-				needsDelete = true
-			}
+		if objectT.EvaluationInterval.NonCompliant != "" {
+			evaluationInterval.NonCompliant = objectT.EvaluationInterval.NonCompliant
 		}
+	}
 
-		// delete object if needed
-		if needsDelete {
-			// if object has already been deleted and is stuck, no need to redo delete request
+	var nextEvaluation time.Time
+
+	switch details.ComplianceState {
+	case policyv1.Compliant:
+		nextEvaluation, err = evaluationInterval.GetNextCompliantEvaluation(lastEvaluated)
+	case policyv1.NonCompliant:
+		nextEvaluation, err = evaluationInterval.GetNextNonCompliantEvaluation(lastEvaluated)
+	default:
+		return true
+	}
+
+	if errors.Is(err, policyv1.ErrIsNever) {
+		return false
+	} else if err != nil {
+		return true
+	}
+
+	return !nextEvaluation.After(time.Now().UTC())
+}
+
+// templateCompliant determines if the object template at the input index is compliant based on the policy status.
+func templateCompliant(policy *policyv1.ConfigurationPolicy, index int) bool {
+	if index >= len(policy.Status.CompliancyDetails) {
+		return false
+	}
+
+	return policy.Status.CompliancyDetails[index].ComplianceState == policyv1.Compliant
+}
+
+// relatedObjectsForTemplate returns the related objects from a previous evaluation that match the kind, name, and
+// namespaces of the object template. This is used to keep the related objects of an object template that was not
+// reevaluated.
+func relatedObjectsForTemplate(
+	oldRelated []policyv1.RelatedObject, objDetails objectTemplateDetails, namespaces []string,
+) []policyv1.RelatedObject {
+	related := []policyv1.RelatedObject{}
+
+	for _, oldEntry := range oldRelated {
+		if oldEntry.Object.Kind != objDetails.kind {
+			continue
+		}
+
+		if objDetails.name != "" && oldEntry.Object.Metadata.Name != objDetails.name {
+			continue
+		}
+
+		if objDetails.isNamespaced {
+			inNamespaces := false
+
+			for _, ns := range namespaces {
+				if oldEntry.Object.Metadata.Namespace == ns {
+					inNamespaces = true
+
+					break
+				}
+			}
+
+			if !inNamespaces {
+				continue
+			}
+		} else if oldEntry.Object.Metadata.Namespace != "" {
+			continue
+		}
+
+		related = append(related, oldEntry)
+	}
+
+	return related
+}
+
+// selectorTemplateChanged returns true if the templates in the policy's namespaceSelector resolve to a different
+// result than the one used in the last evaluation. This accounts for changes to the objects referenced by the
+// templates (e.g. with fromConfigMap) since the policy itself is not updated when those change. The referenced
+// ConfigMaps are watched, so the templates are only resolved again when one of them changed. Templates whose
+// references can't be determined without resolving them are resolved again at every evaluation loop.
+func (r *ConfigurationPolicyReconciler) selectorTemplateChanged(policy *policyv1.ConfigurationPolicy) bool {
+	if policy.Spec == nil || !selectorHasTemplate(policy.Spec.NamespaceSelector) {
+		return false
+	}
+
+	// If the selector wasn't resolved before, then the other checks determine if the policy should be evaluated.
+	cachedSelector, ok := r.resolvedSelectorCache.Load(policyKey(policy))
+	if !ok {
+		return false
+	}
+
+	selResolver, err := r.selectorWatcher.get(
+		r.TargetK8sConfig,
+		r.TargetK8sClient,
+		policyKey(policy),
+		selectorConfigMapRefs(policy.Spec.NamespaceSelector),
+		r.EvaluationTriggers,
+	)
+	if err != nil {
+		log.Error(err, "Failed to instantiate a template resolver for the namespaceSelector", "policy", policy.Name)
+
+		return false
+	}
+
+	if !selResolver.consumeChange() {
+		return false
+	}
+
+	resolvedSelector, err := resolveSelectorTemplates(
+		selResolver.resolver, policy.Spec.NamespaceSelector, &templates.ResolveOptions{},
+	)
+	if err != nil {
+		// The error will be reported in the status at the next evaluation
+		log.V(2).Info("Failed to resolve the namespaceSelector templates", "policy", policy.Name, "error", err)
+
+		return false
+	}
+
+	return cachedSelector.(policyv1.Target).String() != resolvedSelector.String()
+}
+
+// objectTemplatesRawRefChanged returns true if the object referenced by spec.objectTemplatesRawRef changed since the
+// last evaluation. The object is read from the watch cache, so this doesn't make an API request.
+func (r *ConfigurationPolicyReconciler) objectTemplatesRawRefChanged(policy *policyv1.ConfigurationPolicy) bool {
+	if policy.Spec == nil || policy.Spec.ObjectTemplatesRawRef == nil || !r.rawRefNamespaceAllowed(policy) {
+		return false
+	}
+
+	// If the object wasn't retrieved before, then the other checks determine if the policy should be evaluated.
+	cachedVersion, ok := r.rawRefVersionCache.Load(policyKey(policy))
+	if !ok {
+		return false
+	}
+
+	_, resourceVersion, err := r.getObjectTemplatesRawFromRef(policyKey(policy), policy.Spec.ObjectTemplatesRawRef)
+	if err != nil && resourceVersion == "" && !k8serrors.IsNotFound(err) {
+		// The error will be reported in the status at the next evaluation
+		log.V(2).Info("Failed to get the object referenced by spec.objectTemplatesRawRef", "error", err)
+
+		return false
+	}
+
+	return cachedVersion.(string) != resourceVersion
+}
+
+// getObjectTemplatesRawFromRef returns the object templates in the ConfigMap or Secret key referenced by
+// spec.objectTemplatesRawRef and the resourceVersion of the referenced object. The resourceVersion is empty if the
+// object couldn't be retrieved. The object is read from the cache of the policy's rawRefWatcher watch.
+func (r *ConfigurationPolicyReconciler) getObjectTemplatesRawFromRef(
+	policyKey string, ref *policyv1.ObjectTemplatesRawRef,
+) (raw string, resourceVersion string, err error) {
+	obj, err := r.rawRefWatcher.get(r.TargetK8sClient, policyKey, ref, r.EvaluationTriggers)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the %s %s/%s: %w", ref.Kind, ref.Namespace, ref.Name, err)
+	}
+
+	if objMeta, err := meta.Accessor(obj); err == nil {
+		resourceVersion = objMeta.GetResourceVersion()
+	}
+
+	raw, found := rawRefData(obj, ref.Key)
+	if !found {
+		return "", resourceVersion, fmt.Errorf(
+			"the %s %s/%s does not contain the key %s", ref.Kind, ref.Namespace, ref.Name, ref.Key,
+		)
+	}
+
+	return raw, resourceVersion, nil
+}
+
+// rawRefNamespaceAllowed determines if the spec.objectTemplatesRawRef of the policy references an object in the
+// namespace of the policy or in a namespace matching a pattern in RawRefAllowedNamespaces. This prevents a policy from
+// reading the ConfigMaps and Secrets of any namespace with the controller's permissions.
+func (r *ConfigurationPolicyReconciler) rawRefNamespaceAllowed(policy *policyv1.ConfigurationPolicy) bool {
+	namespace := policy.Spec.ObjectTemplatesRawRef.Namespace
+	if namespace == policy.GetNamespace() {
+		return true
+	}
+
+	for _, pattern := range r.RawRefAllowedNamespaces {
+		// The patterns were validated when the controller started
+		if matched, _ := filepath.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getUnmetDependencies returns a description of each dependency of the policy that doesn't have its required
+// compliance state. The dependencies are read from the controller-runtime cache, which watches them, so a pending
+// policy is evaluated in the next loop after its dependencies are satisfied.
+func (r *ConfigurationPolicyReconciler) getUnmetDependencies(policy *policyv1.ConfigurationPolicy) []string {
+	unmet := []string{}
+
+	for _, dependency := range policy.Spec.Dependencies {
+		kind := dependency.Kind
+		if kind == "" {
+			kind = "ConfigurationPolicy"
+		}
+
+		namespace := dependency.Namespace
+		if namespace == "" {
+			namespace = policy.Namespace
+		}
+
+		wantCompliance := dependency.Compliance
+		if wantCompliance == "" {
+			wantCompliance = policyv1.Compliant
+		}
+
+		key := types.NamespacedName{Namespace: namespace, Name: dependency.Name}
+		depStr := fmt.Sprintf("%s %s/%s", kind, namespace, dependency.Name)
+
+		var compliance policyv1.ComplianceState
+		var err error
+
+		if kind == "OperatorPolicy" {
+			operatorPolicy := &policyv1beta1.OperatorPolicy{}
+			err = r.Get(context.TODO(), key, operatorPolicy)
+			compliance = operatorPolicy.Status.ComplianceState
+		} else {
+			configPolicy := &policyv1.ConfigurationPolicy{}
+			err = r.Get(context.TODO(), key, configPolicy)
+			compliance = configPolicy.Status.ComplianceState
+		}
+
+		switch {
+		case k8serrors.IsNotFound(err):
+			unmet = append(unmet, depStr+" was not found")
+		case err != nil:
+			unmet = append(unmet, fmt.Sprintf("%s could not be retrieved: %v", depStr, err))
+		case compliance == "":
+			unmet = append(unmet, fmt.Sprintf("%s has no compliance state, expected %s", depStr, wantCompliance))
+		case compliance != wantCompliance:
+			unmet = append(unmet, fmt.Sprintf("%s is %s, expected %s", depStr, compliance, wantCompliance))
+		}
+	}
+
+	return unmet
+}
+
+// selectorHasTemplate returns true if any value in the namespaceSelector contains a managed cluster template.
+func selectorHasTemplate(selector policyv1.Target) bool {
+	selectorJSON, err := json.Marshal(selector)
+	if err != nil {
+		return false
+	}
+
+	return templates.HasTemplate(selectorJSON, "", false)
+}
+
+// resolveSelectorTemplates runs the template resolver over the namespaceSelector so that values such as the include
+// list can come from a resource on the cluster (e.g. with fromConfigMap). Since templates resolve to a single string,
+// include, exclude, and matchExpressions values that resolve to a comma separated list are split into separate
+// entries. The input is returned unchanged if it doesn't contain any templates.
+func resolveSelectorTemplates(
+	tmplResolver *templates.TemplateResolver, selector policyv1.Target, resolveOptions *templates.ResolveOptions,
+) (policyv1.Target, error) {
+	selectorJSON, err := json.Marshal(selector)
+	if err != nil {
+		return selector, err
+	}
+
+	if !templates.HasTemplate(selectorJSON, "", false) {
+		return selector, nil
+	}
+
+	resolvedTemplate, err := tmplResolver.ResolveTemplate(selectorJSON, nil, resolveOptions)
+	if err != nil {
+		return selector, err
+	}
+
+	resolvedSelector := policyv1.Target{}
+
+	err = json.Unmarshal(resolvedTemplate.ResolvedJSON, &resolvedSelector)
+	if err != nil {
+		return selector, fmt.Errorf("the resolved namespaceSelector is invalid: %w", err)
+	}
+
+	resolvedSelector.Include = splitSelectorEntries(resolvedSelector.Include)
+	resolvedSelector.Exclude = splitSelectorEntries(resolvedSelector.Exclude)
+
+	if resolvedSelector.MatchExpressions != nil {
+		for i, expression := range *resolvedSelector.MatchExpressions {
+			values := []string{}
+
+			for _, value := range expression.Values {
+				for _, splitValue := range strings.Split(value, ",") {
+					if splitValue = strings.TrimSpace(splitValue); splitValue != "" {
+						values = append(values, splitValue)
+					}
+				}
+			}
+
+			(*resolvedSelector.MatchExpressions)[i].Values = values
+		}
+	}
+
+	return resolvedSelector, nil
+}
+
+// splitSelectorEntries splits any comma separated values in the input list into separate entries and removes empty
+// entries.
+func splitSelectorEntries(entries []policyv1.NonEmptyString) []policyv1.NonEmptyString {
+	if entries == nil {
+		return nil
+	}
+
+	splitEntries := []policyv1.NonEmptyString{}
+
+	for _, entry := range entries {
+		for _, splitEntry := range strings.Split(string(entry), ",") {
+			if splitEntry = strings.TrimSpace(splitEntry); splitEntry != "" {
+				splitEntries = append(splitEntries, policyv1.NonEmptyString(splitEntry))
+			}
+		}
+	}
+
+	return splitEntries
+}
+
+type objectTemplateDetails struct {
+	kind         string
+	name         string
+	namespace    string
+	isNamespaced bool
+	// allNamespaces is set when the metadata.namespace of a namespaced objectDefinition is "*", in which case the
+	// object template is evaluated in all the namespaces of the cluster
+	allNamespaces bool
+	namespaces    []string
+	// The fields of the objectDefinition with a '$patch: delete' directive, which must be absent from the object
+	removals []fieldRemoval
+	// listedObjects has the objects of an object template with the "*" namespace by namespace, from a single
+	// cluster-scoped list. It's nil when the objects are retrieved in each namespace instead.
+	listedObjects map[string][]unstructured.Unstructured
+}
+
+// getObjectTemplateDetails retrieves values from the object templates and returns an array of
+// objects containing the retrieved values.
+// It also gathers namespaces for this policy if necessary:
+//
+//	If a namespaceSelector is present AND objects are namespaced without a namespace specified
+//	If objects are namespaced with the namespace set to "*", in which case all the namespaces are listed
+func (r *ConfigurationPolicyReconciler) getObjectTemplateDetails(
+	plc policyv1.ConfigurationPolicy,
+) ([]objectTemplateDetails, []string, bool, error) {
+	templateObjs := make([]objectTemplateDetails, len(plc.Spec.ObjectTemplates))
+	selectedNamespaces := []string{}
+	queryNamespaces := false
+	listAllNamespaces := false
+
+	for idx, objectT := range plc.Spec.ObjectTemplates {
+		unstruct, err := unmarshalFromJSON(objectT.ObjectDefinition.Raw)
+		if err != nil {
+			return templateObjs, selectedNamespaces, false, err
+		}
+
+		templateObjs[idx].isNamespaced = r.isObjectNamespaced(&unstruct, true)
+		// strings.TrimSpace() is needed here because a multi-line value will have '\n' in it
+		templateObjs[idx].kind = strings.TrimSpace(unstruct.GetKind())
+		templateObjs[idx].name = strings.TrimSpace(unstruct.GetName())
+		templateObjs[idx].namespace = strings.TrimSpace(unstruct.GetNamespace())
+
+		if templateObjs[idx].isNamespaced && templateObjs[idx].namespace == allNamespacesSentinel {
+			templateObjs[idx].allNamespaces = true
+			templateObjs[idx].namespace = ""
+			listAllNamespaces = true
+		} else if templateObjs[idx].isNamespaced && templateObjs[idx].namespace == "" {
+			queryNamespaces = true
+		}
+	}
+
+	if listAllNamespaces {
+		allNamespaces, err := r.listAllNamespaces()
+		if err != nil {
+			errMsg := "Error listing the namespaces for the object templates with metadata.namespace set to \"*\""
+			log.Error(err, errMsg)
+
+			statusChanged := addConditionToStatus(
+				&plc, -1, false, "Error listing namespaces", fmt.Sprintf("%s: %s", errMsg, err.Error()),
+			)
+			if statusChanged {
+				r.Recorder.Event(
+					&plc,
+					eventWarning,
+					fmt.Sprintf(plcFmtStr, plc.GetName()),
+					convertPolicyStatusToString(&plc),
+				)
+			}
+
+			return templateObjs, selectedNamespaces, statusChanged, err
+		}
+
+		for idx := range templateObjs {
+			if templateObjs[idx].allNamespaces {
+				templateObjs[idx].namespaces = allNamespaces
+			}
+		}
+	}
+
+	// If required, query for namespaces specified in NamespaceSelector for objects to use
+	if queryNamespaces {
+		// Retrieve the namespaces based on filters in NamespaceSelector
+		selector := plc.Spec.NamespaceSelector
+		// If MatchLabels/MatchExpressions/Include were not provided, return no namespaces
+		if selector.MatchLabels == nil && selector.MatchExpressions == nil && len(selector.Include) == 0 {
+			r.SelectorReconciler.Stop(plc.Name)
+
+			log.V(1).Info("namespaceSelector is empty. Skipping namespace retrieval.")
+		} else {
+			// If an error occurred in the NamespaceSelector, update the policy status and abort
+			var err error
+			selectedNamespaces, err = r.SelectorReconciler.Get(plc.Name, plc.Spec.NamespaceSelector)
+			if err != nil {
+				errMsg := "Error filtering namespaces with provided namespaceSelector"
+				log.Error(
+					err, errMsg,
+					"namespaceSelector", fmt.Sprintf("%+v", selector))
+
+				reason := "namespaceSelector error"
+				msg := fmt.Sprintf(
+					"%s: %s", errMsg, err.Error())
+
+				r.userErrors.record("ConfigurationPolicy", &plc, userErrorInvalidNamespaceSel, msg)
+
+				statusChanged := addConditionToStatus(&plc, -1, false, reason, msg)
+				if statusChanged {
+					r.Recorder.Event(
+						&plc,
+						eventWarning,
+						fmt.Sprintf(plcFmtStr, plc.GetName()),
+						convertPolicyStatusToString(&plc),
+					)
+				}
+
+				return templateObjs, selectedNamespaces, statusChanged, err
+			}
+
+			if len(selectedNamespaces) == 0 {
+				log.V(1).Info("Fetching namespaces with provided NamespaceSelector returned no namespaces.",
+					"namespaceSelector", fmt.Sprintf("%+v", selector))
+			}
+		}
+	}
+
+	return templateObjs, selectedNamespaces, false, nil
+}
+
+// cleanUpChildObjects prunes the related objects of the policy that aren't in the new related objects, or all of them
+// when newRelated is empty. It returns the objects that failed to be deleted and the objects that weren't deleted
+// since they're no longer the objects the policy created.
+func (r *ConfigurationPolicyReconciler) cleanUpChildObjects(plc policyv1.ConfigurationPolicy,
+	newRelated []policyv1.RelatedObject,
+) (deletionFailures []string, skipped []string) {
+	deletionFailures = []string{}
+
+	// Objects are never deleted in preview mode
+	if !plc.Spec.RemediationAction.IsEnforce() || isPreview(&plc) {
+		return deletionFailures, skipped
+	}
+
+	// PruneObjectBehavior = none case fall in here
+	if !usesPruning(&plc) {
+		return deletionFailures, skipped
+	}
+
+	objsToDelete := plc.Status.RelatedObjects
+
+	// When spec is updated and new related objects are created
+	if len(newRelated) != 0 {
+		var objShouldRemoved []policyv1.RelatedObject
+
+		for _, oldR := range objsToDelete {
+			if !containRelated(newRelated, oldR) {
+				objShouldRemoved = append(objShouldRemoved, oldR)
+			}
+		}
+
+		objsToDelete = objShouldRemoved
+	}
+
+	for _, object := range objsToDelete {
+		// The object containing the object templates is not managed by the policy
+		if object.Reason == reasonRawTemplatesSource {
+			continue
+		}
+
+		pruneBehavior := relatedPruneBehavior(object, plc.Spec.PruneObjectBehavior)
+		if !pruneBehavior.IsPruning() {
+			continue
+		}
+
+		// set up client for object deletion
+		gvk := schema.FromAPIVersionAndKind(object.Object.APIVersion, object.Object.Kind)
+
+		log := log.WithValues("policy", plc.GetName(), "groupVersionKind", gvk.String())
+
+		r.lock.RLock()
+		mapper := restmapper.NewDiscoveryRESTMapper(r.apiGroups)
+		r.lock.RUnlock()
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			log.Error(err, "Could not get resource mapping for child object")
+
+			deletionFailures = append(deletionFailures, gvk.String()+fmt.Sprintf(` "%s" in namespace %s`,
+				object.Object.Metadata.Name, object.Object.Metadata.Namespace))
+
+			continue
+		}
+
+		namespaced := object.Object.Metadata.Namespace != ""
+
+		if reason, _ := r.enforcementRestriction(object.Object.Metadata.Namespace, gvk.GroupKind()); reason != "" {
+			log.Info(
+				"Not deleting the child object since it's restricted by the controller configuration",
+				"name", object.Object.Metadata.Name, "namespace", object.Object.Metadata.Namespace, "reason", reason,
+			)
+
+			continue
+		}
+
+		// determine whether object should be deleted
+		needsDelete := false
+
+		existing, err := getObject(
+			namespaced,
+			object.Object.Metadata.Namespace,
+			object.Object.Metadata.Name,
+			mapping.Resource,
+			r.TargetK8sDynamicClient,
+		)
+		if err != nil {
+			log.Error(err, "Failed to get child object")
+
+			deletionFailures = append(deletionFailures, gvk.String()+fmt.Sprintf(` "%s" in namespace %s`,
+				object.Object.Metadata.Name, object.Object.Metadata.Namespace))
+
+			continue
+		}
+
+		// object does not exist, no deletion logic needed
+		if existing == nil {
+			continue
+		}
+
+		if pruneBehavior == "DeleteAll" {
+			needsDelete = true
+		} else if pruneBehavior == "DeleteIfCreated" {
+			// if prune behavior is DeleteIfCreated, we need to check whether createdByPolicy
+			// is true and the UID is not stale
+			if object.Properties != nil &&
+				object.Properties.CreatedByPolicy != nil &&
+				*object.Properties.CreatedByPolicy &&
+				object.Properties.UID == string(existing.GetUID()) {
+				needsDelete = true
+			}
+		}
+
+		// delete object if needed
+		if needsDelete {
+			// if object has already been deleted and is stuck, no need to redo delete request
 			_, deletionTimeFound, _ := unstructured.NestedString(existing.Object, "metadata", "deletionTimestamp")
 			if deletionTimeFound {
 				log.Error(fmt.Errorf("tried to delete object, but delete is hanging"), "Error")
@@ -651,13 +1561,55 @@ func (r *ConfigurationPolicyReconciler) cleanUpChildObjects(plc policyv1.Configu
 				res = r.TargetK8sDynamicClient.Resource(mapping.Resource)
 			}
 
-			if completed, err := deleteObject(res, object.Object.Metadata.Name,
-				object.Object.Metadata.Namespace); !completed {
+			deleteOptions := metav1.DeleteOptions{}
+
+			// The UID precondition prevents deleting an object that was recreated by someone else since the policy
+			// created it
+			if pruneBehavior == "DeleteIfCreated" {
+				uid := types.UID(object.Properties.UID)
+				deleteOptions.Preconditions = &metav1.Preconditions{UID: &uid}
+			}
+
+			completed, err := deleteObject(
+				res, object.Object.Metadata.Name, object.Object.Metadata.Namespace, deleteOptions,
+			)
+			if isUIDPreconditionFailed(err) {
+				log.Info(
+					"Not deleting the child object since it's no longer the object the policy created",
+					"name", object.Object.Metadata.Name, "namespace", object.Object.Metadata.Namespace,
+					"uid", object.Properties.UID,
+				)
+
+				msg := gvk.String() + fmt.Sprintf(
+					` "%s" in namespace %s isn't deleted since it's no longer the object the policy created`,
+					object.Object.Metadata.Name, object.Object.Metadata.Namespace,
+				)
+
+				skipped = append(skipped, msg)
+
+				if r.Recorder != nil {
+					r.Recorder.Event(&plc, eventWarning, eventReasonPruneSkipped, msg)
+				}
+
+				continue
+			}
+
+			if !completed {
 				deletionFailures = append(deletionFailures, gvk.String()+fmt.Sprintf(` "%s" in namespace %s`,
 					object.Object.Metadata.Name, object.Object.Metadata.Namespace))
 
 				log.Error(err, "Error: Failed to delete object during child object pruning")
 			} else {
+				// There's no object template for a related object, so no deletion record is written
+				r.sendMutationEvent(singleObject{
+					policy:      &plc,
+					gvr:         mapping.Resource,
+					existingObj: existing,
+					name:        object.Object.Metadata.Name,
+					namespace:   object.Object.Metadata.Namespace,
+					namespaced:  namespaced,
+				}, nil, existing, eventReasonPolicyDeleted)
+
 				obj, _ := getObject(
 					namespaced,
 					object.Object.Metadata.Namespace,
@@ -680,7 +1632,42 @@ func (r *ConfigurationPolicyReconciler) cleanUpChildObjects(plc policyv1.Configu
 		}
 	}
 
-	return deletionFailures
+	return deletionFailures, skipped
+}
+
+// usesPruning determines if the policy deletes any objects when it's deleted based on the pruneObjectBehavior of the
+// policy, of its object templates, and of its related objects. The related objects account for the object templates
+// that are only known once they are resolved, such as with object-templates-raw.
+func usesPruning(plc *policyv1.ConfigurationPolicy) bool {
+	if plc.Spec.PruneObjectBehavior.IsPruning() {
+		return true
+	}
+
+	for _, objectT := range plc.Spec.ObjectTemplates {
+		if objectT != nil && objectT.PruneObjectBehavior.IsPruning() {
+			return true
+		}
+	}
+
+	for _, object := range plc.Status.RelatedObjects {
+		if object.Properties != nil && object.Properties.PruneObjectBehavior.IsPruning() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// relatedPruneBehavior returns the pruneObjectBehavior that applies to the related object, which is the one of its
+// object template when it overrides the input pruneObjectBehavior of the policy.
+func relatedPruneBehavior(
+	object policyv1.RelatedObject, policyBehavior policyv1.PruneObjectBehavior,
+) policyv1.PruneObjectBehavior {
+	if object.Properties != nil && object.Properties.PruneObjectBehavior != "" {
+		return object.Properties.PruneObjectBehavior
+	}
+
+	return policyBehavior
 }
 
 // cleanupImmediately returns true (i.e. beingUninstalled or crdDeleting) when the cluster is in a state where
@@ -737,6 +1724,18 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 	log := log.WithValues("policy", plc.GetName())
 	log.V(1).Info("Processing object templates")
 
+	// The evaluation is recorded once the status is written, with the compliance state it resulted in
+	before := time.Now()
+
+	defer func() {
+		recordPolicyEvaluation(plc.Name, plc.Status.ComplianceState, time.Since(before))
+	}()
+
+	// The policy is only evaluated again right away if its enforcement is throttled again in this evaluation
+	r.throttledPolicyCache.Delete(plc.GetUID())
+	// The compliance event only has the diffs of this evaluation
+	r.eventDiffCache.Delete(plc.Namespace + "/" + plc.Name)
+
 	// initialize the RelatedObjects for this Configuration Policy
 	oldRelated := append([]policyv1.RelatedObject{}, plc.Status.RelatedObjects...)
 	relatedObjects := []policyv1.RelatedObject{}
@@ -762,18 +1761,13 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 
 		r.checkRelatedAndUpdate(plc, relatedObjects, oldRelated, statusChanged, true)
 
-		parent := ""
-		if len(plc.OwnerReferences) > 0 {
-			parent = plc.OwnerReferences[0].Name
-		}
-
-		policyUserErrorsCounter.WithLabelValues(parent, plc.GetName(), "invalid-template").Add(1)
+		r.userErrors.record("ConfigurationPolicy", &plc, userErrorInvalidTemplate, message)
 
 		return
 	}
 
 	// object handling for when configurationPolicy is deleted
-	if plc.Spec.PruneObjectBehavior == "DeleteIfCreated" || plc.Spec.PruneObjectBehavior == "DeleteAll" {
+	if usesPruning(&plc) {
 		var crdDeleting bool
 
 		if !r.UninstallMode {
@@ -827,11 +1821,13 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 		if plc.ObjectMeta.DeletionTimestamp != nil {
 			log.Info("Config policy has been deleted, handling child objects")
 
-			failures := r.cleanUpChildObjects(plc, nil)
+			failures, skipped := r.cleanUpChildObjects(plc, nil)
 
 			if len(failures) == 0 {
 				log.Info("Objects have been successfully cleaned up, removing finalizer")
 
+				recordComplianceEventPruned(&r.complianceEvents, plc.Namespace, plc.Name)
+
 				patch := removeObjFinalizerPatch(&plc, pruneObjectFinalizer)
 
 				err := r.Patch(context.TODO(), &plc, client.RawPatch(types.JSONPatchType, patch))
@@ -851,7 +1847,11 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 					"Failed to delete objects: "+strings.Join(failures, ", "))
 				if statusChanged {
 					parentStatusUpdateNeeded = true
+				}
+
+				setPruneSkippedCondition(&plc, skipped)
 
+				if statusChanged && !noncomplianceGracePending(&plc, time.Now()) {
 					r.Recorder.Event(
 						&plc,
 						eventWarning,
@@ -878,6 +1878,27 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 		}
 	}
 
+	// Nothing is evaluated or enforced until the dependencies are satisfied
+	if unmetDependencies := r.getUnmetDependencies(&plc); len(unmetDependencies) != 0 {
+		msg := "Waiting for the dependencies: " + strings.Join(unmetDependencies, "; ")
+		log.V(1).Info("The policy has unmet dependencies", "dependencies", unmetDependencies)
+
+		statusChanged := addConditionToStatus(&plc, -1, false, reasonDependenciesUnmet, msg)
+		if statusChanged && !noncomplianceGracePending(&plc, time.Now()) {
+			r.Recorder.Event(
+				&plc,
+				eventNormal,
+				fmt.Sprintf(plcFmtStr, plc.GetName()),
+				convertPolicyStatusToString(&plc),
+			)
+		}
+
+		// The related objects aren't changed since the object templates weren't evaluated
+		r.checkRelatedAndUpdate(plc, oldRelated, oldRelated, statusChanged, false)
+
+		return
+	}
+
 	// When it is hub or managed template parse error, deleteDetachedObjs should be false
 	// Then it doesn't remove resources
 	addTemplateErrorViolation := func(reason, msg string) {
@@ -887,10 +1908,14 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 			reason = "Error processing template"
 		}
 
+		r.userErrors.record("ConfigurationPolicy", &plc, userErrorTemplateError, msg)
+
 		statusChanged := addConditionToStatus(&plc, -1, false, reason, msg)
 		if statusChanged {
 			parentStatusUpdateNeeded = true
+		}
 
+		if statusChanged && !noncomplianceGracePending(&plc, time.Now()) {
 			r.Recorder.Event(
 				&plc,
 				eventWarning,
@@ -938,6 +1963,58 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 		}
 	}
 
+	sizeErr := validateObjectTemplateSizes(plc.Spec, r.MaxObjectDefinitionBytes, r.MaxObjectTemplatesBytes)
+	if sizeErr != nil {
+		addTemplateErrorViolation(reasonObjectDefinitionTooLarge, sizeErr.Error())
+
+		return
+	}
+
+	if plc.Spec.ObjectTemplatesRawRef != nil {
+		ref := plc.Spec.ObjectTemplatesRawRef
+
+		if len(plc.Spec.ObjectTemplates) != 0 || plc.Spec.ObjectTemplatesRaw != "" {
+			addTemplateErrorViolation(
+				"Invalid objectTemplatesRawRef",
+				"spec.objectTemplatesRawRef can't be set with spec.object-templates or spec.object-templates-raw",
+			)
+
+			return
+		}
+
+		if !r.rawRefNamespaceAllowed(&plc) {
+			r.rawRefVersionCache.Delete(policyKey(&plc))
+			r.rawRefWatcher.stop(policyKey(&plc))
+
+			addTemplateErrorViolation("Invalid objectTemplatesRawRef", fmt.Sprintf(
+				"the %s %s/%s can't be referenced since spec.objectTemplatesRawRef is limited to the policy namespace "+
+					"and the namespaces allowed by the controller configuration (--raw-ref-allowed-namespaces)",
+				ref.Kind, ref.Namespace, ref.Name,
+			))
+
+			return
+		}
+
+		rawTemplates, resourceVersion, err := r.getObjectTemplatesRawFromRef(policyKey(&plc), ref)
+		r.rawRefVersionCache.Store(policyKey(&plc), resourceVersion)
+
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: strings.ToLower(ref.Kind) + "s"}
+		relatedObjects = append(relatedObjects, addRelatedObjects(
+			err == nil, gvr, ref.Kind, ref.Namespace, true, []string{ref.Name}, reasonRawTemplatesSource, nil,
+		)...)
+
+		if err != nil {
+			addTemplateErrorViolation("Error loading the object-templates-raw reference", err.Error())
+
+			return
+		}
+
+		plc.Spec.ObjectTemplatesRaw = rawTemplates
+	} else {
+		r.rawRefVersionCache.Delete(policyKey(&plc))
+		r.rawRefWatcher.stop(policyKey(&plc))
+	}
+
 	// set up raw data for template processing
 	var rawDataList [][]byte
 	var isRawObjTemplate bool
@@ -954,7 +2031,13 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 
 	tmplResolverCfg.InputIsYAML = isRawObjTemplate
 
-	tmplResolver, err := templates.NewResolver(r.TargetK8sConfig, tmplResolverCfg)
+	// The objects looked up by the templates are watched so that a change causes the policy to be evaluated again
+	watchLookups := !disableTemplates && r.watchesTemplateLookups(&plc)
+	if !watchLookups {
+		r.stopTemplateWatches(plc.Namespace, plc.Name)
+	}
+
+	tmplResolver, err := r.newTemplateResolver(tmplResolverCfg, watchLookups)
 	if err != nil {
 		log.Error(err, "Failed to instantiate a template resolver")
 		addTemplateErrorViolation("", err.Error())
@@ -964,11 +2047,83 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 
 	log.V(2).Info("Processing the object templates", "count", len(plc.Spec.ObjectTemplates))
 
+	// This must be checked before the object templates that use .ObjectNamespace are rendered and before
+	// getObjectTemplateDetails since getting the selected namespaces resets it
+	namespacesUpdated := r.SelectorReconciler.HasUpdate(plc.Name)
+
+	// The placeholders of the object templates whose templates exceeded a limit, with the reason as the value
+	limitedTemplates := map[*policyv1.ObjectTemplate]string{}
+
+	// The template resolution metrics only include the policies that have managed cluster templates
+	hasTemplates := false
+
 	if !disableTemplates {
 		startTime := time.Now().UTC()
 
+		// All the lookups of this evaluation are in a single query batch, so an object looked up several times is only
+		// retrieved once, and the watches of the objects that are no longer looked up are stopped when it ends
+		if watchLookups {
+			watcherID := templateWatcherID(plc.Namespace, plc.Name)
+
+			if err := r.DynamicWatcher.StartQueryBatch(watcherID); err != nil {
+				log.Error(err, "Failed to start a query batch for the policy templates")
+				addTemplateErrorViolation("", err.Error())
+
+				return
+			}
+
+			defer func() {
+				if err := r.DynamicWatcher.EndQueryBatch(watcherID); err != nil {
+					log.Error(err, "Failed to end the query batch of the policy templates")
+				}
+			}()
+
+			resolveOptions.Watcher = &watcherID
+		}
+
+		// Resolve the templates in the namespaceSelector once per evaluation and cache the result so that changes to
+		// the objects referenced by the templates can be detected between evaluations. This is done first since the
+		// object templates that use .ObjectNamespace are rendered for each selected namespace.
+		if selectorHasTemplate(plc.Spec.NamespaceSelector) {
+			hasTemplates = true
+
+			// Watch the ConfigMaps referenced by the templates before resolving them so that only the changes after
+			// this point cause the templates to be resolved again. A failure is logged by selectorTemplateChanged.
+			selResolver, watchErr := r.selectorWatcher.get(
+				r.TargetK8sConfig,
+				r.TargetK8sClient,
+				policyKey(&plc),
+				selectorConfigMapRefs(plc.Spec.NamespaceSelector),
+				r.EvaluationTriggers,
+			)
+			if watchErr == nil {
+				selResolver.consumeChange()
+			}
+
+			resolvedSelector, selectorErr := resolveSelectorTemplates(
+				tmplResolver, plc.Spec.NamespaceSelector, &resolveOptions,
+			)
+			if selectorErr != nil {
+				recordTemplateResolution(plc.GetName(), startTime, selectorErr)
+				addTemplateErrorViolation("Error processing the namespaceSelector template", selectorErr.Error())
+
+				return
+			}
+
+			r.resolvedSelectorCache.Store(policyKey(&plc), resolvedSelector)
+
+			plc.Spec.NamespaceSelector = resolvedSelector
+		} else {
+			r.resolvedSelectorCache.Delete(policyKey(&plc))
+			r.selectorWatcher.stop(policyKey(&plc))
+		}
+
 		var objTemps []*policyv1.ObjectTemplate
 
+		// The object templates with their templates resolved, which can be more than the input object templates when
+		// they're rendered for each selected namespace
+		resolvedTemps := make([]*policyv1.ObjectTemplate, 0, len(plc.Spec.ObjectTemplates))
+
 		// process object templates for go template usage
 		for i, rawData := range rawDataList {
 			// first check to make sure there are no hub-templates with delimiter - {{hub
@@ -988,6 +2143,10 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 					"message", hubTemplatesErrMsg,
 				)
 
+				if hasTemplates {
+					recordTemplateResolution(plc.GetName(), startTime, errors.New(hubTemplatesErrMsg))
+				}
+
 				addTemplateErrorViolation("Error processing hub templates", hubTemplatesErrMsg)
 
 				return
@@ -996,74 +2155,173 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 			if templates.HasTemplate(rawData, "", true) {
 				log.V(1).Info("Processing policy templates")
 
+				hasTemplates = true
+
 				// If there's a template, we can't rely on the cache results.
 				r.processedPolicyCache.Delete(plc.GetUID())
 
-				resolvedTemplate, tplErr := tmplResolver.ResolveTemplate(rawData, nil, &resolveOptions)
-
-				// If the error is because the padding is invalid, this either means the encrypted value was not
-				// generated by the "protect" template function or the AES key is incorrect. Control for a stale
-				// cached key.
-				if usedKeyCache && (errors.Is(tplErr, templates.ErrInvalidPKCS7Padding) ||
-					errors.Is(tplErr, templates.ErrInvalidAESKey) ||
-					errors.Is(tplErr, templates.ErrAESKeyNotSet)) {
-					log.V(2).Info(
-						"The template decryption failed likely due to an invalid encryption key, will refresh " +
-							"the encryption key cache and try the decryption again",
-					)
-					var encryptionConfig templates.EncryptionConfig
+				renderNamespaces := []string{""}
 
-					encryptionConfig, usedKeyCache, err = r.getEncryptionConfig(plc, true)
+				if usesObjectNamespace(rawData) {
+					renderNamespaces, err = r.objectNamespaces(plc)
 					if err != nil {
-						addTemplateErrorViolation("", err.Error())
+						recordTemplateResolution(plc.GetName(), startTime, err)
+						addTemplateErrorViolation(
+							"Error filtering namespaces with provided namespaceSelector", err.Error(),
+						)
 
 						return
 					}
 
-					resolveOptions.EncryptionConfig = encryptionConfig
-
-					resolvedTemplate, tplErr = tmplResolver.ResolveTemplate(rawData, nil, &resolveOptions)
+					if len(renderNamespaces) == 0 {
+						log.V(1).Info(
+							"Skipping the object templates that refer to .ObjectNamespace since the namespaceSelector "+
+								"selected no namespaces",
+							"index", i,
+						)
+					}
 				}
 
-				if tplErr != nil {
-					var msg string
+				tmplContext := templateContext{PolicyName: plc.Name, PolicyNamespace: plc.Namespace}
+
+				// The resolved object templates of this object template are discarded if one of its renders exceeds a
+				// template limit
+				firstResolved := len(resolvedTemps)
 
-					if errors.Is(tplErr, templates.ErrInvalidAESKey) || errors.Is(tplErr, templates.ErrAESKeyNotSet) {
-						msg = `The "policy-encryption-key" Secret contains an invalid AES key`
-					} else if errors.Is(tplErr, templates.ErrInvalidIV) {
-						msg = fmt.Sprintf(
-							`The "%s" annotation value is not a valid initialization vector`, IVAnnotation,
+				var limitErr error
+
+				for _, renderNamespace := range renderNamespaces {
+					tmplContext.ObjectNamespace = renderNamespace
+
+					resolvedTemplate, tplErr := r.resolveTemplate(&plc, tmplResolver, rawData, tmplContext, resolveOptions)
+
+					// If the error is because the padding is invalid, this either means the encrypted value was not
+					// generated by the "protect" template function or the AES key is incorrect. Control for a stale
+					// cached key.
+					if usedKeyCache && (errors.Is(tplErr, templates.ErrInvalidPKCS7Padding) ||
+						errors.Is(tplErr, templates.ErrInvalidAESKey) ||
+						errors.Is(tplErr, templates.ErrAESKeyNotSet)) {
+						log.V(2).Info(
+							"The template decryption failed likely due to an invalid encryption key, will refresh " +
+								"the encryption key cache and try the decryption again",
 						)
-					} else {
-						msg = tplErr.Error()
+						var encryptionConfig templates.EncryptionConfig
+
+						encryptionConfig, usedKeyCache, err = r.getEncryptionConfig(plc, true)
+						if err != nil {
+							recordTemplateResolution(plc.GetName(), startTime, err)
+							addTemplateErrorViolation("", err.Error())
+
+							return
+						}
+
+						resolveOptions.EncryptionConfig = encryptionConfig
+
+						resolvedTemplate, tplErr = r.resolveTemplate(&plc, tmplResolver, rawData, tmplContext, resolveOptions)
 					}
 
-					addTemplateErrorViolation("", msg)
+					if isTemplateLimitError(tplErr) {
+						limitErr = tplErr
 
-					return
+						break
+					}
+
+					if tplErr != nil {
+						recordTemplateResolution(plc.GetName(), startTime, tplErr)
+
+						var msg string
+
+						if errors.Is(tplErr, templates.ErrInvalidAESKey) || errors.Is(tplErr, templates.ErrAESKeyNotSet) {
+							msg = `The "policy-encryption-key" Secret contains an invalid AES key`
+						} else if errors.Is(tplErr, templates.ErrInvalidIV) {
+							msg = fmt.Sprintf(
+								`The "%s" annotation value is not a valid initialization vector`, IVAnnotation,
+							)
+						} else {
+							msg = templateErrorMessage(tplErr, i, isRawObjTemplate)
+						}
+
+						addTemplateErrorViolation("", msg)
+
+						return
+					}
+
+					// If raw data, only one passthrough is needed, since all the object templates are in it
+					if isRawObjTemplate {
+						var renderedTemps []*policyv1.ObjectTemplate
+
+						err := json.Unmarshal(resolvedTemplate.ResolvedJSON, &renderedTemps)
+						if err != nil {
+							recordTemplateResolution(plc.GetName(), startTime, err)
+							addTemplateErrorViolation("Error unmarshalling raw template", err.Error())
+
+							return
+						}
+
+						for _, renderedT := range renderedTemps {
+							objTemps = append(objTemps, r.withRenderedNamespace(renderedT, renderNamespace))
+						}
+
+						continue
+					}
+
+					// Otherwise, set the resolved data for use in further processing
+					resolvedT := plc.Spec.ObjectTemplates[i].DeepCopy()
+					resolvedT.ObjectDefinition.Raw = resolvedTemplate.ResolvedJSON
+
+					resolvedTemps = append(resolvedTemps, r.withRenderedNamespace(resolvedT, renderNamespace))
 				}
 
-				// If raw data, only one passthrough is needed, since all the object templates are in it
-				if isRawObjTemplate {
-					err := json.Unmarshal(resolvedTemplate.ResolvedJSON, &objTemps)
-					if err != nil {
-						addTemplateErrorViolation("Error unmarshalling raw template", err.Error())
+				if limitErr != nil {
+					// All the object templates are in object-templates-raw, so there are no others to evaluate
+					if isRawObjTemplate {
+						recordTemplateResolution(plc.GetName(), startTime, limitErr)
+						addTemplateErrorViolation(reasonTemplateLimitExceeded, limitErr.Error())
 
 						return
 					}
 
+					log.Info(
+						"Skipping the object template since its template resolution exceeded a limit",
+						"index", i, "error", limitErr.Error(),
+					)
+
+					// The other object templates are still resolved, so only the error is recorded for now
+					recordTemplateResolutionError(plc.GetName(), limitErr)
+
+					placeholder := templateLimitPlaceholder(plc.Spec.ObjectTemplates[i])
+					limitedTemplates[placeholder] = fmt.Sprintf("object-templates[%d]: %s", i, limitErr.Error())
+					resolvedTemps = append(resolvedTemps[:firstResolved], placeholder)
+
+					// A resolution that timed out may still be using the template resolver in the background
+					if errors.Is(limitErr, errTemplateTimeout) {
+						tmplResolver, err = r.newTemplateResolver(tmplResolverCfg, watchLookups)
+						if err != nil {
+							recordTemplateResolution(plc.GetName(), startTime, err)
+							log.Error(err, "Failed to instantiate a template resolver")
+							addTemplateErrorViolation("", err.Error())
+
+							return
+						}
+					}
+
+					continue
+				}
+
+				if isRawObjTemplate {
 					plc.Spec.ObjectTemplates = objTemps
 
 					break
 				}
-
-				// Otherwise, set the resolved data for use in further processing
-				plc.Spec.ObjectTemplates[i].ObjectDefinition.Raw = resolvedTemplate.ResolvedJSON
 			} else if isRawObjTemplate {
 				// Unmarshal raw template YAML into object if that has not already been done by the template
 				// resolution function
 				err = yaml.Unmarshal(rawData, &objTemps)
 				if err != nil {
+					if hasTemplates {
+						recordTemplateResolution(plc.GetName(), startTime, err)
+					}
+
 					addTemplateErrorViolation("Error parsing the YAML in the object-templates-raw field", err.Error())
 
 					return
@@ -1072,9 +2330,19 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 				plc.Spec.ObjectTemplates = objTemps
 
 				break
+			} else {
+				resolvedTemps = append(resolvedTemps, plc.Spec.ObjectTemplates[i])
 			}
 		}
 
+		if !isRawObjTemplate {
+			plc.Spec.ObjectTemplates = resolvedTemps
+		}
+
+		if hasTemplates {
+			recordTemplateResolution(plc.GetName(), startTime, nil)
+		}
+
 		if r.EnableMetrics {
 			durationSeconds := time.Since(startTime).Seconds()
 			plcTempsProcessSecondsCounter.WithLabelValues(plc.GetName()).Add(durationSeconds)
@@ -1082,11 +2350,48 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 		}
 	}
 
+	// The rendered object templates are output for debugging before the List and kind only object templates are
+	// expanded so that they match what the templates rendered
+	recordRenderedTemplates(&plc, hasTemplates || usesEncryption(plc))
+
+	// The per object template evaluation intervals are checked before the templates are resolved, so the ones set in
+	// object-templates-raw or objectTemplatesRawRef would never apply
+	if isRawObjTemplate {
+		for i, objectT := range plc.Spec.ObjectTemplates {
+			if objectT != nil && (objectT.EvaluationInterval.Compliant != "" ||
+				objectT.EvaluationInterval.NonCompliant != "") {
+				addTemplateErrorViolation("Invalid evaluationInterval", fmt.Sprintf(
+					"object-templates-raw[%d] sets evaluationInterval, which is only supported in object-templates. "+
+						"Set spec.evaluationInterval instead.", i,
+				))
+
+				return
+			}
+		}
+	}
+
+	// Each item of a List objectDefinition is evaluated as its own object template
+	expandedTemplates, err := expandListTemplates(plc.Spec.ObjectTemplates)
+	if err != nil {
+		addTemplateErrorViolation("Invalid List objectDefinition", err.Error())
+
+		return
+	}
+
+	// The objectDefinitions without an apiVersion use the preferred version of their kind on the cluster
+	plc.Spec.ObjectTemplates = r.resolveKindOnlyTemplates(expandedTemplates)
+
 	// Parse and fetch details from each object in each objectTemplate, and gather namespaces if required
 	var templateObjs []objectTemplateDetails
 	var selectedNamespaces []string
 	var objTmplStatusChangeNeeded bool
 
+	// The per object template evaluation intervals don't apply when the policy was updated or its evaluation was
+	// triggered by annotation. Note that this is determined now since the status is updated while the object templates
+	// are processed.
+	perTemplateIntervals := usesTemplateEvaluationIntervals(&plc) &&
+		plc.Status.LastEvaluatedGeneration == plc.Generation && !evaluationTriggered(&plc)
+
 	templateObjs, selectedNamespaces, objTmplStatusChangeNeeded, err = r.getObjectTemplateDetails(plc)
 
 	// Set the CompliancyDetails array length accordingly in case the number of
@@ -1117,66 +2422,312 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 		if statusUpdateNeeded {
 			eventType := eventNormal
 
-			r.Recorder.Event(&plc, eventType, fmt.Sprintf(plcFmtStr, plc.GetName()),
-				convertPolicyStatusToString(&plc))
+			r.Recorder.Event(&plc, eventType, fmt.Sprintf(plcFmtStr, plc.GetName()),
+				convertPolicyStatusToString(&plc))
+		}
+
+		r.checkRelatedAndUpdate(plc, relatedObjects, oldRelated, statusUpdateNeeded, true)
+
+		return
+	}
+
+	if err := validateEvaluationInterval(plc.Spec.EvaluationInterval); err != nil {
+		addTemplateErrorViolation("Invalid evaluationInterval", "spec.evaluationInterval: "+err.Error())
+
+		return
+	}
+
+	for indx, objectT := range plc.Spec.ObjectTemplates {
+		if err := validateEvaluationInterval(objectT.EvaluationInterval); err != nil {
+			addTemplateErrorViolation(
+				"Invalid evaluationInterval", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+			)
+
+			return
+		}
+
+		if _, err := parseIgnoreFields(objectT.IgnoreFields); err != nil {
+			addTemplateErrorViolation(
+				"Invalid ignoreFields", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+			)
+
+			return
+		}
+
+		if _, err := parseTolerateExternalFields(objectT.TolerateExternalFields); err != nil {
+			addTemplateErrorViolation(
+				"Invalid tolerateExternalFields", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+			)
+
+			return
+		}
+
+		if _, err := parseUnorderedLists(objectT.UnorderedLists); err != nil {
+			addTemplateErrorViolation(
+				"Invalid unorderedLists", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+			)
+
+			return
+		}
+
+		if _, err := parseListMergeKeys(objectT.ListMergeKeys); err != nil {
+			addTemplateErrorViolation(
+				"Invalid listMergeKeys", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+			)
+
+			return
+		}
+
+		if len(objectT.ObjectChecks) != 0 {
+			var checksErr string
+
+			if _, err := parseObjectChecks(objectT.ObjectChecks); err != nil {
+				checksErr = err.Error()
+			} else if plc.Spec.RemediationAction.IsEnforce() {
+				checksErr = "objectChecks can only be used with the inform remediationAction"
+			} else if objectT.ComplianceType.IsMustNotHave() {
+				checksErr = "objectChecks can't be used with mustnothave"
+			}
+
+			if checksErr != "" {
+				addTemplateErrorViolation(
+					"Invalid objectChecks", fmt.Sprintf("object-templates[%d]: %s", indx, checksErr),
+				)
+
+				return
+			}
+		}
+
+		// The status can't be enforced, so it's only compared when the policy is inform
+		if plc.Spec.RemediationAction.IsEnforce() && !objectT.ComplianceType.IsMustNotHave() {
+			objDef := map[string]interface{}{}
+
+			// An invalid objectDefinition is reported when the object template is evaluated
+			if err := json.Unmarshal(objectT.ObjectDefinition.Raw, &objDef); err == nil {
+				if _, ok := objDef["status"]; ok {
+					addTemplateErrorViolation(
+						"InvalidPolicySpec",
+						fmt.Sprintf("object-templates[%d]: the status can only be compared with the inform "+
+							"remediationAction", indx),
+					)
+
+					return
+				}
+			}
+		}
+
+		if objectT.ObjectSelector != nil {
+			if templateObjs[indx].name != "" {
+				addTemplateErrorViolation(
+					"Invalid objectSelector",
+					fmt.Sprintf("object-templates[%d]: objectSelector can only be set on object templates without a "+
+						"name", indx),
+				)
+
+				return
+			}
+
+			if _, err := metav1.LabelSelectorAsSelector(objectT.ObjectSelector); err != nil {
+				addTemplateErrorViolation(
+					"Invalid objectSelector", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+				)
+
+				return
+			}
+		}
+
+		if objectT.CheckExistenceOnly && objectT.ComplianceType.IsMustOnlyHave() {
+			addTemplateErrorViolation(
+				"Invalid checkExistenceOnly",
+				fmt.Sprintf("object-templates[%d]: checkExistenceOnly can't be set with the mustonlyhave "+
+					"compliance type", indx),
+			)
+
+			return
+		}
+
+		if err := validateObjectCounts(&plc, objectT, templateObjs[indx]); err != nil {
+			addTemplateErrorViolation(
+				"Invalid object count assertion", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+			)
+
+			return
+		}
+
+		// A server-side apply only sets the fields of the objectDefinition, so it can't remove the other fields
+		if plc.Spec.EnforcementMethod == policyv1.EnforcementMethodServerSideApply &&
+			objectT.ComplianceType.IsMustOnlyHave() {
+			addTemplateErrorViolation(
+				"Invalid complianceType", fmt.Sprintf(
+					"object-templates[%d]: the mustonlyhave complianceType isn't supported with the "+
+						"ServerSideApply enforcementMethod",
+					indx,
+				),
+			)
+
+			return
+		}
+
+		directivesErr := validateRemovalDirectives(
+			objectT.ObjectDefinition.Raw,
+			objectT.ComplianceType.IsMustNotHave(),
+			plc.Spec.EnforcementMethod == policyv1.EnforcementMethodServerSideApply,
+		)
+		if directivesErr != nil {
+			addTemplateErrorViolation(
+				"Invalid $patch directive", fmt.Sprintf("object-templates[%d]: %s", indx, directivesErr.Error()),
+			)
+
+			return
+		}
+
+		if err := validateAllNamespaces(&plc, objectT, templateObjs[indx]); err != nil {
+			addTemplateErrorViolation(
+				"Invalid namespace", fmt.Sprintf("object-templates[%d]: %s", indx, err.Error()),
+			)
+
+			return
+		}
+	}
+
+	// Outside of the enforcement schedule's windows, the noncompliant messages say when enforcement resumes
+	enforcementDeferredUntil := ""
+
+	if plc.Spec.EnforcementSchedule != nil && plc.Spec.RemediationAction.IsEnforce() {
+		inWindow, nextWindow, err := plc.Spec.EnforcementSchedule.InWindow(time.Now())
+		if err != nil {
+			addTemplateErrorViolation("Invalid enforcementSchedule", err.Error())
+
+			return
+		}
+
+		if !inWindow {
+			enforcementDeferredUntil = nextWindow.Format(time.RFC3339)
+		}
+	}
+
+	// With ordered evaluation, this is the index of the first object template that is not compliant
+	blockingTemplate := -1
+
+	createdRelated := map[string]bool{}
+
+	for _, object := range oldRelated {
+		if isCreatedByPolicy(object) {
+			createdRelated[getObjectString(object)] = true
+		}
+	}
+
+	// When the object templates have their own evaluation intervals, the object templates that haven't reached theirs
+	// are skipped and keep their previous results so that the policy compliance aggregates all of them.
+	templateDue := func(indx int) bool {
+		if _, limited := limitedTemplates[plc.Spec.ObjectTemplates[indx]]; limited {
+			return false
+		}
+
+		usesSelectedNamespaces := templateObjs[indx].isNamespaced && templateObjs[indx].namespace == ""
+
+		return !perTemplateIntervals || (namespacesUpdated && usesSelectedNamespaces) ||
+			objectTemplateDue(&plc, plc.Spec.ObjectTemplates[indx], indx)
+	}
+
+	// The object templates are evaluated concurrently, except with ordered evaluation since an object template is then
+	// only evaluated when the previous ones are compliant. The results are processed in order below so that the status
+	// stays deterministic.
+	var evaluations []*templateEvaluation
+
+	if !plc.Spec.OrderedEvaluation && r.TemplateEvaluationConcurrency > 1 {
+		evaluations = r.evaluateObjectTemplates(&plc, templateObjs, selectedNamespaces, templateDue)
+	}
+
+	for indx, objectT := range plc.Spec.ObjectTemplates {
+		relevantNamespaces := templateNamespaces(templateObjs[indx], selectedNamespaces)
+
+		if plc.Spec.OrderedEvaluation && blockingTemplate == -1 && indx > 0 && !templateCompliant(&plc, indx-1) {
+			blockingTemplate = indx - 1
+		}
+
+		// Skip the object templates after a noncompliant one when using ordered evaluation, and keep their previous
+		// related objects so that they aren't considered detached.
+		if blockingTemplate != -1 {
+			log.V(1).Info("Skipping the object template due to an earlier noncompliant object template", "index", indx)
+
+			msg := fmt.Sprintf(
+				"The object template was not evaluated because object-templates[%d] is not compliant", blockingTemplate,
+			)
+
+			if addConditionToStatus(&plc, indx, false, "Skipped due to earlier template", msg) {
+				parentStatusUpdateNeeded = true
+			}
+
+			for _, object := range relatedObjectsForTemplate(oldRelated, templateObjs[indx], relevantNamespaces) {
+				relatedObjects = updateRelatedObjectsStatus(relatedObjects, object)
+			}
+
+			continue
 		}
 
-		r.checkRelatedAndUpdate(plc, relatedObjects, oldRelated, statusUpdateNeeded, true)
-
-		return
-	}
+		if msg, limited := limitedTemplates[objectT]; limited {
+			if addConditionToStatus(&plc, indx, false, reasonTemplateLimitExceeded, msg) {
+				parentStatusUpdateNeeded = true
+			}
 
-	for indx, objectT := range plc.Spec.ObjectTemplates {
-		// If the object does not have a namespace specified, use the previously retrieved namespaces
-		// from the NamespaceSelector. If no namespaces are found/specified, use the value from the
-		// object so that the objectTemplate is processed:
-		// - For clusterwide resources, an empty string will be expected
-		// - For namespaced resources, handleObjects() will return a status with a no namespace message if
-		//   it's an empty string or else it will use the namespace defined in the object
-		var relevantNamespaces []string
-		if templateObjs[indx].isNamespaced && templateObjs[indx].namespace == "" && len(selectedNamespaces) != 0 {
-			relevantNamespaces = selectedNamespaces
-		} else {
-			relevantNamespaces = []string{templateObjs[indx].namespace}
+			continue
 		}
 
-		nsToResults := map[string]objectTmplEvalResult{}
-		// map raw object to a resource, generate a violation if resource cannot be found
-		mapping, mappingErrResult := r.getMapping(objectT.ObjectDefinition, &plc, indx)
+		if !templateDue(indx) {
+			log.V(1).Info(
+				"Skipping the object template evaluation due to it not reaching its evaluation interval", "index", indx,
+			)
+
+			for _, object := range relatedObjectsForTemplate(oldRelated, templateObjs[indx], relevantNamespaces) {
+				relatedObjects = updateRelatedObjectsStatus(relatedObjects, object)
+			}
 
-		if mapping == nil && mappingErrResult == nil {
-			// If there was no violation generated but the mapping failed, there is nothing to do for this
-			// object-template.
 			continue
 		}
 
-		desiredObj, err := unmarshalFromJSON(objectT.ObjectDefinition.Raw)
-		if err != nil {
-			panic(err)
+		var evaluation *templateEvaluation
+		if evaluations != nil {
+			evaluation = evaluations[indx]
+		} else {
+			evaluation = r.evaluateObjectTemplate(&plc, indx, templateObjs[indx], relevantNamespaces)
 		}
 
-		// iterate through all namespaces the configurationpolicy is set on
-		for _, ns := range relevantNamespaces {
-			log.V(1).Info(
-				"Handling the object template for the relevant namespace",
-				"namespace", ns,
-				"desiredName", templateObjs[indx].name,
-				"index", indx,
-			)
+		// If there was no violation generated but the mapping failed, there is nothing to do for this object-template.
+		if evaluation == nil {
+			continue
+		}
 
-			if mappingErrResult != nil {
-				nsToResults[ns] = *mappingErrResult
+		mapping := evaluation.mapping
+		nsToResults := evaluation.nsToResults
+		templateRelated := evaluation.templateRelated
 
-				continue
+		// The object template's pruneObjectBehavior is recorded on its related objects so that they are pruned
+		// accordingly when the policy is deleted
+		if objectT.PruneObjectBehavior != "" {
+			for i := range templateRelated {
+				if templateRelated[i].Properties == nil {
+					templateRelated[i].Properties = &policyv1.ObjectProperties{}
+				}
+
+				templateRelated[i].Properties.PruneObjectBehavior = objectT.PruneObjectBehavior
 			}
+		}
 
-			related, result := r.handleObjects(objectT, ns, templateObjs[indx], indx, &plc, mapping, desiredObj)
+		// The related objects record when they were last evaluated, and the ones of the skipped object templates
+		// keep their previous time
+		setRelatedLastEvaluated(templateRelated, time.Now())
 
-			nsToResults[ns] = result
+		// The related objects of an object template are limited in the status, but the ones created by the policy
+		// are always kept so that they can be pruned. The limit doesn't apply when all the related objects are
+		// pruned.
+		maxRelatedObjects := r.MaxRelatedObjectsPerTemplate
+		if objectT.GetPruneObjectBehavior(plc.Spec.PruneObjectBehavior) == "DeleteAll" {
+			maxRelatedObjects = 0
+		}
 
-			for _, object := range related {
-				relatedObjects = updateRelatedObjectsStatus(relatedObjects, object)
-			}
+		for _, object := range capRelatedObjects(templateRelated, maxRelatedObjects, createdRelated) {
+			relatedObjects = updateRelatedObjectsStatus(relatedObjects, object)
 		}
 
 		// Each index is a batch of compliance events to be set on the ConfigurationPolicy before going on to the
@@ -1225,6 +2776,11 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 			lastBatch := eventBatches[len(eventBatches)-1]
 
 			compliant, reason, msg := createStatus(resourceName, lastBatch)
+			msg = renderCustomMessage(&plc, compliant, msg, lastBatch)
+
+			if !compliant && enforcementDeferredUntil != "" {
+				msg += fmt.Sprintf(" (enforcement deferred until %s)", enforcementDeferredUntil)
+			}
 
 			if !compliant {
 				statusUpdateNeeded := addConditionToStatus(plc.DeepCopy(), indx, compliant, reason, msg)
@@ -1239,6 +2795,11 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 
 		for i, batch := range eventBatches {
 			compliant, reason, msg := createStatus(resourceName, batch)
+			msg = renderCustomMessage(&plc, compliant, msg, batch)
+
+			if !compliant && enforcementDeferredUntil != "" {
+				msg += fmt.Sprintf(" (enforcement deferred until %s)", enforcementDeferredUntil)
+			}
 
 			statusUpdateNeeded := addConditionToStatus(&plc, indx, compliant, reason, msg)
 
@@ -1259,9 +2820,193 @@ func (r *ConfigurationPolicyReconciler) handleObjectTemplates(plc policyv1.Confi
 				r.addForUpdate(&plc, true)
 			}
 		}
+
+		if indx < len(plc.Status.CompliancyDetails) {
+			// This is set with the final compliance state of the object template so that both are updated together
+			plc.Status.CompliancyDetails[indx].Summary = summarizeRelatedObjects(templateRelated)
+
+			if usesTemplateEvaluationIntervals(&plc) {
+				plc.Status.CompliancyDetails[indx].LastEvaluated = time.Now().UTC().Format(time.RFC3339)
+			}
+		}
+	}
+
+	// The user errors are only forgotten after a complete evaluation, since they may not have been checked
+	r.userErrors.endEvaluation("ConfigurationPolicy", plc.Namespace, plc.Name)
+
+	r.pruneEnforcedFields(policyKey(&plc), relatedObjects)
+	r.sendObjectEvents(&plc, relatedObjects, oldRelated)
+	// The objects of the object templates that exceeded a template limit are unknown, so the detached objects are
+	// only deleted when every object template was evaluated
+	r.checkRelatedAndUpdate(plc, relatedObjects, oldRelated, parentStatusUpdateNeeded, len(limitedTemplates) == 0)
+}
+
+// templateEvaluation is the result of evaluating an object template in each of its relevant namespaces.
+type templateEvaluation struct {
+	mapping         *meta.RESTMapping
+	nsToResults     map[string]objectTmplEvalResult
+	templateRelated []policyv1.RelatedObject
+}
+
+// templateNamespaces returns the namespaces in which the object template is evaluated. If the object does not have a
+// namespace specified, the namespaces selected by the NamespaceSelector are used, and if the namespace is "*", all
+// the namespaces of the cluster are used. If no namespaces are found or
+// specified, the value from the object is used so that the object template is processed:
+//   - For clusterwide resources, an empty string will be expected
+//   - For namespaced resources, handleObjects() will return a status with a no namespace message if
+//     it's an empty string or else it will use the namespace defined in the object
+func templateNamespaces(details objectTemplateDetails, selectedNamespaces []string) []string {
+	if details.allNamespaces {
+		if len(details.namespaces) == 0 {
+			return []string{""}
+		}
+
+		return details.namespaces
+	}
+
+	if details.isNamespaced && details.namespace == "" && len(selectedNamespaces) != 0 {
+		return selectedNamespaces
+	}
+
+	return []string{details.namespace}
+}
+
+// evaluateObjectTemplate evaluates the object template at the input index in each of the input namespaces. Nil is
+// returned when the API mapping of the object template failed without generating a violation, in which case there is
+// nothing to do for the object template.
+func (r *ConfigurationPolicyReconciler) evaluateObjectTemplate(
+	plc *policyv1.ConfigurationPolicy, indx int, details objectTemplateDetails, namespaces []string,
+) *templateEvaluation {
+	objectT := plc.Spec.ObjectTemplates[indx]
+	evaluation := &templateEvaluation{
+		nsToResults:     map[string]objectTmplEvalResult{},
+		templateRelated: []policyv1.RelatedObject{},
+	}
+
+	// map raw object to a resource, generate a violation if resource cannot be found
+	mapping, mappingErrResult := r.getMapping(objectT.ObjectDefinition, plc, indx)
+
+	if mapping == nil && mappingErrResult == nil {
+		return nil
+	}
+
+	evaluation.mapping = mapping
+
+	desiredObj, err := unmarshalFromJSON(objectT.ObjectDefinition.Raw)
+	if err != nil {
+		panic(err)
+	}
+
+	// Compare and enforce the Secret stringData values the way the API server stores them
+	desiredObj = secretStringDataToData(desiredObj)
+
+	// The "*" namespace only selects the namespaces, so the objects are compared in the namespace they're found in
+	if details.allNamespaces {
+		desiredObj.SetNamespace("")
+	}
+
+	// The fields with a '$patch: delete' directive aren't part of the desired object and are removed from the
+	// existing object instead. The directives were validated before the object templates were processed.
+	details.removals, _ = extractRemovalDirectives(desiredObj.Object)
+
+	// The objects in all the namespaces are listed at once rather than with a request in each namespace. If the list
+	// fails, the objects are retrieved in each namespace so that the errors are reported there.
+	if details.allNamespaces && mappingErrResult == nil {
+		details.listedObjects, err = r.listAllNamespacesObjects(objectT, details, desiredObj, mapping.Resource)
+		if err != nil {
+			log.Info(
+				"Failed to list the objects in all the namespaces, so they're retrieved in each namespace",
+				"policy", plc.GetName(), "index", indx, "error", err.Error(),
+			)
+		}
+	}
+
+	// iterate through all namespaces the configurationpolicy is set on
+	for _, ns := range namespaces {
+		log.V(1).Info(
+			"Handling the object template for the relevant namespace",
+			"policy", plc.GetName(),
+			"namespace", ns,
+			"desiredName", details.name,
+			"index", indx,
+		)
+
+		if mappingErrResult != nil {
+			evaluation.nsToResults[ns] = *mappingErrResult
+
+			continue
+		}
+
+		related, result := r.handleObjects(objectT, ns, details, indx, plc, mapping, desiredObj)
+
+		evaluation.nsToResults[ns] = result
+		evaluation.templateRelated = append(evaluation.templateRelated, related...)
+	}
+
+	return evaluation
+}
+
+// evaluateObjectTemplates evaluates the due object templates with up to TemplateEvaluationConcurrency Go routines and
+// returns the results by object template index. The object templates of the same kind can target the same objects, so
+// they are evaluated in order by the same Go routine. The policy must not be modified until this returns.
+func (r *ConfigurationPolicyReconciler) evaluateObjectTemplates(
+	plc *policyv1.ConfigurationPolicy,
+	templateObjs []objectTemplateDetails,
+	selectedNamespaces []string,
+	templateDue func(indx int) bool,
+) []*templateEvaluation {
+	evaluations := make([]*templateEvaluation, len(plc.Spec.ObjectTemplates))
+	groups := [][]int{}
+	groupIndexes := map[string]int{}
+
+	for indx := range plc.Spec.ObjectTemplates {
+		if !templateDue(indx) {
+			continue
+		}
+
+		groupIndex, ok := groupIndexes[templateObjs[indx].kind]
+		if !ok {
+			groupIndex = len(groups)
+			groupIndexes[templateObjs[indx].kind] = groupIndex
+			groups = append(groups, []int{})
+		}
+
+		groups[groupIndex] = append(groups[groupIndex], indx)
+	}
+
+	groupQueue := make(chan []int, len(groups))
+	for _, group := range groups {
+		groupQueue <- group
+	}
+
+	close(groupQueue)
+
+	workers := int(r.TemplateEvaluationConcurrency)
+	if workers > len(groups) {
+		workers = len(groups)
 	}
 
-	r.checkRelatedAndUpdate(plc, relatedObjects, oldRelated, parentStatusUpdateNeeded, true)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for group := range groupQueue {
+				for _, indx := range group {
+					namespaces := templateNamespaces(templateObjs[indx], selectedNamespaces)
+					// Each object template index is only written by one Go routine
+					evaluations[indx] = r.evaluateObjectTemplate(plc, indx, templateObjs[indx], namespaces)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return evaluations
 }
 
 // checkRelatedAndUpdate checks the related objects field and triggers an update on the ConfigurationPolicy
@@ -1327,8 +3072,23 @@ func (r *ConfigurationPolicyReconciler) sortRelatedObjectsAndUpdate(
 					newEntry.Properties != nil &&
 					newEntry.Properties.CreatedByPolicy != nil &&
 					!(*newEntry.Properties.CreatedByPolicy) {
-					// Use the old properties if they existed and this is not a newly created resource
-					related[i].Properties = oldEntry.Properties
+					// Use the old properties if they existed and this is not a newly created resource, but keep
+					// the preview results, the pruneObjectBehavior, and the evaluation time of this evaluation.
+					properties := *oldEntry.Properties
+					properties.PreviewAction = newEntry.Properties.PreviewAction
+					properties.Diff = newEntry.Properties.Diff
+					properties.PruneObjectBehavior = newEntry.Properties.PruneObjectBehavior
+					properties.LastEvaluated = newEntry.Properties.LastEvaluated
+					properties.ResourceVersion = newEntry.Properties.ResourceVersion
+
+					// An object with another UID was deleted and recreated since the last evaluation, so it's no
+					// longer the object the policy created
+					if newEntry.Properties.UID != "" && newEntry.Properties.UID != properties.UID {
+						properties.UID = newEntry.Properties.UID
+						properties.CreatedByPolicy = newEntry.Properties.CreatedByPolicy
+					}
+
+					related[i].Properties = &properties
 
 					if collectMetrics {
 						found[objKey] = true
@@ -1350,11 +3110,14 @@ func (r *ConfigurationPolicyReconciler) sortRelatedObjectsAndUpdate(
 		}
 	}
 
-	if !gocmp.Equal(related, oldRelated) {
-		if deleteDetachedObjs {
-			r.cleanUpChildObjects(*plc, related)
-		}
+	// The evaluation time and the evaluated resourceVersion alone don't detach any related object
+	if deleteDetachedObjs && !gocmp.Equal(related, oldRelated, ignoreEvaluationProperties) {
+		_, skipped := r.cleanUpChildObjects(*plc, related)
 
+		setPruneSkippedCondition(plc, skipped)
+	}
+
+	if !gocmp.Equal(related, oldRelated) {
 		plc.Status.RelatedObjects = related
 	}
 }
@@ -1376,6 +3139,9 @@ func addConditionToStatus(
 	if reason == reasonCleanupError {
 		complianceState = policyv1.Terminating
 		cond.Type = "violation"
+	} else if reason == reasonDependenciesUnmet {
+		complianceState = policyv1.Pending
+		cond.Type = "notification"
 	} else if compliant {
 		complianceState = policyv1.Compliant
 		cond.Type = "notification"
@@ -1390,7 +3156,8 @@ func addConditionToStatus(
 		msg := `This policy will not be evaluated again due to spec.evaluationInterval.compliant being set to "never"`
 		log.Info(msg)
 		cond.Message += fmt.Sprintf(". %s.", msg)
-	} else if !compliant && plc.Spec != nil && plc.Spec.EvaluationInterval.NonCompliant == "never" {
+	} else if complianceState == policyv1.NonCompliant && plc.Spec != nil &&
+		plc.Spec.EvaluationInterval.NonCompliant == "never" {
 		msg := "This policy will not be evaluated again due to spec.evaluationInterval.noncompliant " +
 			`being set to "never"`
 		log.Info(msg)
@@ -1468,7 +3235,8 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 
 	exists := true
 	objNames := []string{}
-	remediation := policy.Spec.RemediationAction
+	remediation := effectiveRemediationAction(policy, time.Now())
+	objShouldExist := !objectT.ComplianceType.IsMustNotHave()
 
 	// If the parsed namespace doesn't match the object namespace, something in the calling function went wrong
 	if objDetails.namespace != "" && objDetails.namespace != namespace {
@@ -1496,6 +3264,13 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 			space, objName, kindWithoutNS,
 		)
 
+		if objDetails.allNamespaces {
+			msg = fmt.Sprintf("namespaced object%s%s of kind %s has no namespace to be evaluated in since no "+
+				"namespace was found on the cluster",
+				space, objName, kindWithoutNS,
+			)
+		}
+
 		result = objectTmplEvalResult{
 			[]string{objName},
 			namespace,
@@ -1509,29 +3284,103 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 	var allResourceNames []string
 
 	if objDetails.name != "" { // named object, so checking just for the existence of the specific object
-		// If the object couldn't be retrieved, this will be handled later on.
-		existingObj, _ = getObject(
-			objDetails.isNamespaced, namespace, objDetails.name, mapping.Resource, r.TargetK8sDynamicClient,
-		)
+		// If the object couldn't be retrieved for another reason, this will be handled later on.
+		var err error
+
+		existingObj, err = r.getTemplateObject(objDetails, namespace, objDetails.name, mapping.Resource)
+		if k8serrors.IsForbidden(err) {
+			log.Info("Access to the object is denied by RBAC, so it can't be evaluated", "name", objDetails.name)
+
+			return accessDeniedResult([]string{objDetails.name}, namespace, objDetails, mapping)
+		}
 
 		exists = existingObj != nil
 
 		objNames = append(objNames, objDetails.name)
+	} else if generateName := desiredObj.GetGenerateName(); generateName != "" && objDetails.kind != "" {
+		// The name is generated by the API server, so the generateName acts as a name prefix
+		matchingNames, allResourceNames, err := r.getTemplateNamesOfKind(
+			objDetails,
+			desiredObj,
+			mapping.Resource,
+			namespace,
+			"",
+			strings.ToLower(string(objectT.ComplianceType)),
+		)
+		if k8serrors.IsForbidden(err) {
+			log.Info("Access to the objects is denied by RBAC, so they can't be evaluated")
+
+			return accessDeniedResult(nil, namespace, objDetails, mapping)
+		}
+
+		if !objShouldExist {
+			return r.handleObjectPurge(
+				objectT, filterByPrefix(allResourceNames, generateName), namespace, objDetails, policy, mapping,
+				remediation,
+			)
+		}
+
+		existingObj = r.getGeneratedObject(policy, mapping.Resource, objDetails, namespace, generateName)
+
+		if existingObj == nil {
+			matchingNames = filterByPrefix(matchingNames, generateName)
+			if objectT.CheckExistenceOnly {
+				matchingNames = filterByPrefix(allResourceNames, generateName)
+			}
+
+			if len(matchingNames) != 0 {
+				// If the object couldn't be retrieved, this will be handled later on.
+				existingObj, _ = r.getTemplateObject(objDetails, namespace, matchingNames[0], mapping.Resource)
+			}
+		}
+
+		exists = existingObj != nil
+
+		if exists {
+			objNames = append(objNames, existingObj.GetName())
+		} else {
+			// The object will be created with a generated name when enforcing
+			objNames = append(objNames, generateName)
+		}
 	} else if objDetails.kind != "" { // no name, so we are checking for the existence of any object of this kind
 		log.V(1).Info(
 			"The object template does not specify a name. Will search for matching objects in the namespace.",
 		)
-		objNames, allResourceNames = getNamesOfKind(
+		labelSelector := ""
+
+		if objectT.ObjectSelector != nil {
+			// The objectSelector was validated before the object templates were processed
+			selector, _ := metav1.LabelSelectorAsSelector(objectT.ObjectSelector)
+			labelSelector = selector.String()
+		}
+
+		var err error
+
+		// Dry run API requests aren't run on unnamed object templates for performance reasons, so the comparison
+		// algorithm is less conservative.
+		objNames, allResourceNames, err = r.getTemplateNamesOfKind(
+			objDetails,
 			desiredObj,
 			mapping.Resource,
-			objDetails.isNamespaced,
 			namespace,
-			r.TargetK8sDynamicClient,
+			labelSelector,
 			strings.ToLower(string(objectT.ComplianceType)),
-			// Dry run API requests aren't run on unnamed object templates for performance reasons, so be less
-			// conservative in the comparison algorithm.
-			true,
 		)
+		if k8serrors.IsForbidden(err) {
+			log.Info("Access to the objects is denied by RBAC, so they can't be evaluated")
+
+			return accessDeniedResult(nil, namespace, objDetails, mapping)
+		}
+
+		// Every object of the kind matches when the contents aren't evaluated
+		if objectT.CheckExistenceOnly {
+			objNames = allResourceNames
+		}
+
+		// A mustnothave object template with an objectSelector deletes all the matching objects when enforcing
+		if objectT.ObjectSelector != nil && !objShouldExist {
+			return r.handleObjectPurge(objectT, objNames, namespace, objDetails, policy, mapping, remediation)
+		}
 
 		// we do not support enforce on unnamed templates
 		if !remediation.IsInform() {
@@ -1542,20 +3391,20 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 		}
 		remediation = "inform"
 
+		if objectT.MinimumObjects != nil || objectT.MaximumObjects != nil {
+			return handleObjectCount(objectT, objNames, mapping.Resource, objDetails, namespace)
+		}
+
 		if len(objNames) == 0 {
 			exists = false
 		} else if len(objNames) == 1 {
 			// If the object couldn't be retrieved, this will be handled later on.
-			existingObj, _ = getObject(
-				objDetails.isNamespaced, namespace, objNames[0], mapping.Resource, r.TargetK8sDynamicClient,
-			)
+			existingObj, _ = r.getTemplateObject(objDetails, namespace, objNames[0], mapping.Resource)
 
 			exists = existingObj != nil
 		}
 	}
 
-	objShouldExist := !objectT.ComplianceType.IsMustNotHave()
-
 	shouldAddCondensedRelatedObj := false
 
 	if len(objNames) == 1 {
@@ -1570,6 +3419,7 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 			shouldExist: objShouldExist,
 			index:       index,
 			desiredObj:  desiredObj,
+			removals:    objDetails.removals,
 		}
 
 		log.V(2).Info("Handling a single object template")
@@ -1577,6 +3427,7 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 		var creationInfo *policyv1.ObjectProperties
 
 		result, creationInfo = r.handleSingleObj(singObj, remediation, exists, objectT)
+		creationInfo = setEvaluatedIdentity(creationInfo, existingObj)
 
 		if len(result.events) != 0 {
 			event := result.events[len(result.events)-1]
@@ -1597,6 +3448,10 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 			if exists {
 				resultEvent.compliant = true
 				resultEvent.reason = reasonWantFoundExists
+
+				if objectT.CheckExistenceOnly {
+					resultEvent.reason = reasonWantFoundExistsOnly
+				}
 			} else {
 				resultEvent.compliant = false
 				resultEvent.reason = reasonWantFoundDNE
@@ -1623,35 +3478,340 @@ func (r *ConfigurationPolicyReconciler) handleObjects(
 			}
 		}
 
-		result = objectTmplEvalResult{objectNames: objNames, events: []objectTmplEvalEvent{resultEvent}}
+		result = objectTmplEvalResult{objectNames: objNames, events: []objectTmplEvalEvent{resultEvent}}
+
+		if shouldAddCondensedRelatedObj {
+			// relatedObjs name is -
+			relatedObjects = addCondensedRelatedObjs(
+				mapping.Resource,
+				resultEvent.compliant,
+				objDetails.kind,
+				namespace,
+				objDetails.isNamespaced,
+				resultEvent.reason,
+			)
+		} else {
+			relatedObjects = addRelatedObjects(
+				resultEvent.compliant,
+				mapping.Resource,
+				objDetails.kind,
+				namespace,
+				objDetails.isNamespaced,
+				objNames,
+				resultEvent.reason,
+				nil,
+			)
+		}
+	}
+
+	return relatedObjects, result
+}
+
+// getGeneratedObject returns the object that the policy previously created from the generateName of an object
+// template. The object is tracked by the UID recorded in the related objects of the policy status, so nil is
+// returned if the object was deleted or replaced.
+func (r *ConfigurationPolicyReconciler) getGeneratedObject(
+	policy *policyv1.ConfigurationPolicy,
+	gvr schema.GroupVersionResource,
+	objDetails objectTemplateDetails,
+	namespace string,
+	generateName string,
+) *unstructured.Unstructured {
+	for _, related := range policy.Status.RelatedObjects {
+		props := related.Properties
+		if props == nil || props.UID == "" || props.CreatedByPolicy == nil || !*props.CreatedByPolicy {
+			continue
+		}
+
+		if related.Object.Kind != objDetails.kind || related.Object.APIVersion != gvr.GroupVersion().String() {
+			continue
+		}
+
+		if related.Object.Metadata.Namespace != namespace ||
+			!strings.HasPrefix(related.Object.Metadata.Name, generateName) {
+			continue
+		}
+
+		existingObj, _ := r.getTemplateObject(objDetails, namespace, related.Object.Metadata.Name, gvr)
+		if existingObj != nil && string(existingObj.GetUID()) == props.UID {
+			return existingObj
+		}
+	}
+
+	return nil
+}
+
+// filterByPrefix returns the names that start with the input prefix.
+func filterByPrefix(names []string, prefix string) []string {
+	filtered := []string{}
+
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			filtered = append(filtered, name)
+		}
+	}
+
+	return filtered
+}
+
+// handleObjectPurge handles a mustnothave object template without a name that sets an objectSelector or a
+// generateName. Every matching object in the namespace is noncompliant, and when enforcing, all of them are deleted.
+func (r *ConfigurationPolicyReconciler) handleObjectPurge(
+	objectT *policyv1.ObjectTemplate,
+	objNames []string,
+	namespace string,
+	objDetails objectTemplateDetails,
+	policy *policyv1.ConfigurationPolicy,
+	mapping *meta.RESTMapping,
+	remediation policyv1.RemediationAction,
+) (
+	relatedObjects []policyv1.RelatedObject,
+	result objectTmplEvalResult,
+) {
+	result = objectTmplEvalResult{objectNames: objNames, namespace: namespace}
+
+	if len(objNames) == 0 {
+		result.events = []objectTmplEvalEvent{{true, reasonWantNotFoundDNE, ""}}
+		relatedObjects = addCondensedRelatedObjs(
+			mapping.Resource, true, objDetails.kind, namespace, objDetails.isNamespaced, reasonWantNotFoundDNE,
+		)
+
+		return relatedObjects, result
+	}
+
+	event := objectTmplEvalEvent{false, reasonWantNotFoundExists, ""}
+
+	if remediation.IsEnforce() {
+		deleted := []string{}
+		remaining := []string{}
+		previewed := []string{}
+		protected := []string{}
+		restricted := []string{}
+		throttled := []string{}
+		denied := []string{}
+		failures := []string{}
+
+		for _, name := range objNames {
+			existingObj, err := r.getTemplateObject(objDetails, namespace, name, mapping.Resource)
+			// The object isn't deleted when it can't be retrieved to check it
+			if k8serrors.IsForbidden(err) {
+				denied = append(denied, name)
+
+				continue
+			}
+
+			if existingObj == nil {
+				deleted = append(deleted, name)
+
+				continue
+			}
+
+			// Don't issue another delete request for an object that is already terminating
+			if existingObj.GetDeletionTimestamp() != nil {
+				remaining = append(remaining, name)
+
+				continue
+			}
+
+			obj := singleObject{
+				policy:      policy,
+				gvr:         mapping.Resource,
+				existingObj: existingObj,
+				name:        name,
+				namespace:   namespace,
+				namespaced:  objDetails.isNamespaced,
+				index:       -1,
+			}
+			obj.desiredObj.SetGroupVersionKind(mapping.GroupVersionKind)
+
+			completed, reason, msg, _, _ := r.enforceByCreatingOrDeleting(obj, objectT)
+
+			switch {
+			case completed:
+				deleted = append(deleted, name)
+			case reason == reasonWantNotFoundTerm:
+				remaining = append(remaining, name)
+			case reason == reasonPreviewDelete:
+				previewed = append(previewed, name)
+			case reason == reasonDeletionProtected:
+				protected = append(protected, name)
+			case reason == reasonNamespaceRestricted || reason == reasonKindRestricted:
+				restricted = append(restricted, name)
+			case reason == reasonEnforcementThrottled:
+				throttled = append(throttled, name)
+			default:
+				failures = append(failures, msg)
+			}
+		}
+
+		idStr := identifierStr(nil, namespace)
+
+		switch {
+		case len(failures) != 0:
+			event = objectTmplEvalEvent{false, "K8s deletion error", strings.Join(failures, "; ")}
+		case len(denied) != 0:
+			result.objectNames = denied
+			event = objectTmplEvalEvent{false, reasonAccessDenied, ""}
+		case len(restricted) != 0:
+			result.objectNames = restricted
+			restrictedReason, restriction := r.enforcementRestriction(namespace, mapping.GroupVersionKind.GroupKind())
+			msg := fmt.Sprintf("%d %s not deleted since %s: %s", len(restricted), mapping.Resource.Resource,
+				restriction, truncatedNameList(restricted, purgeMessageNameLimit))
+			if idStr != "" {
+				msg += " " + idStr
+			}
+
+			event = objectTmplEvalEvent{false, restrictedReason, msg}
+		case len(protected) != 0:
+			result.objectNames = protected
+			msg := fmt.Sprintf("%d %s not deleted since they have the %s annotation: %s", len(protected),
+				mapping.Resource.Resource, protectedAnnotation, truncatedNameList(protected, purgeMessageNameLimit))
+			if idStr != "" {
+				msg += " " + idStr
+			}
+
+			event = objectTmplEvalEvent{false, reasonDeletionProtected, msg}
+		case len(throttled) != 0:
+			result.objectNames = throttled
+			event = objectTmplEvalEvent{false, reasonEnforcementThrottled, ""}
+		case len(remaining) != 0:
+			result.objectNames = remaining
+			event = objectTmplEvalEvent{false, reasonWantNotFoundTerm, ""}
+		case len(previewed) != 0:
+			result.objectNames = previewed
+			event = objectTmplEvalEvent{false, reasonPreviewDelete, ""}
+		default:
+			msg := fmt.Sprintf("%d %s deleted successfully: %s", len(deleted), mapping.Resource.Resource,
+				truncatedNameList(deleted, purgeMessageNameLimit))
+			if idStr != "" {
+				msg += " " + idStr
+			}
+
+			event = objectTmplEvalEvent{true, reasonPurgeSuccess, msg}
+		}
+	}
+
+	result.events = []objectTmplEvalEvent{event}
+	relatedObjects = addRelatedObjects(
+		event.compliant,
+		mapping.Resource,
+		objDetails.kind,
+		namespace,
+		objDetails.isNamespaced,
+		objNames,
+		event.reason,
+		nil,
+	)
 
-		if shouldAddCondensedRelatedObj {
-			// relatedObjs name is -
-			relatedObjects = addCondensedRelatedObjs(
-				mapping.Resource,
-				resultEvent.compliant,
-				objDetails.kind,
-				namespace,
-				objDetails.isNamespaced,
-				resultEvent.reason,
-			)
-		} else {
-			relatedObjects = addRelatedObjects(
-				resultEvent.compliant,
-				mapping.Resource,
-				objDetails.kind,
-				namespace,
-				objDetails.isNamespaced,
-				objNames,
-				resultEvent.reason,
-				nil,
-			)
-		}
+	return relatedObjects, result
+}
+
+// truncatedNameList formats the names as a list with at most limit names followed by the count of the rest.
+func truncatedNameList(names []string, limit int) string {
+	sortedNames := append([]string{}, names...)
+	sort.Strings(sortedNames)
+
+	if len(sortedNames) <= limit {
+		return "[" + strings.Join(sortedNames, ", ") + "]"
+	}
+
+	return fmt.Sprintf(
+		"[%s] and %d more", strings.Join(sortedNames[:limit], ", "), len(sortedNames)-limit,
+	)
+}
+
+// validateObjectCounts returns an error if the minimumObjects and maximumObjects fields of the object template are
+// set in an unsupported way.
+func validateObjectCounts(
+	plc *policyv1.ConfigurationPolicy, objectT *policyv1.ObjectTemplate, objDetails objectTemplateDetails,
+) error {
+	if objectT.MinimumObjects == nil && objectT.MaximumObjects == nil {
+		return nil
+	}
+
+	if objDetails.name != "" {
+		return errors.New("minimumObjects and maximumObjects can only be set on object templates without a name")
+	}
+
+	if objectT.ComplianceType.IsMustNotHave() {
+		return errors.New("minimumObjects and maximumObjects can't be set with the mustnothave compliance type")
+	}
+
+	if plc.Spec.RemediationAction.IsEnforce() {
+		return errors.New("minimumObjects and maximumObjects can only be set in inform policies")
+	}
+
+	if objectT.MinimumObjects != nil && objectT.MaximumObjects != nil &&
+		*objectT.MinimumObjects > *objectT.MaximumObjects {
+		return errors.New("minimumObjects can't be greater than maximumObjects")
+	}
+
+	return nil
+}
+
+// handleObjectCount determines the compliance of an object template with minimumObjects or maximumObjects set based
+// on the number of objects that match it in the namespace.
+func handleObjectCount(
+	objectT *policyv1.ObjectTemplate,
+	objNames []string,
+	gvr schema.GroupVersionResource,
+	objDetails objectTemplateDetails,
+	namespace string,
+) (
+	relatedObjects []policyv1.RelatedObject,
+	result objectTmplEvalResult,
+) {
+	count := len(objNames)
+	compliant := (objectT.MinimumObjects == nil || count >= int(*objectT.MinimumObjects)) &&
+		(objectT.MaximumObjects == nil || count <= int(*objectT.MaximumObjects))
+
+	reason := reasonObjectCount
+	if !compliant {
+		reason = reasonObjectCountNoMatch
+	}
+
+	msg := objectCountMessage(gvr.Resource, count, namespace, objectT.MinimumObjects, objectT.MaximumObjects)
+
+	result = objectTmplEvalResult{
+		objectNames: objNames,
+		namespace:   namespace,
+		events:      []objectTmplEvalEvent{{compliant, reason, msg}},
+	}
+
+	if count == 0 {
+		relatedObjects = addCondensedRelatedObjs(
+			gvr, compliant, objDetails.kind, namespace, objDetails.isNamespaced, reason,
+		)
+	} else {
+		relatedObjects = addRelatedObjects(
+			compliant, gvr, objDetails.kind, namespace, objDetails.isNamespaced, objNames, reason, nil,
+		)
 	}
 
 	return relatedObjects, result
 }
 
+// objectCountMessage generates the compliance message of an object template with minimumObjects or maximumObjects
+// set, which includes the number of matching objects and the expected bounds.
+func objectCountMessage(resource string, count int, namespace string, minimum, maximum *int32) string {
+	msg := fmt.Sprintf("%d %s found", count, resource)
+	if namespace != "" {
+		msg += " in namespace " + namespace
+	}
+
+	bounds := []string{}
+
+	if minimum != nil {
+		bounds = append(bounds, fmt.Sprintf("at least %d", *minimum))
+	}
+
+	if maximum != nil {
+		bounds = append(bounds, fmt.Sprintf("at most %d", *maximum))
+	}
+
+	return msg + ", expected " + strings.Join(bounds, " and ")
+}
+
 type singleObject struct {
 	policy      *policyv1.ConfigurationPolicy
 	gvr         schema.GroupVersionResource
@@ -1662,6 +3822,10 @@ type singleObject struct {
 	shouldExist bool
 	index       int
 	desiredObj  unstructured.Unstructured
+	// The paths of the fields that conflict with other policies enforcing the object, which aren't enforced
+	conflictingPaths [][]string
+	// The fields with a removal directive in the objectDefinition, which are deleted from the object when enforcing
+	removals []fieldRemoval
 }
 
 type objectTmplEvalResult struct {
@@ -1700,6 +3864,37 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 		events:      []objectTmplEvalEvent{},
 	}
 
+	// The object is only evaluated, and the reason for it not being enforced replaces the noncompliant reasons
+	groupKind := obj.desiredObj.GroupVersionKind().GroupKind()
+
+	restrictedReason, restriction := r.enforcementRestriction(obj.namespace, groupKind)
+	if remediation.IsEnforce() && restrictedReason != "" {
+		objLog.V(1).Info("Evaluating the object as inform since it's restricted", "reason", restrictedReason)
+
+		remediation = policyv1.Inform
+
+		objDescription := fmt.Sprintf("%s [%s]", obj.gvr.Resource, obj.name)
+		if obj.namespace != "" {
+			objDescription += " in namespace " + obj.namespace
+		}
+
+		defer func() {
+			for i, event := range result.events {
+				if event.compliant {
+					continue
+				}
+
+				result.events[i] = objectTmplEvalEvent{
+					compliant: false,
+					reason:    restrictedReason,
+					message: fmt.Sprintf(
+						"%s is noncompliant (%s) and isn't modified since %s", objDescription, event.reason, restriction,
+					),
+				}
+			}
+		}()
+	}
+
 	if !exists && obj.shouldExist {
 		// object is missing and will be created, so send noncompliant "does not exist" event regardless of the
 		// remediation action
@@ -1707,8 +3902,7 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 
 		// it is a musthave and it does not exist, so it must be created
 		if remediation.IsEnforce() {
-			var uid string
-			completed, reason, msg, uid, err := r.enforceByCreatingOrDeleting(obj)
+			completed, reason, msg, createdObj, err := r.enforceByCreatingOrDeleting(obj, objectT)
 
 			hasStatus := false
 			if tmplObj, err := unmarshalFromJSON(objectT.ObjectDefinition.Raw); err == nil {
@@ -1726,12 +3920,20 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 			if err != nil {
 				// violation created for handling error
 				objLog.Error(err, "Could not handle missing musthave object")
-			} else {
+			} else if reason == reasonPreviewCreate {
+				creationInfo = &policyv1.ObjectProperties{PreviewAction: policyv1.PreviewActionCreate}
+			} else if createdObj != nil {
+				r.sendMutationEvent(obj, objectT, createdObj, eventReasonPolicyCreated)
+
 				created := true
 				creationInfo = &policyv1.ObjectProperties{
 					CreatedByPolicy: &created,
-					UID:             uid,
+					UID:             string(createdObj.GetUID()),
+					ResourceVersion: createdObj.GetResourceVersion(),
 				}
+
+				// The name isn't known until the object is created when it's generated by the API server
+				result.objectNames = []string{createdObj.GetName()}
 			}
 		}
 
@@ -1739,14 +3941,31 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 	}
 
 	if exists && !obj.shouldExist {
+		// The object still counts as present until its deletion completes, and it shouldn't be deleted again
+		if obj.existingObj != nil && obj.existingObj.GetDeletionTimestamp() != nil {
+			log.V(1).Info("The mustnothave object is terminating")
+
+			result.events = append(result.events, objectTmplEvalEvent{false, reasonWantNotFoundTerm, ""})
+
+			return
+		}
+
 		// it is a mustnothave but it exist, so it must be deleted
 		if remediation.IsEnforce() {
-			completed, reason, msg, _, err := r.enforceByCreatingOrDeleting(obj)
+			completed, reason, msg, _, err := r.enforceByCreatingOrDeleting(obj, objectT)
 			if err != nil {
 				objLog.Error(err, "Could not handle existing mustnothave object")
 			}
 
 			result.events = append(result.events, objectTmplEvalEvent{completed, reason, msg})
+
+			if reason == reasonPreviewDelete {
+				created := false
+				creationInfo = &policyv1.ObjectProperties{
+					CreatedByPolicy: &created,
+					PreviewAction:   policyv1.PreviewActionDelete,
+				}
+			}
 		} else { // inform
 			result.events = append(result.events, objectTmplEvalEvent{false, reasonWantNotFoundExists, ""})
 		}
@@ -1762,21 +3981,93 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 		return
 	}
 
+	// object exists and the template only requires it to exist, so the contents aren't compared
+	if exists && obj.shouldExist && objectT.CheckExistenceOnly {
+		log.V(2).Info("The object already exists. Not verifying the object fields due to checkExistenceOnly.")
+
+		result.events = append(result.events, objectTmplEvalEvent{true, reasonWantFoundExistsOnly, ""})
+
+		if remediation.IsEnforce() {
+			created := false
+			creationInfo = &policyv1.ObjectProperties{
+				CreatedByPolicy: &created,
+				UID:             "",
+			}
+		}
+
+		return
+	}
+
 	// object exists and the template requires it, so we need to check specific fields to see if we have a match
 	if exists && obj.shouldExist {
 		log.V(2).Info("The object already exists. Verifying the object fields match what is desired.")
 
 		var throwSpecViolation, triedUpdate, updatedObj bool
-		var msg string
+		var msg, previewDiff string
 
-		if evaluated, compliant := r.alreadyEvaluated(obj.policy, obj.existingObj); evaluated {
+		// The fields that conflict with other policies aren't enforced, and the conflict makes the object noncompliant
+		if remediation.IsEnforce() {
+			// The ignoreFields paths were validated before the object templates were processed
+			ignoredPaths, _ := parseIgnoreFields(objectT.IgnoreFields)
+
+			if conflicts := r.recordEnforcedFields(obj, ignoredPaths); len(conflicts) != 0 {
+				obj.conflictingPaths = conflictingPaths(conflicts)
+
+				defer func() {
+					result.events = append(
+						result.events, objectTmplEvalEvent{false, reasonPolicyConflict, conflictMessage(obj, conflicts)},
+					)
+				}()
+			}
+		} else {
+			r.forgetEnforcedFields(obj)
+		}
+
+		// The preview results are refreshed on every evaluation, so the cached results aren't used in preview mode
+		preview := remediation.IsEnforce() && isPreview(obj.policy)
+
+		// The object checks are evaluated before the comparison since it modifies the existing object, and they are
+		// evaluated every time rather than cached so that the unmet expectations are always reported
+		checksMsg := objectChecksMessage(obj, objectT)
+
+		if evaluated, compliant := r.alreadyEvaluated(obj.policy, obj.existingObj); evaluated && !preview {
 			log.V(1).Info("Skipping object comparison since the resourceVersion hasn't changed")
 
 			throwSpecViolation = !compliant
 		} else {
-			throwSpecViolation, msg, triedUpdate, updatedObj = r.checkAndUpdateResource(
+			throwSpecViolation, msg, triedUpdate, updatedObj, previewDiff = r.checkAndUpdateResource(
 				obj, objectT, remediation,
 			)
+
+			if updatedObj && isObjectRecreated(msg) {
+				// The recreated object has a new UID
+				recreatedObj := obj.existingObj.DeepCopy()
+				recreatedObj.SetUID("")
+
+				r.sendMutationEvent(obj, objectT, recreatedObj, eventReasonPolicyRecreated)
+			} else if updatedObj {
+				r.sendMutationEvent(obj, objectT, obj.existingObj, eventReasonPolicyUpdated)
+			}
+		}
+
+		if !throwSpecViolation && checksMsg != "" {
+			log.Info("The object doesn't meet the object checks", "message", checksMsg)
+
+			throwSpecViolation = true
+			msg = checksMsg
+		}
+
+		if preview && throwSpecViolation && triedUpdate && msg == "" {
+			result.events = append(result.events, objectTmplEvalEvent{false, reasonPreviewUpdate, ""})
+
+			created := false
+			creationInfo = &policyv1.ObjectProperties{
+				CreatedByPolicy: &created,
+				PreviewAction:   policyv1.PreviewActionUpdate,
+				Diff:            previewDiff,
+			}
+
+			return
 		}
 
 		if triedUpdate && !strings.Contains(msg, "Error validating the object") {
@@ -1787,7 +4078,22 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 		if throwSpecViolation {
 			var resultReason, resultMsg string
 
-			if msg != "" {
+			if isEnforcementReverted(msg) {
+				resultReason = reasonEnforcementReverted
+				resultMsg = msg
+			} else if isRecreatePending(msg) {
+				resultReason = reasonRecreatePending
+				resultMsg = msg
+			} else if isFieldsOwned(msg) {
+				resultReason = reasonFieldsOwned
+				resultMsg = msg
+			} else if isObjectChecksFailed(msg) {
+				resultReason = reasonObjectChecksFailed
+				resultMsg = msg
+			} else if isEnforcementThrottled(msg) {
+				resultReason = reasonEnforcementThrottled
+				resultMsg = msg
+			} else if msg != "" {
 				resultReason = "K8s update template error"
 				resultMsg = msg
 			} else {
@@ -1796,10 +4102,20 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 
 			result.events = append(result.events, objectTmplEvalEvent{false, resultReason, resultMsg})
 		} else {
+			toleratedMsg := toleratedExternalFieldsMessage(obj, objectT)
+
 			// it is a must have and it does exist, so it is compliant
 			if remediation.IsEnforce() {
-				if updatedObj {
+				if updatedObj && isObjectRecreated(msg) {
+					result.events = append(result.events, objectTmplEvalEvent{true, reasonRecreateSuccess, ""})
+				} else if updatedObj {
 					result.events = append(result.events, objectTmplEvalEvent{true, reasonUpdateSuccess, ""})
+				} else if isToleratedDrift(msg) {
+					result.events = append(result.events, objectTmplEvalEvent{true, reasonToleratedDrift, msg})
+				} else if toleratedMsg != "" {
+					result.events = append(
+						result.events, objectTmplEvalEvent{true, reasonToleratedExternalFields, toleratedMsg},
+					)
 				} else {
 					result.events = append(result.events, objectTmplEvalEvent{true, reasonWantFoundExists, ""})
 				}
@@ -1808,6 +4124,12 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 					CreatedByPolicy: &created,
 					UID:             "",
 				}
+			} else if isToleratedDrift(msg) {
+				result.events = append(result.events, objectTmplEvalEvent{true, reasonToleratedDrift, msg})
+			} else if toleratedMsg != "" {
+				result.events = append(
+					result.events, objectTmplEvalEvent{true, reasonToleratedExternalFields, toleratedMsg},
+				)
 			} else {
 				result.events = append(result.events, objectTmplEvalEvent{true, reasonWantFoundExists, ""})
 			}
@@ -1817,6 +4139,56 @@ func (r *ConfigurationPolicyReconciler) handleSingleObj(
 	return
 }
 
+// enforcementRestriction returns the reason and an explanation when the controller configuration prevents objects of
+// the group and kind in the namespace from being created, updated, or deleted. Empty strings are returned otherwise.
+func (r *ConfigurationPolicyReconciler) enforcementRestriction(
+	namespace string, groupKind schema.GroupKind,
+) (reason string, restriction string) {
+	if r.namespaceRestricted(namespace) {
+		return reasonNamespaceRestricted, "the namespace is restricted by the controller configuration"
+	}
+
+	// The patterns were validated when the controller started
+	if pattern, _ := common.MatchGroupKind(r.DeniedKinds, groupKind); pattern != "" {
+		return reasonKindRestricted, fmt.Sprintf(
+			"the kind is restricted by the controller configuration (--denied-kinds %s)", pattern,
+		)
+	}
+
+	return "", ""
+}
+
+// namespaceRestricted determines if the controller configuration prevents objects in the namespace from being
+// created, updated, or deleted. Cluster scoped objects are never restricted.
+func (r *ConfigurationPolicyReconciler) namespaceRestricted(namespace string) bool {
+	if namespace == "" {
+		return false
+	}
+
+	allowed := len(r.AllowedNamespaces) == 0
+
+	for _, pattern := range r.AllowedNamespaces {
+		// The patterns were validated when the controller started
+		if matched, _ := filepath.Match(pattern, namespace); matched {
+			allowed = true
+
+			break
+		}
+	}
+
+	if !allowed {
+		return true
+	}
+
+	for _, pattern := range r.DeniedNamespaces {
+		if matched, _ := filepath.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
 // isObjectNamespaced determines if the input object is a namespaced resource. When refreshIfNecessary
 // is true, the discovery information will be refreshed if the resource cannot be found.
 func (r *ConfigurationPolicyReconciler) isObjectNamespaced(
@@ -1877,6 +4249,8 @@ func (r *ConfigurationPolicyReconciler) getMapping(
 
 		log.Error(err, "Could not decode object")
 
+		r.userErrors.record("ConfigurationPolicy", policy, userErrorInvalidObjectDefinition, decodeErr)
+
 		result = &objectTmplEvalResult{
 			events: []objectTmplEvalEvent{
 				{compliant: false, reason: "K8s decode object definition error", message: decodeErr},
@@ -1889,6 +4263,11 @@ func (r *ConfigurationPolicyReconciler) getMapping(
 	if gvk.Group == "" && gvk.Version == "" {
 		err := fmt.Errorf("object template at index [%v] in policy `%v` missing apiVersion", index, policy.Name)
 
+		// The apiVersion is only missing when the kind couldn't be resolved, so explain why
+		if _, resolveErr := r.preferredGroupVersion(gvk.Kind); resolveErr != nil {
+			err = fmt.Errorf("%w, and %v", err, resolveErr)
+		}
+
 		log.Error(err, "Can not get mapping for object")
 
 		result = &objectTmplEvalResult{
@@ -1919,21 +4298,25 @@ func (r *ConfigurationPolicyReconciler) getMapping(
 
 		afterPrefix := err.Error()[(startIdx + len(prefix)):len(err.Error())]
 		kind := afterPrefix[0:(strings.Index(afterPrefix, "\" "))]
+		reason := "K8s creation error"
 		mappingErrMsg := "couldn't find mapping resource with kind " + kind +
 			", please check if you have CRD deployed"
 
-		log.Error(err, "Could not map resource, do you have the CRD deployed?", "kind", kind)
+		if r.CRDWatcher != nil {
+			r.CRDWatcher.Wait(policy.GetName(), gvk.GroupKind())
 
-		parent := ""
-		if len(policy.OwnerReferences) > 0 {
-			parent = policy.OwnerReferences[0].Name
+			reason = "Mapping not found"
+			mappingErrMsg = "couldn't find mapping resource with kind " + kind +
+				", will retry when the CRD is installed"
 		}
 
-		policyUserErrorsCounter.WithLabelValues(parent, policy.GetName(), "no-object-CRD").Add(1)
+		log.Error(err, "Could not map resource, do you have the CRD deployed?", "kind", kind)
+
+		r.userErrors.record("ConfigurationPolicy", policy, userErrorNoObjectCRD, mappingErrMsg)
 
 		result = &objectTmplEvalResult{
 			events: []objectTmplEvalEvent{
-				{compliant: false, reason: "K8s creation error", message: mappingErrMsg},
+				{compliant: false, reason: reason, message: mappingErrMsg},
 			},
 		}
 
@@ -1965,7 +4348,7 @@ func buildNameList(
 			// if any key in the object generates a mismatch, the object does not match the template and we
 			// do not add its name to the list
 			errorMsg, updateNeeded, _, skipped := handleSingleKey(
-				key, desiredObj, &uObj, complianceType, zeroValueEqualsNil,
+				key, desiredObj, &uObj, complianceType, nil, zeroValueEqualsNil,
 			)
 			if !skipped {
 				if errorMsg != "" || updateNeeded {
@@ -1984,48 +4367,63 @@ func buildNameList(
 
 // getNamesOfKind returns an array with names of all of the resources found
 // matching the GVK specified.
-// allResourceList includes names that are under the same namespace and kind.
+// allResourceList includes names that are under the same namespace and kind. The error is returned when the resources
+// can't be listed so that the caller can tell when access is denied, since the lists are empty then.
 func getNamesOfKind(
 	desiredObj unstructured.Unstructured,
 	rsrc schema.GroupVersionResource,
 	namespaced bool,
 	ns string,
+	labelSelector string,
 	dclient dynamic.Interface,
 	complianceType string,
 	zeroValueEqualsNil bool,
-) (kindNameList []string, allResourceList []string) {
+) (kindNameList []string, allResourceList []string, err error) {
 	var resList *unstructured.UnstructuredList
-	var err error
+
+	listOptions := metav1.ListOptions{LabelSelector: labelSelector}
 
 	if namespaced {
 		res := dclient.Resource(rsrc).Namespace(ns)
 
-		resList, err = res.List(context.TODO(), metav1.ListOptions{})
+		resList, err = res.List(context.TODO(), listOptions)
 	} else {
 		res := dclient.Resource(rsrc)
 
-		resList, err = res.List(context.TODO(), metav1.ListOptions{})
+		resList, err = res.List(context.TODO(), listOptions)
 	}
 
 	if err != nil {
 		log.Error(err, "Could not list resources", "rsrc", rsrc, "namespaced", namespaced)
 
-		return kindNameList, allResourceList
+		return kindNameList, allResourceList, err
 	}
 
 	for _, res := range resList.Items {
 		allResourceList = append(allResourceList, res.GetName())
 	}
 
-	return buildNameList(desiredObj, complianceType, resList, zeroValueEqualsNil), allResourceList
+	return buildNameList(desiredObj, complianceType, resList, zeroValueEqualsNil), allResourceList, nil
+}
+
+// isProtected determines if the object has the protected annotation set to "true" and the controller honors it, in
+// which case enforced mustnothave object templates don't delete it.
+func (r *ConfigurationPolicyReconciler) isProtected(object *unstructured.Unstructured) bool {
+	if r.IgnoreProtectedAnnotation || object == nil {
+		return false
+	}
+
+	return strings.EqualFold(object.GetAnnotations()[protectedAnnotation], "true")
 }
 
 // enforceByCreatingOrDeleting can handle the situation where a musthave or mustonlyhave object is
 // completely missing (as opposed to existing, but not matching the desired state), or where a
 // mustnothave object does exist. Eg, it does not handle the case where a targeted update would need
 // to be made to an object.
-func (r *ConfigurationPolicyReconciler) enforceByCreatingOrDeleting(obj singleObject) (
-	result bool, reason string, msg string, uid string, erro error,
+func (r *ConfigurationPolicyReconciler) enforceByCreatingOrDeleting(
+	obj singleObject, objectT *policyv1.ObjectTemplate,
+) (
+	result bool, reason string, msg string, createdObj *unstructured.Unstructured, erro error,
 ) {
 	log := log.WithValues(
 		"object", obj.name,
@@ -2042,39 +4440,136 @@ func (r *ConfigurationPolicyReconciler) enforceByCreatingOrDeleting(obj singleOb
 		res = r.TargetK8sDynamicClient.Resource(obj.gvr)
 	}
 
+	// The restriction is also checked here since the purge of the objects matching an objectSelector or a generateName
+	// deletes them without evaluating them one by one
+	groupKind := obj.desiredObj.GroupVersionKind().GroupKind()
+	if groupKind.Kind == "" && obj.existingObj != nil {
+		groupKind = obj.existingObj.GroupVersionKind().GroupKind()
+	}
+
+	if restrictedReason, restriction := r.enforcementRestriction(obj.namespace, groupKind); restrictedReason != "" {
+		log.Info("Not enforcing the object since it's restricted", "reason", restrictedReason)
+
+		return false, restrictedReason, fmt.Sprintf(
+			"%v %v isn't modified since %s", obj.gvr.Resource, idStr, restriction,
+		), nil, nil
+	}
+
 	var completed bool
 	var err error
 
+	preview := isPreview(obj.policy)
+
 	if obj.shouldExist {
-		log.Info("Enforcing the policy by creating the object")
+		if throttledMsg := r.enforcementThrottled(obj); throttledMsg != "" {
+			log.Info("Not creating the object yet since the enforcement rate limit was reached")
+
+			return false, reasonEnforcementThrottled, throttledMsg, nil, nil
+		}
 
-		var createdObj *unstructured.Unstructured
+		log.Info("Enforcing the policy by creating the object", "preview", preview)
 
-		if createdObj, err = r.createObject(res, obj.desiredObj); createdObj == nil {
+		// A server-side apply requires a name, so an object with a generated name is always created
+		generatedName := obj.desiredObj.GetName() == "" && obj.desiredObj.GetGenerateName() != ""
+
+		if obj.policy.Spec.EnforcementMethod == policyv1.EnforcementMethodServerSideApply && !generatedName {
+			createdObj, err = r.applyObject(res, obj, obj.policy.Spec.ForceConflicts, preview)
+		} else {
+			createdObj, err = r.createObject(
+				res, obj.desiredObj, policyFieldManager(r.FieldManager, obj.policy.Name), preview,
+			)
+		}
+
+		if createdObj != nil && generatedName {
+			idStr = identifierStr([]string{createdObj.GetName()}, obj.namespace)
+		}
+
+		if createdObj == nil {
 			reason = "K8s creation error"
 			msg = fmt.Sprintf("%v %v is missing, and cannot be created, reason: `%v`", obj.gvr.Resource, idStr, err)
+		} else if preview {
+			reason = reasonPreviewCreate
+			msg = fmt.Sprintf("%v %v would be created (preview)", obj.gvr.Resource, idStr)
 		} else {
 			log.V(2).Info("Created missing must have object", "resource", obj.gvr.Resource, "name", obj.name)
 			reason = reasonWantFoundCreated
 			msg = fmt.Sprintf("%v %v was created successfully", obj.gvr.Resource, idStr)
 
-			uid = string(createdObj.GetUID())
 			completed = true
 		}
+	} else if r.isProtected(obj.existingObj) {
+		log.Info("Not deleting the object since it has the protected annotation")
+
+		reason = reasonDeletionProtected
+		msg = fmt.Sprintf(
+			"%v %v exists, and isn't deleted since it has the %s annotation",
+			obj.gvr.Resource, idStr, protectedAnnotation,
+		)
+
+		if r.Recorder != nil {
+			r.Recorder.Event(obj.policy, eventWarning, eventReasonDeletionBlocked, msg)
+		}
 	} else {
-		log.Info("Enforcing the policy by deleting the object")
+		if throttledMsg := r.enforcementThrottled(obj); throttledMsg != "" {
+			log.Info("Not deleting the object yet since the enforcement rate limit was reached")
+
+			return false, reasonEnforcementThrottled, throttledMsg, nil, nil
+		}
+
+		log.Info("Enforcing the policy by deleting the object", "preview", preview)
+
+		deleteOptions := metav1.DeleteOptions{PropagationPolicy: objectT.DeleteOptions.PropagationPolicy}
+
+		if preview {
+			deleteOptions.DryRun = []string{metav1.DryRunAll}
+		}
+
+		// The UID precondition prevents deleting an object that was recreated since it was retrieved
+		if obj.existingObj != nil && obj.existingObj.GetUID() != "" {
+			uid := obj.existingObj.GetUID()
+			deleteOptions.Preconditions = &metav1.Preconditions{UID: &uid}
+		}
+
+		// The record is captured before the deletion since the object can't be retrieved afterwards
+		record, recordErr := deletionRecord(obj, objectT)
+		if recordErr != nil {
+			log.Info("Failed to generate the deletion record: " + recordErr.Error())
+		}
 
-		if completed, err = deleteObject(res, obj.name, obj.namespace); !completed {
+		if completed, err = deleteObject(res, obj.name, obj.namespace, deleteOptions); !completed {
 			reason = "K8s deletion error"
-			msg = fmt.Sprintf("%v %v exists, and cannot be deleted, reason: `%v`", obj.gvr.Resource, idStr, err)
+
+			if isUIDPreconditionFailed(err) {
+				msg = fmt.Sprintf(
+					"%v %v exists, and isn't deleted since it was replaced after it was retrieved",
+					obj.gvr.Resource, idStr,
+				)
+			} else {
+				msg = fmt.Sprintf("%v %v exists, and cannot be deleted, reason: `%v`", obj.gvr.Resource, idStr, err)
+			}
+		} else if preview {
+			completed = false
+			reason = reasonPreviewDelete
+			msg = fmt.Sprintf("%v %v would be deleted (preview)", obj.gvr.Resource, idStr)
+		} else if r.objectStillExists(obj) {
+			// The object remains until its finalizers are removed, and with foreground deletion, until its dependents
+			// are deleted
+			completed = false
+			reason = reasonWantNotFoundTerm
+
+			r.recordDeletion(obj, record)
+			r.sendMutationEvent(obj, objectT, obj.existingObj, eventReasonPolicyDeleted)
 		} else {
+			r.recordDeletion(obj, record)
+			r.sendMutationEvent(obj, objectT, obj.existingObj, eventReasonPolicyDeleted)
+
 			reason = reasonDeleteSuccess
 			msg = fmt.Sprintf("%v %v was deleted successfully", obj.gvr.Resource, idStr)
 			obj.existingObj = nil
 		}
 	}
 
-	return completed, reason, msg, uid, err
+	return completed, reason, msg, createdObj, err
 }
 
 // checkMessageSimilarity decides whether to append a new condition to a configurationPolicy status
@@ -2132,7 +4627,7 @@ func getObject(
 }
 
 func (r *ConfigurationPolicyReconciler) createObject(
-	res dynamic.ResourceInterface, unstruct unstructured.Unstructured,
+	res dynamic.ResourceInterface, unstruct unstructured.Unstructured, fieldManager string, dryRun bool,
 ) (object *unstructured.Unstructured, err error) {
 	objLog := log.WithValues("name", unstruct.GetName(), "namespace", unstruct.GetNamespace())
 	objLog.V(2).Info("Entered createObject", "unstruct", unstruct)
@@ -2145,9 +4640,16 @@ func (r *ConfigurationPolicyReconciler) createObject(
 		}
 	}
 
-	object, err = res.Create(context.TODO(), &unstruct, metav1.CreateOptions{
+	options := metav1.CreateOptions{
+		FieldManager:    fieldManager,
 		FieldValidation: metav1.FieldValidationStrict,
-	})
+	}
+
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	object, err = res.Create(context.TODO(), &unstruct, options)
 	if err != nil {
 		if k8serrors.IsAlreadyExists(err) {
 			objLog.V(2).Info("Got 'Already Exists' response for object")
@@ -2160,16 +4662,79 @@ func (r *ConfigurationPolicyReconciler) createObject(
 		return nil, err
 	}
 
-	objLog.V(2).Info("Resource created")
+	objLog.V(2).Info("Resource created")
+
+	return object, nil
+}
+
+// applyObject sends a server-side apply request of the object's desired state with the controller's field
+// manager. This creates the object if it doesn't exist.
+func (r *ConfigurationPolicyReconciler) applyObject(
+	res dynamic.ResourceInterface, obj singleObject, force bool, dryRun bool,
+) (object *unstructured.Unstructured, err error) {
+	objLog := log.WithValues("name", obj.name, "namespace", obj.namespace, "dryRun", dryRun)
+	objLog.V(2).Info("Entered applyObject")
+
+	desiredObj := obj.desiredObj.DeepCopy()
+	desiredObj.SetName(obj.name)
+
+	if obj.namespaced {
+		desiredObj.SetNamespace(obj.namespace)
+	}
+
+	// The status can't be set with an apply request to the object itself
+	unstructured.RemoveNestedField(desiredObj.Object, "status")
+
+	// FieldValidation is supported in k8s 1.25 as beta release
+	// so if the version is below 1.25, we need to use client side validation to validate the object
+	if semver.Compare(r.serverVersion, "v1.25.0") < 0 {
+		if err := r.validateObject(desiredObj); err != nil {
+			return nil, err
+		}
+	}
+
+	objJSON, err := json.Marshal(desiredObj.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	options := metav1.PatchOptions{
+		FieldManager:    policyFieldManager(r.FieldManager, obj.policy.Name),
+		Force:           &force,
+		FieldValidation: metav1.FieldValidationStrict,
+	}
+
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	object, err = res.Patch(context.TODO(), obj.name, types.ApplyPatchType, objJSON, options)
+	if err != nil {
+		objLog.V(2).Info("Could not apply the object", "reason", k8serrors.ReasonForError(err))
+
+		return nil, err
+	}
+
+	objLog.V(2).Info("Resource applied")
 
 	return object, nil
 }
 
-func deleteObject(res dynamic.ResourceInterface, name, namespace string) (deleted bool, err error) {
+// objectStillExists determines if the object is still present after a delete request. If the object can't be
+// retrieved, it's assumed to still exist.
+func (r *ConfigurationPolicyReconciler) objectStillExists(obj singleObject) bool {
+	existingObj, err := getObject(obj.namespaced, obj.namespace, obj.name, obj.gvr, r.TargetK8sDynamicClient)
+
+	return err != nil || existingObj != nil
+}
+
+func deleteObject(
+	res dynamic.ResourceInterface, name, namespace string, options metav1.DeleteOptions,
+) (deleted bool, err error) {
 	objLog := log.WithValues("name", name, "namespace", namespace)
 	objLog.V(2).Info("Entered deleteObject")
 
-	err = res.Delete(context.TODO(), name, metav1.DeleteOptions{})
+	err = res.Delete(context.TODO(), name, options)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			objLog.V(2).Info("Got 'Not Found' response while deleting object")
@@ -2187,6 +4752,12 @@ func deleteObject(res dynamic.ResourceInterface, name, namespace string) (delete
 	return true, nil
 }
 
+// isUIDPreconditionFailed determines if the error is from a delete request with a UID precondition that doesn't match
+// the UID of the object, meaning that the object was replaced by another object with the same name.
+func isUIDPreconditionFailed(err error) bool {
+	return k8serrors.IsConflict(err) && strings.Contains(err.Error(), "Precondition failed: UID")
+}
+
 // mergeSpecs is a wrapper for the recursive function to merge 2 maps.
 func mergeSpecs(templateVal, existingVal interface{}, ctype string, zeroValueEqualsNil bool) (interface{}, error) {
 	// Copy templateVal since it will be modified in mergeSpecsHelper
@@ -2380,12 +4951,13 @@ func compareSpecs(
 }
 
 // handleSingleKey checks whether a key/value pair in an object template matches with that in the existing
-// resource on the cluster
+// resource on the cluster. The lists at the input merge key paths are merged by pairing their items by key.
 func handleSingleKey(
 	key string,
 	desiredObj unstructured.Unstructured,
 	existingObj *unstructured.Unstructured,
 	complianceType string,
+	mergeKeys []listMergeKey,
 	zeroValueEqualsNil bool,
 ) (errormsg string, update bool, merged interface{}, skip bool) {
 	log := log.WithValues("name", existingObj.GetName(), "namespace", existingObj.GetNamespace())
@@ -2446,6 +5018,22 @@ func handleSingleKey(
 		return message, false, mergedValue, false
 	}
 
+	// The lists with a merge key are merged by pairing their items by key rather than by position
+	for _, mergeKey := range mergeKeys {
+		if mergeKey.path[0] != key {
+			continue
+		}
+
+		mergedValue = applyListMergeKey(
+			mergedValue, desiredValue, existingValue, mergeKey.path[1:], mergeKey.key, complianceType,
+			zeroValueEqualsNil,
+		)
+	}
+
+	// Keep the existing representation of equal scalars (e.g. "1Gi" instead of "1024Mi" or true instead of "true") so
+	// that the API server normalizing them isn't reported as a mismatch or shown in the diff
+	mergedValue, _ = canonicalizeScalars(mergedValue, existingValue)
+
 	if key == "metadata" {
 		// filter out autogenerated annotations that have caused compare issues in the past
 		mergedValue, existingValue = fmtMetadataForCompare(
@@ -2478,8 +5066,17 @@ func handleSingleKey(
 		existingValue = decodedValue
 	}
 
+	compareMerged, compareExisting := mergedValue, existingValue
+
+	// Unless mustonlyhave is used, an explicit null or empty map is equivalent to the field not being set, since the
+	// API server often drops them and they'd otherwise be reported as a mismatch on every evaluation
+	if complianceType != "mustonlyhave" {
+		compareMerged = pruneNullAndEmpty(mergedValue)
+		compareExisting = pruneNullAndEmpty(existingValue)
+	}
+
 	// sort objects before checking equality to ensure they're in the same order
-	if !equalObjWithSort(mergedValue, existingValue, zeroValueEqualsNil) {
+	if !equalObjWithSort(compareMerged, compareExisting, zeroValueEqualsNil) {
 		updateNeeded = true
 	}
 
@@ -2546,9 +5143,11 @@ func (r *ConfigurationPolicyReconciler) checkAndUpdateResource(
 	obj singleObject,
 	objectT *policyv1.ObjectTemplate,
 	remediation policyv1.RemediationAction,
-) (throwSpecViolation bool, message string, updateNeeded bool, updateSucceeded bool) {
+) (
+	throwSpecViolation bool, message string, updateNeeded bool, updateSucceeded bool, previewDiff string,
+) {
 	complianceType := strings.ToLower(string(objectT.ComplianceType))
-	mdComplianceType := strings.ToLower(string(objectT.MetadataComplianceType))
+	mdComplianceTypes := getMetadataComplianceTypes(objectT)
 
 	log := log.WithValues(
 		"policy", obj.policy.Name, "name", obj.name, "namespace", obj.namespace, "resource", obj.gvr.Resource,
@@ -2571,10 +5170,12 @@ func (r *ConfigurationPolicyReconciler) checkAndUpdateResource(
 		).Inc()
 	}()
 
+	preview := isPreview(obj.policy)
+
 	if obj.existingObj == nil {
 		log.Info("Skipping update: Previous object retrieval from the API server failed")
 
-		return false, "", false, false
+		return false, "", false, false, ""
 	}
 
 	var res dynamic.ResourceInterface
@@ -2584,157 +5185,628 @@ func (r *ConfigurationPolicyReconciler) checkAndUpdateResource(
 		res = r.TargetK8sDynamicClient.Resource(obj.gvr)
 	}
 
+	// The ignoreFields paths were validated before the object templates were processed
+	ignoredPaths, _ := parseIgnoreFields(objectT.IgnoreFields)
+	ignoredPaths = append(ignoredPaths, obj.conflictingPaths...)
+
+	// The fields added by other controllers that match tolerateExternalFields are handled like the ignored fields, but
+	// only when the objectDefinition doesn't set them. The paths were validated before the object templates were
+	// processed.
+	externalPatterns, _ := parseTolerateExternalFields(objectT.TolerateExternalFields)
+
+	externalPaths, err := toleratedExternalPaths(obj.existingObj.Object, obj.desiredObj.Object, externalPatterns)
+	if err != nil {
+		log.Info("The tolerateExternalFields can't be applied to the object", "error", err.Error())
+
+		return true, err.Error(), false, false, ""
+	}
+
+	ignoredPaths = append(ignoredPaths, externalPaths...)
+
+	if len(ignoredPaths) != 0 {
+		desiredObj := obj.desiredObj.DeepCopy()
+		removeIgnoredFields(desiredObj, ignoredPaths)
+		obj.desiredObj = *desiredObj
+	}
+
+	if obj.policy.Spec.EnforcementMethod == policyv1.EnforcementMethodServerSideApply {
+		return r.checkAndApplyResource(obj, objectT, remediation, res, ignoredPaths)
+	}
+
+	// The unorderedLists paths were validated before the object templates were processed
+	unorderedPaths, _ := parseUnorderedLists(objectT.UnorderedLists)
+	if len(unorderedPaths) != 0 {
+		desiredObj := obj.desiredObj.DeepCopy()
+		dedupeUnorderedLists(desiredObj, unorderedPaths)
+		obj.desiredObj = *desiredObj
+	}
+
 	// Use a copy since some values can be directly assigned to mergedObj in handleSingleKey.
 	existingObjectCopy := obj.existingObj.DeepCopy()
 	removeFieldsForComparison(existingObjectCopy)
+	removeIgnoredFields(existingObjectCopy, ignoredPaths)
+	dedupeUnorderedLists(existingObjectCopy, unorderedPaths)
+
+	originalObj := obj.existingObj.DeepCopy()
+
+	// The listMergeKeys paths were validated before the object templates were processed
+	mergeKeys, _ := parseListMergeKeys(objectT.ListMergeKeys)
+
+	throwSpecViolation, message, updateNeeded, statusMismatch := handleKeys(
+		obj.desiredObj,
+		obj.existingObj,
+		existingObjectCopy,
+		complianceType,
+		mdComplianceTypes,
+		mergeKeys,
+		!r.DryRunSupported,
+	)
+	if message != "" {
+		return true, message, true, false, ""
+	}
+
+	// The merged object is based on a copy without the ignored fields, so set them back to their current values
+	restoreIgnoredFields(obj.existingObj, originalObj, ignoredPaths)
+	// Keep the current order of the unordered lists so that reordering them isn't reported as a change
+	alignUnorderedLists(obj.existingObj, originalObj, unorderedPaths)
+	// The status is only compared and is never written, so set it back to its current value
+	restoreIgnoredFields(obj.existingObj, originalObj, [][]string{{"status"}})
+
+	// The fields with a removal directive must be absent, so any that are present are a mismatch
+	if removed := applyRemovals(obj.existingObj.Object, obj.removals); len(removed) != 0 {
+		log.Info("Detected fields that must be removed", "fields", fmt.Sprint(removed))
+
+		updateNeeded = true
+	}
+
+	if updateNeeded {
+		// Identify the mismatched fields owned by other field managers. The fields owned by tolerated field managers
+		// are left as is and are only reported.
+		owned := ownedFields(
+			differingPaths(originalObj.Object, obj.existingObj.Object),
+			originalObj.GetManagedFields(),
+			policyFieldManager(r.FieldManager, obj.policy.Name),
+		)
+		tolerated, ownedByOthers := splitTolerated(owned, objectT.TolerateFieldManagers)
+		idStr := identifierStr([]string{obj.name}, obj.namespace)
+
+		if len(tolerated) != 0 {
+			toleratedPaths := make([][]string, 0, len(tolerated))
+			for _, field := range tolerated {
+				toleratedPaths = append(toleratedPaths, field.path)
+			}
+
+			restoreIgnoredFields(obj.existingObj, originalObj, toleratedPaths)
+
+			if len(differingPaths(originalObj.Object, obj.existingObj.Object)) == 0 {
+				log.Info(
+					"The only mismatched fields are owned by tolerated field managers",
+					"ownership", ownershipDescription(tolerated),
+				)
+
+				message := fmt.Sprintf(
+					"%v %v %v: %v", obj.gvr.Resource, idStr, toleratedDriftMsg, ownershipDescription(tolerated),
+				)
+
+				return false, message, false, false, ""
+			}
+		}
+
+		mismatchLog := "Detected value mismatch"
+		suppressedNote := diffSuppressedNote(obj, objectT)
+
+		// Add a configuration breadcrumb for users that might be looking in the logs for a diff
+		if suppressedNote != "" {
+			mismatchLog += " (" + suppressedNote + ")"
+		} else if objectT.RecordDiff != policyv1.RecordDiffLog {
+			mismatchLog += " (Diff disabled. To log the diff, " +
+				"set 'spec.object-tempates[].recordDiff' to 'Log' for this object-template.)"
+		}
+
+		// Warn about the fields that are about to be taken over from other field managers
+		ownershipLog := ""
+		if len(ownedByOthers) != 0 {
+			ownershipLog = "\nOwnership of the mismatched fields: " + ownershipDescription(ownedByOthers)
+		}
+
+		log.Info(mismatchLog + ownershipLog)
+
+		// recordLocalDiff generates and logs the diff between the existing object and the object merged with the
+		// objectDefinition by the local comparison, which doesn't require any API request.
+		recordLocalDiff := func() string {
+			mergedObjCopy := obj.existingObj.DeepCopy()
+			removeFieldsForComparison(mergedObjCopy)
+			removeIgnoredFields(mergedObjCopy, ignoredPaths)
+			dedupeUnorderedLists(mergedObjCopy, unorderedPaths)
+
+			diff, err := r.objectDiff(existingObjectCopy, mergedObjCopy, objectT.DiffFormat)
+			if err != nil {
+				log.Info("Failed to generate the diff: " + err.Error())
+
+				return ""
+			}
+
+			if objectT.DiffFormat != policyv1.DiffFormatJSONPatch {
+				diff = withToleratedExternalFieldsNote(diff, externalPaths)
+			}
+
+			if objectT.RecordDiff == policyv1.RecordDiffLog {
+				log.Info(r.diffLogMessage(obj, diff+ownershipLog))
+
+				if remediation.IsInform() {
+					r.recordEventDiff(obj, diff)
+				}
+			}
+
+			return diff
+		}
+
+		// FieldValidation is supported in k8s 1.25 as beta release
+		// so if the version is below 1.25, we need to use client side validation to validate the object
+		if semver.Compare(r.serverVersion, "v1.25.0") < 0 {
+			if err := r.validateObject(obj.existingObj); err != nil {
+				message := fmt.Sprintf("Error validating the object %s, the error is `%v`", obj.name, err)
+
+				return true, message, updateNeeded, false, ""
+			}
+		}
+
+		// If the cluster supports dry run requests, verify that the API server agrees with the local comparison logic.
+		// It's possible the dry run request shows the object does match. This can happen if the ConfigurationPolicy
+		// specifies an empty map and the API server omits it from the return value.
+		if r.DryRunSupported {
+			dryRunUpdatedObj, err := res.Update(context.TODO(), obj.existingObj, metav1.UpdateOptions{
+				FieldManager:    policyFieldManager(r.FieldManager, obj.policy.Name),
+				FieldValidation: metav1.FieldValidationStrict,
+				DryRun:          []string{metav1.DryRunAll},
+			})
+			if err != nil {
+				// The update changes immutable fields, so the object must be recreated to be enforced
+				if shouldRecreate(objectT, remediation, preview, err) {
+					return r.recreateObject(obj, objectT, res)
+				}
+
+				// If an inform policy and the update is forbidden (i.e. modifying Pod spec fields), then return
+				// noncompliant since that confirms some fields don't match. The dry run doesn't return the updated
+				// object, so the diff is generated from the local comparison.
+				if k8serrors.IsForbidden(err) {
+					r.setEvaluatedObject(obj.policy, obj.existingObj, false)
+
+					if suppressedNote != "" {
+						r.suppressDiff(obj, objectT, remediation, suppressedNote)
+					} else if objectT.RecordDiff == policyv1.RecordDiffLog {
+						recordLocalDiff()
+					}
+
+					return true, "", false, false, ""
+				}
+
+				// If it's a conflict, refetch the object and try again.
+				if k8serrors.IsConflict(err) {
+					log.Info("The object was updating during the evaluation. Trying again.")
+
+					rv, getErr := res.Get(context.TODO(), obj.existingObj.GetName(), metav1.GetOptions{})
+					if getErr == nil {
+						obj.existingObj = rv
+
+						return r.checkAndUpdateResource(obj, objectT, remediation)
+					}
+				}
+
+				message := getUpdateErrorMsg(err, obj.existingObj.GetKind(), obj.name)
+				if message == "" {
+					message = fmt.Sprintf(
+						"Error issuing a dry run update request for the object `%v`, the error is `%v`",
+						obj.name,
+						err,
+					)
+				}
+
+				return true, message, updateNeeded, false, ""
+			}
+
+			removeFieldsForComparison(dryRunUpdatedObj)
+			removeIgnoredFields(dryRunUpdatedObj, ignoredPaths)
+			dedupeUnorderedLists(dryRunUpdatedObj, unorderedPaths)
+
+			if dryRunUnchanged(dryRunUpdatedObj, existingObjectCopy, complianceType) {
+				log.Info(
+					"A mismatch was detected but a dry run update didn't make any changes. Assuming the object is " +
+						"compliant.",
+				)
+
+				r.setEvaluatedObject(obj.policy, obj.existingObj, true)
+				r.resetEnforcementHistory(obj.policy, obj.existingObj)
+
+				return false, "", false, false, ""
+			}
+
+			// Generate and log the diff. It's always generated in preview mode since it's reported in the status.
+			if suppressedNote != "" {
+				previewDiff = r.suppressDiff(obj, objectT, remediation, suppressedNote)
+			} else if objectT.RecordDiff == policyv1.RecordDiffLog || preview {
+				diff, err := r.objectDiff(existingObjectCopy, dryRunUpdatedObj, objectT.DiffFormat)
+				if err != nil {
+					log.Info("Failed to generate the diff: " + err.Error())
+				} else {
+					// The note would make a JSON patch invalid
+					if objectT.DiffFormat != policyv1.DiffFormatJSONPatch {
+						diff = withToleratedExternalFieldsNote(diff, externalPaths)
+					}
+
+					if objectT.RecordDiff == policyv1.RecordDiffLog {
+						log.Info(r.diffLogMessage(obj, diff+ownershipLog))
+
+						if remediation.IsInform() {
+							r.recordEventDiff(obj, diff)
+						}
+					}
+				}
+
+				previewDiff = diff
+			}
+		} else if suppressedNote != "" {
+			previewDiff = r.suppressDiff(obj, objectT, remediation, suppressedNote)
+		} else if objectT.RecordDiff == policyv1.RecordDiffLog || preview {
+			// Generate and log the diff for when dryrun is unsupported (i.e. OCP v3.11)
+			previewDiff = recordLocalDiff()
+		}
+
+		// The object would have been updated, so if it's inform, return as noncompliant.
+		if remediation.IsInform() {
+			r.setEvaluatedObject(obj.policy, obj.existingObj, false)
+
+			if len(ownedByOthers) != 0 {
+				message := fmt.Sprintf(
+					"%v %v %v: %v", obj.gvr.Resource, idStr, fieldsOwnedMsg, ownershipDescription(ownedByOthers),
+				)
+
+				return true, message, false, false, ""
+			}
+
+			return true, "", false, false, ""
+		}
+
+		// In preview mode, the update is only reported
+		if preview {
+			log.Info("Not updating the object since the policy is in preview mode")
+
+			return true, "", true, false, previewDiff
+		}
+
+		if message := r.enforcementPaused(obj); message != "" {
+			log.Info("Not updating the object since another controller keeps reverting the updates")
+
+			return true, message, false, false, ""
+		}
+
+		if message := r.enforcementThrottled(obj); message != "" {
+			log.Info("Not updating the object yet since the enforcement rate limit was reached")
+
+			return true, message, false, false, ""
+		}
+
+		if shouldRecreate(objectT, remediation, preview, nil) {
+			return r.recreateObject(obj, objectT, res)
+		}
+
+		// If it's not inform (i.e. enforce), update the object
+		log.Info("Updating the object based on the template definition")
+
+		updatedObj, err := patchOrUpdateObject(
+			res, originalObj, obj.existingObj, policyFieldManager(r.FieldManager, obj.policy.Name),
+		)
+		if err != nil {
+			if k8serrors.IsConflict(err) {
+				log.Info("The object updating during the evaluation. Trying again.")
+
+				rv, getErr := res.Get(context.TODO(), obj.existingObj.GetName(), metav1.GetOptions{})
+				if getErr == nil {
+					obj.existingObj = rv
+
+					return r.checkAndUpdateResource(obj, objectT, remediation)
+				}
+			}
+
+			if shouldRecreate(objectT, remediation, preview, err) {
+				return r.recreateObject(obj, objectT, res)
+			}
+
+			message := getUpdateErrorMsg(err, obj.existingObj.GetKind(), obj.name)
+			if message == "" {
+				message = fmt.Sprintf("Error updating the object `%v`, the error is `%v`", obj.name, err)
+			}
+
+			return true, message, updateNeeded, false, ""
+		}
+
+		if !statusMismatch {
+			r.setEvaluatedObject(obj.policy, updatedObj, true)
+		}
+
+		// An update that didn't change the object (e.g. a status only mismatch) can't be reverted
+		if updatedObj.GetResourceVersion() != originalObj.GetResourceVersion() {
+			r.recordEnforcement(obj.policy, originalObj)
+		}
+
+		updateSucceeded = true
+	} else {
+		r.setEvaluatedObject(obj.policy, obj.existingObj, !throwSpecViolation)
+
+		if !throwSpecViolation {
+			r.resetEnforcementHistory(obj.policy, obj.existingObj)
+		}
+	}
+
+	return throwSpecViolation, "", updateNeeded, updateSucceeded, ""
+}
+
+// checkAndApplyResource is the server-side apply variant of checkAndUpdateResource. A dry run apply request of the
+// desired object determines if the object is compliant, so fields owned by other field managers that aren't set in
+// the objectDefinition are not considered a mismatch. When enforcing, the desired object is applied with the
+// controller's field manager and conflicting fields are only taken over if spec.forceConflicts is set.
+func (r *ConfigurationPolicyReconciler) checkAndApplyResource(
+	obj singleObject,
+	objectT *policyv1.ObjectTemplate,
+	remediation policyv1.RemediationAction,
+	res dynamic.ResourceInterface,
+	ignoredPaths [][]string,
+) (
+	throwSpecViolation bool, message string, updateNeeded bool, updateSucceeded bool, previewDiff string,
+) {
+	log := log.WithValues(
+		"policy", obj.policy.Name, "name", obj.name, "namespace", obj.namespace, "resource", obj.gvr.Resource,
+	)
+
+	preview := isPreview(obj.policy)
+
+	existingObjectCopy := obj.existingObj.DeepCopy()
+	removeFieldsForComparison(existingObjectCopy)
+	removeIgnoredFields(existingObjectCopy, ignoredPaths)
+
+	// The status can't be applied, so it's compared the same way as with the Update enforcement method.
+	statusMismatch := false
+
+	if _, ok := obj.desiredObj.Object["status"]; ok {
+		complianceType := strings.ToLower(string(objectT.ComplianceType))
+
+		errorMsg, statusUpdateNeeded, _, _ := handleSingleKey(
+			"status", obj.desiredObj, existingObjectCopy.DeepCopy(), complianceType, nil, !r.DryRunSupported,
+		)
+		if errorMsg != "" {
+			return true, errorMsg, true, false, ""
+		}
+
+		statusMismatch = statusUpdateNeeded
+	}
+
+	force := obj.policy.Spec.ForceConflicts
+
+	dryRunAppliedObj, err := r.applyObject(res, obj, force, true)
+	if err != nil {
+		// A conflict means that a field in the objectDefinition is owned by another field manager and has a
+		// different value, so the object is noncompliant.
+		if k8serrors.IsConflict(err) {
+			r.setEvaluatedObject(obj.policy, obj.existingObj, false)
+
+			if remediation.IsInform() {
+				return true, "", false, false, ""
+			}
+
+			message := fmt.Sprintf(
+				"The object `%v` has fields managed by other field managers that conflict with the "+
+					"objectDefinition, set spec.forceConflicts to true to take ownership of them: %v",
+				obj.name,
+				err,
+			)
+
+			return true, message, true, false, ""
+		}
+
+		// The apply changes immutable fields, so the object must be recreated to be enforced
+		if shouldRecreate(objectT, remediation, preview, err) {
+			return r.recreateObject(obj, objectT, res)
+		}
+
+		message := getUpdateErrorMsg(err, obj.existingObj.GetKind(), obj.name)
+		if message == "" {
+			message = fmt.Sprintf(
+				"Error issuing a dry run apply request for the object `%v`, the error is `%v`", obj.name, err,
+			)
+		}
 
-	throwSpecViolation, message, updateNeeded, statusMismatch := handleKeys(
-		obj.desiredObj, obj.existingObj, existingObjectCopy, complianceType, mdComplianceType, !r.DryRunSupported,
-	)
-	if message != "" {
-		return true, message, true, false
+		return true, message, true, false, ""
 	}
 
-	if updateNeeded {
-		mismatchLog := "Detected value mismatch"
+	removeFieldsForComparison(dryRunAppliedObj)
+	removeIgnoredFields(dryRunAppliedObj, ignoredPaths)
 
-		// Add a configuration breadcrumb for users that might be looking in the logs for a diff
-		if objectT.RecordDiff != policyv1.RecordDiffLog {
-			mismatchLog += " (Diff disabled. To log the diff, " +
-				"set 'spec.object-tempates[].recordDiff' to 'Log' for this object-template.)"
+	if reflect.DeepEqual(dryRunAppliedObj.Object, existingObjectCopy.Object) {
+		r.setEvaluatedObject(obj.policy, obj.existingObj, !statusMismatch)
+
+		if !statusMismatch {
+			r.resetEnforcementHistory(obj.policy, obj.existingObj)
 		}
 
-		log.Info(mismatchLog)
+		return statusMismatch, "", false, false, ""
+	}
 
-		// FieldValidation is supported in k8s 1.25 as beta release
-		// so if the version is below 1.25, we need to use client side validation to validate the object
-		if semver.Compare(r.serverVersion, "v1.25.0") < 0 {
-			if err := r.validateObject(obj.existingObj); err != nil {
-				message := fmt.Sprintf("Error validating the object %s, the error is `%v`", obj.name, err)
+	mismatchLog := "Detected value mismatch"
+	suppressedNote := diffSuppressedNote(obj, objectT)
+
+	if suppressedNote != "" {
+		mismatchLog += " (" + suppressedNote + ")"
+	} else if objectT.RecordDiff != policyv1.RecordDiffLog {
+		mismatchLog += " (Diff disabled. To log the diff, " +
+			"set 'spec.object-tempates[].recordDiff' to 'Log' for this object-template.)"
+	}
+
+	log.Info(mismatchLog)
 
-				return true, message, updateNeeded, false
+	// The diff is always generated in preview mode since it's reported in the status
+	if suppressedNote != "" {
+		previewDiff = r.suppressDiff(obj, objectT, remediation, suppressedNote)
+	} else if objectT.RecordDiff == policyv1.RecordDiffLog || preview {
+		diff, err := r.objectDiff(existingObjectCopy, dryRunAppliedObj, objectT.DiffFormat)
+		if err != nil {
+			log.Info("Failed to generate the diff: " + err.Error())
+		} else if objectT.RecordDiff == policyv1.RecordDiffLog {
+			log.Info(r.diffLogMessage(obj, diff))
+
+			if remediation.IsInform() {
+				r.recordEventDiff(obj, diff)
 			}
 		}
 
-		// If the cluster supports dry run requests, verify that the API server agrees with the local comparison logic.
-		// It's possible the dry run request shows the object does match. This can happen if the ConfigurationPolicy
-		// specifies an empty map and the API server omits it from the return value.
-		if r.DryRunSupported {
-			dryRunUpdatedObj, err := res.Update(context.TODO(), obj.existingObj, metav1.UpdateOptions{
-				FieldValidation: metav1.FieldValidationStrict,
-				DryRun:          []string{metav1.DryRunAll},
-			})
-			if err != nil {
-				// If an inform policy and the update is forbidden (i.e. modifying Pod spec fields), then return
-				// noncompliant since that confirms some fields don't match.
-				if k8serrors.IsForbidden(err) {
-					r.setEvaluatedObject(obj.policy, obj.existingObj, false)
+		previewDiff = diff
+	}
 
-					return true, "", false, false
-				}
+	// The object would have been updated, so if it's inform, return as noncompliant.
+	if remediation.IsInform() {
+		r.setEvaluatedObject(obj.policy, obj.existingObj, false)
 
-				// If it's a conflict, refetch the object and try again.
-				if k8serrors.IsConflict(err) {
-					log.Info("The object was updating during the evaluation. Trying again.")
+		return true, "", false, false, ""
+	}
 
-					rv, getErr := res.Get(context.TODO(), obj.existingObj.GetName(), metav1.GetOptions{})
-					if getErr == nil {
-						obj.existingObj = rv
+	// In preview mode, the apply is only reported
+	if preview {
+		log.Info("Not applying the object since the policy is in preview mode")
 
-						return r.checkAndUpdateResource(obj, objectT, remediation)
-					}
-				}
+		return true, "", true, false, previewDiff
+	}
 
-				message := getUpdateErrorMsg(err, obj.existingObj.GetKind(), obj.name)
-				if message == "" {
-					message = fmt.Sprintf(
-						"Error issuing a dry run update request for the object `%v`, the error is `%v`",
-						obj.name,
-						err,
-					)
-				}
+	if message := r.enforcementPaused(obj); message != "" {
+		log.Info("Not applying the object since another controller keeps reverting the changes")
 
-				return true, message, updateNeeded, false
-			}
+		return true, message, false, false, ""
+	}
 
-			removeFieldsForComparison(dryRunUpdatedObj)
+	if message := r.enforcementThrottled(obj); message != "" {
+		log.Info("Not applying the object yet since the enforcement rate limit was reached")
 
-			if reflect.DeepEqual(dryRunUpdatedObj.Object, existingObjectCopy.Object) {
-				log.Info(
-					"A mismatch was detected but a dry run update didn't make any changes. Assuming the object is " +
-						"compliant.",
-				)
+		return true, message, false, false, ""
+	}
 
-				r.setEvaluatedObject(obj.policy, obj.existingObj, true)
+	if shouldRecreate(objectT, remediation, preview, nil) {
+		return r.recreateObject(obj, objectT, res)
+	}
 
-				return false, "", false, false
-			}
+	log.Info("Applying the object based on the template definition")
 
-			// Generate and log the diff
-			if objectT.RecordDiff == policyv1.RecordDiffLog {
-				diff, err := generateDiff(existingObjectCopy, dryRunUpdatedObj)
-				if err != nil {
-					log.Info("Failed to generate the diff: " + err.Error())
-				} else {
-					log.Info("Logging the diff:\n" + diff)
-				}
-			}
-		} else if objectT.RecordDiff == policyv1.RecordDiffLog {
-			// Generate and log the diff for when dryrun is unsupported (i.e. OCP v3.11)
-			mergedObjCopy := obj.existingObj.DeepCopy()
-			removeFieldsForComparison(mergedObjCopy)
+	appliedObj, err := r.applyObject(res, obj, force, false)
+	if err != nil {
+		if shouldRecreate(objectT, remediation, preview, err) {
+			return r.recreateObject(obj, objectT, res)
+		}
 
-			diff, err := generateDiff(existingObjectCopy, mergedObjCopy)
-			if err != nil {
-				log.Info("Failed to generate the diff: " + err.Error())
-			} else {
-				log.Info("Logging the diff:\n" + diff)
-			}
+		message := getUpdateErrorMsg(err, obj.existingObj.GetKind(), obj.name)
+		if message == "" {
+			message = fmt.Sprintf("Error applying the object `%v`, the error is `%v`", obj.name, err)
 		}
 
-		// The object would have been updated, so if it's inform, return as noncompliant.
-		if remediation.IsInform() {
-			r.setEvaluatedObject(obj.policy, obj.existingObj, false)
+		return true, message, true, false, ""
+	}
 
-			return true, "", false, false
-		}
+	if !statusMismatch {
+		r.setEvaluatedObject(obj.policy, appliedObj, true)
+	}
 
-		// If it's not inform (i.e. enforce), update the object
-		log.Info("Updating the object based on the template definition")
+	if appliedObj.GetResourceVersion() != obj.existingObj.GetResourceVersion() {
+		r.recordEnforcement(obj.policy, obj.existingObj)
+	}
 
-		updatedObj, err := res.Update(context.TODO(), obj.existingObj, metav1.UpdateOptions{
-			FieldValidation: metav1.FieldValidationStrict,
-		})
-		if err != nil {
-			if k8serrors.IsConflict(err) {
-				log.Info("The object updating during the evaluation. Trying again.")
+	return statusMismatch, "", true, true, ""
+}
 
-				rv, getErr := res.Get(context.TODO(), obj.existingObj.GetName(), metav1.GetOptions{})
-				if getErr == nil {
-					obj.existingObj = rv
+// metadataComplianceTypes are the lowercase compliance types of the metadata of an object template. The labels and
+// annotations compliance types override the metadata compliance type for their own map.
+type metadataComplianceTypes struct {
+	metadata    string
+	labels      string
+	annotations string
+}
 
-					return r.checkAndUpdateResource(obj, objectT, remediation)
-				}
-			}
+func getMetadataComplianceTypes(objectT *policyv1.ObjectTemplate) metadataComplianceTypes {
+	return metadataComplianceTypes{
+		metadata:    strings.ToLower(string(objectT.MetadataComplianceType)),
+		labels:      strings.ToLower(string(objectT.LabelsComplianceType)),
+		annotations: strings.ToLower(string(objectT.AnnotationsComplianceType)),
+	}
+}
 
-			message := getUpdateErrorMsg(err, obj.existingObj.GetKind(), obj.name)
-			if message == "" {
-				message = fmt.Sprintf("Error updating the object `%v`, the error is `%v`", obj.name, err)
-			}
+// separate determines if the labels and annotations are compared with different compliance types, in which case
+// each map is compared on its own.
+func (m metadataComplianceTypes) separate() bool {
+	return m.labels != "" || m.annotations != ""
+}
+
+// forField returns the compliance type of the metadata field (labels or annotations), which defaults to the
+// metadata compliance type and then to the input compliance type of the object template.
+func (m metadataComplianceTypes) forField(field string, compType string) string {
+	if field == "labels" && m.labels != "" {
+		return m.labels
+	}
+
+	if field == "annotations" && m.annotations != "" {
+		return m.annotations
+	}
+
+	if m.metadata != "" {
+		return m.metadata
+	}
+
+	return compType
+}
 
-			return true, message, updateNeeded, false
+// handleMetadataMaps compares the labels and annotations of the desired object to the existing object each with
+// their own compliance type, and sets the merged maps on the existing object. The maps that aren't in the desired
+// object are left as is. It returns the mismatched maps.
+func handleMetadataMaps(
+	desiredObj unstructured.Unstructured,
+	existingObj *unstructured.Unstructured,
+	existingObjectCopy *unstructured.Unstructured,
+	compType string,
+	mdCompTypes metadataComplianceTypes,
+	zeroValueEqualsNil bool,
+) (message string, mismatched []string) {
+	for _, field := range []string{"labels", "annotations"} {
+		desiredValue, found, _ := unstructured.NestedFieldNoCopy(desiredObj.Object, "metadata", field)
+		if !found {
+			continue
 		}
 
-		if !statusMismatch {
-			r.setEvaluatedObject(obj.policy, updatedObj, true)
+		fieldDesiredObj := unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{field: desiredValue},
+		}}
+
+		errorMsg, fieldUpdateNeeded, mergedObj, _ := handleSingleKey(
+			"metadata",
+			fieldDesiredObj,
+			existingObjectCopy,
+			mdCompTypes.forField(field, compType),
+			nil,
+			zeroValueEqualsNil,
+		)
+		if errorMsg != "" {
+			return errorMsg, mismatched
 		}
 
-		updateSucceeded = true
-	} else {
-		r.setEvaluatedObject(obj.policy, obj.existingObj, !throwSpecViolation)
+		// if it's not the right type, the map will be empty
+		mdMap, _ := mergedObj.(map[string]interface{})
+		merged, _, _ := unstructured.NestedStringMap(mdMap, field)
+
+		if field == "labels" {
+			existingObj.SetLabels(merged)
+		} else {
+			existingObj.SetAnnotations(merged)
+		}
+
+		if fieldUpdateNeeded {
+			mismatched = append(mismatched, field)
+		}
 	}
 
-	return throwSpecViolation, "", updateNeeded, updateSucceeded
+	return "", mismatched
 }
 
 // handleKeys goes through all of the fields in the desired object and checks if the existing object
@@ -2745,21 +5817,41 @@ func handleKeys(
 	existingObj *unstructured.Unstructured,
 	existingObjectCopy *unstructured.Unstructured,
 	compType string,
-	mdCompType string,
+	mdCompTypes metadataComplianceTypes,
+	mergeKeys []listMergeKey,
 	zeroValueEqualsNil bool,
 ) (throwSpecViolation bool, message string, updateNeeded bool, statusMismatch bool) {
 	for key := range desiredObj.Object {
 		isStatus := key == "status"
 
+		if key == "metadata" && mdCompTypes.separate() {
+			errorMsg, mismatched := handleMetadataMaps(
+				desiredObj, existingObj, existingObjectCopy, compType, mdCompTypes, zeroValueEqualsNil,
+			)
+			if errorMsg != "" {
+				log.Info(errorMsg)
+
+				return true, errorMsg, true, statusMismatch
+			}
+
+			if len(mismatched) != 0 {
+				log.Info("Detected a metadata mismatch", "fields", mismatched)
+
+				updateNeeded = true
+			}
+
+			continue
+		}
+
 		// use metadatacompliancetype to evaluate metadata if it is set
 		keyComplianceType := compType
-		if key == "metadata" && mdCompType != "" {
-			keyComplianceType = mdCompType
+		if key == "metadata" && mdCompTypes.metadata != "" {
+			keyComplianceType = mdCompTypes.metadata
 		}
 
 		// check key for mismatch
 		errorMsg, keyUpdateNeeded, mergedObj, skipped := handleSingleKey(
-			key, desiredObj, existingObjectCopy, keyComplianceType, zeroValueEqualsNil,
+			key, desiredObj, existingObjectCopy, keyComplianceType, mergeKeys, zeroValueEqualsNil,
 		)
 		if errorMsg != "" {
 			log.Info(errorMsg)
@@ -2808,6 +5900,17 @@ func removeFieldsForComparison(obj *unstructured.Unstructured) {
 	unstructured.RemoveNestedField(obj.Object, "metadata", "generation")
 }
 
+// dryRunUnchanged returns whether the object returned by a dry run update is the same as the existing object, meaning
+// that the update wouldn't change anything. Unless the compliance type is mustonlyhave, null and empty map fields are
+// ignored, since the API server may return them for an update and drop them when the object is stored.
+func dryRunUnchanged(dryRunObj, existingObj *unstructured.Unstructured, complianceType string) bool {
+	if strings.EqualFold(complianceType, "mustonlyhave") {
+		return reflect.DeepEqual(dryRunObj.Object, existingObj.Object)
+	}
+
+	return reflect.DeepEqual(pruneNullAndEmpty(dryRunObj.Object), pruneNullAndEmpty(existingObj.Object))
+}
+
 // setEvaluatedObject updates the cache to indicate that the ConfigurationPolicy has evaluated this
 // object at its current resourceVersion.
 func (r *ConfigurationPolicyReconciler) setEvaluatedObject(
@@ -2905,6 +6008,7 @@ func IsSimilarToLastCondition(oldCond policyv1.Condition, newCond policyv1.Condi
 // argument determines if a status update event should be sent on the parent policy and configuration policy.
 func (r *ConfigurationPolicyReconciler) addForUpdate(policy *policyv1.ConfigurationPolicy, sendEvent bool) {
 	compliant := true
+	pending := false
 
 	if policy.Spec == nil {
 		compliant = false
@@ -2915,6 +6019,10 @@ func (r *ConfigurationPolicyReconciler) addForUpdate(policy *policyv1.Configurat
 
 				break
 			}
+
+			if policy.Status.CompliancyDetails[index].ComplianceState == policyv1.Pending {
+				pending = true
+			}
 		}
 	}
 
@@ -2924,12 +6032,39 @@ func (r *ConfigurationPolicyReconciler) addForUpdate(policy *policyv1.Configurat
 		policy.Status.ComplianceState = policyv1.Terminating
 	} else if len(policy.Status.CompliancyDetails) == 0 {
 		policy.Status.ComplianceState = policyv1.UnknownCompliancy
+	} else if compliant && pending {
+		policy.Status.ComplianceState = policyv1.Pending
 	} else if compliant {
 		policy.Status.ComplianceState = policyv1.Compliant
 	} else {
 		policy.Status.ComplianceState = policyv1.NonCompliant
 	}
 
+	var reportedDetails []policyv1.TemplateStatus
+
+	keepReportedDetails := false
+
+	if applyNoncomplianceGracePeriod(policy, previousComplianceState, time.Now()) {
+		log.V(1).Info(
+			"Not reporting the policy noncompliance during the noncompliance grace period",
+			"policy", policy.GetName(), "pendingNoncompliance", policy.Status.PendingNoncompliance,
+		)
+
+		// The noncompliant details are only reported with the noncompliance, so the ones last reported are kept. The
+		// evaluated policy keeps its details since the rest of the evaluation relies on them.
+		if policy.Status.PendingNoncompliance != nil {
+			reported := &policyv1.ConfigurationPolicy{}
+
+			err := r.Get(context.TODO(), types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}, reported)
+			if err == nil {
+				reportedDetails = reported.Status.CompliancyDetails
+				keepReportedDetails = true
+			}
+		}
+
+		sendEvent = false
+	}
+
 	// Always send an event if the ComplianceState changed
 	if previousComplianceState != policy.Status.ComplianceState {
 		sendEvent = true
@@ -2940,10 +6075,21 @@ func (r *ConfigurationPolicyReconciler) addForUpdate(policy *policyv1.Configurat
 		sendEvent = true
 	}
 
+	setCompliantCondition(policy)
+
 	policy.Status.LastEvaluated = time.Now().UTC().Format(time.RFC3339)
 	policy.Status.LastEvaluatedGeneration = policy.Generation
+	policy.Status.LastTriggeredEvaluation = policy.GetAnnotations()[triggerEvaluationAnnotation]
+
+	statusPolicy := policy
 
-	err := r.updatePolicyStatus(policy, sendEvent)
+	if keepReportedDetails {
+		statusPolicy = policy.DeepCopy()
+		statusPolicy.Status.CompliancyDetails = reportedDetails
+		setCompliantCondition(statusPolicy)
+	}
+
+	err := r.updatePolicyStatus(statusPolicy, sendEvent)
 	policyLog := log.WithValues("name", policy.Name, "namespace", policy.Namespace)
 
 	if k8serrors.IsConflict(err) {
@@ -2960,6 +6106,151 @@ func (r *ConfigurationPolicyReconciler) addForUpdate(policy *policyv1.Configurat
 	}
 }
 
+// applyNoncomplianceGracePeriod keeps the reported compliance state of a compliant policy that became noncompliant
+// until the noncompliance persists past the policy's noncomplianceGracePeriod, and tracks the pending noncompliance in
+// the status. It returns true when the noncompliance is pending or was resolved before the grace period passed, in
+// which case the reported compliance is unchanged and no compliance event should be sent.
+func applyNoncomplianceGracePeriod(
+	policy *policyv1.ConfigurationPolicy, previousComplianceState policyv1.ComplianceState, now time.Time,
+) bool {
+	pending := policy.Status.PendingNoncompliance
+	policy.Status.PendingNoncompliance = nil
+
+	if policy.Spec == nil || policy.Spec.NoncomplianceGracePeriod == "" {
+		return false
+	}
+
+	gracePeriod, err := time.ParseDuration(policy.Spec.NoncomplianceGracePeriod)
+	if err != nil || gracePeriod <= 0 {
+		return false
+	}
+
+	if policy.Status.ComplianceState != policyv1.NonCompliant || previousComplianceState != policyv1.Compliant {
+		return pending != nil && policy.Status.ComplianceState == policyv1.Compliant
+	}
+
+	since := now
+
+	if pending != nil {
+		if pendingSince, err := time.Parse(time.RFC3339, pending.Since); err == nil {
+			since = pendingSince
+		}
+	}
+
+	effectiveAt := since.Add(gracePeriod)
+	if !now.Before(effectiveAt) {
+		return false
+	}
+
+	policy.Status.ComplianceState = policyv1.Compliant
+	policy.Status.PendingNoncompliance = &policyv1.PendingNoncompliance{
+		Since:       since.UTC().Format(time.RFC3339),
+		EffectiveAt: effectiveAt.UTC().Format(time.RFC3339),
+	}
+
+	return true
+}
+
+// noncomplianceGracePending returns true if a noncompliance found in the current evaluation of the policy wouldn't be
+// reported yet due to the policy's noncomplianceGracePeriod. This is used before the compliance state is calculated
+// to not emit events for a noncompliance that is masked.
+func noncomplianceGracePending(policy *policyv1.ConfigurationPolicy, now time.Time) bool {
+	if policy.Spec == nil || policy.Spec.NoncomplianceGracePeriod == "" {
+		return false
+	}
+
+	gracePeriod, err := time.ParseDuration(policy.Spec.NoncomplianceGracePeriod)
+	if err != nil || gracePeriod <= 0 {
+		return false
+	}
+
+	if pending := policy.Status.PendingNoncompliance; pending != nil {
+		effectiveAt, err := time.Parse(time.RFC3339, pending.EffectiveAt)
+
+		return err == nil && now.Before(effectiveAt)
+	}
+
+	return policy.Status.ComplianceState == policyv1.Compliant
+}
+
+// setCompliantCondition sets the standard Compliant condition in the policy status based on its compliance state and
+// the messages of its compliancy details. The last transition time only changes when the condition status changes.
+func setCompliantCondition(policy *policyv1.ConfigurationPolicy) {
+	condition := metav1.Condition{
+		Type:               compliantConditionType,
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: policy.Generation,
+		Reason:             string(policyv1.UnknownCompliancy),
+		Message:            string(policyv1.UnknownCompliancy),
+	}
+
+	switch policy.Status.ComplianceState {
+	case policyv1.Compliant:
+		condition.Status = metav1.ConditionTrue
+	case policyv1.NonCompliant, policyv1.Pending:
+		condition.Status = metav1.ConditionFalse
+	}
+
+	if policy.Status.ComplianceState != "" {
+		condition.Reason = string(policy.Status.ComplianceState)
+		condition.Message = string(policy.Status.ComplianceState)
+	}
+
+	if details := compliancyDetailsMessage(policy.Status); details != "" {
+		condition.Message += "; " + details
+	}
+
+	// The condition message has a maximum length in the CRD
+	if len(condition.Message) > maxConditionMessageLength {
+		condition.Message = truncateString(
+			condition.Message, maxConditionMessageLength-len(truncatedMessageSuffix),
+		) + truncatedMessageSuffix
+	}
+
+	meta.SetStatusCondition(&policy.Status.Conditions, condition)
+}
+
+// setPruneSkippedCondition sets the PruneSkipped condition in the policy status to list the related objects that
+// weren't pruned since they were replaced after the policy created them. The condition is removed when there are none.
+func setPruneSkippedCondition(policy *policyv1.ConfigurationPolicy, skipped []string) {
+	if len(skipped) == 0 {
+		meta.RemoveStatusCondition(&policy.Status.Conditions, pruneSkippedConditionType)
+
+		return
+	}
+
+	message := strings.Join(skipped, "; ")
+
+	// The condition message has a maximum length in the CRD
+	if len(message) > maxConditionMessageLength {
+		message = truncateString(message, maxConditionMessageLength-len(truncatedMessageSuffix)) +
+			truncatedMessageSuffix
+	}
+
+	meta.SetStatusCondition(&policy.Status.Conditions, metav1.Condition{
+		Type:               pruneSkippedConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: policy.Generation,
+		Reason:             eventReasonPruneSkipped,
+		Message:            message,
+	})
+}
+
+// compliancyDetailsMessage joins the messages of the compliancy details conditions in the status.
+func compliancyDetailsMessage(status policyv1.ConfigurationPolicyStatus) string {
+	messages := []string{}
+
+	for _, compliancyDetail := range status.CompliancyDetails {
+		for _, condition := range compliancyDetail.Conditions {
+			if condition.Message != "" {
+				messages = append(messages, condition.Message)
+			}
+		}
+	}
+
+	return strings.Join(messages, "; ")
+}
+
 // updatePolicyStatus updates the status of the configurationPolicy if new conditions are added and generates an event
 // on the parent policy and configuration policy with the compliance decision if the sendEvent argument is true.
 func (r *ConfigurationPolicyReconciler) updatePolicyStatus(
@@ -2980,6 +6271,13 @@ func (r *ConfigurationPolicyReconciler) updatePolicyStatus(
 		"Updating configurationPolicy status", "status", policy.Status.ComplianceState, "policy", policy.GetName(),
 	)
 
+	if boundStatusSize(&policy.Status, r.MaxStatusBytes) {
+		log.Info(
+			"Shortened the configurationPolicy status to fit the maximum status size",
+			"policy", policy.GetName(), "maxStatusBytes", r.MaxStatusBytes,
+		)
+	}
+
 	updatedStatus := policy.Status
 
 	maxRetries := 3
@@ -3003,27 +6301,15 @@ func (r *ConfigurationPolicyReconciler) updatePolicyStatus(
 		}
 	}
 
+	setPolicyComplianceMetric(policy)
+
 	if sendEvent {
 		log.V(1).Info("Sending policy status update event")
 
 		var msg string
 
-		for i, compliancyDetail := range policy.Status.CompliancyDetails {
-			if i == 0 {
-				msg = ": "
-			}
-
-			for _, condition := range compliancyDetail.Conditions {
-				if condition.Message == "" {
-					continue
-				}
-
-				if msg != ": " {
-					msg += "; "
-				}
-
-				msg += condition.Message
-			}
+		if len(policy.Status.CompliancyDetails) != 0 {
+			msg = ": " + compliancyDetailsMessage(policy.Status)
 		}
 
 		eventType := eventNormal
@@ -3067,7 +6353,7 @@ func (r *ConfigurationPolicyReconciler) sendComplianceEvent(instance *policyv1.C
 			APIVersion: ownerRef.APIVersion,
 		},
 		Reason:  fmt.Sprintf(eventFmtStr, instance.Namespace, instance.Name),
-		Message: convertPolicyStatusToString(instance),
+		Message: convertPolicyStatusToString(instance) + r.eventDiffsMessage(instance),
 		Source: corev1.EventSource{
 			Component: ControllerName,
 			Host:      r.InstanceName,
@@ -3088,26 +6374,40 @@ func (r *ConfigurationPolicyReconciler) sendComplianceEvent(instance *policyv1.C
 		ReportingInstance:   r.InstanceName,
 	}
 
-	eventAnnotations := map[string]string{}
+	event.Annotations = complianceEventAnnotations(
+		instance, complianceEventDBIDs(context.TODO(), r.Client, instance), instance.Status.ComplianceState,
+		compliantConditionType,
+	)
 
-	instanceAnnotations := instance.GetAnnotations()
-	if instanceAnnotations[common.ParentDBIDAnnotation] != "" {
-		eventAnnotations[common.ParentDBIDAnnotation] = instanceAnnotations[common.ParentDBIDAnnotation]
+	if instance.Status.ComplianceState != policyv1.Compliant {
+		event.Type = "Warning"
 	}
 
-	if instanceAnnotations[common.PolicyDBIDAnnotation] != "" {
-		eventAnnotations[common.PolicyDBIDAnnotation] = instanceAnnotations[common.PolicyDBIDAnnotation]
+	if r.ComplianceHistory != nil {
+		r.ComplianceHistory.ReportEvent(event, instance.Status.ComplianceState)
 	}
 
-	if len(eventAnnotations) > 0 {
-		event.Annotations = eventAnnotations
+	event.Message = truncateComplianceEventMessage(event.Message, r.MaxComplianceEventMessageLength)
+
+	if !r.ComplianceEvents.createsEvent(event) {
+		return nil
 	}
 
-	if instance.Status.ComplianceState != policyv1.Compliant {
-		event.Type = "Warning"
+	err := createComplianceEventWithRetries(
+		context.TODO(), r.Client, &r.complianceEvents, r.ComplianceEventDedupWindow, complianceEventBackoff, event,
+	)
+	if err != nil && complianceEventPermanentError(err) {
+		// Retrying won't help, so the status is still updated. The transient failures are returned so that the status
+		// isn't updated and the compliance event is sent again on the next evaluation.
+		log.Error(
+			err, "Dropping the compliance event that can't be created",
+			"namespace", instance.Namespace, "policy", instance.Name,
+		)
+
+		return nil
 	}
 
-	return r.Create(context.TODO(), event)
+	return err
 }
 
 // convertPolicyStatusToString to be able to pass the status as event
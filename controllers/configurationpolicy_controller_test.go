@@ -5,17 +5,31 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
 
+	gocmp "github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/yaml"
@@ -569,14 +583,14 @@ status:
 	existingObjOrderOne := unstructured.Unstructured{Object: orderOneObj}
 	existingObjOrderTwo := unstructured.Unstructured{Object: orderTwoObj}
 
-	errormsg, updateNeeded, _, _ := handleSingleKey("status", desiredObj, &existingObjOrderOne, "musthave", true)
+	errormsg, updateNeeded, _, _ := handleSingleKey("status", desiredObj, &existingObjOrderOne, "musthave", nil, true)
 	if len(errormsg) != 0 {
 		t.Error("Got unexpected error message", errormsg)
 	}
 
 	assert.False(t, updateNeeded)
 
-	errormsg, updateNeeded, _, _ = handleSingleKey("status", desiredObj, &existingObjOrderTwo, "musthave", true)
+	errormsg, updateNeeded, _, _ = handleSingleKey("status", desiredObj, &existingObjOrderTwo, "musthave", nil, true)
 	if len(errormsg) != 0 {
 		t.Error("Got unexpected error message", errormsg)
 	}
@@ -683,6 +697,83 @@ func TestSortRelatedObjectsAndUpdate(t *testing.T) {
 	assert.True(t, relatedList[0].Object.Metadata.Name == "bar")
 }
 
+func TestSortRelatedObjectsLastEvaluated(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: "inform"},
+	}
+	rsrc := policyv1.SchemeBuilder.GroupVersion.WithResource("ConfigurationPolicy")
+	notCreated := false
+
+	newRelated := func(name string) []policyv1.RelatedObject {
+		return addRelatedObjects(true, rsrc, "ConfigurationPolicy", "default", true, []string{name}, "reason",
+			&policyv1.ObjectProperties{CreatedByPolicy: &notCreated, UID: name + "-uid"})
+	}
+
+	firstEvaluation := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldRelated := append(newRelated("foo"), newRelated("bar")...)
+	setRelatedLastEvaluated(oldRelated, firstEvaluation)
+
+	// Only foo is evaluated again, so bar is pruned and foo has the new evaluation time
+	related := newRelated("foo")
+	setRelatedLastEvaluated(related, firstEvaluation.Add(time.Minute))
+
+	r.sortRelatedObjectsAndUpdate(policy, related, oldRelated, false, false)
+	assert.Len(t, policy.Status.RelatedObjects, 1)
+	assert.Equal(t, "foo", policy.Status.RelatedObjects[0].Object.Metadata.Name)
+	assert.Equal(t, "2024-01-01T00:01:00Z", policy.Status.RelatedObjects[0].Properties.LastEvaluated)
+
+	// The related objects are otherwise the same when only the evaluation time differs
+	assert.True(t, gocmp.Equal(oldRelated[:1], related, ignoreEvaluationProperties))
+	assert.False(t, gocmp.Equal(oldRelated[:1], related))
+}
+
+func TestSortRelatedObjectsEvaluatedIdentity(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: "enforce"},
+	}
+	rsrc := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	created := true
+	existing := &unstructured.Unstructured{}
+	existing.SetUID("foo-uid")
+	existing.SetResourceVersion("2")
+
+	oldRelated := addRelatedObjects(true, rsrc, "ConfigMap", "default", true, []string{"foo"}, "reason",
+		&policyv1.ObjectProperties{CreatedByPolicy: &created, UID: "foo-uid", ResourceVersion: "1"})
+
+	evaluatedRelated := func() []policyv1.RelatedObject {
+		notCreated := false
+
+		return addRelatedObjects(true, rsrc, "ConfigMap", "default", true, []string{"foo"}, "reason",
+			setEvaluatedIdentity(&policyv1.ObjectProperties{CreatedByPolicy: &notCreated}, existing))
+	}
+
+	// The object the policy created is still recorded as created, with the resourceVersion of this evaluation
+	r.sortRelatedObjectsAndUpdate(policy, evaluatedRelated(), oldRelated, false, false)
+
+	properties := policy.Status.RelatedObjects[0].Properties
+	assert.True(t, *properties.CreatedByPolicy)
+	assert.Equal(t, "foo-uid", properties.UID)
+	assert.Equal(t, "2", properties.ResourceVersion)
+
+	// The object was deleted and recreated by something else, so the policy no longer created it
+	existing.SetUID("new-uid")
+	existing.SetResourceVersion("3")
+	r.sortRelatedObjectsAndUpdate(policy, evaluatedRelated(), oldRelated, false, false)
+
+	properties = policy.Status.RelatedObjects[0].Properties
+	assert.False(t, *properties.CreatedByPolicy)
+	assert.Equal(t, "new-uid", properties.UID)
+	assert.Equal(t, "3", properties.ResourceVersion)
+}
+
 func TestCreateStatus(t *testing.T) {
 	testcases := []struct {
 		testName          string
@@ -892,6 +983,142 @@ func TestCreateStatus(t *testing.T) {
 			"K8s has a `must not have` object",
 			"configmaps [buzz] found in namespace toy-story",
 		},
+		{
+			"must have single object exists with contents not evaluated",
+			"secrets",
+			map[string]*objectTmplEvalResultWithEvent{
+				"toy-story": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: true,
+						reason:    reasonWantFoundExistsOnly,
+					},
+				},
+			},
+			true,
+			"K8s `must have` object already exists",
+			"secrets [buzz] found (contents not evaluated) in namespace toy-story",
+		},
+		{
+			"must not have single object terminating",
+			"configmaps",
+			map[string]*objectTmplEvalResultWithEvent{
+				"toy-story": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: false,
+						reason:    reasonWantNotFoundTerm,
+					},
+				},
+			},
+			false,
+			"K8s has a `must not have` object",
+			"configmaps [buzz] found but is terminating in namespace toy-story",
+		},
+		{
+			"must have single object would be updated in preview mode",
+			"configmaps",
+			map[string]*objectTmplEvalResultWithEvent{
+				"toy-story": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: false,
+						reason:    reasonPreviewUpdate,
+					},
+				},
+			},
+			false,
+			"K8s does not have a `must have` object",
+			"configmaps [buzz] found but not as specified and would be updated (preview) in namespace toy-story",
+		},
+		{
+			"must not have single object would be deleted in preview mode",
+			"configmaps",
+			map[string]*objectTmplEvalResultWithEvent{
+				"toy-story": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: false,
+						reason:    reasonPreviewDelete,
+					},
+				},
+			},
+			false,
+			"K8s has a `must not have` object",
+			"configmaps [buzz] found and would be deleted (preview) in namespace toy-story",
+		},
+		{
+			"must have objects throttled by the enforcement rate limit",
+			"configmaps",
+			map[string]*objectTmplEvalResultWithEvent{
+				"toy-story": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: true,
+						reason:    reasonUpdateSuccess,
+					},
+				},
+				"toy-story2": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: false,
+						reason:    reasonEnforcementThrottled,
+					},
+				},
+				"toy-story3": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: false,
+						reason:    reasonEnforcementThrottled,
+					},
+				},
+			},
+			false,
+			reasonEnforcementThrottled,
+			"configmaps [buzz] not remediated yet since the enforcement rate limit was reached in namespaces: " +
+				"toy-story2, toy-story3 (remediated 1/3 namespaces)",
+		},
+		{
+			"access denied by RBAC",
+			"secrets",
+			map[string]*objectTmplEvalResultWithEvent{
+				"toy-story": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: true,
+						reason:    reasonWantFoundExists,
+					},
+				},
+				"toy-story2": {
+					result: objectTmplEvalResult{
+						objectNames: []string{"buzz"},
+					},
+					event: objectTmplEvalEvent{
+						compliant: false,
+						reason:    reasonAccessDenied,
+					},
+				},
+			},
+			false,
+			reasonAccessDenied,
+			"secrets [buzz] can't be evaluated since access is denied by RBAC in namespace toy-story2",
+		},
 		{
 			"must not have single object not found",
 			"configmaps",
@@ -1229,6 +1456,275 @@ func TestShouldEvaluatePolicy(t *testing.T) {
 	}
 }
 
+func TestStartupJitter(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{StartupJitterPerPolicy: time.Second}
+	policies := make([]policyv1.ConfigurationPolicy, 10)
+
+	for i := range policies {
+		policies[i].SetUID(types.UID(fmt.Sprintf("policy-%d", i)))
+	}
+
+	now := time.Now()
+	r.scheduleStartupJitter(policies, now)
+
+	for i := range policies {
+		until, ok := r.startupJitterCache.Load(policies[i].GetUID())
+		assert.True(t, ok)
+		assert.False(t, until.(time.Time).Before(now))
+		assert.True(t, until.(time.Time).Before(now.Add(10*time.Second)))
+	}
+
+	// Once the delay passed, the policy is no longer tracked
+	assert.False(t, r.startupJitterPending(&policies[0], now.Add(10*time.Second)))
+	assert.False(t, r.startupJitterPending(&policies[0], now))
+
+	// Policies created after the controller started aren't delayed
+	assert.False(t, r.startupJitterPending(&policyv1.ConfigurationPolicy{}, now))
+}
+
+func TestEvaluationIntervalJitter(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1.ConfigurationPolicy{}
+	policy.SetUID("policy-uid")
+	policy.Status.LastEvaluated = "2024-01-01T00:00:00Z"
+
+	jitter := evaluationIntervalJitter(policy, 10*time.Second)
+	assert.Less(t, jitter, time.Second)
+	assert.Equal(t, jitter, evaluationIntervalJitter(policy, 10*time.Second))
+
+	assert.Less(t, evaluationIntervalJitter(policy, 24*time.Hour), 30*time.Second)
+	assert.Equal(t, time.Duration(0), evaluationIntervalJitter(policy, 0))
+}
+
+func TestEvaluationTriggered(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Generation: 2},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			EvaluationInterval: policyv1.EvaluationInterval{Compliant: "never", NonCompliant: "never"},
+		},
+		Status: policyv1.ConfigurationPolicyStatus{
+			ComplianceState:         policyv1.Compliant,
+			LastEvaluated:           time.Now().UTC().Format(time.RFC3339),
+			LastEvaluatedGeneration: 2,
+		},
+	}
+
+	assert.False(t, evaluationTriggered(policy))
+	assert.False(t, r.shouldEvaluatePolicy(policy, false))
+
+	policy.SetAnnotations(map[string]string{triggerEvaluationAnnotation: "2024-01-01T00:00:00Z"})
+	assert.True(t, evaluationTriggered(policy))
+	assert.True(t, r.shouldEvaluatePolicy(policy, false))
+
+	// The same value is only honored once
+	policy.Status.LastTriggeredEvaluation = "2024-01-01T00:00:00Z"
+	assert.False(t, evaluationTriggered(policy))
+	assert.False(t, r.shouldEvaluatePolicy(policy, false))
+}
+
+func TestApplyNoncomplianceGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &policyv1.ConfigurationPolicy{
+		Spec:   &policyv1.ConfigurationPolicySpec{NoncomplianceGracePeriod: "5m"},
+		Status: policyv1.ConfigurationPolicyStatus{ComplianceState: policyv1.NonCompliant},
+	}
+
+	// The noncompliance is pending, so the compliant state is kept
+	assert.True(t, applyNoncomplianceGracePeriod(policy, policyv1.Compliant, now))
+	assert.Equal(t, policyv1.Compliant, policy.Status.ComplianceState)
+	assert.Equal(
+		t,
+		&policyv1.PendingNoncompliance{Since: "2024-01-01T00:00:00Z", EffectiveAt: "2024-01-01T00:05:00Z"},
+		policy.Status.PendingNoncompliance,
+	)
+
+	// The grace period is counted from the first noncompliant evaluation
+	policy.Status.ComplianceState = policyv1.NonCompliant
+	assert.True(t, applyNoncomplianceGracePeriod(policy, policyv1.Compliant, now.Add(4*time.Minute)))
+	assert.Equal(t, "2024-01-01T00:05:00Z", policy.Status.PendingNoncompliance.EffectiveAt)
+
+	// The noncompliance is reported once it persists past the grace period
+	policy.Status.ComplianceState = policyv1.NonCompliant
+	assert.False(t, applyNoncomplianceGracePeriod(policy, policyv1.Compliant, now.Add(5*time.Minute)))
+	assert.Equal(t, policyv1.NonCompliant, policy.Status.ComplianceState)
+	assert.Nil(t, policy.Status.PendingNoncompliance)
+
+	// A noncompliance resolved during the grace period isn't reported
+	policy.Status.ComplianceState = policyv1.NonCompliant
+	assert.True(t, applyNoncomplianceGracePeriod(policy, policyv1.Compliant, now))
+	policy.Status.ComplianceState = policyv1.Compliant
+	assert.True(t, applyNoncomplianceGracePeriod(policy, policyv1.Compliant, now.Add(time.Minute)))
+	assert.Nil(t, policy.Status.PendingNoncompliance)
+
+	// The grace period doesn't apply to a policy that wasn't compliant
+	policy.Status.ComplianceState = policyv1.NonCompliant
+	assert.False(t, applyNoncomplianceGracePeriod(policy, policyv1.UnknownCompliancy, now))
+	assert.Equal(t, policyv1.NonCompliant, policy.Status.ComplianceState)
+
+	policy.Spec.NoncomplianceGracePeriod = ""
+	assert.False(t, applyNoncomplianceGracePeriod(policy, policyv1.Compliant, now))
+	assert.Equal(t, policyv1.NonCompliant, policy.Status.ComplianceState)
+}
+
+func TestUsesPruning(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1.ConfigurationPolicy{
+		Spec: &policyv1.ConfigurationPolicySpec{
+			PruneObjectBehavior: "None",
+			ObjectTemplates:     []*policyv1.ObjectTemplate{{ComplianceType: "musthave"}},
+		},
+	}
+	assert.False(t, usesPruning(policy))
+
+	policy.Spec.ObjectTemplates[0].PruneObjectBehavior = "DeleteIfCreated"
+	assert.True(t, usesPruning(policy))
+
+	// The pruneObjectBehavior of object templates from object-templates-raw is recorded on the related objects
+	policy.Spec.ObjectTemplates = nil
+	policy.Status.RelatedObjects = []policyv1.RelatedObject{
+		{Properties: &policyv1.ObjectProperties{PruneObjectBehavior: "DeleteAll"}},
+	}
+	assert.True(t, usesPruning(policy))
+
+	assert.Equal(
+		t,
+		policyv1.PruneObjectBehavior("DeleteAll"),
+		relatedPruneBehavior(policy.Status.RelatedObjects[0], policy.Spec.PruneObjectBehavior),
+	)
+	assert.Equal(
+		t,
+		policyv1.PruneObjectBehavior("DeleteIfCreated"),
+		relatedPruneBehavior(policyv1.RelatedObject{}, "DeleteIfCreated"),
+	)
+}
+
+func TestNoncomplianceGracePending(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &policyv1.ConfigurationPolicy{
+		Spec:   &policyv1.ConfigurationPolicySpec{NoncomplianceGracePeriod: "5m"},
+		Status: policyv1.ConfigurationPolicyStatus{ComplianceState: policyv1.Compliant},
+	}
+
+	assert.True(t, noncomplianceGracePending(policy, now))
+
+	policy.Status.PendingNoncompliance = &policyv1.PendingNoncompliance{
+		Since: "2024-01-01T00:00:00Z", EffectiveAt: "2024-01-01T00:05:00Z",
+	}
+
+	assert.True(t, noncomplianceGracePending(policy, now.Add(4*time.Minute)))
+	assert.False(t, noncomplianceGracePending(policy, now.Add(5*time.Minute)))
+
+	policy.Status.PendingNoncompliance = nil
+	policy.Status.ComplianceState = policyv1.NonCompliant
+
+	assert.False(t, noncomplianceGracePending(policy, now))
+
+	policy.Status.ComplianceState = policyv1.Compliant
+	policy.Spec.NoncomplianceGracePeriod = ""
+
+	assert.False(t, noncomplianceGracePending(policy, now))
+}
+
+func TestAddForUpdateNoncomplianceGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	s := runtime.NewScheme()
+	assert.NoError(t, policyv1.AddToScheme(s))
+
+	compliantDetails := []policyv1.TemplateStatus{{
+		ComplianceState: policyv1.Compliant,
+		Conditions:      []policyv1.Condition{{Type: "notification", Message: "configmaps [cm] found as specified"}},
+	}}
+
+	reported := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+		Spec:       &policyv1.ConfigurationPolicySpec{NoncomplianceGracePeriod: "5m"},
+		Status: policyv1.ConfigurationPolicyStatus{
+			ComplianceState:   policyv1.Compliant,
+			CompliancyDetails: compliantDetails,
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(reported.DeepCopy()).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &ConfigurationPolicyReconciler{Client: cl, Recorder: recorder}
+
+	evaluated := reported.DeepCopy()
+	evaluated.Status.CompliancyDetails = []policyv1.TemplateStatus{{
+		ComplianceState: policyv1.NonCompliant,
+		Conditions:      []policyv1.Condition{{Type: "violation", Message: "configmaps [cm] not found"}},
+	}}
+
+	r.addForUpdate(evaluated, false)
+
+	// The noncompliance is pending, so neither the compliance state nor the details are reported
+	stored := &policyv1.ConfigurationPolicy{}
+	assert.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: "managed", Name: "policy"}, stored))
+	assert.Equal(t, policyv1.Compliant, stored.Status.ComplianceState)
+	assert.NotNil(t, stored.Status.PendingNoncompliance)
+	assert.Equal(t, compliantDetails, stored.Status.CompliancyDetails)
+	assert.Empty(t, recorder.Events)
+
+	// The evaluated policy keeps its details for the rest of the evaluation
+	assert.Equal(t, policyv1.NonCompliant, evaluated.Status.CompliancyDetails[0].ComplianceState)
+}
+
+func TestNamespaceRestricted(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{DeniedNamespaces: []string{"kube-*", "storage"}}
+
+	assert.True(t, r.namespaceRestricted("kube-system"))
+	assert.True(t, r.namespaceRestricted("storage"))
+	assert.False(t, r.namespaceRestricted("storage-2"))
+	assert.False(t, r.namespaceRestricted("default"))
+	assert.False(t, r.namespaceRestricted(""))
+
+	r.AllowedNamespaces = []string{"team-*", "kube-public"}
+
+	assert.True(t, r.namespaceRestricted("default"))
+	assert.False(t, r.namespaceRestricted("team-a"))
+	// The denied namespaces take precedence
+	assert.True(t, r.namespaceRestricted("kube-public"))
+	assert.False(t, r.namespaceRestricted(""))
+}
+
+func TestEnforcementRestriction(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{
+		DeniedNamespaces: []string{"kube-system"},
+		DeniedKinds:      []string{"Node", "*.admissionregistration.k8s.io"},
+	}
+
+	reason, _ := r.enforcementRestriction("kube-system", schema.GroupKind{Kind: "ConfigMap"})
+	assert.Equal(t, reasonNamespaceRestricted, reason)
+
+	reason, restriction := r.enforcementRestriction("", schema.GroupKind{Kind: "Node"})
+	assert.Equal(t, reasonKindRestricted, reason)
+	assert.Equal(t, "the kind is restricted by the controller configuration (--denied-kinds Node)", restriction)
+
+	reason, _ = r.enforcementRestriction(
+		"", schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"},
+	)
+	assert.Equal(t, reasonKindRestricted, reason)
+
+	reason, restriction = r.enforcementRestriction("default", schema.GroupKind{Kind: "ConfigMap"})
+	assert.Equal(t, "", reason)
+	assert.Equal(t, "", restriction)
+}
+
 type fakeSR struct{}
 
 func (r *fakeSR) Get(_ string, _ policyv1.Target) ([]string, error) {
@@ -1332,8 +1828,1267 @@ func TestShouldHandleSingleKeyFalse(t *testing.T) {
 		unstruct.Object = test.input
 		unstructObj.Object = test.fromAPI
 		key := test.expectResult.key
-		_, update, _, skip = handleSingleKey(key, unstruct, &unstructObj, "musthave", true)
+		_, update, _, skip = handleSingleKey(key, unstruct, &unstructObj, "musthave", nil, true)
 		assert.Equal(t, update, test.expectResult.expect)
 		assert.False(t, skip)
 	}
 }
+
+func TestHandleSingleKeyQuantities(t *testing.T) {
+	t.Parallel()
+
+	desiredContainer := map[string]interface{}{
+		"name": "nginx",
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{"memory": "1024Mi", "cpu": 0.5},
+		},
+	}
+	desiredObj := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"containers": []interface{}{desiredContainer}},
+	}}
+	existingObj := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":  "nginx",
+					"image": "nginx:1.25",
+					"resources": map[string]interface{}{
+						"requests": map[string]interface{}{"memory": "1Gi", "cpu": "500m"},
+					},
+				},
+			},
+		},
+	}}
+
+	for _, complianceType := range []string{"musthave", "mustonlyhave"} {
+		errMsg, update, merged, _ := handleSingleKey("spec", desiredObj, &existingObj, complianceType, nil, true)
+		assert.Empty(t, errMsg)
+
+		// The merged value uses the quantities as the API server returns them
+		mergedContainers, _, _ := unstructured.NestedSlice(merged.(map[string]interface{}), "containers")
+		assert.Len(t, mergedContainers, 1)
+		assert.Equal(
+			t,
+			map[string]interface{}{"requests": map[string]interface{}{"memory": "1Gi", "cpu": "500m"}},
+			mergedContainers[0].(map[string]interface{})["resources"],
+		)
+
+		// The image isn't in the template, so only mustonlyhave requires an update
+		assert.Equal(t, complianceType == "mustonlyhave", update)
+	}
+
+	// The template isn't modified
+	memory, _, _ := unstructured.NestedString(desiredContainer, "resources", "requests", "memory")
+	assert.Equal(t, "1024Mi", memory)
+
+	desiredContainer["resources"] = map[string]interface{}{"requests": map[string]interface{}{"memory": "1G"}}
+
+	_, update, _, _ := handleSingleKey("spec", desiredObj, &existingObj, "musthave", nil, true)
+	assert.True(t, update)
+}
+
+func TestHandleSingleKeyNullAndEmpty(t *testing.T) {
+	t.Parallel()
+
+	existingObj := unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":              "pod",
+			"creationTimestamp": "2024-01-01T00:00:00Z",
+			"labels":            map[string]interface{}{"app": "test"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "nginx", "image": "nginx:1.25"},
+			},
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "cache"},
+			},
+		},
+	}}
+
+	tests := map[string]struct {
+		key            string
+		desired        map[string]interface{}
+		complianceType string
+		expectedUpdate bool
+	}{
+		"null creationTimestamp": {
+			key: "metadata",
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "pod", "creationTimestamp": nil},
+			},
+			complianceType: "musthave",
+		},
+		"empty annotations": {
+			key: "metadata",
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "pod", "annotations": map[string]interface{}{}},
+			},
+			complianceType: "musthave",
+		},
+		"empty emptyDir": {
+			key: "spec",
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"volumes": []interface{}{
+						map[string]interface{}{"name": "cache", "emptyDir": map[string]interface{}{}},
+					},
+				},
+			},
+			complianceType: "musthave",
+		},
+		"null and nested empty maps": {
+			key: "spec",
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"securityContext": map[string]interface{}{"seLinuxOptions": map[string]interface{}{}},
+					"nodeSelector":    nil,
+				},
+			},
+			complianceType: "musthave",
+		},
+		"empty emptyDir with mustonlyhave": {
+			key: "spec",
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "nginx", "image": "nginx:1.25"},
+					},
+					"volumes": []interface{}{
+						map[string]interface{}{"name": "cache", "emptyDir": map[string]interface{}{}},
+					},
+				},
+			},
+			complianceType: "mustonlyhave",
+			expectedUpdate: true,
+		},
+		"missing list item with an empty map": {
+			key: "spec",
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"volumes": []interface{}{
+						map[string]interface{}{"name": "cache", "emptyDir": map[string]interface{}{}},
+						map[string]interface{}{"name": "data", "emptyDir": map[string]interface{}{}},
+					},
+				},
+			},
+			complianceType: "musthave",
+			expectedUpdate: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			existing := existingObj.DeepCopy()
+			desiredObj := unstructured.Unstructured{Object: test.desired}
+
+			errMsg, update, _, _ := handleSingleKey(test.key, desiredObj, existing, test.complianceType, nil, false)
+			assert.Empty(t, errMsg)
+			assert.Equal(t, test.expectedUpdate, update)
+		})
+	}
+}
+
+func TestDryRunUnchanged(t *testing.T) {
+	t.Parallel()
+
+	existingObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "deployment"},
+		"spec":     map[string]interface{}{"replicas": int64(1)},
+	}}
+	dryRunObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "deployment", "creationTimestamp": nil},
+		"spec":     map[string]interface{}{"replicas": int64(1), "template": map[string]interface{}{}},
+	}}
+
+	assert.True(t, dryRunUnchanged(dryRunObj, existingObj, "musthave"))
+	assert.False(t, dryRunUnchanged(dryRunObj, existingObj, "mustonlyhave"))
+
+	dryRunObj.Object["spec"].(map[string]interface{})["replicas"] = int64(2)
+
+	assert.False(t, dryRunUnchanged(dryRunObj, existingObj, "musthave"))
+}
+
+func TestSplitSelectorEntries(t *testing.T) {
+	t.Parallel()
+
+	entries := []policyv1.NonEmptyString{"default", "app-1, app-2,", " kube-* "}
+	expected := []policyv1.NonEmptyString{"default", "app-1", "app-2", "kube-*"}
+
+	assert.Equal(t, expected, splitSelectorEntries(entries))
+	assert.Nil(t, splitSelectorEntries(nil))
+}
+
+func TestResolveSelectorTemplatesNoTemplates(t *testing.T) {
+	t.Parallel()
+
+	selector := policyv1.Target{
+		Include: []policyv1.NonEmptyString{"app-1,app-2"},
+		Exclude: []policyv1.NonEmptyString{"kube-*"},
+	}
+
+	assert.False(t, selectorHasTemplate(selector))
+
+	// The resolver is not used when there are no templates, so the selector should be returned unchanged
+	resolved, err := resolveSelectorTemplates(nil, selector, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, selector, resolved)
+
+	selector.Include = []policyv1.NonEmptyString{`{{ fromConfigMap "default" "namespaces" "include" }}`}
+
+	assert.True(t, selectorHasTemplate(selector))
+}
+
+func TestObjectTemplateDue(t *testing.T) {
+	t.Parallel()
+
+	justNow := time.Now().UTC().Format(time.RFC3339)
+	hourAgo := time.Now().UTC().Add(-61 * time.Minute).Format(time.RFC3339)
+
+	policy := policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed", Generation: 1},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			EvaluationInterval: policyv1.EvaluationInterval{Compliant: "10s", NonCompliant: "10s"},
+			ObjectTemplates: []*policyv1.ObjectTemplate{
+				{ComplianceType: "musthave"},
+				{
+					ComplianceType:     "musthave",
+					EvaluationInterval: policyv1.EvaluationInterval{Compliant: "1h"},
+				},
+				{
+					ComplianceType:     "musthave",
+					EvaluationInterval: policyv1.EvaluationInterval{Compliant: "never"},
+				},
+			},
+		},
+		Status: policyv1.ConfigurationPolicyStatus{
+			LastEvaluated:           justNow,
+			LastEvaluatedGeneration: 1,
+		},
+	}
+
+	assert.True(t, usesTemplateEvaluationIntervals(&policy))
+
+	tests := []struct {
+		testDescription string
+		index           int
+		lastEvaluated   string
+		complianceState policyv1.ComplianceState
+		expected        bool
+	}{
+		{"Policy interval not reached", 0, justNow, policyv1.Compliant, false},
+		{"Policy interval reached", 0, hourAgo, policyv1.Compliant, true},
+		{"Template interval not reached", 1, time.Now().UTC().Add(-time.Minute).Format(time.RFC3339),
+			policyv1.Compliant, false},
+		{"Template interval reached", 1, hourAgo, policyv1.Compliant, true},
+		{"Template falls back to the policy noncompliant interval", 1, hourAgo, policyv1.NonCompliant, true},
+		{"Template interval set to never", 2, hourAgo, policyv1.Compliant, false},
+		{"Unknown compliance", 2, justNow, policyv1.UnknownCompliancy, true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.testDescription, func(t *testing.T) {
+			t.Parallel()
+
+			policy := policy.DeepCopy()
+
+			for i := 0; i <= test.index; i++ {
+				policy.Status.CompliancyDetails = append(
+					policy.Status.CompliancyDetails, policyv1.TemplateStatus{ComplianceState: policyv1.Compliant},
+				)
+			}
+
+			policy.Status.CompliancyDetails[test.index].ComplianceState = test.complianceState
+			policy.Status.CompliancyDetails[test.index].LastEvaluated = test.lastEvaluated
+
+			objectT := policy.Spec.ObjectTemplates[test.index]
+
+			if actual := objectTemplateDue(policy, objectT, test.index); actual != test.expected {
+				t.Fatalf("expected %v but got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDueObjectTemplateList(t *testing.T) {
+	t.Parallel()
+
+	justNow := time.Now().UTC().Format(time.RFC3339)
+	hourAgo := time.Now().UTC().Add(-61 * time.Minute).Format(time.RFC3339)
+
+	configMaps := `{"apiVersion":"v1","kind":"List","items":[` +
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm1"}},` +
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm2"}}]}`
+
+	// The List is expanded into the first two object templates, so the third object template's status is at index 2
+	policy := policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed", Generation: 1},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			ObjectTemplates: []*policyv1.ObjectTemplate{
+				{
+					ComplianceType:     "musthave",
+					ObjectDefinition:   runtime.RawExtension{Raw: []byte(configMaps)},
+					EvaluationInterval: policyv1.EvaluationInterval{Compliant: "never"},
+				},
+				{
+					ComplianceType:     "musthave",
+					EvaluationInterval: policyv1.EvaluationInterval{Compliant: "1h"},
+				},
+			},
+		},
+		Status: policyv1.ConfigurationPolicyStatus{
+			LastEvaluated:           justNow,
+			LastEvaluatedGeneration: 1,
+			CompliancyDetails: []policyv1.TemplateStatus{
+				{ComplianceState: policyv1.Compliant, LastEvaluated: hourAgo},
+				{ComplianceState: policyv1.Compliant, LastEvaluated: hourAgo},
+				{ComplianceState: policyv1.Compliant, LastEvaluated: justNow},
+			},
+		},
+	}
+
+	assert.Equal(t, -1, dueObjectTemplate(&policy))
+
+	policy.Status.CompliancyDetails[2].LastEvaluated = hourAgo
+
+	assert.Equal(t, 2, dueObjectTemplate(&policy))
+}
+
+func TestRelatedObjectsForTemplate(t *testing.T) {
+	t.Parallel()
+
+	newRelated := func(kind, name, namespace string) policyv1.RelatedObject {
+		return policyv1.RelatedObject{
+			Object: policyv1.ObjectResource{
+				Kind:       kind,
+				APIVersion: "v1",
+				Metadata:   policyv1.ObjectMetadata{Name: name, Namespace: namespace},
+			},
+		}
+	}
+
+	oldRelated := []policyv1.RelatedObject{
+		newRelated("ConfigMap", "cm1", "ns1"),
+		newRelated("ConfigMap", "cm1", "ns2"),
+		newRelated("ConfigMap", "cm2", "ns1"),
+		newRelated("Namespace", "ns1", ""),
+	}
+
+	details := objectTemplateDetails{kind: "ConfigMap", name: "cm1", isNamespaced: true}
+	related := relatedObjectsForTemplate(oldRelated, details, []string{"ns1"})
+	assert.Equal(t, []policyv1.RelatedObject{oldRelated[0]}, related)
+
+	details = objectTemplateDetails{kind: "ConfigMap", isNamespaced: true}
+	related = relatedObjectsForTemplate(oldRelated, details, []string{"ns1"})
+	assert.Equal(t, []policyv1.RelatedObject{oldRelated[0], oldRelated[2]}, related)
+
+	details = objectTemplateDetails{kind: "Namespace", name: "ns1"}
+	related = relatedObjectsForTemplate(oldRelated, details, []string{""})
+	assert.Equal(t, []policyv1.RelatedObject{oldRelated[3]}, related)
+}
+
+func TestValidateObjectCounts(t *testing.T) {
+	t.Parallel()
+
+	one := int32(1)
+	three := int32(3)
+
+	tests := map[string]struct {
+		remediation    policyv1.RemediationAction
+		complianceType policyv1.ComplianceType
+		name           string
+		minimum        *int32
+		maximum        *int32
+		expectedErr    string
+	}{
+		"no counts": {
+			remediation: policyv1.Enforce, complianceType: policyv1.MustHave, name: "foo",
+		},
+		"valid counts": {
+			remediation: policyv1.Inform, complianceType: policyv1.MustHave, minimum: &one, maximum: &three,
+		},
+		"named object": {
+			remediation: policyv1.Inform, complianceType: policyv1.MustHave, name: "foo", minimum: &one,
+			expectedErr: "minimumObjects and maximumObjects can only be set on object templates without a name",
+		},
+		"mustnothave": {
+			remediation: policyv1.Inform, complianceType: policyv1.MustNotHave, maximum: &one,
+			expectedErr: "minimumObjects and maximumObjects can't be set with the mustnothave compliance type",
+		},
+		"enforce": {
+			remediation: policyv1.Enforce, complianceType: policyv1.MustHave, minimum: &one,
+			expectedErr: "minimumObjects and maximumObjects can only be set in inform policies",
+		},
+		"minimum greater than maximum": {
+			remediation: policyv1.Inform, complianceType: policyv1.MustHave, minimum: &three, maximum: &one,
+			expectedErr: "minimumObjects can't be greater than maximumObjects",
+		},
+	}
+
+	for testName, test := range tests {
+		test := test
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			policy := &policyv1.ConfigurationPolicy{
+				Spec: &policyv1.ConfigurationPolicySpec{RemediationAction: test.remediation},
+			}
+			objectT := &policyv1.ObjectTemplate{
+				ComplianceType: test.complianceType,
+				MinimumObjects: test.minimum,
+				MaximumObjects: test.maximum,
+			}
+
+			err := validateObjectCounts(policy, objectT, objectTemplateDetails{name: test.name})
+			if test.expectedErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestHandleObjectCount(t *testing.T) {
+	t.Parallel()
+
+	three := int32(3)
+	objectT := &policyv1.ObjectTemplate{MinimumObjects: &three}
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}
+	objDetails := objectTemplateDetails{kind: "ReplicaSet", isNamespaced: true}
+
+	related, result := handleObjectCount(objectT, []string{"rs1", "rs2"}, gvr, objDetails, "default")
+
+	assert.Len(t, related, 2)
+	assert.Equal(t, string(policyv1.NonCompliant), related[0].Compliant)
+	assert.Equal(t, []objectTmplEvalEvent{{
+		false, reasonObjectCountNoMatch, "2 replicasets found in namespace default, expected at least 3",
+	}}, result.events)
+
+	related, result = handleObjectCount(objectT, []string{"rs1", "rs2", "rs3"}, gvr, objDetails, "default")
+
+	assert.Len(t, related, 3)
+	assert.Equal(t, string(policyv1.Compliant), related[0].Compliant)
+	assert.True(t, result.events[0].compliant)
+
+	zero := int32(0)
+	objectT = &policyv1.ObjectTemplate{MinimumObjects: &zero, MaximumObjects: &zero}
+
+	related, result = handleObjectCount(objectT, nil, gvr, objDetails, "default")
+
+	assert.Len(t, related, 1)
+	assert.Equal(t, "-", related[0].Object.Metadata.Name)
+	assert.Equal(t, []objectTmplEvalEvent{{
+		true, reasonObjectCount, "0 replicasets found in namespace default, expected at least 0 and at most 0",
+	}}, result.events)
+}
+
+func TestTruncatedNameList(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "[a, b, c]", truncatedNameList([]string{"c", "a", "b"}, 3))
+	assert.Equal(t, "[a, b] and 2 more", truncatedNameList([]string{"d", "c", "a", "b"}, 2))
+}
+
+func TestEnforceDeleteTerminatingObject(t *testing.T) {
+	t.Parallel()
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":       "finalized",
+			"namespace":  "default",
+			"finalizers": []interface{}{"example.com/cleanup"},
+		},
+	}}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap.DeepCopy())
+
+	// The fake client doesn't honor the finalizers, so only mark the object as terminating like the API server would
+	client.PrependReactor("delete", "configmaps", func(_ clienttesting.Action) (bool, runtime.Object, error) {
+		terminating := configMap.DeepCopy()
+		now := metav1.Now()
+		terminating.SetDeletionTimestamp(&now)
+
+		return true, nil, client.Tracker().Update(gvr, terminating, "default")
+	})
+
+	r := &ConfigurationPolicyReconciler{TargetK8sDynamicClient: client}
+	obj := singleObject{
+		policy: &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+			Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: policyv1.Enforce},
+		},
+		gvr:         gvr,
+		existingObj: configMap.DeepCopy(),
+		name:        "finalized",
+		namespace:   "default",
+		namespaced:  true,
+	}
+
+	// The object held by a finalizer isn't reported as deleted with the default propagation policy
+	objectT := &policyv1.ObjectTemplate{ComplianceType: policyv1.MustNotHave}
+
+	completed, reason, _, _, err := r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.NoError(t, err)
+	assert.False(t, completed)
+	assert.Equal(t, reasonWantNotFoundTerm, reason)
+
+	background := metav1.DeletePropagationBackground
+	objectT.DeleteOptions.PropagationPolicy = &background
+
+	completed, reason, _, _, err = r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.NoError(t, err)
+	assert.False(t, completed)
+	assert.Equal(t, reasonWantNotFoundTerm, reason)
+}
+
+func TestGetUnmetDependencies(t *testing.T) {
+	t.Parallel()
+
+	s := runtime.NewScheme()
+	assert.NoError(t, policyv1.AddToScheme(s))
+
+	newPolicy := func(name string, compliance policyv1.ComplianceState) *policyv1.ConfigurationPolicy {
+		return &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "managed"},
+			Status:     policyv1.ConfigurationPolicyStatus{ComplianceState: compliance},
+		}
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		newPolicy("compliant", policyv1.Compliant),
+		newPolicy("noncompliant", policyv1.NonCompliant),
+		newPolicy("unknown", ""),
+	).Build()
+	r := &ConfigurationPolicyReconciler{Client: cl}
+
+	policy := newPolicy("policy", "")
+	policy.Spec = &policyv1.ConfigurationPolicySpec{
+		Dependencies: []policyv1.PolicyDependency{
+			{Name: "compliant"},
+			{Name: "noncompliant", Compliance: policyv1.NonCompliant},
+			{Name: "noncompliant"},
+			{Name: "unknown", Kind: "ConfigurationPolicy"},
+			{Name: "missing", Namespace: "other"},
+		},
+	}
+
+	expected := []string{
+		"ConfigurationPolicy managed/noncompliant is NonCompliant, expected Compliant",
+		"ConfigurationPolicy managed/unknown has no compliance state, expected Compliant",
+		"ConfigurationPolicy other/missing was not found",
+	}
+
+	assert.Equal(t, expected, r.getUnmetDependencies(policy))
+
+	policy.Spec.Dependencies = policy.Spec.Dependencies[:2]
+
+	assert.Empty(t, r.getUnmetDependencies(policy))
+}
+
+func TestEnforcementSchedule(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1.ConfigurationPolicy{
+		Spec: &policyv1.ConfigurationPolicySpec{
+			RemediationAction: policyv1.Enforce,
+			EnforcementSchedule: &policyv1.EnforcementSchedule{
+				Windows: []policyv1.EnforcementWindow{{Start: "02:00", Duration: "1h"}},
+			},
+		},
+	}
+
+	beforeWindow := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	inWindow := time.Date(2026, 10, 16, 2, 30, 0, 0, time.UTC)
+	afterWindow := time.Date(2026, 10, 16, 4, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, policyv1.Inform, effectiveRemediationAction(policy, beforeWindow))
+	assert.Equal(t, policyv1.Enforce, effectiveRemediationAction(policy, inWindow))
+	assert.Equal(t, policyv1.Inform, effectiveRemediationAction(policy, afterWindow))
+
+	assert.False(t, enforcementWindowChanged(policy, beforeWindow, beforeWindow.Add(30*time.Minute)))
+	assert.True(t, enforcementWindowChanged(policy, beforeWindow, inWindow))
+	assert.True(t, enforcementWindowChanged(policy, inWindow, afterWindow))
+
+	policy.Spec.RemediationAction = policyv1.Inform
+
+	assert.Equal(t, policyv1.Inform, effectiveRemediationAction(policy, inWindow))
+	assert.False(t, enforcementWindowChanged(policy, beforeWindow, inWindow))
+}
+
+func TestEnforceProtectedObject(t *testing.T) {
+	t.Parallel()
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":        "protected",
+			"namespace":   "default",
+			"annotations": map[string]interface{}{protectedAnnotation: "true"},
+		},
+	}}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	recorder := record.NewFakeRecorder(10)
+	r := &ConfigurationPolicyReconciler{
+		TargetK8sDynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap.DeepCopy()),
+		Recorder:               recorder,
+	}
+	obj := singleObject{
+		policy: &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+			Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: policyv1.Enforce},
+		},
+		gvr:         gvr,
+		existingObj: configMap,
+		name:        "protected",
+		namespace:   "default",
+		namespaced:  true,
+	}
+	objectT := &policyv1.ObjectTemplate{ComplianceType: policyv1.MustNotHave}
+
+	completed, reason, msg, _, err := r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.NoError(t, err)
+	assert.False(t, completed)
+	assert.Equal(t, reasonDeletionProtected, reason)
+	assert.Equal(
+		t,
+		"configmaps [protected] in namespace default exists, and isn't deleted since it has the "+
+			protectedAnnotation+" annotation",
+		msg,
+	)
+	assert.Len(t, recorder.Events, 1)
+
+	_, err = r.TargetK8sDynamicClient.Resource(gvr).Namespace("default").Get(
+		context.TODO(), "protected", metav1.GetOptions{},
+	)
+	assert.NoError(t, err)
+
+	// The annotation isn't honored when the controller ignores it
+	r.IgnoreProtectedAnnotation = true
+
+	completed, reason, _, _, err = r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.NoError(t, err)
+	assert.True(t, completed)
+	assert.Equal(t, reasonDeleteSuccess, reason)
+}
+
+func TestEnforceDeleteReplacedObject(t *testing.T) {
+	t.Parallel()
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "replaced",
+			"namespace": "default",
+			"uid":       "new-uid",
+		},
+	}}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap.DeepCopy())
+
+	// The fake client doesn't check the preconditions, so reject the deletion like the API server would
+	client.PrependReactor("delete", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(clienttesting.DeleteAction)
+
+		preconditions := deleteAction.GetDeleteOptions().Preconditions
+		if preconditions == nil || preconditions.UID == nil || *preconditions.UID == configMap.GetUID() {
+			return false, nil, nil
+		}
+
+		msg := fmt.Sprintf(
+			"Precondition failed: UID in precondition: %v, UID in object meta: %v",
+			*preconditions.UID, configMap.GetUID(),
+		)
+
+		return true, nil, k8serrors.NewConflict(gvr.GroupResource(), deleteAction.GetName(), errors.New(msg))
+	})
+
+	r := &ConfigurationPolicyReconciler{TargetK8sDynamicClient: client}
+	existingObj := configMap.DeepCopy()
+	existingObj.SetUID("old-uid")
+	obj := singleObject{
+		policy: &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+			Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: policyv1.Enforce},
+		},
+		gvr:         gvr,
+		existingObj: existingObj,
+		name:        "replaced",
+		namespace:   "default",
+		namespaced:  true,
+	}
+	objectT := &policyv1.ObjectTemplate{ComplianceType: policyv1.MustNotHave}
+
+	completed, reason, msg, _, err := r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.Error(t, err)
+	assert.False(t, completed)
+	assert.Equal(t, "K8s deletion error", reason)
+	assert.Equal(
+		t,
+		"configmaps [replaced] in namespace default exists, and isn't deleted since it was replaced after it was "+
+			"retrieved",
+		msg,
+	)
+
+	_, err = client.Resource(gvr).Namespace("default").Get(context.TODO(), "replaced", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// The object is deleted when it's still the one that was retrieved
+	obj.existingObj = configMap.DeepCopy()
+
+	completed, reason, _, _, err = r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.NoError(t, err)
+	assert.True(t, completed)
+	assert.Equal(t, reasonDeleteSuccess, reason)
+}
+
+func TestCleanUpReplacedChildObject(t *testing.T) {
+	t.Parallel()
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "replaced",
+			"namespace": "default",
+			"uid":       "new-uid",
+		},
+	}}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap.DeepCopy())
+
+	// The object is replaced after it's retrieved, so the retrieved object is still the one the policy created
+	client.PrependReactor("get", "configmaps", func(_ clienttesting.Action) (bool, runtime.Object, error) {
+		retrieved := configMap.DeepCopy()
+		retrieved.SetUID("old-uid")
+
+		return true, retrieved, nil
+	})
+
+	// The fake client doesn't check the preconditions, so reject the deletion like the API server would
+	client.PrependReactor("delete", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(clienttesting.DeleteAction)
+		preconditions := deleteAction.GetDeleteOptions().Preconditions
+
+		msg := fmt.Sprintf(
+			"Precondition failed: UID in precondition: %v, UID in object meta: %v",
+			*preconditions.UID, configMap.GetUID(),
+		)
+
+		return true, nil, k8serrors.NewConflict(gvr.GroupResource(), deleteAction.GetName(), errors.New(msg))
+	})
+
+	recorder := record.NewFakeRecorder(10)
+	r := &ConfigurationPolicyReconciler{
+		TargetK8sDynamicClient: client,
+		Recorder:               recorder,
+		apiGroups: []*restmapper.APIGroupResources{{
+			Group: metav1.APIGroup{
+				Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "v1", Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "v1", Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1": {{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"}},
+			},
+		}},
+	}
+	createdByPolicy := true
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			RemediationAction:   policyv1.Enforce,
+			PruneObjectBehavior: "DeleteIfCreated",
+		},
+		Status: policyv1.ConfigurationPolicyStatus{
+			RelatedObjects: []policyv1.RelatedObject{{
+				Object: policyv1.ObjectResource{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+					Metadata:   policyv1.ObjectMetadata{Name: "replaced", Namespace: "default"},
+				},
+				Properties: &policyv1.ObjectProperties{CreatedByPolicy: &createdByPolicy, UID: "old-uid"},
+			}},
+		},
+	}
+
+	failures, skipped := r.cleanUpChildObjects(*policy, nil)
+	assert.Empty(t, failures)
+
+	msg := `/v1, Kind=ConfigMap "replaced" in namespace default isn't deleted since it's no longer the object the ` +
+		"policy created"
+	assert.Equal(t, []string{msg}, skipped)
+	assert.Equal(t, "Warning "+eventReasonPruneSkipped+" "+msg, <-recorder.Events)
+
+	setPruneSkippedCondition(policy, skipped)
+
+	condition := meta.FindStatusCondition(policy.Status.Conditions, pruneSkippedConditionType)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, eventReasonPruneSkipped, condition.Reason)
+		assert.Equal(t, msg, condition.Message)
+	}
+
+	// The condition is removed once no related object is skipped
+	setPruneSkippedCondition(policy, nil)
+	assert.Nil(t, meta.FindStatusCondition(policy.Status.Conditions, pruneSkippedConditionType))
+}
+
+func TestCheckAndUpdateResourceDryRunForbidden(t *testing.T) {
+	t.Parallel()
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "cm", "namespace": "default"},
+		"data":       map[string]interface{}{"key": "old"},
+	}}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap.DeepCopy())
+	updates := 0
+
+	// The API server forbids some updates, such as of most Pod spec fields, even in a dry run
+	client.PrependReactor("update", "configmaps", func(_ clienttesting.Action) (bool, runtime.Object, error) {
+		updates++
+
+		return true, nil, k8serrors.NewForbidden(gvr.GroupResource(), "cm", errors.New("field is immutable"))
+	})
+
+	r := &ConfigurationPolicyReconciler{
+		TargetK8sDynamicClient: client,
+		DryRunSupported:        true,
+		MaxEventDiffBytes:      1024,
+		discoveryInfo:          discoveryInfo{serverVersion: "v1.28.0"},
+	}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed", UID: "policy-uid"},
+		Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: policyv1.Inform},
+	}
+	desiredObj := configMap.DeepCopy()
+	desiredObj.Object["data"] = map[string]interface{}{"key": "new"}
+	obj := singleObject{
+		policy:      policy,
+		gvr:         gvr,
+		existingObj: configMap.DeepCopy(),
+		name:        "cm",
+		namespace:   "default",
+		namespaced:  true,
+		shouldExist: true,
+		desiredObj:  *desiredObj,
+	}
+	objectT := &policyv1.ObjectTemplate{ComplianceType: policyv1.MustHave, RecordDiff: policyv1.RecordDiffLog}
+
+	throwSpecViolation, message, updateNeeded, updateSucceeded, _ := r.checkAndUpdateResource(
+		obj, objectT, policyv1.Inform,
+	)
+	assert.True(t, throwSpecViolation)
+	assert.Equal(t, "", message)
+	assert.False(t, updateNeeded)
+	assert.False(t, updateSucceeded)
+	assert.Equal(t, 1, updates)
+
+	// The dry run doesn't return the updated object, so the diff comes from the local comparison
+	policy.Status.ComplianceState = policyv1.NonCompliant
+	diffs := r.eventDiffsMessage(policy)
+
+	assert.Contains(t, diffs, "diff of ConfigMap default/cm")
+	assert.Contains(t, diffs, "-  key: old\n+  key: new")
+}
+
+func TestPurgeRestrictedNamespace(t *testing.T) {
+	t.Parallel()
+
+	newConfigMap := func(name string, namespace string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		}}
+	}
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	mapping := &meta.RESTMapping{
+		Resource:         gvr,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Scope:            meta.RESTScopeNamespace,
+	}
+	r := &ConfigurationPolicyReconciler{
+		TargetK8sDynamicClient: dynamicfake.NewSimpleDynamicClient(
+			runtime.NewScheme(), newConfigMap("cm-1", "kube-system"), newConfigMap("cm-1", "default"),
+		),
+		DeniedNamespaces: []string{"kube-*"},
+	}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+		Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: policyv1.Enforce},
+	}
+	objectT := &policyv1.ObjectTemplate{ComplianceType: policyv1.MustNotHave}
+	objDetails := objectTemplateDetails{kind: "ConfigMap", isNamespaced: true}
+
+	_, result := r.handleObjectPurge(
+		objectT, []string{"cm-1"}, "kube-system", objDetails, policy, mapping, policyv1.Enforce,
+	)
+	assert.Len(t, result.events, 1)
+	assert.False(t, result.events[0].compliant)
+	assert.Equal(t, reasonNamespaceRestricted, result.events[0].reason)
+	assert.Equal(
+		t,
+		"1 configmaps not deleted since the namespace is restricted by the controller configuration: [cm-1] "+
+			"in namespace kube-system",
+		result.events[0].message,
+	)
+
+	_, err := r.TargetK8sDynamicClient.Resource(gvr).Namespace("kube-system").Get(
+		context.TODO(), "cm-1", metav1.GetOptions{},
+	)
+	assert.NoError(t, err)
+
+	// The objects in the other namespaces are still deleted
+	_, result = r.handleObjectPurge(
+		objectT, []string{"cm-1"}, "default", objDetails, policy, mapping, policyv1.Enforce,
+	)
+	assert.Len(t, result.events, 1)
+	assert.True(t, result.events[0].compliant)
+
+	_, err = r.TargetK8sDynamicClient.Resource(gvr).Namespace("default").Get(
+		context.TODO(), "cm-1", metav1.GetOptions{},
+	)
+	assert.True(t, k8serrors.IsNotFound(err))
+}
+
+func TestPurgeRestrictedKind(t *testing.T) {
+	t.Parallel()
+
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "secret-1", "namespace": "default"},
+	}}
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	mapping := &meta.RESTMapping{
+		Resource:         gvr,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Secret"},
+		Scope:            meta.RESTScopeNamespace,
+	}
+	r := &ConfigurationPolicyReconciler{
+		TargetK8sDynamicClient: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), secret.DeepCopy()),
+		DeniedKinds:            []string{"Secret"},
+	}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+		Spec:       &policyv1.ConfigurationPolicySpec{RemediationAction: policyv1.Enforce},
+	}
+	objectT := &policyv1.ObjectTemplate{ComplianceType: policyv1.MustNotHave}
+	objDetails := objectTemplateDetails{kind: "Secret", isNamespaced: true}
+
+	_, result := r.handleObjectPurge(
+		objectT, []string{"secret-1"}, "default", objDetails, policy, mapping, policyv1.Enforce,
+	)
+	assert.Len(t, result.events, 1)
+	assert.False(t, result.events[0].compliant)
+	assert.Equal(t, reasonKindRestricted, result.events[0].reason)
+
+	_, err := r.TargetK8sDynamicClient.Resource(gvr).Namespace("default").Get(
+		context.TODO(), "secret-1", metav1.GetOptions{},
+	)
+	assert.NoError(t, err)
+
+	// The kind of the existing object is checked when the desired object doesn't have one
+	obj := singleObject{
+		policy:      policy,
+		gvr:         gvr,
+		existingObj: secret,
+		name:        "secret-1",
+		namespace:   "default",
+		namespaced:  true,
+	}
+
+	completed, reason, msg, _, err := r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.NoError(t, err)
+	assert.False(t, completed)
+	assert.Equal(t, reasonKindRestricted, reason)
+	assert.Equal(
+		t,
+		"secrets [secret-1] in namespace default isn't modified since the kind is restricted by the controller "+
+			"configuration (--denied-kinds Secret)",
+		msg,
+	)
+
+	_, err = r.TargetK8sDynamicClient.Resource(gvr).Namespace("default").Get(
+		context.TODO(), "secret-1", metav1.GetOptions{},
+	)
+	assert.NoError(t, err)
+}
+
+func TestEvaluateObjectTemplates(t *testing.T) {
+	t.Parallel()
+
+	// The object definitions are missing the apiVersion, so they are evaluated without any API request
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			ObjectTemplates: []*policyv1.ObjectTemplate{
+				{ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"kind":"ConfigMap"}`)}},
+				{ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"kind":"ConfigMap"}`)}},
+				{ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"kind":"Secret"}`)}},
+			},
+		},
+	}
+	templateObjs := []objectTemplateDetails{
+		{kind: "ConfigMap", isNamespaced: true},
+		{kind: "ConfigMap", isNamespaced: true},
+		{kind: "Secret", isNamespaced: true},
+	}
+	r := &ConfigurationPolicyReconciler{TemplateEvaluationConcurrency: 2}
+
+	evaluations := r.evaluateObjectTemplates(
+		policy, templateObjs, []string{"ns1", "ns2"}, func(indx int) bool { return indx != 1 },
+	)
+	assert.Len(t, evaluations, 3)
+
+	// The object template that isn't due isn't evaluated
+	assert.Nil(t, evaluations[1])
+
+	for indx, kind := range map[int]string{0: "ConfigMap", 2: "Secret"} {
+		if assert.NotNil(t, evaluations[indx]) {
+			assert.Len(t, evaluations[indx].nsToResults, 2)
+			assert.Equal(
+				t,
+				fmt.Sprintf(
+					"object template at index [%d] in policy `policy` missing apiVersion, and the kind %s can't be "+
+						"resolved since no API group on the cluster serves it",
+					indx, kind,
+				),
+				evaluations[indx].nsToResults["ns2"].events[0].message,
+			)
+		}
+	}
+}
+
+// fixedSR is a SelectorReconciler that always selects the same namespaces.
+type fixedSR struct {
+	fakeSR
+	namespaces []string
+}
+
+func (r *fixedSR) Get(_ string, _ policyv1.Target) ([]string, error) {
+	return r.namespaces, nil
+}
+
+func TestHandleObjectTemplatesConcurrency(t *testing.T) {
+	t.Parallel()
+
+	// The template resolver is created for each evaluation, but the object templates don't have templates, so the API
+	// server isn't expected to be called
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	namespaces := []string{"ns1", "ns2", "ns3"}
+
+	newObject := func(apiVersion, kind, namespace, name string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		}
+	}
+
+	newObjectTemplate := func(
+		complianceType policyv1.ComplianceType, object map[string]interface{},
+	) *policyv1.ObjectTemplate {
+		raw, err := json.Marshal(object)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &policyv1.ObjectTemplate{
+			ComplianceType:   complianceType,
+			ObjectDefinition: runtime.RawExtension{Raw: raw},
+		}
+	}
+
+	// The object templates span several kinds and namespaces, with a mix of compliant and noncompliant results
+	objectTemplates := []*policyv1.ObjectTemplate{
+		newObjectTemplate(policyv1.MustHave, newObject("v1", "ConfigMap", "", "config")),
+		newObjectTemplate(policyv1.MustHave, newObject("v1", "Secret", "ns2", "secret")),
+		newObjectTemplate(policyv1.MustHave, newObject("v1", "ServiceAccount", "", "missing")),
+		newObjectTemplate(policyv1.MustNotHave, newObject("v1", "ConfigMap", "ns1", "config")),
+		newObjectTemplate(policyv1.MustHave, newObject("rbac.authorization.k8s.io/v1", "Role", "", "role")),
+		newObjectTemplate(policyv1.MustNotHave, newObject("v1", "ServiceAccount", "ns3", "sa")),
+		newObjectTemplate(policyv1.MustHave, newObject("v1", "Secret", "", "secret")),
+	}
+
+	apiResourceList := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"},
+				{Name: "secrets", Namespaced: true, Kind: "Secret"},
+				{Name: "serviceaccounts", Namespaced: true, Kind: "ServiceAccount"},
+			},
+		},
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "roles", Namespaced: true, Kind: "Role"}},
+		},
+	}
+
+	apiGroups := []*restmapper.APIGroupResources{
+		{
+			Group: metav1.APIGroup{
+				Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "v1", Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "v1", Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{"v1": apiResourceList[0].APIResources},
+		},
+		{
+			Group: metav1.APIGroup{
+				Name: "rbac.authorization.k8s.io",
+				Versions: []metav1.GroupVersionForDiscovery{
+					{GroupVersion: "rbac.authorization.k8s.io/v1", Version: "v1"},
+				},
+				PreferredVersion: metav1.GroupVersionForDiscovery{
+					GroupVersion: "rbac.authorization.k8s.io/v1", Version: "v1",
+				},
+			},
+			VersionedResources: map[string][]metav1.APIResource{"v1": apiResourceList[1].APIResources},
+		},
+	}
+
+	evaluate := func(concurrency uint8) *policyv1.ConfigurationPolicy {
+		clusterObjects := []runtime.Object{}
+
+		for _, ns := range namespaces {
+			clusterObjects = append(clusterObjects,
+				&unstructured.Unstructured{Object: newObject("v1", "ConfigMap", ns, "config")},
+				&unstructured.Unstructured{Object: newObject("v1", "ServiceAccount", ns, "sa")},
+			)
+		}
+
+		clusterObjects = append(
+			clusterObjects, &unstructured.Unstructured{Object: newObject("v1", "Secret", "ns2", "secret")},
+		)
+
+		policy := &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+			Spec: &policyv1.ConfigurationPolicySpec{
+				RemediationAction: policyv1.Inform,
+				NamespaceSelector: policyv1.Target{Include: []policyv1.NonEmptyString{"ns*"}},
+				ObjectTemplates:   objectTemplates,
+			},
+		}
+
+		testScheme := runtime.NewScheme()
+		if err := policyv1.AddToScheme(testScheme); err != nil {
+			t.Fatal(err)
+		}
+
+		client := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(policy.DeepCopy()).Build()
+
+		r := &ConfigurationPolicyReconciler{
+			Client:                        client,
+			Scheme:                        testScheme,
+			Recorder:                      record.NewFakeRecorder(100),
+			TargetK8sConfig:               &rest.Config{Host: server.URL},
+			TargetK8sDynamicClient:        dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), clusterObjects...),
+			SelectorReconciler:            &fixedSR{namespaces: namespaces},
+			TemplateEvaluationConcurrency: concurrency,
+			discoveryInfo: discoveryInfo{
+				apiResourceList: apiResourceList,
+				apiGroups:       apiGroups,
+				serverVersion:   "v1.28.0",
+			},
+		}
+
+		r.handleObjectTemplates(*policy)
+
+		evaluated := &policyv1.ConfigurationPolicy{}
+
+		err := client.Get(context.TODO(), types.NamespacedName{Namespace: "managed", Name: "policy"}, evaluated)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return evaluated
+	}
+
+	serial := evaluate(1)
+
+	// Verify that the object templates were evaluated so that the comparison below isn't trivial
+	assert.Len(t, serial.Status.CompliancyDetails, len(objectTemplates))
+	assert.Len(t, serial.Status.RelatedObjects, 3+1+3+1+3+1+3)
+	assert.Equal(t, policyv1.NonCompliant, serial.Status.ComplianceState)
+
+	// The evaluation times and the transition times differ between the evaluations
+	ignoreTimes := []gocmp.Option{
+		cmpopts.IgnoreTypes(metav1.Time{}),
+		cmpopts.IgnoreFields(policyv1.ObjectProperties{}, "LastEvaluated"),
+		cmpopts.IgnoreFields(policyv1.TemplateStatus{}, "LastEvaluated"),
+	}
+
+	// Run it several times since the order of the concurrent evaluations varies
+	for i := 0; i < 5; i++ {
+		concurrent := evaluate(4)
+
+		assert.Empty(t, gocmp.Diff(serial.Status.RelatedObjects, concurrent.Status.RelatedObjects, ignoreTimes...))
+		assert.Empty(
+			t, gocmp.Diff(serial.Status.CompliancyDetails, concurrent.Status.CompliancyDetails, ignoreTimes...),
+		)
+		assert.Equal(t, serial.Status.ComplianceState, concurrent.Status.ComplianceState)
+	}
+}
+
+func TestSetCompliantCondition(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Generation: 3},
+		Status: policyv1.ConfigurationPolicyStatus{
+			ComplianceState: policyv1.NonCompliant,
+			CompliancyDetails: []policyv1.TemplateStatus{{
+				Conditions: []policyv1.Condition{{Message: "configmaps [a] not found in namespace default"}},
+			}},
+		},
+	}
+
+	setCompliantCondition(policy)
+
+	_, condition := policy.Status.GetCondition("Compliant")
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "NonCompliant", condition.Reason)
+	assert.Equal(t, "NonCompliant; configmaps [a] not found in namespace default", condition.Message)
+	assert.Equal(t, int64(3), condition.ObservedGeneration)
+
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	policy.Status.Conditions[0].LastTransitionTime = transitionTime
+
+	// The transition time is kept while the condition status is unchanged
+	policy.Status.CompliancyDetails[0].Conditions[0].Message = "configmaps [b] not found in namespace default"
+	setCompliantCondition(policy)
+
+	idx, condition := policy.Status.GetCondition("Compliant")
+	assert.Equal(t, 0, idx)
+	assert.Equal(t, transitionTime, condition.LastTransitionTime)
+	assert.Equal(t, "NonCompliant; configmaps [b] not found in namespace default", condition.Message)
+
+	policy.Status.ComplianceState = policyv1.Compliant
+	policy.Status.CompliancyDetails = nil
+	setCompliantCondition(policy)
+
+	_, condition = policy.Status.GetCondition("Compliant")
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Compliant", condition.Reason)
+	assert.Equal(t, "Compliant", condition.Message)
+	assert.NotEqual(t, transitionTime, condition.LastTransitionTime)
+	assert.Len(t, policy.Status.Conditions, 1)
+}
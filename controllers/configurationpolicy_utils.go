@@ -4,17 +4,24 @@
 package controllers
 
 import (
+	"encoding/base64"
 	"fmt"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
+	"github.com/Masterminds/sprig/v3"
 	gocmp "github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pmezard/go-difflib/difflib"
 	apiRes "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/json"
 	"sigs.k8s.io/yaml"
@@ -145,6 +152,50 @@ func updateRelatedObjectsStatus(
 	return list
 }
 
+// ignoreEvaluationProperties compares related objects regardless of when they were last evaluated and the
+// resourceVersion that was evaluated.
+var ignoreEvaluationProperties = cmpopts.IgnoreFields(policyv1.ObjectProperties{}, "LastEvaluated", "ResourceVersion")
+
+// setRelatedLastEvaluated sets the lastEvaluated property of the related objects to the input time in the RFC3339
+// format.
+func setRelatedLastEvaluated(related []policyv1.RelatedObject, evaluated time.Time) {
+	lastEvaluated := evaluated.UTC().Format(time.RFC3339)
+
+	for i := range related {
+		if related[i].Properties == nil {
+			related[i].Properties = &policyv1.ObjectProperties{}
+		}
+
+		related[i].Properties.LastEvaluated = lastEvaluated
+	}
+}
+
+// setEvaluatedIdentity records the UID and the resourceVersion of the evaluated object in the related object
+// properties, so that the evaluation can be correlated with the audit log and a replaced object can be detected. The
+// properties are created if they're nil, and the values already set, such as those of an object the policy just
+// created, are kept.
+func setEvaluatedIdentity(
+	properties *policyv1.ObjectProperties, obj *unstructured.Unstructured,
+) *policyv1.ObjectProperties {
+	if obj == nil {
+		return properties
+	}
+
+	if properties == nil {
+		properties = &policyv1.ObjectProperties{}
+	}
+
+	if properties.UID == "" {
+		properties.UID = string(obj.GetUID())
+	}
+
+	if properties.ResourceVersion == "" {
+		properties.ResourceVersion = obj.GetResourceVersion()
+	}
+
+	return properties
+}
+
 // equalObjWithSort is a wrapper function that calls the correct function to check equality depending on what
 // type the objects to compare are
 func equalObjWithSort(mergedObj interface{}, oldObj interface{}, zeroValueEqualsNil bool) (areEqual bool) {
@@ -184,7 +235,7 @@ func equalObjWithSort(mergedObj interface{}, oldObj interface{}, zeroValueEquals
 			}
 		}
 
-		return fmt.Sprint(mergedObj) == fmt.Sprint(oldObj)
+		return scalarsEqual(mergedObj, oldObj)
 	}
 }
 
@@ -229,29 +280,14 @@ func checkFieldsWithSort(
 				return false
 			}
 		case string:
-			// extra check to see if value is a byte value
-			mQty, err := apiRes.ParseQuantity(mVal)
-			if err != nil {
-				oVal, ok := oldObj[i]
-				if !ok {
-					return false
-				}
-
-				// An error indicates the value is a regular string, so check equality normally
-				if fmt.Sprint(oVal) != fmt.Sprint(mVal) {
-					return false
-				}
-			} else {
-				// if the value is a quantity of bytes, convert original
-				oVal, ok := oldObj[i].(string)
-				if !ok {
-					return false
-				}
+			oVal, ok := oldObj[i]
+			if !ok {
+				return false
+			}
 
-				oQty, err := apiRes.ParseQuantity(oVal)
-				if err != nil || !oQty.Equal(mQty) {
-					return false
-				}
+			// quantities such as "1024Mi" and "1Gi" are compared semantically
+			if !scalarsEqual(mVal, oVal) {
+				return false
 			}
 		default:
 			// if field is not an object, just do a basic compare to check for a match
@@ -262,7 +298,7 @@ func checkFieldsWithSort(
 				oVal = reflect.Zero(ref.Type()).Interface()
 			}
 
-			if fmt.Sprint(oVal) != fmt.Sprint(mVal) {
+			if !scalarsEqual(mVal, oVal) {
 				return false
 			}
 		}
@@ -271,6 +307,41 @@ func checkFieldsWithSort(
 	return true
 }
 
+// pruneNullAndEmpty returns a copy of the value without the map fields that are null or empty maps, including the
+// maps that are only empty after pruning. The API server often drops these fields on write, so they are considered
+// equivalent to the field not being set. List items are pruned but never removed.
+func pruneNullAndEmpty(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		pruned := make(map[string]interface{}, len(value))
+
+		for key, val := range value {
+			prunedVal := pruneNullAndEmpty(val)
+			if prunedVal == nil {
+				continue
+			}
+
+			if prunedMap, ok := prunedVal.(map[string]interface{}); ok && len(prunedMap) == 0 {
+				continue
+			}
+
+			pruned[key] = prunedVal
+		}
+
+		return pruned
+	case []interface{}:
+		pruned := make([]interface{}, len(value))
+
+		for i, val := range value {
+			pruned[i] = pruneNullAndEmpty(val)
+		}
+
+		return pruned
+	default:
+		return value
+	}
+}
+
 // sortAndSprint sorts any lists in the input, and formats the resulting object as a string
 func sortAndSprint(item interface{}) string {
 	switch item := item.(type) {
@@ -295,10 +366,197 @@ func sortAndSprint(item interface{}) string {
 
 		return fmt.Sprintf("%v", sorted)
 	default:
+		// format quantities in their canonical form so that equal quantities such as "1024Mi" and "1Gi" sort together
+		if qty, ok := parseQuantity(item); ok {
+			return qty.String()
+		}
+
 		return fmt.Sprintf("%v", item)
 	}
 }
 
+// parseQuantity parses a string or number as a resource quantity. The returned bool is false if the value is not a
+// quantity.
+func parseQuantity(value interface{}) (apiRes.Quantity, bool) {
+	var qtyStr string
+
+	switch value := value.(type) {
+	case string:
+		qtyStr = value
+	case int64, int32, int, float64:
+		qtyStr = fmt.Sprint(value)
+	default:
+		return apiRes.Quantity{}, false
+	}
+
+	qty, err := apiRes.ParseQuantity(qtyStr)
+	if err != nil {
+		return apiRes.Quantity{}, false
+	}
+
+	return qty, true
+}
+
+// quantitiesEqual compares two values as resource quantities, such as "1024Mi" and "1Gi", or 0.5 and "500m". The
+// isQuantity return value is false if at least one of the values isn't a quantity or neither of them is a string,
+// in which case they should be compared as is.
+func quantitiesEqual(value1 interface{}, value2 interface{}) (equal bool, isQuantity bool) {
+	_, isString1 := value1.(string)
+	_, isString2 := value2.(string)
+
+	if !isString1 && !isString2 {
+		return false, false
+	}
+
+	qty1, ok := parseQuantity(value1)
+	if !ok {
+		return false, false
+	}
+
+	qty2, ok := parseQuantity(value2)
+	if !ok {
+		return false, false
+	}
+
+	return qty1.Equal(qty2), true
+}
+
+// scalarsEqual compares two values that are not maps or lists. Quantities are compared semantically, a string is
+// coerced to the type of a bool or number it's compared to, and everything else is compared by its string
+// representation.
+func scalarsEqual(value1 interface{}, value2 interface{}) bool {
+	if equal, isQuantity := quantitiesEqual(value1, value2); isQuantity {
+		return equal
+	}
+
+	if equal, coerced := coercedScalarsEqual(value1, value2); coerced {
+		return equal
+	}
+
+	return fmt.Sprint(value1) == fmt.Sprint(value2)
+}
+
+// coercedScalarsEqual compares a string to a bool or number by parsing the string as that type, since YAML authors
+// often write "true" or "8080" for fields that the API server stores as a bool or a number. Bools are compared
+// case-insensitively, so "True" equals true but "1" does not. The coerced return value is false if the values aren't
+// a string and a bool or number.
+func coercedScalarsEqual(value1 interface{}, value2 interface{}) (equal bool, coerced bool) {
+	str, ok := value1.(string)
+	other := value2
+
+	if !ok {
+		str, ok = value2.(string)
+		if !ok {
+			return false, false
+		}
+
+		other = value1
+	}
+
+	switch other := other.(type) {
+	case bool:
+		return strings.EqualFold(str, strconv.FormatBool(other)), true
+	case int:
+		return coercedScalarsEqual(str, int64(other))
+	case int32:
+		return coercedScalarsEqual(str, int64(other))
+	case int64:
+		// Parse as an integer first to avoid losing precision on large values
+		if parsed, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return parsed == other, true
+		}
+
+		parsed, err := strconv.ParseFloat(str, 64)
+
+		return err == nil && parsed == float64(other), true
+	case float64:
+		parsed, err := strconv.ParseFloat(str, 64)
+
+		return err == nil && parsed == other, true
+	default:
+		return false, false
+	}
+}
+
+// canonicalizeScalars replaces the scalars in the merged value with their representation in the existing value when
+// they are semantically equal according to scalarsEqual, such as "1024Mi" and "1Gi" or "true" and true, so that
+// enforcing the policy doesn't change them and the diff doesn't show them. List items are paired using
+// matchListItems. The input values are not modified and the returned bool indicates whether anything was replaced.
+func canonicalizeScalars(merged interface{}, existing interface{}) (interface{}, bool) {
+	switch merged := merged.(type) {
+	case map[string]interface{}:
+		existing, ok := existing.(map[string]interface{})
+		if !ok {
+			return merged, false
+		}
+
+		var updated map[string]interface{}
+
+		for key, val := range merged {
+			canonical, changed := canonicalizeScalars(val, existing[key])
+			if !changed {
+				continue
+			}
+
+			if updated == nil {
+				updated = make(map[string]interface{}, len(merged))
+
+				for k, v := range merged {
+					updated[k] = v
+				}
+			}
+
+			updated[key] = canonical
+		}
+
+		if updated == nil {
+			return merged, false
+		}
+
+		return updated, true
+	case []interface{}:
+		existing, ok := existing.([]interface{})
+		if !ok {
+			return merged, false
+		}
+
+		var updated []interface{}
+
+		for existingIdx, mergedIdx := range matchListItems(existing, merged) {
+			if mergedIdx == -1 {
+				continue
+			}
+
+			canonical, changed := canonicalizeScalars(merged[mergedIdx], existing[existingIdx])
+			if !changed {
+				continue
+			}
+
+			if updated == nil {
+				updated = append([]interface{}{}, merged...)
+			}
+
+			updated[mergedIdx] = canonical
+		}
+
+		if updated == nil {
+			return merged, false
+		}
+
+		return updated, true
+	default:
+		if existing == nil || reflect.DeepEqual(merged, existing) {
+			return merged, false
+		}
+
+		if scalarsEqual(merged, existing) {
+			return existing, true
+		}
+
+		return merged, false
+	}
+}
+
 // checkListsMatch is a generic list check that uses an arbitrary sort to ensure it is comparing the right values
 func checkListsMatch(oldVal []interface{}, mergedVal []interface{}) (m bool) {
 	if (oldVal == nil && mergedVal != nil) || (oldVal != nil && mergedVal == nil) {
@@ -335,7 +593,7 @@ func checkListsMatch(oldVal []interface{}, mergedVal []interface{}) (m bool) {
 			return false
 		default:
 			// otherwise, just do a generic check
-			if fmt.Sprint(oNestedVal) != fmt.Sprint(mVal[idx]) {
+			if !scalarsEqual(oNestedVal, mVal[idx]) {
 				return false
 			}
 		}
@@ -468,11 +726,18 @@ func createStatus(
 ) {
 	reasonToNamespaceToEvent := map[string]map[string]*objectTmplEvalResultWithEvent{}
 	compliant = true
+	// The progress of a throttled enforcement is reported as the number of namespaces that are already compliant
+	remediated := 0
+	total := len(namespaceToEvent)
 	// If all objects are compliant, this only contains compliant events. If there is at least one noncompliant
 	// object, then this will only contain noncompliant events.
 	filteredNamespaceToEvent := map[string]*objectTmplEvalResultWithEvent{}
 
 	for namespace, eventWithCtx := range namespaceToEvent {
+		if eventWithCtx.event.compliant {
+			remediated++
+		}
+
 		// If a noncompliant event is encountered, then reset the maps to only include noncompliant events.
 		if compliant && !eventWithCtx.event.compliant {
 			compliant = false
@@ -496,13 +761,19 @@ func createStatus(
 	// Create an order of the reasons so that the generated reason and compliance message is deterministic.
 	orderedReasons := []string{
 		reasonWantFoundExists,
+		reasonWantFoundExistsOnly,
 		reasonWantFoundCreated,
 		reasonUpdateSuccess,
+		reasonRecreateSuccess,
 		reasonDeleteSuccess,
 		reasonWantFoundDNE,
 		reasonWantFoundNoMatch,
 		reasonWantNotFoundDNE,
 		reasonWantNotFoundExists,
+		reasonWantNotFoundTerm,
+		reasonPreviewCreate,
+		reasonPreviewUpdate,
+		reasonPreviewDelete,
 	}
 	otherReasons := []string{}
 
@@ -575,12 +846,18 @@ func createStatus(
 			case reasonWantFoundExists:
 				generatedReason = "K8s `must have` object already exists"
 				generatedMsg = fmt.Sprintf("%s%s found as specified", resourceName, namesStr)
+			case reasonWantFoundExistsOnly:
+				generatedReason = "K8s `must have` object already exists"
+				generatedMsg = fmt.Sprintf("%s%s found (contents not evaluated)", resourceName, namesStr)
 			case reasonWantFoundCreated:
 				generatedReason = reasonWantFoundCreated
 				generatedMsg = fmt.Sprintf("%s%s was created successfully", resourceName, namesStr)
 			case reasonUpdateSuccess:
 				generatedReason = reasonUpdateSuccess
 				generatedMsg = fmt.Sprintf("%s%s was updated successfully", resourceName, namesStr)
+			case reasonRecreateSuccess:
+				generatedReason = reasonRecreateSuccess
+				generatedMsg = fmt.Sprintf("%s%s was recreated to apply changes", resourceName, namesStr)
 			case reasonDeleteSuccess:
 				generatedReason = reasonDeleteSuccess
 				generatedMsg = fmt.Sprintf("%s%s was deleted successfully", resourceName, namesStr)
@@ -593,9 +870,33 @@ func createStatus(
 			case reasonWantNotFoundExists:
 				generatedReason = "K8s has a `must not have` object"
 				compliancyDetailsMsg += fmt.Sprintf("%s%s found", resourceName, namesStr)
+			case reasonWantNotFoundTerm:
+				generatedReason = "K8s has a `must not have` object"
+				compliancyDetailsMsg += fmt.Sprintf("%s%s found but is terminating", resourceName, namesStr)
 			case reasonWantNotFoundDNE:
 				generatedReason = "K8s `must not have` object already missing"
 				compliancyDetailsMsg += fmt.Sprintf("%s%s missing as expected", resourceName, namesStr)
+			case reasonPreviewCreate:
+				generatedReason = "K8s does not have a `must have` object"
+				generatedMsg = fmt.Sprintf("%s%s not found and would be created (preview)", resourceName, namesStr)
+			case reasonPreviewUpdate:
+				generatedReason = "K8s does not have a `must have` object"
+				generatedMsg = fmt.Sprintf(
+					"%s%s found but not as specified and would be updated (preview)", resourceName, namesStr,
+				)
+			case reasonPreviewDelete:
+				generatedReason = "K8s has a `must not have` object"
+				generatedMsg = fmt.Sprintf("%s%s found and would be deleted (preview)", resourceName, namesStr)
+			case reasonAccessDenied:
+				generatedReason = reasonAccessDenied
+				generatedMsg = fmt.Sprintf(
+					"%s%s can't be evaluated since access is denied by RBAC", resourceName, namesStr,
+				)
+			case reasonEnforcementThrottled:
+				generatedReason = reasonEnforcementThrottled
+				generatedMsg = fmt.Sprintf(
+					"%s%s not remediated yet since the enforcement rate limit was reached", resourceName, namesStr,
+				)
 			default:
 				// If it's not one of the above reasons, then skip consolidation. This is likely an error being
 				// reported.
@@ -641,6 +942,10 @@ func createStatus(
 				} else {
 					compliancyDetailsMsg += fmt.Sprintf(" in namespace %s", objectNameStrsToNamespaces[namesStr][0])
 				}
+
+				if reason == reasonEnforcementThrottled && i == len(sortedObjectNamesStrs)-1 {
+					compliancyDetailsMsg += fmt.Sprintf(" (remediated %d/%d namespaces)", remediated, total)
+				}
 			}
 		}
 	}
@@ -648,75 +953,1046 @@ func createStatus(
 	return
 }
 
-func objHasFinalizer(obj metav1.Object, finalizer string) bool {
-	for _, existingFinalizer := range obj.GetFinalizers() {
-		if existingFinalizer == finalizer {
-			return true
+// customMessageData is the data available to the spec.customMessage templates.
+type customMessageData struct {
+	DefaultMessage string
+	Objects        []customMessageObject
+	Namespaces     []string
+}
+
+type customMessageObject struct {
+	Name      string
+	Namespace string
+}
+
+// renderCustomMessage returns the message from the policy's spec.customMessage template that matches the compliance
+// of the object template. The generated message and the evaluated objects are available to the template. If there is
+// no template, the generated message is returned as is. If the template fails to render, the generated message is
+// returned with a warning.
+func renderCustomMessage(
+	plc *policyv1.ConfigurationPolicy,
+	compliant bool,
+	defaultMsg string,
+	namespaceToEvent map[string]*objectTmplEvalResultWithEvent,
+) string {
+	if plc.Spec == nil {
+		return defaultMsg
+	}
+
+	tmplStr := plc.Spec.CustomMessage.NonCompliant
+	if compliant {
+		tmplStr = plc.Spec.CustomMessage.Compliant
+	}
+
+	if tmplStr == "" {
+		return defaultMsg
+	}
+
+	data := customMessageData{
+		DefaultMessage: defaultMsg,
+		Objects:        []customMessageObject{},
+		Namespaces:     []string{},
+	}
+
+	namespaces := make([]string, 0, len(namespaceToEvent))
+
+	for ns := range namespaceToEvent {
+		namespaces = append(namespaces, ns)
+	}
+
+	sort.Strings(namespaces)
+
+	for _, ns := range namespaces {
+		// A namespace of "" indicates a cluster scoped object
+		if ns != "" {
+			data.Namespaces = append(data.Namespaces, ns)
+		}
+
+		for _, name := range namespaceToEvent[ns].result.objectNames {
+			data.Objects = append(data.Objects, customMessageObject{Name: name, Namespace: ns})
 		}
 	}
 
-	return false
+	// Only the repeatable Sprig functions are available, which excludes the functions that read the environment of the
+	// controller or resolve host names
+	tmpl, err := template.New("customMessage").Funcs(sprig.HermeticTxtFuncMap()).Parse(tmplStr)
+	if err == nil {
+		var msg strings.Builder
+
+		err = tmpl.Execute(&msg, data)
+		if err == nil {
+			return msg.String()
+		}
+	}
+
+	log.Info(
+		"Failed to render the custom message template, using the default message",
+		"policy", plc.GetName(), "error", err,
+	)
+
+	return fmt.Sprintf("%s (warning: the spec.customMessage template failed to render: %v)", defaultMsg, err)
 }
 
-func removeObjFinalizerPatch(obj metav1.Object, finalizer string) []byte {
-	for i, existingFinalizer := range obj.GetFinalizers() {
-		if existingFinalizer == finalizer {
-			return []byte(`[{"op":"remove","path":"/metadata/finalizers/` + strconv.FormatInt(int64(i), 10) + `"}]`)
+// summarizeRelatedObjects counts the related objects of an object template by their compliance.
+func summarizeRelatedObjects(related []policyv1.RelatedObject) *policyv1.TemplateSummary {
+	summary := &policyv1.TemplateSummary{Matched: len(related)}
+
+	for _, object := range related {
+		switch object.Compliant {
+		case string(policyv1.Compliant):
+			summary.Compliant++
+		case string(policyv1.NonCompliant):
+			summary.NonCompliant++
 		}
 	}
 
-	return nil
+	return summary
 }
 
-func containRelated(related []policyv1.RelatedObject, input policyv1.RelatedObject) bool {
-	// should compare name, APIVersion, Kind  and namespace
-	for _, r := range related {
-		if gocmp.Equal(r.Object, input.Object) {
-			return true
+// parseIgnoreFields converts the JSON pointer paths in an object template's ignoreFields to the list of keys in
+// each path. An error is returned if a path is not a valid JSON pointer or refers to a field that identifies the
+// object.
+func parseIgnoreFields(ignoreFields []string) ([][]string, error) {
+	paths := make([][]string, 0, len(ignoreFields))
+
+	for _, field := range ignoreFields {
+		keys, err := splitJSONPointer(field, "ignoreFields")
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.Join(keys, "/") {
+		case "apiVersion", "kind", "metadata", "metadata/name", "metadata/namespace":
+			return nil, fmt.Errorf("the ignoreFields path %s identifies the object and can't be ignored", field)
 		}
+
+		paths = append(paths, keys)
 	}
 
-	return false
+	return paths, nil
 }
 
-// generateDiff takes two unstructured objects and returns the diff between the two embedded objects
-func generateDiff(existingObj, updatedObj *unstructured.Unstructured) (string, error) {
-	// Marshal YAML to []byte and parse object names for logging
-	existingYAML, err := yaml.Marshal(existingObj.Object)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal existing object to YAML for diff: %w", err)
+// parseUnorderedLists converts the JSON pointer paths in an object template's unorderedLists to the list of keys
+// in each path. A key of '*' matches every item of a list. An error is returned if a path is not a valid JSON
+// pointer.
+func parseUnorderedLists(unorderedLists []string) ([][]string, error) {
+	paths := make([][]string, 0, len(unorderedLists))
+
+	for _, field := range unorderedLists {
+		keys, err := splitJSONPointer(field, "unorderedLists")
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, keys)
 	}
 
-	existingYAMLName := existingObj.GetName() + " : existing"
-	if existingObj.GetNamespace() != "" {
-		existingYAMLName = existingObj.GetNamespace() + "/" + existingYAMLName
+	return paths, nil
+}
+
+// listMergeKey is a parsed entry of an object template's listMergeKeys. The key is the field that identifies the
+// items of the list at the path, or an empty string when the items are identified by their full content.
+type listMergeKey struct {
+	path []string
+	key  string
+}
+
+// parseListMergeKeys converts the JSON pointer paths in an object template's listMergeKeys to the list of keys in
+// each path, sorted by the path. An error is returned if a path is not a valid JSON pointer or doesn't only traverse
+// maps.
+func parseListMergeKeys(listMergeKeys map[string]string) ([]listMergeKey, error) {
+	mergeKeys := make([]listMergeKey, 0, len(listMergeKeys))
+
+	for field, key := range listMergeKeys {
+		keys, err := splitJSONPointer(field, "listMergeKeys")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pathKey := range keys {
+			if pathKey == "*" {
+				return nil, fmt.Errorf("the listMergeKeys path %s can only traverse maps", field)
+			}
+		}
+
+		mergeKeys = append(mergeKeys, listMergeKey{path: keys, key: key})
 	}
 
-	updatedYAML, err := yaml.Marshal(updatedObj.Object)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal updated object to YAML for diff: %w", err)
+	sort.Slice(mergeKeys, func(i, j int) bool {
+		return strings.Join(mergeKeys[i].path, "/") < strings.Join(mergeKeys[j].path, "/")
+	})
+
+	return mergeKeys, nil
+}
+
+// splitJSONPointer returns the unescaped keys of the input JSON pointer path. The fieldName is the object template
+// field the path is from, which is used in the returned error.
+func splitJSONPointer(path string, fieldName string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("the %s path %s must start with /", fieldName, path)
 	}
 
-	updatedYAMLName := updatedObj.GetName() + " : updated"
-	if updatedObj.GetNamespace() != "" {
-		updatedYAMLName = updatedObj.GetNamespace() + "/" + updatedYAMLName
+	unescaper := strings.NewReplacer("~1", "/", "~0", "~")
+	keys := strings.Split(path[1:], "/")
+
+	for i, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("the %s path %s contains an empty key", fieldName, path)
+		}
+
+		keys[i] = unescaper.Replace(key)
 	}
 
-	// Set the diffing configuration
-	// See https://pkg.go.dev/github.com/pmezard/go-difflib/difflib#UnifiedDiff
-	unifiedDiff := difflib.UnifiedDiff{
-		A:        difflib.SplitLines(string(existingYAML)),
-		FromFile: existingYAMLName,
-		B:        difflib.SplitLines(string(updatedYAML)),
-		ToFile:   updatedYAMLName,
-		Context:  1,
+	return keys, nil
+}
+
+// removeIgnoredFields removes the fields at the input paths from the object so that they aren't compared.
+func removeIgnoredFields(obj *unstructured.Unstructured, paths [][]string) {
+	for _, path := range paths {
+		unstructured.RemoveNestedField(obj.Object, path...)
 	}
+}
 
-	// Generate and return the diff
-	diff, err := difflib.GetUnifiedDiffString(unifiedDiff)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate diff: %w", err)
+// restoreIgnoredFields sets the fields at the input paths on the object to their values in the original object,
+// so that merging the objectDefinition into the object doesn't modify them.
+func restoreIgnoredFields(obj *unstructured.Unstructured, original *unstructured.Unstructured, paths [][]string) {
+	for _, path := range paths {
+		value, found, err := unstructured.NestedFieldNoCopy(original.Object, path...)
+		if err != nil {
+			continue
+		}
+
+		if !found {
+			unstructured.RemoveNestedField(obj.Object, path...)
+
+			continue
+		}
+
+		// An error here means a parent field is no longer a map, which is left as is
+		_ = unstructured.SetNestedField(obj.Object, runtime.DeepCopyJSONValue(value), path...)
+	}
+}
+
+// dedupeUnorderedLists removes the duplicate items of the lists at the input paths so that they are compared as
+// sets. The first occurrence of an item is kept.
+func dedupeUnorderedLists(obj *unstructured.Unstructured, paths [][]string) {
+	for _, path := range paths {
+		obj.Object, _ = transformLists(obj.Object, path, dedupeList).(map[string]interface{})
 	}
+}
 
-	return diff, nil
+// alignUnorderedLists reorders the lists at the input paths on the object to follow the order of the same lists in
+// the original object, so that merging the objectDefinition into the object doesn't reorder them. Items that aren't
+// in the original list are added at the end.
+func alignUnorderedLists(obj *unstructured.Unstructured, original *unstructured.Unstructured, paths [][]string) {
+	for _, path := range paths {
+		obj.Object, _ = alignLists(obj.Object, original.Object, path).(map[string]interface{})
+	}
+}
+
+// transformLists calls transform on the lists found at the input path in the value and returns the updated value.
+// A key of '*' in the path matches every item of a list. The input value is not modified.
+func transformLists(
+	value interface{}, path []string, transform func([]interface{}) []interface{},
+) interface{} {
+	if len(path) == 0 {
+		if list, ok := value.([]interface{}); ok {
+			return transform(list)
+		}
+
+		return value
+	}
+
+	if path[0] == "*" {
+		list, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+
+		updated := make([]interface{}, len(list))
+
+		for i, item := range list {
+			updated[i] = transformLists(item, path[1:], transform)
+		}
+
+		return updated
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	child, found := fields[path[0]]
+	if !found {
+		return value
+	}
+
+	updated := make(map[string]interface{}, len(fields))
+
+	for key, val := range fields {
+		updated[key] = val
+	}
+
+	updated[path[0]] = transformLists(child, path[1:], transform)
+
+	return updated
+}
+
+// dedupeList returns the list without the duplicate items, keeping the first occurrence of each item.
+func dedupeList(list []interface{}) []interface{} {
+	deduped := make([]interface{}, 0, len(list))
+	seen := make(map[string]bool, len(list))
+
+	for _, item := range list {
+		key := sortAndSprint(item)
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		deduped = append(deduped, item)
+	}
+
+	return deduped
+}
+
+// alignLists is like transformLists, but reorders the lists found at the input path in the merged value to follow
+// the order of the matching lists in the existing value. The items matched by a '*' key are paired with the
+// existing list items using matchListItems.
+func alignLists(merged interface{}, existing interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		mergedList, ok := merged.([]interface{})
+		if !ok {
+			return merged
+		}
+
+		existingList, ok := existing.([]interface{})
+		if !ok {
+			return merged
+		}
+
+		aligned := make([]interface{}, 0, len(mergedList))
+		matched := make([]bool, len(mergedList))
+
+		for _, mergedIdx := range matchListItems(existingList, mergedList) {
+			if mergedIdx == -1 {
+				continue
+			}
+
+			matched[mergedIdx] = true
+
+			aligned = append(aligned, mergedList[mergedIdx])
+		}
+
+		for i, item := range mergedList {
+			if !matched[i] {
+				aligned = append(aligned, item)
+			}
+		}
+
+		return aligned
+	}
+
+	if path[0] == "*" {
+		mergedList, ok := merged.([]interface{})
+		if !ok {
+			return merged
+		}
+
+		existingList, _ := existing.([]interface{})
+		existingMatches := make([]interface{}, len(mergedList))
+
+		for existingIdx, mergedIdx := range matchListItems(existingList, mergedList) {
+			if mergedIdx != -1 {
+				existingMatches[mergedIdx] = existingList[existingIdx]
+			}
+		}
+
+		updated := make([]interface{}, len(mergedList))
+
+		for i, item := range mergedList {
+			updated[i] = alignLists(item, existingMatches[i], path[1:])
+		}
+
+		return updated
+	}
+
+	fields, ok := merged.(map[string]interface{})
+	if !ok {
+		return merged
+	}
+
+	child, found := fields[path[0]]
+	if !found {
+		return merged
+	}
+
+	existingFields, _ := existing.(map[string]interface{})
+	updated := make(map[string]interface{}, len(fields))
+
+	for key, val := range fields {
+		updated[key] = val
+	}
+
+	updated[path[0]] = alignLists(child, existingFields[path[0]], path[1:])
+
+	return updated
+}
+
+// matchListItems pairs each item in the existing list with an item in the merged list, and returns the index of
+// the merged item for each existing item, or -1 if there is no match. Equal items are paired first, then the
+// remaining maps with the same "name" key value, such as the containers of a Deployment that were updated.
+func matchListItems(existing []interface{}, merged []interface{}) []int {
+	matches := make([]int, len(existing))
+	used := make([]bool, len(merged))
+	mergedKeys := make([]string, len(merged))
+
+	for i, item := range merged {
+		mergedKeys[i] = sortAndSprint(item)
+	}
+
+	for existingIdx, item := range existing {
+		matches[existingIdx] = -1
+		key := sortAndSprint(item)
+
+		for mergedIdx := range merged {
+			if !used[mergedIdx] && mergedKeys[mergedIdx] == key {
+				matches[existingIdx] = mergedIdx
+				used[mergedIdx] = true
+
+				break
+			}
+		}
+	}
+
+	for existingIdx, item := range existing {
+		name := listItemName(item)
+		if matches[existingIdx] != -1 || name == "" {
+			continue
+		}
+
+		for mergedIdx := range merged {
+			if !used[mergedIdx] && listItemName(merged[mergedIdx]) == name {
+				matches[existingIdx] = mergedIdx
+				used[mergedIdx] = true
+
+				break
+			}
+		}
+	}
+
+	return matches
+}
+
+// listItemName returns the "name" key value of a list item that is a map, or an empty string otherwise.
+func listItemName(item interface{}) string {
+	fields, ok := item.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	name, _ := fields["name"].(string)
+
+	return name
+}
+
+// applyListMergeKey is like transformLists, but replaces the list found at the input path in the merged value with
+// the result of mergeListByKey on the desired and existing lists at the same path. The input value is not modified.
+func applyListMergeKey(
+	merged interface{},
+	desired interface{},
+	existing interface{},
+	path []string,
+	mergeKey string,
+	ctype string,
+	zeroValueEqualsNil bool,
+) interface{} {
+	if len(path) == 0 {
+		desiredList, ok := desired.([]interface{})
+		if !ok {
+			return merged
+		}
+
+		existingList, ok := existing.([]interface{})
+		if !ok {
+			return merged
+		}
+
+		return mergeListByKey(desiredList, existingList, mergeKey, ctype, zeroValueEqualsNil)
+	}
+
+	fields, ok := merged.(map[string]interface{})
+	if !ok {
+		return merged
+	}
+
+	child, found := fields[path[0]]
+	if !found {
+		return merged
+	}
+
+	desiredFields, _ := desired.(map[string]interface{})
+	existingFields, _ := existing.(map[string]interface{})
+	updated := make(map[string]interface{}, len(fields))
+
+	for key, val := range fields {
+		updated[key] = val
+	}
+
+	updated[path[0]] = applyListMergeKey(
+		child, desiredFields[path[0]], existingFields[path[0]], path[1:], mergeKey, ctype, zeroValueEqualsNil,
+	)
+
+	return updated
+}
+
+// mergeListByKey merges the desired list into the existing list by pairing the items with the same value for the
+// merge key, following the order of the existing list. The paired items are merged with mergeSpecs, except with
+// mustonlyhave where the desired item is used as is. The existing items without a desired item are only kept when
+// the compliance type isn't mustonlyhave, and the desired items without an existing item are added at the end.
+func mergeListByKey(
+	desired []interface{}, existing []interface{}, mergeKey string, ctype string, zeroValueEqualsNil bool,
+) []interface{} {
+	merged := make([]interface{}, 0, len(existing)+len(desired))
+	used := make([]bool, len(desired))
+	desiredKeys := make([]string, len(desired))
+
+	for i, item := range desired {
+		desiredKeys[i] = listItemKey(item, mergeKey)
+	}
+
+	for _, existingItem := range existing {
+		key := listItemKey(existingItem, mergeKey)
+		desiredIdx := -1
+
+		for i := range desired {
+			if !used[i] && desiredKeys[i] == key {
+				desiredIdx = i
+				used[i] = true
+
+				break
+			}
+		}
+
+		if desiredIdx == -1 {
+			if ctype != "mustonlyhave" {
+				merged = append(merged, existingItem)
+			}
+
+			continue
+		}
+
+		mergedItem := desired[desiredIdx]
+
+		if ctype != "mustonlyhave" {
+			if item, err := mergeSpecs(desired[desiredIdx], existingItem, ctype, zeroValueEqualsNil); err == nil {
+				mergedItem = item
+			}
+		}
+
+		merged = append(merged, mergedItem)
+	}
+
+	for i, item := range desired {
+		if !used[i] {
+			merged = append(merged, item)
+		}
+	}
+
+	return merged
+}
+
+// listItemKey returns the value that identifies a list item for the merge key. Items that aren't maps with the merge
+// key, or any item when the merge key is empty, are identified by their full content.
+func listItemKey(item interface{}, mergeKey string) string {
+	if fields, ok := item.(map[string]interface{}); ok && mergeKey != "" {
+		if value, found := fields[mergeKey]; found {
+			return mergeKey + "=" + sortAndSprint(value)
+		}
+	}
+
+	return sortAndSprint(item)
+}
+
+// isSecret returns whether the object is a Kubernetes Secret.
+func isSecret(obj *unstructured.Unstructured) bool {
+	return obj.GetAPIVersion() == "v1" && obj.GetKind() == "Secret"
+}
+
+// secretStringDataToData returns the Secret object definition with its stringData values base64 encoded into data,
+// which is how the API server stores them. Like with kubectl apply, a stringData key takes precedence over the same
+// key in data. Other objects are returned as is.
+func secretStringDataToData(obj unstructured.Unstructured) unstructured.Unstructured {
+	stringData, ok := obj.Object["stringData"].(map[string]interface{})
+	if !ok || !isSecret(&obj) {
+		return obj
+	}
+
+	converted := obj.DeepCopy()
+
+	data, ok := converted.Object["data"].(map[string]interface{})
+	if !ok {
+		data = make(map[string]interface{}, len(stringData))
+	}
+
+	for key, value := range stringData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(value)))
+	}
+
+	converted.Object["data"] = data
+	delete(converted.Object, "stringData")
+
+	return *converted
+}
+
+const (
+	redactedSecretValue        = "<REDACTED>"
+	redactedChangedSecretValue = "<REDACTED: changed>"
+)
+
+// redactSecretValues returns copies of the Secret objects with the data and stringData values redacted so that a diff
+// of them doesn't expose the secret values. The values in the updated object that differ from the existing object are
+// redacted differently so that the diff still shows which keys are changed.
+func redactSecretValues(
+	existingObj *unstructured.Unstructured, updatedObj *unstructured.Unstructured,
+) (*unstructured.Unstructured, *unstructured.Unstructured) {
+	existingValues := secretValues(existingObj)
+	updatedValues := secretValues(updatedObj)
+
+	existingCopy := existingObj.DeepCopy()
+	updatedCopy := updatedObj.DeepCopy()
+
+	for _, field := range []string{"data", "stringData"} {
+		if values, ok := existingCopy.Object[field].(map[string]interface{}); ok {
+			for key := range values {
+				values[key] = redactedSecretValue
+			}
+		}
+
+		if values, ok := updatedCopy.Object[field].(map[string]interface{}); ok {
+			for key := range values {
+				existingValue, found := existingValues[key]
+				if found && existingValue != updatedValues[key] {
+					values[key] = redactedChangedSecretValue
+				} else {
+					values[key] = redactedSecretValue
+				}
+			}
+		}
+	}
+
+	return existingCopy, updatedCopy
+}
+
+// secretValues returns the decoded data values of the Secret overlaid with its stringData values.
+func secretValues(obj *unstructured.Unstructured) map[string]string {
+	values := map[string]string{}
+
+	if data, ok := obj.Object["data"].(map[string]interface{}); ok {
+		for key, value := range data {
+			encoded := fmt.Sprint(value)
+
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				values[key] = encoded
+			} else {
+				values[key] = string(decoded)
+			}
+		}
+	}
+
+	if stringData, ok := obj.Object["stringData"].(map[string]interface{}); ok {
+		for key, value := range stringData {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+
+	return values
+}
+
+func objHasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, existingFinalizer := range obj.GetFinalizers() {
+		if existingFinalizer == finalizer {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeObjFinalizerPatch(obj metav1.Object, finalizer string) []byte {
+	for i, existingFinalizer := range obj.GetFinalizers() {
+		if existingFinalizer == finalizer {
+			return []byte(`[{"op":"remove","path":"/metadata/finalizers/` + strconv.FormatInt(int64(i), 10) + `"}]`)
+		}
+	}
+
+	return nil
+}
+
+func containRelated(related []policyv1.RelatedObject, input policyv1.RelatedObject) bool {
+	// should compare name, APIVersion, Kind  and namespace
+	for _, r := range related {
+		if gocmp.Equal(r.Object, input.Object) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateDiff takes two unstructured objects and returns the diff between the two embedded objects
+func generateDiff(existingObj, updatedObj *unstructured.Unstructured) (string, error) {
+	return generateDiffWithContext(existingObj, updatedObj, defaultDiffContextLines, false)
+}
+
+// generateDiffWithContext returns the diff between the two embedded objects with the number of unchanged lines around
+// each change. When hunkPaths is true, the hunk headers include the YAML path of their first changed line.
+func generateDiffWithContext(
+	existingObj, updatedObj *unstructured.Unstructured, contextLines int, hunkPaths bool,
+) (string, error) {
+	if isSecret(existingObj) || isSecret(updatedObj) {
+		existingObj, updatedObj = redactSecretValues(existingObj, updatedObj)
+	}
+
+	// Marshal YAML to []byte and parse object names for logging
+	existingYAML, err := yaml.Marshal(existingObj.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal existing object to YAML for diff: %w", err)
+	}
+
+	existingYAMLName := existingObj.GetName() + " : existing"
+	if existingObj.GetNamespace() != "" {
+		existingYAMLName = existingObj.GetNamespace() + "/" + existingYAMLName
+	}
+
+	updatedYAML, err := yaml.Marshal(updatedObj.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal updated object to YAML for diff: %w", err)
+	}
+
+	updatedYAMLName := updatedObj.GetName() + " : updated"
+	if updatedObj.GetNamespace() != "" {
+		updatedYAMLName = updatedObj.GetNamespace() + "/" + updatedYAMLName
+	}
+
+	// Set the diffing configuration
+	// See https://pkg.go.dev/github.com/pmezard/go-difflib/difflib#UnifiedDiff
+	unifiedDiff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(existingYAML)),
+		FromFile: existingYAMLName,
+		B:        difflib.SplitLines(string(updatedYAML)),
+		ToFile:   updatedYAMLName,
+		Context:  contextLines,
+	}
+
+	// Generate and return the diff
+	diff, err := difflib.GetUnifiedDiffString(unifiedDiff)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate diff: %w", err)
+	}
+
+	if hunkPaths {
+		diff = withHunkPaths(diff, unifiedDiff.A, unifiedDiff.B)
+	}
+
+	return diff, nil
+}
+
+// expandListTemplates replaces each object template with a v1 List objectDefinition with an object template per
+// item in the list. The other fields of the object template apply to every item. An empty list results in no object
+// templates, and a list nested in a list is an error.
+func expandListTemplates(objTemps []*policyv1.ObjectTemplate) ([]*policyv1.ObjectTemplate, error) {
+	expanded := make([]*policyv1.ObjectTemplate, 0, len(objTemps))
+
+	for i, objectT := range objTemps {
+		if objectT == nil {
+			expanded = append(expanded, objectT)
+
+			continue
+		}
+
+		objDef := map[string]interface{}{}
+
+		// An invalid objectDefinition is reported when the object template is evaluated
+		if err := json.Unmarshal(objectT.ObjectDefinition.Raw, &objDef); err != nil ||
+			objDef["apiVersion"] != "v1" || objDef["kind"] != "List" {
+			expanded = append(expanded, objectT)
+
+			continue
+		}
+
+		items, _ := objDef["items"].([]interface{})
+
+		for j, item := range items {
+			itemObj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("object-templates[%d]: the List item at index %d is not an object", i, j)
+			}
+
+			if itemObj["kind"] == "List" {
+				return nil, fmt.Errorf("object-templates[%d]: the List item at index %d is a List, which is "+
+					"not supported", i, j)
+			}
+
+			itemJSON, err := json.Marshal(itemObj)
+			if err != nil {
+				return nil, fmt.Errorf("object-templates[%d]: the List item at index %d is invalid: %w", i, j, err)
+			}
+
+			itemT := objectT.DeepCopy()
+			itemT.ObjectDefinition = runtime.RawExtension{Raw: itemJSON}
+
+			expanded = append(expanded, itemT)
+		}
+	}
+
+	return expanded, nil
+}
+
+// maxFieldManagerLength is the maximum length of a field manager accepted by the Kubernetes API server.
+const maxFieldManagerLength = 128
+
+// policyFieldManager returns the field manager for the requests made on behalf of the input policy. The policy name
+// is appended to the base field manager with the characters that aren't alphanumeric, '-', '_', or '.' replaced with
+// '-', and the result is truncated to the maximum length accepted by the Kubernetes API server.
+func policyFieldManager(base string, policyName string) string {
+	if base == "" {
+		base = DefaultFieldManager
+	}
+
+	manager := base
+
+	if policyName != "" {
+		sanitized := strings.Map(func(char rune) rune {
+			switch {
+			case char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z', char >= '0' && char <= '9':
+				return char
+			case char == '-', char == '_', char == '.':
+				return char
+			default:
+				return '-'
+			}
+		}, policyName)
+
+		manager += "/" + sanitized
+	}
+
+	if len(manager) > maxFieldManagerLength {
+		manager = manager[:maxFieldManagerLength]
+	}
+
+	return manager
+}
+
+// capRelatedObjects limits the related objects of an object template to maxObjects entries followed by an entry that
+// summarizes the omitted objects. The noncompliant objects are shown first, and the objects are otherwise sorted by
+// namespace and name so that the same objects are shown between evaluations. The objects created by the policy and the
+// objects in the protected set, keyed by getObjectString, are always shown since the policy needs them to track the
+// objects it created. A maxObjects value of zero or less disables the limit.
+func capRelatedObjects(
+	related []policyv1.RelatedObject, maxObjects int, protected map[string]bool,
+) []policyv1.RelatedObject {
+	if maxObjects <= 0 || len(related) <= maxObjects {
+		return related
+	}
+
+	rank := func(object policyv1.RelatedObject) int {
+		if isCreatedByPolicy(object) || protected[getObjectString(object)] {
+			return 0
+		}
+
+		if object.Compliant == string(policyv1.NonCompliant) {
+			return 1
+		}
+
+		return 2
+	}
+
+	sorted := append([]policyv1.RelatedObject{}, related...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if rank(sorted[i]) != rank(sorted[j]) {
+			return rank(sorted[i]) < rank(sorted[j])
+		}
+
+		if sorted[i].Object.Metadata.Namespace != sorted[j].Object.Metadata.Namespace {
+			return sorted[i].Object.Metadata.Namespace < sorted[j].Object.Metadata.Namespace
+		}
+
+		return sorted[i].Object.Metadata.Name < sorted[j].Object.Metadata.Name
+	})
+
+	shown := maxObjects
+
+	for shown < len(sorted) && rank(sorted[shown]) == 0 {
+		shown++
+	}
+
+	if shown == len(sorted) {
+		return sorted
+	}
+
+	return append(sorted[:shown], omittedRelatedObjectsSummary(sorted[shown:]))
+}
+
+// isCreatedByPolicy returns whether the related object was recorded as created by the policy.
+func isCreatedByPolicy(object policyv1.RelatedObject) bool {
+	return object.Properties != nil && object.Properties.CreatedByPolicy != nil && *object.Properties.CreatedByPolicy
+}
+
+// omittedRelatedObjectsSummary returns a related object entry that counts the input omitted related objects by their
+// compliance. Like a condensed related object, its name is "-".
+func omittedRelatedObjectsSummary(omitted []policyv1.RelatedObject) policyv1.RelatedObject {
+	summary := summarizeRelatedObjects(omitted)
+
+	compliant := string(policyv1.Compliant)
+	if summary.NonCompliant > 0 {
+		compliant = string(policyv1.NonCompliant)
+	}
+
+	return policyv1.RelatedObject{
+		Compliant: compliant,
+		Reason: fmt.Sprintf(
+			"and %s more objects (%s compliant / %s noncompliant)",
+			formatCount(summary.Matched), formatCount(summary.Compliant), formatCount(summary.NonCompliant),
+		),
+		Object: policyv1.ObjectResource{
+			APIVersion: omitted[0].Object.APIVersion,
+			Kind:       omitted[0].Object.Kind,
+			Metadata:   policyv1.ObjectMetadata{Name: "-"},
+		},
+	}
+}
+
+// formatCount formats the input count with a comma as the thousands separator.
+func formatCount(count int) string {
+	if count < 0 {
+		return "-" + formatCount(-count)
+	}
+
+	digits := strconv.Itoa(count)
+
+	var formatted strings.Builder
+
+	for i, digit := range digits {
+		if i != 0 && (len(digits)-i)%3 == 0 {
+			formatted.WriteByte(',')
+		}
+
+		formatted.WriteRune(digit)
+	}
+
+	return formatted.String()
+}
+
+// truncatedMessageSuffix is appended to the condition messages that are truncated to reduce the size of the status.
+const truncatedMessageSuffix = "... (truncated)"
+
+// boundStatusSize reduces the size of the status so that its JSON representation doesn't exceed maxBytes. The
+// condition messages and the diffs of the related objects are truncated first, and then the related objects are
+// replaced with a summary entry. The related objects that were created by the policy or that record a UID are never
+// replaced since the policy needs them to track the objects it manages, so the status may still exceed maxBytes. A
+// maxBytes value of zero or less disables the limit. It returns whether the status was changed.
+func boundStatusSize(status *policyv1.ConfigurationPolicyStatus, maxBytes int) bool {
+	statusSize := func() int {
+		statusJSON, err := json.Marshal(status)
+		if err != nil {
+			return 0
+		}
+
+		return len(statusJSON)
+	}
+
+	if maxBytes <= 0 || statusSize() <= maxBytes {
+		return false
+	}
+
+	// Shorten the messages more and more until the status fits or the messages can't be shortened any further
+	for maxMessageLength := maxBytes / 8; maxMessageLength >= 256; maxMessageLength /= 2 {
+		for i := range status.CompliancyDetails {
+			for j := range status.CompliancyDetails[i].Conditions {
+				condition := &status.CompliancyDetails[i].Conditions[j]
+
+				if len(condition.Message) > maxMessageLength {
+					condition.Message = truncateString(condition.Message, maxMessageLength-len(truncatedMessageSuffix)) +
+						truncatedMessageSuffix
+				}
+			}
+		}
+
+		for i := range status.Conditions {
+			condition := &status.Conditions[i]
+
+			if len(condition.Message) > maxMessageLength {
+				condition.Message = truncateString(condition.Message, maxMessageLength-len(truncatedMessageSuffix)) +
+					truncatedMessageSuffix
+			}
+		}
+
+		for i := range status.RelatedObjects {
+			if properties := status.RelatedObjects[i].Properties; properties != nil {
+				properties.Diff = truncateDiff(properties.Diff, maxMessageLength)
+			}
+		}
+
+		if statusSize() <= maxBytes {
+			return true
+		}
+	}
+
+	if len(status.RelatedObjects) == 0 {
+		return true
+	}
+
+	// Omit half of the remaining related objects at a time, keeping the tracked objects and then the noncompliant
+	// objects first
+	related := status.RelatedObjects
+	tracked := []policyv1.RelatedObject{}
+	untracked := []policyv1.RelatedObject{}
+	protected := map[string]bool{}
+
+	for _, object := range related {
+		if isCreatedByPolicy(object) || (object.Properties != nil && object.Properties.UID != "") {
+			tracked = append(tracked, object)
+			protected[getObjectString(object)] = true
+		} else {
+			untracked = append(untracked, object)
+		}
+	}
+
+	if len(untracked) == 0 {
+		return true
+	}
+
+	for shown := len(untracked) / 2; ; shown /= 2 {
+		if shown == 0 {
+			status.RelatedObjects = append(
+				append([]policyv1.RelatedObject{}, tracked...), omittedRelatedObjectsSummary(untracked),
+			)
+		} else {
+			status.RelatedObjects = capRelatedObjects(related, len(tracked)+shown, protected)
+		}
+
+		if statusSize() <= maxBytes || shown == 0 {
+			break
+		}
+	}
+
+	return true
+}
+
+// truncateString returns at most the first maxLength bytes of the input string without splitting a UTF-8 character.
+func truncateString(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+
+	for maxLength > 0 && !utf8.RuneStart(value[maxLength]) {
+		maxLength--
+	}
+
+	return value[:maxLength]
 }
@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 )
@@ -199,6 +201,84 @@ func TestEqualObjWithSortEmptyMap(t *testing.T) {
 	assert.False(t, equalObjWithSort(mergedObj, oldObj, false))
 }
 
+func TestScalarsEqual(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value1   interface{}
+		value2   interface{}
+		expected bool
+	}{
+		{"true", true, true},
+		{"True", true, true},
+		{"TRUE", true, true},
+		{"false", false, true},
+		{"true", false, false},
+		{"1", true, false},
+		{"0", false, false},
+		{"yes", true, false},
+		{"8080", int64(8080), true},
+		{"8080", 8080, true},
+		{"8080", int64(8081), false},
+		{"1.0", int64(1), true},
+		{"1.5", float64(1.5), true},
+		{"1.5", int64(1), false},
+		{"9007199254740993", int64(9007199254740993), true},
+		{"9007199254740993", int64(9007199254740992), false},
+		{"0x10", int64(16), false},
+		{"abc", int64(1), false},
+		{"1024Mi", "1Gi", true},
+		{"500m", float64(0.5), true},
+		{"1G", "1Gi", false},
+		{"true", "True", false},
+		{true, true, true},
+		{int64(1), float64(1), true},
+		{nil, "", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(
+			t, test.expected, scalarsEqual(test.value1, test.value2), fmt.Sprintf("%#v and %#v", test.value1, test.value2),
+		)
+		assert.Equal(
+			t, test.expected, scalarsEqual(test.value2, test.value1), fmt.Sprintf("%#v and %#v", test.value2, test.value1),
+		)
+	}
+}
+
+func TestCanonicalizeScalars(t *testing.T) {
+	t.Parallel()
+
+	merged := map[string]interface{}{
+		"hostNetwork": "True",
+		"ports":       []interface{}{map[string]interface{}{"name": "http", "containerPort": "8080"}},
+		"image":       "nginx:1.25",
+	}
+	existing := map[string]interface{}{
+		"hostNetwork": true,
+		"ports":       []interface{}{map[string]interface{}{"name": "http", "containerPort": int64(8080)}},
+		"image":       "nginx:1.24",
+	}
+
+	canonical, changed := canonicalizeScalars(merged, existing)
+	assert.True(t, changed)
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"hostNetwork": true,
+			"ports":       []interface{}{map[string]interface{}{"name": "http", "containerPort": int64(8080)}},
+			"image":       "nginx:1.25",
+		},
+		canonical,
+	)
+
+	// The input isn't modified
+	assert.Equal(t, "True", merged["hostNetwork"])
+
+	_, changed = canonicalizeScalars(existing, existing)
+	assert.False(t, changed)
+}
+
 func TestGenerateDiff(t *testing.T) {
 	t.Parallel()
 
@@ -307,3 +387,659 @@ func TestGenerateDiff(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateDiffSecret(t *testing.T) {
+	t.Parallel()
+
+	existingObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]interface{}{
+			"unchanged": "c2FtZQ==",     // same
+			"changed":   "b2xkLXZhbHVl", // old-value
+		},
+	}}
+	updatedObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]interface{}{
+			"unchanged": "c2FtZQ==",
+			"changed":   "bmV3LXZhbHVl", // new-value
+			"added":     "YWRkZWQ=",     // added
+		},
+	}}
+
+	diff, err := generateDiff(existingObj, updatedObj)
+	assert.Nil(t, err)
+
+	for _, secretValue := range []string{"c2FtZQ==", "b2xkLXZhbHVl", "bmV3LXZhbHVl", "YWRkZWQ="} {
+		assert.NotContains(t, diff, secretValue)
+	}
+
+	assert.Contains(t, diff, "added: <REDACTED>")
+	assert.Contains(t, diff, "changed: <REDACTED>")
+	assert.Contains(t, diff, "<REDACTED: changed>")
+	assert.Equal(t, 1, strings.Count(diff, "<REDACTED: changed>"), diff)
+
+	// The input objects aren't modified
+	assert.Equal(t, "YWRkZWQ=", updatedObj.Object["data"].(map[string]interface{})["added"])
+}
+
+func TestSecretStringDataToData(t *testing.T) {
+	t.Parallel()
+
+	secret := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]interface{}{
+			"username": "YWRtaW4=",         // admin
+			"password": "b3ZlcnJpZGRlbg==", // overridden
+		},
+		"stringData": map[string]interface{}{
+			"password": "s3cr3t",
+			"port":     int64(5432),
+		},
+	}}
+
+	converted := secretStringDataToData(secret)
+
+	expected := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]interface{}{
+			"username": "YWRtaW4=",
+			"password": "czNjcjN0", // s3cr3t
+			"port":     "NTQzMg==", // 5432
+		},
+	}
+	assert.Equal(t, expected, converted.Object)
+
+	// The input object isn't modified
+	assert.Contains(t, secret.Object, "stringData")
+
+	configMap := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"stringData": map[string]interface{}{"key": "value"},
+	}}
+	assert.Equal(t, configMap, secretStringDataToData(configMap))
+}
+
+func TestRenderCustomMessage(t *testing.T) {
+	t.Parallel()
+
+	namespaceToEvent := map[string]*objectTmplEvalResultWithEvent{
+		"ns2": {result: objectTmplEvalResult{objectNames: []string{"cm1"}, namespace: "ns2"}},
+		"ns1": {result: objectTmplEvalResult{objectNames: []string{"cm1"}, namespace: "ns1"}},
+	}
+
+	tests := []struct {
+		testDescription string
+		customMessage   policyv1.CustomMessage
+		compliant       bool
+		expected        string
+	}{
+		{
+			"No custom message",
+			policyv1.CustomMessage{},
+			false,
+			"default message",
+		},
+		{
+			"Only a compliant message",
+			policyv1.CustomMessage{Compliant: "All good"},
+			false,
+			"default message",
+		},
+		{
+			"Append to the default message",
+			policyv1.CustomMessage{NonCompliant: "{{ .DefaultMessage }}; see the runbook"},
+			false,
+			"default message; see the runbook",
+		},
+		{
+			"Reference the objects and namespaces",
+			policyv1.CustomMessage{
+				Compliant: `{{ range .Objects }}{{ .Namespace }}/{{ .Name }} {{ end }}in {{ join ", " .Namespaces }}`,
+			},
+			true,
+			"ns1/cm1 ns2/cm1 in ns1, ns2",
+		},
+		{
+			"Invalid template",
+			policyv1.CustomMessage{NonCompliant: "{{ .DefaultMessage "},
+			false,
+			"default message (warning: the spec.customMessage template failed to render: " +
+				"template: customMessage:1: unclosed action)",
+		},
+		{
+			"The environment of the controller isn't available",
+			policyv1.CustomMessage{NonCompliant: `{{ env "HOME" }}`},
+			false,
+			"default message (warning: the spec.customMessage template failed to render: " +
+				`template: customMessage:1: function "env" not defined)`,
+		},
+		{
+			"The host names aren't resolved",
+			policyv1.CustomMessage{NonCompliant: `{{ getHostByName "example.com" }}`},
+			false,
+			"default message (warning: the spec.customMessage template failed to render: " +
+				`template: customMessage:1: function "getHostByName" not defined)`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.testDescription, func(t *testing.T) {
+			t.Parallel()
+
+			policy := &policyv1.ConfigurationPolicy{
+				Spec: &policyv1.ConfigurationPolicySpec{CustomMessage: test.customMessage},
+			}
+
+			actual := renderCustomMessage(policy, test.compliant, "default message", namespaceToEvent)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestParseIgnoreFields(t *testing.T) {
+	t.Parallel()
+
+	paths, err := parseIgnoreFields([]string{"/spec/replicas", "/metadata/annotations/example.com~1foo~0bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"spec", "replicas"}, {"metadata", "annotations", "example.com/foo~bar"}}, paths)
+
+	for _, invalid := range []string{"spec/replicas", "/spec//replicas", "/spec/", "/", "/metadata/name", "/kind"} {
+		_, err := parseIgnoreFields([]string{invalid})
+		assert.NotNil(t, err, "expected an error for "+invalid)
+	}
+}
+
+func TestRestoreIgnoredFields(t *testing.T) {
+	t.Parallel()
+
+	original := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{"foo": "webhook"},
+		},
+		"spec": map[string]interface{}{"replicas": int64(5), "paused": false},
+	}}
+	merged := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{},
+		"spec":     map[string]interface{}{"replicas": int64(1), "paused": true, "extra": "value"},
+	}}
+	paths := [][]string{{"spec", "replicas"}, {"metadata", "annotations", "foo"}, {"spec", "extra"}}
+
+	restoreIgnoredFields(merged, original, paths)
+
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{"foo": "webhook"},
+		},
+		"spec": map[string]interface{}{"replicas": int64(5), "paused": true},
+	}
+	assert.Equal(t, expected, merged.Object)
+
+	removeIgnoredFields(merged, paths)
+
+	expected = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{},
+		},
+		"spec": map[string]interface{}{"paused": true},
+	}
+	assert.Equal(t, expected, merged.Object)
+}
+
+func TestDedupeUnorderedLists(t *testing.T) {
+	t.Parallel()
+
+	paths, err := parseUnorderedLists([]string{"/spec/containers/*/args", "/spec/tolerations", "/spec/missing"})
+	assert.Nil(t, err)
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "a", "args": []interface{}{"-v", "-x", "-v"}},
+				map[string]interface{}{"name": "b"},
+			},
+			"tolerations": []interface{}{
+				map[string]interface{}{"key": "k", "effect": "NoSchedule"},
+				map[string]interface{}{"effect": "NoSchedule", "key": "k"},
+			},
+		},
+	}}
+	original := obj.DeepCopy()
+
+	dedupeUnorderedLists(obj, paths)
+
+	expected := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "a", "args": []interface{}{"-v", "-x"}},
+				map[string]interface{}{"name": "b"},
+			},
+			"tolerations": []interface{}{
+				map[string]interface{}{"key": "k", "effect": "NoSchedule"},
+			},
+		},
+	}
+	assert.Equal(t, expected, obj.Object)
+
+	// The lists are replaced rather than modified in place
+	assert.Len(t, original.Object["spec"].(map[string]interface{})["tolerations"], 2)
+
+	for _, invalid := range []string{"spec/tolerations", "/spec//tolerations", "/"} {
+		_, err := parseUnorderedLists([]string{invalid})
+		assert.NotNil(t, err, "expected an error for "+invalid)
+	}
+}
+
+func TestAlignUnorderedLists(t *testing.T) {
+	t.Parallel()
+
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "a", "image": "a:1", "env": []interface{}{"X", "Y", "Z"},
+				},
+				map[string]interface{}{"name": "b", "image": "b:1"},
+			},
+			"finalizers": []interface{}{"one", "two", "three"},
+		},
+	}}
+	desired := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "b", "image": "b:2"},
+				map[string]interface{}{
+					"name": "a", "image": "a:1", "env": []interface{}{"Z", "Y", "X"},
+				},
+			},
+			"finalizers": []interface{}{"four", "three", "one", "two"},
+		},
+	}}
+	paths, err := parseUnorderedLists([]string{"/spec/containers", "/spec/containers/*/env", "/spec/finalizers"})
+	assert.Nil(t, err)
+
+	merged := existing.DeepCopy()
+	existingCopy := existing.DeepCopy()
+
+	_, _, updateNeeded, _ := handleKeys(
+		desired, merged, existingCopy, "mustonlyhave", metadataComplianceTypes{}, nil, true,
+	)
+	assert.True(t, updateNeeded)
+
+	alignUnorderedLists(merged, existing, paths)
+
+	diff, err := generateDiff(existing, merged)
+	assert.Nil(t, err)
+
+	// Only the changed image and the added finalizer are in the diff, not the reordered items
+	assert.Equal(t, 2, strings.Count(diff, "\n+ "), diff)
+	assert.Equal(t, 1, strings.Count(diff, "\n- "), diff)
+	assert.Contains(t, diff, "image: b:2")
+	assert.Contains(t, diff, "- four")
+
+	expected := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "a", "image": "a:1", "env": []interface{}{"X", "Y", "Z"},
+				},
+				map[string]interface{}{"name": "b", "image": "b:2"},
+			},
+			"finalizers": []interface{}{"one", "two", "three", "four"},
+		},
+	}
+	assert.Equal(t, expected, merged.Object)
+}
+
+func TestHandleKeysListMergeKeys(t *testing.T) {
+	t.Parallel()
+
+	mergeKeys, err := parseListMergeKeys(map[string]string{
+		"/spec/template/spec/containers":  "name",
+		"/spec/template/spec/tolerations": "",
+	})
+	assert.Nil(t, err)
+
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "istio-proxy", "image": "proxy:1"},
+				map[string]interface{}{"name": "app", "image": "app:1", "terminationMessagePath": "/dev/log"},
+			},
+			"tolerations": []interface{}{
+				map[string]interface{}{"key": "a", "effect": "NoSchedule"},
+			},
+		}}},
+	}}
+	desired := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:2"},
+			},
+			"tolerations": []interface{}{
+				map[string]interface{}{"key": "b", "effect": "NoSchedule"},
+			},
+		}}},
+	}}
+
+	tests := map[string]struct {
+		complianceType      string
+		expectedContainers  []interface{}
+		expectedTolerations []interface{}
+	}{
+		"musthave keeps the extra items": {
+			complianceType: "musthave",
+			expectedContainers: []interface{}{
+				map[string]interface{}{"name": "istio-proxy", "image": "proxy:1"},
+				map[string]interface{}{"name": "app", "image": "app:2", "terminationMessagePath": "/dev/log"},
+			},
+			expectedTolerations: []interface{}{
+				map[string]interface{}{"key": "a", "effect": "NoSchedule"},
+				map[string]interface{}{"key": "b", "effect": "NoSchedule"},
+			},
+		},
+		"mustonlyhave removes the extra items": {
+			complianceType: "mustonlyhave",
+			expectedContainers: []interface{}{
+				map[string]interface{}{"name": "app", "image": "app:2"},
+			},
+			expectedTolerations: []interface{}{
+				map[string]interface{}{"key": "b", "effect": "NoSchedule"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			merged := existing.DeepCopy()
+
+			_, _, updateNeeded, _ := handleKeys(
+				desired, merged, existing.DeepCopy(), test.complianceType, metadataComplianceTypes{}, mergeKeys, true,
+			)
+			assert.True(t, updateNeeded)
+
+			containers, _, _ := unstructured.NestedSlice(merged.Object, "spec", "template", "spec", "containers")
+			assert.Equal(t, test.expectedContainers, containers)
+
+			tolerations, _, _ := unstructured.NestedSlice(merged.Object, "spec", "template", "spec", "tolerations")
+			assert.Equal(t, test.expectedTolerations, tolerations)
+
+			// The desired object isn't modified by the merge
+			containers, _, _ = unstructured.NestedSlice(desired.Object, "spec", "template", "spec", "containers")
+			assert.Equal(t, []interface{}{map[string]interface{}{"name": "app", "image": "app:2"}}, containers)
+		})
+	}
+
+	for _, invalid := range []string{"spec/containers", "/spec/containers/*/env", "/"} {
+		_, err := parseListMergeKeys(map[string]string{invalid: "name"})
+		assert.NotNil(t, err, "expected an error for "+invalid)
+	}
+}
+
+func TestSummarizeRelatedObjects(t *testing.T) {
+	t.Parallel()
+
+	related := []policyv1.RelatedObject{
+		{Compliant: string(policyv1.Compliant)},
+		{Compliant: string(policyv1.NonCompliant)},
+		{Compliant: string(policyv1.Compliant)},
+		{Compliant: string(policyv1.UnknownCompliancy)},
+	}
+
+	expected := &policyv1.TemplateSummary{Matched: 4, Compliant: 2, NonCompliant: 1}
+	assert.Equal(t, expected, summarizeRelatedObjects(related))
+	assert.Equal(t, &policyv1.TemplateSummary{}, summarizeRelatedObjects(nil))
+}
+
+func TestExpandListTemplates(t *testing.T) {
+	t.Parallel()
+
+	configMap := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"}}`
+	secret := `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"secret"}}`
+
+	objTemps := []*policyv1.ObjectTemplate{
+		{
+			ComplianceType:   "mustnothave",
+			ObjectDefinition: runtime.RawExtension{Raw: []byte(configMap)},
+		},
+		{
+			ComplianceType:   "musthave",
+			ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"List","items":[]}`)},
+		},
+		{
+			ComplianceType: "mustonlyhave",
+			ObjectDefinition: runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"v1","kind":"List","items":[` + configMap + `,` + secret + `]}`),
+			},
+		},
+	}
+
+	expanded, err := expandListTemplates(objTemps)
+	assert.NoError(t, err)
+	assert.Len(t, expanded, 3)
+
+	assert.Equal(t, objTemps[0], expanded[0])
+	assert.JSONEq(t, configMap, string(expanded[1].ObjectDefinition.Raw))
+	assert.Equal(t, policyv1.ComplianceType("mustonlyhave"), expanded[1].ComplianceType)
+	assert.JSONEq(t, secret, string(expanded[2].ObjectDefinition.Raw))
+	assert.Equal(t, policyv1.ComplianceType("mustonlyhave"), expanded[2].ComplianceType)
+
+	nested := []*policyv1.ObjectTemplate{{
+		ComplianceType: "musthave",
+		ObjectDefinition: runtime.RawExtension{
+			Raw: []byte(`{"apiVersion":"v1","kind":"List","items":[{"apiVersion":"v1","kind":"List"}]}`),
+		},
+	}}
+
+	_, err = expandListTemplates(nested)
+	assert.ErrorContains(t, err, "object-templates[0]: the List item at index 0 is a List, which is not supported")
+}
+
+func TestPolicyFieldManager(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		base       string
+		policyName string
+		expected   string
+	}{
+		"default base":      {"", "my-policy", "config-policy-controller/my-policy"},
+		"custom base":       {"audit", "my-policy", "audit/my-policy"},
+		"no policy name":    {"audit", "", "audit"},
+		"sanitized name":    {"audit", "my:policy/v1", "audit/my-policy-v1"},
+		"truncated manager": {"audit", strings.Repeat("a", 200), "audit/" + strings.Repeat("a", 122)},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, policyFieldManager(test.base, test.policyName))
+		})
+	}
+}
+
+func TestCapRelatedObjects(t *testing.T) {
+	t.Parallel()
+
+	related := []policyv1.RelatedObject{}
+
+	for i := 0; i < 6; i++ {
+		compliant := string(policyv1.Compliant)
+		if i%3 == 0 {
+			compliant = string(policyv1.NonCompliant)
+		}
+
+		related = append(related, policyv1.RelatedObject{
+			Compliant: compliant,
+			Object: policyv1.ObjectResource{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Metadata:   policyv1.ObjectMetadata{Name: fmt.Sprintf("cm%d", 5-i), Namespace: "default"},
+			},
+		})
+	}
+
+	assert.Equal(t, related, capRelatedObjects(related, 0, nil))
+	assert.Equal(t, related, capRelatedObjects(related, 6, nil))
+
+	protected := map[string]bool{"ConfigMap.v1/default/cm3": true}
+
+	capped := capRelatedObjects(related, 3, protected)
+	names := []string{}
+
+	for _, object := range capped {
+		names = append(names, object.Object.Metadata.Name)
+	}
+
+	// The protected object is first, followed by the noncompliant objects and then the compliant objects
+	assert.Equal(t, []string{"cm3", "cm2", "cm5", "-"}, names)
+	assert.Equal(t, string(policyv1.Compliant), capped[3].Compliant)
+	assert.Equal(t, "and 3 more objects (3 compliant / 0 noncompliant)", capped[3].Reason)
+
+	capped = capRelatedObjects(related, 1, nil)
+	assert.Len(t, capped, 2)
+	assert.Equal(t, "cm2", capped[0].Object.Metadata.Name)
+	assert.Equal(t, string(policyv1.NonCompliant), capped[1].Compliant)
+	assert.Equal(t, "and 5 more objects (4 compliant / 1 noncompliant)", capped[1].Reason)
+}
+
+func TestFormatCount(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "0", formatCount(0))
+	assert.Equal(t, "999", formatCount(999))
+	assert.Equal(t, "4,321", formatCount(4321))
+	assert.Equal(t, "1,234,567", formatCount(1234567))
+	assert.Equal(t, "-1,000", formatCount(-1000))
+}
+
+func TestBoundStatusSize(t *testing.T) {
+	t.Parallel()
+
+	status := policyv1.ConfigurationPolicyStatus{
+		CompliancyDetails: []policyv1.TemplateStatus{{
+			Conditions: []policyv1.Condition{{Message: strings.Repeat("configmaps [cm] found; ", 1000)}},
+		}},
+	}
+
+	related := func() []policyv1.RelatedObject {
+		objects := []policyv1.RelatedObject{}
+
+		for i := 0; i < 1000; i++ {
+			objects = append(objects, policyv1.RelatedObject{
+				Compliant: string(policyv1.Compliant),
+				Object: policyv1.ObjectResource{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+					Metadata:   policyv1.ObjectMetadata{Name: fmt.Sprintf("cm%d", i), Namespace: "default"},
+				},
+			})
+		}
+
+		return objects
+	}
+
+	status.RelatedObjects = related()
+
+	assert.False(t, boundStatusSize(&status, 0))
+
+	maxBytes := 16 * 1024
+
+	assert.True(t, boundStatusSize(&status, maxBytes))
+
+	statusJSON, err := json.Marshal(status)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(statusJSON), maxBytes)
+	assert.True(t, strings.HasSuffix(status.CompliancyDetails[0].Conditions[0].Message, truncatedMessageSuffix))
+
+	summary := status.RelatedObjects[len(status.RelatedObjects)-1]
+	assert.Equal(t, "-", summary.Object.Metadata.Name)
+	assert.Contains(t, summary.Reason, "more objects")
+
+	assert.False(t, boundStatusSize(&status, maxBytes))
+
+	// The related objects the policy tracks are kept even when all the others are omitted
+	createdByPolicy := true
+	status.RelatedObjects = append(related(), policyv1.RelatedObject{
+		Compliant:  string(policyv1.Compliant),
+		Object:     policyv1.ObjectResource{Kind: "ConfigMap", Metadata: policyv1.ObjectMetadata{Name: "created"}},
+		Properties: &policyv1.ObjectProperties{CreatedByPolicy: &createdByPolicy},
+	}, policyv1.RelatedObject{
+		Compliant:  string(policyv1.Compliant),
+		Object:     policyv1.ObjectResource{Kind: "ConfigMap", Metadata: policyv1.ObjectMetadata{Name: "with-uid"}},
+		Properties: &policyv1.ObjectProperties{UID: "1234"},
+	})
+
+	assert.True(t, boundStatusSize(&status, 1024))
+
+	names := []string{}
+
+	for _, object := range status.RelatedObjects {
+		names = append(names, object.Object.Metadata.Name)
+	}
+
+	assert.Equal(t, []string{"created", "with-uid", "-"}, names)
+	assert.Equal(t, "and 1,000 more objects (1,000 compliant / 0 noncompliant)", status.RelatedObjects[2].Reason)
+}
+
+func TestHandleKeysSeparateMetadataComplianceTypes(t *testing.T) {
+	t.Parallel()
+
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":        "my-configmap",
+			"labels":      map[string]interface{}{"app": "a", "extra": "label"},
+			"annotations": map[string]interface{}{"injected": "by-controller", "owner": "team-a"},
+		},
+	}}
+	desired := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":        "my-configmap",
+			"labels":      map[string]interface{}{"app": "a"},
+			"annotations": map[string]interface{}{"owner": "team-a"},
+		},
+	}}
+
+	// The extra annotation is tolerated but the extra label isn't
+	mdCompTypes := metadataComplianceTypes{labels: "mustonlyhave", annotations: "musthave"}
+
+	merged := existing.DeepCopy()
+	_, _, updateNeeded, _ := handleKeys(desired, merged, existing.DeepCopy(), "musthave", mdCompTypes, nil, true)
+	assert.True(t, updateNeeded)
+	assert.Equal(t, map[string]string{"app": "a"}, merged.GetLabels())
+	assert.Equal(t, map[string]string{"injected": "by-controller", "owner": "team-a"}, merged.GetAnnotations())
+
+	// Only the annotations are mustonlyhave through the metadataComplianceType, which the labels override
+	mdCompTypes = metadataComplianceTypes{metadata: "mustonlyhave", labels: "musthave"}
+
+	merged = existing.DeepCopy()
+	_, _, updateNeeded, _ = handleKeys(desired, merged, existing.DeepCopy(), "musthave", mdCompTypes, nil, true)
+	assert.True(t, updateNeeded)
+	assert.Equal(t, map[string]string{"app": "a", "extra": "label"}, merged.GetLabels())
+	assert.Equal(t, map[string]string{"owner": "team-a"}, merged.GetAnnotations())
+
+	// Both maps tolerate the extra entries
+	mdCompTypes = metadataComplianceTypes{labels: "musthave", annotations: "musthave"}
+
+	merged = existing.DeepCopy()
+	_, _, updateNeeded, _ = handleKeys(desired, merged, existing.DeepCopy(), "mustonlyhave", mdCompTypes, nil, true)
+	assert.False(t, updateNeeded)
+}
@@ -0,0 +1,80 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// deletionRecordLabel starts every deletion record so that it's not mistaken for a diff.
+const deletionRecordLabel = "# Deletion record"
+
+// deletionRecord returns the record of the object that a mustnothave object template is about to delete, with the
+// kind, name, namespace, and labels of the object. When the object template records its diff, the record also has the
+// YAML of the object with the Secret values redacted as in the diffs, unless the diff of the object is suppressed.
+func deletionRecord(obj singleObject, objectT *policyv1.ObjectTemplate) (string, error) {
+	if obj.existingObj == nil {
+		return "", nil
+	}
+
+	var record strings.Builder
+
+	record.WriteString(deletionRecordLabel + "\n")
+	record.WriteString("# kind: " + obj.existingObj.GetKind() + "\n")
+	record.WriteString("# name: " + obj.existingObj.GetName() + "\n")
+
+	if obj.existingObj.GetNamespace() != "" {
+		record.WriteString("# namespace: " + obj.existingObj.GetNamespace() + "\n")
+	}
+
+	if objLabels := obj.existingObj.GetLabels(); len(objLabels) != 0 {
+		record.WriteString("# labels: " + labels.Set(objLabels).String() + "\n")
+	}
+
+	if objectT.RecordDiff != policyv1.RecordDiffLog {
+		return record.String(), nil
+	}
+
+	if note := diffSuppressedNote(obj, objectT); note != "" {
+		record.WriteString("# " + note + "\n")
+
+		return record.String(), nil
+	}
+
+	deletedObj := obj.existingObj.DeepCopy()
+	removeFieldsForComparison(deletedObj)
+
+	if isSecret(deletedObj) {
+		deletedObj, _ = redactSecretValues(deletedObj, deletedObj)
+	}
+
+	deletedYAML, err := yaml.Marshal(deletedObj.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the deleted object to YAML for the deletion record: %w", err)
+	}
+
+	record.Write(deletedYAML)
+
+	return record.String(), nil
+}
+
+// recordDeletion writes the deletion record of the object deleted by the policy to the diff log, or the controller
+// log, and records it for the compliance event of the policy evaluation.
+func (r *ConfigurationPolicyReconciler) recordDeletion(obj singleObject, record string) {
+	if record == "" {
+		return
+	}
+
+	log := log.WithValues(
+		"policy", obj.policy.Name, "name", obj.name, "namespace", obj.namespace, "resource", obj.gvr.Resource,
+	)
+
+	log.Info(r.deletionLogMessage(obj, record))
+	r.recordEventDeletion(obj, record)
+}
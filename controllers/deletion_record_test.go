@@ -0,0 +1,132 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func newDeletedSecret() singleObject {
+	return singleObject{
+		policy: &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}},
+		existingObj: &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":          "creds",
+				"namespace":     "default",
+				"labels":        map[string]interface{}{"tier": "web", "app": "web"},
+				"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			},
+			"data": map[string]interface{}{"password": "c2VjcmV0"}, // secret
+		}},
+		name:      "creds",
+		namespace: "default",
+	}
+}
+
+func TestDeletionRecord(t *testing.T) {
+	t.Parallel()
+
+	summary := deletionRecordLabel + "\n# kind: Secret\n# name: creds\n# namespace: default\n# labels: app=web,tier=web\n"
+
+	tests := map[string]struct {
+		recordDiff policyv1.RecordDiff
+		annotation string
+		expected   func(t *testing.T, record string)
+	}{
+		"summary only": {
+			recordDiff: policyv1.RecordDiffNone,
+			expected: func(t *testing.T, record string) {
+				assert.Equal(t, summary, record)
+			},
+		},
+		"censored": {
+			recordDiff: policyv1.RecordDiffCensored,
+			expected: func(t *testing.T, record string) {
+				assert.Equal(t, summary, record)
+			},
+		},
+		"diff disabled on the object": {
+			recordDiff: policyv1.RecordDiffLog,
+			annotation: "true",
+			expected: func(t *testing.T, record string) {
+				assert.True(t, strings.HasPrefix(record, summary))
+				assert.Contains(t, record, "# diff suppressed by the "+disableDiffAnnotation+" annotation")
+				assert.NotContains(t, record, "password")
+			},
+		},
+		"redacted YAML": {
+			recordDiff: policyv1.RecordDiffLog,
+			expected: func(t *testing.T, record string) {
+				assert.True(t, strings.HasPrefix(record, summary))
+				assert.Contains(t, record, "\nkind: Secret\n")
+				assert.Contains(t, record, "  password: ")
+				assert.Contains(t, record, redactedSecretValue)
+				assert.NotContains(t, record, "c2VjcmV0")
+				assert.NotContains(t, record, "managedFields")
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := newDeletedSecret()
+
+			if test.annotation != "" {
+				obj.existingObj.SetAnnotations(map[string]string{disableDiffAnnotation: test.annotation})
+			}
+
+			record, err := deletionRecord(obj, &policyv1.ObjectTemplate{RecordDiff: test.recordDiff})
+			assert.NoError(t, err)
+			test.expected(t, record)
+		})
+	}
+}
+
+func TestRecordDeletion(t *testing.T) {
+	t.Parallel()
+
+	obj := newDeletedSecret()
+
+	path := filepath.Join(t.TempDir(), "diffs.log")
+
+	diffLog, err := NewDiffLogWriter(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer diffLog.Close()
+
+	r := &ConfigurationPolicyReconciler{MaxEventDiffBytes: 1024, DiffLog: diffLog}
+
+	record := deletionRecordLabel + "\n# kind: Secret\n# name: creds\n"
+	r.recordDeletion(obj, record)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"namespace":"default","deletion":true}`)
+	assert.True(t, strings.HasSuffix(string(content), "\n"+record))
+
+	// The deletion records are in the compliance event even when the policy is compliant
+	obj.policy.Status.ComplianceState = policyv1.Compliant
+
+	assert.Equal(
+		t,
+		"; deletion record of Secret default/creds:\n"+strings.TrimRight(record, "\n"),
+		r.eventDiffsMessage(obj.policy),
+	)
+}
@@ -0,0 +1,117 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// maxExactFloatInteger is the largest integer that a float64 represents exactly.
+const maxExactFloatInteger = 1 << 53
+
+// canonicalDiffValue returns a copy of the object value in a canonical form so that both sides of a JSON patch diff
+// are compared the same way and only the values that genuinely changed show up in the diff. Maps of any type become
+// map[string]interface{}, typed slices become []interface{}, and numbers become int64 when they are integers and
+// float64 otherwise. This avoids spurious changes when, for example, the existing object was decoded with float64
+// numbers and the updated object has int64 numbers.
+func canonicalDiffValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		canonical := make(map[string]interface{}, len(typed))
+
+		for key, val := range typed {
+			canonical[key] = canonicalDiffValue(val)
+		}
+
+		return canonical
+	case []interface{}:
+		canonical := make([]interface{}, len(typed))
+
+		for i, val := range typed {
+			canonical[i] = canonicalDiffValue(val)
+		}
+
+		return canonical
+	case string, bool, int64:
+		return typed
+	case float64:
+		return canonicalFloat(typed)
+	case json.Number:
+		if integer, err := typed.Int64(); err == nil {
+			return integer
+		}
+
+		if float, err := typed.Float64(); err == nil {
+			return canonicalFloat(float)
+		}
+
+		return typed.String()
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() <= math.MaxInt64 {
+			return int64(rv.Uint())
+		}
+
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return canonicalFloat(rv.Float())
+	case reflect.Map:
+		canonical := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+
+		for iter.Next() {
+			canonical[fmt.Sprint(iter.Key().Interface())] = canonicalDiffValue(iter.Value().Interface())
+		}
+
+		return canonical
+	case reflect.Slice, reflect.Array:
+		// Byte slices are marshalled as base64 strings, so they're kept as is
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+
+		canonical := make([]interface{}, rv.Len())
+
+		for i := 0; i < rv.Len(); i++ {
+			canonical[i] = canonicalDiffValue(rv.Index(i).Interface())
+		}
+
+		return canonical
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+
+		return canonicalDiffValue(rv.Elem().Interface())
+	}
+
+	return value
+}
+
+// canonicalFloat returns the float as an int64 when it's an integer that the float represents exactly.
+func canonicalFloat(float float64) interface{} {
+	if float == math.Trunc(float) && math.Abs(float) <= maxExactFloatInteger {
+		return int64(float)
+	}
+
+	return float
+}
+
+// canonicalDiffObject returns the canonical form of the object's content for a JSON patch diff. The unified diff and
+// the deletion record don't need it since the YAML marshalling already serializes equal values the same way.
+func canonicalDiffObject(object map[string]interface{}) map[string]interface{} {
+	canonical, _ := canonicalDiffValue(object).(map[string]interface{})
+
+	return canonical
+}
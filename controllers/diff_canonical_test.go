@@ -0,0 +1,134 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCanonicalDiffValue(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{
+		"typedMap":   map[int]string{1: "one"},
+		"typedSlice": []string{"a", "b"},
+		"int32":      int32(3),
+		"uint":       uint(4),
+		"float":      float64(5),
+		"fraction":   float32(1.5),
+		"number":     json.Number("6"),
+		"bytes":      []byte("hi"),
+		"nested":     []map[string]int{{"port": 8080}},
+	}
+
+	expected := map[string]interface{}{
+		"typedMap":   map[string]interface{}{"1": "one"},
+		"typedSlice": []interface{}{"a", "b"},
+		"int32":      int64(3),
+		"uint":       int64(4),
+		"float":      int64(5),
+		"fraction":   float64(1.5),
+		"number":     int64(6),
+		"bytes":      []byte("hi"),
+		"nested":     []interface{}{map[string]interface{}{"port": int64(8080)}},
+	}
+
+	assert.Equal(t, expected, canonicalDiffValue(value))
+}
+
+// newCanonicalDiffObjects returns the same Deployment built in different ways, with only the image changed in the
+// updated object.
+func newCanonicalDiffObjects() (existing, updated *unstructured.Unstructured) {
+	existing = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"tier": "web", "app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": float64(2),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "web",
+							"image": "web:1",
+							"ports": []interface{}{map[string]interface{}{"containerPort": float64(8080)}},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	updated = &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []map[string]interface{}{
+						{"ports": []map[string]int32{{"containerPort": 8080}}, "image": "web:2", "name": "web"},
+					},
+				},
+			},
+			"replicas": int32(2),
+		},
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{"app": "web", "tier": "web"},
+			"name":   "web",
+		},
+		"kind":       "Deployment",
+		"apiVersion": "apps/v1",
+	}}
+
+	return existing, updated
+}
+
+func TestGenerateDiffCanonical(t *testing.T) {
+	t.Parallel()
+
+	existing, updated := newCanonicalDiffObjects()
+
+	diff, err := generateDiff(existing, updated)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"--- web : existing\n+++ web : updated\n@@ -12,3 +12,3 @@\n       containers:\n"+
+			"-      - image: web:1\n+      - image: web:2\n         name: web\n",
+		diff,
+	)
+}
+
+func TestGenerateJSONPatchDiffCanonical(t *testing.T) {
+	t.Parallel()
+
+	existing, updated := newCanonicalDiffObjects()
+
+	patch, err := generateJSONPatchDiff(existing, updated)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"op":"replace","path":"/spec/template/spec/containers/0/image","value":"web:2"}]`, patch)
+}
+
+func TestGenerateJSONPatchDiffNumberTypes(t *testing.T) {
+	t.Parallel()
+
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": float64(2), "ports": []interface{}{float64(80)}},
+	}}
+	updated := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"replicas": int64(2), "ports": []int32{80}},
+	}}
+
+	// Without the canonical form, the values are compared by their Go types and are all replaced
+	operations, err := jsonPatchOperations("", existing.Object, updated.Object)
+	assert.NoError(t, err)
+	assert.Len(t, operations, 2)
+
+	patch, err := generateJSONPatchDiff(existing, updated)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[]`, patch)
+}
@@ -0,0 +1,151 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// defaultDiffContextLines is the number of unchanged lines shown around each change in the diffs by default
+const defaultDiffContextLines = 1
+
+// hunkHeaderRegex matches the header of a unified diff hunk (e.g. '@@ -2,3 +2,3 @@') and captures the start lines
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@$`)
+
+// objectDiff returns the diff between the objects in the format of the object template. The unified diffs use the
+// number of context lines and the hunk paths configured on the reconciler.
+func (r *ConfigurationPolicyReconciler) objectDiff(
+	existingObj, updatedObj *unstructured.Unstructured, format policyv1.DiffFormat,
+) (string, error) {
+	contextLines := r.DiffContextLines
+	if contextLines <= 0 {
+		contextLines = defaultDiffContextLines
+	}
+
+	return generateDiffInFormat(existingObj, updatedObj, format, contextLines, r.DiffHunkPaths)
+}
+
+// withHunkPaths appends the YAML path of the first changed line of each hunk to the hunk header, similar to the
+// function context of a Git diff (e.g. '@@ -12,3 +12,3 @@ spec.template.spec.containers[0]'). The existing and
+// updated lines are the YAML lines that the diff was generated from.
+func withHunkPaths(diff string, existingLines, updatedLines []string) string {
+	lines := strings.Split(diff, "\n")
+
+	for i, line := range lines {
+		matches := hunkHeaderRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		// The regular expression only matches digits
+		existingStart, _ := strconv.Atoi(matches[1])
+		updatedStart, _ := strconv.Atoi(matches[2])
+
+		// The start lines are one based, except that they're zero when the hunk has no lines on that side
+		existingIndex := existingStart - 1
+		updatedIndex := updatedStart - 1
+
+		if existingStart == 0 {
+			existingIndex = 0
+		}
+
+		if updatedStart == 0 {
+			updatedIndex = 0
+		}
+
+		path := ""
+
+		// Skip to the first changed line of the hunk to determine its path in the object it's from
+		for _, hunkLine := range lines[i+1:] {
+			if strings.HasPrefix(hunkLine, "-") {
+				path = yamlPath(existingLines, existingIndex)
+
+				break
+			}
+
+			if strings.HasPrefix(hunkLine, "+") {
+				path = yamlPath(updatedLines, updatedIndex)
+
+				break
+			}
+
+			existingIndex++
+			updatedIndex++
+		}
+
+		if path != "" {
+			lines[i] = line + " " + path
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// yamlPath returns the path of the parent keys of the line at the index in the YAML lines, with the index of the
+// list items (e.g. 'spec.containers[1].env'). The YAML must use the formatting of sigs.k8s.io/yaml, where the items
+// of a list are at the same indentation as the key of the list. An empty string is returned for a top level line.
+func yamlPath(lines []string, index int) string {
+	if index < 0 || index >= len(lines) {
+		return ""
+	}
+
+	segments := []string{}
+
+	// The indentation of the line whose parents are searched for, and the indentation of the list and the index of
+	// the list item that contain it, when it's in a list
+	indent, listIndent, itemIndex := yamlIndentation(lines[index]), -1, 0
+
+	if strings.HasPrefix(strings.TrimLeft(lines[index], " "), "-") {
+		indent, listIndent = indent+1, indent
+	}
+
+	for i := index - 1; i >= 0 && indent > 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		lineIndent := yamlIndentation(lines[i])
+
+		if trimmed == "" || lineIndent >= indent {
+			continue
+		}
+
+		content, isItem := strings.CutPrefix(trimmed, "- ")
+
+		// The previous items of the same list
+		if isItem && lineIndent == listIndent {
+			itemIndex++
+
+			continue
+		}
+
+		// The key of the list can be the first key of a list item (e.g. '- env:' followed by '  - name: A')
+		if !isItem || lineIndent+2 < indent {
+			key, _, _ := strings.Cut(content, ":")
+
+			if listIndent != -1 {
+				key += fmt.Sprintf("[%d]", itemIndex)
+			}
+
+			segments = append([]string{key}, segments...)
+			listIndent = -1
+		}
+
+		if isItem {
+			indent, listIndent, itemIndex = lineIndent+1, lineIndent, 0
+		} else {
+			indent = lineIndent
+		}
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// yamlIndentation returns the number of spaces that the YAML line is indented by.
+func yamlIndentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
@@ -0,0 +1,96 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestYAMLPath(t *testing.T) {
+	t.Parallel()
+
+	lines := difflib.SplitLines(`apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - env:
+    - name: A
+      value: x
+    image: nginx
+    name: one
+  - image: busybox
+    name: two
+`)
+
+	expected := []string{
+		"",
+		"",
+		"",
+		"spec",
+		"spec.containers[0]",
+		"spec.containers[0].env[0]",
+		"spec.containers[0].env[0]",
+		"spec.containers[0]",
+		"spec.containers[0]",
+		"spec.containers[1]",
+		"spec.containers[1]",
+	}
+
+	for i, path := range expected {
+		assert.Equal(t, path, yamlPath(lines, i), "line %d: %s", i, lines[i])
+	}
+
+	assert.Equal(t, "", yamlPath(lines, len(lines)+1))
+}
+
+func diffContextTestPod(envValue, image string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"env":   []interface{}{map[string]interface{}{"name": "A", "value": envValue}},
+					"image": "nginx",
+					"name":  "one",
+				},
+				map[string]interface{}{"image": image, "name": "two"},
+			},
+		},
+	}}
+}
+
+func TestGenerateDiffWithContext(t *testing.T) {
+	t.Parallel()
+
+	existing := diffContextTestPod("old", "busybox")
+	updated := diffContextTestPod("new", "busybox:2")
+
+	// The default diff is unchanged
+	diff, err := generateDiffWithContext(existing, updated, defaultDiffContextLines, false)
+	assert.NoError(t, err)
+
+	defaultDiff, err := generateDiff(existing, updated)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultDiff, diff)
+	assert.Contains(t, diff, "@@ -6,6 +6,6 @@\n")
+
+	diff, err = generateDiffWithContext(existing, updated, 0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "---  : existing\n+++  : updated\n"+
+		"@@ -7 +7 @@ spec.containers[0].env[0]\n"+
+		"-      value: old\n"+
+		"+      value: new\n"+
+		"@@ -10 +10 @@ spec.containers[1]\n"+
+		"-  - image: busybox\n"+
+		"+  - image: busybox:2\n", diff)
+
+	diff, err = generateDiffWithContext(existing, updated, 3, true)
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "@@ -4,9 +4,9 @@ spec.containers[0].env[0]\n")
+	assert.Contains(t, diff, "   containers:\n")
+}
@@ -0,0 +1,201 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// diffLogEntryPrefix starts the header line of each entry in the diff log file, which is followed by the JSON of a
+// diffLogHeader.
+const diffLogEntryPrefix = "=== "
+
+// diffLogHeader identifies the object and the policy of a diff in the diff log file. Deletion is set when the entry is
+// the record of an object deleted by the policy rather than a diff.
+type diffLogHeader struct {
+	Timestamp string `json:"timestamp"`
+	Policy    string `json:"policy"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Deletion  bool   `json:"deletion,omitempty"`
+}
+
+// DiffLogWriter writes the diffs of the objects that the policies record to a dedicated file instead of the
+// controller log, where the multiline diffs break the log parsers. When writing an entry would make the file larger
+// than MaxBytes, the file is rotated to <path>.1, the previous <path>.1 to <path>.2, and so on, and the files beyond
+// MaxBackups are removed. Zero or less disables the rotation.
+type DiffLogWriter struct {
+	Path       string
+	MaxBytes   int64
+	MaxBackups int
+	lock       sync.Mutex
+	file       *os.File
+	size       int64
+}
+
+// NewDiffLogWriter returns a DiffLogWriter that appends to the file at the path, which is created if it doesn't exist.
+func NewDiffLogWriter(path string, maxBytes int64, maxBackups int) (*DiffLogWriter, error) {
+	w := &DiffLogWriter{Path: path, MaxBytes: maxBytes, MaxBackups: maxBackups}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *DiffLogWriter) open() error {
+	file, err := os.OpenFile(w.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open the diff log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return fmt.Errorf("failed to determine the size of the diff log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+
+	return nil
+}
+
+// rotate closes the file, shifts it and its backups by one, and opens a new file at the path. The file is reopened
+// even if it can't be shifted so that the next entries are still written.
+func (w *DiffLogWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		w.file = nil
+
+		return fmt.Errorf("failed to close the diff log file: %w", err)
+	}
+
+	shiftErr := w.shiftBackups()
+
+	if err := w.open(); err != nil {
+		w.file = nil
+
+		return err
+	}
+
+	return shiftErr
+}
+
+// shiftBackups renames the file to <path>.1 after renaming the previous backups to the next number, and removes the
+// file or the backup beyond MaxBackups.
+func (w *DiffLogWriter) shiftBackups() error {
+	if w.MaxBackups <= 0 {
+		if err := os.Remove(w.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove the diff log file: %w", err)
+		}
+
+		return nil
+	}
+
+	for i := w.MaxBackups - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", w.Path, i), fmt.Sprintf("%s.%d", w.Path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate the diff log file: %w", err)
+		}
+	}
+
+	if err := os.Rename(w.Path, w.Path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate the diff log file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteDiff writes the diff of the object with a header line identifying it.
+func (w *DiffLogWriter) WriteDiff(header diffLogHeader, diff string) error {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	entry := diffLogEntryPrefix + string(headerJSON) + "\n" + diff
+	if entry[len(entry)-1] != '\n' {
+		entry += "\n"
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("the diff log file %s is closed", w.Path)
+	}
+
+	// An entry larger than the limit is still written to an empty file
+	if w.MaxBytes > 0 && w.size > 0 && w.size+int64(len(entry)) > w.MaxBytes {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.WriteString(entry)
+	w.size += int64(n)
+
+	return err
+}
+
+// Close closes the diff log file.
+func (w *DiffLogWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
+
+// diffLogMessage returns the message to log for the diff of the object. When the reconciler has a DiffLog, the diff
+// is written to it and the message only points to it. The diff is logged as before if it can't be written.
+func (r *ConfigurationPolicyReconciler) diffLogMessage(obj singleObject, diff string) string {
+	return r.diffLogEntryMessage(obj, diff, false)
+}
+
+// deletionLogMessage is like diffLogMessage for the deletion record of an object, which is labeled as such in the
+// diff log file.
+func (r *ConfigurationPolicyReconciler) deletionLogMessage(obj singleObject, record string) string {
+	return r.diffLogEntryMessage(obj, record, true)
+}
+
+func (r *ConfigurationPolicyReconciler) diffLogEntryMessage(obj singleObject, entry string, deletion bool) string {
+	entryName := "diff"
+	if deletion {
+		entryName = "deletion record"
+	}
+
+	if r.DiffLog == nil {
+		return "Logging the " + entryName + ":\n" + entry
+	}
+
+	header := diffLogHeader{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Policy:    obj.policy.Namespace + "/" + obj.policy.Name,
+		Kind:      obj.existingObj.GetKind(),
+		Name:      obj.name,
+		Namespace: obj.namespace,
+		Deletion:  deletion,
+	}
+
+	if err := r.DiffLog.WriteDiff(header, entry); err != nil {
+		log.Error(err, "Failed to write the "+entryName+" to the diff log file", "path", r.DiffLog.Path)
+
+		return "Logging the " + entryName + ":\n" + entry
+	}
+
+	return "Wrote the " + entryName + " to " + r.DiffLog.Path
+}
@@ -0,0 +1,99 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestDiffLogWriterRotation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "diffs.log")
+
+	w, err := NewDiffLogWriter(path, 150, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer w.Close()
+
+	header := diffLogHeader{Timestamp: "2024-01-01T00:00:00Z", Policy: "managed/policy", Kind: "ConfigMap", Name: "cm"}
+
+	// Each entry is about 120 bytes, so every entry after the first rotates the file
+	for _, diff := range []string{"first", "second", "third", "fourth"} {
+		assert.NoError(t, w.WriteDiff(header, "-  key: "+diff))
+	}
+
+	current, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(current), "fourth")
+
+	backup1, err := os.ReadFile(path + ".1")
+	assert.NoError(t, err)
+	assert.Contains(t, string(backup1), "third")
+
+	backup2, err := os.ReadFile(path + ".2")
+	assert.NoError(t, err)
+	assert.Contains(t, string(backup2), "second")
+
+	// The backups beyond MaxBackups are removed
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	lines := strings.Split(string(current), "\n")
+	if assert.Len(t, lines, 3) {
+		headerJSON, found := strings.CutPrefix(lines[0], diffLogEntryPrefix)
+		assert.True(t, found)
+
+		parsed := diffLogHeader{}
+		assert.NoError(t, json.Unmarshal([]byte(headerJSON), &parsed))
+		assert.Equal(t, header, parsed)
+		assert.Equal(t, "-  key: fourth", lines[1])
+	}
+}
+
+func TestDiffLogMessage(t *testing.T) {
+	t.Parallel()
+
+	obj := singleObject{
+		policy: &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}},
+		existingObj: &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+		}},
+		name:      "cm",
+		namespace: "default",
+	}
+
+	r := &ConfigurationPolicyReconciler{}
+
+	assert.Equal(t, "Logging the diff:\n-a\n+b", r.diffLogMessage(obj, "-a\n+b"))
+
+	path := filepath.Join(t.TempDir(), "diffs.log")
+
+	diffLog, err := NewDiffLogWriter(path, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer diffLog.Close()
+
+	r.DiffLog = diffLog
+
+	assert.Equal(t, "Wrote the diff to "+path, r.diffLogMessage(obj, "-a\n+b"))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"policy":"managed/policy","kind":"ConfigMap","name":"cm","namespace":"default"}`)
+	assert.True(t, strings.HasSuffix(string(content), "\n-a\n+b\n"))
+}
@@ -0,0 +1,42 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"strings"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	// disableDiffAnnotation on an object set to "true" suppresses the generation of its diff regardless of the
+	// recordDiff setting of the object template.
+	disableDiffAnnotation = "policy.open-cluster-management.io/disable-diff"
+	diffSuppressedMsg     = "diff suppressed"
+)
+
+// diffSuppressedNote returns the note explaining why the diff of the object isn't generated, or an empty string when
+// it can be generated.
+func diffSuppressedNote(obj singleObject, objectT *policyv1.ObjectTemplate) string {
+	if objectT.RecordDiff == policyv1.RecordDiffCensored {
+		return diffSuppressedMsg + " by the object template's recordDiff set to Censored"
+	}
+
+	if obj.existingObj != nil && strings.EqualFold(obj.existingObj.GetAnnotations()[disableDiffAnnotation], "true") {
+		return diffSuppressedMsg + " by the " + disableDiffAnnotation + " annotation on the object"
+	}
+
+	return ""
+}
+
+// suppressDiff returns the note to report in place of the suppressed diff of the mismatched object, which is also
+// recorded for the compliance event when the object template records its diff.
+func (r *ConfigurationPolicyReconciler) suppressDiff(
+	obj singleObject, objectT *policyv1.ObjectTemplate, remediation policyv1.RemediationAction, note string,
+) string {
+	if objectT.RecordDiff == policyv1.RecordDiffLog && remediation.IsInform() {
+		r.recordEventDiff(obj, note)
+	}
+
+	return note
+}
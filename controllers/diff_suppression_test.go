@@ -0,0 +1,82 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestDiffSuppressedNote(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		recordDiff   policyv1.RecordDiff
+		annotation   string
+		expectedNote string
+	}{
+		"diff logged": {
+			recordDiff: policyv1.RecordDiffLog,
+		},
+		"diff censored": {
+			recordDiff:   policyv1.RecordDiffCensored,
+			expectedNote: "diff suppressed by the object template's recordDiff set to Censored",
+		},
+		"diff disabled on the object": {
+			recordDiff:   policyv1.RecordDiffLog,
+			annotation:   "True",
+			expectedNote: "diff suppressed by the " + disableDiffAnnotation + " annotation on the object",
+		},
+		"diff not disabled on the object": {
+			annotation: "false",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			existingObj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1", "kind": "ConfigMap",
+			}}
+
+			if test.annotation != "" {
+				existingObj.SetAnnotations(map[string]string{disableDiffAnnotation: test.annotation})
+			}
+
+			note := diffSuppressedNote(
+				singleObject{existingObj: existingObj}, &policyv1.ObjectTemplate{RecordDiff: test.recordDiff},
+			)
+			assert.Equal(t, test.expectedNote, note)
+		})
+	}
+}
+
+func TestSuppressDiff(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}}
+	policy.Status.ComplianceState = policyv1.NonCompliant
+
+	obj := singleObject{
+		policy: policy,
+		existingObj: &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+		}},
+		name:      "cm",
+		namespace: "default",
+	}
+
+	r := &ConfigurationPolicyReconciler{MaxEventDiffBytes: 1024}
+	objectT := &policyv1.ObjectTemplate{RecordDiff: policyv1.RecordDiffLog}
+
+	assert.Equal(t, "diff suppressed", r.suppressDiff(obj, objectT, policyv1.Inform, "diff suppressed"))
+	assert.Equal(t, "; diff of ConfigMap default/cm:\ndiff suppressed", r.eventDiffsMessage(policy))
+}
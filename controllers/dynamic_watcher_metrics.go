@@ -0,0 +1,169 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"sync"
+
+	depclient "github.com/stolostron/kubernetes-dependency-watches/client"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// InstrumentDynamicWatcher returns the DynamicWatcher wrapped so that its active watches and the objects queried
+// through it are recorded in the DynamicWatcher metrics. The queries through it aren't recorded in the API request
+// metrics since they're served from its cache. Its API requests are recorded by creating it with a config from
+// InstrumentConfig instead.
+func InstrumentDynamicWatcher(watcher depclient.DynamicWatcher, controller string) depclient.DynamicWatcher {
+	return &instrumentedDynamicWatcher{
+		DynamicWatcher: watcher,
+		controller:     controller,
+		batchObjects:   map[string]map[string]bool{},
+		queriedObjects: map[string]map[string]bool{},
+		objectWatchers: map[string]int{},
+	}
+}
+
+// instrumentedDynamicWatcher records the number of active watches of the DynamicWatcher and of the objects queried
+// through it in the DynamicWatcher metrics. The DynamicWatcher doesn't expose the size of its cache, so the objects
+// that it caches but that no query returned aren't included.
+type instrumentedDynamicWatcher struct {
+	depclient.DynamicWatcher
+	controller string
+	lock       sync.Mutex
+	// batchObjects has the watchers in a query batch as the keys and the values are the objects queried in the batch.
+	batchObjects map[string]map[string]bool
+	// queriedObjects has the watchers as the keys and the values are the objects queried in their last query batch.
+	queriedObjects map[string]map[string]bool
+	// objectWatchers has the objects queried in the last query batch of any watcher as the keys and the values are the
+	// number of watchers that queried them, so that the objects shared between policies are only counted once.
+	objectWatchers map[string]int
+}
+
+// watcherKey returns the key of the watcher in the maps of the instrumentedDynamicWatcher.
+func watcherKey(watcher depclient.ObjectIdentifier) string {
+	return watcher.Kind + "/" + watcher.Namespace + "/" + watcher.Name
+}
+
+// recordQueriedObjects records the objects queried by the watcher in its query batch. The queries outside of a batch
+// aren't recorded, since their watches are removed when the query is done.
+func (w *instrumentedDynamicWatcher) recordQueriedObjects(
+	watcher depclient.ObjectIdentifier, objects ...*unstructured.Unstructured,
+) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	queried, ok := w.batchObjects[watcherKey(watcher)]
+	if !ok {
+		return
+	}
+
+	for _, object := range objects {
+		queried[object.GroupVersionKind().String()+"/"+object.GetNamespace()+"/"+object.GetName()] = true
+	}
+}
+
+// setQueriedObjects replaces the objects queried in the last query batch of the watcher, or removes them when queried
+// is nil. The lock must be held.
+func (w *instrumentedDynamicWatcher) setQueriedObjects(key string, queried map[string]bool) {
+	for object := range w.queriedObjects[key] {
+		w.objectWatchers[object]--
+
+		if w.objectWatchers[object] == 0 {
+			delete(w.objectWatchers, object)
+		}
+	}
+
+	if queried == nil {
+		delete(w.queriedObjects, key)
+
+		return
+	}
+
+	w.queriedObjects[key] = queried
+
+	for object := range queried {
+		w.objectWatchers[object]++
+	}
+}
+
+// updateGauges sets the DynamicWatcher metrics of the controller. The lock must be held.
+func (w *instrumentedDynamicWatcher) updateGauges() {
+	dynamicWatcherWatchesGauge.WithLabelValues(w.controller).Set(float64(w.GetWatchCount()))
+	dynamicWatcherQueriedObjectsGauge.WithLabelValues(w.controller).Set(float64(len(w.objectWatchers)))
+}
+
+func (w *instrumentedDynamicWatcher) StartQueryBatch(watcher depclient.ObjectIdentifier) error {
+	if err := w.DynamicWatcher.StartQueryBatch(watcher); err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.batchObjects[watcherKey(watcher)] = map[string]bool{}
+
+	return nil
+}
+
+func (w *instrumentedDynamicWatcher) EndQueryBatch(watcher depclient.ObjectIdentifier) error {
+	err := w.DynamicWatcher.EndQueryBatch(watcher)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	key := watcherKey(watcher)
+
+	if queried, ok := w.batchObjects[key]; ok {
+		w.setQueriedObjects(key, queried)
+
+		delete(w.batchObjects, key)
+	}
+
+	w.updateGauges()
+
+	return err
+}
+
+func (w *instrumentedDynamicWatcher) RemoveWatcher(watcher depclient.ObjectIdentifier) error {
+	err := w.DynamicWatcher.RemoveWatcher(watcher)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	key := watcherKey(watcher)
+
+	delete(w.batchObjects, key)
+	w.setQueriedObjects(key, nil)
+
+	w.updateGauges()
+
+	return err
+}
+
+func (w *instrumentedDynamicWatcher) Get(
+	watcher depclient.ObjectIdentifier, gvk schema.GroupVersionKind, namespace string, name string,
+) (*unstructured.Unstructured, error) {
+	object, err := w.DynamicWatcher.Get(watcher, gvk, namespace, name)
+	if object != nil {
+		w.recordQueriedObjects(watcher, object)
+	}
+
+	return object, err
+}
+
+func (w *instrumentedDynamicWatcher) List(
+	watcher depclient.ObjectIdentifier, gvk schema.GroupVersionKind, namespace string, selector labels.Selector,
+) ([]unstructured.Unstructured, error) {
+	objects, err := w.DynamicWatcher.List(watcher, gvk, namespace, selector)
+
+	queried := make([]*unstructured.Unstructured, 0, len(objects))
+	for i := range objects {
+		queried = append(queried, &objects[i])
+	}
+
+	w.recordQueriedObjects(watcher, queried...)
+
+	return objects, err
+}
@@ -0,0 +1,112 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	depclient "github.com/stolostron/kubernetes-dependency-watches/client"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeDynamicWatcher returns the objects for the queries and has a watch per watcher in a query batch or with queried
+// objects.
+type fakeDynamicWatcher struct {
+	depclient.DynamicWatcher
+	objects  []unstructured.Unstructured
+	watchers map[depclient.ObjectIdentifier]bool
+}
+
+func (w *fakeDynamicWatcher) StartQueryBatch(watcher depclient.ObjectIdentifier) error {
+	w.watchers[watcher] = true
+
+	return nil
+}
+
+func (w *fakeDynamicWatcher) EndQueryBatch(_ depclient.ObjectIdentifier) error {
+	return nil
+}
+
+func (w *fakeDynamicWatcher) RemoveWatcher(watcher depclient.ObjectIdentifier) error {
+	delete(w.watchers, watcher)
+
+	return nil
+}
+
+func (w *fakeDynamicWatcher) GetWatchCount() uint {
+	return uint(len(w.watchers))
+}
+
+func (w *fakeDynamicWatcher) Get(
+	_ depclient.ObjectIdentifier, _ schema.GroupVersionKind, _ string, name string,
+) (*unstructured.Unstructured, error) {
+	for i := range w.objects {
+		if w.objects[i].GetName() == name {
+			return &w.objects[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (w *fakeDynamicWatcher) List(
+	_ depclient.ObjectIdentifier, _ schema.GroupVersionKind, _ string, _ labels.Selector,
+) ([]unstructured.Unstructured, error) {
+	return w.objects, nil
+}
+
+func TestInstrumentDynamicWatcherGauges(t *testing.T) {
+	t.Parallel()
+
+	newInstallPlan := func(name string) unstructured.Unstructured {
+		installPlan := unstructured.Unstructured{}
+		installPlan.SetAPIVersion("operators.coreos.com/v1alpha1")
+		installPlan.SetKind("InstallPlan")
+		installPlan.SetName(name)
+		installPlan.SetNamespace("operators")
+
+		return installPlan
+	}
+
+	fakeWatcher := &fakeDynamicWatcher{
+		objects:  []unstructured.Unstructured{newInstallPlan("install-1"), newInstallPlan("install-2")},
+		watchers: map[depclient.ObjectIdentifier]bool{},
+	}
+	controller := "dynamic-watcher-gauges-test"
+	watcher := InstrumentDynamicWatcher(fakeWatcher, controller)
+
+	policy1 := opPolIdentifier("managed", "policy-1")
+	policy2 := opPolIdentifier("managed", "policy-2")
+
+	// The same object queried twice in a batch is only counted once
+	assert.NoError(t, watcher.StartQueryBatch(policy1))
+	_, err := watcher.List(policy1, installPlanGVK, "operators", labels.Everything())
+	assert.NoError(t, err)
+	_, err = watcher.Get(policy1, installPlanGVK, "operators", "install-1")
+	assert.NoError(t, err)
+	_, err = watcher.Get(policy1, installPlanGVK, "operators", "missing")
+	assert.NoError(t, err)
+	assert.NoError(t, watcher.EndQueryBatch(policy1))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(dynamicWatcherWatchesGauge.WithLabelValues(controller)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(dynamicWatcherQueriedObjectsGauge.WithLabelValues(controller)))
+
+	assert.NoError(t, watcher.StartQueryBatch(policy2))
+	_, err = watcher.Get(policy2, installPlanGVK, "operators", "install-2")
+	assert.NoError(t, err)
+	assert.NoError(t, watcher.EndQueryBatch(policy2))
+
+	// The object queried by both policies is only counted once
+	assert.Equal(t, float64(2), testutil.ToFloat64(dynamicWatcherWatchesGauge.WithLabelValues(controller)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(dynamicWatcherQueriedObjectsGauge.WithLabelValues(controller)))
+
+	// The objects only queried by a removed watcher are no longer counted
+	assert.NoError(t, watcher.RemoveWatcher(policy1))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(dynamicWatcherWatchesGauge.WithLabelValues(controller)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(dynamicWatcherQueriedObjectsGauge.WithLabelValues(controller)))
+}
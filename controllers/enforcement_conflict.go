@@ -0,0 +1,184 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	reasonEnforcementReverted = "Enforcement is being reverted by another controller"
+	// enforcementRevertedMsg is included in the message returned by checkAndUpdateResource when updates to an object
+	// are paused, which is how the reason is determined.
+	enforcementRevertedMsg = "is being reverted by another controller"
+	// The first pause of the updates to an object is enforcementBackoffBase, and it doubles every time the updates are
+	// reverted again, up to enforcementBackoffMax.
+	enforcementBackoffBase = time.Minute
+	enforcementBackoffMax  = time.Hour
+)
+
+// enforcementHistory is the record of the recent updates that a ConfigurationPolicy made to an object, which is used to
+// detect when another controller keeps reverting them.
+type enforcementHistory struct {
+	updates []time.Time
+	// backoffs is the number of times the updates were paused since the object last converged.
+	backoffs     int
+	backoffUntil time.Time
+	// manager is the field manager that last changed the object before the policy updated it.
+	manager string
+}
+
+// record adds an update at the input time and returns true if there were more than threshold updates within the
+// window, in which case the updates are paused with an exponential backoff.
+func (h *enforcementHistory) record(now time.Time, threshold int, window time.Duration) bool {
+	recent := make([]time.Time, 0, len(h.updates)+1)
+
+	for _, update := range h.updates {
+		if now.Sub(update) < window {
+			recent = append(recent, update)
+		}
+	}
+
+	h.updates = append(recent, now)
+
+	if threshold <= 0 || len(h.updates) <= threshold {
+		return false
+	}
+
+	backoff := enforcementBackoffBase
+	for i := 0; i < h.backoffs && backoff < enforcementBackoffMax; i++ {
+		backoff *= 2
+	}
+
+	if backoff > enforcementBackoffMax {
+		backoff = enforcementBackoffMax
+	}
+
+	h.backoffs++
+	h.backoffUntil = now.Add(backoff)
+
+	return true
+}
+
+// enforcementHistoryFor returns the enforcement history of the object for the policy, creating it if necessary.
+func (r *ConfigurationPolicyReconciler) enforcementHistoryFor(
+	policy *policyv1.ConfigurationPolicy, object *unstructured.Unstructured,
+) *enforcementHistory {
+	policyMap := &sync.Map{}
+
+	loadedPolicyMap, loaded := r.enforcementHistoryCache.LoadOrStore(policy.GetUID(), policyMap)
+	if loaded {
+		policyMap = loadedPolicyMap.(*sync.Map)
+	}
+
+	history, _ := policyMap.LoadOrStore(object.GetUID(), &enforcementHistory{})
+
+	return history.(*enforcementHistory)
+}
+
+// enforcementPaused returns a noncompliant message if the policy's updates to the object are paused because another
+// controller keeps reverting them. An empty string is returned otherwise.
+func (r *ConfigurationPolicyReconciler) enforcementPaused(obj singleObject) string {
+	if r.EnforcementConflictThreshold <= 0 {
+		return ""
+	}
+
+	loadedPolicyMap, loaded := r.enforcementHistoryCache.Load(obj.policy.GetUID())
+	if !loaded {
+		return ""
+	}
+
+	loadedHistory, loaded := loadedPolicyMap.(*sync.Map).Load(obj.existingObj.GetUID())
+	if !loaded {
+		return ""
+	}
+
+	history := loadedHistory.(*enforcementHistory)
+	if !time.Now().Before(history.backoffUntil) {
+		return ""
+	}
+
+	msg := fmt.Sprintf("%s [%s]", obj.gvr.Resource, obj.name)
+	if obj.namespace != "" {
+		msg += " in namespace " + obj.namespace
+	}
+
+	msg += " " + enforcementRevertedMsg
+
+	if history.manager != "" {
+		msg += fmt.Sprintf(" with the field manager `%s`", history.manager)
+	}
+
+	return msg + fmt.Sprintf(
+		", %d updates were made within %s without converging, so updates are paused until %s",
+		len(history.updates), r.EnforcementConflictWindow, history.backoffUntil.UTC().Format(time.RFC3339),
+	)
+}
+
+// recordEnforcement records that the policy updated the object. The input object is the object before the update,
+// which is used to determine the field manager that last changed it.
+func (r *ConfigurationPolicyReconciler) recordEnforcement(
+	policy *policyv1.ConfigurationPolicy, object *unstructured.Unstructured,
+) {
+	if r.EnforcementConflictThreshold <= 0 {
+		return
+	}
+
+	history := r.enforcementHistoryFor(policy, object)
+	history.manager = conflictingFieldManager(
+		object.GetManagedFields(), policyFieldManager(r.FieldManager, policy.Name),
+	)
+
+	if history.record(time.Now(), r.EnforcementConflictThreshold, r.EnforcementConflictWindow) {
+		log.Info(
+			"The updates to the object keep getting reverted, so they are paused",
+			"policy", policy.Name, "kind", object.GetKind(), "name", object.GetName(),
+			"namespace", object.GetNamespace(), "fieldManager", history.manager, "until", history.backoffUntil,
+		)
+	}
+}
+
+// resetEnforcementHistory clears the enforcement history of the object for the policy, which is done when the object
+// is compliant without an update.
+func (r *ConfigurationPolicyReconciler) resetEnforcementHistory(
+	policy *policyv1.ConfigurationPolicy, object *unstructured.Unstructured,
+) {
+	if loadedPolicyMap, loaded := r.enforcementHistoryCache.Load(policy.GetUID()); loaded {
+		loadedPolicyMap.(*sync.Map).Delete(object.GetUID())
+	}
+}
+
+// conflictingFieldManager returns the field manager, other than the input policy field manager, that most recently
+// changed the object based on its managed fields. An empty string is returned if there is none.
+func conflictingFieldManager(managedFields []metav1.ManagedFieldsEntry, policyManager string) string {
+	var manager string
+	var latest time.Time
+
+	for _, entry := range managedFields {
+		// The status is typically set by the controller that owns the object, which isn't a conflict
+		if entry.Manager == policyManager || entry.Subresource != "" || entry.Time == nil {
+			continue
+		}
+
+		if manager == "" || entry.Time.After(latest) {
+			manager = entry.Manager
+			latest = entry.Time.Time
+		}
+	}
+
+	return manager
+}
+
+// isEnforcementReverted returns true if the message from checkAndUpdateResource indicates that the updates to the
+// object are paused because another controller keeps reverting them.
+func isEnforcementReverted(message string) bool {
+	return strings.Contains(message, enforcementRevertedMsg)
+}
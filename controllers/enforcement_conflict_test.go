@@ -0,0 +1,104 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestEnforcementHistoryRecord(t *testing.T) {
+	t.Parallel()
+
+	history := &enforcementHistory{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		assert.False(t, history.record(start.Add(time.Duration(i)*time.Minute), 3, 10*time.Minute))
+	}
+
+	// The fourth update within the window starts the backoff
+	assert.True(t, history.record(start.Add(3*time.Minute), 3, 10*time.Minute))
+	assert.Equal(t, start.Add(4*time.Minute), history.backoffUntil)
+
+	// The backoff doubles every time the updates are reverted again
+	assert.True(t, history.record(start.Add(5*time.Minute), 3, 10*time.Minute))
+	assert.Equal(t, start.Add(7*time.Minute), history.backoffUntil)
+
+	history.backoffs = 10
+	assert.True(t, history.record(start.Add(8*time.Minute), 3, 10*time.Minute))
+	assert.Equal(t, start.Add(8*time.Minute+enforcementBackoffMax), history.backoffUntil)
+
+	// The updates outside of the window aren't counted
+	assert.False(t, history.record(start.Add(time.Hour), 3, 10*time.Minute))
+	assert.Len(t, history.updates, 1)
+
+	assert.False(t, (&enforcementHistory{}).record(start, 0, 10*time.Minute))
+}
+
+func TestEnforcementPaused(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{
+		EnforcementConflictThreshold: 1,
+		EnforcementConflictWindow:    10 * time.Minute,
+	}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-policy", UID: "policy-uid"},
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetUID("object-uid")
+	existing.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: policyFieldManager("", "my-policy"), Time: &metav1.Time{Time: time.Now()}},
+		{Manager: "other-operator", Time: &metav1.Time{Time: time.Now().Add(-time.Minute)}},
+	})
+
+	obj := singleObject{
+		policy:      policy,
+		gvr:         schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		existingObj: existing,
+		name:        "my-configmap",
+		namespace:   "default",
+	}
+
+	r.recordEnforcement(policy, existing)
+	assert.Equal(t, "", r.enforcementPaused(obj))
+
+	r.recordEnforcement(policy, existing)
+
+	msg := r.enforcementPaused(obj)
+	assert.True(t, isEnforcementReverted(msg))
+	assert.True(t, strings.HasPrefix(
+		msg,
+		"configmaps [my-configmap] in namespace default is being reverted by another controller with the field "+
+			"manager `other-operator`, 2 updates were made within 10m0s without converging",
+	))
+
+	// The history is cleared when the object converges
+	r.resetEnforcementHistory(policy, existing)
+	assert.Equal(t, "", r.enforcementPaused(obj))
+}
+
+func TestConflictingFieldManager(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	managedFields := []metav1.ManagedFieldsEntry{
+		{Manager: "old-operator", Time: &metav1.Time{Time: now.Add(-time.Hour)}},
+		{Manager: "other-operator", Time: &metav1.Time{Time: now.Add(-time.Minute)}},
+		{Manager: "status-controller", Subresource: "status", Time: &metav1.Time{Time: now}},
+		{Manager: "config-policy-controller", Time: &metav1.Time{Time: now}},
+	}
+
+	assert.Equal(t, "other-operator", conflictingFieldManager(managedFields, "config-policy-controller"))
+	assert.Equal(t, "", conflictingFieldManager(managedFields[2:], "config-policy-controller"))
+}
@@ -0,0 +1,114 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	reasonEnforcementThrottled = "Enforcement throttled by the rate limit"
+	// enforcementThrottledMsg is included in the message when an object isn't created, updated, or deleted since the
+	// enforcement rate limit was reached, which is how the reason is determined.
+	enforcementThrottledMsg = "isn't modified yet since the enforcement rate limit was reached"
+)
+
+// newEnforcementRateLimiter returns a token bucket rate limiter with the input queries per second and burst, or nil if
+// the queries per second is zero or less, which disables the limit.
+func newEnforcementRateLimiter(qps float32, burst int) *rate.Limiter {
+	if qps <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return rate.NewLimiter(rate.Limit(qps), burst)
+}
+
+// reserveToken takes a token from the rate limiter if one is available at the input time. The returned reservation is
+// nil when the limiter is nil, and it can be canceled at the same time to return the token.
+func reserveToken(limiter *rate.Limiter, now time.Time) (*rate.Reservation, bool) {
+	if limiter == nil {
+		return nil, true
+	}
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+		reservation.CancelAt(now)
+
+		return nil, false
+	}
+
+	return reservation, true
+}
+
+// enforcementAllowed takes a token from the policy's enforcement rate limiter and from the controller-wide one, and
+// returns true if the policy may create, update, or delete an object now. Only the requests that modify objects take a
+// token, so the evaluation of the objects is never throttled. When the controller-wide limit is reached, the token of
+// the policy is returned so that a throttled enforcement doesn't use up the policy's budget.
+func (r *ConfigurationPolicyReconciler) enforcementAllowed(policy *policyv1.ConfigurationPolicy) bool {
+	var policyLimiter *rate.Limiter
+
+	if r.PolicyEnforcementQPS > 0 {
+		limiter, found := r.policyRateLimiterCache.Load(policyKey(policy))
+		if !found {
+			limiter, _ = r.policyRateLimiterCache.LoadOrStore(
+				policyKey(policy), newEnforcementRateLimiter(r.PolicyEnforcementQPS, r.PolicyEnforcementBurst),
+			)
+		}
+
+		policyLimiter = limiter.(*rate.Limiter)
+	}
+
+	// The reservations are canceled at the time they were made, since a reservation is already consumed afterward
+	now := time.Now()
+
+	policyReservation, allowed := reserveToken(policyLimiter, now)
+	if !allowed {
+		return false
+	}
+
+	r.enforcementRateLimiterOnce.Do(func() {
+		r.enforcementRateLimiter = newEnforcementRateLimiter(r.EnforcementQPS, r.EnforcementBurst)
+	})
+
+	if _, allowed := reserveToken(r.enforcementRateLimiter, now); !allowed {
+		if policyReservation != nil {
+			policyReservation.CancelAt(now)
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// enforcementThrottled returns a message when the object can't be modified since the enforcement rate limit was
+// reached, and an empty string otherwise. The dry run requests of policies in preview mode are never throttled. A
+// throttled policy is evaluated again in the next loop regardless of its evaluationInterval, so the enforcement
+// continues until the objects converge.
+func (r *ConfigurationPolicyReconciler) enforcementThrottled(obj singleObject) string {
+	if isPreview(obj.policy) || r.enforcementAllowed(obj.policy) {
+		return ""
+	}
+
+	r.throttledPolicyCache.Store(obj.policy.GetUID(), true)
+
+	idStr := identifierStr([]string{obj.name}, obj.namespace)
+
+	return fmt.Sprintf("%v %v %v", obj.gvr.Resource, idStr, enforcementThrottledMsg)
+}
+
+// isEnforcementThrottled returns true if the message indicates that the object wasn't modified since the enforcement
+// rate limit was reached.
+func isEnforcementThrottled(message string) bool {
+	return strings.Contains(message, enforcementThrottledMsg)
+}
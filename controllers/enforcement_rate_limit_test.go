@@ -0,0 +1,80 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestEnforcementAllowed(t *testing.T) {
+	t.Parallel()
+
+	// The limits are low enough that no token is added back during the test
+	r := &ConfigurationPolicyReconciler{
+		EnforcementQPS:         0.001,
+		EnforcementBurst:       3,
+		PolicyEnforcementQPS:   0.001,
+		PolicyEnforcementBurst: 2,
+	}
+	policy1 := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: "managed"}}
+	policy2 := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy2", Namespace: "managed"}}
+
+	assert.True(t, r.enforcementAllowed(policy1))
+	assert.True(t, r.enforcementAllowed(policy1))
+	// The policy's own limit is reached
+	assert.False(t, r.enforcementAllowed(policy1))
+
+	assert.True(t, r.enforcementAllowed(policy2))
+	// The controller-wide limit is reached
+	assert.False(t, r.enforcementAllowed(policy2))
+
+	// The token of the policy is returned when the controller-wide limit is reached
+	policy2Limiter, _ := r.policyRateLimiterCache.Load(policyKey(policy2))
+	assert.InDelta(t, 1, policy2Limiter.(*rate.Limiter).Tokens(), 0.01)
+
+	unlimited := &ConfigurationPolicyReconciler{}
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, unlimited.enforcementAllowed(policy1))
+	}
+}
+
+func TestEnforcementThrottled(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{EnforcementQPS: 0.001, EnforcementBurst: 1}
+	obj := singleObject{
+		policy: &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed", UID: "policy-uid"},
+		},
+		gvr:       schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		name:      "my-configmap",
+		namespace: "default",
+	}
+
+	assert.Equal(t, "", r.enforcementThrottled(obj))
+
+	msg := r.enforcementThrottled(obj)
+	assert.Equal(
+		t,
+		"configmaps [my-configmap] in namespace default isn't modified yet since the enforcement rate limit was reached",
+		msg,
+	)
+	assert.True(t, isEnforcementThrottled(msg))
+
+	// The throttled policy is evaluated again in the next loop
+	_, throttled := r.throttledPolicyCache.Load(obj.policy.GetUID())
+	assert.True(t, throttled)
+
+	// The dry run requests in preview mode aren't throttled
+	obj.policy.SetAnnotations(map[string]string{previewAnnotation: "true"})
+
+	assert.Equal(t, "", r.enforcementThrottled(obj))
+}
@@ -0,0 +1,134 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// eventDiffs are the diffs of the mismatched objects and the records of the deleted objects of a policy evaluation,
+// keyed by the object, which are appended to the compliance event. The object templates can be evaluated
+// concurrently, so they're locked.
+type eventDiffs struct {
+	lock      sync.Mutex
+	diffs     map[string]string
+	deletions map[string]string
+}
+
+// eventObjectKey identifies the object in the compliance event message.
+func eventObjectKey(obj singleObject) string {
+	if obj.namespace != "" {
+		return obj.existingObj.GetKind() + " " + obj.namespace + "/" + obj.name
+	}
+
+	return obj.existingObj.GetKind() + " " + obj.name
+}
+
+// policyEventDiffs returns the eventDiffs of the evaluation of the object's policy.
+func (r *ConfigurationPolicyReconciler) policyEventDiffs(obj singleObject) *eventDiffs {
+	key := obj.policy.Namespace + "/" + obj.policy.Name
+	loaded, _ := r.eventDiffCache.LoadOrStore(
+		key, &eventDiffs{diffs: map[string]string{}, deletions: map[string]string{}},
+	)
+
+	return loaded.(*eventDiffs)
+}
+
+// recordEventDiff records the diff of the mismatched object for the compliance event of the policy evaluation. It's
+// only recorded when the diffs are appended to the compliance events.
+func (r *ConfigurationPolicyReconciler) recordEventDiff(obj singleObject, diff string) {
+	if r.MaxEventDiffBytes <= 0 || diff == "" {
+		return
+	}
+
+	policyDiffs := r.policyEventDiffs(obj)
+
+	policyDiffs.lock.Lock()
+	policyDiffs.diffs[eventObjectKey(obj)] = diff
+	policyDiffs.lock.Unlock()
+}
+
+// recordEventDeletion records the deletion record of the object for the compliance event of the policy evaluation.
+// It's only recorded when the diffs are appended to the compliance events.
+func (r *ConfigurationPolicyReconciler) recordEventDeletion(obj singleObject, record string) {
+	if r.MaxEventDiffBytes <= 0 || record == "" {
+		return
+	}
+
+	policyDiffs := r.policyEventDiffs(obj)
+
+	policyDiffs.lock.Lock()
+	policyDiffs.deletions[eventObjectKey(obj)] = record
+	policyDiffs.lock.Unlock()
+}
+
+// eventDiffsMessage returns the diffs of the mismatched objects of the last evaluation of the noncompliant policy and
+// the records of the objects it deleted to append to its compliance event message, truncated to MaxEventDiffBytes.
+// The diffs are only included when the policy is noncompliant. An empty string is returned when there is nothing to
+// append.
+func (r *ConfigurationPolicyReconciler) eventDiffsMessage(plc *policyv1.ConfigurationPolicy) string {
+	if r.MaxEventDiffBytes <= 0 {
+		return ""
+	}
+
+	loaded, ok := r.eventDiffCache.Load(plc.Namespace + "/" + plc.Name)
+	if !ok {
+		return ""
+	}
+
+	policyDiffs := loaded.(*eventDiffs)
+
+	policyDiffs.lock.Lock()
+	defer policyDiffs.lock.Unlock()
+
+	var message strings.Builder
+
+	if plc.Status.ComplianceState == policyv1.NonCompliant {
+		writeEventEntries(&message, "diff", policyDiffs.diffs)
+	}
+
+	writeEventEntries(&message, "deletion record", policyDiffs.deletions)
+
+	return truncateDiff(message.String(), r.MaxEventDiffBytes)
+}
+
+// writeEventEntries writes the entries sorted by their object key, each labeled with the input label.
+func writeEventEntries(message *strings.Builder, label string, entries map[string]string) {
+	objKeys := make([]string, 0, len(entries))
+
+	for objKey := range entries {
+		objKeys = append(objKeys, objKey)
+	}
+
+	sort.Strings(objKeys)
+
+	for _, objKey := range objKeys {
+		message.WriteString("; " + label + " of " + objKey + ":\n")
+		message.WriteString(strings.TrimRight(entries[objKey], "\n"))
+	}
+}
+
+// truncateDiff returns the diff shortened to at most maxBytes, cut at the end of a line when possible, with
+// truncatedMessageSuffix appended when it was shortened. A maxBytes value of zero or less disables the limit.
+func truncateDiff(diff string, maxBytes int) string {
+	if maxBytes <= 0 || len(diff) <= maxBytes {
+		return diff
+	}
+
+	maxLength := maxBytes - len(truncatedMessageSuffix) - 1
+	if maxLength < 0 {
+		maxLength = 0
+	}
+
+	truncated := truncateString(diff, maxLength)
+
+	if lastLine := strings.LastIndexByte(truncated, '\n'); lastLine > 0 {
+		truncated = truncated[:lastLine]
+	}
+
+	return truncated + "\n" + truncatedMessageSuffix
+}
@@ -0,0 +1,71 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestEventDiffsMessage(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}}
+	policy.Status.ComplianceState = policyv1.NonCompliant
+
+	newObj := func(name string, namespace string) singleObject {
+		return singleObject{
+			policy: policy,
+			existingObj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1", "kind": "ConfigMap",
+			}},
+			name:      name,
+			namespace: namespace,
+		}
+	}
+
+	r := &ConfigurationPolicyReconciler{MaxEventDiffBytes: 1024}
+
+	assert.Equal(t, "", r.eventDiffsMessage(policy))
+
+	r.recordEventDiff(newObj("b", "default"), "-  key: old\n+  key: new\n")
+	r.recordEventDiff(newObj("a", "default"), "-  other: old\n+  other: new\n")
+
+	assert.Equal(
+		t,
+		"; diff of ConfigMap default/a:\n-  other: old\n+  other: new"+
+			"; diff of ConfigMap default/b:\n-  key: old\n+  key: new",
+		r.eventDiffsMessage(policy),
+	)
+
+	compliant := policy.DeepCopy()
+	compliant.Status.ComplianceState = policyv1.Compliant
+	assert.Equal(t, "", r.eventDiffsMessage(compliant))
+
+	r.MaxEventDiffBytes = 80
+	message := r.eventDiffsMessage(policy)
+	assert.LessOrEqual(t, len(message), 80)
+	assert.Equal(t, "; diff of ConfigMap default/a:\n-  other: old\n"+truncatedMessageSuffix, message)
+
+	r.MaxEventDiffBytes = 0
+	assert.Equal(t, "", r.eventDiffsMessage(policy))
+}
+
+func TestTruncateDiff(t *testing.T) {
+	t.Parallel()
+
+	diff := strings.Repeat("-  key: old\n+  key: new\n", 10)
+
+	assert.Equal(t, diff, truncateDiff(diff, 0))
+	assert.Equal(t, diff, truncateDiff(diff, len(diff)))
+
+	truncated := truncateDiff(diff, 50)
+	assert.LessOrEqual(t, len(truncated), 50)
+	assert.Equal(t, "-  key: old\n+  key: new\n"+truncatedMessageSuffix, truncated)
+}
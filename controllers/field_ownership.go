@@ -0,0 +1,183 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	reasonFieldsOwned    = "Resource found but does not match fields owned by other managers"
+	reasonToleratedDrift = "Resource found with tolerated drift"
+	// toleratedDriftMsg is included in the message returned by checkAndUpdateResource when the only mismatched fields
+	// are owned by tolerated field managers, which is how the reason is determined.
+	toleratedDriftMsg = "found with tolerated drift"
+	// fieldsOwnedMsg is included in the message returned by checkAndUpdateResource when mismatched fields are owned by
+	// other field managers, which is how the reason is determined.
+	fieldsOwnedMsg = "found but not as specified on fields owned by other managers"
+)
+
+// fieldOwnership is a field that differs from the objectDefinition and the field manager that owns it on the cluster.
+type fieldOwnership struct {
+	path    []string
+	manager string
+}
+
+func (o fieldOwnership) String() string {
+	return fmt.Sprintf("field %s is owned by '%s'", strings.Join(o.path, "."), o.manager)
+}
+
+// differingPaths returns the paths to the values that differ between the existing object and the object merged with
+// the objectDefinition, sorted by the path. Maps are compared key by key, but lists are compared as a whole.
+func differingPaths(existing, merged map[string]interface{}) [][]string {
+	paths := [][]string{}
+
+	var compare func(path []string, existingVal, mergedVal interface{})
+
+	compare = func(path []string, existingVal, mergedVal interface{}) {
+		existingMap, existingIsMap := existingVal.(map[string]interface{})
+		mergedMap, mergedIsMap := mergedVal.(map[string]interface{})
+
+		if !existingIsMap || !mergedIsMap {
+			if !reflect.DeepEqual(existingVal, mergedVal) {
+				paths = append(paths, path)
+			}
+
+			return
+		}
+
+		for key, value := range mergedMap {
+			compare(append(append([]string{}, path...), key), existingMap[key], value)
+		}
+
+		for key, value := range existingMap {
+			if _, ok := mergedMap[key]; !ok {
+				compare(append(append([]string{}, path...), key), value, nil)
+			}
+		}
+	}
+
+	compare(nil, existing, merged)
+
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Join(paths[i], "/") < strings.Join(paths[j], "/")
+	})
+
+	return paths
+}
+
+// fieldManagerOf returns the field manager, other than the input policy field manager, that owns the field at the
+// path based on the managed fields of the object. If several field managers own the field, the one that most recently
+// changed the object is returned. An empty string is returned if no other field manager owns the field.
+func fieldManagerOf(managedFields []metav1.ManagedFieldsEntry, path []string, policyManager string) string {
+	var manager string
+	var latest time.Time
+
+	for _, entry := range managedFields {
+		if entry.Manager == policyManager || entry.FieldsV1 == nil {
+			continue
+		}
+
+		fieldSet := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fieldSet); err != nil {
+			continue
+		}
+
+		owned := true
+
+		for _, key := range path {
+			nested, ok := fieldSet["f:"+key].(map[string]interface{})
+			if !ok {
+				owned = false
+
+				break
+			}
+
+			fieldSet = nested
+		}
+
+		if !owned {
+			continue
+		}
+
+		if manager == "" || (entry.Time != nil && entry.Time.After(latest)) {
+			manager = entry.Manager
+
+			if entry.Time != nil {
+				latest = entry.Time.Time
+			}
+		}
+	}
+
+	return manager
+}
+
+// ownedFields returns the input fields that are owned by other field managers than the policy's.
+func ownedFields(
+	paths [][]string, managedFields []metav1.ManagedFieldsEntry, policyManager string,
+) []fieldOwnership {
+	owned := []fieldOwnership{}
+
+	for _, path := range paths {
+		if manager := fieldManagerOf(managedFields, path, policyManager); manager != "" {
+			owned = append(owned, fieldOwnership{path: path, manager: manager})
+		}
+	}
+
+	return owned
+}
+
+// splitTolerated splits the owned fields between the fields owned by one of the tolerated field managers and the
+// others.
+func splitTolerated(owned []fieldOwnership, toleratedManagers []string) (tolerated, others []fieldOwnership) {
+	for _, field := range owned {
+		isTolerated := false
+
+		for _, manager := range toleratedManagers {
+			if field.manager == manager {
+				isTolerated = true
+
+				break
+			}
+		}
+
+		if isTolerated {
+			tolerated = append(tolerated, field)
+		} else {
+			others = append(others, field)
+		}
+	}
+
+	return tolerated, others
+}
+
+// ownershipDescription describes the owners of the fields, such as "field spec.replicas is owned by
+// 'horizontal-pod-autoscaler'".
+func ownershipDescription(owned []fieldOwnership) string {
+	descriptions := make([]string, 0, len(owned))
+
+	for _, field := range owned {
+		descriptions = append(descriptions, field.String())
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// isToleratedDrift returns true if the message from checkAndUpdateResource indicates that the object only differs
+// from the objectDefinition on fields owned by tolerated field managers.
+func isToleratedDrift(message string) bool {
+	return strings.Contains(message, toleratedDriftMsg)
+}
+
+// isFieldsOwned returns true if the message from checkAndUpdateResource indicates that the object differs from the
+// objectDefinition on fields owned by other field managers.
+func isFieldsOwned(message string) bool {
+	return strings.Contains(message, fieldsOwnedMsg)
+}
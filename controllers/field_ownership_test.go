@@ -0,0 +1,86 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDifferingPaths(t *testing.T) {
+	t.Parallel()
+
+	existing := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "my-deployment", "labels": map[string]interface{}{"app": "a"}},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"paused":   false,
+			"template": map[string]interface{}{"containers": []interface{}{"nginx"}},
+		},
+	}
+	merged := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "my-deployment", "labels": map[string]interface{}{"app": "a"}},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{"containers": []interface{}{"nginx", "sidecar"}},
+			"strategy": map[string]interface{}{"type": "Recreate"},
+		},
+	}
+
+	assert.Equal(
+		t,
+		[][]string{{"spec", "paused"}, {"spec", "replicas"}, {"spec", "strategy"}, {"spec", "template", "containers"}},
+		differingPaths(existing, merged),
+	)
+	assert.Empty(t, differingPaths(existing, existing))
+}
+
+func TestFieldManagerOf(t *testing.T) {
+	t.Parallel()
+
+	older := metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := metav1.NewTime(time.Now())
+	managedFields := []metav1.ManagedFieldsEntry{
+		{
+			Manager:  "kubectl",
+			Time:     &older,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{},"f:paused":{}}}`)},
+		},
+		{
+			Manager:  "horizontal-pod-autoscaler",
+			Time:     &newer,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+		{
+			Manager:  "config-policy-controller.my-policy",
+			Time:     &newer,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:paused":{},"f:strategy":{}}}`)},
+		},
+	}
+	policyManager := "config-policy-controller.my-policy"
+
+	assert.Equal(
+		t, "horizontal-pod-autoscaler", fieldManagerOf(managedFields, []string{"spec", "replicas"}, policyManager),
+	)
+	assert.Equal(t, "kubectl", fieldManagerOf(managedFields, []string{"spec", "paused"}, policyManager))
+	assert.Equal(t, "", fieldManagerOf(managedFields, []string{"spec", "strategy"}, policyManager))
+	assert.Equal(t, "", fieldManagerOf(managedFields, []string{"spec", "replicas", "value"}, policyManager))
+
+	owned := ownedFields(
+		[][]string{{"spec", "paused"}, {"spec", "replicas"}, {"spec", "strategy"}}, managedFields, policyManager,
+	)
+	assert.Equal(
+		t,
+		"field spec.paused is owned by 'kubectl', field spec.replicas is owned by 'horizontal-pod-autoscaler'",
+		ownershipDescription(owned),
+	)
+
+	tolerated, others := splitTolerated(owned, []string{"horizontal-pod-autoscaler"})
+	assert.Equal(
+		t, []fieldOwnership{{path: []string{"spec", "replicas"}, manager: "horizontal-pod-autoscaler"}}, tolerated,
+	)
+	assert.Equal(t, []fieldOwnership{{path: []string{"spec", "paused"}, manager: "kubectl"}}, others)
+}
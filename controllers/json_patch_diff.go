@@ -0,0 +1,164 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// jsonPatchOperation is an operation of an RFC 6902 JSON Patch. The value is raw JSON so that a null value is kept.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// generateDiffInFormat returns the diff between the objects in the input format of the object template, which
+// defaults to a unified diff with the input number of context lines and hunk paths.
+func generateDiffInFormat(
+	existingObj, updatedObj *unstructured.Unstructured,
+	format policyv1.DiffFormat,
+	contextLines int,
+	hunkPaths bool,
+) (string, error) {
+	if format == policyv1.DiffFormatJSONPatch {
+		return generateJSONPatchDiff(existingObj, updatedObj)
+	}
+
+	return generateDiffWithContext(existingObj, updatedObj, contextLines, hunkPaths)
+}
+
+// generateJSONPatchDiff returns the differences between the objects as an RFC 6902 JSON Patch that transforms the
+// existing object into the updated object. The Secret values are redacted as in the unified diffs, so the changed
+// values are replaced with a redacted placeholder.
+func generateJSONPatchDiff(existingObj, updatedObj *unstructured.Unstructured) (string, error) {
+	if isSecret(existingObj) || isSecret(updatedObj) {
+		existingObj, updatedObj = redactSecretValues(existingObj, updatedObj)
+	}
+
+	operations, err := jsonPatchOperations(
+		"", canonicalDiffObject(existingObj.Object), canonicalDiffObject(updatedObj.Object),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the JSON patch diff: %w", err)
+	}
+
+	patch, err := json.Marshal(operations)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the JSON patch diff: %w", err)
+	}
+
+	return string(patch), nil
+}
+
+// jsonPatchOperations returns the JSON Patch operations that transform the existing value at the path into the
+// updated value. Maps are compared key by key in sorted order, and lists item by item, with the items beyond the end
+// of the shorter list added or removed. The items are removed from the end so that the indexes of the operations stay
+// valid when they're applied in order.
+func jsonPatchOperations(path string, existing, updated interface{}) ([]jsonPatchOperation, error) {
+	operations := []jsonPatchOperation{}
+
+	existingMap, existingIsMap := existing.(map[string]interface{})
+	updatedMap, updatedIsMap := updated.(map[string]interface{})
+
+	if existingIsMap && updatedIsMap {
+		keys := make([]string, 0, len(existingMap)+len(updatedMap))
+
+		for key := range existingMap {
+			keys = append(keys, key)
+		}
+
+		for key := range updatedMap {
+			if _, ok := existingMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyPath := path + "/" + escapeJSONPointer(key)
+			existingValue, inExisting := existingMap[key]
+			updatedValue, inUpdated := updatedMap[key]
+
+			var keyOperations []jsonPatchOperation
+			var err error
+
+			switch {
+			case !inUpdated:
+				keyOperations = []jsonPatchOperation{{Op: "remove", Path: keyPath}}
+			case !inExisting:
+				keyOperations, err = valueOperation("add", keyPath, updatedValue)
+			default:
+				keyOperations, err = jsonPatchOperations(keyPath, existingValue, updatedValue)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			operations = append(operations, keyOperations...)
+		}
+
+		return operations, nil
+	}
+
+	existingList, existingIsList := existing.([]interface{})
+	updatedList, updatedIsList := updated.([]interface{})
+
+	if existingIsList && updatedIsList {
+		for i := 0; i < len(existingList) && i < len(updatedList); i++ {
+			itemOperations, err := jsonPatchOperations(path+"/"+strconv.Itoa(i), existingList[i], updatedList[i])
+			if err != nil {
+				return nil, err
+			}
+
+			operations = append(operations, itemOperations...)
+		}
+
+		for i := len(existingList) - 1; i >= len(updatedList); i-- {
+			operations = append(operations, jsonPatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+
+		for i := len(existingList); i < len(updatedList); i++ {
+			itemOperations, err := valueOperation("add", path+"/"+strconv.Itoa(i), updatedList[i])
+			if err != nil {
+				return nil, err
+			}
+
+			operations = append(operations, itemOperations...)
+		}
+
+		return operations, nil
+	}
+
+	if reflect.DeepEqual(existing, updated) {
+		return operations, nil
+	}
+
+	return valueOperation("replace", path, updated)
+}
+
+// valueOperation returns the JSON Patch operation that sets the value at the path.
+func valueOperation(op string, path string, value interface{}) ([]jsonPatchOperation, error) {
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return []jsonPatchOperation{{Op: op, Path: path, Value: valueJSON}}, nil
+}
+
+// escapeJSONPointer escapes a key for a JSON Pointer path as described in RFC 6901.
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
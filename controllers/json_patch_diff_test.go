@@ -0,0 +1,145 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestGenerateJSONPatchDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		existing      map[string]interface{}
+		updated       map[string]interface{}
+		expectedPatch string
+	}{
+		"no changes": {
+			existing:      map[string]interface{}{"data": map[string]interface{}{"key": "value"}},
+			updated:       map[string]interface{}{"data": map[string]interface{}{"key": "value"}},
+			expectedPatch: `[]`,
+		},
+		"nested maps": {
+			existing: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "old"}},
+					},
+				},
+			},
+			updated: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"labels": map[string]interface{}{"app": "new", "tier": "web"},
+						},
+					},
+				},
+			},
+			expectedPatch: `[` +
+				`{"op":"replace","path":"/spec/template/metadata/labels/app","value":"new"},` +
+				`{"op":"add","path":"/spec/template/metadata/labels/tier","value":"web"}` +
+				`]`,
+		},
+		"list changes": {
+			existing: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"ports": []interface{}{
+						map[string]interface{}{"port": int64(80)},
+						map[string]interface{}{"port": int64(443)},
+					},
+				},
+			},
+			updated: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"ports": []interface{}{
+						map[string]interface{}{"port": int64(8080)},
+						map[string]interface{}{"port": int64(443)},
+						map[string]interface{}{"port": int64(8443)},
+					},
+				},
+			},
+			expectedPatch: `[` +
+				`{"op":"replace","path":"/spec/ports/0/port","value":8080},` +
+				`{"op":"add","path":"/spec/ports/2","value":{"port":8443}}` +
+				`]`,
+		},
+		"removals": {
+			existing: map[string]interface{}{
+				"data":       map[string]interface{}{"kept": "value", "removed": "value"},
+				"finalizers": []interface{}{"a", "b", "c"},
+			},
+			updated: map[string]interface{}{
+				"data":       map[string]interface{}{"kept": "value"},
+				"finalizers": []interface{}{"a"},
+			},
+			expectedPatch: `[` +
+				`{"op":"remove","path":"/data/removed"},` +
+				`{"op":"remove","path":"/finalizers/2"},` +
+				`{"op":"remove","path":"/finalizers/1"}` +
+				`]`,
+		},
+		"type change and null value": {
+			existing:      map[string]interface{}{"data": map[string]interface{}{"key": "value"}, "other": "value"},
+			updated:       map[string]interface{}{"data": "value", "other": nil},
+			expectedPatch: `[{"op":"replace","path":"/data","value":"value"},{"op":"replace","path":"/other","value":null}]`,
+		},
+		"escaped keys": {
+			existing: map[string]interface{}{
+				"metadata": map[string]interface{}{"annotations": map[string]interface{}{"example.com/a~b": "old"}},
+			},
+			updated: map[string]interface{}{
+				"metadata": map[string]interface{}{"annotations": map[string]interface{}{"example.com/a~b": "new"}},
+			},
+			expectedPatch: `[{"op":"replace","path":"/metadata/annotations/example.com~1a~0b","value":"new"}]`,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			patch, err := generateJSONPatchDiff(
+				&unstructured.Unstructured{Object: test.existing}, &unstructured.Unstructured{Object: test.updated},
+			)
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.expectedPatch, patch)
+		})
+	}
+}
+
+func TestGenerateJSONPatchDiffSecret(t *testing.T) {
+	t.Parallel()
+
+	existingObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]interface{}{
+			"unchanged": "c2FtZQ==",     // same
+			"changed":   "b2xkLXZhbHVl", // old-value
+		},
+	}}
+	updatedObj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]interface{}{
+			"unchanged": "c2FtZQ==",
+			"changed":   "bmV3LXZhbHVl", // new-value
+			"added":     "YWRkZWQ=",     // added
+		},
+	}}
+
+	patch, err := generateDiffInFormat(existingObj, updatedObj, policyv1.DiffFormatJSONPatch, 1, false)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"add","path":"/data/added","value":"<REDACTED>"},
+		{"op":"replace","path":"/data/changed","value":"<REDACTED: changed>"}
+	]`, patch)
+}
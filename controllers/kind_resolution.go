@@ -0,0 +1,130 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/restmapper"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// preferredGroupVersion returns the group and version to use for the input kind when the object template doesn't set
+// the apiVersion. Of the versions of an API group that serve the kind, the preferred version of the group is used. An
+// error is returned if no API group or more than one API group serves the kind, in which case the apiVersion must be
+// set.
+func (r *ConfigurationPolicyReconciler) preferredGroupVersion(kind string) (schema.GroupVersion, error) {
+	candidates := []schema.GroupVersion{}
+
+	r.lock.RLock()
+
+	for _, group := range r.apiGroups {
+		if version := servingVersion(group, kind); version != "" {
+			candidates = append(candidates, schema.GroupVersion{Group: group.Group.Name, Version: version})
+		}
+	}
+
+	r.lock.RUnlock()
+
+	switch len(candidates) {
+	case 0:
+		return schema.GroupVersion{}, fmt.Errorf(
+			"the kind %s can't be resolved since no API group on the cluster serves it", kind,
+		)
+	case 1:
+		return candidates[0], nil
+	default:
+		candidateStrs := make([]string, 0, len(candidates))
+
+		for _, candidate := range candidates {
+			candidateStrs = append(candidateStrs, candidate.String())
+		}
+
+		return schema.GroupVersion{}, fmt.Errorf(
+			"the kind %s can't be resolved since multiple API groups serve it, so the apiVersion must be set to one "+
+				"of: %s",
+			kind, strings.Join(candidateStrs, ", "),
+		)
+	}
+}
+
+// servingVersion returns the version of the API group that serves the kind, favoring the preferred version of the
+// group, or an empty string if the group doesn't serve it.
+func servingVersion(group *restmapper.APIGroupResources, kind string) string {
+	versions := []string{group.Group.PreferredVersion.Version}
+
+	for _, version := range group.Group.Versions {
+		if version.Version != group.Group.PreferredVersion.Version {
+			versions = append(versions, version.Version)
+		}
+	}
+
+	for _, version := range versions {
+		for _, resource := range group.VersionedResources[version] {
+			// Skip the subresources since they can have the same kind as their parent (e.g. the status)
+			if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+				return version
+			}
+		}
+	}
+
+	return ""
+}
+
+// resolveKindOnlyTemplates sets the apiVersion of the objectDefinitions that omit it or whose object template sets
+// kindOnly to the preferred group and version of the kind on the cluster. The object templates are copied before they
+// are modified. When the kind can't be resolved, the apiVersion is left unset so that the object template reports why
+// when its mapping is determined.
+func (r *ConfigurationPolicyReconciler) resolveKindOnlyTemplates(
+	objTemps []*policyv1.ObjectTemplate,
+) []*policyv1.ObjectTemplate {
+	resolved := make([]*policyv1.ObjectTemplate, 0, len(objTemps))
+
+	for _, objectT := range objTemps {
+		objDef := map[string]interface{}{}
+
+		// An invalid objectDefinition is reported when the object template is evaluated
+		if objectT == nil || json.Unmarshal(objectT.ObjectDefinition.Raw, &objDef) != nil {
+			resolved = append(resolved, objectT)
+
+			continue
+		}
+
+		apiVersion, _ := objDef["apiVersion"].(string)
+		kind, _ := objDef["kind"].(string)
+
+		if kind == "" || (apiVersion != "" && !objectT.KindOnly) {
+			resolved = append(resolved, objectT)
+
+			continue
+		}
+
+		gv, err := r.preferredGroupVersion(kind)
+		if err != nil {
+			delete(objDef, "apiVersion")
+		} else {
+			objDef["apiVersion"] = gv.String()
+
+			log.V(2).Info("Resolved the apiVersion of the object template", "kind", kind, "apiVersion", gv.String())
+		}
+
+		objDefJSON, err := json.Marshal(objDef)
+		if err != nil {
+			resolved = append(resolved, objectT)
+
+			continue
+		}
+
+		resolvedT := objectT.DeepCopy()
+		resolvedT.ObjectDefinition = runtime.RawExtension{Raw: objDefJSON}
+
+		resolved = append(resolved, resolvedT)
+	}
+
+	return resolved
+}
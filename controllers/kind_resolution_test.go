@@ -0,0 +1,146 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/restmapper"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func kindResolutionTestReconciler() *ConfigurationPolicyReconciler {
+	r := &ConfigurationPolicyReconciler{}
+	r.apiGroups = []*restmapper.APIGroupResources{
+		{
+			Group: metav1.APIGroup{
+				Name:             "",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1": {
+					{Name: "configmaps", Kind: "ConfigMap"},
+					{Name: "events", Kind: "Event"},
+				},
+			},
+		},
+		{
+			Group: metav1.APIGroup{
+				Name:             "networking.k8s.io",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}, {Version: "v1beta1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1beta1": {
+					{Name: "ingresses", Kind: "Ingress"},
+					{Name: "ingressclasses", Kind: "IngressClass"},
+				},
+				"v1": {
+					{Name: "ingresses", Kind: "Ingress"},
+					{Name: "ingresses/status", Kind: "Ingress"},
+				},
+			},
+		},
+		{
+			Group: metav1.APIGroup{
+				Name:             "events.k8s.io",
+				Versions:         []metav1.GroupVersionForDiscovery{{Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1": {{Name: "events", Kind: "Event"}},
+			},
+		},
+	}
+
+	return r
+}
+
+func TestPreferredGroupVersion(t *testing.T) {
+	t.Parallel()
+
+	r := kindResolutionTestReconciler()
+
+	tests := map[string]struct {
+		kind     string
+		expected schema.GroupVersion
+		errMsg   string
+	}{
+		"core kind": {
+			kind:     "ConfigMap",
+			expected: schema.GroupVersion{Version: "v1"},
+		},
+		"preferred version": {
+			kind:     "Ingress",
+			expected: schema.GroupVersion{Group: "networking.k8s.io", Version: "v1"},
+		},
+		"only served by a version that isn't preferred": {
+			kind:     "IngressClass",
+			expected: schema.GroupVersion{Group: "networking.k8s.io", Version: "v1beta1"},
+		},
+		"ambiguous kind": {
+			kind: "Event",
+			errMsg: "the kind Event can't be resolved since multiple API groups serve it, so the apiVersion must be " +
+				"set to one of: v1, events.k8s.io/v1",
+		},
+		"unknown kind": {
+			kind:   "Widget",
+			errMsg: "the kind Widget can't be resolved since no API group on the cluster serves it",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gv, err := r.preferredGroupVersion(test.kind)
+			if test.errMsg != "" {
+				assert.EqualError(t, err, test.errMsg)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, gv)
+		})
+	}
+}
+
+func TestResolveKindOnlyTemplates(t *testing.T) {
+	t.Parallel()
+
+	r := kindResolutionTestReconciler()
+	objTemps := []*policyv1.ObjectTemplate{
+		{ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"kind":"Ingress"}`)}},
+		{ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":"extensions/v1beta1","kind":"Ingress"}`)}},
+		{
+			ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":"extensions/v1beta1","kind":"Ingress"}`)},
+			KindOnly:         true,
+		},
+		{
+			ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Event"}`)},
+			KindOnly:         true,
+		},
+	}
+
+	resolved := r.resolveKindOnlyTemplates(objTemps)
+
+	assert.Len(t, resolved, 4)
+	assert.JSONEq(t, `{"apiVersion":"networking.k8s.io/v1","kind":"Ingress"}`, string(resolved[0].ObjectDefinition.Raw))
+	// The apiVersion that is set is used as is unless kindOnly is set
+	assert.Same(t, objTemps[1], resolved[1])
+	assert.JSONEq(t, `{"apiVersion":"networking.k8s.io/v1","kind":"Ingress"}`, string(resolved[2].ObjectDefinition.Raw))
+	// The apiVersion is removed when the kind is ambiguous so that the object template reports it
+	assert.JSONEq(t, `{"kind":"Event"}`, string(resolved[3].ObjectDefinition.Raw))
+
+	// The input object templates aren't modified
+	assert.JSONEq(t, `{"kind":"Ingress"}`, string(objTemps[0].ObjectDefinition.Raw))
+}
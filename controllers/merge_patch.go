@@ -0,0 +1,106 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/dynamic"
+)
+
+// mergePatch returns a JSON merge patch with only the paths that differ between the original and the modified object.
+// The fields the controller never sets, such as the managed fields and the status, are left out. The returned boolean
+// is false when a merge patch can't express the change, in which case the object must be updated instead. This is the
+// case when a list changes, since a merge patch replaces the whole list without the resourceVersion and could drop
+// the entries added to the list after the object was compared, while the update fails on a conflict instead.
+func mergePatch(original, modified *unstructured.Unstructured) ([]byte, bool, error) {
+	originalJSON, err := json.Marshal(fieldsForMergePatch(original).Object)
+	if err != nil {
+		return nil, false, err
+	}
+
+	modifiedJSON, err := json.Marshal(fieldsForMergePatch(modified).Object)
+	if err != nil {
+		return nil, false, err
+	}
+
+	patch, err := jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
+	if err != nil {
+		return nil, false, err
+	}
+
+	patchObj := map[string]interface{}{}
+
+	if err := json.Unmarshal(patch, &patchObj); err != nil {
+		return nil, false, err
+	}
+
+	if containsList(patchObj) {
+		return nil, false, nil
+	}
+
+	return patch, true, nil
+}
+
+// fieldsForMergePatch returns a copy of the object without the fields that aren't compared or are managed by the API
+// server, so they are never part of a merge patch.
+func fieldsForMergePatch(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	objCopy := obj.DeepCopy()
+
+	removeFieldsForComparison(objCopy)
+	unstructured.RemoveNestedField(objCopy.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(objCopy.Object, "status")
+
+	return objCopy
+}
+
+// containsList returns true if the map of a merge patch has a list nested in it, meaning the patch replaces the list.
+func containsList(patch map[string]interface{}) bool {
+	for _, value := range patch {
+		switch value := value.(type) {
+		case []interface{}:
+			return true
+		case map[string]interface{}:
+			if containsList(value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// patchOrUpdateObject enforces the merged object with a JSON merge patch of only the changed fields, so the request is
+// small and doesn't fail on a conflict when the object changed in an unrelated way after it was retrieved. It falls
+// back to an update of the whole object when a merge patch can't express the change.
+func patchOrUpdateObject(
+	res dynamic.ResourceInterface,
+	original *unstructured.Unstructured,
+	merged *unstructured.Unstructured,
+	fieldManager string,
+) (*unstructured.Unstructured, error) {
+	patch, patchable, err := mergePatch(original, merged)
+	if err != nil {
+		log.Info(
+			"Failed to compute the merge patch, so updating the whole object instead",
+			"name", merged.GetName(), "error", err.Error(),
+		)
+	}
+
+	if !patchable {
+		return res.Update(context.TODO(), merged, metav1.UpdateOptions{
+			FieldManager:    fieldManager,
+			FieldValidation: metav1.FieldValidationStrict,
+		})
+	}
+
+	return res.Patch(context.TODO(), merged.GetName(), types.MergePatchType, patch, metav1.PatchOptions{
+		FieldManager:    fieldManager,
+		FieldValidation: metav1.FieldValidationStrict,
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func mergePatchTestObj(data map[string]interface{}, finalizers []interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "my-configmap",
+			"namespace":       "default",
+			"resourceVersion": "1",
+			"managedFields": []interface{}{
+				map[string]interface{}{"manager": "kubectl", "operation": "Update"},
+			},
+		},
+		"data": data,
+	}}
+
+	if finalizers != nil {
+		obj.Object["metadata"].(map[string]interface{})["finalizers"] = finalizers
+	}
+
+	return obj
+}
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+
+	original := mergePatchTestObj(
+		map[string]interface{}{"unchanged": "value", "changed": "old", "removed": "value"}, []interface{}{"a"},
+	)
+
+	tests := map[string]struct {
+		modified      *unstructured.Unstructured
+		expectedPatch string
+		patchable     bool
+	}{
+		"changed field": {
+			modified: mergePatchTestObj(
+				map[string]interface{}{"unchanged": "value", "changed": "new", "removed": "value"}, []interface{}{"a"},
+			),
+			expectedPatch: `{"data":{"changed":"new"}}`,
+			patchable:     true,
+		},
+		"removed field": {
+			modified: mergePatchTestObj(
+				map[string]interface{}{"unchanged": "value", "changed": "old"}, []interface{}{"a"},
+			),
+			expectedPatch: `{"data":{"removed":null}}`,
+			patchable:     true,
+		},
+		"added list entry": {
+			modified: mergePatchTestObj(
+				map[string]interface{}{"unchanged": "value", "changed": "old", "removed": "value"},
+				[]interface{}{"a", "b"},
+			),
+			patchable: false,
+		},
+		"replaced list": {
+			modified: mergePatchTestObj(
+				map[string]interface{}{"unchanged": "value", "changed": "old", "removed": "value"}, []interface{}{"b"},
+			),
+			patchable: false,
+		},
+		"ignores managed fields and the resourceVersion": {
+			modified: func() *unstructured.Unstructured {
+				modified := mergePatchTestObj(
+					map[string]interface{}{"unchanged": "value", "changed": "new", "removed": "value"},
+					[]interface{}{"a"},
+				)
+				removeFieldsForComparison(modified)
+				modified.SetResourceVersion("2")
+
+				return modified
+			}(),
+			expectedPatch: `{"data":{"changed":"new"}}`,
+			patchable:     true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			patch, patchable, err := mergePatch(original, test.modified)
+			assert.NoError(t, err)
+			assert.Equal(t, test.patchable, patchable)
+
+			if test.patchable {
+				assert.JSONEq(t, test.expectedPatch, string(patch))
+			}
+		})
+	}
+}
+
+func TestPatchOrUpdateObject(t *testing.T) {
+	t.Parallel()
+
+	existing := mergePatchTestObj(map[string]interface{}{"unchanged": "value", "changed": "old"}, []interface{}{"a"})
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), existing.DeepCopy())
+
+	var patches []string
+
+	updates := 0
+
+	client.PrependReactor("patch", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(clienttesting.PatchAction)
+
+		assert.Equal(t, types.MergePatchType, patchAction.GetPatchType())
+		patches = append(patches, string(patchAction.GetPatch()))
+
+		return false, nil, nil
+	})
+	client.PrependReactor("update", "configmaps", func(_ clienttesting.Action) (bool, runtime.Object, error) {
+		updates++
+
+		return false, nil, nil
+	})
+
+	res := client.Resource(gvr).Namespace("default")
+
+	merged := existing.DeepCopy()
+	merged.Object["data"].(map[string]interface{})["changed"] = "new"
+
+	updatedObj, err := patchOrUpdateObject(res, existing, merged, "policy-controller")
+	assert.NoError(t, err)
+	assert.Equal(t, "new", updatedObj.Object["data"].(map[string]interface{})["changed"])
+	assert.Equal(t, "value", updatedObj.Object["data"].(map[string]interface{})["unchanged"])
+
+	// Only the changed field is sent to the API server
+	assert.Len(t, patches, 1)
+	assert.JSONEq(t, `{"data":{"changed":"new"}}`, patches[0])
+	assert.Equal(t, 0, updates)
+
+	// A list change falls back to an update at the compared resourceVersion so that a concurrent change to the list
+	// results in a conflict rather than being lost
+	merged = updatedObj.DeepCopy()
+	merged.SetFinalizers([]string{"a", "b"})
+
+	_, err = patchOrUpdateObject(res, updatedObj, merged, "policy-controller")
+	assert.NoError(t, err)
+	assert.Len(t, patches, 1)
+	assert.Equal(t, 1, updates)
+}
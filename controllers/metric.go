@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -35,6 +36,22 @@ var (
 		},
 		[]string{"name"},
 	)
+	policyEvalResultCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "config_policy_evaluation_results_total",
+			Help: "The total number of evaluations of the configuration policy by the resulting compliance state",
+		},
+		[]string{"name", "result"},
+	)
+	policyEvalHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "config_policy_evaluation_duration_seconds",
+			Help: "The seconds that it takes to evaluate the configuration policy, from resolving its templates " +
+				"through writing its status",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"name"},
+	)
 	plcTempsProcessSecondsCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "config_policy_templates_process_seconds_total",
@@ -51,6 +68,23 @@ var (
 		},
 		[]string{"name"},
 	)
+	templateResolutionHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "config_policy_template_resolution_duration_seconds",
+			Help: "The seconds that it takes to resolve the templates of the configuration policy, whether or not " +
+				"the resolution succeeds",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"name"},
+	)
+	templateResolutionErrorsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "config_policy_template_resolution_errors_total",
+			Help: "The number of template resolution errors of the configuration policy by the class of the error: " +
+				"parse, missing-object, denied-lookup, limit-exceeded, or other",
+		},
+		[]string{"name", "class"},
+	)
 	compareObjSecondsCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "compare_objects_seconds_total",
@@ -67,6 +101,46 @@ var (
 		},
 		[]string{"config_policy_name", "namespace", "object"},
 	)
+	policyComplianceGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "config_policy_compliance",
+			Help: "The compliance state of the configuration policy, which is 1 when it's compliant, 0 when it's " +
+				"noncompliant, and -1 when it's pending or unknown",
+		},
+		[]string{"name", "namespace"},
+	)
+	apiRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "config_policy_api_requests_total",
+			Help: "The number of API requests issued by the controller, by the verb and the resource of the requests. " +
+				"The reads served from a cache aren't included. Use this alongside " +
+				"config_policy_api_request_duration_seconds.",
+		},
+		[]string{"verb", "kind", "controller"},
+	)
+	apiRequestDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "config_policy_api_request_duration_seconds",
+			Help:    "The seconds that the API requests issued by the controller take",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"verb", "kind", "controller"},
+	)
+	dynamicWatcherWatchesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "config_policy_dynamic_watcher_watches",
+			Help: "The number of active API watches of the dependency watcher of the controller",
+		},
+		[]string{"controller"},
+	)
+	dynamicWatcherQueriedObjectsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "config_policy_dynamic_watcher_queried_objects",
+			Help: "The number of distinct objects returned by the last dependency watcher queries of the policies " +
+				"of the controller",
+		},
+		[]string{"controller"},
+	)
 	// The policyRelatedObjectMap collects a map of related objects to policies
 	// in order to populate the gauge:
 	//   <kind.version/namespace/name>: []<policy-namespace/policy-name>
@@ -81,6 +155,53 @@ var (
 			"policy",
 		},
 	)
+	suppressedComplianceEventsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "policy_suppressed_compliance_events_total",
+			Help: "The number of compliance events that weren't emitted since they repeated the last compliance " +
+				"event of the policy within the deduplication window",
+		},
+		[]string{"policy"},
+	)
+	droppedComplianceEventsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "policy_dropped_compliance_events_total",
+			Help: "The number of compliance events that were never created since they failed permanently, the " +
+				"queue of the compliance events to resend was full, or the policy was deleted before they were resent",
+		},
+		[]string{"policy"},
+	)
+	emittedComplianceEventsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "compliance_events_emitted_total",
+			Help: "The number of compliance events created for the policy, or counted in the series of an existing " +
+				"compliance event, by the compliance state they report",
+		},
+		[]string{"policy", "compliance"},
+	)
+	failedComplianceEventsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "compliance_events_failed_total",
+			Help: "The number of times that creating a compliance event of the policy failed after the retries. Use " +
+				"this alongside policy_dropped_compliance_events_total for the events that were never created.",
+		},
+		[]string{"policy"},
+	)
+	complianceHistoryDroppedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "compliance_history_events_dropped_total",
+			Help: "The number of compliance events that were never sent to the compliance history API, by the reason " +
+				"(queue_full, rejected, unauthorized, or retries_exhausted)",
+		},
+		[]string{"reason"},
+	)
+	complianceHistoryAuthFailureGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "compliance_history_auth_failure",
+			Help: "1 when the last response of the compliance history API refused the credentials with a 401 or 403 " +
+				"status code, and 0 once a request is accepted",
+		},
+	)
 	policyUserErrorsCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "policy_user_errors_total",
@@ -89,6 +210,7 @@ var (
 		[]string{
 			"policy",
 			"template",
+			"kind",
 			"type",
 		},
 	)
@@ -110,11 +232,26 @@ func init() {
 	metrics.Registry.MustRegister(evalLoopHistogram)
 	metrics.Registry.MustRegister(policyEvalSecondsCounter)
 	metrics.Registry.MustRegister(policyEvalCounter)
+	metrics.Registry.MustRegister(policyEvalResultCounter)
+	metrics.Registry.MustRegister(policyEvalHistogram)
+	metrics.Registry.MustRegister(policyComplianceGauge)
+	metrics.Registry.MustRegister(apiRequestsCounter)
+	metrics.Registry.MustRegister(apiRequestDurationHistogram)
+	metrics.Registry.MustRegister(dynamicWatcherWatchesGauge)
+	metrics.Registry.MustRegister(dynamicWatcherQueriedObjectsGauge)
 	metrics.Registry.MustRegister(plcTempsProcessSecondsCounter)
 	metrics.Registry.MustRegister(plcTempsProcessCounter)
+	metrics.Registry.MustRegister(templateResolutionHistogram)
+	metrics.Registry.MustRegister(templateResolutionErrorsCounter)
 	metrics.Registry.MustRegister(compareObjSecondsCounter)
 	metrics.Registry.MustRegister(compareObjEvalCounter)
 	metrics.Registry.MustRegister(policyRelatedObjectGauge)
+	metrics.Registry.MustRegister(suppressedComplianceEventsCounter)
+	metrics.Registry.MustRegister(droppedComplianceEventsCounter)
+	metrics.Registry.MustRegister(emittedComplianceEventsCounter)
+	metrics.Registry.MustRegister(failedComplianceEventsCounter)
+	metrics.Registry.MustRegister(complianceHistoryDroppedCounter)
+	metrics.Registry.MustRegister(complianceHistoryAuthFailureGauge)
 	// Error metrics may already be registered by template sync
 	alreadyReg := &prometheus.AlreadyRegisteredError{}
 
@@ -129,6 +266,70 @@ func init() {
 	}
 }
 
+// recordPolicyEvaluation records the evaluation of the configuration policy that took the duration and resulted in
+// the compliance state in the evaluation metrics.
+func recordPolicyEvaluation(name string, compliance policyv1.ComplianceState, duration time.Duration) {
+	if compliance == "" {
+		compliance = policyv1.UnknownCompliancy
+	}
+
+	policyEvalSecondsCounter.WithLabelValues(name).Add(duration.Seconds())
+	policyEvalCounter.WithLabelValues(name).Inc()
+	policyEvalResultCounter.WithLabelValues(name, string(compliance)).Inc()
+	policyEvalHistogram.WithLabelValues(name).Observe(duration.Seconds())
+}
+
+// deletePolicyEvaluationMetrics removes the evaluation metrics of the deleted configuration policy.
+func deletePolicyEvaluationMetrics(name string) {
+	_ = policyEvalSecondsCounter.DeleteLabelValues(name)
+	_ = policyEvalCounter.DeleteLabelValues(name)
+	_ = policyEvalResultCounter.DeletePartialMatch(prometheus.Labels{"name": name})
+	_ = policyEvalHistogram.DeleteLabelValues(name)
+}
+
+// recordTemplateResolution records the template resolution of the configuration policy that started at the start
+// time in the template resolution metrics. The error is the one that stopped the resolution, if any.
+func recordTemplateResolution(name string, start time.Time, tplErr error) {
+	templateResolutionHistogram.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+	if tplErr != nil {
+		recordTemplateResolutionError(name, tplErr)
+	}
+}
+
+// recordTemplateResolutionError counts the template resolution error of the configuration policy by its class.
+func recordTemplateResolutionError(name string, tplErr error) {
+	templateResolutionErrorsCounter.WithLabelValues(name, templateErrorClass(tplErr)).Inc()
+}
+
+// deleteTemplateResolutionMetrics removes the template resolution metrics of the deleted configuration policy.
+func deleteTemplateResolutionMetrics(name string) {
+	_ = templateResolutionHistogram.DeleteLabelValues(name)
+	_ = templateResolutionErrorsCounter.DeletePartialMatch(prometheus.Labels{"name": name})
+}
+
+// setPolicyComplianceMetric sets the compliance metric of the configuration policy to its compliance state in its
+// status.
+func setPolicyComplianceMetric(policy *policyv1.ConfigurationPolicy) {
+	var value float64
+
+	switch policy.Status.ComplianceState {
+	case policyv1.Compliant:
+		value = 1
+	case policyv1.NonCompliant:
+		value = 0
+	default:
+		value = -1
+	}
+
+	policyComplianceGauge.WithLabelValues(policy.Name, policy.Namespace).Set(value)
+}
+
+// deletePolicyComplianceMetric removes the compliance metric of the deleted configuration policy.
+func deletePolicyComplianceMetric(namespace string, name string) {
+	_ = policyComplianceGauge.DeleteLabelValues(name, namespace)
+}
+
 // updateRelatedObjectMetric iterates through the collected related object map, deletes any metrics
 // that aren't duplications, and sets a metric for any related object that is handled by multiple
 // policies to the number of policies that currently handles it.
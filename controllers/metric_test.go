@@ -0,0 +1,87 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// gatheredPolicyCompliance returns the value of the config_policy_compliance metric of the policy from the metrics
+// registry, and whether it was found.
+func gatheredPolicyCompliance(t *testing.T, namespace string, name string) (float64, bool) {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	assert.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "config_policy_compliance" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["name"] == name && labels["namespace"] == namespace {
+				return metric.GetGauge().GetValue(), true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func TestPolicyComplianceMetric(t *testing.T) {
+	t.Parallel()
+
+	s := runtime.NewScheme()
+	assert.NoError(t, policyv1.AddToScheme(s))
+
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "compliance-metric", Namespace: "managed"},
+	}
+
+	r := &ConfigurationPolicyReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(policy).Build()}
+
+	_, found := gatheredPolicyCompliance(t, "managed", "compliance-metric")
+	assert.False(t, found)
+
+	transitions := []struct {
+		compliance policyv1.ComplianceState
+		expected   float64
+	}{
+		{"", -1},
+		{policyv1.NonCompliant, 0},
+		{policyv1.Compliant, 1},
+		{policyv1.Pending, -1},
+		{policyv1.NonCompliant, 0},
+	}
+
+	for _, transition := range transitions {
+		policy.Status.ComplianceState = transition.compliance
+
+		assert.NoError(t, r.updatePolicyStatus(policy, false))
+
+		value, found := gatheredPolicyCompliance(t, "managed", "compliance-metric")
+		assert.True(t, found)
+		assert.Equal(t, transition.expected, value, "the compliance %s has the wrong value", transition.compliance)
+	}
+
+	// The metric is removed when the policy is deleted
+	deletePolicyComplianceMetric("managed", "compliance-metric")
+
+	_, found = gatheredPolicyCompliance(t, "managed", "compliance-metric")
+	assert.False(t, found)
+}
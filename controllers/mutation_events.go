@@ -0,0 +1,155 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
+)
+
+const (
+	// The reasons of the mutation events, which are emitted on the objects that the policies create, update, or
+	// delete so that the changes can be attributed to a policy
+	eventReasonPolicyCreated   = "CreatedByPolicy"
+	eventReasonPolicyUpdated   = "UpdatedByPolicy"
+	eventReasonPolicyRecreated = "RecreatedByPolicy"
+	eventReasonPolicyDeleted   = "DeletedByPolicy"
+	// The annotations on the mutation events that identify the policy
+	mutationPolicyKindAnnotation      = "policy.open-cluster-management.io/policy-kind"
+	mutationPolicyNameAnnotation      = "policy.open-cluster-management.io/policy-name"
+	mutationPolicyNamespaceAnnotation = "policy.open-cluster-management.io/policy-namespace"
+)
+
+// mutationEventActions are the verbs of the mutation event messages for each reason.
+var mutationEventActions = map[string]string{
+	eventReasonPolicyCreated:   "created",
+	eventReasonPolicyUpdated:   "updated",
+	eventReasonPolicyRecreated: "recreated",
+	eventReasonPolicyDeleted:   "deleted",
+}
+
+// newMutationEvent returns the event on the object recording that the policy mutated it for the reason. The detail is
+// appended to the message. The event of a cluster scoped object is in the default namespace, like the events of the
+// client-go recorders.
+func newMutationEvent(
+	object client.Object, policyKind string, policy metav1.Object, reason string, detail string, instanceName string,
+) *corev1.Event {
+	now := time.Now()
+	gvk := object.GetObjectKind().GroupVersionKind()
+
+	eventNamespace := object.GetNamespace()
+	if eventNamespace == "" {
+		eventNamespace = metav1.NamespaceDefault
+	}
+
+	msg := fmt.Sprintf(
+		"%s %s was %s by the %s %s/%s",
+		gvk.Kind, identifierStr([]string{object.GetName()}, object.GetNamespace()), mutationEventActions[reason],
+		policyKind, policy.GetNamespace(), policy.GetName(),
+	)
+
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// This event name matches the convention of recorders from client-go
+			Name:      fmt.Sprintf("%v.%x", object.GetName(), now.UnixNano()),
+			Namespace: eventNamespace,
+			Annotations: map[string]string{
+				mutationPolicyKindAnnotation:      policyKind,
+				mutationPolicyNameAnnotation:      policy.GetName(),
+				mutationPolicyNamespaceAnnotation: policy.GetNamespace(),
+			},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       gvk.Kind,
+			Namespace:  object.GetNamespace(),
+			Name:       object.GetName(),
+			UID:        object.GetUID(),
+			APIVersion: gvk.GroupVersion().String(),
+		},
+		Reason:  reason,
+		Message: msg + detail,
+		Source: corev1.EventSource{
+			Component: ControllerName,
+			Host:      instanceName,
+		},
+		FirstTimestamp:      metav1.NewTime(now),
+		LastTimestamp:       metav1.NewTime(now),
+		Count:               1,
+		Type:                "Normal",
+		Action:              reason,
+		ReportingController: ControllerName,
+		ReportingInstance:   instanceName,
+	}
+}
+
+// sendMutationEvent emits the mutation event on the object that the policy created, updated, or deleted when
+// MutationEvents is set. It's called once per create, update, or delete request, including the deletions of the
+// purged and pruned objects. The object template is nil for the pruned objects, which have no deletion record. The
+// event is created on the cluster of the object, and a failure is only logged.
+func (r *ConfigurationPolicyReconciler) sendMutationEvent(
+	obj singleObject, objectT *policyv1.ObjectTemplate, mutatedObj *unstructured.Unstructured, reason string,
+) {
+	if !r.MutationEvents || mutatedObj == nil {
+		return
+	}
+
+	// Point to where the diff of the update or the deletion record was written
+	entryName := ""
+
+	switch {
+	case reason == eventReasonPolicyDeleted && objectT != nil:
+		entryName = "deletion record"
+	case reason == eventReasonPolicyUpdated && objectT.RecordDiff == policyv1.RecordDiffLog &&
+		diffSuppressedNote(obj, objectT) == "":
+		entryName = "diff"
+	}
+
+	detail := ""
+
+	if entryName != "" {
+		if r.DiffLog != nil {
+			detail = "; the " + entryName + " was written to " + r.DiffLog.Path
+		} else {
+			detail = "; the " + entryName + " was written to the controller log"
+		}
+	}
+
+	event := newMutationEvent(mutatedObj, "ConfigurationPolicy", obj.policy, reason, detail, r.InstanceName)
+
+	_, err := r.TargetK8sClient.CoreV1().Events(event.Namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	if err != nil {
+		log.Error(
+			err, "Failed to emit the event on the object mutated by the policy",
+			"policy", obj.policy.Name, "kind", mutatedObj.GetKind(), "name", obj.name, "namespace", obj.namespace,
+		)
+	}
+}
+
+// sendMutationEvent emits the mutation event on the object that the policy created or updated when MutationEvents
+// is set. A failure is only logged.
+func (r *OperatorPolicyReconciler) sendMutationEvent(
+	ctx context.Context, policy *policyv1beta1.OperatorPolicy, object client.Object, reason string, detail string,
+) {
+	if !r.MutationEvents {
+		return
+	}
+
+	event := newMutationEvent(object, "OperatorPolicy", policy, reason, detail, r.InstanceName)
+
+	if err := r.Create(ctx, event); err != nil {
+		log.Error(
+			err, "Failed to emit the event on the object mutated by the policy",
+			"policy", policy.Name, "kind", event.InvolvedObject.Kind, "name", object.GetName(),
+			"namespace", object.GetNamespace(),
+		)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
+)
+
+func TestNewMutationEvent(t *testing.T) {
+	t.Parallel()
+
+	plc := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}}
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "app", "namespace": "default", "uid": "app-uid"},
+	}}
+
+	event := newMutationEvent(
+		deployment, "ConfigurationPolicy", plc, eventReasonPolicyUpdated, "; the diff was written to the controller log",
+		"controller",
+	)
+
+	assert.Equal(t, "default", event.Namespace)
+	assert.Equal(
+		t,
+		corev1.ObjectReference{
+			Kind: "Deployment", Namespace: "default", Name: "app", UID: "app-uid", APIVersion: "apps/v1",
+		},
+		event.InvolvedObject,
+	)
+	assert.Equal(t, eventReasonPolicyUpdated, event.Reason)
+	assert.Equal(
+		t,
+		"Deployment [app] in namespace default was updated by the ConfigurationPolicy managed/policy; the diff was "+
+			"written to the controller log",
+		event.Message,
+	)
+	assert.Equal(
+		t,
+		map[string]string{
+			mutationPolicyKindAnnotation:      "ConfigurationPolicy",
+			mutationPolicyNameAnnotation:      "policy",
+			mutationPolicyNamespaceAnnotation: "managed",
+		},
+		event.Annotations,
+	)
+
+	// The event of a cluster scoped object is in the default namespace
+	namespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": "app-ns"},
+	}}
+
+	event = newMutationEvent(namespace, "ConfigurationPolicy", plc, eventReasonPolicyDeleted, "", "controller")
+
+	assert.Equal(t, metav1.NamespaceDefault, event.Namespace)
+	assert.Equal(t, "", event.InvolvedObject.Namespace)
+	assert.Equal(t, "Namespace [app-ns] was deleted by the ConfigurationPolicy managed/policy", event.Message)
+}
+
+func TestSendMutationEvent(t *testing.T) {
+	t.Parallel()
+
+	targetClient := testclient.NewSimpleClientset()
+	r := &ConfigurationPolicyReconciler{TargetK8sClient: targetClient, InstanceName: "controller"}
+
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "cm", "namespace": "default"},
+	}}
+	obj := singleObject{
+		policy:      &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}},
+		existingObj: configMap,
+		name:        "cm",
+		namespace:   "default",
+	}
+	objectT := &policyv1.ObjectTemplate{RecordDiff: policyv1.RecordDiffLog}
+
+	// No event is emitted unless the option is enabled
+	r.sendMutationEvent(obj, objectT, configMap, eventReasonPolicyUpdated)
+
+	events, err := targetClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, events.Items)
+
+	r.MutationEvents = true
+	r.sendMutationEvent(obj, objectT, configMap, eventReasonPolicyUpdated)
+
+	events, err = targetClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+
+	if assert.Len(t, events.Items, 1) {
+		assert.Equal(t, "cm", events.Items[0].InvolvedObject.Name)
+		assert.Equal(
+			t,
+			"ConfigMap [cm] in namespace default was updated by the ConfigurationPolicy managed/policy; the diff "+
+				"was written to the controller log",
+			events.Items[0].Message,
+		)
+	}
+
+	// The OperatorPolicy events are created with the controller's client
+	opReconciler := &OperatorPolicyReconciler{
+		Client: fake.NewClientBuilder().Build(), InstanceName: "controller", MutationEvents: true,
+	}
+	opPolicy := &policyv1beta1.OperatorPolicy{ObjectMeta: metav1.ObjectMeta{Name: "op-policy", Namespace: "managed"}}
+
+	subscription := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "Subscription",
+		"metadata":   map[string]interface{}{"name": "my-operator", "namespace": "operators"},
+	}}
+
+	opReconciler.sendMutationEvent(context.TODO(), opPolicy, subscription, eventReasonPolicyCreated, "")
+
+	opEvents := &corev1.EventList{}
+	assert.NoError(t, opReconciler.List(context.TODO(), opEvents))
+
+	if assert.Len(t, opEvents.Items, 1) {
+		assert.Equal(t, "operators", opEvents.Items[0].Namespace)
+		assert.Equal(
+			t,
+			"Subscription [my-operator] in namespace operators was created by the OperatorPolicy managed/op-policy",
+			opEvents.Items[0].Message,
+		)
+	}
+}
+
+func TestDeletionMutationEvents(t *testing.T) {
+	t.Parallel()
+
+	newConfigMap := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		}}
+	}
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	targetClient := testclient.NewSimpleClientset()
+	r := &ConfigurationPolicyReconciler{
+		TargetK8sClient: targetClient,
+		TargetK8sDynamicClient: dynamicfake.NewSimpleDynamicClient(
+			runtime.NewScheme(), newConfigMap("deleted"), newConfigMap("purged"), newConfigMap("pruned"),
+		),
+		InstanceName:   "controller",
+		MutationEvents: true,
+		apiGroups: []*restmapper.APIGroupResources{{
+			Group: metav1.APIGroup{
+				Versions:         []metav1.GroupVersionForDiscovery{{GroupVersion: "v1", Version: "v1"}},
+				PreferredVersion: metav1.GroupVersionForDiscovery{GroupVersion: "v1", Version: "v1"},
+			},
+			VersionedResources: map[string][]metav1.APIResource{
+				"v1": {{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"}},
+			},
+		}},
+	}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			RemediationAction:   policyv1.Enforce,
+			PruneObjectBehavior: "DeleteAll",
+		},
+	}
+	objectT := &policyv1.ObjectTemplate{ComplianceType: policyv1.MustNotHave}
+
+	// A mustnothave object template
+	obj := singleObject{
+		policy:      policy,
+		gvr:         gvr,
+		existingObj: newConfigMap("deleted"),
+		name:        "deleted",
+		namespace:   "default",
+		namespaced:  true,
+	}
+
+	completed, reason, _, _, err := r.enforceByCreatingOrDeleting(obj, objectT)
+	assert.NoError(t, err)
+	assert.True(t, completed)
+	assert.Equal(t, reasonDeleteSuccess, reason)
+
+	// A mustnothave object template that purges the objects matching an objectSelector
+	mapping := &meta.RESTMapping{
+		Resource:         gvr,
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Scope:            meta.RESTScopeNamespace,
+	}
+	objDetails := objectTemplateDetails{kind: "ConfigMap", isNamespaced: true}
+
+	_, result := r.handleObjectPurge(
+		objectT, []string{"purged"}, "default", objDetails, policy, mapping, policyv1.Enforce,
+	)
+	assert.Equal(t, reasonPurgeSuccess, result.events[0].reason)
+
+	// A related object pruned when the policy is deleted
+	policy.Status.RelatedObjects = []policyv1.RelatedObject{{
+		Object: policyv1.ObjectResource{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+			Metadata:   policyv1.ObjectMetadata{Name: "pruned", Namespace: "default"},
+		},
+	}}
+
+	failures, skipped := r.cleanUpChildObjects(*policy, nil)
+	assert.Empty(t, failures)
+	assert.Empty(t, skipped)
+
+	events, err := targetClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+
+	messages := []string{}
+
+	for _, event := range events.Items {
+		assert.Equal(t, eventReasonPolicyDeleted, event.Reason)
+
+		messages = append(messages, event.Message)
+	}
+
+	// Each deletion request has a single event, and only the object templates have a deletion record
+	assert.ElementsMatch(
+		t,
+		[]string{
+			"ConfigMap [deleted] in namespace default was deleted by the ConfigurationPolicy managed/policy; the " +
+				"deletion record was written to the controller log",
+			"ConfigMap [purged] in namespace default was deleted by the ConfigurationPolicy managed/policy; the " +
+				"deletion record was written to the controller log",
+			"ConfigMap [pruned] in namespace default was deleted by the ConfigurationPolicy managed/policy",
+		},
+		messages,
+	)
+}
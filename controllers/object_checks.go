@@ -0,0 +1,221 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	reasonObjectChecksFailed = "Resource found but does not meet the object checks"
+	// objectChecksFailedMsg is included in the message when an object doesn't meet the object checks, which is how the
+	// reason is determined.
+	objectChecksFailedMsg = "found but does not meet the object checks"
+)
+
+// objectCheck is a parsed entry of an object template's objectChecks.
+type objectCheck struct {
+	path    []string
+	pointer string
+	pattern string
+	regex   *regexp.Regexp
+	minimum *int64
+	maximum *int64
+	exists  *bool
+}
+
+// fieldValue is a value found at the path of an object check. The pointer is the JSON pointer to the value, where the
+// '*' keys of the path are replaced with the index of the list item.
+type fieldValue struct {
+	pointer string
+	value   interface{}
+}
+
+// parseObjectChecks validates an object template's objectChecks and converts them to objectCheck entries. The regular
+// expressions must fully match the values. An error is returned if an entry is invalid.
+func parseObjectChecks(checks []policyv1.ObjectCheck) ([]objectCheck, error) {
+	parsed := make([]objectCheck, 0, len(checks))
+
+	for _, check := range checks {
+		if err := check.Validate(); err != nil {
+			return nil, err
+		}
+
+		keys, err := splitJSONPointer(check.Path, "objectChecks")
+		if err != nil {
+			return nil, err
+		}
+
+		entry := objectCheck{
+			path:    keys,
+			pointer: check.Path,
+			pattern: check.Regex,
+			minimum: check.Minimum,
+			maximum: check.Maximum,
+			exists:  check.Exists,
+		}
+
+		if check.Regex != "" {
+			// The regular expression was validated above
+			entry.regex = regexp.MustCompile("^(?:" + check.Regex + ")$")
+		}
+
+		parsed = append(parsed, entry)
+	}
+
+	return parsed, nil
+}
+
+// objectCheckFailures returns a description of each expectation of the object checks that the object doesn't meet.
+func objectCheckFailures(checks []objectCheck, obj *unstructured.Unstructured) []string {
+	failures := []string{}
+
+	for _, check := range checks {
+		failures = append(failures, check.failures(obj.Object)...)
+	}
+
+	return failures
+}
+
+// failures returns a description of each expectation of the object check that the object doesn't meet.
+func (c objectCheck) failures(obj map[string]interface{}) []string {
+	values := fieldValues(obj, c.path, "")
+
+	if c.exists != nil && !*c.exists {
+		if len(values) != 0 {
+			return []string{fmt.Sprintf("the field %s is set but must not be", c.pointer)}
+		}
+
+		return nil
+	}
+
+	if len(values) == 0 {
+		return []string{fmt.Sprintf("the field %s is not set", c.pointer)}
+	}
+
+	failures := []string{}
+
+	for _, value := range values {
+		if c.regex != nil {
+			str, ok := value.value.(string)
+			if !ok {
+				failures = append(failures, fmt.Sprintf("the field %s is not a string", value.pointer))
+			} else if !c.regex.MatchString(str) {
+				failures = append(failures, fmt.Sprintf(
+					"the field %s value '%s' does not match the regex '%s'",
+					value.pointer, str, c.pattern,
+				))
+			}
+		}
+
+		if c.minimum == nil && c.maximum == nil {
+			continue
+		}
+
+		number, ok := numericValue(value.value)
+		if !ok {
+			failures = append(failures, fmt.Sprintf("the field %s is not a number", value.pointer))
+
+			continue
+		}
+
+		if c.minimum != nil && number < float64(*c.minimum) {
+			failures = append(failures, fmt.Sprintf(
+				"the field %s value %v is less than the minimum %d", value.pointer, value.value, *c.minimum,
+			))
+		}
+
+		if c.maximum != nil && number > float64(*c.maximum) {
+			failures = append(failures, fmt.Sprintf(
+				"the field %s value %v is greater than the maximum %d", value.pointer, value.value, *c.maximum,
+			))
+		}
+	}
+
+	return failures
+}
+
+// fieldValues returns the values found at the input path in the value. A key of '*' in the path matches every item
+// of a list. The pointer is the JSON pointer of the input value.
+func fieldValues(value interface{}, path []string, pointer string) []fieldValue {
+	if len(path) == 0 {
+		return []fieldValue{{pointer: pointer, value: value}}
+	}
+
+	if path[0] == "*" {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		values := []fieldValue{}
+
+		for i, item := range list {
+			values = append(values, fieldValues(item, path[1:], pointer+"/"+strconv.Itoa(i))...)
+		}
+
+		return values
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	child, found := fields[path[0]]
+	if !found {
+		return nil
+	}
+
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+
+	return fieldValues(child, path[1:], pointer+"/"+escaper.Replace(path[0]))
+}
+
+// numericValue returns the value as a float64 if it's a number.
+func numericValue(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int32:
+		return float64(value), true
+	case float64:
+		return value, true
+	default:
+		return 0, false
+	}
+}
+
+// isObjectChecksFailed returns true if the message indicates that the object doesn't meet the object checks.
+func isObjectChecksFailed(message string) bool {
+	return strings.Contains(message, objectChecksFailedMsg)
+}
+
+// objectChecksMessage returns a message describing the expectations of the object template's objectChecks that the
+// existing object doesn't meet, or an empty string if it meets all of them.
+func objectChecksMessage(obj singleObject, objectT *policyv1.ObjectTemplate) string {
+	if len(objectT.ObjectChecks) == 0 || obj.existingObj == nil {
+		return ""
+	}
+
+	// The objectChecks were validated before the object templates were processed
+	checks, _ := parseObjectChecks(objectT.ObjectChecks)
+
+	failures := objectCheckFailures(checks, obj.existingObj)
+	if len(failures) == 0 {
+		return ""
+	}
+
+	idStr := identifierStr([]string{obj.name}, obj.namespace)
+
+	return fmt.Sprintf("%v %v %v: %v", obj.gvr.Resource, idStr, objectChecksFailedMsg, strings.Join(failures, "; "))
+}
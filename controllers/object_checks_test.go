@@ -0,0 +1,94 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestObjectCheckFailures(t *testing.T) {
+	t.Parallel()
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"terminationGracePeriodSeconds": int64(90),
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "registry.corp/app:1"},
+				map[string]interface{}{"name": "proxy", "image": "docker.io/proxy:1"},
+			},
+		},
+	}}
+	sixty := int64(60)
+	hundred := int64(100)
+	exists := true
+	notExists := false
+
+	tests := map[string]struct {
+		check    policyv1.ObjectCheck
+		expected []string
+	}{
+		"regex on every list item": {
+			check: policyv1.ObjectCheck{Path: "/spec/containers/*/image", Regex: `registry\.corp/.*`},
+			expected: []string{
+				"the field /spec/containers/1/image value 'docker.io/proxy:1' does not match the regex " +
+					`'registry\.corp/.*'`,
+			},
+		},
+		"regex must fully match": {
+			check: policyv1.ObjectCheck{Path: "/spec/containers/*/name", Regex: "ap"},
+			expected: []string{
+				"the field /spec/containers/0/name value 'app' does not match the regex 'ap'",
+				"the field /spec/containers/1/name value 'proxy' does not match the regex 'ap'",
+			},
+		},
+		"maximum exceeded": {
+			check: policyv1.ObjectCheck{Path: "/spec/terminationGracePeriodSeconds", Maximum: &sixty},
+			expected: []string{
+				"the field /spec/terminationGracePeriodSeconds value 90 is greater than the maximum 60",
+			},
+		},
+		"within the range": {
+			check: policyv1.ObjectCheck{
+				Path: "/spec/terminationGracePeriodSeconds", Minimum: &sixty, Maximum: &hundred,
+			},
+			expected: []string{},
+		},
+		"not a number": {
+			check: policyv1.ObjectCheck{Path: "/spec/containers/*/name", Minimum: &sixty},
+			expected: []string{
+				"the field /spec/containers/0/name is not a number",
+				"the field /spec/containers/1/name is not a number",
+			},
+		},
+		"must exist": {
+			check:    policyv1.ObjectCheck{Path: "/spec/hostNetwork", Exists: &exists},
+			expected: []string{"the field /spec/hostNetwork is not set"},
+		},
+		"must not exist": {
+			check:    policyv1.ObjectCheck{Path: "/spec/terminationGracePeriodSeconds", Exists: &notExists},
+			expected: []string{"the field /spec/terminationGracePeriodSeconds is set but must not be"},
+		},
+		"missing and must not exist": {
+			check:    policyv1.ObjectCheck{Path: "/spec/hostNetwork", Exists: &notExists},
+			expected: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			checks, err := parseObjectChecks([]policyv1.ObjectCheck{test.check})
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expected, objectCheckFailures(checks, obj))
+		})
+	}
+}
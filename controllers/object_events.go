@@ -0,0 +1,234 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+const (
+	// maxObjectEvents is the maximum number of object compliance events emitted per policy evaluation. The remaining
+	// compliance changes are summarized in a single event so that a policy matching many objects doesn't flood the
+	// cluster with events.
+	maxObjectEvents = 20
+	// eventReasonObjectCompliance is the reason of the object compliance events, which differs from the policy
+	// compliance events so that they aren't interpreted as policy compliance history.
+	eventReasonObjectCompliance = "ObjectComplianceUpdate"
+	// The annotations on the object compliance events that identify the object
+	objectAPIVersionAnnotation = "policy.open-cluster-management.io/object-api-version"
+	objectKindAnnotation       = "policy.open-cluster-management.io/object-kind"
+	objectNameAnnotation       = "policy.open-cluster-management.io/object-name"
+	objectNamespaceAnnotation  = "policy.open-cluster-management.io/object-namespace"
+	objectComplianceAnnotation = "policy.open-cluster-management.io/object-compliance"
+)
+
+// objectComplianceChanges returns the related objects whose compliance changed since the previous evaluation,
+// including the objects that weren't related to the policy before.
+func objectComplianceChanges(related, oldRelated []policyv1.RelatedObject) []policyv1.RelatedObject {
+	oldCompliance := make(map[string]string, len(oldRelated))
+
+	for _, object := range oldRelated {
+		oldCompliance[getObjectString(object)] = object.Compliant
+	}
+
+	changes := []policyv1.RelatedObject{}
+
+	for _, object := range related {
+		// Condensed related objects such as "-" for all namespaces don't identify an object
+		if object.Object.Metadata.Name == "" || object.Object.Metadata.Name == "-" {
+			continue
+		}
+
+		if previous, ok := oldCompliance[getObjectString(object)]; !ok || previous != object.Compliant {
+			changes = append(changes, object)
+		}
+	}
+
+	return changes
+}
+
+// sendObjectEvents emits an event for each related object whose compliance changed since the previous evaluation
+// when the policy has objectEvents enabled. At most maxObjectEvents are emitted and the remaining changes are
+// summarized in a single event.
+func (r *ConfigurationPolicyReconciler) sendObjectEvents(
+	plc *policyv1.ConfigurationPolicy, related, oldRelated []policyv1.RelatedObject,
+) {
+	if plc.Spec == nil || !plc.Spec.ObjectEvents {
+		return
+	}
+
+	changes := objectComplianceChanges(related, oldRelated)
+
+	for i, object := range changes {
+		if i == maxObjectEvents {
+			r.sendObjectEventsSummary(plc, changes[i:])
+
+			break
+		}
+
+		msg := fmt.Sprintf("%s %s [%s]", object.Compliant, object.Object.Kind, object.Object.Metadata.Name)
+		if object.Object.Metadata.Namespace != "" {
+			msg += " in namespace " + object.Object.Metadata.Namespace
+		}
+
+		if object.Reason != "" {
+			msg += ": " + object.Reason
+		}
+
+		event := r.newObjectEvent(plc, object.Compliant, msg)
+		event.Annotations[objectAPIVersionAnnotation] = object.Object.APIVersion
+		event.Annotations[objectKindAnnotation] = object.Object.Kind
+		event.Annotations[objectNameAnnotation] = object.Object.Metadata.Name
+		event.Annotations[objectNamespaceAnnotation] = object.Object.Metadata.Namespace
+		event.Annotations[objectComplianceAnnotation] = object.Compliant
+		event.Related = &corev1.ObjectReference{
+			Kind:       object.Object.Kind,
+			Namespace:  object.Object.Metadata.Namespace,
+			Name:       object.Object.Metadata.Name,
+			APIVersion: object.Object.APIVersion,
+		}
+
+		if !r.ComplianceEvents.createsEvent(event) {
+			continue
+		}
+
+		if err := r.Create(context.TODO(), event); err != nil {
+			log.Error(err, "Failed to emit the object compliance event", "policy", plc.Name, "object", msg)
+		}
+	}
+}
+
+// sendObjectEventsSummary emits a single event summarizing the related object compliance changes that exceeded
+// maxObjectEvents.
+func (r *ConfigurationPolicyReconciler) sendObjectEventsSummary(
+	plc *policyv1.ConfigurationPolicy, changes []policyv1.RelatedObject,
+) {
+	compliant := 0
+
+	for _, object := range changes {
+		if object.Compliant == string(policyv1.Compliant) {
+			compliant++
+		}
+	}
+
+	compliance := string(policyv1.Compliant)
+	if compliant != len(changes) {
+		compliance = string(policyv1.NonCompliant)
+	}
+
+	msg := fmt.Sprintf(
+		"%d more related objects changed compliance and aren't reported individually: %d became compliant and %d "+
+			"became noncompliant",
+		len(changes), compliant, len(changes)-compliant,
+	)
+
+	event := r.newObjectEvent(plc, compliance, msg)
+	if !r.ComplianceEvents.createsEvent(event) {
+		return
+	}
+
+	if err := r.Create(context.TODO(), event); err != nil {
+		log.Error(err, "Failed to emit the object compliance summary event", "policy", plc.Name)
+	}
+}
+
+// newObjectEvent returns an object compliance event on the policy with the same database ID annotations as the
+// policy compliance events.
+func (r *ConfigurationPolicyReconciler) newObjectEvent(
+	plc *policyv1.ConfigurationPolicy, compliance string, msg string,
+) *corev1.Event {
+	now := time.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// This event name matches the convention of recorders from client-go
+			Name:        fmt.Sprintf("%v.%x", plc.Name, now.UnixNano()),
+			Namespace:   plc.Namespace,
+			Annotations: map[string]string{},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       plc.Kind,
+			Namespace:  plc.Namespace,
+			Name:       plc.Name,
+			UID:        plc.UID,
+			APIVersion: plc.APIVersion,
+		},
+		Reason:  eventReasonObjectCompliance,
+		Message: msg,
+		Source: corev1.EventSource{
+			Component: ControllerName,
+			Host:      r.InstanceName,
+		},
+		FirstTimestamp:      metav1.NewTime(now),
+		LastTimestamp:       metav1.NewTime(now),
+		Count:               1,
+		Type:                "Normal",
+		Action:              "ComplianceStateUpdate",
+		ReportingController: ControllerName,
+		ReportingInstance:   r.InstanceName,
+	}
+
+	for key, value := range dbIDAnnotations(plc.GetAnnotations()) {
+		event.Annotations[key] = value
+	}
+
+	if compliance != string(policyv1.Compliant) {
+		event.Type = "Warning"
+	}
+
+	return event
+}
+
+// dbIDAnnotations returns the compliance database ID annotations of the policy, which are copied to its events. The
+// annotations that are missing or aren't positive integers are skipped rather than copied as is.
+func dbIDAnnotations(policyAnnotations map[string]string) map[string]string {
+	annotations := map[string]string{}
+
+	for _, key := range []string{common.ParentDBIDAnnotation, common.PolicyDBIDAnnotation} {
+		value := policyAnnotations[key]
+		if value == "" {
+			continue
+		}
+
+		if id, err := strconv.ParseInt(value, 10, 64); err != nil || id <= 0 {
+			log.Info("Skipping the invalid compliance database ID annotation", "annotation", key, "value", value)
+
+			continue
+		}
+
+		annotations[key] = value
+	}
+
+	return annotations
+}
+
+// complianceEventAnnotations returns the annotations of a compliance event of the policy, which are the compliance
+// database ID annotations from complianceEventDBIDs, the policy generation that was evaluated, the resolved
+// compliance, and the type of the condition that triggered the event.
+func complianceEventAnnotations(
+	policy metav1.Object, dbIDs map[string]string, compliance policyv1.ComplianceState, conditionType string,
+) map[string]string {
+	annotations := make(map[string]string, len(dbIDs)+3)
+
+	for key, value := range dbIDs {
+		annotations[key] = value
+	}
+
+	if compliance == "" {
+		compliance = policyv1.UnknownCompliancy
+	}
+
+	annotations[common.PolicyGenerationAnnotation] = strconv.FormatInt(policy.GetGeneration(), 10)
+	annotations[common.ComplianceStateAnnotation] = string(compliance)
+	annotations[common.ConditionTypeAnnotation] = conditionType
+
+	return annotations
+}
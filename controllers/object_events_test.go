@@ -0,0 +1,174 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+	"open-cluster-management.io/config-policy-controller/pkg/common"
+)
+
+func testRelatedObject(name string, compliant policyv1.ComplianceState) policyv1.RelatedObject {
+	return policyv1.RelatedObject{
+		Object: policyv1.ObjectResource{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+			Metadata:   policyv1.ObjectMetadata{Name: name, Namespace: "default"},
+		},
+		Compliant: string(compliant),
+		Reason:    "Resource found as expected",
+	}
+}
+
+func TestObjectComplianceChanges(t *testing.T) {
+	t.Parallel()
+
+	oldRelated := []policyv1.RelatedObject{
+		testRelatedObject("unchanged", policyv1.Compliant),
+		testRelatedObject("fixed", policyv1.NonCompliant),
+		testRelatedObject("removed", policyv1.Compliant),
+	}
+	related := []policyv1.RelatedObject{
+		testRelatedObject("unchanged", policyv1.Compliant),
+		testRelatedObject("fixed", policyv1.Compliant),
+		testRelatedObject("new", policyv1.NonCompliant),
+		testRelatedObject("-", policyv1.NonCompliant),
+	}
+
+	changes := objectComplianceChanges(related, oldRelated)
+	assert.Equal(t, []policyv1.RelatedObject{related[1], related[2]}, changes)
+}
+
+func TestSendObjectEvents(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{Client: fake.NewClientBuilder().Build(), InstanceName: "controller"}
+	plc := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "policy",
+			Namespace:   "managed",
+			Annotations: map[string]string{common.PolicyDBIDAnnotation: "5"},
+		},
+		Spec: &policyv1.ConfigurationPolicySpec{ObjectEvents: true},
+	}
+
+	related := []policyv1.RelatedObject{}
+	for i := 0; i < maxObjectEvents+5; i++ {
+		related = append(related, testRelatedObject(fmt.Sprintf("configmap-%d", i), policyv1.NonCompliant))
+	}
+
+	r.sendObjectEvents(plc, related, nil)
+
+	events := &corev1.EventList{}
+	assert.NoError(t, r.List(context.TODO(), events))
+	assert.Len(t, events.Items, maxObjectEvents+1)
+
+	summarized := 0
+
+	for _, event := range events.Items {
+		assert.Equal(t, eventReasonObjectCompliance, event.Reason)
+		assert.Equal(t, "Warning", event.Type)
+		assert.Equal(t, "5", event.Annotations[common.PolicyDBIDAnnotation])
+
+		if event.Annotations[objectNameAnnotation] == "" {
+			summarized++
+
+			assert.Equal(
+				t,
+				"5 more related objects changed compliance and aren't reported individually: 0 became compliant "+
+					"and 5 became noncompliant",
+				event.Message,
+			)
+
+			continue
+		}
+
+		assert.Equal(t, "ConfigMap", event.Annotations[objectKindAnnotation])
+		assert.Equal(t, "default", event.Annotations[objectNamespaceAnnotation])
+		assert.Equal(t, "NonCompliant", event.Annotations[objectComplianceAnnotation])
+	}
+
+	assert.Equal(t, 1, summarized)
+
+	// No events are emitted when the option is disabled
+	plc.Spec.ObjectEvents = false
+	r.sendObjectEvents(plc, related, nil)
+
+	assert.NoError(t, r.List(context.TODO(), events))
+	assert.Len(t, events.Items, maxObjectEvents+1)
+}
+
+func TestComplianceEventAnnotations(t *testing.T) {
+	t.Parallel()
+
+	plc := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "policy",
+			Namespace:  "managed",
+			Generation: 3,
+			Annotations: map[string]string{
+				common.PolicyDBIDAnnotation: "5",
+				"other":                     "value",
+			},
+		},
+	}
+
+	expected := map[string]string{
+		common.PolicyDBIDAnnotation:       "5",
+		common.PolicyGenerationAnnotation: "3",
+		common.ComplianceStateAnnotation:  "NonCompliant",
+		common.ConditionTypeAnnotation:    compliantConditionType,
+	}
+
+	dbIDs := dbIDAnnotations(plc.Annotations)
+
+	assert.Equal(t, expected, complianceEventAnnotations(plc, dbIDs, policyv1.NonCompliant, compliantConditionType))
+
+	// An evaluation without a compliance state yet is reported as unknown
+	annotations := complianceEventAnnotations(plc, dbIDs, "", compliantConditionType)
+	assert.Equal(t, string(policyv1.UnknownCompliancy), annotations[common.ComplianceStateAnnotation])
+}
+
+func TestDBIDAnnotations(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		annotations map[string]string
+		expected    map[string]string
+	}{
+		"valid": {
+			map[string]string{common.ParentDBIDAnnotation: "124", common.PolicyDBIDAnnotation: "64"},
+			map[string]string{common.ParentDBIDAnnotation: "124", common.PolicyDBIDAnnotation: "64"},
+		},
+		"missing": {
+			map[string]string{"other": "value"},
+			map[string]string{},
+		},
+		"empty": {
+			map[string]string{common.ParentDBIDAnnotation: "", common.PolicyDBIDAnnotation: "64"},
+			map[string]string{common.PolicyDBIDAnnotation: "64"},
+		},
+		"invalid": {
+			map[string]string{common.ParentDBIDAnnotation: "abc", common.PolicyDBIDAnnotation: "-1"},
+			map[string]string{},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, dbIDAnnotations(test.annotations))
+		})
+	}
+}
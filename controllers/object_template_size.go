@@ -0,0 +1,53 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const reasonObjectDefinitionTooLarge = "Object definition too large"
+
+// validateObjectTemplateSizes returns an error if an objectDefinition of the policy exceeds maxDefinitionBytes or if
+// the object templates of the policy, including spec.object-templates-raw, exceed maxTotalBytes in sum. Zero or less
+// disables the respective limit. The object templates loaded through spec.objectTemplatesRawRef aren't stored in the
+// policy, so they aren't counted and are suggested as the alternative.
+func validateObjectTemplateSizes(
+	spec *policyv1.ConfigurationPolicySpec, maxDefinitionBytes int, maxTotalBytes int,
+) error {
+	if spec == nil || spec.ObjectTemplatesRawRef != nil {
+		return nil
+	}
+
+	const suggestion = "; reference the object templates in a ConfigMap with spec.objectTemplatesRawRef instead"
+
+	total := len(spec.ObjectTemplatesRaw)
+
+	for i, objectT := range spec.ObjectTemplates {
+		if objectT == nil {
+			continue
+		}
+
+		size := len(objectT.ObjectDefinition.Raw)
+
+		if maxDefinitionBytes > 0 && size > maxDefinitionBytes {
+			return fmt.Errorf(
+				"object-templates[%d]: the objectDefinition is %d bytes, which exceeds the limit of %d bytes%s",
+				i, size, maxDefinitionBytes, suggestion,
+			)
+		}
+
+		total += size
+	}
+
+	if maxTotalBytes > 0 && total > maxTotalBytes {
+		return fmt.Errorf(
+			"the object templates are %d bytes in total, which exceeds the limit of %d bytes%s",
+			total, maxTotalBytes, suggestion,
+		)
+	}
+
+	return nil
+}
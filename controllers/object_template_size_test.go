@@ -0,0 +1,79 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestValidateObjectTemplateSizes(t *testing.T) {
+	t.Parallel()
+
+	objDef := []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"my-configmap"}}`)
+	objTemps := []*policyv1.ObjectTemplate{
+		{ObjectDefinition: runtime.RawExtension{Raw: objDef}},
+		{ObjectDefinition: runtime.RawExtension{Raw: append(append([]byte{}, objDef...), ' ', ' ')}},
+	}
+
+	tests := map[string]struct {
+		spec          *policyv1.ConfigurationPolicySpec
+		maxDefinition int
+		maxTotal      int
+		errMsg        string
+	}{
+		"within the limits": {
+			spec:          &policyv1.ConfigurationPolicySpec{ObjectTemplates: objTemps},
+			maxDefinition: len(objDef) + 2,
+			maxTotal:      2*len(objDef) + 2,
+		},
+		"limits disabled": {
+			spec: &policyv1.ConfigurationPolicySpec{ObjectTemplates: objTemps},
+		},
+		"objectDefinition too large": {
+			spec:          &policyv1.ConfigurationPolicySpec{ObjectTemplates: objTemps},
+			maxDefinition: len(objDef) + 1,
+			errMsg: "object-templates[1]: the objectDefinition is 75 bytes, which exceeds the limit of 74 bytes; " +
+				"reference the object templates in a ConfigMap with spec.objectTemplatesRawRef instead",
+		},
+		"object templates too large in total": {
+			spec:     &policyv1.ConfigurationPolicySpec{ObjectTemplates: objTemps},
+			maxTotal: 2*len(objDef) + 1,
+			errMsg: "the object templates are 148 bytes in total, which exceeds the limit of 147 bytes; " +
+				"reference the object templates in a ConfigMap with spec.objectTemplatesRawRef instead",
+		},
+		"object-templates-raw too large": {
+			spec:     &policyv1.ConfigurationPolicySpec{ObjectTemplatesRaw: string(objDef)},
+			maxTotal: len(objDef) - 1,
+			errMsg: "the object templates are 73 bytes in total, which exceeds the limit of 72 bytes; " +
+				"reference the object templates in a ConfigMap with spec.objectTemplatesRawRef instead",
+		},
+		"objectTemplatesRawRef isn't counted": {
+			spec: &policyv1.ConfigurationPolicySpec{
+				ObjectTemplatesRawRef: &policyv1.ObjectTemplatesRawRef{},
+			},
+			maxTotal: 1,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateObjectTemplateSizes(test.spec, test.maxDefinition, test.maxTotal)
+			if test.errMsg == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, test.errMsg)
+		})
+	}
+}
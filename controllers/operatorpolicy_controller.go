@@ -6,11 +6,14 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"reflect"
 	"regexp"
+	"sync"
+	"time"
 
 	operatorv1 "github.com/operator-framework/api/pkg/operators/v1"
 	operatorv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
@@ -23,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -35,6 +39,7 @@ import (
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
+	common "open-cluster-management.io/config-policy-controller/pkg/common"
 )
 
 const (
@@ -86,6 +91,42 @@ type OperatorPolicyReconciler struct {
 	DynamicWatcher   depclient.DynamicWatcher
 	InstanceName     string
 	DefaultNamespace string
+	// The base field manager for the requests that enforce policies. The policy name is appended to it so that the
+	// changes can be attributed to a policy. It defaults to DefaultFieldManager.
+	FieldManager string
+	// The Kind.group patterns of the objects that are never created or updated, regardless of the policies.
+	DeniedKinds []string
+	// When set, the compliance events are also sent to the compliance history API.
+	ComplianceHistory *ComplianceHistoryReporter
+	// When set, an event is emitted on each object that a policy creates or updates, which identifies the policy.
+	MutationEvents bool
+	// How the compliance events are emitted. It defaults to ComplianceEventsEnabled.
+	ComplianceEvents ComplianceEventsMode
+	// The maximum length in bytes of the compliance event messages, beyond which they're truncated. Zero or less
+	// disables the truncation.
+	MaxComplianceEventMessageLength int
+	// How long an exact repeat of the last compliance event of a policy isn't emitted. Zero or less disables the
+	// deduplication.
+	ComplianceEventDedupWindow time.Duration
+	// complianceEvents is the cache of the compliance events emitted for the policies. See createComplianceEvent.
+	complianceEvents complianceEventCache
+	// dryRunCache has the OperatorPolicy namespace and name as the key and the values are a *sync.Map with the kind,
+	// namespace, and name of the objects compared by mergeObjects as the keys and the dryRunCacheEntry of the last
+	// dry run update of the object as the values. A policy only has an entry per object it manages, and its entries
+	// are removed when the policy is deleted.
+	dryRunCache sync.Map
+	// userErrors counts the user errors of the policies in the policy_user_errors metric.
+	userErrors userErrorTracker
+}
+
+// dryRunCacheEntry is the outcome of a dry run update in mergeObjects. It's only valid for the UID and resourceVersion
+// of the object and the hash of the desired object it was determined with.
+type dryRunCacheEntry struct {
+	uid               types.UID
+	resourceVersion   string
+	desiredHash       string
+	updateNeeded      bool
+	updateIsForbidden bool
 }
 
 // SetupWithManager sets up the controller with the Manager and will reconcile when the dynamic watcher
@@ -95,7 +136,9 @@ func (r *OperatorPolicyReconciler) SetupWithManager(mgr ctrl.Manager, depEvents
 		Named(OperatorControllerName).
 		For(
 			&policyv1beta1.OperatorPolicy{},
-			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+			builder.WithPredicates(
+				predicate.GenerationChangedPredicate{}, removedPolicyPredicate(&r.complianceEvents),
+			)).
 		Watches(
 			depEvents,
 			&handler.EnqueueRequestForObject{}).
@@ -129,6 +172,15 @@ func (r *OperatorPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		if k8serrors.IsNotFound(err) {
 			OpLog.Info("Operator policy could not be found")
 
+			// OperatorPolicies don't prune the resources they created, so the event always states that they weren't
+			// pruned
+			sendRemovalComplianceEvent(
+				ctx, r.Client, &r.complianceEvents, r.ComplianceEvents, r.InstanceName, req.Namespace, req.Name,
+			)
+			forgetComplianceEvents(&r.complianceEvents, req.Namespace, req.Name)
+			r.dryRunCache.Delete(req.NamespacedName.String())
+			r.userErrors.forget("OperatorPolicy", req.Namespace, req.Name)
+
 			err = r.DynamicWatcher.RemoveWatcher(watcher)
 			if err != nil {
 				OpLog.Error(err, "Error updating dependency watcher. Ignoring the failure.")
@@ -162,9 +214,21 @@ func (r *OperatorPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	errs := make([]error, 0)
 
+	// Resend the compliance events that couldn't be created in the previous reconciles before the new ones
+	err = resendComplianceEvents(
+		ctx, r.Client, &r.complianceEvents, r.ComplianceEventDedupWindow, complianceEventBackoff,
+		policy.Namespace, policy.Name,
+	)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	conditionsToEmit, conditionChanged, err := r.handleResources(ctx, policy)
 	if err != nil {
 		errs = append(errs, err)
+	} else {
+		// The user errors are only forgotten after a complete evaluation, since they may not have been checked
+		r.userErrors.endEvaluation("OperatorPolicy", policy.Namespace, policy.Name)
 	}
 
 	if conditionChanged {
@@ -172,6 +236,9 @@ func (r *OperatorPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		// "early" events (and possibly has zero events).
 		conditionsToEmit = append(conditionsToEmit, calculateComplianceCondition(policy))
 
+		// The evaluation time alone doesn't change the status, so it's recorded when the status is written
+		setRelatedLastEvaluated(policy.Status.RelatedObjects, time.Now())
+
 		if err := r.Status().Update(ctx, policy); err != nil {
 			errs = append(errs, err)
 		}
@@ -308,7 +375,12 @@ func (r *OperatorPolicyReconciler) buildResources(policy *policyv1beta1.Operator
 			fmt.Errorf("the operator namespace ('%v') does not exist", opGroupNS))
 	}
 
-	return sub, opGroup, updateStatus(policy, validationCond(validationErrors)), nil
+	validation := validationCond(validationErrors)
+	if validation.Status == metav1.ConditionFalse {
+		r.userErrors.record("OperatorPolicy", policy, userErrorInvalidPolicySpec, validation.Message)
+	}
+
+	return sub, opGroup, updateStatus(policy, validation), nil
 }
 
 // buildSubscription bootstraps the subscription spec defined in the operator policy
@@ -466,19 +538,27 @@ func (r *OperatorPolicyReconciler) handleOpGroup(
 			return nil, changed, nil
 		}
 
+		if pattern := r.deniedKind(operatorGroupGVK); pattern != "" {
+			return nil, updateStatus(
+				policy, restrictedCond("OperatorGroup", pattern), missingWantedObj(desiredOpGroup),
+			), nil
+		}
+
 		earlyConds := []metav1.Condition{}
 
 		if changed {
 			earlyConds = append(earlyConds, calculateComplianceCondition(policy))
 		}
 
-		err = r.Create(ctx, desiredOpGroup)
+		err = r.Create(ctx, desiredOpGroup, r.fieldOwner(policy))
 		if err != nil {
 			return nil, changed, fmt.Errorf("error creating the OperatorGroup: %w", err)
 		}
 
 		desiredOpGroup.SetGroupVersionKind(operatorGroupGVK) // Create stripped this information
 
+		r.sendMutationEvent(ctx, policy, desiredOpGroup, eventReasonPolicyCreated, "")
+
 		// Now the OperatorGroup should match, so report Compliance
 		updateStatus(policy, createdCond("OperatorGroup"), createdObj(desiredOpGroup))
 
@@ -516,9 +596,7 @@ func (r *OperatorPolicyReconciler) handleOpGroup(
 
 		merged := opGroup.DeepCopy() // Copy it so that the value in the cache is not changed
 
-		updateNeeded, skipUpdate, err := r.mergeObjects(
-			ctx, desiredUnstruct, merged, string(policy.Spec.ComplianceType),
-		)
+		updateNeeded, skipUpdate, err := r.mergeObjects(ctx, desiredUnstruct, merged, policy)
 		if err != nil {
 			return nil, false, fmt.Errorf("error checking if the OperatorGroup needs an update: %w", err)
 		}
@@ -549,6 +627,10 @@ func (r *OperatorPolicyReconciler) handleOpGroup(
 			return nil, changed, nil
 		}
 
+		if pattern := r.deniedKind(operatorGroupGVK); pattern != "" {
+			return nil, updateStatus(policy, restrictedCond("OperatorGroup", pattern), mismatchedObj(&opGroup)), nil
+		}
+
 		earlyConds := []metav1.Condition{}
 
 		if changed {
@@ -557,7 +639,7 @@ func (r *OperatorPolicyReconciler) handleOpGroup(
 
 		desiredOpGroup.ResourceVersion = opGroup.GetResourceVersion()
 
-		err = r.Update(ctx, merged)
+		err = r.patchOrUpdate(ctx, &opGroup, merged, policy)
 		if err != nil {
 			return nil, changed, fmt.Errorf("error updating the OperatorGroup: %w", err)
 		}
@@ -597,19 +679,27 @@ func (r *OperatorPolicyReconciler) handleSubscription(
 			return desiredSub, nil, changed, nil
 		}
 
+		if pattern := r.deniedKind(subscriptionGVK); pattern != "" {
+			return desiredSub, nil, updateStatus(
+				policy, restrictedCond("Subscription", pattern), missingWantedObj(desiredSub),
+			), nil
+		}
+
 		earlyConds := []metav1.Condition{}
 
 		if changed {
 			earlyConds = append(earlyConds, calculateComplianceCondition(policy))
 		}
 
-		err := r.Create(ctx, desiredSub)
+		err := r.Create(ctx, desiredSub, r.fieldOwner(policy))
 		if err != nil {
 			return nil, nil, changed, fmt.Errorf("error creating the Subscription: %w", err)
 		}
 
 		desiredSub.SetGroupVersionKind(subscriptionGVK) // Create stripped this information
 
+		r.sendMutationEvent(ctx, policy, desiredSub, eventReasonPolicyCreated, "")
+
 		// Now it should match, so report Compliance
 		updateStatus(policy, createdCond("Subscription"), createdObj(desiredSub))
 
@@ -624,7 +714,7 @@ func (r *OperatorPolicyReconciler) handleSubscription(
 
 	merged := foundSub.DeepCopy() // Copy it so that the value in the cache is not changed
 
-	updateNeeded, skipUpdate, err := r.mergeObjects(ctx, desiredUnstruct, merged, string(policy.Spec.ComplianceType))
+	updateNeeded, skipUpdate, err := r.mergeObjects(ctx, desiredUnstruct, merged, policy)
 	if err != nil {
 		return nil, nil, false, fmt.Errorf("error checking if the Subscription needs an update: %w", err)
 	}
@@ -684,13 +774,17 @@ func (r *OperatorPolicyReconciler) handleSubscription(
 		return mergedSub, nil, changed, nil
 	}
 
+	if pattern := r.deniedKind(subscriptionGVK); pattern != "" {
+		return mergedSub, nil, updateStatus(policy, restrictedCond("Subscription", pattern), mismatchedObj(foundSub)), nil
+	}
+
 	earlyConds := []metav1.Condition{}
 
 	if changed {
 		earlyConds = append(earlyConds, calculateComplianceCondition(policy))
 	}
 
-	err = r.Update(ctx, merged)
+	err = r.patchOrUpdate(ctx, foundSub, merged, policy)
 	if err != nil {
 		return mergedSub, nil, changed, fmt.Errorf("error updating the Subscription: %w", err)
 	}
@@ -887,14 +981,24 @@ func (r *OperatorPolicyReconciler) handleInstallPlan(
 		return changed, nil
 	}
 
+	if pattern := r.deniedKind(installPlanGVK); pattern != "" {
+		changed := updateStatus(policy, restrictedCond("InstallPlan", pattern), relatedInstallPlans...)
+
+		return changed, nil
+	}
+
 	if err := unstructured.SetNestedField(approvableInstallPlans[0].Object, true, "spec", "approved"); err != nil {
 		return false, fmt.Errorf("error approving InstallPlan: %w", err)
 	}
 
-	if err := r.Update(ctx, &approvableInstallPlans[0]); err != nil {
+	if err := r.Update(ctx, &approvableInstallPlans[0], r.fieldOwner(policy)); err != nil {
 		return false, fmt.Errorf("error updating approved InstallPlan: %w", err)
 	}
 
+	r.sendMutationEvent(
+		ctx, policy, &approvableInstallPlans[0], eventReasonPolicyUpdated, " to approve "+approvedVersion,
+	)
+
 	return updateStatus(policy, installPlanApprovedCond(approvedVersion), relatedInstallPlans...), nil
 }
 
@@ -1060,6 +1164,42 @@ func opPolIdentifier(namespace, name string) depclient.ObjectIdentifier {
 	}
 }
 
+// deniedKind returns the pattern of the controller configuration that prevents objects of the input kind from being
+// created or updated, or an empty string if they aren't restricted.
+func (r *OperatorPolicyReconciler) deniedKind(gvk schema.GroupVersionKind) string {
+	// The patterns were validated when the controller started
+	pattern, _ := common.MatchGroupKind(r.DeniedKinds, gvk.GroupKind())
+
+	return pattern
+}
+
+// fieldOwner returns the field manager for the requests that enforce the input policy.
+func (r *OperatorPolicyReconciler) fieldOwner(policy *policyv1beta1.OperatorPolicy) client.FieldOwner {
+	return client.FieldOwner(policyFieldManager(r.FieldManager, policy.Name))
+}
+
+// patchOrUpdate enforces the merged object with a JSON merge patch of only the fields that differ from the original
+// object, or with an update of the whole object when a merge patch can't express the change.
+func (r *OperatorPolicyReconciler) patchOrUpdate(
+	ctx context.Context,
+	original *unstructured.Unstructured,
+	merged *unstructured.Unstructured,
+	policy *policyv1beta1.OperatorPolicy,
+) error {
+	patch, patchable, err := mergePatch(original, merged)
+	if err != nil || !patchable {
+		err = r.Update(ctx, merged, r.fieldOwner(policy))
+	} else {
+		err = r.Patch(ctx, merged, client.RawPatch(types.MergePatchType, patch), r.fieldOwner(policy))
+	}
+
+	if err == nil {
+		r.sendMutationEvent(ctx, policy, merged, eventReasonPolicyUpdated, "")
+	}
+
+	return err
+}
+
 // mergeObjects takes fields from the desired object and sets/merges them on the
 // existing object. It checks and returns whether an update is really necessary
 // with a server-side dry-run.
@@ -1067,7 +1207,7 @@ func (r *OperatorPolicyReconciler) mergeObjects(
 	ctx context.Context,
 	desired map[string]interface{},
 	existing *unstructured.Unstructured,
-	complianceType string,
+	policy *policyv1beta1.OperatorPolicy,
 ) (updateNeeded, updateIsForbidden bool, err error) {
 	desiredObj := unstructured.Unstructured{Object: desired}
 
@@ -1075,20 +1215,69 @@ func (r *OperatorPolicyReconciler) mergeObjects(
 	existingObjectCopy := existing.DeepCopy()
 	removeFieldsForComparison(existingObjectCopy)
 
+	original := existing.DeepCopy()
+
 	_, errMsg, updateNeeded, _ := handleKeys(
-		desiredObj, existing, existingObjectCopy, complianceType, "", false,
+		desiredObj,
+		existing,
+		existingObjectCopy,
+		string(policy.Spec.ComplianceType),
+		metadataComplianceTypes{},
+		nil,
+		false,
 	)
 	if errMsg != "" {
 		return updateNeeded, false, errors.New(errMsg)
 	}
 
 	if updateNeeded {
-		err := r.Update(ctx, existing, client.DryRunAll)
+		// The dry run outcome doesn't change until the object or the desired object changes, so it's reused in the
+		// steady state rather than issuing the same dry run update on every reconcile.
+		loaded, _ := r.dryRunCache.LoadOrStore(client.ObjectKeyFromObject(policy).String(), &sync.Map{})
+		policyCache := loaded.(*sync.Map)
+		objKey := existing.GetKind() + "/" + existing.GetNamespace() + "/" + existing.GetName()
+
+		desiredHash, hashErr := dryRunDesiredHash(desired, policy.Spec.ComplianceType)
+		if hashErr == nil {
+			if cached, found := policyCache.Load(objKey); found {
+				entry := cached.(dryRunCacheEntry)
+
+				if entry.uid == existing.GetUID() && entry.resourceVersion == existing.GetResourceVersion() &&
+					entry.desiredHash == desiredHash {
+					// The merged values are only used for an update, so the object is otherwise left as it is on
+					// the cluster.
+					if !entry.updateNeeded {
+						existing.Object = original.Object
+					}
+
+					return entry.updateNeeded, entry.updateIsForbidden, nil
+				}
+			}
+		}
+
+		cacheOutcome := func(updateNeeded, updateIsForbidden bool) {
+			if hashErr != nil || original.GetUID() == "" {
+				return
+			}
+
+			// A recreated object replaces the entry of the previous object
+			policyCache.Store(objKey, dryRunCacheEntry{
+				uid:               original.GetUID(),
+				resourceVersion:   original.GetResourceVersion(),
+				desiredHash:       desiredHash,
+				updateNeeded:      updateNeeded,
+				updateIsForbidden: updateIsForbidden,
+			})
+		}
+
+		err := r.Update(ctx, existing, client.DryRunAll, r.fieldOwner(policy))
 		if err != nil {
 			if k8serrors.IsForbidden(err) {
 				// This indicates the update would make a change, but the change is not allowed,
 				// for example, the changed field might be immutable.
 				// The policy should be marked as noncompliant, but an enforcement update would fail.
+				cacheOutcome(true, true)
+
 				return true, true, nil
 			}
 
@@ -1097,11 +1286,26 @@ func (r *OperatorPolicyReconciler) mergeObjects(
 
 		removeFieldsForComparison(existing)
 
-		if reflect.DeepEqual(existing.Object, existingObjectCopy.Object) {
+		if dryRunUnchanged(existing, existingObjectCopy, string(policy.Spec.ComplianceType)) {
 			// The dry run indicates that there is not *really* a mismatch.
 			updateNeeded = false
 		}
+
+		cacheOutcome(updateNeeded, false)
 	}
 
 	return updateNeeded, false, nil
 }
+
+// dryRunDesiredHash returns a hash of the desired object and the compliance type it's compared with, which identifies
+// the dry run updates of mergeObjects that have the same outcome.
+func dryRunDesiredHash(desired map[string]interface{}, complianceType policyv1.ComplianceType) (string, error) {
+	desiredJSON, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(append([]byte(complianceType+"\n"), desiredJSON...))
+
+	return hex.EncodeToString(hash[:]), nil
+}
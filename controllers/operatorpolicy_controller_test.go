@@ -1,15 +1,23 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"testing"
 
 	operatorv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	eventsv1 "k8s.io/api/events/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
 )
 
@@ -199,3 +207,247 @@ func TestMessageIncludesSubscription(t *testing.T) {
 		)
 	}
 }
+
+// updateCountingClient counts the update requests and leaves the objects unchanged, like a dry run update that
+// doesn't change anything.
+type updateCountingClient struct {
+	client.Client
+	updates int
+}
+
+func (c *updateCountingClient) Update(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+	c.updates++
+
+	return nil
+}
+
+func TestMergeObjectsDryRunCache(t *testing.T) {
+	t.Parallel()
+
+	countingClient := &updateCountingClient{}
+	r := &OperatorPolicyReconciler{Client: countingClient}
+	policy := &policyv1beta1.OperatorPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-policy", Namespace: "default"},
+		Spec:       policyv1beta1.OperatorPolicySpec{ComplianceType: "musthave"},
+	}
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "Subscription",
+		"metadata": map[string]interface{}{
+			"name":            "my-operator",
+			"namespace":       "default",
+			"uid":             "sub-uid",
+			"resourceVersion": "1",
+		},
+		"spec": map[string]interface{}{"channel": "stable"},
+	}}
+	desired := map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "Subscription",
+		"spec":       map[string]interface{}{"channel": "fast"},
+	}
+
+	for i := 0; i < 3; i++ {
+		updateNeeded, forbidden, err := r.mergeObjects(context.TODO(), desired, existing.DeepCopy(), policy)
+		assert.NoError(t, err)
+		assert.True(t, updateNeeded)
+		assert.False(t, forbidden)
+	}
+
+	// The dry run outcome is reused until the object or the desired object changes
+	assert.Equal(t, 1, countingClient.updates)
+
+	existing.SetResourceVersion("2")
+
+	_, _, err := r.mergeObjects(context.TODO(), desired, existing.DeepCopy(), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countingClient.updates)
+
+	desired["spec"] = map[string]interface{}{"channel": "candidate"}
+
+	_, _, err = r.mergeObjects(context.TODO(), desired, existing.DeepCopy(), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, countingClient.updates)
+
+	// A recreated object isn't matched with the outcome of the previous object and replaces its entry
+	existing.SetUID("recreated-sub-uid")
+
+	_, _, err = r.mergeObjects(context.TODO(), desired, existing.DeepCopy(), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, countingClient.updates)
+
+	loaded, ok := r.dryRunCache.Load("default/my-policy")
+	if assert.True(t, ok) {
+		entries := 0
+
+		loaded.(*sync.Map).Range(func(_, _ interface{}) bool {
+			entries++
+
+			return true
+		})
+
+		assert.Equal(t, 1, entries)
+	}
+}
+
+func TestUpdateStatusUnchangedRelatedObject(t *testing.T) {
+	t.Parallel()
+
+	cond := metav1.Condition{
+		Type:    "OperatorGroupCompliant",
+		Status:  metav1.ConditionTrue,
+		Reason:  "OperatorGroupMatches",
+		Message: "the OperatorGroup matches what is required by the policy",
+	}
+	relatedObj := func(resourceVersion string) policyv1.RelatedObject {
+		return policyv1.RelatedObject{
+			Object: policyv1.ObjectResource{
+				Kind:       "OperatorGroup",
+				APIVersion: "operators.coreos.com/v1",
+				Metadata:   policyv1.ObjectMetadata{Name: "my-group", Namespace: "default"},
+			},
+			Compliant:  string(policyv1.Compliant),
+			Reason:     "Resource found as expected",
+			Properties: &policyv1.ObjectProperties{UID: "uid", ResourceVersion: resourceVersion},
+		}
+	}
+
+	policy := &policyv1beta1.OperatorPolicy{}
+
+	assert.True(t, updateStatus(policy, cond, relatedObj("1")))
+
+	expected := policy.Status.DeepCopy()
+
+	// A new resourceVersion alone isn't a change, so the status is left as is rather than being changed without an
+	// update
+	assert.False(t, updateStatus(policy, cond, relatedObj("2")))
+	assert.Equal(t, expected, &policy.Status)
+	assert.Equal(t, "1", policy.Status.RelatedObjects[0].Properties.ResourceVersion)
+	assert.Empty(t, policy.Status.RelatedObjects[0].Properties.LastEvaluated)
+}
+
+func TestUpdateStatusRelatedObjectIdentity(t *testing.T) {
+	t.Parallel()
+
+	cond := metav1.Condition{
+		Type:    "SubscriptionCompliant",
+		Status:  metav1.ConditionTrue,
+		Reason:  "SubscriptionMatches",
+		Message: "the Subscription matches what is required by the policy",
+	}
+	createdByPolicy := true
+	relatedObj := func(namespace string) policyv1.RelatedObject {
+		return policyv1.RelatedObject{
+			Object: policyv1.ObjectResource{
+				Kind:       "Subscription",
+				APIVersion: "operators.coreos.com/v1alpha1",
+				Metadata:   policyv1.ObjectMetadata{Name: "my-operator", Namespace: namespace},
+			},
+			Compliant:  string(policyv1.Compliant),
+			Reason:     "Resource found as expected",
+			Properties: &policyv1.ObjectProperties{UID: "uid"},
+		}
+	}
+
+	previous := relatedObj("operators")
+	previous.Properties.CreatedByPolicy = &createdByPolicy
+
+	policy := &policyv1beta1.OperatorPolicy{}
+	policy.Status.Conditions = []metav1.Condition{cond}
+	policy.Status.RelatedObjects = []policyv1.RelatedObject{previous}
+
+	// An object with the same name in another namespace is a different object, so it's not considered created by
+	// the policy
+	assert.True(t, updateStatus(policy, cond, relatedObj("other")))
+	assert.Len(t, policy.Status.RelatedObjects, 1)
+	assert.Equal(t, "other", policy.Status.RelatedObjects[0].Object.Metadata.Namespace)
+	assert.Nil(t, policy.Status.RelatedObjects[0].Properties.CreatedByPolicy)
+}
+
+func TestEmitComplianceEventType(t *testing.T) {
+	t.Parallel()
+
+	r := &OperatorPolicyReconciler{Client: fake.NewClientBuilder().Build(), InstanceName: "controller"}
+	policy := &policyv1beta1.OperatorPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-policy",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "policy.open-cluster-management.io/v1", Kind: "Policy", Name: "parent", UID: "parent-uid"},
+			},
+		},
+	}
+	// The policy's compliance state is only updated after the early compliance events are emitted
+	policy.Status.ComplianceState = policyv1.Compliant
+
+	nonCompliant := metav1.Condition{
+		Type: compliantConditionType, Status: metav1.ConditionFalse, Message: "NonCompliant; the Subscription is missing",
+	}
+	compliant := metav1.Condition{
+		Type: compliantConditionType, Status: metav1.ConditionTrue, Message: "Compliant; the Subscription matches",
+	}
+
+	assert.NoError(t, r.emitComplianceEvent(context.TODO(), policy, nonCompliant))
+	assert.NoError(t, r.emitComplianceEvent(context.TODO(), policy, compliant))
+
+	events := &eventsv1.EventList{}
+	assert.NoError(t, r.List(context.TODO(), events))
+
+	eventTypes := map[string]string{}
+	for _, event := range events.Items {
+		eventTypes[event.Note] = event.Type
+	}
+
+	assert.Equal(t, map[string]string{nonCompliant.Message: "Warning", compliant.Message: "Normal"}, eventTypes)
+}
+
+func TestEmitComplianceEventOrder(t *testing.T) {
+	t.Parallel()
+
+	r := &OperatorPolicyReconciler{Client: fake.NewClientBuilder().Build(), InstanceName: "controller"}
+	policy := &policyv1beta1.OperatorPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-policy",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "policy.open-cluster-management.io/v1", Kind: "Policy", Name: "parent", UID: "parent-uid"},
+			},
+		},
+	}
+
+	// The early compliance event from before the Subscription was created is emitted right before the final one
+	nonCompliant := metav1.Condition{
+		Type: compliantConditionType, Status: metav1.ConditionFalse, Message: "NonCompliant; the Subscription is missing",
+	}
+	compliant := metav1.Condition{
+		Type: compliantConditionType, Status: metav1.ConditionTrue, Message: "Compliant; the Subscription matches",
+	}
+
+	for i := 0; i < 10; i++ {
+		policy.Generation = int64(i)
+
+		assert.NoError(t, r.emitComplianceEvent(context.TODO(), policy, nonCompliant))
+		assert.NoError(t, r.emitComplianceEvent(context.TODO(), policy, compliant))
+	}
+
+	events := &eventsv1.EventList{}
+	assert.NoError(t, r.List(context.TODO(), events))
+	assert.Len(t, events.Items, 20)
+
+	sort.Slice(events.Items, func(i, j int) bool {
+		return events.Items[i].EventTime.Before(&events.Items[j].EventTime)
+	})
+
+	for i, event := range events.Items {
+		if i > 0 {
+			assert.True(t, events.Items[i-1].EventTime.Before(&event.EventTime), "the event times must be unique")
+		}
+
+		// Each early NonCompliant event sorts before the final Compliant one of the same evaluation
+		if i%2 == 0 {
+			assert.Equal(t, nonCompliant.Message, event.Note)
+		} else {
+			assert.Equal(t, compliant.Message, event.Note)
+		}
+	}
+}
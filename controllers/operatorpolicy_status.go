@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-	"time"
 
 	operatorv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -16,7 +15,6 @@ import (
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
-	common "open-cluster-management.io/config-policy-controller/pkg/common"
 )
 
 // updateStatus takes one condition to update, and related objects for that condition. The related
@@ -25,7 +23,9 @@ import (
 // already in the status - in that case, no changes to the policy are made. The `lastTransitionTime`
 // on a condition is not considered when checking if the condition has changed. If not provided, the
 // `lastTransitionTime` will use "now". It also handles preserving the `CreatedByPolicy` property on
-// relatedObjects.
+// relatedObjects. The `resourceVersion` property of the related objects isn't considered when checking if
+// they have changed, so it's only updated along with another change, and the `lastEvaluated` property is set
+// by the reconciler when the status is written.
 //
 // This function requires that all given related objects are of the same kind.
 //
@@ -83,14 +83,16 @@ func updateStatus(
 	}
 
 	for _, prevObj := range prevRelObjs {
-		nameFound := false
+		objFound := false
 
 		for i, updatedObj := range updatedRelatedObjs {
-			if prevObj.Object.Metadata.Name != updatedObj.Object.Metadata.Name {
+			// The objects are matched by their apiVersion, kind, namespace, and name since objects in different
+			// namespaces can have the same name
+			if getObjectString(prevObj) != getObjectString(updatedObj) {
 				continue
 			}
 
-			nameFound = true
+			objFound = true
 
 			if updatedObj.Properties != nil && prevObj.Properties != nil {
 				if updatedObj.Properties.UID != prevObj.Properties.UID {
@@ -106,7 +108,7 @@ func updateStatus(
 			}
 		}
 
-		if !nameFound {
+		if !objFound {
 			relObjsChanged = true
 		}
 	}
@@ -286,7 +288,10 @@ func (r *OperatorPolicyReconciler) emitComplianceEvent(
 	}
 
 	ownerRef := policy.OwnerReferences[0]
-	now := time.Now()
+	reason := fmt.Sprintf(eventFmtStr, policy.Namespace, policy.Name)
+	// The early compliance events and the final one are emitted in quick succession, so their timestamps are kept
+	// strictly increasing for the consumers to order them
+	now := complianceEventTime(&r.complianceEvents, policy.Namespace, reason)
 	event := &corev1.Event{
 		ObjectMeta: metav1.ObjectMeta{
 			// This event name matches the convention of recorders from client-go
@@ -300,7 +305,7 @@ func (r *OperatorPolicyReconciler) emitComplianceEvent(
 			UID:        ownerRef.UID,
 			APIVersion: ownerRef.APIVersion,
 		},
-		Reason:  fmt.Sprintf(eventFmtStr, policy.Namespace, policy.Name),
+		Reason:  reason,
 		Message: complianceCondition.Message,
 		Source: corev1.EventSource{
 			Component: ControllerName,
@@ -322,26 +327,34 @@ func (r *OperatorPolicyReconciler) emitComplianceEvent(
 		ReportingInstance:   r.InstanceName,
 	}
 
-	eventAnnotations := map[string]string{}
-
-	policyAnnotations := policy.GetAnnotations()
-	if policyAnnotations[common.ParentDBIDAnnotation] != "" {
-		eventAnnotations[common.ParentDBIDAnnotation] = policyAnnotations[common.ParentDBIDAnnotation]
+	// The early compliance events are emitted before the policy's compliance state is updated, so the compliance is
+	// determined from the condition being reported
+	compliance := policyv1.NonCompliant
+	if complianceCondition.Status == metav1.ConditionTrue {
+		compliance = policyv1.Compliant
 	}
 
-	if policyAnnotations[common.PolicyDBIDAnnotation] != "" {
-		eventAnnotations[common.PolicyDBIDAnnotation] = policyAnnotations[common.PolicyDBIDAnnotation]
+	if compliance != policyv1.Compliant {
+		event.Type = "Warning"
 	}
 
-	if len(eventAnnotations) > 0 {
-		event.Annotations = eventAnnotations
+	event.Annotations = complianceEventAnnotations(
+		policy, complianceEventDBIDs(ctx, r.Client, policy), compliance, complianceCondition.Type,
+	)
+
+	if r.ComplianceHistory != nil {
+		r.ComplianceHistory.ReportEvent(event, compliance)
 	}
 
-	if policy.Status.ComplianceState != policyv1.Compliant {
-		event.Type = "Warning"
+	event.Message = truncateComplianceEventMessage(event.Message, r.MaxComplianceEventMessageLength)
+
+	if !r.ComplianceEvents.createsEvent(event) {
+		return nil
 	}
 
-	return r.Create(ctx, event)
+	return createOrQueueComplianceEvent(
+		ctx, r.Client, &r.complianceEvents, r.ComplianceEventDedupWindow, complianceEventBackoff, event,
+	)
 }
 
 const (
@@ -438,6 +451,18 @@ func mismatchCondUnfixable(kind string) metav1.Condition {
 	}
 }
 
+// restrictedCond returns a NonCompliant condition with a Reason like '____Restricted', and a Message like
+// 'the ____ can't be enforced since its kind is restricted by the controller configuration'
+func restrictedCond(kind string, pattern string) metav1.Condition {
+	return metav1.Condition{
+		Type:   condType(kind),
+		Status: metav1.ConditionFalse,
+		Reason: kind + "Restricted",
+		Message: "the " + kind + " can't be enforced since its kind is restricted by the controller configuration " +
+			"(--denied-kinds " + pattern + ")",
+	}
+}
+
 // updatedCond returns a Compliant condition, with a Reason like'____Updated',
 // and a Message like 'the ____ was updated to match the policy'
 func updatedCond(kind string) metav1.Condition {
@@ -687,6 +712,7 @@ func createdObj(obj client.Object) policyv1.RelatedObject {
 		Properties: &policyv1.ObjectProperties{
 			CreatedByPolicy: &created,
 			UID:             string(obj.GetUID()),
+			ResourceVersion: obj.GetResourceVersion(),
 		},
 	}
 }
@@ -698,7 +724,8 @@ func matchedObj(obj client.Object) policyv1.RelatedObject {
 		Compliant: string(policyv1.Compliant),
 		Reason:    reasonWantFoundExists,
 		Properties: &policyv1.ObjectProperties{
-			UID: string(obj.GetUID()),
+			UID:             string(obj.GetUID()),
+			ResourceVersion: obj.GetResourceVersion(),
 		},
 	}
 }
@@ -710,7 +737,8 @@ func mismatchedObj(obj client.Object) policyv1.RelatedObject {
 		Compliant: string(policyv1.NonCompliant),
 		Reason:    reasonWantFoundNoMatch,
 		Properties: &policyv1.ObjectProperties{
-			UID: string(obj.GetUID()),
+			UID:             string(obj.GetUID()),
+			ResourceVersion: obj.GetResourceVersion(),
 		},
 	}
 }
@@ -722,7 +750,8 @@ func updatedObj(obj client.Object) policyv1.RelatedObject {
 		Compliant: string(policyv1.Compliant),
 		Reason:    reasonUpdateSuccess,
 		Properties: &policyv1.ObjectProperties{
-			UID: string(obj.GetUID()),
+			UID:             string(obj.GetUID()),
+			ResourceVersion: obj.GetResourceVersion(),
 		},
 	}
 }
@@ -733,7 +762,8 @@ func nonCompObj(obj client.Object, reason string) policyv1.RelatedObject {
 		Compliant: string(policyv1.NonCompliant),
 		Reason:    reason,
 		Properties: &policyv1.ObjectProperties{
-			UID: string(obj.GetUID()),
+			UID:             string(obj.GetUID()),
+			ResourceVersion: obj.GetResourceVersion(),
 		},
 	}
 }
@@ -750,7 +780,8 @@ func opGroupTooManyObjs(opGroups []unstructured.Unstructured) []policyv1.Related
 			Compliant: string(policyv1.NonCompliant),
 			Reason:    "There is more than one OperatorGroup in this namespace",
 			Properties: &policyv1.ObjectProperties{
-				UID: string(opGroup.GetUID()),
+				UID:             string(opGroup.GetUID()),
+				ResourceVersion: opGroup.GetResourceVersion(),
 			},
 		}
 	}
@@ -779,7 +810,8 @@ func existingInstallPlanObj(ip client.Object, phase string) policyv1.RelatedObje
 	relObj := policyv1.RelatedObject{
 		Object: policyv1.ObjectResourceFromObj(ip),
 		Properties: &policyv1.ObjectProperties{
-			UID: string(ip.GetUID()),
+			UID:             string(ip.GetUID()),
+			ResourceVersion: ip.GetResourceVersion(),
 		},
 	}
 
@@ -828,7 +860,8 @@ func existingCSVObj(csv *operatorv1alpha1.ClusterServiceVersion) policyv1.Relate
 		Compliant: string(compliance),
 		Reason:    string(csv.Status.Reason),
 		Properties: &policyv1.ObjectProperties{
-			UID: string(csv.GetUID()),
+			UID:             string(csv.GetUID()),
+			ResourceVersion: csv.GetResourceVersion(),
 		},
 	}
 }
@@ -875,7 +908,8 @@ func existingDeploymentObj(dep *appsv1.Deployment) policyv1.RelatedObject {
 		Compliant: string(compliance),
 		Reason:    reason,
 		Properties: &policyv1.ObjectProperties{
-			UID: string(dep.GetUID()),
+			UID:             string(dep.GetUID()),
+			ResourceVersion: dep.GetResourceVersion(),
 		},
 	}
 }
@@ -0,0 +1,265 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	reasonPolicyConflict = "Policy conflict"
+	// eventReasonPolicyConflict is the reason of the Kubernetes event emitted on the policy when a conflict with
+	// another policy is first detected.
+	eventReasonPolicyConflict = "PolicyConflict"
+)
+
+// enforcedObjectKey identifies an object enforced by policies regardless of the API version used by each policy.
+type enforcedObjectKey struct {
+	groupKind schema.GroupKind
+	namespace string
+	name      string
+}
+
+// enforcedFields are the fields that a policy enforces on an object, with the JSON pointer of each field as the key.
+type enforcedFields struct {
+	fields map[string]interface{}
+	// conflicts are the other policies that this policy conflicted with in its last evaluation of the object.
+	conflicts map[string]bool
+}
+
+// policyConflict is a conflict between the fields a policy enforces on an object and the fields another policy
+// enforces on the same object.
+type policyConflict struct {
+	policy string
+	paths  []string
+}
+
+// getEnforcedFields returns the fields set by the object definition keyed by their JSON pointer. The fields that
+// identify the object, the status, and the ignored fields aren't included. Lists are also not included since the list
+// items of musthave object templates are merged rather than replaced, so different lists don't necessarily conflict.
+func getEnforcedFields(desiredObj *unstructured.Unstructured, ignoredPaths [][]string) map[string]interface{} {
+	obj := desiredObj.DeepCopy()
+
+	removeIgnoredFields(obj, ignoredPaths)
+	unstructured.RemoveNestedField(obj.Object, "apiVersion")
+	unstructured.RemoveNestedField(obj.Object, "kind")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "name")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "namespace")
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	fields := map[string]interface{}{}
+
+	var flatten func(path []string, value interface{})
+
+	flatten = func(path []string, value interface{}) {
+		switch typedValue := value.(type) {
+		case map[string]interface{}:
+			for key, nestedValue := range typedValue {
+				flatten(append(append([]string{}, path...), key), nestedValue)
+			}
+		case []interface{}:
+			return
+		default:
+			fields[toJSONPointer(path)] = value
+		}
+	}
+
+	flatten(nil, obj.Object)
+
+	return fields
+}
+
+// toJSONPointer converts the list of keys to a JSON pointer, which is the inverse of splitJSONPointer.
+func toJSONPointer(keys []string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+
+	var pointer strings.Builder
+
+	for _, key := range keys {
+		pointer.WriteString("/" + escaper.Replace(key))
+	}
+
+	return pointer.String()
+}
+
+// recordEnforcedFields records the fields that the policy enforces on the object and returns the conflicts with the
+// fields that other policies enforce on the same object, sorted by the policy. A conflict is a field set to different
+// values by the policies.
+func (r *ConfigurationPolicyReconciler) recordEnforcedFields(
+	obj singleObject, ignoredPaths [][]string,
+) []policyConflict {
+	key := enforcedObjectKey{
+		groupKind: obj.desiredObj.GroupVersionKind().GroupKind(),
+		namespace: obj.namespace,
+		name:      obj.name,
+	}
+	policyMap := &sync.Map{}
+
+	// This prevents the object's entry from being deleted as empty while this policy's fields are recorded in it
+	r.enforcedFieldsLock.RLock()
+	defer r.enforcedFieldsLock.RUnlock()
+
+	loadedPolicyMap, loaded := r.enforcedFieldsCache.LoadOrStore(key, policyMap)
+	if loaded {
+		policyMap = loadedPolicyMap.(*sync.Map)
+	}
+
+	thisPolicy := policyKey(obj.policy)
+	fields := &enforcedFields{
+		fields:    getEnforcedFields(&obj.desiredObj, ignoredPaths),
+		conflicts: map[string]bool{},
+	}
+	conflicts := []policyConflict{}
+
+	policyMap.Range(func(otherPolicy, otherFields interface{}) bool {
+		if otherPolicy == thisPolicy {
+			return true
+		}
+
+		conflict := policyConflict{policy: otherPolicy.(string)}
+
+		for pointer, value := range fields.fields {
+			otherValue, ok := otherFields.(*enforcedFields).fields[pointer]
+			if ok && !reflect.DeepEqual(value, otherValue) {
+				conflict.paths = append(conflict.paths, pointer)
+			}
+		}
+
+		if len(conflict.paths) != 0 {
+			sort.Strings(conflict.paths)
+			conflicts = append(conflicts, conflict)
+			fields.conflicts[conflict.policy] = true
+		}
+
+		return true
+	})
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].policy < conflicts[j].policy })
+
+	previous, _ := policyMap.Swap(thisPolicy, fields)
+	newConflict := false
+
+	for _, conflict := range conflicts {
+		if previous != nil && previous.(*enforcedFields).conflicts[conflict.policy] {
+			continue
+		}
+
+		newConflict = true
+
+		log.Info(
+			"Detected a conflict with another policy enforcing the same object", "policy", obj.policy.Name,
+			"otherPolicy", conflict.policy, "kind", obj.desiredObj.GetKind(), "name", obj.name,
+			"namespace", obj.namespace, "fields", conflict.paths,
+		)
+	}
+
+	// The event is only emitted when a conflict is first detected rather than on every evaluation
+	if newConflict && r.Recorder != nil {
+		r.Recorder.Event(obj.policy, eventWarning, eventReasonPolicyConflict, conflictMessage(obj, conflicts))
+	}
+
+	return conflicts
+}
+
+// forgetEnforcedFields removes the record of the fields that the policy enforces on the object, which is done when
+// the policy no longer enforces it.
+func (r *ConfigurationPolicyReconciler) forgetEnforcedFields(obj singleObject) {
+	key := enforcedObjectKey{
+		groupKind: obj.desiredObj.GroupVersionKind().GroupKind(),
+		namespace: obj.namespace,
+		name:      obj.name,
+	}
+
+	if loadedPolicyMap, loaded := r.enforcedFieldsCache.Load(key); loaded {
+		loadedPolicyMap.(*sync.Map).Delete(policyKey(obj.policy))
+		r.deleteEmptyEnforcedFields(key, loadedPolicyMap.(*sync.Map))
+	}
+}
+
+// pruneEnforcedFields removes the record of the fields that the policy enforces on the objects that are no longer
+// related to it. If relatedObjects is nil, the records of every object are removed, such as when the policy is
+// deleted.
+func (r *ConfigurationPolicyReconciler) pruneEnforcedFields(policy string, relatedObjects []policyv1.RelatedObject) {
+	related := make(map[enforcedObjectKey]bool, len(relatedObjects))
+
+	for _, object := range relatedObjects {
+		key := enforcedObjectKey{
+			groupKind: schema.FromAPIVersionAndKind(object.Object.APIVersion, object.Object.Kind).GroupKind(),
+			namespace: object.Object.Metadata.Namespace,
+			name:      object.Object.Metadata.Name,
+		}
+		related[key] = true
+	}
+
+	r.enforcedFieldsCache.Range(func(key, policyMap interface{}) bool {
+		if !related[key.(enforcedObjectKey)] {
+			policyMap.(*sync.Map).Delete(policy)
+			r.deleteEmptyEnforcedFields(key.(enforcedObjectKey), policyMap.(*sync.Map))
+		}
+
+		return true
+	})
+}
+
+// deleteEmptyEnforcedFields removes the object from the enforced fields cache if no policy enforces it anymore, so
+// that the cache doesn't grow with the objects that were enforced in the past.
+func (r *ConfigurationPolicyReconciler) deleteEmptyEnforcedFields(key enforcedObjectKey, policyMap *sync.Map) {
+	r.enforcedFieldsLock.Lock()
+	defer r.enforcedFieldsLock.Unlock()
+
+	empty := true
+
+	policyMap.Range(func(_, _ interface{}) bool {
+		empty = false
+
+		return false
+	})
+
+	if empty {
+		r.enforcedFieldsCache.CompareAndDelete(key, policyMap)
+	}
+}
+
+// conflictingPaths returns the paths of the fields that conflict with other policies, which aren't enforced.
+func conflictingPaths(conflicts []policyConflict) [][]string {
+	paths := [][]string{}
+
+	for _, conflict := range conflicts {
+		for _, pointer := range conflict.paths {
+			keys, err := splitJSONPointer(pointer, "conflict")
+			if err == nil {
+				paths = append(paths, keys)
+			}
+		}
+	}
+
+	return paths
+}
+
+// conflictMessage returns the noncompliant message describing the conflicts with other policies.
+func conflictMessage(obj singleObject, conflicts []policyConflict) string {
+	msg := fmt.Sprintf("%s [%s]", obj.gvr.Resource, obj.name)
+	if obj.namespace != "" {
+		msg += " in namespace " + obj.namespace
+	}
+
+	descriptions := make([]string, 0, len(conflicts))
+
+	for _, conflict := range conflicts {
+		descriptions = append(descriptions, fmt.Sprintf(
+			"the policy %s on the fields %s", conflict.policy, strings.Join(conflict.paths, ", "),
+		))
+	}
+
+	return msg + " conflicts with " + strings.Join(descriptions, " and with ") +
+		", so the conflicting fields aren't enforced"
+}
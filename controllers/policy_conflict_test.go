@@ -0,0 +1,109 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func conflictTestObject(policyName string, data map[string]interface{}) singleObject {
+	return singleObject{
+		policy: &policyv1.ConfigurationPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: policyName, Namespace: "managed"},
+		},
+		gvr:       schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		name:      "my-configmap",
+		namespace: "default",
+		desiredObj: unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "my-configmap",
+				"namespace": "default",
+			},
+			"data": data,
+		}},
+	}
+}
+
+func TestGetEnforcedFields(t *testing.T) {
+	t.Parallel()
+
+	obj := conflictTestObject("policy", map[string]interface{}{"a/b": "1", "c": "2", "ignored": "3"})
+	obj.desiredObj.Object["spec"] = map[string]interface{}{"list": []interface{}{"x"}}
+	obj.desiredObj.Object["status"] = map[string]interface{}{"phase": "Active"}
+
+	fields := getEnforcedFields(&obj.desiredObj, [][]string{{"data", "ignored"}})
+	assert.Equal(t, map[string]interface{}{"/data/a~1b": "1", "/data/c": "2"}, fields)
+
+	keys, err := splitJSONPointer(toJSONPointer([]string{"data", "a/b", "~c"}), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data", "a/b", "~c"}, keys)
+}
+
+func TestRecordEnforcedFields(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{}
+
+	first := conflictTestObject("first", map[string]interface{}{"shared": "same", "key": "first"})
+	second := conflictTestObject("second", map[string]interface{}{"shared": "same", "key": "second", "other": "x"})
+
+	assert.Empty(t, r.recordEnforcedFields(first, nil))
+
+	conflicts := r.recordEnforcedFields(second, nil)
+	assert.Equal(t, []policyConflict{{policy: "managed/first", paths: []string{"/data/key"}}}, conflicts)
+	assert.Equal(t, [][]string{{"data", "key"}}, conflictingPaths(conflicts))
+	assert.Equal(
+		t,
+		"configmaps [my-configmap] in namespace default conflicts with the policy managed/first on the fields "+
+			"/data/key, so the conflicting fields aren't enforced",
+		conflictMessage(second, conflicts),
+	)
+
+	// Both policies report the conflict
+	assert.Equal(
+		t,
+		[]policyConflict{{policy: "managed/second", paths: []string{"/data/key"}}},
+		r.recordEnforcedFields(first, nil),
+	)
+
+	// The conflict is resolved once the other policy no longer enforces the object
+	r.forgetEnforcedFields(second)
+	assert.Empty(t, r.recordEnforcedFields(first, nil))
+
+	assert.NotEmpty(t, r.recordEnforcedFields(second, nil))
+	r.pruneEnforcedFields("managed/first", nil)
+	assert.Empty(t, r.recordEnforcedFields(second, nil))
+
+	// Objects that are still related to the policy are kept
+	assert.NotEmpty(t, r.recordEnforcedFields(first, nil))
+	r.pruneEnforcedFields("managed/second", []policyv1.RelatedObject{{
+		Object: policyv1.ObjectResource{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+			Metadata:   policyv1.ObjectMetadata{Name: "my-configmap", Namespace: "default"},
+		},
+	}})
+	assert.NotEmpty(t, r.recordEnforcedFields(first, nil))
+
+	// The object is removed from the cache once no policy enforces it
+	r.forgetEnforcedFields(second)
+	r.pruneEnforcedFields("managed/first", nil)
+
+	cached := 0
+
+	r.enforcedFieldsCache.Range(func(_, _ interface{}) bool {
+		cached++
+
+		return true
+	})
+	assert.Zero(t, cached)
+}
@@ -0,0 +1,191 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// rawRefSyncTimeout is how long to wait for the initial list of the object referenced by spec.objectTemplatesRawRef.
+var rawRefSyncTimeout = 30 * time.Second
+
+// rawRefWatch is the watch of the object referenced by the spec.objectTemplatesRawRef of a policy.
+type rawRefWatch struct {
+	kind      string
+	namespace string
+	name      string
+	informer  cache.SharedIndexInformer
+	cancel    context.CancelFunc
+}
+
+// rawRefWatcher watches the objects referenced by the spec.objectTemplatesRawRef of the policies so that they're read
+// from a cache at each evaluation loop rather than retrieved from the API server. Each watch is limited to the single
+// referenced object with a field selector.
+type rawRefWatcher struct {
+	// watches has the ConfigurationPolicy namespace and name as the key and the values are *rawRefWatch objects.
+	watches map[string]*rawRefWatch
+	lock    sync.Mutex
+}
+
+// get returns the ConfigMap or Secret referenced by the input spec.objectTemplatesRawRef of the policy from the watch
+// cache. The watch is started if the policy doesn't have one for the referenced object yet, in which case the initial
+// list is waited for. A NotFound error is returned if the referenced object doesn't exist. A value is sent on the
+// evaluationTriggers channel, if set, when the referenced object changes.
+func (w *rawRefWatcher) get(
+	client kubernetes.Interface,
+	policyKey string,
+	ref *policyv1.ObjectTemplatesRawRef,
+	evaluationTriggers chan<- struct{},
+) (runtime.Object, error) {
+	watch, err := w.watch(client, policyKey, ref, evaluationTriggers)
+	if err != nil {
+		return nil, err
+	}
+
+	if !watch.informer.HasSynced() {
+		ctx, cancel := context.WithTimeout(context.TODO(), rawRefSyncTimeout)
+		defer cancel()
+
+		if !cache.WaitForCacheSync(ctx.Done(), watch.informer.HasSynced) {
+			return nil, fmt.Errorf("timed out waiting for the watch of the %s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+		}
+	}
+
+	obj, exists, err := watch.informer.GetStore().GetByKey(ref.Namespace + "/" + ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		resource := schema.GroupResource{Resource: "configmaps"}
+		if ref.Kind == "Secret" {
+			resource.Resource = "secrets"
+		}
+
+		return nil, k8serrors.NewNotFound(resource, ref.Name)
+	}
+
+	return obj.(runtime.Object), nil
+}
+
+// watch returns the watch of the object referenced by the input spec.objectTemplatesRawRef of the policy. A previous
+// watch of the policy for a different object is stopped.
+func (w *rawRefWatcher) watch(
+	client kubernetes.Interface,
+	policyKey string,
+	ref *policyv1.ObjectTemplatesRawRef,
+	evaluationTriggers chan<- struct{},
+) (*rawRefWatch, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.watches == nil {
+		w.watches = map[string]*rawRefWatch{}
+	}
+
+	existing := w.watches[policyKey]
+	if existing != nil {
+		if existing.kind == ref.Kind && existing.namespace == ref.Namespace && existing.name == ref.Name {
+			return existing, nil
+		}
+
+		existing.cancel()
+	}
+
+	tweakListOptions := func(opts *metav1.ListOptions) {
+		opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", ref.Name).String()
+	}
+
+	var informer cache.SharedIndexInformer
+
+	if ref.Kind == "Secret" {
+		informer = coreinformers.NewFilteredSecretInformer(
+			client, ref.Namespace, 0, cache.Indexers{}, tweakListOptions,
+		)
+	} else {
+		informer = coreinformers.NewFilteredConfigMapInformer(
+			client, ref.Namespace, 0, cache.Indexers{}, tweakListOptions,
+		)
+	}
+
+	// Wake up the evaluation loop when the referenced object changes so that the policy is evaluated again without
+	// waiting for the remaining update frequency. The resourceVersion comparison in objectTemplatesRawRefChanged
+	// determines if the policy is evaluated.
+	triggerEvaluation := func(_ interface{}) {
+		if evaluationTriggers == nil {
+			return
+		}
+
+		// The channel is buffered, so a pending value already wakes up the evaluation loop
+		select {
+		case evaluationTriggers <- struct{}{}:
+		default:
+		}
+	}
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    triggerEvaluation,
+		UpdateFunc: func(_, newObj interface{}) { triggerEvaluation(newObj) },
+		DeleteFunc: triggerEvaluation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	watch := &rawRefWatch{
+		kind:      ref.Kind,
+		namespace: ref.Namespace,
+		name:      ref.Name,
+		informer:  informer,
+		cancel:    cancel,
+	}
+	w.watches[policyKey] = watch
+
+	go informer.Run(ctx.Done())
+
+	return watch, nil
+}
+
+// stop stops the watch of the object referenced by the spec.objectTemplatesRawRef of the policy.
+func (w *rawRefWatcher) stop(policyKey string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if watch, ok := w.watches[policyKey]; ok {
+		watch.cancel()
+		delete(w.watches, policyKey)
+	}
+}
+
+// rawRefData returns the value of the key in the ConfigMap or Secret and whether the key was found.
+func rawRefData(obj runtime.Object, key string) (string, bool) {
+	switch typedObj := obj.(type) {
+	case *corev1.Secret:
+		value, found := typedObj.Data[key]
+
+		return string(value), found
+	case *corev1.ConfigMap:
+		value, found := typedObj.Data[key]
+
+		return value, found
+	}
+
+	return "", false
+}
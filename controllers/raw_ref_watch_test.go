@@ -0,0 +1,146 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestGetObjectTemplatesRawFromRefWatch(t *testing.T) {
+	t.Parallel()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "templates", Namespace: "policies", ResourceVersion: "1"},
+		Data:       map[string]string{"object-templates-raw": "- complianceType: musthave"},
+	}
+
+	client := testclient.NewSimpleClientset(configMap)
+	r := &ConfigurationPolicyReconciler{TargetK8sClient: client, EvaluationTriggers: make(chan struct{}, 1)}
+	ref := &policyv1.ObjectTemplatesRawRef{
+		Kind: "ConfigMap", Namespace: "policies", Name: "templates", Key: "object-templates-raw",
+	}
+
+	raw, resourceVersion, err := r.getObjectTemplatesRawFromRef("policy", ref)
+	assert.NoError(t, err)
+	assert.Equal(t, "- complianceType: musthave", raw)
+	assert.Equal(t, "1", resourceVersion)
+
+	// Ignore the trigger from the initial list
+	<-r.EvaluationTriggers
+
+	updated := configMap.DeepCopy()
+	updated.ResourceVersion = "2"
+	updated.Data["object-templates-raw"] = "- complianceType: mustnothave"
+
+	_, err = client.CoreV1().ConfigMaps("policies").Update(context.TODO(), updated, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		raw, resourceVersion, err = r.getObjectTemplatesRawFromRef("policy", ref)
+
+		return err == nil && resourceVersion == "2"
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "- complianceType: mustnothave", raw)
+
+	// The update wakes up the evaluation loop
+	assert.Eventually(t, func() bool {
+		return len(r.EvaluationTriggers) == 1
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// The referenced object is read from the watch cache rather than retrieved at each evaluation
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "get", action.GetVerb())
+	}
+
+	_, _, err = r.getObjectTemplatesRawFromRef("policy", &policyv1.ObjectTemplatesRawRef{
+		Kind: "ConfigMap", Namespace: "policies", Name: "templates", Key: "missing",
+	})
+	assert.ErrorContains(t, err, "the ConfigMap policies/templates does not contain the key missing")
+
+	_, resourceVersion, err = r.getObjectTemplatesRawFromRef("policy", &policyv1.ObjectTemplatesRawRef{
+		Kind: "Secret", Namespace: "policies", Name: "templates", Key: "object-templates-raw",
+	})
+	assert.True(t, k8serrors.IsNotFound(err))
+	assert.Equal(t, "", resourceVersion)
+
+	r.rawRefWatcher.stop("policy")
+
+	r.rawRefWatcher.lock.Lock()
+	assert.Empty(t, r.rawRefWatcher.watches)
+	r.rawRefWatcher.lock.Unlock()
+}
+
+func TestRawRefWatcherReusesWatch(t *testing.T) {
+	t.Parallel()
+
+	client := testclient.NewSimpleClientset()
+	watcher := &rawRefWatcher{}
+	ref := &policyv1.ObjectTemplatesRawRef{Kind: "Secret", Namespace: "policies", Name: "templates", Key: "key"}
+
+	for i := 0; i < 3; i++ {
+		_, err := watcher.get(client, "policy", ref, nil)
+		assert.True(t, k8serrors.IsNotFound(err))
+	}
+
+	lists := 0
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" {
+			lists++
+
+			listAction, ok := action.(clienttesting.ListAction)
+			assert.True(t, ok)
+			assert.Equal(t, "metadata.name=templates", listAction.GetListRestrictions().Fields.String())
+		}
+	}
+
+	assert.Equal(t, 1, lists)
+
+	watcher.stop("policy")
+}
+
+func TestRawRefNamespaceAllowed(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{RawRefAllowedNamespaces: []string{"policy-templates", "team-*"}}
+	policy := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			ObjectTemplatesRawRef: &policyv1.ObjectTemplatesRawRef{
+				Kind: "Secret", Namespace: "managed", Name: "templates", Key: "key",
+			},
+		},
+	}
+
+	tests := map[string]bool{
+		"managed":          true,
+		"policy-templates": true,
+		"team-a":           true,
+		"kube-system":      false,
+		"openshift-config": false,
+	}
+
+	for namespace, expected := range tests {
+		policy.Spec.ObjectTemplatesRawRef.Namespace = namespace
+
+		assert.Equal(t, expected, r.rawRefNamespaceAllowed(policy), namespace)
+	}
+
+	// Without the flag, only the policy namespace is allowed
+	r.RawRefAllowedNamespaces = nil
+	policy.Spec.ObjectTemplatesRawRef.Namespace = "team-a"
+
+	assert.False(t, r.rawRefNamespaceAllowed(policy))
+	assert.False(t, r.objectTemplatesRawRefChanged(policy))
+}
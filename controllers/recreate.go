@@ -0,0 +1,137 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	reasonRecreateSuccess = "Object recreated to apply changes"
+	reasonRecreatePending = "Object is being recreated to apply changes"
+	// recreatedMsg and recreatePendingMsg are included in the messages returned by checkAndUpdateResource when the
+	// object is recreated, which is how the reason is determined.
+	recreatedMsg       = "was recreated to apply changes"
+	recreatePendingMsg = "is being deleted to be recreated to apply changes"
+)
+
+// isImmutableFieldErr determines if the update request failed because it changes fields that can't be changed after
+// the object is created (e.g. the Pod spec or the selector of a Deployment).
+func isImmutableFieldErr(err error) bool {
+	if !k8serrors.IsInvalid(err) && !k8serrors.IsForbidden(err) {
+		return false
+	}
+
+	msg := err.Error()
+
+	return strings.Contains(msg, "field is immutable") || strings.Contains(msg, "may not change") ||
+		(strings.Contains(msg, "updates to") && strings.Contains(msg, "are forbidden"))
+}
+
+// shouldRecreate determines if the object is recreated rather than updated when enforcing based on the object
+// template's recreateOption. The input error is the error of the update request, or nil if the update wasn't
+// attempted yet, in which case the object is only recreated with the Always option.
+func shouldRecreate(
+	objectT *policyv1.ObjectTemplate, remediation policyv1.RemediationAction, preview bool, updateErr error,
+) bool {
+	if !remediation.IsEnforce() || preview {
+		return false
+	}
+
+	switch objectT.RecreateOption {
+	case policyv1.RecreateAlways:
+		return updateErr == nil || isImmutableFieldErr(updateErr)
+	case policyv1.RecreateIfRequired:
+		return updateErr != nil && isImmutableFieldErr(updateErr)
+	default:
+		return false
+	}
+}
+
+// recreateObject deletes the object with the object template's deleteOptions and creates it again from the desired
+// object. If the deletion isn't complete yet, such as when dependents are deleted in the foreground, the object is
+// created in a later evaluation. The return values match those of checkAndUpdateResource.
+func (r *ConfigurationPolicyReconciler) recreateObject(
+	obj singleObject, objectT *policyv1.ObjectTemplate, res dynamic.ResourceInterface,
+) (throwSpecViolation bool, message string, updateNeeded bool, updateSucceeded bool, previewDiff string) {
+	log := log.WithValues(
+		"policy", obj.policy.Name, "name", obj.name, "namespace", obj.namespace, "resource", obj.gvr.Resource,
+	)
+	idStr := identifierStr([]string{obj.name}, obj.namespace)
+
+	if obj.existingObj.GetDeletionTimestamp() == nil {
+		log.Info("Deleting the object to recreate it based on the template definition")
+
+		uid := obj.existingObj.GetUID()
+		deleteOptions := metav1.DeleteOptions{
+			PropagationPolicy: objectT.DeleteOptions.PropagationPolicy,
+			// Don't delete the object if it was already recreated by something else
+			Preconditions: &metav1.Preconditions{UID: &uid},
+		}
+
+		if deleted, err := deleteObject(res, obj.name, obj.namespace, deleteOptions); !deleted {
+			message := fmt.Sprintf(
+				"%v %v can't be deleted to be recreated, reason: `%v`", obj.gvr.Resource, idStr, err,
+			)
+
+			return true, message, true, false, ""
+		}
+	}
+
+	if r.objectStillExists(obj) {
+		log.Info("Waiting for the object to be deleted before recreating it")
+
+		return true, fmt.Sprintf("%v %v %v", obj.gvr.Resource, idStr, recreatePendingMsg), true, false, ""
+	}
+
+	var createdObj *unstructured.Unstructured
+	var err error
+
+	if obj.policy.Spec.EnforcementMethod == policyv1.EnforcementMethodServerSideApply {
+		createdObj, err = r.applyObject(res, obj, obj.policy.Spec.ForceConflicts, false)
+	} else {
+		desiredObj := obj.desiredObj.DeepCopy()
+		desiredObj.SetName(obj.name)
+
+		if obj.namespaced {
+			desiredObj.SetNamespace(obj.namespace)
+		}
+
+		fieldManager := policyFieldManager(r.FieldManager, obj.policy.Name)
+
+		createdObj, err = r.createObject(res, *desiredObj, fieldManager, false)
+	}
+
+	if err != nil {
+		message := fmt.Sprintf(
+			"%v %v was deleted to be recreated, and cannot be created, reason: `%v`", obj.gvr.Resource, idStr, err,
+		)
+
+		return true, message, true, false, ""
+	}
+
+	log.Info("Recreated the object based on the template definition")
+
+	r.setEvaluatedObject(obj.policy, createdObj, true)
+
+	return false, fmt.Sprintf("%v %v %v", obj.gvr.Resource, idStr, recreatedMsg), true, true, ""
+}
+
+// isObjectRecreated returns true if the message from checkAndUpdateResource indicates that the object was recreated.
+func isObjectRecreated(message string) bool {
+	return strings.Contains(message, recreatedMsg)
+}
+
+// isRecreatePending returns true if the message from checkAndUpdateResource indicates that the object is being
+// deleted in order to be recreated.
+func isRecreatePending(message string) bool {
+	return strings.Contains(message, recreatePendingMsg)
+}
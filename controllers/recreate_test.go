@@ -0,0 +1,64 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestShouldRecreate(t *testing.T) {
+	t.Parallel()
+
+	groupKind := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	immutableErr := k8serrors.NewInvalid(groupKind, "my-deployment", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "selector"), nil, "field is immutable"),
+	})
+	invalidErr := k8serrors.NewInvalid(groupKind, "my-deployment", field.ErrorList{
+		field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+	})
+
+	assert.True(t, isImmutableFieldErr(immutableErr))
+	assert.False(t, isImmutableFieldErr(invalidErr))
+	assert.False(t, isImmutableFieldErr(errors.New("field is immutable")))
+
+	tests := map[string]struct {
+		option      policyv1.RecreateOption
+		remediation policyv1.RemediationAction
+		preview     bool
+		err         error
+		expected    bool
+	}{
+		"None with an immutable field":  {policyv1.RecreateNone, "enforce", false, immutableErr, false},
+		"unset with an immutable field": {"", "enforce", false, immutableErr, false},
+		"IfRequired with an immutable field": {
+			policyv1.RecreateIfRequired, "enforce", false, immutableErr, true,
+		},
+		"IfRequired with another error": {policyv1.RecreateIfRequired, "enforce", false, invalidErr, false},
+		"IfRequired before the update":  {policyv1.RecreateIfRequired, "enforce", false, nil, false},
+		"IfRequired when inform":        {policyv1.RecreateIfRequired, "inform", false, immutableErr, false},
+		"IfRequired in preview mode":    {policyv1.RecreateIfRequired, "enforce", true, immutableErr, false},
+		"Always before the update":      {policyv1.RecreateAlways, "enforce", false, nil, true},
+		"Always with another error":     {policyv1.RecreateAlways, "enforce", false, invalidErr, false},
+		"Always when inform":            {policyv1.RecreateAlways, "inform", false, nil, false},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			objectT := &policyv1.ObjectTemplate{RecreateOption: test.option}
+
+			assert.Equal(t, test.expected, shouldRecreate(objectT, test.remediation, test.preview, test.err))
+		})
+	}
+}
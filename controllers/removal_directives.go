@@ -0,0 +1,317 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	patchDirectiveKey    = "$patch"
+	patchDirectiveDelete = "delete"
+)
+
+// removalStep is a key in the path to a field with a removal directive. When inList is set, the value at the key is
+// a list and the path continues in the items of the list that have the fields in match.
+type removalStep struct {
+	key    string
+	inList bool
+	match  map[string]interface{}
+}
+
+// fieldRemoval is a field of the objectDefinition with a '$patch: delete' directive, which must be absent from the
+// object. When the last step is in a list, the items of the list that have the fields in its match are removed.
+type fieldRemoval struct {
+	steps []removalStep
+}
+
+func (f fieldRemoval) String() string {
+	keys := make([]string, 0, len(f.steps))
+
+	for _, step := range f.steps {
+		key := step.key
+		if step.inList {
+			matchJSON, _ := json.Marshal(step.match)
+			key += string(matchJSON)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return strings.Join(keys, ".")
+}
+
+// isDeleteDirective determines if the map has a '$patch' directive. An error is returned if the directive isn't
+// 'delete', which is the only one supported.
+func isDeleteDirective(value map[string]interface{}) (bool, error) {
+	directive, ok := value[patchDirectiveKey]
+	if !ok {
+		return false, nil
+	}
+
+	if directive != patchDirectiveDelete {
+		return false, fmt.Errorf("the %s directive %v is not supported, only %s is", patchDirectiveKey, directive,
+			patchDirectiveDelete)
+	}
+
+	return true, nil
+}
+
+// scalarFields returns the fields of the list item that aren't maps or lists, which identify the item in the path to
+// a nested removal directive.
+func scalarFields(item map[string]interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+
+	for key, value := range item {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+		default:
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
+
+// extractRemovalDirectives removes the '$patch: delete' directives from the objectDefinition and returns the fields
+// they refer to. A map value of '{"$patch": "delete"}' removes the key, and a list item with '"$patch": "delete"'
+// removes the items of the list that have the item's other fields. Maps and lists that only contained directives are
+// removed as well, so that they don't require the field to be empty. The input is modified.
+func extractRemovalDirectives(objDef map[string]interface{}) ([]fieldRemoval, error) {
+	removals := []fieldRemoval{}
+
+	var extract func(value map[string]interface{}, steps []removalStep) error
+
+	extract = func(value map[string]interface{}, steps []removalStep) error {
+		for key, child := range value {
+			childSteps := append(append([]removalStep{}, steps...), removalStep{key: key})
+
+			switch child := child.(type) {
+			case map[string]interface{}:
+				isDelete, err := isDeleteDirective(child)
+				if err != nil {
+					return err
+				}
+
+				if isDelete {
+					removals = append(removals, fieldRemoval{steps: childSteps})
+
+					delete(value, key)
+
+					continue
+				}
+
+				hadFields := len(child) != 0
+
+				if err := extract(child, childSteps); err != nil {
+					return err
+				}
+
+				if hadFields && len(child) == 0 {
+					delete(value, key)
+				}
+			case []interface{}:
+				items := make([]interface{}, 0, len(child))
+
+				for _, item := range child {
+					itemMap, ok := item.(map[string]interface{})
+					if !ok {
+						items = append(items, item)
+
+						continue
+					}
+
+					isDelete, err := isDeleteDirective(itemMap)
+					if err != nil {
+						return err
+					}
+
+					if isDelete {
+						match := map[string]interface{}{}
+
+						for itemKey, itemValue := range itemMap {
+							if itemKey != patchDirectiveKey {
+								match[itemKey] = itemValue
+							}
+						}
+
+						if len(match) == 0 {
+							return fmt.Errorf(
+								"the list item with the %s directive in %s must have other fields to identify the items "+
+									"to remove", patchDirectiveKey, fieldRemoval{steps: childSteps},
+							)
+						}
+
+						itemSteps := append(
+							append([]removalStep{}, steps...), removalStep{key: key, inList: true, match: match},
+						)
+						removals = append(removals, fieldRemoval{steps: itemSteps})
+
+						continue
+					}
+
+					itemSteps := append(
+						append([]removalStep{}, steps...),
+						removalStep{key: key, inList: true, match: scalarFields(itemMap)},
+					)
+
+					if err := extract(itemMap, itemSteps); err != nil {
+						return err
+					}
+
+					items = append(items, itemMap)
+				}
+
+				if len(child) != 0 && len(items) == 0 {
+					delete(value, key)
+				} else {
+					value[key] = items
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if err := extract(objDef, []removalStep{}); err != nil {
+		return nil, err
+	}
+
+	for _, removal := range removals {
+		if removal.steps[0].key == "status" {
+			return nil, fmt.Errorf("the %s directive can't be used in the status", patchDirectiveKey)
+		}
+
+		if len(removal.steps) > 2 || removal.steps[len(removal.steps)-1].inList {
+			continue
+		}
+
+		switch removal.String() {
+		case "apiVersion", "kind", "metadata", "metadata.name", "metadata.namespace":
+			return nil, fmt.Errorf("the %s directive on %s can't remove a field that identifies the object",
+				patchDirectiveKey, removal)
+		}
+	}
+
+	return removals, nil
+}
+
+// validateRemovalDirectives returns an error if the '$patch' directives in the objectDefinition are invalid. A
+// server-side apply only sets the fields of the objectDefinition, so the directives aren't supported with it.
+func validateRemovalDirectives(rawObjDef []byte, mustNotHave bool, serverSideApply bool) error {
+	objDef := map[string]interface{}{}
+
+	if err := json.Unmarshal(rawObjDef, &objDef); err != nil {
+		// An invalid objectDefinition is reported when the object template is evaluated
+		return nil //nolint:nilerr
+	}
+
+	removals, err := extractRemovalDirectives(objDef)
+	if err != nil {
+		return err
+	}
+
+	if mustNotHave && len(removals) != 0 {
+		return errors.New("the $patch directive can't be used with mustnothave")
+	}
+
+	if serverSideApply && len(removals) != 0 {
+		return errors.New("the $patch directive isn't supported with the ServerSideApply enforcementMethod")
+	}
+
+	return nil
+}
+
+// itemMatches determines if the list item has the input fields.
+func itemMatches(item interface{}, match map[string]interface{}) bool {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for key, value := range match {
+		// The values are compared as strings since the number types of the objectDefinition and the object differ
+		if fieldValue, ok := itemMap[key]; !ok || fmt.Sprint(fieldValue) != fmt.Sprint(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyRemoval removes the field at the steps from the value and returns whether it was present.
+func applyRemoval(value interface{}, steps []removalStep) bool {
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	step := steps[0]
+
+	child, ok := valueMap[step.key]
+	if !ok {
+		return false
+	}
+
+	if !step.inList {
+		if len(steps) == 1 {
+			delete(valueMap, step.key)
+
+			return true
+		}
+
+		return applyRemoval(child, steps[1:])
+	}
+
+	list, ok := child.([]interface{})
+	if !ok {
+		return false
+	}
+
+	removed := false
+
+	if len(steps) == 1 {
+		items := make([]interface{}, 0, len(list))
+
+		for _, item := range list {
+			if itemMatches(item, step.match) {
+				removed = true
+
+				continue
+			}
+
+			items = append(items, item)
+		}
+
+		if removed {
+			valueMap[step.key] = items
+		}
+
+		return removed
+	}
+
+	for _, item := range list {
+		if itemMatches(item, step.match) && applyRemoval(item, steps[1:]) {
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+// applyRemovals removes the fields with a removal directive from the object and returns the removals that were
+// applied, meaning that the fields were present.
+func applyRemovals(obj map[string]interface{}, removals []fieldRemoval) []fieldRemoval {
+	applied := []fieldRemoval{}
+
+	for _, removal := range removals {
+		if applyRemoval(obj, removal.steps) {
+			applied = append(applied, removal)
+		}
+	}
+
+	return applied
+}
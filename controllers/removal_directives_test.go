@@ -0,0 +1,209 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func removalDirectivesTestObjDef(t *testing.T) map[string]interface{} {
+	t.Helper()
+
+	objDef := map[string]interface{}{}
+
+	err := json.Unmarshal([]byte(`{
+		"apiVersion": "apps/v1",
+		"kind": "Deployment",
+		"metadata": {
+			"name": "my-deployment",
+			"annotations": {"remove-me": {"$patch": "delete"}}
+		},
+		"spec": {
+			"replicas": 2,
+			"template": {
+				"spec": {
+					"containers": [
+						{
+							"name": "app",
+							"env": [{"name": "DEBUG", "$patch": "delete"}, {"name": "KEEP", "value": "true"}]
+						}
+					]
+				}
+			}
+		}
+	}`), &objDef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return objDef
+}
+
+func TestExtractRemovalDirectives(t *testing.T) {
+	t.Parallel()
+
+	objDef := removalDirectivesTestObjDef(t)
+
+	removals, err := extractRemovalDirectives(objDef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removalStrs := []string{}
+	for _, removal := range removals {
+		removalStrs = append(removalStrs, removal.String())
+	}
+
+	assert.ElementsMatch(
+		t,
+		[]string{
+			"metadata.annotations.remove-me",
+			`spec.template.spec.containers{"name":"app"}.env{"name":"DEBUG"}`,
+		},
+		removalStrs,
+	)
+
+	// The directives are removed along with the maps that only contained directives
+	objDefJSON, err := json.Marshal(objDef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(
+		t,
+		`{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {"name": "my-deployment"},
+			"spec": {
+				"replicas": 2,
+				"template": {
+					"spec": {"containers": [{"name": "app", "env": [{"name": "KEEP", "value": "true"}]}]}
+				}
+			}
+		}`,
+		string(objDefJSON),
+	)
+}
+
+func TestValidateRemovalDirectives(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		objDef          string
+		mustNotHave     bool
+		serverSideApply bool
+		errMsg          string
+	}{
+		"valid": {
+			objDef: `{"metadata": {"labels": {"foo": {"$patch": "delete"}}}}`,
+		},
+		"unsupported directive": {
+			objDef: `{"metadata": {"labels": {"$patch": "replace"}}}`,
+			errMsg: "the $patch directive replace is not supported, only delete is",
+		},
+		"list item without fields": {
+			objDef: `{"spec": {"finalizers": [{"$patch": "delete"}]}}`,
+			errMsg: "the list item with the $patch directive in spec.finalizers must have other fields to " +
+				"identify the items to remove",
+		},
+		"identifying field": {
+			objDef: `{"metadata": {"name": {"$patch": "delete"}}}`,
+			errMsg: "the $patch directive on metadata.name can't remove a field that identifies the object",
+		},
+		"status": {
+			objDef: `{"status": {"phase": {"$patch": "delete"}}}`,
+			errMsg: "the $patch directive can't be used in the status",
+		},
+		"mustnothave": {
+			objDef:      `{"metadata": {"labels": {"foo": {"$patch": "delete"}}}}`,
+			mustNotHave: true,
+			errMsg:      "the $patch directive can't be used with mustnothave",
+		},
+		"server-side apply": {
+			objDef:          `{"metadata": {"labels": {"foo": {"$patch": "delete"}}}}`,
+			serverSideApply: true,
+			errMsg:          "the $patch directive isn't supported with the ServerSideApply enforcementMethod",
+		},
+		"server-side apply without directives": {
+			objDef:          `{"metadata": {"labels": {"foo": "bar"}}}`,
+			serverSideApply: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateRemovalDirectives([]byte(test.objDef), test.mustNotHave, test.serverSideApply)
+			if test.errMsg == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, test.errMsg)
+		})
+	}
+}
+
+func TestApplyRemovals(t *testing.T) {
+	t.Parallel()
+
+	removals, err := extractRemovalDirectives(removalDirectivesTestObjDef(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := map[string]interface{}{}
+
+	err = json.Unmarshal([]byte(`{
+		"metadata": {"name": "my-deployment", "annotations": {"remove-me": "yes", "other": "value"}},
+		"spec": {
+			"replicas": 2,
+			"template": {
+				"spec": {
+					"containers": [
+						{"name": "app", "env": [{"name": "DEBUG", "value": "1"}, {"name": "KEEP", "value": "true"}]},
+						{"name": "sidecar", "env": [{"name": "DEBUG", "value": "1"}]}
+					]
+				}
+			}
+		}
+	}`), &existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, applyRemovals(existing, removals), 2)
+
+	existingJSON, err := json.Marshal(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(
+		t,
+		`{
+			"metadata": {"name": "my-deployment", "annotations": {"other": "value"}},
+			"spec": {
+				"replicas": 2,
+				"template": {
+					"spec": {
+						"containers": [
+							{"name": "app", "env": [{"name": "KEEP", "value": "true"}]},
+							{"name": "sidecar", "env": [{"name": "DEBUG", "value": "1"}]}
+						]
+					}
+				}
+			}
+		}`,
+		string(existingJSON),
+	)
+
+	// Nothing is removed once the fields are absent
+	assert.Empty(t, applyRemovals(existing, removals))
+}
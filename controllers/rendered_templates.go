@@ -0,0 +1,180 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+	yaml "sigs.k8s.io/yaml"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	// debugRenderedTemplatesAnnotation makes the controller log the rendered object templates of each evaluation when
+	// set to "Log", and also record them in the status when set to "Status".
+	debugRenderedTemplatesAnnotation = "policy.open-cluster-management.io/debug-rendered-templates"
+	debugRenderedTemplatesLog        = "Log"
+	debugRenderedTemplatesStatus     = "Status"
+	// The maximum size in bytes of the rendered object templates recorded in the status
+	maxRenderedTemplatesBytes  = 16 * 1024
+	renderedTemplatesTruncated = "\n# ... truncated"
+	// renderedTemplatesNotRecorded is recorded in the status instead of the rendered object templates of a policy
+	// that resolves templates, since they can render Secret values
+	renderedTemplatesNotRecorded = "# The rendered object templates aren't recorded in the status since the policy " +
+		"resolves templates, which can render Secret values. Set the annotation to Log to output them with the " +
+		"values redacted in the controller log."
+)
+
+// renderedTemplatesDebugMode returns the value of the debug-rendered-templates annotation of the policy, which is
+// either debugRenderedTemplatesLog, debugRenderedTemplatesStatus, or an empty string when the debug output is disabled.
+// The value is case insensitive and an unknown value disables the debug output.
+func renderedTemplatesDebugMode(plc *policyv1.ConfigurationPolicy) string {
+	value, ok := plc.GetAnnotations()[debugRenderedTemplatesAnnotation]
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case strings.EqualFold(value, debugRenderedTemplatesLog):
+		return debugRenderedTemplatesLog
+	case strings.EqualFold(value, debugRenderedTemplatesStatus):
+		return debugRenderedTemplatesStatus
+	default:
+		log.Info(
+			"Ignoring the invalid value of the annotation, which must be Log or Status",
+			"annotation", debugRenderedTemplatesAnnotation, "value", value,
+		)
+
+		return ""
+	}
+}
+
+// redactRenderedValues replaces the values in the objectDefinition with redactedSecretValue, except the fields that
+// identify the object, so that only the structure of the rendered object is output. The metadata labels and
+// annotations are redacted as well since their values can come from a Secret, such as a checksum annotation.
+func redactRenderedValues(value interface{}, path []string) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typedValue {
+			typedValue[key] = redactRenderedValues(child, append(append([]string{}, path...), key))
+		}
+
+		return typedValue
+	case []interface{}:
+		for i, item := range typedValue {
+			typedValue[i] = redactRenderedValues(item, path)
+		}
+
+		return typedValue
+	}
+
+	switch strings.Join(path, ".") {
+	case "apiVersion", "kind", "metadata.name", "metadata.namespace", "metadata.generateName":
+		return value
+	}
+
+	return redactedSecretValue
+}
+
+// renderedTemplatesOutput returns the YAML of the rendered object templates for debugging. The values of Secret
+// objectDefinitions are redacted as in the diffs. When the policy resolves templates, every value of the
+// objectDefinitions besides the fields that identify the object is redacted since any of them can come from a Secret.
+func renderedTemplatesOutput(objTemps []*policyv1.ObjectTemplate, resolvesTemplates bool) (string, error) {
+	redactedTemps := make([]*policyv1.ObjectTemplate, 0, len(objTemps))
+
+	for _, objectT := range objTemps {
+		if objectT == nil {
+			continue
+		}
+
+		obj := unstructured.Unstructured{}
+
+		// An invalid objectDefinition is output as is since it's reported when the object template is evaluated
+		if err := json.Unmarshal(objectT.ObjectDefinition.Raw, &obj.Object); err != nil {
+			redactedTemps = append(redactedTemps, objectT)
+
+			continue
+		}
+
+		if resolvesTemplates {
+			obj.Object, _ = redactRenderedValues(obj.Object, nil).(map[string]interface{})
+		} else if obj.GetKind() == "Secret" {
+			redactedObj, _ := redactSecretValues(&obj, &obj)
+			obj = *redactedObj
+		} else {
+			redactedTemps = append(redactedTemps, objectT)
+
+			continue
+		}
+
+		redactedJSON, err := json.Marshal(obj.Object)
+		if err != nil {
+			return "", err
+		}
+
+		redactedT := objectT.DeepCopy()
+		redactedT.ObjectDefinition.Raw = redactedJSON
+
+		redactedTemps = append(redactedTemps, redactedT)
+	}
+
+	output, err := yaml.Marshal(redactedTemps)
+	if err != nil {
+		return "", err
+	}
+
+	return string(output), nil
+}
+
+// truncateRenderedTemplates shortens the rendered object templates to fit in maxRenderedTemplatesBytes for the
+// status, and marks that it was shortened.
+func truncateRenderedTemplates(output string) string {
+	if len(output) <= maxRenderedTemplatesBytes {
+		return output
+	}
+
+	truncated := output[:maxRenderedTemplatesBytes-len(renderedTemplatesTruncated)]
+
+	// Don't leave a partial multibyte character at the end
+	return strings.ToValidUTF8(truncated, "") + renderedTemplatesTruncated
+}
+
+// recordRenderedTemplates logs the rendered object templates of the policy when the debug-rendered-templates
+// annotation is set, and records them in the status when it's set to "Status". A policy that resolves templates only
+// has a note in the status since the values it renders can't be told apart from Secret values. Otherwise, they're
+// cleared from the status.
+func recordRenderedTemplates(plc *policyv1.ConfigurationPolicy, resolvesTemplates bool) {
+	mode := renderedTemplatesDebugMode(plc)
+	if mode == "" {
+		plc.Status.RenderedObjectTemplates = ""
+
+		return
+	}
+
+	log := log.WithValues("policy", plc.GetName())
+
+	output, err := renderedTemplatesOutput(plc.Spec.ObjectTemplates, resolvesTemplates)
+	if err != nil {
+		log.Error(err, "Failed to output the rendered object templates for debugging")
+
+		plc.Status.RenderedObjectTemplates = ""
+
+		return
+	}
+
+	log.Info("Debug output of the rendered object templates", "renderedObjectTemplates", output)
+
+	switch {
+	case mode != debugRenderedTemplatesStatus:
+		plc.Status.RenderedObjectTemplates = ""
+	case resolvesTemplates:
+		log.Info("Not recording the rendered object templates in the status since the policy resolves templates")
+
+		plc.Status.RenderedObjectTemplates = renderedTemplatesNotRecorded
+	default:
+		plc.Status.RenderedObjectTemplates = truncateRenderedTemplates(output)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestRenderedTemplatesDebugMode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		annotations map[string]string
+		expected    string
+	}{
+		"no annotation": {expected: ""},
+		"log":           {annotations: map[string]string{debugRenderedTemplatesAnnotation: "Log"}, expected: "Log"},
+		"status":        {annotations: map[string]string{debugRenderedTemplatesAnnotation: "status"}, expected: "Status"},
+		"invalid":       {annotations: map[string]string{debugRenderedTemplatesAnnotation: "true"}, expected: ""},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			plc := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+
+			assert.Equal(t, test.expected, renderedTemplatesDebugMode(plc))
+		})
+	}
+}
+
+func TestRenderedTemplatesOutput(t *testing.T) {
+	t.Parallel()
+
+	objTemps := []*policyv1.ObjectTemplate{
+		{
+			ComplianceType: policyv1.MustHave,
+			ObjectDefinition: runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"creds"},"data":{"pw":"c2VjcmV0"}}`),
+			},
+		},
+		{
+			ComplianceType: policyv1.MustHave,
+			ObjectDefinition: runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"},"data":{"key":"value"}}`),
+			},
+		},
+	}
+
+	output, err := renderedTemplatesOutput(objTemps, false)
+	assert.NoError(t, err)
+	assert.Equal(t, `- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    data:
+      pw: <REDACTED>
+    kind: Secret
+    metadata:
+      name: creds
+- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    data:
+      key: value
+    kind: ConfigMap
+    metadata:
+      name: cm
+`, output)
+
+	// Every value besides the fields that identify the object is redacted when the policy resolves templates, including
+	// the labels and annotations
+	objTemps = append(objTemps, &policyv1.ObjectTemplate{
+		ComplianceType: policyv1.MustHave,
+		ObjectDefinition: runtime.RawExtension{
+			Raw: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"app","namespace":"default",` +
+				`"annotations":{"checksum/secret":"abc123"}},"spec":{"replicas":2,"template":{"spec":{"containers":` +
+				`[{"name":"app","env":[{"name":"PW","value":"secret"}]}]}}}}`),
+		},
+	})
+
+	output, err = renderedTemplatesOutput(objTemps[1:], true)
+	assert.NoError(t, err)
+	assert.Equal(t, `- complianceType: musthave
+  objectDefinition:
+    apiVersion: v1
+    data:
+      key: <REDACTED>
+    kind: ConfigMap
+    metadata:
+      name: cm
+- complianceType: musthave
+  objectDefinition:
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      annotations:
+        checksum/secret: <REDACTED>
+      name: app
+      namespace: default
+    spec:
+      replicas: <REDACTED>
+      template:
+        spec:
+          containers:
+          - env:
+            - name: <REDACTED>
+              value: <REDACTED>
+            name: <REDACTED>
+`, output)
+
+	// The input object templates aren't modified
+	assert.JSONEq(
+		t,
+		`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"creds"},"data":{"pw":"c2VjcmV0"}}`,
+		string(objTemps[0].ObjectDefinition.Raw),
+	)
+}
+
+func TestRecordRenderedTemplates(t *testing.T) {
+	t.Parallel()
+
+	plc := &policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "policy",
+			Annotations: map[string]string{debugRenderedTemplatesAnnotation: "Status"},
+		},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			ObjectTemplates: []*policyv1.ObjectTemplate{{
+				ComplianceType: policyv1.MustHave,
+				ObjectDefinition: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"},"data":{"key":"value"}}`),
+				},
+			}},
+		},
+	}
+
+	recordRenderedTemplates(plc, false)
+	assert.Contains(t, plc.Status.RenderedObjectTemplates, "key: value")
+
+	// The Status mode is refused when the policy resolves templates
+	recordRenderedTemplates(plc, true)
+	assert.Equal(t, renderedTemplatesNotRecorded, plc.Status.RenderedObjectTemplates)
+
+	plc.SetAnnotations(nil)
+
+	recordRenderedTemplates(plc, false)
+	assert.Equal(t, "", plc.Status.RenderedObjectTemplates)
+}
+
+func TestTruncateRenderedTemplates(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "short", truncateRenderedTemplates("short"))
+
+	// The multibyte characters are offset by one byte so that one is cut
+	truncated := truncateRenderedTemplates("x" + strings.Repeat("é", maxRenderedTemplatesBytes))
+
+	assert.LessOrEqual(t, len(truncated), maxRenderedTemplatesBytes)
+	assert.True(t, strings.HasSuffix(truncated, renderedTemplatesTruncated))
+	assert.NotContains(t, truncated, "�")
+}
@@ -0,0 +1,338 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"text/template/parse"
+	"time"
+
+	templates "github.com/stolostron/go-template-utils/v4/pkg/templates"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// selectorTemplateFuncs are the template functions that can be used in a namespaceSelector whose references can be
+// watched. fromConfigMap is the only function that reads from the cluster, and its namespace and name arguments must
+// be literal strings. Templates that use any other function are resolved at every evaluation loop instead.
+var selectorTemplateFuncs = map[string]bool{
+	"fromConfigMap": true,
+	"and":           true,
+	"or":            true,
+	"not":           true,
+	"eq":            true,
+	"ne":            true,
+	"index":         true,
+	"len":           true,
+	"print":         true,
+	"printf":        true,
+	"default":       true,
+	"join":          true,
+	"split":         true,
+	"lower":         true,
+	"upper":         true,
+	"replace":       true,
+	"trim":          true,
+	"toLiteral":     true,
+}
+
+// selectorResolver holds the template resolver of a policy's namespaceSelector and the watch of the ConfigMaps that its
+// templates reference.
+type selectorResolver struct {
+	resolver *templates.TemplateResolver
+	// refs are the ConfigMaps referenced by the namespaceSelector templates. This is nil when the references can't be
+	// determined without resolving the templates.
+	refs   []types.NamespacedName
+	cancel context.CancelFunc
+	// changed is true when a referenced ConfigMap changed since the namespaceSelector templates were last resolved.
+	changed bool
+	lock    sync.Mutex
+}
+
+// selectorWatcher watches the ConfigMaps referenced by the namespaceSelector templates of the policies so that the
+// templates are only resolved again when a referenced ConfigMap changes. Each watch is limited to the single referenced
+// ConfigMap with a field selector.
+type selectorWatcher struct {
+	// resolvers has the ConfigurationPolicy namespace and name as the key and the values are *selectorResolver objects.
+	resolvers map[string]*selectorResolver
+	lock      sync.Mutex
+}
+
+// get returns the selectorResolver of the policy for the input namespaceSelector references. A new resolver is created
+// and the referenced ConfigMaps are watched if the policy doesn't have one for these references yet. A value is sent
+// on the evaluationTriggers channel, if set, when a watched ConfigMap changes.
+func (w *selectorWatcher) get(
+	config *rest.Config,
+	client kubernetes.Interface,
+	policyKey string,
+	refs []types.NamespacedName,
+	evaluationTriggers chan<- struct{},
+) (*selectorResolver, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.resolvers == nil {
+		w.resolvers = map[string]*selectorResolver{}
+	}
+
+	existing := w.resolvers[policyKey]
+	if existing != nil {
+		if sameSelectorRefs(existing.refs, refs) {
+			return existing, nil
+		}
+
+		existing.cancel()
+	}
+
+	// Cache the result of a missing API resource like the resolver of the object templates
+	tmplResolver, err := templates.NewResolver(
+		config, templates.Config{MissingAPIResourceCacheTTL: 10 * time.Second},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The references changed or weren't watched before, so the templates must be resolved again
+	selResolver := &selectorResolver{resolver: tmplResolver, refs: refs, cancel: cancel, changed: true}
+
+	markChanged := func(_ interface{}) {
+		selResolver.lock.Lock()
+		selResolver.changed = true
+		selResolver.lock.Unlock()
+
+		if evaluationTriggers != nil {
+			// The channel is buffered, so a pending value already wakes up the evaluation loop
+			select {
+			case evaluationTriggers <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		name := ref.Name
+		tweakListOptions := func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}
+
+		informer := coreinformers.NewFilteredConfigMapInformer(
+			client, ref.Namespace, 0, cache.Indexers{}, tweakListOptions,
+		)
+
+		_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    markChanged,
+			UpdateFunc: func(_, newObj interface{}) { markChanged(newObj) },
+			DeleteFunc: markChanged,
+		})
+		if err != nil {
+			cancel()
+
+			return nil, err
+		}
+
+		go informer.Run(ctx.Done())
+	}
+
+	w.resolvers[policyKey] = selResolver
+
+	return selResolver, nil
+}
+
+// stop stops the watches of the ConfigMaps referenced by the namespaceSelector templates of the policy and forgets its
+// template resolver.
+func (w *selectorWatcher) stop(policyKey string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if selResolver, ok := w.resolvers[policyKey]; ok {
+		selResolver.cancel()
+		delete(w.resolvers, policyKey)
+	}
+}
+
+// consumeChange returns true if the namespaceSelector templates must be resolved again and resets the changed state.
+// This is always true when the references of the templates aren't known.
+func (s *selectorResolver) consumeChange() bool {
+	if s.refs == nil {
+		return true
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	changed := s.changed
+	s.changed = false
+
+	return changed
+}
+
+// sameSelectorRefs returns true if both lists have the same references. A nil list, which means the references are
+// unknown, is only the same as another nil list.
+func sameSelectorRefs(a []types.NamespacedName, b []types.NamespacedName) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectorConfigMapRefs returns the sorted ConfigMaps referenced by the templates in the namespaceSelector. nil is
+// returned if a template uses a function other than those in selectorTemplateFuncs or calls fromConfigMap with a
+// namespace or name that isn't a literal string, since the references can't be determined without resolving it.
+func selectorConfigMapRefs(selector policyv1.Target) []types.NamespacedName {
+	selectorJSON, err := json.Marshal(selector)
+	if err != nil {
+		return nil
+	}
+
+	var selectorValues interface{}
+
+	if err := json.Unmarshal(selectorJSON, &selectorValues); err != nil {
+		return nil
+	}
+
+	refSet := map[types.NamespacedName]bool{}
+
+	if !collectSelectorRefs(selectorValues, refSet) {
+		return nil
+	}
+
+	refs := make([]types.NamespacedName, 0, len(refSet))
+
+	for ref := range refSet {
+		refs = append(refs, ref)
+	}
+
+	sort.Slice(refs, func(i, j int) bool { return refs[i].String() < refs[j].String() })
+
+	return refs
+}
+
+// collectSelectorRefs adds the ConfigMaps referenced by the templates in the input value to refSet. false is returned
+// if the references of a template can't be determined.
+func collectSelectorRefs(value interface{}, refSet map[types.NamespacedName]bool) bool {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for _, nested := range typedValue {
+			if !collectSelectorRefs(nested, refSet) {
+				return false
+			}
+		}
+	case []interface{}:
+		for _, nested := range typedValue {
+			if !collectSelectorRefs(nested, refSet) {
+				return false
+			}
+		}
+	case string:
+		if !strings.Contains(typedValue, "{{") {
+			return true
+		}
+
+		// The functions aren't known to the parser, so they're checked in collectNodeRefs instead
+		tree := parse.New("selector")
+		tree.Mode = parse.SkipFuncCheck
+		trees := map[string]*parse.Tree{}
+
+		if _, err := tree.Parse(typedValue, "{{", "}}", trees); err != nil {
+			return false
+		}
+
+		for _, parsedTree := range trees {
+			if !collectNodeRefs(parsedTree.Root, refSet) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// collectNodeRefs adds the ConfigMaps referenced by fromConfigMap calls in the parsed template node to refSet. false is
+// returned if the node uses a function that isn't in selectorTemplateFuncs or calls fromConfigMap with arguments that
+// aren't literal strings.
+func collectNodeRefs(node parse.Node, refSet map[types.NamespacedName]bool) bool {
+	switch typedNode := node.(type) {
+	case *parse.ListNode:
+		if typedNode == nil {
+			return true
+		}
+
+		for _, nested := range typedNode.Nodes {
+			if !collectNodeRefs(nested, refSet) {
+				return false
+			}
+		}
+	case *parse.ActionNode:
+		return collectNodeRefs(typedNode.Pipe, refSet)
+	case *parse.IfNode:
+		return collectBranchRefs(&typedNode.BranchNode, refSet)
+	case *parse.RangeNode:
+		return collectBranchRefs(&typedNode.BranchNode, refSet)
+	case *parse.WithNode:
+		return collectBranchRefs(&typedNode.BranchNode, refSet)
+	case *parse.PipeNode:
+		if typedNode == nil {
+			return true
+		}
+
+		for _, cmd := range typedNode.Cmds {
+			if !collectNodeRefs(cmd, refSet) {
+				return false
+			}
+		}
+	case *parse.CommandNode:
+		if ident, ok := typedNode.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "fromConfigMap" {
+			if len(typedNode.Args) < 3 {
+				return false
+			}
+
+			namespace, nsOK := typedNode.Args[1].(*parse.StringNode)
+			name, nameOK := typedNode.Args[2].(*parse.StringNode)
+
+			if !nsOK || !nameOK {
+				return false
+			}
+
+			refSet[types.NamespacedName{Namespace: namespace.Text, Name: name.Text}] = true
+		}
+
+		for _, arg := range typedNode.Args {
+			if !collectNodeRefs(arg, refSet) {
+				return false
+			}
+		}
+	case *parse.IdentifierNode:
+		return selectorTemplateFuncs[typedNode.Ident]
+	case *parse.TemplateNode:
+		return false
+	}
+
+	return true
+}
+
+// collectBranchRefs calls collectNodeRefs on the pipeline and lists of an if, range, or with node.
+func collectBranchRefs(branch *parse.BranchNode, refSet map[types.NamespacedName]bool) bool {
+	return collectNodeRefs(branch.Pipe, refSet) &&
+		collectNodeRefs(branch.List, refSet) &&
+		collectNodeRefs(branch.ElseList, refSet)
+}
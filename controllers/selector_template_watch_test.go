@@ -0,0 +1,135 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestSelectorConfigMapRefs(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		selector policyv1.Target
+		expected []types.NamespacedName
+	}{
+		"literal references": {
+			selector: policyv1.Target{
+				Include: []policyv1.NonEmptyString{
+					`{{ fromConfigMap "policies" "namespaces" "include" | lower }}`,
+					`{{ if eq (fromConfigMap "default" "toggles" "enabled") "true" }}app-*{{ end }}`,
+				},
+				Exclude: []policyv1.NonEmptyString{`{{ fromConfigMap "policies" "namespaces" "exclude" }}`},
+			},
+			expected: []types.NamespacedName{
+				{Namespace: "default", Name: "toggles"},
+				{Namespace: "policies", Name: "namespaces"},
+			},
+		},
+		"no references": {
+			selector: policyv1.Target{
+				Include: []policyv1.NonEmptyString{`{{ printf "%s-%s" "app" "1" }}`},
+			},
+			expected: []types.NamespacedName{},
+		},
+		"variable reference": {
+			selector: policyv1.Target{
+				Include: []policyv1.NonEmptyString{
+					`{{ $ns := "policies" }}{{ fromConfigMap $ns "namespaces" "include" }}`,
+				},
+			},
+			expected: nil,
+		},
+		"other cluster function": {
+			selector: policyv1.Target{
+				MatchLabels: &map[string]string{"env": `{{ fromClusterClaim "env" }}`},
+			},
+			expected: nil,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, selectorConfigMapRefs(test.selector))
+		})
+	}
+}
+
+func TestSelectorWatcherDetectsChanges(t *testing.T) {
+	t.Parallel()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespaces", Namespace: "policies"},
+		Data:       map[string]string{"include": "app-1"},
+	}
+
+	client := testclient.NewSimpleClientset(configMap)
+	triggers := make(chan struct{}, 1)
+	watcher := &selectorWatcher{}
+	refs := []types.NamespacedName{{Namespace: "policies", Name: "namespaces"}}
+
+	selResolver, err := watcher.get(&rest.Config{Host: "https://127.0.0.1"}, client, "managed/policy", refs, triggers)
+	assert.NoError(t, err)
+
+	// A new watch always requires the templates to be resolved
+	assert.True(t, selResolver.consumeChange())
+
+	// Ignore the change from the initial list
+	<-triggers
+	selResolver.consumeChange()
+
+	reused, err := watcher.get(nil, client, "managed/policy", refs, triggers)
+	assert.NoError(t, err)
+	assert.Same(t, selResolver, reused)
+	assert.False(t, selResolver.consumeChange())
+
+	updated := configMap.DeepCopy()
+
+	// The update is repeated since the watch may not be established yet after the initial list
+	assert.Eventually(t, func() bool {
+		updated.Data["include"] += ",app-2"
+
+		_, err := client.CoreV1().ConfigMaps("policies").Update(context.TODO(), updated, metav1.UpdateOptions{})
+		assert.NoError(t, err)
+
+		return len(triggers) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.True(t, selResolver.consumeChange())
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" {
+			listAction, ok := action.(clienttesting.ListAction)
+			assert.True(t, ok)
+			assert.Equal(t, "metadata.name=namespaces", listAction.GetListRestrictions().Fields.String())
+		}
+	}
+
+	// Unknown references are resolved at every evaluation loop
+	unknown, err := watcher.get(&rest.Config{Host: "https://127.0.0.1"}, client, "managed/policy", nil, triggers)
+	assert.NoError(t, err)
+	assert.NotSame(t, selResolver, unknown)
+	assert.True(t, unknown.consumeChange())
+	assert.True(t, unknown.consumeChange())
+
+	watcher.stop("managed/policy")
+
+	watcher.lock.Lock()
+	assert.Empty(t, watcher.resolvers)
+	watcher.lock.Unlock()
+}
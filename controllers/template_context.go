@@ -0,0 +1,75 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// errObjectNamespaceWithoutSelector is returned when an object template refers to .ObjectNamespace but the policy
+// doesn't have a namespaceSelector to select the namespaces to render it for.
+var errObjectNamespaceWithoutSelector = errors.New(
+	"the namespaceSelector must be set for the object templates that refer to .ObjectNamespace",
+)
+
+// templateContext is the context of the managed templates in the object templates, so that they can refer to the
+// policy and the namespace they're rendered for (e.g. {{ .PolicyName }}). Referring to any other field fails the
+// template resolution.
+type templateContext struct {
+	PolicyName      string
+	PolicyNamespace string
+	// ObjectNamespace is the namespace selected by the namespaceSelector that the object template is rendered for.
+	// An object template that uses it is rendered once per selected namespace.
+	ObjectNamespace string
+}
+
+// usesObjectNamespace determines if the object template or object-templates-raw refers to .ObjectNamespace, in which
+// case it is rendered once per namespace selected by the namespaceSelector.
+func usesObjectNamespace(rawData []byte) bool {
+	return bytes.Contains(rawData, []byte(".ObjectNamespace"))
+}
+
+// objectNamespaces returns the namespaces selected by the namespaceSelector of the policy that the object templates
+// using .ObjectNamespace are rendered for. When no namespaces are selected, no namespaces are returned so that those
+// object templates are skipped rather than rendered with an empty namespace. An error is returned when the
+// namespaceSelector isn't set, since there's no namespace to render the object templates for.
+func (r *ConfigurationPolicyReconciler) objectNamespaces(plc policyv1.ConfigurationPolicy) ([]string, error) {
+	selector := plc.Spec.NamespaceSelector
+	if selector.MatchLabels == nil && selector.MatchExpressions == nil && len(selector.Include) == 0 {
+		return nil, errObjectNamespaceWithoutSelector
+	}
+
+	return r.SelectorReconciler.Get(plc.Name, selector)
+}
+
+// withRenderedNamespace sets the namespace of a namespaced objectDefinition without a namespace to the namespace the
+// object template was rendered for, so that it's only evaluated in that namespace. The object template is returned
+// unchanged if it wasn't rendered for a namespace.
+func (r *ConfigurationPolicyReconciler) withRenderedNamespace(
+	objectT *policyv1.ObjectTemplate, namespace string,
+) *policyv1.ObjectTemplate {
+	if namespace == "" || objectT == nil {
+		return objectT
+	}
+
+	objDef, err := unmarshalFromJSON(objectT.ObjectDefinition.Raw)
+	// An invalid objectDefinition is reported when the object template is evaluated
+	if err != nil || objDef.GetNamespace() != "" || !r.isObjectNamespaced(&objDef, true) {
+		return objectT
+	}
+
+	objDef.SetNamespace(namespace)
+
+	objDefJSON, err := json.Marshal(objDef.Object)
+	if err != nil {
+		return objectT
+	}
+
+	objectT.ObjectDefinition.Raw = objDefJSON
+
+	return objectT
+}
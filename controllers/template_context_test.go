@@ -0,0 +1,94 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestUsesObjectNamespace(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, usesObjectNamespace([]byte(`{"metadata":{"labels":{"ns":"{{ .ObjectNamespace }}"}}}`)))
+	assert.False(t, usesObjectNamespace([]byte(`{"metadata":{"labels":{"policy":"{{ .PolicyName }}"}}}`)))
+}
+
+func TestObjectNamespacesWithoutSelector(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{}
+	plc := policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Spec:       &policyv1.ConfigurationPolicySpec{},
+	}
+
+	// There's no namespace to render .ObjectNamespace for
+	_, err := r.objectNamespaces(plc)
+	assert.ErrorIs(t, err, errObjectNamespaceWithoutSelector)
+}
+
+func TestObjectNamespacesNoneSelected(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{SelectorReconciler: &fakeSR{}}
+	plc := policyv1.ConfigurationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Spec: &policyv1.ConfigurationPolicySpec{
+			NamespaceSelector: policyv1.Target{Include: []policyv1.NonEmptyString{"missing"}},
+		},
+	}
+
+	// The object templates are skipped rather than rendered with an empty namespace
+	namespaces, err := r.objectNamespaces(plc)
+	assert.NoError(t, err)
+	assert.Empty(t, namespaces)
+}
+
+func TestWithRenderedNamespace(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{}
+	r.apiResourceList = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+				{Name: "namespaces", Kind: "Namespace", Namespaced: false},
+			},
+		},
+	}
+
+	newTemplate := func(objDef string) *policyv1.ObjectTemplate {
+		return &policyv1.ObjectTemplate{ObjectDefinition: runtime.RawExtension{Raw: []byte(objDef)}}
+	}
+
+	rendered := r.withRenderedNamespace(
+		newTemplate(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"}}`), "app",
+	)
+	assert.JSONEq(
+		t,
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"app"}}`,
+		string(rendered.ObjectDefinition.Raw),
+	)
+
+	// An explicit namespace is kept
+	objDef := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"other"}}`
+	rendered = r.withRenderedNamespace(newTemplate(objDef), "app")
+	assert.JSONEq(t, objDef, string(rendered.ObjectDefinition.Raw))
+
+	// Cluster scoped objects don't get a namespace
+	objDef = `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"app"}}`
+	rendered = r.withRenderedNamespace(newTemplate(objDef), "app")
+	assert.JSONEq(t, objDef, string(rendered.ObjectDefinition.Raw))
+
+	// Object templates that weren't rendered for a namespace are unchanged
+	objDef = `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"}}`
+	rendered = r.withRenderedNamespace(newTemplate(objDef), "")
+	assert.JSONEq(t, objDef, string(rendered.ObjectDefinition.Raw))
+}
@@ -0,0 +1,96 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// The classes of the template resolution errors in the config_policy_template_resolution_errors_total metric
+const (
+	templateErrorClassParse         = "parse"
+	templateErrorClassMissingObject = "missing-object"
+	templateErrorClassDeniedLookup  = "denied-lookup"
+	templateErrorClassLimitExceeded = "limit-exceeded"
+	templateErrorClassOther         = "other"
+)
+
+var (
+	// templateErrorRegex matches the location of a Go template parse or execution error (e.g.
+	// 'template: tmpl:47:12: ...'). The column is only included in execution errors.
+	templateErrorRegex = regexp.MustCompile(`(?s)template: [^:\s]*:(\d+)(?::(\d+))?: (.*)`)
+	// templateExecErrorRegex matches the failing call of a Go template execution error (e.g.
+	// 'executing "tmpl" at <fromConfigMap "x" "y" "z">: error calling fromConfigMap: ...').
+	templateExecErrorRegex = regexp.MustCompile(`(?s)^executing "[^"]*" at <(.*?)>: (?:error calling (\w+): )?(.*)$`)
+)
+
+// templateErrorMessage returns the message of a template resolution error with the location of the error and the
+// failing function call, such as "line 47, column 12: fromConfigMap: configmaps "y" not found". The line and column
+// are relative to the object-templates-raw string, where the Go template errors point to. The object templates in
+// object-templates are converted before they're resolved, so the index of the object template is used instead. The
+// error message is returned as is if it's not from a Go template.
+func templateErrorMessage(tplErr error, index int, isRawObjTemplate bool) string {
+	msg := tplErr.Error()
+
+	matches := templateErrorRegex.FindStringSubmatch(msg)
+	if matches == nil {
+		return msg
+	}
+
+	detail := matches[3]
+
+	if execMatches := templateExecErrorRegex.FindStringSubmatch(detail); execMatches != nil {
+		if execMatches[2] != "" {
+			detail = execMatches[2] + ": " + execMatches[3]
+		} else {
+			detail = fmt.Sprintf("<%s>: %s", execMatches[1], execMatches[3])
+		}
+	}
+
+	if !isRawObjTemplate {
+		return fmt.Sprintf("object-templates[%d]: %s", index, detail)
+	}
+
+	// The regular expression only matches digits
+	line, _ := strconv.Atoi(matches[1])
+	location := fmt.Sprintf("line %d", line)
+
+	if matches[2] != "" {
+		// The column of Go template errors is the zero based byte offset in the line
+		column, _ := strconv.Atoi(matches[2])
+		location += fmt.Sprintf(", column %d", column+1)
+	}
+
+	return location + ": " + detail
+}
+
+// templateErrorClass returns the class of a template resolution error for the template resolution metrics. A Go
+// template error that isn't from executing the template is a parse error, such as an unknown function. The lookup
+// errors are classified by the API status they wrap, or by their message when the template function didn't wrap it.
+func templateErrorClass(tplErr error) string {
+	if isTemplateLimitError(tplErr) {
+		return templateErrorClassLimitExceeded
+	}
+
+	msg := tplErr.Error()
+
+	if !errors.As(tplErr, &template.ExecError{}) && templateErrorRegex.MatchString(msg) {
+		return templateErrorClassParse
+	}
+
+	switch {
+	case k8serrors.IsForbidden(tplErr), strings.Contains(msg, "forbidden"), strings.Contains(msg, "is restricted"):
+		return templateErrorClassDeniedLookup
+	case k8serrors.IsNotFound(tplErr), strings.Contains(msg, "not found"):
+		return templateErrorClassMissingObject
+	default:
+		return templateErrorClassOther
+	}
+}
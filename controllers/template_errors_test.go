@@ -0,0 +1,151 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestTemplateErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	funcs := template.FuncMap{
+		"fromConfigMap": func(namespace, name, key string) (string, error) {
+			return "", fmt.Errorf(`configmaps "%s" not found`, name)
+		},
+	}
+
+	// The errors of the template resolver wrap the Go template errors
+	templateErr := func(tmplStr string) error {
+		tmpl, err := template.New("tmpl").Funcs(funcs).Parse(tmplStr)
+		if err == nil {
+			err = tmpl.Execute(io.Discard, map[string]interface{}{})
+		}
+
+		if err == nil {
+			t.Fatal("expected a template error")
+		}
+
+		return fmt.Errorf("failed to resolve the template: %w", err)
+	}
+
+	raw := "- complianceType: musthave\n" +
+		"  objectDefinition:\n" +
+		"    data:\n" +
+		`      value: '{{ fromConfigMap "x" "y" "z" }}'` + "\n"
+
+	tests := map[string]struct {
+		err      error
+		index    int
+		isRaw    bool
+		expected string
+	}{
+		"function error in object-templates-raw": {
+			err:      templateErr(raw),
+			isRaw:    true,
+			expected: `line 4, column 18: fromConfigMap: configmaps "y" not found`,
+		},
+		"parse error in object-templates-raw": {
+			err:      templateErr("- complianceType: musthave\n  objectDefinition: '{{ unknown }}'\n"),
+			isRaw:    true,
+			expected: `line 2: function "unknown" not defined`,
+		},
+		"builtin function error": {
+			err:      templateErr("data:\n  value: '{{ index .missing 1 }}'\n"),
+			isRaw:    true,
+			expected: "line 2, column 14: index: index of untyped nil",
+		},
+		"execution error without a function": {
+			err:      templateErr("data:\n  value: '{{ template \"missing\" }}'\n"),
+			isRaw:    true,
+			expected: `line 2, column 23: <{{template "missing"}}>: template "missing" not defined`,
+		},
+		"function error in object-templates": {
+			err:      templateErr(raw),
+			index:    2,
+			expected: `object-templates[2]: fromConfigMap: configmaps "y" not found`,
+		},
+		"not a Go template error": {
+			err:      errors.New("the encryption key is invalid"),
+			isRaw:    true,
+			expected: "the encryption key is invalid",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, templateErrorMessage(test.err, test.index, test.isRaw))
+		})
+	}
+}
+
+func TestTemplateErrorClass(t *testing.T) {
+	t.Parallel()
+
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+
+	funcs := template.FuncMap{
+		"notFound": func() (string, error) {
+			return "", fmt.Errorf("failed to get the ConfigMap: %w", k8serrors.NewNotFound(configMaps, "y"))
+		},
+		"forbidden": func() (string, error) {
+			return "", k8serrors.NewForbidden(configMaps, "y", errors.New("access denied"))
+		},
+		"restricted": func() (string, error) {
+			return "", errors.New("the namespace argument passed to lookup is restricted to default")
+		},
+		"missing": func() (string, error) {
+			return "", errors.New(`configmaps "y" not found`)
+		},
+	}
+
+	templateErr := func(tmplStr string) error {
+		tmpl, err := template.New("tmpl").Funcs(funcs).Parse(tmplStr)
+		if err == nil {
+			err = tmpl.Execute(io.Discard, map[string]interface{}{})
+		}
+
+		if err == nil {
+			t.Fatal("expected a template error")
+		}
+
+		return fmt.Errorf("failed to resolve the template: %w", err)
+	}
+
+	tests := map[string]struct {
+		err      error
+		expected string
+	}{
+		"parse error":               {templateErr("value: '{{ unknown }}'"), templateErrorClassParse},
+		"missing object":            {templateErr("value: '{{ notFound }}'"), templateErrorClassMissingObject},
+		"unwrapped missing object":  {templateErr("value: '{{ missing }}'"), templateErrorClassMissingObject},
+		"forbidden lookup":          {templateErr("value: '{{ forbidden }}'"), templateErrorClassDeniedLookup},
+		"restricted lookup":         {templateErr("value: '{{ restricted }}'"), templateErrorClassDeniedLookup},
+		"execution error":           {templateErr("value: '{{ index .missing 1 }}'"), templateErrorClassOther},
+		"limit exceeded":            {fmt.Errorf("%w after 1s", errTemplateTimeout), templateErrorClassLimitExceeded},
+		"not a Go template error":   {errors.New("the encryption key is invalid"), templateErrorClassOther},
+		"invalid resolved selector": {errors.New("the resolved namespaceSelector is invalid"), templateErrorClassOther},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, templateErrorClass(test.err))
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	templates "github.com/stolostron/go-template-utils/v4/pkg/templates"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	reasonTemplateLimitExceeded = "Template limit exceeded"
+	// maxAbandonedTemplateResolutions is the number of template resolutions that timed out and are still running in
+	// the background, beyond which no more templates are resolved with a timeout until some of them complete.
+	maxAbandonedTemplateResolutions = 10
+)
+
+var (
+	errTemplateTimeout        = errors.New("the template resolution timed out")
+	errTemplateOutputTooLarge = errors.New("the resolved template is too large")
+	errTemplateTimedOutBefore = errors.New(
+		"the template resolution timed out in a previous evaluation, so it isn't retried until the policy is updated",
+	)
+	errTooManyAbandonedResolutions = fmt.Errorf(
+		"the template isn't resolved since %d template resolutions that timed out are still running",
+		maxAbandonedTemplateResolutions,
+	)
+	// abandonedTemplateResolutions is the number of template resolutions that timed out and are still running.
+	abandonedTemplateResolutions atomic.Int32
+)
+
+// resolveTemplateWithLimits calls resolve and returns an error wrapping errTemplateTimeout when it takes longer than
+// the timeout or errTemplateOutputTooLarge when the resolved template is larger than maxOutputBytes. Go templates
+// can't be interrupted, so a resolution that times out keeps running in the background and its result is discarded.
+// To bound the resources used by those, errTooManyAbandonedResolutions is returned without resolving the template
+// while maxAbandonedTemplateResolutions of them are still running. The output size can only be checked once the
+// template is resolved. Zero or less disables a limit.
+func resolveTemplateWithLimits(
+	resolve func() (templates.TemplateResult, error), timeout time.Duration, maxOutputBytes int,
+) (templates.TemplateResult, error) {
+	var result templates.TemplateResult
+	var err error
+
+	if timeout > 0 {
+		if abandonedTemplateResolutions.Load() >= maxAbandonedTemplateResolutions {
+			return templates.TemplateResult{}, errTooManyAbandonedResolutions
+		}
+
+		type resolution struct {
+			result templates.TemplateResult
+			err    error
+		}
+
+		// The channel is buffered so that an abandoned resolution doesn't block forever when it completes
+		done := make(chan resolution, 1)
+
+		go func() {
+			res, resErr := resolve()
+			done <- resolution{res, resErr}
+		}()
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case res := <-done:
+			result, err = res.result, res.err
+		case <-timer.C:
+			abandonedTemplateResolutions.Add(1)
+
+			go func() {
+				<-done
+				abandonedTemplateResolutions.Add(-1)
+			}()
+
+			return templates.TemplateResult{}, fmt.Errorf("%w after the limit of %s", errTemplateTimeout, timeout)
+		}
+	} else {
+		result, err = resolve()
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	if maxOutputBytes > 0 && len(result.ResolvedJSON) > maxOutputBytes {
+		return templates.TemplateResult{}, fmt.Errorf(
+			"%w: it is %d bytes, which exceeds the limit of %d bytes",
+			errTemplateOutputTooLarge, len(result.ResolvedJSON), maxOutputBytes,
+		)
+	}
+
+	return result, nil
+}
+
+// isTemplateLimitError returns true if the error is from a template resolution that exceeded a limit of
+// resolveTemplateWithLimits.
+func isTemplateLimitError(err error) bool {
+	return errors.Is(err, errTemplateTimeout) || errors.Is(err, errTemplateOutputTooLarge) ||
+		errors.Is(err, errTemplateTimedOutBefore) || errors.Is(err, errTooManyAbandonedResolutions)
+}
+
+// templateLimitPlaceholder returns a copy of the object template whose templates exceeded a limit, which takes its
+// place in the policy so that the indexes of the other object templates are kept. The objectDefinition is emptied so
+// that the unresolved templates aren't evaluated, and the returned pointer identifies the object template since it's
+// passed through as is when the List and kind only object templates are expanded.
+func templateLimitPlaceholder(objectT *policyv1.ObjectTemplate) *policyv1.ObjectTemplate {
+	placeholder := objectT.DeepCopy()
+	placeholder.ObjectDefinition = runtime.RawExtension{Raw: []byte("{}")}
+	placeholder.KindOnly = false
+
+	return placeholder
+}
+
+// timedOutTemplates are the templates of a policy generation whose resolution timed out.
+type timedOutTemplates struct {
+	lock       sync.Mutex
+	generation int64
+	// templates has the keys of the templates from timedOutTemplateKey.
+	templates map[string]bool
+}
+
+// timedOutTemplateKey returns the key of the template rendered with the template context in timedOutTemplates. The
+// template is hashed since the object-templates-raw loaded from a reference can change without a new generation.
+func timedOutTemplateKey(rawData []byte, tmplContext templateContext) string {
+	hash := sha256.Sum256(rawData)
+
+	return hex.EncodeToString(hash[:]) + "/" + tmplContext.ObjectNamespace
+}
+
+// templateTimedOut returns whether the resolution of the template timed out in a previous evaluation of the current
+// generation of the policy.
+func (r *ConfigurationPolicyReconciler) templateTimedOut(plc *policyv1.ConfigurationPolicy, key string) bool {
+	loaded, ok := r.timedOutTemplateCache.Load(plc.Namespace + "/" + plc.Name)
+	if !ok {
+		return false
+	}
+
+	timedOut := loaded.(*timedOutTemplates)
+
+	timedOut.lock.Lock()
+	defer timedOut.lock.Unlock()
+
+	return timedOut.generation == plc.Generation && timedOut.templates[key]
+}
+
+// recordTemplateTimeout records that the resolution of the template timed out for the current generation of the
+// policy. The templates recorded for a previous generation are forgotten.
+func (r *ConfigurationPolicyReconciler) recordTemplateTimeout(plc *policyv1.ConfigurationPolicy, key string) {
+	loaded, _ := r.timedOutTemplateCache.LoadOrStore(plc.Namespace+"/"+plc.Name, &timedOutTemplates{})
+	timedOut := loaded.(*timedOutTemplates)
+
+	timedOut.lock.Lock()
+	defer timedOut.lock.Unlock()
+
+	if timedOut.generation != plc.Generation || timedOut.templates == nil {
+		timedOut.generation = plc.Generation
+		timedOut.templates = map[string]bool{}
+	}
+
+	timedOut.templates[key] = true
+}
+
+// resolveTemplate resolves the templates of an object template with the limits configured on the reconciler. The
+// template context and resolve options are passed by value since a resolution that times out keeps using them in the
+// background. A template whose resolution timed out isn't resolved again until the policy is updated, since every
+// resolution that times out keeps running in the background.
+func (r *ConfigurationPolicyReconciler) resolveTemplate(
+	plc *policyv1.ConfigurationPolicy,
+	tmplResolver *templates.TemplateResolver,
+	rawData []byte,
+	tmplContext templateContext,
+	resolveOptions templates.ResolveOptions,
+) (templates.TemplateResult, error) {
+	key := timedOutTemplateKey(rawData, tmplContext)
+
+	if r.templateTimedOut(plc, key) {
+		return templates.TemplateResult{}, errTemplateTimedOutBefore
+	}
+
+	result, err := resolveTemplateWithLimits(
+		func() (templates.TemplateResult, error) {
+			return tmplResolver.ResolveTemplate(rawData, tmplContext, &resolveOptions)
+		},
+		r.TemplateResolutionTimeout,
+		r.MaxTemplateOutputBytes,
+	)
+	if errors.Is(err, errTemplateTimeout) {
+		r.recordTemplateTimeout(plc, key)
+	}
+
+	return result, err
+}
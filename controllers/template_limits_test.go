@@ -0,0 +1,179 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	templates "github.com/stolostron/go-template-utils/v4/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestResolveTemplateWithLimits(t *testing.T) {
+	t.Parallel()
+
+	resolved := func() (templates.TemplateResult, error) {
+		return templates.TemplateResult{ResolvedJSON: []byte(`{"data":"value"}`)}, nil
+	}
+
+	tests := map[string]struct {
+		resolve        func() (templates.TemplateResult, error)
+		timeout        time.Duration
+		maxOutputBytes int
+		expectedErr    error
+		expectedMsg    string
+	}{
+		"within the limits": {
+			resolve:        resolved,
+			timeout:        time.Minute,
+			maxOutputBytes: 100,
+		},
+		"limits disabled": {
+			resolve: resolved,
+		},
+		"timed out": {
+			resolve: func() (templates.TemplateResult, error) {
+				time.Sleep(time.Second)
+
+				return resolved()
+			},
+			timeout:     10 * time.Millisecond,
+			expectedErr: errTemplateTimeout,
+			expectedMsg: "the template resolution timed out after the limit of 10ms",
+		},
+		"output too large": {
+			resolve:        resolved,
+			maxOutputBytes: 10,
+			expectedErr:    errTemplateOutputTooLarge,
+			expectedMsg:    "the resolved template is too large: it is 16 bytes, which exceeds the limit of 10 bytes",
+		},
+		"resolution error": {
+			resolve: func() (templates.TemplateResult, error) {
+				return templates.TemplateResult{}, errors.New("some error")
+			},
+			timeout:        time.Minute,
+			maxOutputBytes: 10,
+			expectedMsg:    "some error",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := resolveTemplateWithLimits(test.resolve, test.timeout, test.maxOutputBytes)
+			if test.expectedMsg == "" {
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"data":"value"}`, string(result.ResolvedJSON))
+
+				return
+			}
+
+			assert.EqualError(t, err, test.expectedMsg)
+			assert.Equal(t, test.expectedErr != nil, isTemplateLimitError(err))
+
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestTemplateLimitPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	objectT := &policyv1.ObjectTemplate{
+		ComplianceType:   policyv1.MustHave,
+		ObjectDefinition: runtime.RawExtension{Raw: []byte(`{"kind":"ConfigMap","data":"{{ .PolicyName }}"}`)},
+		KindOnly:         true,
+	}
+
+	placeholder := templateLimitPlaceholder(objectT)
+
+	assert.Equal(t, policyv1.MustHave, placeholder.ComplianceType)
+	assert.False(t, placeholder.KindOnly)
+	assert.JSONEq(t, `{}`, string(placeholder.ObjectDefinition.Raw))
+
+	// The placeholder is passed through as is so that it can be identified after the object templates are expanded
+	expanded, err := expandListTemplates([]*policyv1.ObjectTemplate{placeholder})
+	assert.NoError(t, err)
+
+	resolved := (&ConfigurationPolicyReconciler{}).resolveKindOnlyTemplates(expanded)
+	if assert.Len(t, resolved, 1) {
+		assert.Same(t, placeholder, resolved[0])
+	}
+
+	// The input object template isn't modified
+	assert.True(t, objectT.KindOnly)
+}
+
+// The test isn't parallel since it fills the package-wide limit of abandoned template resolutions.
+func TestAbandonedTemplateResolutionsLimit(t *testing.T) {
+	release := make(chan struct{})
+	blocked := func() (templates.TemplateResult, error) {
+		<-release
+
+		return templates.TemplateResult{ResolvedJSON: []byte(`{}`)}, nil
+	}
+
+	for i := 0; i < maxAbandonedTemplateResolutions; i++ {
+		_, err := resolveTemplateWithLimits(blocked, time.Millisecond, 0)
+		assert.ErrorIs(t, err, errTemplateTimeout)
+	}
+
+	// No more templates are resolved with a timeout while the abandoned resolutions are still running
+	resolved := false
+	_, err := resolveTemplateWithLimits(func() (templates.TemplateResult, error) {
+		resolved = true
+
+		return templates.TemplateResult{}, nil
+	}, time.Minute, 0)
+	assert.ErrorIs(t, err, errTooManyAbandonedResolutions)
+	assert.True(t, isTemplateLimitError(err))
+	assert.False(t, resolved)
+
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		return abandonedTemplateResolutions.Load() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = resolveTemplateWithLimits(blocked, time.Minute, 0)
+	assert.NoError(t, err)
+}
+
+func TestTemplateTimedOut(t *testing.T) {
+	t.Parallel()
+
+	r := &ConfigurationPolicyReconciler{}
+	plc := &policyv1.ConfigurationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed"}}
+	plc.Generation = 1
+
+	key := timedOutTemplateKey([]byte(`{"data":"{{ slow }}"}`), templateContext{ObjectNamespace: "default"})
+	otherNamespaceKey := timedOutTemplateKey([]byte(`{"data":"{{ slow }}"}`), templateContext{ObjectNamespace: "app"})
+
+	assert.False(t, r.templateTimedOut(plc, key))
+
+	r.recordTemplateTimeout(plc, key)
+
+	assert.True(t, r.templateTimedOut(plc, key))
+	assert.False(t, r.templateTimedOut(plc, otherNamespaceKey))
+
+	// The template is resolved again once the policy is updated
+	plc.Generation = 2
+
+	assert.False(t, r.templateTimedOut(plc, key))
+
+	r.recordTemplateTimeout(plc, otherNamespaceKey)
+
+	assert.True(t, r.templateTimedOut(plc, otherNamespaceKey))
+	assert.False(t, r.templateTimedOut(plc, key))
+}
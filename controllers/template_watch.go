@@ -0,0 +1,123 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	templates "github.com/stolostron/go-template-utils/v4/pkg/templates"
+	depclient "github.com/stolostron/kubernetes-dependency-watches/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+// disableTemplateWatchesAnnotation opts a policy out of watching the objects looked up by its templates, such as for
+// looked up objects that change constantly. The templates are then only resolved again at the next evaluation.
+const disableTemplateWatchesAnnotation = "policy.open-cluster-management.io/disable-template-watches"
+
+// TemplateWatchReconciler is the reconciler of the DynamicWatcher that watches the objects looked up by the templates
+// of the policies. It records the policies whose looked up objects changed so that they're evaluated at the next
+// evaluation loop rather than at their next evaluation interval.
+type TemplateWatchReconciler struct {
+	// When set, a value is sent on this channel when a looked up object changes so that the evaluation loop doesn't
+	// wait for the remaining update frequency before evaluating the policies again.
+	EvaluationTriggers chan<- struct{}
+	// changed has the ConfigurationPolicy namespace and name as the key of the policies whose looked up objects
+	// changed since their last evaluation.
+	changed sync.Map
+}
+
+// Reconcile records that an object looked up by the templates of the policy changed.
+func (t *TemplateWatchReconciler) Reconcile(
+	_ context.Context, watcher depclient.ObjectIdentifier,
+) (reconcile.Result, error) {
+	log.V(2).Info(
+		"An object looked up by the policy templates changed", "namespace", watcher.Namespace, "name", watcher.Name,
+	)
+
+	t.changed.Store(watcher.Namespace+"/"+watcher.Name, true)
+
+	if t.EvaluationTriggers != nil {
+		// The channel is buffered, so a pending value already wakes up the evaluation loop
+		select {
+		case t.EvaluationTriggers <- struct{}{}:
+		default:
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// consumeChange returns true if an object looked up by the templates of the policy changed since the last call and
+// resets the changed state.
+func (t *TemplateWatchReconciler) consumeChange(policyKey string) bool {
+	_, changed := t.changed.LoadAndDelete(policyKey)
+
+	return changed
+}
+
+// templateWatcherID returns the identifier of the policy as the watcher of the objects looked up by its templates.
+func templateWatcherID(namespace, name string) depclient.ObjectIdentifier {
+	return depclient.ObjectIdentifier{
+		Group:     policyv1.GroupVersion.Group,
+		Version:   policyv1.GroupVersion.Version,
+		Kind:      "ConfigurationPolicy",
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+// watchesTemplateLookups returns true if the objects looked up by the templates of the policy are watched, which is
+// the case when the controller has a DynamicWatcher for them and the policy didn't opt out with the
+// disable-template-watches annotation.
+func (r *ConfigurationPolicyReconciler) watchesTemplateLookups(policy *policyv1.ConfigurationPolicy) bool {
+	if r.DynamicWatcher == nil {
+		return false
+	}
+
+	disableAnnotation, ok := policy.GetAnnotations()[disableTemplateWatchesAnnotation]
+	if !ok {
+		return true
+	}
+
+	disabled, err := strconv.ParseBool(disableAnnotation)
+	if err != nil {
+		log.Error(err, "Could not parse value for disable-template-watches annotation", "value", disableAnnotation)
+
+		return true
+	}
+
+	return !disabled
+}
+
+// newTemplateResolver returns a template resolver for the object templates of the policy. When the looked up objects
+// are watched, the lookups are served from the cache of the DynamicWatcher, so the same object is only retrieved once
+// per evaluation.
+func (r *ConfigurationPolicyReconciler) newTemplateResolver(
+	cfg templates.Config, watchLookups bool,
+) (*templates.TemplateResolver, error) {
+	if watchLookups {
+		return templates.NewResolverWithDynamicWatcher(r.DynamicWatcher, cfg)
+	}
+
+	return templates.NewResolver(r.TargetK8sConfig, cfg)
+}
+
+// stopTemplateWatches stops watching the objects looked up by the templates of the policy.
+func (r *ConfigurationPolicyReconciler) stopTemplateWatches(namespace string, name string) {
+	if r.DynamicWatcher == nil {
+		return
+	}
+
+	if err := r.DynamicWatcher.RemoveWatcher(templateWatcherID(namespace, name)); err != nil {
+		log.Error(err, "Failed to stop watching the objects looked up by the policy templates",
+			"namespace", namespace, "name", name)
+	}
+
+	if r.TemplateWatches != nil {
+		r.TemplateWatches.consumeChange(namespace + "/" + name)
+	}
+}
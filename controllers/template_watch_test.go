@@ -0,0 +1,86 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	depclient "github.com/stolostron/kubernetes-dependency-watches/client"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestTemplateWatchReconciler(t *testing.T) {
+	t.Parallel()
+
+	triggers := make(chan struct{}, 1)
+	templateWatches := &TemplateWatchReconciler{EvaluationTriggers: triggers}
+
+	assert.False(t, templateWatches.consumeChange("managed/policy"))
+
+	_, err := templateWatches.Reconcile(context.TODO(), templateWatcherID("managed", "policy"))
+	assert.NoError(t, err)
+
+	// A second change before the evaluation loop doesn't block
+	_, err = templateWatches.Reconcile(context.TODO(), templateWatcherID("managed", "policy"))
+	assert.NoError(t, err)
+
+	assert.Len(t, triggers, 1)
+	assert.False(t, templateWatches.consumeChange("managed/other"))
+	assert.True(t, templateWatches.consumeChange("managed/policy"))
+	assert.False(t, templateWatches.consumeChange("managed/policy"))
+}
+
+func TestWatchesTemplateLookups(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		annotations map[string]string
+		expected    bool
+	}{
+		"no annotation":      {nil, true},
+		"disabled":           {map[string]string{disableTemplateWatchesAnnotation: "true"}, false},
+		"enabled":            {map[string]string{disableTemplateWatchesAnnotation: "false"}, true},
+		"invalid annotation": {map[string]string{disableTemplateWatchesAnnotation: "sometimes"}, true},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			policy := &policyv1.ConfigurationPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "managed", Annotations: test.annotations},
+			}
+
+			r := &ConfigurationPolicyReconciler{}
+			assert.False(t, r.watchesTemplateLookups(policy))
+
+			r.DynamicWatcher = &fakeDynamicWatcher{watchers: map[depclient.ObjectIdentifier]bool{}}
+			assert.Equal(t, test.expected, r.watchesTemplateLookups(policy))
+		})
+	}
+}
+
+func TestStopTemplateWatches(t *testing.T) {
+	t.Parallel()
+
+	watcher := &fakeDynamicWatcher{watchers: map[depclient.ObjectIdentifier]bool{}}
+	r := &ConfigurationPolicyReconciler{DynamicWatcher: watcher, TemplateWatches: &TemplateWatchReconciler{}}
+
+	assert.NoError(t, watcher.StartQueryBatch(templateWatcherID("managed", "policy")))
+	assert.NoError(t, watcher.StartQueryBatch(templateWatcherID("managed", "other")))
+
+	_, err := r.TemplateWatches.Reconcile(context.TODO(), templateWatcherID("managed", "policy"))
+	assert.NoError(t, err)
+
+	r.stopTemplateWatches("managed", "policy")
+
+	// A pending change of a policy that no longer watches its looked up objects is discarded
+	assert.False(t, r.TemplateWatches.consumeChange("managed/policy"))
+	assert.Equal(t, map[depclient.ObjectIdentifier]bool{templateWatcherID("managed", "other"): true}, watcher.watchers)
+}
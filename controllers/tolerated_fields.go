@@ -0,0 +1,194 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+const (
+	reasonToleratedExternalFields = "Resource found with tolerated external fields"
+	// toleratedExternalFieldsMsg is included in the message of compliant mustonlyhave objects that have fields
+	// matching the tolerateExternalFields of the object template.
+	toleratedExternalFieldsMsg = "found as specified with tolerated external fields present but ignored"
+)
+
+// parseTolerateExternalFields converts the JSON pointer paths in an object template's tolerateExternalFields to the
+// list of key patterns in each path. An error is returned if a path is not a valid JSON pointer or refers to a field
+// that identifies the object.
+func parseTolerateExternalFields(tolerateExternalFields []string) ([][]string, error) {
+	paths := make([][]string, 0, len(tolerateExternalFields))
+
+	for _, field := range tolerateExternalFields {
+		keys, err := splitJSONPointer(field, "tolerateExternalFields")
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.Join(keys, "/") {
+		case "apiVersion", "kind", "metadata", "metadata/name", "metadata/namespace":
+			return nil, fmt.Errorf(
+				"the tolerateExternalFields path %s identifies the object and can't be tolerated", field,
+			)
+		}
+
+		paths = append(paths, keys)
+	}
+
+	return paths, nil
+}
+
+// keyMatches determines if the map key matches the key pattern of a tolerateExternalFields path, where a '*' matches
+// any characters.
+func keyMatches(pattern string, key string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == key
+	}
+
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+
+	key = key[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i == -1 {
+			return false
+		}
+
+		key = key[i+len(part):]
+	}
+
+	return strings.HasSuffix(key, parts[len(parts)-1])
+}
+
+// toleratedExternalPaths returns the paths, sorted, to the fields of the existing object that match the input
+// patterns and aren't set in the desired object. These fields are excluded from the comparison and are left as is
+// when enforcing. An error is returned if a pattern reaches a list in the existing object, since the paths can only
+// traverse maps.
+func toleratedExternalPaths(existing, desired map[string]interface{}, patterns [][]string) ([][]string, error) {
+	found := map[string][]string{}
+
+	var walk func(value interface{}, path []string, pattern []string) error
+
+	walk = func(value interface{}, path []string, pattern []string) error {
+		if len(pattern) == 0 {
+			if _, set, _ := unstructured.NestedFieldNoCopy(desired, path...); !set {
+				found[strings.Join(path, "\x00")] = path
+			}
+
+			return nil
+		}
+
+		if _, isList := value.([]interface{}); isList {
+			return fmt.Errorf("reaches the list at %s", strings.Join(path, "."))
+		}
+
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		for key, child := range valueMap {
+			if keyMatches(pattern[0], key) {
+				if err := walk(child, append(append([]string{}, path...), key), pattern[1:]); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+
+	for _, pattern := range patterns {
+		if err := walk(existing, []string{}, pattern); err != nil {
+			keys := make([]string, 0, len(pattern))
+
+			for _, key := range pattern {
+				keys = append(keys, escaper.Replace(key))
+			}
+
+			return nil, fmt.Errorf(
+				"the tolerateExternalFields path /%s %v, but paths can only traverse maps", strings.Join(keys, "/"), err,
+			)
+		}
+	}
+
+	keys := make([]string, 0, len(found))
+
+	for key := range found {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	paths := make([][]string, 0, len(keys))
+
+	for _, key := range keys {
+		paths = append(paths, found[key])
+	}
+
+	return paths, nil
+}
+
+// toleratedExternalFieldsNote describes the tolerated external fields that are present on the object, such as
+// "metadata.annotations.sidecar.istio.io/inject", or returns an empty string if there are none.
+func toleratedExternalFieldsNote(paths [][]string) string {
+	descriptions := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		descriptions = append(descriptions, strings.Join(path, "."))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// toleratedExternalFieldsMessage returns the message of a compliant mustonlyhave object that has fields matching the
+// tolerateExternalFields of the object template, so that it's clear they are present but ignored. An empty string is
+// returned if the object has no such fields.
+func toleratedExternalFieldsMessage(obj singleObject, objectT *policyv1.ObjectTemplate) string {
+	if !objectT.ComplianceType.IsMustOnlyHave() || len(objectT.TolerateExternalFields) == 0 ||
+		obj.existingObj == nil {
+		return ""
+	}
+
+	// The tolerateExternalFields paths were validated before the object templates were processed
+	patterns, _ := parseTolerateExternalFields(objectT.TolerateExternalFields)
+
+	// A path that reaches a list makes the object noncompliant, so it isn't reported here
+	paths, err := toleratedExternalPaths(obj.existingObj.Object, obj.desiredObj.Object, patterns)
+	if err != nil {
+		return ""
+	}
+
+	note := toleratedExternalFieldsNote(paths)
+	if note == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"%v %v %v: %v", obj.gvr.Resource, identifierStr([]string{obj.name}, obj.namespace),
+		toleratedExternalFieldsMsg, note,
+	)
+}
+
+// withToleratedExternalFieldsNote appends a note to the diff listing the tolerated external fields that are present
+// on the object, since they are omitted from the diff.
+func withToleratedExternalFieldsNote(diff string, paths [][]string) string {
+	note := toleratedExternalFieldsNote(paths)
+	if note == "" {
+		return diff
+	}
+
+	return diff + "\n# Tolerated external fields present but ignored: " + note
+}
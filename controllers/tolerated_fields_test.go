@@ -0,0 +1,157 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
+)
+
+func TestParseTolerateExternalFields(t *testing.T) {
+	t.Parallel()
+
+	paths, err := parseTolerateExternalFields([]string{"/metadata/annotations/sidecar.istio.io~1*", "/spec/*/foo"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"metadata", "annotations", "sidecar.istio.io/*"}, {"spec", "*", "foo"}}, paths)
+
+	for _, invalid := range []string{"metadata/labels", "/metadata//labels", "/metadata/name", "/kind"} {
+		_, err := parseTolerateExternalFields([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestKeyMatches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern  string
+		key      string
+		expected bool
+	}{
+		{"foo", "foo", true},
+		{"foo", "foobar", false},
+		{"*", "anything", true},
+		{"sidecar.istio.io/*", "sidecar.istio.io/inject", true},
+		{"sidecar.istio.io/*", "istio.io/rev", false},
+		{"*.istio.io/*", "sidecar.istio.io/status", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"a*a", "a", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, keyMatches(test.pattern, test.key), test.pattern+" "+test.key)
+	}
+}
+
+func toleratedFieldsTestObjs() (existing, desired *unstructured.Unstructured) {
+	existing = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "my-deployment",
+			"namespace": "default",
+			"annotations": map[string]interface{}{
+				"sidecar.istio.io/inject": "true",
+				"sidecar.istio.io/status": "{}",
+				"owner":                   "me",
+			},
+		},
+	}}
+
+	desired = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "my-deployment",
+			"namespace": "default",
+			"annotations": map[string]interface{}{
+				"sidecar.istio.io/inject": "false",
+				"owner":                   "me",
+			},
+		},
+	}}
+
+	return existing, desired
+}
+
+func TestToleratedExternalPaths(t *testing.T) {
+	t.Parallel()
+
+	existing, desired := toleratedFieldsTestObjs()
+	patterns := [][]string{{"metadata", "annotations", "sidecar.istio.io/*"}, {"spec", "replicas"}}
+
+	// The fields set in the objectDefinition are still compared
+	paths, err := toleratedExternalPaths(existing.Object, desired.Object, patterns)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"metadata", "annotations", "sidecar.istio.io/status"}}, paths)
+
+	unstructured.RemoveNestedField(desired.Object, "metadata", "annotations")
+
+	paths, err = toleratedExternalPaths(existing.Object, desired.Object, patterns)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[][]string{
+			{"metadata", "annotations", "sidecar.istio.io/inject"},
+			{"metadata", "annotations", "sidecar.istio.io/status"},
+		},
+		paths,
+	)
+	assert.Equal(
+		t,
+		"metadata.annotations.sidecar.istio.io/inject, metadata.annotations.sidecar.istio.io/status",
+		toleratedExternalFieldsNote(paths),
+	)
+
+	// Paths can only traverse maps, so a path through a list is rejected rather than ignored
+	assert.NoError(t, unstructured.SetNestedSlice(
+		existing.Object,
+		[]interface{}{map[string]interface{}{"name": "istio-proxy", "image": "proxy"}},
+		"spec", "template", "spec", "containers",
+	))
+
+	_, err = toleratedExternalPaths(
+		existing.Object, desired.Object, [][]string{{"spec", "template", "spec", "containers", "*", "image"}},
+	)
+	assert.EqualError(
+		t,
+		err,
+		"the tolerateExternalFields path /spec/template/spec/containers/*/image reaches the list at "+
+			"spec.template.spec.containers, but paths can only traverse maps",
+	)
+}
+
+func TestToleratedExternalFieldsMessage(t *testing.T) {
+	t.Parallel()
+
+	existing, desired := toleratedFieldsTestObjs()
+	obj := singleObject{
+		gvr:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		existingObj: existing,
+		desiredObj:  *desired,
+		name:        "my-deployment",
+		namespace:   "default",
+	}
+	objectT := &policyv1.ObjectTemplate{
+		ComplianceType:         policyv1.MustOnlyHave,
+		TolerateExternalFields: []string{"/metadata/annotations/sidecar.istio.io~1*"},
+	}
+
+	assert.Equal(
+		t,
+		"deployments [my-deployment] in namespace default found as specified with tolerated external fields "+
+			"present but ignored: metadata.annotations.sidecar.istio.io/status",
+		toleratedExternalFieldsMessage(obj, objectT),
+	)
+
+	// The extra fields are already tolerated with musthave, so they aren't reported
+	objectT.ComplianceType = policyv1.MustHave
+	assert.Equal(t, "", toleratedExternalFieldsMessage(obj, objectT))
+}
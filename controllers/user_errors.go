@@ -0,0 +1,100 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types of the user errors, which are the spec errors that the policy author must fix, in the policy_user_errors
+// metric
+const (
+	userErrorInvalidTemplate         = "invalid-template"
+	userErrorNoObjectCRD             = "no-object-CRD"
+	userErrorTemplateError           = "template-error"
+	userErrorInvalidNamespaceSel     = "invalid-namespace-selector"
+	userErrorInvalidObjectDefinition = "invalid-object-definition"
+	userErrorInvalidPolicySpec       = "invalid-policy-spec"
+)
+
+// userErrorTracker counts the user errors of the policies in the policy_user_errors metric, once per error rather
+// than once per evaluation while the error persists. The errors of a policy that aren't reported again in its next
+// evaluation are forgotten, so they're counted again if they come back.
+type userErrorTracker struct {
+	// policies has the kind, namespace, and name of the policies as the key and the values are their *policyUserErrors.
+	policies sync.Map
+}
+
+// policyUserErrors are the user errors of a policy.
+type policyUserErrors struct {
+	lock sync.Mutex
+	// reported has the types of the user errors reported for the policy as the keys and the messages as the values.
+	reported map[string]string
+	// seen has the types of the user errors reported since the last complete evaluation of the policy.
+	seen map[string]bool
+}
+
+// userErrorKey returns the key of the policy in the userErrorTracker.
+func userErrorKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// record reports the user error of the type and the message in the current evaluation of the policy. The
+// policy_user_errors metric is only incremented when the policy didn't already have this error.
+func (t *userErrorTracker) record(kind string, policy metav1.Object, errType string, message string) {
+	loaded, _ := t.policies.LoadOrStore(
+		userErrorKey(kind, policy.GetNamespace(), policy.GetName()),
+		&policyUserErrors{reported: map[string]string{}, seen: map[string]bool{}},
+	)
+	errs := loaded.(*policyUserErrors)
+
+	errs.lock.Lock()
+	defer errs.lock.Unlock()
+
+	errs.seen[errType] = true
+
+	if previous, ok := errs.reported[errType]; ok && previous == message {
+		return
+	}
+
+	errs.reported[errType] = message
+
+	parent := ""
+	if ownerRefs := policy.GetOwnerReferences(); len(ownerRefs) > 0 {
+		parent = ownerRefs[0].Name
+	}
+
+	policyUserErrorsCounter.WithLabelValues(parent, policy.GetName(), kind, errType).Inc()
+}
+
+// endEvaluation forgets the user errors of the policy that weren't reported in its evaluation that just ended. It must
+// only be called after a complete evaluation, since the errors that weren't checked would otherwise be counted again.
+func (t *userErrorTracker) endEvaluation(kind string, namespace string, name string) {
+	loaded, ok := t.policies.Load(userErrorKey(kind, namespace, name))
+	if !ok {
+		return
+	}
+
+	errs := loaded.(*policyUserErrors)
+
+	errs.lock.Lock()
+	defer errs.lock.Unlock()
+
+	for errType := range errs.reported {
+		if !errs.seen[errType] {
+			delete(errs.reported, errType)
+		}
+	}
+
+	errs.seen = map[string]bool{}
+}
+
+// forget removes the deleted policy from the tracker and its series from the policy_user_errors metric.
+func (t *userErrorTracker) forget(kind string, namespace string, name string) {
+	t.policies.Delete(userErrorKey(kind, namespace, name))
+
+	_ = policyUserErrorsCounter.DeletePartialMatch(prometheus.Labels{"template": name, "kind": kind})
+}
@@ -0,0 +1,71 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1beta1 "open-cluster-management.io/config-policy-controller/api/v1beta1"
+)
+
+func TestUserErrorTracker(t *testing.T) {
+	t.Parallel()
+
+	policy := &policyv1beta1.OperatorPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "user-errors-policy",
+			Namespace:       "managed",
+			OwnerReferences: []metav1.OwnerReference{{Name: "user-errors-parent"}},
+		},
+	}
+	counter := policyUserErrorsCounter.WithLabelValues(
+		"user-errors-parent", "user-errors-policy", "OperatorPolicy", userErrorInvalidPolicySpec,
+	)
+	tracker := &userErrorTracker{}
+
+	evaluate := func(message string) {
+		if message != "" {
+			tracker.record("OperatorPolicy", policy, userErrorInvalidPolicySpec, message)
+		}
+
+		tracker.endEvaluation("OperatorPolicy", "managed", "user-errors-policy")
+	}
+
+	// The error is only counted once while it persists
+	evaluate("the operator namespace ('foo') does not exist")
+	evaluate("the operator namespace ('foo') does not exist")
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter))
+
+	// A different error of the same type is counted
+	evaluate("the operator namespace ('bar') does not exist")
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter))
+
+	// The error is counted again when it comes back after being fixed
+	evaluate("")
+	evaluate("the operator namespace ('bar') does not exist")
+	assert.Equal(t, float64(3), testutil.ToFloat64(counter))
+
+	// The series of a ConfigurationPolicy with the same name is kept when the OperatorPolicy is deleted
+	configPolicyCounter := policyUserErrorsCounter.WithLabelValues(
+		"user-errors-parent", "user-errors-policy", "ConfigurationPolicy", userErrorTemplateError,
+	)
+	configPolicyCounter.Inc()
+
+	tracker.forget("OperatorPolicy", "managed", "user-errors-policy")
+	assert.Equal(t, float64(1), testutil.ToFloat64(configPolicyCounter))
+
+	// The series is deleted with the policy, and the error is counted again when the policy is recreated
+	counter = policyUserErrorsCounter.WithLabelValues(
+		"user-errors-parent", "user-errors-policy", "OperatorPolicy", userErrorInvalidPolicySpec,
+	)
+	assert.Equal(t, float64(0), testutil.ToFloat64(counter))
+
+	evaluate("the operator namespace ('bar') does not exist")
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter))
+
+	tracker.forget("ConfigurationPolicy", "managed", "user-errors-policy")
+}
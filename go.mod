@@ -3,6 +3,8 @@ module open-cluster-management.io/config-policy-controller
 go 1.21
 
 require (
+	github.com/Masterminds/sprig/v3 v3.2.3
+	github.com/evanphx/json-patch v5.7.0+incompatible
 	github.com/ghodss/yaml v1.0.1-0.20190212211648-25d852aebe32
 	github.com/go-logr/zapr v1.2.4
 	github.com/google/go-cmp v0.6.0
@@ -17,6 +19,7 @@ require (
 	github.com/stolostron/kubernetes-dependency-watches v0.5.2
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/mod v0.13.0
+	golang.org/x/time v0.3.0
 	k8s.io/api v0.27.7
 	k8s.io/apiextensions-apiserver v0.27.7
 	k8s.io/apimachinery v0.27.7
@@ -33,13 +36,11 @@ require (
 require (
 	github.com/Masterminds/goutils v1.1.1 // indirect
 	github.com/Masterminds/semver/v3 v3.2.1 // indirect
-	github.com/Masterminds/sprig/v3 v3.2.3 // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/blang/semver/v4 v4.0.0 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/emicklei/go-restful/v3 v3.11.0 // indirect
-	github.com/evanphx/json-patch v5.7.0+incompatible // indirect
 	github.com/evanphx/json-patch/v5 v5.7.0 // indirect
 	github.com/exponent-io/jsonpath v0.0.0-20210407135951-1de76d718b3f // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
@@ -87,7 +88,6 @@ require (
 	golang.org/x/sys v0.13.0 // indirect
 	golang.org/x/term v0.13.0 // indirect
 	golang.org/x/text v0.13.0 // indirect
-	golang.org/x/time v0.3.0 // indirect
 	golang.org/x/tools v0.14.0 // indirect
 	gomodules.xyz/jsonpatch/v2 v2.4.0 // indirect
 	google.golang.org/appengine v1.6.8 // indirect
@@ -9,6 +9,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -78,21 +80,57 @@ func init() {
 }
 
 type ctrlOpts struct {
-	clusterName           string
-	hubConfigPath         string
-	targetKubeConfig      string
-	metricsAddr           string
-	probeAddr             string
-	operatorPolDefaultNS  string
-	clientQPS             float32
-	clientBurst           uint
-	frequency             uint
-	decryptionConcurrency uint8
-	evaluationConcurrency uint8
-	enableLease           bool
-	enableLeaderElection  bool
-	enableMetrics         bool
-	enableOperatorPolicy  bool
+	clusterName            string
+	hubConfigPath          string
+	targetKubeConfig       string
+	metricsAddr            string
+	probeAddr              string
+	operatorPolDefaultNS   string
+	fieldManager           string
+	maxRelatedObjects      int
+	maxStatusBytes         int
+	maxObjDefinitionBytes  int
+	maxObjTemplatesBytes   int
+	templateTimeout        time.Duration
+	maxTemplateOutputBytes int
+	diffContextLines       int
+	diffHunkPaths          bool
+	diffLogPath            string
+	diffLogMaxBytes        int64
+	diffLogMaxBackups      int
+	maxEventDiffBytes      int
+	complianceHistoryURL   string
+	complianceHistoryToken string
+	complianceHistoryCA    string
+	complianceHistoryQueue int
+	complianceEventDedup   time.Duration
+	maxEventMessageLength  int
+	complianceEvents       string
+	mutationEvents         bool
+	conflictThreshold      int
+	conflictWindow         time.Duration
+	evaluationJitter       bool
+	startupJitter          time.Duration
+	allowedNamespaces      []string
+	deniedNamespaces       []string
+	deniedKinds            []string
+	rawRefNamespaces       []string
+	ignoreProtected        bool
+	enforcementQPS         float32
+	enforcementBurst       int
+	policyEnforcementQPS   float32
+	policyEnforcementBurst int
+	clientQPS              float32
+	clientBurst            uint
+	frequency              uint
+	decryptionConcurrency  uint8
+	evaluationConcurrency  uint8
+	templateConcurrency    uint8
+	enableLease            bool
+	enableLeaderElection   bool
+	enableMetrics          bool
+	enableOperatorPolicy   bool
+	enableWebhook          bool
 }
 
 func main() {
@@ -153,6 +191,22 @@ func main() {
 		panic("The --evaluation-concurrency option cannot be less than 1")
 	}
 
+	if opts.templateConcurrency < 1 {
+		panic("The --template-evaluation-concurrency option cannot be less than 1")
+	}
+
+	namespacePatterns := append(append([]string{}, opts.allowedNamespaces...), opts.deniedNamespaces...)
+
+	for _, pattern := range append(namespacePatterns, opts.rawRefNamespaces...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("The namespace pattern %s is invalid: %v", pattern, err))
+		}
+	}
+
+	if _, err := common.MatchGroupKind(opts.deniedKinds, schema.GroupKind{}); err != nil {
+		panic(fmt.Sprintf("The --denied-kinds option is invalid: %v", err))
+	}
+
 	printVersion()
 
 	// Get a config to talk to the apiserver
@@ -315,8 +369,6 @@ func main() {
 
 	if opts.targetKubeConfig == "" {
 		targetK8sConfig = cfg
-		targetK8sClient = kubernetes.NewForConfigOrDie(targetK8sConfig)
-		targetK8sDynamicClient = dynamic.NewForConfigOrDie(targetK8sConfig)
 		nsSelMgr = mgr
 	} else { // "Hosted mode"
 		var err error
@@ -330,9 +382,6 @@ func main() {
 		targetK8sConfig.Burst = int(opts.clientBurst)
 		targetK8sConfig.QPS = opts.clientQPS
 
-		targetK8sClient = kubernetes.NewForConfigOrDie(targetK8sConfig)
-		targetK8sDynamicClient = dynamic.NewForConfigOrDie(targetK8sConfig)
-
 		// The managed cluster's API server is potentially not the same as the hosting cluster and it could be
 		// offline already as part of the uninstall process. In this case, the manager's instantiation will fail.
 		// This controller is not needed in uninstall mode, so just skip it.
@@ -356,14 +405,25 @@ func main() {
 		)
 	}
 
+	// The API requests of the ConfigurationPolicy controller on the target cluster are recorded in the metrics
+	instrumentedTargetConfig := controllers.InstrumentConfig(targetK8sConfig, "config-policy")
+	targetK8sClient = kubernetes.NewForConfigOrDie(instrumentedTargetConfig)
+	targetK8sDynamicClient = dynamic.NewForConfigOrDie(instrumentedTargetConfig)
+
 	instanceName, _ := os.Hostname() // on an error, instanceName will be empty, which is ok
 
 	var nsSelReconciler common.NamespaceSelectorReconciler
 	var dryRunSupported bool
 
+	// Buffered so that a selection change during a policy evaluation loop isn't missed
+	selectorUpdates := make(chan struct{}, 1)
+	crdUpdates := make(chan struct{}, 1)
+	crdWatcher := &common.CRDWatcher{DynamicClient: targetK8sDynamicClient, Updates: crdUpdates}
+
 	if !beingUninstalled {
 		nsSelReconciler = common.NamespaceSelectorReconciler{
-			Client: nsSelMgr.GetClient(),
+			Client:  nsSelMgr.GetClient(),
+			Updates: selectorUpdates,
 		}
 		if err = nsSelReconciler.SetupWithManager(nsSelMgr); err != nil {
 			log.Error(err, "Unable to create controller", "controller", "NamespaceSelector")
@@ -389,24 +449,117 @@ func main() {
 		}
 	}
 
-	reconciler := controllers.ConfigurationPolicyReconciler{
-		Client:                 mgr.GetClient(),
-		DecryptionConcurrency:  opts.decryptionConcurrency,
-		DryRunSupported:        dryRunSupported,
-		EvaluationConcurrency:  opts.evaluationConcurrency,
-		Scheme:                 mgr.GetScheme(),
-		Recorder:               mgr.GetEventRecorderFor(controllers.ControllerName),
-		InstanceName:           instanceName,
-		TargetK8sClient:        targetK8sClient,
-		TargetK8sDynamicClient: targetK8sDynamicClient,
-		TargetK8sConfig:        targetK8sConfig,
-		SelectorReconciler:     &nsSelReconciler,
-		EnableMetrics:          opts.enableMetrics,
-		UninstallMode:          beingUninstalled,
+	var diffLog *controllers.DiffLogWriter
+
+	if opts.diffLogPath != "" {
+		diffLog, err = controllers.NewDiffLogWriter(opts.diffLogPath, opts.diffLogMaxBytes, opts.diffLogMaxBackups)
+		if err != nil {
+			log.Error(err, "Unable to open the diff log file", "path", opts.diffLogPath)
+			os.Exit(1)
+		}
+
+		defer diffLog.Close()
+	}
+
+	var complianceHistory *controllers.ComplianceHistoryReporter
+
+	if opts.complianceHistoryURL != "" {
+		complianceHistory, err = controllers.NewComplianceHistoryReporter(
+			opts.complianceHistoryURL,
+			opts.clusterName,
+			opts.complianceHistoryToken,
+			opts.complianceHistoryCA,
+			opts.complianceHistoryQueue,
+		)
+		if err != nil {
+			log.Error(err, "Unable to configure the compliance history API reporter")
+			os.Exit(1)
+		}
+	}
+
+	complianceEvents, err := controllers.ParseComplianceEventsMode(opts.complianceEvents)
+	if err != nil {
+		log.Error(err, "Invalid --compliance-events flag")
+		os.Exit(1)
 	}
 
 	managerCtx, managerCancel := context.WithCancel(context.Background())
 
+	// Buffered so that a trigger during a policy evaluation loop isn't missed
+	evaluationTriggers := make(chan struct{}, 1)
+	templateWatches := &controllers.TemplateWatchReconciler{EvaluationTriggers: evaluationTriggers}
+
+	// The objects looked up by the policy templates are watched so that their changes cause the policies to be
+	// evaluated again. The API requests of the watches are recorded in the metrics of the ConfigurationPolicy controller.
+	templateWatcher, err := depclient.New(instrumentedTargetConfig, templateWatches,
+		&depclient.Options{DisableInitialReconcile: true, EnableCache: true})
+	if err != nil {
+		log.Error(err, "Unable to create the template dependency watcher")
+		os.Exit(1)
+	}
+
+	go func() {
+		err := templateWatcher.Start(managerCtx)
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	// Wait until the dynamic watcher has started.
+	<-templateWatcher.Started()
+
+	reconciler := controllers.ConfigurationPolicyReconciler{
+		Client:                          controllers.InstrumentClient(mgr.GetClient(), "config-policy"),
+		DecryptionConcurrency:           opts.decryptionConcurrency,
+		DryRunSupported:                 dryRunSupported,
+		EvaluationConcurrency:           opts.evaluationConcurrency,
+		TemplateEvaluationConcurrency:   opts.templateConcurrency,
+		Scheme:                          mgr.GetScheme(),
+		Recorder:                        complianceEvents.EventRecorder(mgr.GetEventRecorderFor(controllers.ControllerName)),
+		InstanceName:                    instanceName,
+		TargetK8sClient:                 targetK8sClient,
+		TargetK8sDynamicClient:          targetK8sDynamicClient,
+		TargetK8sConfig:                 targetK8sConfig,
+		SelectorReconciler:              &nsSelReconciler,
+		SelectorUpdates:                 selectorUpdates,
+		CRDWatcher:                      crdWatcher,
+		CRDUpdates:                      crdUpdates,
+		EvaluationTriggers:              evaluationTriggers,
+		DynamicWatcher:                  controllers.InstrumentDynamicWatcher(templateWatcher, "config-policy"),
+		TemplateWatches:                 templateWatches,
+		EnableMetrics:                   opts.enableMetrics,
+		RawRefAllowedNamespaces:         opts.rawRefNamespaces,
+		UninstallMode:                   beingUninstalled,
+		FieldManager:                    opts.fieldManager,
+		MaxRelatedObjectsPerTemplate:    opts.maxRelatedObjects,
+		MaxStatusBytes:                  opts.maxStatusBytes,
+		MaxObjectDefinitionBytes:        opts.maxObjDefinitionBytes,
+		MaxObjectTemplatesBytes:         opts.maxObjTemplatesBytes,
+		TemplateResolutionTimeout:       opts.templateTimeout,
+		MaxTemplateOutputBytes:          opts.maxTemplateOutputBytes,
+		DiffContextLines:                opts.diffContextLines,
+		DiffHunkPaths:                   opts.diffHunkPaths,
+		DiffLog:                         diffLog,
+		MaxEventDiffBytes:               opts.maxEventDiffBytes,
+		ComplianceHistory:               complianceHistory,
+		ComplianceEventDedupWindow:      opts.complianceEventDedup,
+		MaxComplianceEventMessageLength: opts.maxEventMessageLength,
+		ComplianceEvents:                complianceEvents,
+		MutationEvents:                  opts.mutationEvents,
+		EnforcementConflictThreshold:    opts.conflictThreshold,
+		EnforcementConflictWindow:       opts.conflictWindow,
+		EvaluationJitter:                opts.evaluationJitter,
+		StartupJitterPerPolicy:          opts.startupJitter,
+		AllowedNamespaces:               opts.allowedNamespaces,
+		DeniedNamespaces:                opts.deniedNamespaces,
+		DeniedKinds:                     opts.deniedKinds,
+		IgnoreProtectedAnnotation:       opts.ignoreProtected,
+		EnforcementQPS:                  opts.enforcementQPS,
+		EnforcementBurst:                opts.enforcementBurst,
+		PolicyEnforcementQPS:            opts.policyEnforcementQPS,
+		PolicyEnforcementBurst:          opts.policyEnforcementBurst,
+	}
+
 	if err = reconciler.SetupWithManager(mgr); err != nil {
 		log.Error(err, "Unable to create controller", "controller", "ConfigurationPolicy")
 		os.Exit(1)
@@ -415,7 +568,8 @@ func main() {
 	if opts.enableOperatorPolicy {
 		depReconciler, depEvents := depclient.NewControllerRuntimeSource()
 
-		watcher, err := depclient.New(cfg, depReconciler,
+		// The API requests of the dependency watcher are recorded in the metrics of the OperatorPolicy controller
+		watcher, err := depclient.New(controllers.InstrumentConfig(cfg, "operator-policy"), depReconciler,
 			&depclient.Options{DisableInitialReconcile: true, EnableCache: true})
 		if err != nil {
 			log.Error(err, "Unable to create dependency watcher")
@@ -433,10 +587,17 @@ func main() {
 		<-watcher.Started()
 
 		OpReconciler := controllers.OperatorPolicyReconciler{
-			Client:           mgr.GetClient(),
-			DynamicWatcher:   watcher,
-			InstanceName:     instanceName,
-			DefaultNamespace: opts.operatorPolDefaultNS,
+			Client:                          controllers.InstrumentClient(mgr.GetClient(), "operator-policy"),
+			DynamicWatcher:                  controllers.InstrumentDynamicWatcher(watcher, "operator-policy"),
+			InstanceName:                    instanceName,
+			DefaultNamespace:                opts.operatorPolDefaultNS,
+			FieldManager:                    opts.fieldManager,
+			DeniedKinds:                     opts.deniedKinds,
+			ComplianceHistory:               complianceHistory,
+			ComplianceEventDedupWindow:      opts.complianceEventDedup,
+			MaxComplianceEventMessageLength: opts.maxEventMessageLength,
+			ComplianceEvents:                complianceEvents,
+			MutationEvents:                  opts.mutationEvents,
 		}
 
 		if err = OpReconciler.SetupWithManager(mgr, depEvents); err != nil {
@@ -445,6 +606,13 @@ func main() {
 		}
 	}
 
+	if opts.enableWebhook {
+		if err = (&policyv1.ConfigurationPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			log.Error(err, "Unable to create webhook", "webhook", "ConfigurationPolicy")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -465,6 +633,19 @@ func main() {
 		managerCancel()
 	}()
 
+	if complianceHistory != nil {
+		go complianceHistory.Start(managerCtx)
+	}
+
+	if !beingUninstalled {
+		go func() {
+			// The policies waiting for a CRD otherwise fall back to being evaluated on their evaluation interval
+			if err := crdWatcher.Start(uninstallingCtx); err != nil {
+				log.Error(err, "Unable to watch the CustomResourceDefinitions on the target cluster")
+			}
+		}()
+	}
+
 	// This lease is not related to leader election. This is to report the status of the controller
 	// to the addon framework. This can be seen in the "status" section of the ManagedClusterAddOn
 	// resource objects.
@@ -672,6 +853,24 @@ func parseOpts(flags *pflag.FlagSet, args []string) *ctrlOpts {
 		"The max number of concurrent configuration policy evaluations",
 	)
 
+	flags.StringSliceVar(
+		&opts.rawRefNamespaces,
+		"raw-ref-allowed-namespaces",
+		nil,
+		"The namespace patterns (e.g. policy-templates) of the ConfigMaps and Secrets that the "+
+			"spec.objectTemplatesRawRef of a policy may reference, besides the namespace of the policy.",
+	)
+
+	flags.Uint8Var(
+		&opts.templateConcurrency,
+		"template-evaluation-concurrency",
+		// The object templates are evaluated one at a time by default since the evaluations of the policies are
+		// already concurrent.
+		1,
+		"The max number of concurrent object template evaluations within a configuration policy. The object "+
+			"templates of policies with orderedEvaluation are always evaluated one at a time.",
+	)
+
 	flags.BoolVar(
 		&opts.enableMetrics,
 		"enable-metrics",
@@ -702,6 +901,14 @@ func parseOpts(flags *pflag.FlagSet, args []string) *ctrlOpts {
 		"Enable operator policy controller",
 	)
 
+	flags.BoolVar(
+		&opts.enableWebhook,
+		"enable-webhook",
+		false,
+		"Enable the validating webhook for ConfigurationPolicy. This requires serving certificates in the "+
+			"controller-runtime default certificate directory.",
+	)
+
 	flags.StringVar(
 		&opts.operatorPolDefaultNS,
 		"operator-policy-default-namespace",
@@ -709,6 +916,268 @@ func parseOpts(flags *pflag.FlagSet, args []string) *ctrlOpts {
 		"The default namespace to be used by an OperatorPolicy if not specified in the policy.",
 	)
 
+	flags.StringVar(
+		&opts.fieldManager,
+		"field-manager",
+		controllers.DefaultFieldManager,
+		"The base field manager for the requests that enforce policies. The policy name is appended to it.",
+	)
+
+	flags.IntVar(
+		&opts.maxRelatedObjects,
+		"max-related-objects-per-template",
+		100,
+		"The maximum number of related objects per object template recorded in a ConfigurationPolicy status. "+
+			"The omitted objects are counted in a summary entry. Set to 0 to disable the limit.",
+	)
+
+	flags.IntVar(
+		&opts.maxStatusBytes,
+		"max-status-bytes",
+		512*1024,
+		"The maximum size in bytes of a ConfigurationPolicy status. The status is shortened to fit. "+
+			"Set to 0 to disable the limit.",
+	)
+
+	flags.IntVar(
+		&opts.maxObjDefinitionBytes,
+		"max-object-definition-bytes",
+		256*1024,
+		"The maximum size in bytes of a single objectDefinition in a ConfigurationPolicy. Larger object templates "+
+			"aren't evaluated and should be referenced with objectTemplatesRawRef instead. Set to 0 to disable the limit.",
+	)
+
+	flags.IntVar(
+		&opts.maxObjTemplatesBytes,
+		"max-object-templates-bytes",
+		768*1024,
+		"The maximum size in bytes of all the object templates in a ConfigurationPolicy combined. "+
+			"Set to 0 to disable the limit.",
+	)
+
+	flags.DurationVar(
+		&opts.templateTimeout,
+		"template-resolution-timeout",
+		30*time.Second,
+		"The maximum duration of the template resolution of an object template in a ConfigurationPolicy. The object "+
+			"templates that exceed it are noncompliant. Set to 0 to disable the limit.",
+	)
+
+	flags.IntVar(
+		&opts.maxTemplateOutputBytes,
+		"max-template-output-bytes",
+		1024*1024,
+		"The maximum size in bytes of the resolved templates of an object template in a ConfigurationPolicy. The "+
+			"object templates that exceed it are noncompliant. Set to 0 to disable the limit.",
+	)
+
+	flags.IntVar(
+		&opts.diffContextLines,
+		"diff-context-lines",
+		1,
+		"The number of unchanged lines shown around each change in the diffs of the objects that the policies "+
+			"record. Set to 0 to use the default of 1.",
+	)
+
+	flags.BoolVar(
+		&opts.diffHunkPaths,
+		"diff-hunk-paths",
+		false,
+		"Include the YAML path of the first changed line of each hunk in the hunk headers of the diffs of the objects "+
+			"that the policies record, such as '@@ -12,3 +12,3 @@ spec.template.spec.containers[0]'.",
+	)
+
+	flags.StringVar(
+		&opts.diffLogPath,
+		"diff-log-path",
+		"",
+		"The path of a file to write the diffs that the policies record to instead of the controller log, which "+
+			"only gets a pointer to it. Each diff is preceded by a header line with the policy and the object.",
+	)
+
+	flags.Int64Var(
+		&opts.diffLogMaxBytes,
+		"diff-log-max-bytes",
+		10*1024*1024,
+		"The maximum size in bytes of the diff log file before it's rotated. Set to 0 to disable the rotation.",
+	)
+
+	flags.IntVar(
+		&opts.diffLogMaxBackups,
+		"diff-log-max-backups",
+		3,
+		"The number of rotated diff log files to keep.",
+	)
+
+	flags.IntVar(
+		&opts.maxEventDiffBytes,
+		"max-event-diff-bytes",
+		0,
+		"The maximum size in bytes of the diffs appended to the noncompliant compliance events of the object "+
+			"templates with recordDiff set to Log. The diffs aren't appended by default.",
+	)
+
+	flags.StringVar(
+		&opts.complianceHistoryURL,
+		"compliance-history-api-url",
+		"",
+		"The HTTPS URL of the compliance history API compliance events endpoint to send the compliance events of the "+
+			"policies with the compliance history database ID annotations to. Disabled when empty.",
+	)
+
+	flags.StringVar(
+		&opts.complianceHistoryToken,
+		"compliance-history-api-token-path",
+		"",
+		"The path of the file with the bearer token for the compliance history API requests",
+	)
+
+	flags.StringVar(
+		&opts.complianceHistoryCA,
+		"compliance-history-api-ca-path",
+		"",
+		"The path of the CA certificates file to verify the compliance history API certificate with instead of the "+
+			"system CA certificates",
+	)
+
+	flags.IntVar(
+		&opts.complianceHistoryQueue,
+		"compliance-history-queue-size",
+		1024,
+		"The maximum number of compliance events waiting to be sent to the compliance history API, beyond which the "+
+			"new compliance events are dropped",
+	)
+
+	flags.DurationVar(
+		&opts.complianceEventDedup,
+		"compliance-event-dedup-window",
+		5*time.Minute,
+		"How long an exact repeat of the last compliance event of a policy isn't emitted. The compliance events are "+
+			"always emitted when the compliance or the message changes. Set to 0 to disable.",
+	)
+
+	flags.IntVar(
+		&opts.maxEventMessageLength,
+		"max-compliance-event-message-length",
+		0,
+		"The maximum length in bytes of the compliance event messages of the ConfigurationPolicies and the "+
+			"OperatorPolicies, beyond which they're truncated at a word boundary. The full messages remain in the "+
+			"policy statuses. The default of 0 disables the truncation.",
+	)
+
+	flags.StringVar(
+		&opts.complianceEvents,
+		"compliance-events",
+		string(controllers.ComplianceEventsEnabled),
+		"How the compliance events of the ConfigurationPolicies and the OperatorPolicies are emitted. Set to "+
+			"'enabled' to create Kubernetes events, 'log-only' to only write them to the controller log, or "+
+			"'disabled' to not emit them. This also applies to the other events on the policies, such as the policy "+
+			"conflict events. The compliance history API reporting isn't affected.",
+	)
+
+	flags.BoolVar(
+		&opts.mutationEvents,
+		"mutation-events",
+		false,
+		"Emit an event on each object that a ConfigurationPolicy or an OperatorPolicy creates, updates, or deletes, "+
+			"which identifies the policy",
+	)
+
+	flags.IntVar(
+		&opts.conflictThreshold,
+		"enforcement-conflict-threshold",
+		5,
+		"The number of times a policy can update the same object within the enforcement conflict window before "+
+			"another controller is assumed to be reverting the updates and they are paused. Set to 0 to disable.",
+	)
+
+	flags.DurationVar(
+		&opts.conflictWindow,
+		"enforcement-conflict-window",
+		10*time.Minute,
+		"The time window in which the updates to the same object are counted to detect enforcement conflicts.",
+	)
+
+	flags.BoolVar(
+		&opts.evaluationJitter,
+		"evaluation-jitter",
+		true,
+		"Spread the first evaluation of the policies after the controller starts and offset the evaluationInterval "+
+			"based evaluations so that they don't all happen at once. Disable it when immediate evaluation is needed, "+
+			"such as in test environments.",
+	)
+
+	flags.DurationVar(
+		&opts.startupJitter,
+		"startup-jitter-per-policy",
+		100*time.Millisecond,
+		"The duration per policy of the window that the first evaluation of the policies is spread over after the "+
+			"controller starts. For example, 2000 policies are spread over 200 seconds with the default value.",
+	)
+
+	flags.StringSliceVar(
+		&opts.allowedNamespaces,
+		"allowed-namespaces",
+		nil,
+		"The namespace patterns (e.g. team-*) in which the controller may create, update, or delete objects. When "+
+			"set, the objects in other namespaces are only evaluated as if the policies were inform.",
+	)
+
+	flags.StringSliceVar(
+		&opts.deniedNamespaces,
+		"denied-namespaces",
+		nil,
+		"The namespace patterns (e.g. kube-*) in which the controller never creates, updates, or deletes objects, "+
+			"regardless of the policies. The objects in them are only evaluated as if the policies were inform.",
+	)
+
+	flags.StringSliceVar(
+		&opts.deniedKinds,
+		"denied-kinds",
+		nil,
+		"The Kind.group patterns (e.g. Node, ClusterRoleBinding.rbac.authorization.k8s.io, "+
+			"*.admissionregistration.k8s.io) of the objects that the controller never creates, updates, or deletes, "+
+			"regardless of the policies. The objects are only evaluated as if the policies were inform.",
+	)
+
+	flags.BoolVar(
+		&opts.ignoreProtected,
+		"ignore-protected-annotation",
+		false,
+		"Delete the objects with the policy.open-cluster-management.io/protected annotation when enforcing mustnothave "+
+			"object templates. By default, the annotation blocks the deletion and the object is reported as noncompliant.",
+	)
+
+	flags.Float32Var(
+		&opts.enforcementQPS,
+		"enforcement-max-qps",
+		0,
+		"The max number of objects per second that all ConfigurationPolicies combined can create, update, or delete. "+
+			"The throttled policies continue their enforcement in the next evaluation loops. Set to 0 to disable.",
+	)
+
+	flags.IntVar(
+		&opts.enforcementBurst,
+		"enforcement-burst",
+		50,
+		"The number of objects that all ConfigurationPolicies combined can modify at once above enforcement-max-qps.",
+	)
+
+	flags.Float32Var(
+		&opts.policyEnforcementQPS,
+		"policy-enforcement-max-qps",
+		0,
+		"The max number of objects per second that each ConfigurationPolicy can create, update, or delete. "+
+			"The throttled policies continue their enforcement in the next evaluation loops. Set to 0 to disable.",
+	)
+
+	flags.IntVar(
+		&opts.policyEnforcementBurst,
+		"policy-enforcement-burst",
+		10,
+		"The number of objects that each ConfigurationPolicy can modify at once above policy-enforcement-max-qps.",
+	)
+
 	_ = flags.Parse(args)
 
 	// Scale QPS and Burst with concurrency, when they aren't explicitly set.
@@ -15,6 +15,11 @@ const (
 	UninstallingAnnotation string = "policy.open-cluster-management.io/uninstalling"
 	PolicyDBIDAnnotation   string = "policy.open-cluster-management.io/policy-compliance-db-id"
 	ParentDBIDAnnotation   string = "policy.open-cluster-management.io/parent-policy-compliance-db-id"
+	// The annotations of the compliance events with the generation of the policy that was evaluated, the compliance
+	// state it resolved to, and the type of the condition that triggered the event
+	PolicyGenerationAnnotation string = "policy.open-cluster-management.io/policy-generation"
+	ComplianceStateAnnotation  string = "policy.open-cluster-management.io/compliance-state"
+	ConditionTypeAnnotation    string = "policy.open-cluster-management.io/condition-type"
 )
 
 // CreateRecorder return recorder
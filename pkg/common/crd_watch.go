@@ -0,0 +1,236 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// CRDWatcher keeps track of the policies with an object template kind that has no API mapping because its CRD isn't
+// installed. It watches the CustomResourceDefinitions on the target cluster so that those policies can be evaluated
+// as soon as the CRD is established, rather than on their next evaluation interval.
+type CRDWatcher struct {
+	DynamicClient dynamic.Interface
+	// Updates, when set, receives a value whenever a CRD that a policy is waiting for is established, which means
+	// the cached API mappings are outdated. The sends don't block, so a buffer size of one is enough.
+	Updates chan<- struct{}
+	// waiting has the group and kind of the missing CRDs as the keys and the set of policy names waiting for them as
+	// the values.
+	waiting map[schema.GroupKind]map[string]bool
+	// established is the set of the group and kind of every established CRD on the target cluster.
+	established map[schema.GroupKind]bool
+	// installed is the set of policy names that had a CRD they were waiting for established.
+	installed map[string]bool
+	lock      sync.Mutex
+}
+
+// Start watches the CustomResourceDefinitions on the target cluster until the input context is canceled.
+func (w *CRDWatcher) Start(ctx context.Context) error {
+	w.lock.Lock()
+
+	if w.waiting == nil {
+		w.waiting = map[schema.GroupKind]map[string]bool{}
+	}
+
+	w.established = map[schema.GroupKind]bool{}
+	w.installed = map[string]bool{}
+
+	w.lock.Unlock()
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(
+		w.DynamicClient, crdGVR, "", 0, cache.Indexers{}, nil,
+	).Informer()
+
+	// Only the group, kind, and conditions are needed, and CRD schemas can be large, so don't cache the rest
+	err := informer.SetTransform(crdTransform)
+	if err != nil {
+		return err
+	}
+
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.handleCRD,
+		UpdateFunc: func(_, obj interface{}) { w.handleCRD(obj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			if crd, ok := obj.(*unstructured.Unstructured); ok {
+				w.lock.Lock()
+				delete(w.established, crdGroupKind(crd))
+				w.lock.Unlock()
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Starting the CustomResourceDefinition watch")
+
+	informer.Run(ctx.Done())
+
+	return nil
+}
+
+// Wait records that the policy has an object template with the input group and kind that has no API mapping. If the
+// CRD is already established, the cached API mappings are outdated, so the policy is marked as having its CRD
+// installed right away.
+func (w *CRDWatcher) Wait(name string, groupKind schema.GroupKind) {
+	w.lock.Lock()
+
+	if w.waiting == nil {
+		w.waiting = map[schema.GroupKind]map[string]bool{}
+	}
+
+	if w.established[groupKind] {
+		w.installed[name] = true
+
+		w.lock.Unlock()
+		w.notify()
+
+		return
+	}
+
+	if w.waiting[groupKind] == nil {
+		w.waiting[groupKind] = map[string]bool{}
+	}
+
+	w.waiting[groupKind][name] = true
+
+	w.lock.Unlock()
+}
+
+// Installed indicates whether a CRD that the policy was waiting for was established since the last time Installed was
+// called for the policy.
+func (w *CRDWatcher) Installed(name string) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	installed := w.installed[name]
+	delete(w.installed, name)
+
+	return installed
+}
+
+// Stop tells the CRDWatcher to stop tracking the CRDs that the policy is waiting for.
+func (w *CRDWatcher) Stop(name string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for groupKind, names := range w.waiting {
+		delete(names, name)
+
+		if len(names) == 0 {
+			delete(w.waiting, groupKind)
+		}
+	}
+
+	delete(w.installed, name)
+}
+
+// handleCRD marks the policies waiting for the CRD as having it installed once the CRD is established.
+func (w *CRDWatcher) handleCRD(obj interface{}) {
+	crd, ok := obj.(*unstructured.Unstructured)
+	if !ok || !crdEstablished(crd) {
+		return
+	}
+
+	groupKind := crdGroupKind(crd)
+
+	w.lock.Lock()
+
+	w.established[groupKind] = true
+	names := w.waiting[groupKind]
+	delete(w.waiting, groupKind)
+
+	for name := range names {
+		w.installed[name] = true
+	}
+
+	w.lock.Unlock()
+
+	if len(names) == 0 {
+		return
+	}
+
+	log.V(1).Info(
+		"A CRD that policies were waiting for was established", "group", groupKind.Group, "kind", groupKind.Kind,
+	)
+
+	w.notify()
+}
+
+func (w *CRDWatcher) notify() {
+	if w.Updates == nil {
+		return
+	}
+
+	select {
+	case w.Updates <- struct{}{}:
+	default:
+		// A notification is already pending
+	}
+}
+
+// crdTransform removes all the fields of a CRD except for the ones used by the CRDWatcher.
+func crdTransform(obj interface{}) (interface{}, error) {
+	crd, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj, nil
+	}
+
+	trimmed := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	trimmed.SetAPIVersion(crd.GetAPIVersion())
+	trimmed.SetKind(crd.GetKind())
+	trimmed.SetName(crd.GetName())
+	trimmed.SetResourceVersion(crd.GetResourceVersion())
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+
+	_ = unstructured.SetNestedField(trimmed.Object, group, "spec", "group")
+	_ = unstructured.SetNestedField(trimmed.Object, kind, "spec", "names", "kind")
+	_ = unstructured.SetNestedSlice(trimmed.Object, conditions, "status", "conditions")
+
+	return trimmed, nil
+}
+
+func crdGroupKind(crd *unstructured.Unstructured) schema.GroupKind {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+
+	return schema.GroupKind{Group: group, Kind: kind}
+}
+
+// crdEstablished returns whether the CRD has the Established condition set to True, which is when its API is served.
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func testCRD(group string, kind string, established bool) *unstructured.Unstructured {
+	status := "False"
+	if established {
+		status = "True"
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "widgets." + group},
+		"spec": map[string]interface{}{
+			"group": group,
+			"names": map[string]interface{}{"kind": kind, "plural": "widgets"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1", "schema": map[string]interface{}{"openAPIV3Schema": "large"}},
+			},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+				map[string]interface{}{"type": "Established", "status": status},
+			},
+		},
+	}}
+}
+
+func TestCRDWatcher(t *testing.T) {
+	t.Parallel()
+
+	updates := make(chan struct{}, 1)
+	watcher := &CRDWatcher{
+		Updates:     updates,
+		waiting:     map[schema.GroupKind]map[string]bool{},
+		established: map[schema.GroupKind]bool{},
+		installed:   map[string]bool{},
+	}
+	widget := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+
+	watcher.Wait("policy1", widget)
+	watcher.Wait("policy2", widget)
+	watcher.Wait("policy3", widget)
+	watcher.Stop("policy3")
+
+	// The CRD isn't served until it's established
+	watcher.handleCRD(testCRD("example.com", "Widget", false))
+	assert.False(t, watcher.Installed("policy1"))
+	assert.Len(t, updates, 0)
+
+	watcher.handleCRD(testCRD("example.com", "Widget", true))
+	assert.Len(t, updates, 1)
+	assert.True(t, watcher.Installed("policy1"))
+	assert.True(t, watcher.Installed("policy2"))
+	assert.False(t, watcher.Installed("policy3"))
+
+	// Installed is reset once it's read
+	assert.False(t, watcher.Installed("policy1"))
+
+	<-updates
+
+	// Waiting on an established CRD means the cached API mappings are outdated, so it's installed right away
+	watcher.Wait("policy4", widget)
+	assert.Len(t, updates, 1)
+	assert.True(t, watcher.Installed("policy4"))
+	assert.Empty(t, watcher.waiting)
+}
+
+func TestCRDTransform(t *testing.T) {
+	t.Parallel()
+
+	trimmed, err := crdTransform(testCRD("example.com", "Widget", true))
+	assert.NoError(t, err)
+
+	crd, ok := trimmed.(*unstructured.Unstructured)
+	assert.True(t, ok)
+	assert.Equal(t, schema.GroupKind{Group: "example.com", Kind: "Widget"}, crdGroupKind(crd))
+	assert.True(t, crdEstablished(crd))
+	assert.Equal(t, "widgets.example.com", crd.GetName())
+
+	_, found, _ := unstructured.NestedFieldNoCopy(crd.Object, "spec", "versions")
+	assert.False(t, found)
+}
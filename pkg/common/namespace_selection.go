@@ -6,11 +6,11 @@ package common
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"sort"
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
@@ -18,11 +18,8 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 )
@@ -134,7 +131,11 @@ type SelectorReconciler interface {
 }
 
 type NamespaceSelectorReconciler struct {
-	Client     client.Client
+	Client client.Client
+	// Updates, when set, receives a value whenever a cached selection changes so that the affected policies can be
+	// evaluated without waiting for the next evaluation loop. The sends don't block, so a buffer size of one is
+	// enough.
+	Updates    chan<- struct{}
 	selections map[string]namespaceSelection
 	lock       sync.RWMutex
 }
@@ -150,86 +151,101 @@ type namespaceSelection struct {
 func (r *NamespaceSelectorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.selections = make(map[string]namespaceSelection)
 
-	neverEnqueue := predicate.NewPredicateFuncs(func(o client.Object) bool { return false })
-
-	// Instead of reconciling for each Namespace, just reconcile once
-	// - that reconcile will do a list on all the Namespaces anyway.
-	mapToSingleton := func(_ client.Object) []reconcile.Request {
-		return []reconcile.Request{{NamespacedName: types.NamespacedName{
-			Name: "NamespaceSelector",
-		}}}
-	}
-
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("NamespaceSelector").
-		For( // This is a workaround because a `For` is required, but doesn't allow the enqueueing to be customized
+		For(
 			&corev1.Namespace{},
-			builder.WithPredicates(neverEnqueue)).
-		Watches(
-			&source.Kind{Type: &corev1.Namespace{}},
-			handler.EnqueueRequestsFromMapFunc(mapToSingleton),
 			builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Complete(r)
 }
 
 // Reconcile runs whenever a namespace on the target cluster is created, deleted, or has a change in
-// labels. It updates the cached selections for NamespaceSelectors that it knows about.
-func (r *NamespaceSelectorReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
-	log := logf.Log.WithValues("Reconciler", "NamespaceSelector")
-	oldSelections := make(map[string]namespaceSelection)
+// labels. It updates the cached selections for NamespaceSelectors that it knows about. Only the
+// namespace in the request is evaluated against each selection, so the cost of a namespace event
+// doesn't depend on the number of namespaces on the cluster.
+func (r *NamespaceSelectorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.Log.WithValues("Reconciler", "NamespaceSelector", "namespace", req.Name)
 
-	r.lock.RLock()
-
-	for name, selection := range r.selections {
-		oldSelections[name] = selection
-	}
+	namespaces := corev1.NamespaceList{}
+	namespace := corev1.Namespace{}
 
-	r.lock.RUnlock()
+	// This Get will be from the cache
+	err := r.Client.Get(ctx, types.NamespacedName{Name: req.Name}, &namespace)
+	if err == nil {
+		namespaces.Items = []corev1.Namespace{namespace}
+	} else if !k8serrors.IsNotFound(err) {
+		log.Error(err, "Unable to get the namespace from the cache")
 
-	// No selections to populate, just skip.
-	if len(r.selections) == 0 {
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, err
 	}
 
-	namespaces := corev1.NamespaceList{}
+	updated := false
 
-	// This List will be from the cache
-	if err := r.Client.List(ctx, &namespaces); err != nil {
-		log.Error(err, "Unable to list namespaces from the cache")
+	r.lock.Lock()
 
-		return ctrl.Result{}, err
-	}
+	for name, selection := range r.selections {
+		// A selection with an error is recalculated by Get when its target changes
+		if selection.err != nil {
+			continue
+		}
 
-	for name, oldSelection := range oldSelections {
-		newNamespaces, err := filter(namespaces, oldSelection.target)
+		matched, err := filter(namespaces, selection.target)
 		if err != nil {
-			log.Error(err, "Unable to filter namespaces for policy", "name", name)
+			log.Error(err, "Unable to filter the namespace for policy", "name", name)
 
-			r.update(name, namespaceSelection{
-				target:     oldSelection.target,
-				namespaces: newNamespaces,
-				hasUpdate:  oldSelection.err == nil, // it has an update if the error state changed
-				err:        err,
-			})
+			continue
+		}
 
+		newNamespaces, changed := updateSelected(selection.namespaces, req.Name, len(matched) != 0)
+		if !changed {
 			continue
 		}
 
-		if !reflect.DeepEqual(newNamespaces, oldSelection.namespaces) {
-			log.V(2).Info("Updating selection from Reconcile", "policy", name, "selection", newNamespaces)
+		log.V(2).Info("Updating selection from Reconcile", "policy", name, "selection", newNamespaces)
+
+		selection.namespaces = newNamespaces
+		selection.hasUpdate = true
+		r.selections[name] = selection
+		updated = true
+	}
+
+	r.lock.Unlock()
 
-			r.update(name, namespaceSelection{
-				target:     oldSelection.target,
-				namespaces: newNamespaces,
-				hasUpdate:  true,
-				err:        nil,
-			})
+	if updated && r.Updates != nil {
+		select {
+		case r.Updates <- struct{}{}:
+		default:
+			// A notification is already pending
 		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// updateSelected adds or removes the namespace in the sorted list of selected namespaces based on
+// whether it is selected. A new slice is returned since the input slice may have been returned by
+// Get. The returned boolean indicates whether the selection changed.
+func updateSelected(selected []string, namespace string, isSelected bool) ([]string, bool) {
+	idx := sort.SearchStrings(selected, namespace)
+	found := idx < len(selected) && selected[idx] == namespace
+
+	if found == isSelected {
+		return selected, false
+	}
+
+	newSelected := make([]string, 0, len(selected)+1)
+	newSelected = append(newSelected, selected[:idx]...)
+
+	if isSelected {
+		newSelected = append(newSelected, namespace)
+		newSelected = append(newSelected, selected[idx:]...)
+	} else {
+		newSelected = append(newSelected, selected[idx+1:]...)
+	}
+
+	return newSelected, true
+}
+
 // Get returns the items matching the given Target for the given policy. If no selection for that
 // policy and Target has been calculated, it will be calculated now. Otherwise, a cached value
 // may be used.
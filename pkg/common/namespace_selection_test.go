@@ -11,7 +11,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	testclient "k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 )
@@ -342,3 +345,62 @@ func TestGetAllNamespaces(t *testing.T) {
 		)
 	}
 }
+
+func TestNamespaceSelectorReconcile(t *testing.T) {
+	t.Parallel()
+
+	prodLabels := map[string]string{"env": "prod"}
+	fakeClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-a", Labels: prodLabels}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-c", Labels: prodLabels}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+	).Build()
+
+	updates := make(chan struct{}, 1)
+	r := &NamespaceSelectorReconciler{
+		Client:     fakeClient,
+		Updates:    updates,
+		selections: map[string]namespaceSelection{},
+	}
+
+	target := policyv1.Target{MatchLabels: &prodLabels, Include: []policyv1.NonEmptyString{"*"}}
+
+	selected, err := r.Get("policy", target)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod-a", "prod-c"}, selected)
+
+	reconcileNamespace := func(name string) {
+		_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+		assert.NoError(t, err)
+	}
+
+	// A namespace that doesn't match doesn't change the selection
+	reconcileNamespace("dev")
+	assert.False(t, r.HasUpdate("policy"))
+	assert.Len(t, updates, 0)
+
+	// A new matching namespace is added in order
+	err = fakeClient.Create(
+		context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-b", Labels: prodLabels}},
+	)
+	assert.NoError(t, err)
+
+	reconcileNamespace("prod-b")
+	assert.True(t, r.HasUpdate("policy"))
+	assert.Len(t, updates, 1)
+
+	selected, err = r.Get("policy", target)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod-a", "prod-b", "prod-c"}, selected)
+
+	// A deleted namespace is removed
+	err = fakeClient.Delete(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-a"}})
+	assert.NoError(t, err)
+
+	reconcileNamespace("prod-a")
+	assert.True(t, r.HasUpdate("policy"))
+
+	selected, err = r.Get("policy", target)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod-b", "prod-c"}, selected)
+}
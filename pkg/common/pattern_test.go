@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 )
@@ -96,3 +97,30 @@ func TestMatches(t *testing.T) {
 		)
 	}
 }
+
+func TestMatchGroupKind(t *testing.T) {
+	t.Parallel()
+
+	patterns := []string{"Node", "ClusterRoleBinding.rbac.authorization.k8s.io", "*.admissionregistration.k8s.io"}
+
+	tests := []struct {
+		groupKind schema.GroupKind
+		expected  string
+	}{
+		{schema.GroupKind{Kind: "Node"}, "Node"},
+		{schema.GroupKind{Group: "example.com", Kind: "Node"}, ""},
+		{schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}, patterns[1]},
+		{schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"}, ""},
+		{schema.GroupKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}, patterns[2]},
+		{schema.GroupKind{Kind: "ConfigMap"}, ""},
+	}
+
+	for _, test := range tests {
+		pattern, err := MatchGroupKind(patterns, test.groupKind)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, pattern, test.groupKind.String())
+	}
+
+	_, err := MatchGroupKind([]string{"Node.[a"}, schema.GroupKind{})
+	assert.ErrorContains(t, err, "error parsing the kind pattern 'Node.[a'")
+}
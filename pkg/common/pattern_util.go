@@ -6,6 +6,9 @@ package common
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	policyv1 "open-cluster-management.io/config-policy-controller/api/v1"
 )
@@ -65,3 +68,29 @@ func Matches(
 
 	return matchingNamespaces, nil
 }
+
+// MatchGroupKind returns the first pattern that the group and kind match, or an empty string if none match. The
+// patterns are in the Kind.group format (e.g. ClusterRoleBinding.rbac.authorization.k8s.io), where both parts use the
+// filepath.Match syntax. A pattern without a group only matches the core group, so "*.*" is needed to match every
+// group. An error is returned if a pattern is malformed.
+func MatchGroupKind(patterns []string, groupKind schema.GroupKind) (string, error) {
+	for _, pattern := range patterns {
+		kindPattern, groupPattern, _ := strings.Cut(pattern, ".")
+
+		kindMatched, err := filepath.Match(kindPattern, groupKind.Kind)
+		if err != nil {
+			return "", fmt.Errorf("error parsing the kind pattern '%s': %w", pattern, err)
+		}
+
+		groupMatched, err := filepath.Match(groupPattern, groupKind.Group)
+		if err != nil {
+			return "", fmt.Errorf("error parsing the kind pattern '%s': %w", pattern, err)
+		}
+
+		if kindMatched && groupMatched {
+			return pattern, nil
+		}
+	}
+
+	return "", nil
+}
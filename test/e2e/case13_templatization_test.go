@@ -353,6 +353,14 @@ var _ = Describe("Test templatization", Ordered, func() {
 				}
 				By("Policy " + case13UpdateRefObject + " total template process seconds : " + templatesTotalSeconds[0])
 
+				By("Checking metric endpoint for the template resolution duration of policy " + case13UpdateRefObject)
+				Eventually(func() interface{} {
+					return utils.GetMetrics(
+						"config_policy_template_resolution_duration_seconds_count",
+						fmt.Sprintf(`name=\"%s\"`, case13UpdateRefObject),
+					)
+				}, defaultTimeoutSeconds, 1).Should(Not(BeNil()))
+
 				By("Updating the referenced ConfigMap")
 				configMap.Data["message"] = "Hello world!"
 				_, err = clientManaged.CoreV1().ConfigMaps("default").Update(
@@ -81,6 +81,15 @@ var _ = Describe("Testing compliance event formatting", Ordered, func() {
 				g.Expect(event.Annotations[common.PolicyDBIDAnnotation]).To(
 					Equal("30"), common.PolicyDBIDAnnotation+" should have the correct value",
 				)
+				g.Expect(event.Annotations[common.PolicyGenerationAnnotation]).To(
+					Equal("1"), common.PolicyGenerationAnnotation+" should have the correct value",
+				)
+				g.Expect(event.Annotations[common.ComplianceStateAnnotation]).To(
+					Equal("Compliant"), common.ComplianceStateAnnotation+" should have the correct value",
+				)
+				g.Expect(event.Annotations[common.ConditionTypeAnnotation]).To(
+					Equal("Compliant"), common.ConditionTypeAnnotation+" should have the correct value",
+				)
 			}
 		}, defaultTimeoutSeconds, 1).Should(Succeed())
 
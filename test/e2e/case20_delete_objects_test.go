@@ -98,8 +98,9 @@ var _ = Describe("Test Object deletion", Ordered, func() {
 				relatedObj := managedPlc.Object["status"].(map[string]interface{})["relatedObjects"].([]interface{})[0]
 				properties := relatedObj.(map[string]interface{})["properties"].(map[string]interface{})
 
+				// The UID of the evaluated object is recorded even though the policy didn't create it
 				return properties["uid"]
-			}, defaultTimeoutSeconds, 1).Should(BeNil())
+			}, defaultTimeoutSeconds, 1).ShouldNot(BeNil())
 		})
 		It("should update status fields properly for edited objects", func() {
 			By("Creating " + case20ConfigPolicyNameEdit + " on managed")
@@ -127,8 +128,9 @@ var _ = Describe("Test Object deletion", Ordered, func() {
 				relatedObj := managedPlc.Object["status"].(map[string]interface{})["relatedObjects"].([]interface{})[0]
 				properties := relatedObj.(map[string]interface{})["properties"].(map[string]interface{})
 
+				// The UID of the evaluated object is recorded even though the policy didn't create it
 				return properties["uid"]
-			}, defaultTimeoutSeconds, 1).Should(BeNil())
+			}, defaultTimeoutSeconds, 1).ShouldNot(BeNil())
 		})
 		It("should not update status field for inform policies", func() {
 			By("Creating " + case20ConfigPolicyNameInform + " on managed")
@@ -146,9 +148,9 @@ var _ = Describe("Test Object deletion", Ordered, func() {
 				managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
 					case20ConfigPolicyNameInform, testNamespace, true, defaultTimeoutSeconds)
 				relatedObj := managedPlc.Object["status"].(map[string]interface{})["relatedObjects"].([]interface{})[0]
-				properties := relatedObj.(map[string]interface{})["properties"]
+				properties := relatedObj.(map[string]interface{})["properties"].(map[string]interface{})
 
-				return properties
+				return properties["createdByPolicy"]
 			}, defaultTimeoutSeconds, 1).Should(BeNil())
 		})
 		AfterAll(func() {
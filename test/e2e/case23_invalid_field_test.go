@@ -140,7 +140,21 @@ var _ = Describe("Test an objectDefinition with a missing status field that shou
 		policyYAML = "../resources/case23_invalid_field/policy-ignore-status-field.yaml"
 	)
 
-	It("Still enforces when a status is provided but is missing a required field", func() {
+	It("Still evaluates when a status is provided but is missing a required field", func() {
+		By("Creating the " + podName + " pod")
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "nginx",
+					Image: "nginx:1.7.9",
+					Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+				}},
+			},
+		}
+		_, err := clientManaged.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
 		By("Creating the " + policyName + " policy")
 		utils.Kubectl("apply", "-f", policyYAML, "-n", testNamespace)
 
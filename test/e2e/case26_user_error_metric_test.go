@@ -55,5 +55,15 @@ var _ = Describe("Test related object metrics", Ordered, func() {
 		}, defaultTimeoutSeconds, 1).Should(Equal([]string{"1"}))
 	})
 
+	It("should not count the same user error again while it persists", func() {
+		By("Checking that the user error metric isn't incremented by the following evaluations")
+		Consistently(func() interface{} {
+			return utils.GetMetrics(
+				"policy_user_errors",
+				fmt.Sprintf(`template=\"%s\"`, policy1Name),
+			)
+		}, 15, 1).Should(Equal([]string{"1"}))
+	})
+
 	AfterAll(cleanup)
 })
@@ -93,6 +93,32 @@ var _ = Describe("Test config policy evaluation metrics", Ordered, func() {
 		}, defaultTimeoutSeconds, 1).Should(Equal(true))
 	})
 
+	It("should report the evaluation duration histogram for the configurationpolicy", func() {
+		By("Checking metric endpoint for the evaluation duration histogram")
+		Eventually(func() interface{} {
+			metric := utils.GetMetrics(
+				"config_policy_evaluation_duration_seconds_count", fmt.Sprintf(`name=\"%s\"`, policyName))
+			if len(metric) == 0 {
+				return false
+			}
+			numEvals, err := strconv.Atoi(metric[0])
+			if err != nil {
+				return false
+			}
+
+			return numEvals > 0
+		}, defaultTimeoutSeconds, 1).Should(Equal(true))
+	})
+
+	It("should report the evaluations by compliance result for the configurationpolicy", func() {
+		By("Checking metric endpoint for the evaluations with a result")
+		Eventually(func() interface{} {
+			return len(utils.GetMetrics(
+				"config_policy_evaluation_results_total", fmt.Sprintf(`name=\"%s\"`, policyName), `result=\"`,
+			)) != 0
+		}, defaultTimeoutSeconds, 1).Should(Equal(true))
+	})
+
 	cleanup := func() {
 		// Delete the policies and ignore any errors (in case it was deleted previously)
 		cmd := exec.Command("kubectl", "delete",
@@ -112,6 +138,14 @@ var _ = Describe("Test config policy evaluation metrics", Ordered, func() {
 			return utils.GetMetrics(
 				"config_policy_evaluation_seconds_total", fmt.Sprintf(`name=\"%s\"`, policyName))
 		}, defaultTimeoutSeconds, 1).Should(Equal([]string{}))
+		Eventually(func() interface{} {
+			return utils.GetMetrics(
+				"config_policy_evaluation_duration_seconds", fmt.Sprintf(`name=\"%s\"`, policyName))
+		}, defaultTimeoutSeconds, 1).Should(Equal([]string{}))
+		Eventually(func() interface{} {
+			return utils.GetMetrics(
+				"config_policy_evaluation_results_total", fmt.Sprintf(`name=\"%s\"`, policyName))
+		}, defaultTimeoutSeconds, 1).Should(Equal([]string{}))
 	}
 
 	AfterAll(cleanup)
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"open-cluster-management.io/config-policy-controller/pkg/common"
 	"open-cluster-management.io/config-policy-controller/test/utils"
 )
 
@@ -28,37 +29,38 @@ var _ = Describe("Test compliance events of enforced policies that define a stat
 		By("Setting up the policy")
 		createObjWithParent(policyYAML, policyName, cfgPlcYAML, testNamespace, gvrPolicy, gvrConfigPolicy)
 
-		By("Checking there is a NonCompliant event on the policy")
+		By("Checking there is a NonCompliant event on the policy for the invalid spec")
 		Eventually(func() interface{} {
-			return utils.GetMatchingEvents(clientManaged, testNamespace,
-				policyName, cfgPlcName, "^NonCompliant;", defaultTimeoutSeconds)
+			return utils.GetMatchingEvents(clientManaged, testNamespace, policyName, cfgPlcName,
+				"^NonCompliant;.*the status can only be compared with the inform remediationAction",
+				defaultTimeoutSeconds)
 		}, defaultTimeoutSeconds, 5).ShouldNot(BeEmpty())
 
+		By("Checking the nested policy isn't created")
+		nestedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+			nestedPlcName, testNamespace, false, defaultTimeoutSeconds)
+		Expect(nestedPlc).To(BeNil())
+
 		By("Checking there are no Compliant events on the policy")
 		Consistently(func() interface{} {
 			return utils.GetMatchingEvents(clientManaged, testNamespace,
 				policyName, cfgPlcName, "^Compliant;", defaultTimeoutSeconds)
 		}, defaultConsistentlyDuration, 5).Should(BeEmpty())
 
-		By("Updating the policy")
+		By("Updating the policy to inform")
 		utils.Kubectl("apply", "-f", updatedCfgPlc, "-n", testNamespace)
 
-		By("Checking there are no Compliant events created during the update flow")
-		Consistently(func() interface{} {
-			return utils.GetMatchingEvents(clientManaged, testNamespace,
-				policyName, cfgPlcName, "^Compliant;", defaultTimeoutSeconds)
-		}, defaultConsistentlyDuration, 5).Should(BeEmpty())
-
-		By("Updating the nested policy to increment its generation")
+		By("Creating the nested policy")
 		utils.Kubectl("apply", "-f", nestedPlcYAML, "-n", testNamespace)
-		nestedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
-			nestedPlcName, testNamespace, true, defaultTimeoutSeconds)
-		Expect(nestedPlc.GetGeneration()).To(BeNumerically("==", 3))
 
-		By("Checking there is now a Compliant event on the policy")
+		By("Checking there is now a Compliant event on the policy for the updated generation")
+		cfgPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+			cfgPlcName, testNamespace, true, defaultTimeoutSeconds)
 		Eventually(func() interface{} {
-			return utils.GetMatchingEvents(clientManaged, testNamespace,
+			events := utils.GetMatchingEvents(clientManaged, testNamespace,
 				policyName, cfgPlcName, "^Compliant;", defaultTimeoutSeconds)
+
+			return utils.FilterEventsByGeneration(events, cfgPlc.GetGeneration())
 		}, defaultTimeoutSeconds, 5).ShouldNot(BeEmpty())
 	})
 
@@ -77,6 +79,7 @@ var _ = Describe("Test compliance events of enforced policies that define a stat
 				GinkgoWriter.Println("LastTimestamp:", ev.LastTimestamp)
 				GinkgoWriter.Println("Count:", ev.Count)
 				GinkgoWriter.Println("Type:", ev.Type)
+				GinkgoWriter.Println("Generation:", ev.Annotations[common.PolicyGenerationAnnotation])
 				GinkgoWriter.Println("---")
 			}
 		}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -102,6 +103,17 @@ var _ = Describe("Test installing an operator from OperatorPolicy", Ordered, fun
 				g.Expect(event.Annotations[common.PolicyDBIDAnnotation]).To(
 					Equal("64"), common.PolicyDBIDAnnotation+" should have the correct value",
 				)
+
+				g.Expect(event.Annotations).To(HaveKey(common.PolicyGenerationAnnotation))
+				g.Expect(event.Annotations).To(HaveKey(common.ConditionTypeAnnotation))
+
+				if strings.HasPrefix(event.Message, "NonCompliant;") {
+					g.Expect(event.Type).To(Equal("Warning"), "a NonCompliant event should have the Warning type")
+					g.Expect(event.Annotations[common.ComplianceStateAnnotation]).To(Equal("NonCompliant"))
+				} else if strings.HasPrefix(event.Message, "Compliant;") {
+					g.Expect(event.Type).To(Equal("Normal"), "a Compliant event should have the Normal type")
+					g.Expect(event.Annotations[common.ComplianceStateAnnotation]).To(Equal("Compliant"))
+				}
 			}
 		}
 
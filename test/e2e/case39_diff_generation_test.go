@@ -19,10 +19,38 @@ var _ = Describe("Generate the diff", Ordered, func() {
 	const (
 		logPath          string = "../../build/_output/controller.log"
 		configPolicyName string = "case39-policy-cfgmap-create"
+		informPolicyName string = "case39-policy-cfgmap-inform"
 		createYaml       string = "../resources/case39_diff_generation/case39-create-cfgmap-policy.yaml"
 		updateYaml       string = "../resources/case39_diff_generation/case39-update-cfgmap-policy.yaml"
+		informYaml       string = "../resources/case39_diff_generation/case39-inform-cfgmap-policy.yaml"
 	)
 
+	// loggedDiffs returns the diffs logged by the controller, each followed by its log context line
+	loggedDiffs := func() string {
+		logFile, err := os.Open(logPath)
+		Expect(err).ToNot(HaveOccurred())
+		defer logFile.Close()
+
+		diff := ""
+		foundDiff := false
+		logScanner := bufio.NewScanner(logFile)
+		logScanner.Split(bufio.ScanLines)
+		for logScanner.Scan() {
+			line := logScanner.Text()
+			if foundDiff && strings.HasPrefix(line, "\t{") {
+				foundDiff = false
+			} else if foundDiff || strings.Contains(line, "Logging the diff:") {
+				foundDiff = true
+			} else {
+				continue
+			}
+
+			diff += line + "\n"
+		}
+
+		return diff
+	}
+
 	BeforeAll(func() {
 		_, err := os.Stat(logPath)
 		if err != nil {
@@ -57,28 +85,7 @@ var _ = Describe("Generate the diff", Ordered, func() {
 
 	It("diff should be logged by the controller", func() {
 		By("Checking the controller logs")
-		logFile, err := os.Open(logPath)
-		Expect(err).ToNot(HaveOccurred())
-		defer logFile.Close()
-
-		diff := ""
-		foundDiff := false
-		logScanner := bufio.NewScanner(logFile)
-		logScanner.Split(bufio.ScanLines)
-		for logScanner.Scan() {
-			line := logScanner.Text()
-			if foundDiff && strings.HasPrefix(line, "\t{") {
-				foundDiff = false
-			} else if foundDiff || strings.Contains(line, "Logging the diff:") {
-				foundDiff = true
-			} else {
-				continue
-			}
-
-			diff += line + "\n"
-		}
-
-		Expect(diff).Should(ContainSubstring(`Logging the diff:
+		Expect(loggedDiffs()).Should(ContainSubstring(`Logging the diff:
 --- default/case39-map : existing
 +++ default/case39-map : updated
 @@ -2,3 +2,3 @@
@@ -89,8 +96,35 @@ var _ = Describe("Generate the diff", Ordered, func() {
 	{"policy": "case39-policy-cfgmap-create", "name": "case39-map", "namespace": "default", "resource": "configmaps"}`))
 	})
 
+	It("diff should be logged for an inform policy that doesn't update the configmap", func() {
+		By("Creating " + informPolicyName + " on managed")
+		utils.Kubectl("apply", "-f", informYaml, "-n", testNamespace)
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				informPolicyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, 30, 0.5).Should(Equal("NonCompliant"))
+
+		By("Checking the controller logs")
+		Eventually(loggedDiffs, 10, 1).Should(ContainSubstring(`Logging the diff:
+--- default/case39-map : existing
++++ default/case39-map : updated
+@@ -2,3 +2,3 @@
+ data:
+-  fieldToUpdate: "2"
++  fieldToUpdate: "3"
+ kind: ConfigMap
+	{"policy": "case39-policy-cfgmap-inform", "name": "case39-map", "namespace": "default", "resource": "configmaps"}`))
+
+		By("Verifying that the configmap wasn't updated")
+		configMap := utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			"case39-map", "default", true, defaultTimeoutSeconds)
+		Expect(configMap.Object["data"]).To(HaveKeyWithValue("fieldToUpdate", "2"))
+	})
+
 	AfterAll(func() {
-		deleteConfigPolicies([]string{configPolicyName})
+		deleteConfigPolicies([]string{configPolicyName, informPolicyName})
 		utils.Kubectl("delete", "configmap", "case39-map", "--ignore-not-found")
 	})
 })
@@ -0,0 +1,56 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test templates in the namespaceSelector", Ordered, func() {
+	const (
+		prereqYaml string = "../resources/case40_selector_templates/case40_prereq.yaml"
+		policyYaml string = "../resources/case40_selector_templates/case40_policy.yaml"
+		policyName string = "case40-selector-templates"
+	)
+
+	BeforeAll(func() {
+		By("Applying prerequisites")
+		utils.Kubectl("apply", "-f", prereqYaml)
+		DeferCleanup(func() {
+			utils.Kubectl("delete", "-f", prereqYaml, "--ignore-not-found")
+		})
+
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+		})
+	})
+
+	It("should select the namespaces from the ConfigMap", func() {
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetStatusMessage(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal(
+			"configmaps [case40-configmap] not found in namespaces: case40a-e2e, case40b-e2e",
+		))
+	})
+
+	It("should reevaluate when the ConfigMap changes", func() {
+		utils.Kubectl("patch", "configmap", "case40-namespaces", "-n", "default", "--type=json",
+			`--patch=[{"op":"replace","path":"/data/include","value":"case40b-e2e"}]`)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetStatusMessage(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal(
+			"configmaps [case40-configmap] not found in namespace case40b-e2e",
+		))
+	})
+})
@@ -0,0 +1,98 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the ServerSideApply enforcement method", Ordered, func() {
+	const (
+		prereqYaml    string = "../resources/case41_server_side_apply/case41_prereq.yaml"
+		policyYaml    string = "../resources/case41_server_side_apply/case41_policy.yaml"
+		policyName    string = "case41-server-side-apply"
+		configMapName string = "case41-configmap"
+	)
+
+	getConfigMapData := func() map[string]string {
+		configMap := utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			configMapName, "default", true, defaultTimeoutSeconds)
+
+		data, _, _ := unstructured.NestedStringMap(configMap.Object, "data")
+
+		return data
+	}
+
+	BeforeAll(func() {
+		By("Applying the ConfigMap with another field manager")
+		utils.Kubectl("apply", "--server-side", "--field-manager=case41-other", "-f", prereqYaml)
+		DeferCleanup(func() {
+			utils.Kubectl("delete", "-f", prereqYaml, "--ignore-not-found")
+		})
+
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+		})
+	})
+
+	It("should apply the objectDefinition and keep fields owned by other field managers", func() {
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		Expect(getConfigMapData()).To(Equal(map[string]string{"a": "1", "b": "2", "c": "3"}))
+	})
+
+	It("should be noncompliant when a field conflicts with another field manager", func() {
+		utils.Kubectl("patch", "configurationpolicy", policyName, "-n", testNamespace, "--type=json",
+			`--patch=[{"op":"replace","path":"/spec/object-templates/0/objectDefinition/data/a","value":"changed"}]`)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("NonCompliant"))
+
+		Expect(getConfigMapData()["a"]).To(Equal("1"))
+	})
+
+	It("should take ownership of the conflicting field when forceConflicts is set", func() {
+		utils.Kubectl("patch", "configurationpolicy", policyName, "-n", testNamespace, "--type=json",
+			`--patch=[{"op":"add","path":"/spec/forceConflicts","value":true}]`)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		Expect(getConfigMapData()["a"]).To(Equal("changed"))
+	})
+
+	It("should reject the mustonlyhave complianceType", func() {
+		utils.Kubectl("patch", "configurationpolicy", policyName, "-n", testNamespace, "--type=json",
+			`--patch=[{"op":"replace","path":"/spec/object-templates/0/complianceType","value":"mustonlyhave"}]`)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetStatusMessage(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal(
+			"object-templates[0]: the mustonlyhave complianceType isn't supported with the " +
+				"ServerSideApply enforcementMethod",
+		))
+
+		Expect(getConfigMapData()).To(HaveKeyWithValue("b", "2"))
+	})
+})
@@ -0,0 +1,93 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the deletion propagation policy of mustnothave object templates", Ordered, func() {
+	const (
+		parentYaml string = "../resources/case42_delete_options/case42_parent.yaml"
+		policyYaml string = "../resources/case42_delete_options/case42_policy.yaml"
+		policyName string = "case42-delete-options"
+		childName  string = "case42-child"
+		finalizer  string = "policy.open-cluster-management.io/case42-test"
+	)
+
+	BeforeAll(func() {
+		By("Creating the parent ConfigMap and a child ConfigMap that blocks its foreground deletion")
+		utils.Kubectl("apply", "-f", parentYaml)
+
+		parent := utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			"case42-parent", "default", true, defaultTimeoutSeconds)
+		blockOwnerDeletion := true
+
+		_, err := clientManaged.CoreV1().ConfigMaps("default").Create(context.TODO(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       childName,
+				Finalizers: []string{finalizer},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         "v1",
+					Kind:               "ConfigMap",
+					Name:               parent.GetName(),
+					UID:                parent.GetUID(),
+					BlockOwnerDeletion: &blockOwnerDeletion,
+				}},
+			},
+		}, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		DeferCleanup(func() {
+			utils.Kubectl("patch", "configmap", childName, "-n", "default", "--type=json",
+				`--patch=[{"op":"remove","path":"/metadata/finalizers"}]`)
+			utils.Kubectl("delete", "configmap", childName, "-n", "default", "--ignore-not-found")
+			utils.Kubectl("delete", "-f", parentYaml, "--ignore-not-found")
+		})
+
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+		})
+	})
+
+	It("should be noncompliant while the dependents are being deleted", func() {
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetStatusMessage(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal(
+			"configmaps [case42-parent] found but is terminating in namespace default",
+		))
+
+		Consistently(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, "10s", 1).Should(Equal("NonCompliant"))
+	})
+
+	It("should be compliant once the object is deleted", func() {
+		utils.Kubectl("patch", "configmap", childName, "-n", "default", "--type=json",
+			`--patch=[{"op":"remove","path":"/metadata/finalizers"}]`)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap, "case42-parent", "default", false,
+			defaultTimeoutSeconds)
+	})
+})
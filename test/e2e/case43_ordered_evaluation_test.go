@@ -0,0 +1,57 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the ordered evaluation of object templates", Ordered, func() {
+	const (
+		policyYaml string = "../resources/case43_ordered_evaluation/case43_policy.yaml"
+		policyName string = "case43-ordered-evaluation"
+	)
+
+	getSecondTemplateMessage := func() interface{} {
+		managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+			policyName, testNamespace, true, defaultTimeoutSeconds)
+
+		details, _, _ := unstructured.NestedSlice(managedPlc.Object, "status", "compliancyDetails")
+		if len(details) < 2 {
+			return nil
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(details[1].(map[string]interface{}), "conditions")
+		if len(conditions) == 0 {
+			return nil
+		}
+
+		return conditions[0].(map[string]interface{})["message"]
+	}
+
+	BeforeAll(func() {
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+			utils.Kubectl("delete", "namespace", "case43-e2e", "--ignore-not-found")
+		})
+	})
+
+	It("should skip the object templates after a noncompliant one", func() {
+		Eventually(getSecondTemplateMessage, defaultTimeoutSeconds, 1).Should(Equal(
+			"The object template was not evaluated because object-templates[0] is not compliant",
+		))
+	})
+
+	It("should evaluate the next object template once the earlier one is compliant", func() {
+		utils.Kubectl("create", "namespace", "case43-e2e")
+
+		Eventually(getSecondTemplateMessage, defaultTimeoutSeconds, 1).Should(Equal(
+			"configmaps [case43-configmap] not found in namespace case43-e2e",
+		))
+	})
+})
@@ -0,0 +1,74 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test object templates from a ConfigMap reference", Ordered, func() {
+	const (
+		sourceYaml string = "../resources/case44_object_templates_raw_ref/case44_source.yaml"
+		policyYaml string = "../resources/case44_object_templates_raw_ref/case44_policy.yaml"
+		policyName string = "case44-object-templates-raw-ref"
+	)
+
+	getStatusMessage := func() interface{} {
+		managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+			policyName, testNamespace, true, defaultTimeoutSeconds)
+
+		return utils.GetStatusMessage(managedPlc)
+	}
+
+	BeforeAll(func() {
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+			utils.Kubectl("delete", "-f", sourceYaml, "--ignore-not-found")
+		})
+	})
+
+	It("should report a missing reference", func() {
+		Eventually(getStatusMessage, defaultTimeoutSeconds, 1).Should(Equal(
+			`failed to get the ConfigMap default/case44-source: configmaps "case44-source" not found`,
+		))
+	})
+
+	It("should evaluate the object templates in the ConfigMap", func() {
+		utils.Kubectl("apply", "-f", sourceYaml)
+
+		Eventually(getStatusMessage, defaultTimeoutSeconds, 1).Should(Equal(
+			"configmaps [case44-target] not found in namespace default",
+		))
+
+		managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+			policyName, testNamespace, true, defaultTimeoutSeconds)
+		relatedObjects := managedPlc.Object["status"].(map[string]interface{})["relatedObjects"].([]interface{})
+
+		Expect(relatedObjects).To(ContainElement(HaveKeyWithValue("reason",
+			"Resource is the source of the object templates")))
+	})
+
+	It("should reevaluate when the ConfigMap changes", func() {
+		utils.Kubectl("patch", "configmap", "case44-source", "-n", "default", "--type=json",
+			`--patch=[{"op":"replace","path":"/data/object-templates","value":"- complianceType: musthave\n  `+
+				`objectDefinition:\n    apiVersion: v1\n    kind: ConfigMap\n    metadata:\n      `+
+				`name: case44-source\n      namespace: default\n"}]`)
+
+		Eventually(getStatusMessage, defaultTimeoutSeconds, 1).Should(Equal(
+			"configmaps [case44-source] found as specified in namespace default",
+		))
+	})
+
+	It("should report a missing key", func() {
+		utils.Kubectl("patch", "configurationpolicy", policyName, "-n", testNamespace, "--type=json",
+			`--patch=[{"op":"replace","path":"/spec/objectTemplatesRawRef/key","value":"missing"}]`)
+
+		Eventually(getStatusMessage, defaultTimeoutSeconds, 1).Should(Equal(
+			"the ConfigMap default/case44-source does not contain the key missing",
+		))
+	})
+})
@@ -0,0 +1,62 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test deleting the objects matching an objectSelector", Ordered, func() {
+	const (
+		prereqYaml string = "../resources/case45_object_selector_purge/case45_prereq.yaml"
+		policyYaml string = "../resources/case45_object_selector_purge/case45_policy.yaml"
+		policyName string = "case45-object-selector-purge"
+	)
+
+	getStatusMessage := func() interface{} {
+		managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+			policyName, testNamespace, true, defaultTimeoutSeconds)
+
+		return utils.GetStatusMessage(managedPlc)
+	}
+
+	BeforeAll(func() {
+		utils.Kubectl("apply", "-f", prereqYaml)
+		DeferCleanup(func() {
+			utils.Kubectl("delete", "-f", prereqYaml, "--ignore-not-found")
+		})
+
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+		})
+	})
+
+	It("should report the matching objects when informing", func() {
+		Eventually(getStatusMessage, defaultTimeoutSeconds, 1).Should(Equal(
+			"configmaps [case45-temp1, case45-temp2] found in namespace case45-e2e",
+		))
+	})
+
+	It("should delete only the matching objects when enforcing", func() {
+		utils.Kubectl("patch", "configurationpolicy", policyName, "-n", testNamespace, "--type=json",
+			`--patch=[{"op":"replace","path":"/spec/remediationAction","value":"enforce"}]`)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap, "case45-temp1", "case45-e2e", false,
+			defaultTimeoutSeconds)
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap, "case45-temp2", "case45-e2e", false,
+			defaultTimeoutSeconds)
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap, "case45-keep", "case45-e2e", true,
+			defaultTimeoutSeconds)
+	})
+})
@@ -0,0 +1,98 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the preview mode of an enforce policy", Ordered, func() {
+	const (
+		prereqYaml string = "../resources/case46_preview/case46_prereq.yaml"
+		policyYaml string = "../resources/case46_preview/case46_policy.yaml"
+		policyName string = "case46-preview"
+	)
+
+	BeforeAll(func() {
+		utils.Kubectl("apply", "-f", prereqYaml)
+		DeferCleanup(func() {
+			utils.Kubectl("delete", "-f", prereqYaml, "--ignore-not-found")
+			utils.Kubectl("delete", "configmap", "case46-missing", "-n", "default", "--ignore-not-found")
+		})
+
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+		})
+	})
+
+	It("should report the changes it would make without making them", func() {
+		var relatedObjects []interface{}
+
+		Eventually(func(g Gomega) {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			g.Expect(utils.GetComplianceState(managedPlc)).To(Equal("NonCompliant"))
+			g.Expect(utils.GetStatusMessage(managedPlc)).To(Equal(
+				"configmaps [case46-existing] found but not as specified and would be updated (preview) in " +
+					"namespace default",
+			))
+
+			relatedObjects, _, _ = unstructured.NestedSlice(managedPlc.Object, "status", "relatedObjects")
+			g.Expect(relatedObjects).To(HaveLen(2))
+		}, defaultTimeoutSeconds, 1).Should(Succeed())
+
+		actions := map[string]string{}
+
+		for _, related := range relatedObjects {
+			relatedObj := related.(map[string]interface{})
+			name, _, _ := unstructured.NestedString(relatedObj, "object", "metadata", "name")
+			action, _, _ := unstructured.NestedString(relatedObj, "properties", "previewAction")
+			actions[name] = action
+
+			if name == "case46-existing" {
+				diff, _, _ := unstructured.NestedString(relatedObj, "properties", "diff")
+				Expect(diff).To(ContainSubstring("+  city: Durham"))
+			}
+		}
+
+		Expect(actions).To(Equal(map[string]string{
+			"case46-existing": "would update",
+			"case46-missing":  "would create",
+		}))
+
+		By("Verifying that the objects were not changed")
+		existing := utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			"case46-existing", "default", true, defaultTimeoutSeconds)
+		city, _, _ := unstructured.NestedString(existing.Object, "data", "city")
+		Expect(city).To(Equal("Raleigh"))
+
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			"case46-missing", "default", false, defaultTimeoutSeconds)
+	})
+
+	It("should enforce the policy when the preview annotation is removed", func() {
+		utils.Kubectl("annotate", "configurationpolicy", policyName, "-n", testNamespace,
+			"policy.open-cluster-management.io/preview-")
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		existing := utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			"case46-existing", "default", true, defaultTimeoutSeconds)
+		city, _, _ := unstructured.NestedString(existing.Object, "data", "city")
+		Expect(city).To(Equal("Durham"))
+
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			"case46-missing", "default", true, defaultTimeoutSeconds)
+	})
+})
@@ -0,0 +1,66 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the dependencies between ConfigurationPolicies", Ordered, func() {
+	const (
+		policiesYaml   string = "../resources/case47_dependencies/case47_policies.yaml"
+		configMapYaml  string = "../resources/case47_dependencies/case47_configmap.yaml"
+		dependencyName string = "case47-dependency"
+		dependentName  string = "case47-dependent"
+	)
+
+	BeforeAll(func() {
+		utils.Kubectl("apply", "-f", policiesYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{dependencyName, dependentName})
+			utils.Kubectl("delete", "-f", configMapYaml, "--ignore-not-found")
+			utils.Kubectl("delete", "configmap", dependentName, "-n", "default", "--ignore-not-found")
+		})
+	})
+
+	It("should be Pending while the dependency is not compliant", func() {
+		Eventually(func(g Gomega) {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				dependentName, testNamespace, true, defaultTimeoutSeconds)
+
+			g.Expect(utils.GetComplianceState(managedPlc)).To(Equal("Pending"))
+			g.Expect(utils.GetStatusMessage(managedPlc)).To(Equal(
+				"Waiting for the dependencies: ConfigurationPolicy " + testNamespace + "/" + dependencyName +
+					" is NonCompliant, expected Compliant",
+			))
+		}, defaultTimeoutSeconds, 1).Should(Succeed())
+
+		Consistently(func() interface{} {
+			return utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap, dependentName, "default", false, 1)
+		}, "10s", 1).Should(BeNil())
+	})
+
+	It("should be evaluated once the dependency is compliant", func() {
+		utils.Kubectl("apply", "-f", configMapYaml)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				dependencyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				dependentName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			dependentName, "default", true, defaultTimeoutSeconds)
+	})
+})
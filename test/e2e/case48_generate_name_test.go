@@ -0,0 +1,82 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test an object template with a generateName", Ordered, func() {
+	const (
+		musthaveYaml    string = "../resources/case48_generate_name/case48_musthave.yaml"
+		musthaveName    string = "case48-musthave"
+		mustnothaveYaml string = "../resources/case48_generate_name/case48_mustnothave.yaml"
+		mustnothaveName string = "case48-mustnothave"
+	)
+
+	generatedNames := func() []string {
+		configMaps, err := clientManagedDynamic.Resource(gvrConfigMap).Namespace("default").List(
+			context.TODO(), metav1.ListOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		names := []string{}
+
+		for _, configMap := range configMaps.Items {
+			if strings.HasPrefix(configMap.GetName(), "case48-") {
+				names = append(names, configMap.GetName())
+			}
+		}
+
+		return names
+	}
+
+	AfterAll(func() {
+		deleteConfigPolicies([]string{musthaveName, mustnothaveName})
+
+		for _, name := range generatedNames() {
+			utils.Kubectl("delete", "configmap", name, "-n", "default", "--ignore-not-found")
+		}
+	})
+
+	It("should create the object once and track it", func() {
+		utils.Kubectl("apply", "-f", musthaveYaml, "-n", testNamespace)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				musthaveName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		Expect(generatedNames()).To(HaveLen(1))
+
+		// Later evaluations use the created object instead of creating another one
+		Consistently(generatedNames, "20s", 1).Should(HaveLen(1))
+
+		managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+			musthaveName, testNamespace, true, defaultTimeoutSeconds)
+		Expect(utils.GetComplianceState(managedPlc)).To(Equal("Compliant"))
+	})
+
+	It("should delete the objects with the name prefix", func() {
+		deleteConfigPolicies([]string{musthaveName})
+		utils.Kubectl("apply", "-f", mustnothaveYaml, "-n", testNamespace)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				mustnothaveName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		Expect(generatedNames()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,45 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test an objectDefinition of kind List", Ordered, func() {
+	const (
+		policyYaml string = "../resources/case49_list/case49_list.yaml"
+		policyName string = "case49-list"
+	)
+
+	BeforeAll(func() {
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+			utils.Kubectl("delete", "configmap", "case49-first", "case49-second", "-n", "default",
+				"--ignore-not-found")
+		})
+	})
+
+	It("should evaluate every item in the List", func() {
+		Eventually(func(g Gomega) {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			g.Expect(utils.GetComplianceState(managedPlc)).To(Equal("Compliant"))
+
+			details, _, _ := unstructured.NestedSlice(managedPlc.Object, "status", "compliancyDetails")
+			g.Expect(details).To(HaveLen(2))
+
+			relatedObjects, _, _ := unstructured.NestedSlice(managedPlc.Object, "status", "relatedObjects")
+			g.Expect(relatedObjects).To(HaveLen(2))
+		}, defaultTimeoutSeconds, 1).Should(Succeed())
+
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap, "case49-first", "default", true, defaultTimeoutSeconds)
+		utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap, "case49-second", "default", true, defaultTimeoutSeconds)
+	})
+})
@@ -0,0 +1,67 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the field manager of enforced changes", Ordered, func() {
+	const (
+		policyYaml string = "../resources/case50_field_manager/case50_policy.yaml"
+		policyName string = "case50-field-manager"
+	)
+
+	managers := func() []string {
+		configMap := utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+			policyName, "default", true, defaultTimeoutSeconds)
+
+		names := []string{}
+
+		for _, entry := range configMap.GetManagedFields() {
+			names = append(names, entry.Manager)
+		}
+
+		return names
+	}
+
+	BeforeAll(func() {
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+			utils.Kubectl("delete", "configmap", policyName, "-n", "default", "--ignore-not-found")
+		})
+	})
+
+	It("should create the object with the policy's field manager", func() {
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		Expect(managers()).To(ContainElement("config-policy-controller/" + policyName))
+	})
+
+	It("should update the object with the policy's field manager", func() {
+		utils.Kubectl("patch", "configmap", policyName, "-n", "default", "--field-manager=case50-test",
+			"--type=json", `-p=[{"op":"replace","path":"/data/city","value":"Durham"}]`)
+
+		Eventually(func() interface{} {
+			configMap := utils.GetWithTimeout(clientManagedDynamic, gvrConfigMap,
+				policyName, "default", true, defaultTimeoutSeconds)
+
+			city, _, _ := unstructured.NestedString(configMap.Object, "data", "city")
+
+			return city
+		}, defaultTimeoutSeconds, 1).Should(Equal("Raleigh"))
+
+		Expect(managers()).To(ContainElement("config-policy-controller/" + policyName))
+		Expect(managers()).ToNot(ContainElement("case50-test"))
+	})
+})
@@ -0,0 +1,71 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the template context of the object templates", Ordered, func() {
+	const (
+		prereqYaml         string = "../resources/case51_template_context/case51_prereq.yaml"
+		policyYaml         string = "../resources/case51_template_context/case51_policy.yaml"
+		policyName         string = "case51-template-context"
+		noNamespacesYaml   string = "../resources/case51_template_context/case51_policy_no_namespaces.yaml"
+		noNamespacesPolicy string = "case51-template-context-no-namespaces"
+	)
+
+	BeforeAll(func() {
+		By("Applying prerequisites")
+		utils.Kubectl("apply", "-f", prereqYaml)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName, noNamespacesPolicy})
+			utils.Kubectl("delete", "-f", prereqYaml, "--ignore-not-found")
+		})
+	})
+
+	It("should render the namespace of each created object in its labels", func() {
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		for _, namespace := range []string{"case51a-e2e", "case51b-e2e"} {
+			configMap, err := clientManaged.CoreV1().ConfigMaps(namespace).Get(
+				context.TODO(), "case51-configmap", metav1.GetOptions{},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configMap.Labels).To(Equal(map[string]string{
+				"policy":           policyName,
+				"object-namespace": namespace,
+			}))
+		}
+	})
+
+	It("should skip the object templates when no namespaces are selected", func() {
+		utils.Kubectl("apply", "-f", noNamespacesYaml, "-n", testNamespace)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				noNamespacesPolicy, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		configMaps, err := clientManaged.CoreV1().ConfigMaps("").List(context.TODO(), metav1.ListOptions{
+			FieldSelector: "metadata.name=case51-configmap-no-namespaces",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configMaps.Items).To(BeEmpty())
+	})
+})
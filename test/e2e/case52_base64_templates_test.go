@@ -0,0 +1,66 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	"context"
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the base64 template functions", Ordered, func() {
+	const (
+		prereqYaml string = "../resources/case52_base64_templates/case52_prereq.yaml"
+		policyYaml string = "../resources/case52_base64_templates/case52_policy.yaml"
+		policyName string = "case52-base64-templates"
+	)
+
+	BeforeAll(func() {
+		By("Applying prerequisites")
+		utils.Kubectl("apply", "-f", prereqYaml)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+			utils.Kubectl("delete", "-f", prereqYaml, "--ignore-not-found")
+		})
+	})
+
+	It("should encode the ConfigMap value and decode the Secret value", func() {
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		configMap, err := clientManaged.CoreV1().ConfigMaps("case52-e2e").Get(
+			context.TODO(), "case52-stamped", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configMap.Annotations).To(HaveKeyWithValue(
+			"config-stamp", base64.StdEncoding.EncodeToString([]byte("replicas: 2\nlogLevel: info\n")),
+		))
+		Expect(configMap.Data).To(Equal(map[string]string{"token": "case52-token"}))
+	})
+
+	It("should update the annotation when the referenced ConfigMap changes", func() {
+		utils.Kubectl("patch", "configmap", "case52-source", "-n", "case52-e2e", "--type=json",
+			`--patch=[{"op":"replace","path":"/data/config","value":"replicas: 3\nlogLevel: info\n"}]`)
+
+		Eventually(func(g Gomega) {
+			configMap, err := clientManaged.CoreV1().ConfigMaps("case52-e2e").Get(
+				context.TODO(), "case52-stamped", metav1.GetOptions{},
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(configMap.Annotations).To(HaveKeyWithValue(
+				"config-stamp", base64.StdEncoding.EncodeToString([]byte("replicas: 3\nlogLevel: info\n")),
+			))
+		}, defaultTimeoutSeconds, 1).Should(Succeed())
+	})
+})
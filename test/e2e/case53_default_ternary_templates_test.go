@@ -0,0 +1,55 @@
+// Copyright Contributors to the Open Cluster Management project
+
+package e2e
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"open-cluster-management.io/config-policy-controller/test/utils"
+)
+
+var _ = Describe("Test the default and ternary template functions", Ordered, func() {
+	const (
+		prereqYaml string = "../resources/case53_default_ternary_templates/case53_prereq.yaml"
+		policyYaml string = "../resources/case53_default_ternary_templates/case53_policy.yaml"
+		policyName string = "case53-default-ternary-templates"
+	)
+
+	BeforeAll(func() {
+		By("Applying prerequisites")
+		utils.Kubectl("apply", "-f", prereqYaml)
+		DeferCleanup(func() {
+			deleteConfigPolicies([]string{policyName})
+			utils.Kubectl("delete", "-f", prereqYaml, "--ignore-not-found")
+		})
+	})
+
+	It("should default the missing and empty ConfigMap values", func() {
+		utils.Kubectl("apply", "-f", policyYaml, "-n", testNamespace)
+
+		Eventually(func() interface{} {
+			managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+				policyName, testNamespace, true, defaultTimeoutSeconds)
+
+			return utils.GetComplianceState(managedPlc)
+		}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
+
+		configMap, err := clientManaged.CoreV1().ConfigMaps("case53-e2e").Get(
+			context.TODO(), "case53-settings", metav1.GetOptions{},
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		// fromConfigMap returns an empty string for a missing key, so default treats it like an empty value
+		Expect(configMap.Data).To(Equal(map[string]string{
+			"level":    "debug",
+			"missing":  "standard",
+			"empty":    "fallback",
+			"debug":    "enabled",
+			"replicas": "1",
+		}))
+	})
+})
@@ -22,6 +22,8 @@ const (
 	case8ConfigPolicyStatusPod       string = "policy-pod-invalid"
 	case8PolicyYamlBadPod            string = "../resources/case8_status_check/case8_pod_fail.yaml"
 	case8PolicyYamlSpecChange        string = "../resources/case8_status_check/case8_pod_change.yaml"
+	case8ConfigPolicyNamePodStatus   string = "policy-pod-status"
+	case8PolicyYamlPodStatus         string = "../resources/case8_status_check/case8_pod_status.yaml"
 )
 
 var _ = Describe("Test pod obj template handling", func() {
@@ -65,7 +67,7 @@ var _ = Describe("Test pod obj template handling", func() {
 				return utils.GetComplianceState(managedPlc)
 			}, defaultTimeoutSeconds, 1).Should(Equal("NonCompliant"))
 		})
-		It("should return nonCompliant if status does not match (enforce)", func() {
+		It("should return nonCompliant if the status is set with enforce", func() {
 			By("Creating " + case8ConfigPolicyNameEnforceFail + " on managed")
 			utils.Kubectl("apply", "-f", case8PolicyYamlEnforceFail, "-n", testNamespace)
 			plc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
@@ -77,6 +79,14 @@ var _ = Describe("Test pod obj template handling", func() {
 
 				return utils.GetComplianceState(managedPlc)
 			}, defaultTimeoutSeconds, 1).Should(Equal("NonCompliant"))
+			Eventually(func() interface{} {
+				managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
+					case8ConfigPolicyNameEnforceFail, testNamespace, true, defaultTimeoutSeconds)
+
+				return utils.GetStatusMessage(managedPlc)
+			}, defaultTimeoutSeconds, 1).Should(Equal(
+				"object-templates[0]: the status can only be compared with the inform remediationAction",
+			))
 		})
 		AfterAll(func() {
 			policies := []string{
@@ -101,7 +111,7 @@ var _ = Describe("Test pod obj template handling", func() {
 					case8ConfigPolicyStatusPod, testNamespace, true, defaultTimeoutSeconds)
 
 				return utils.GetComplianceState(managedPlc)
-			}, defaultTimeoutSeconds, 1).Should(Equal("NonCompliant"))
+			}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
 			Eventually(func() interface{} {
 				pod := utils.GetWithTimeout(clientManagedDynamic, gvrPod,
 					"nginx-badpod-e2e-8", "default", true, defaultTimeoutSeconds)
@@ -109,18 +119,19 @@ var _ = Describe("Test pod obj template handling", func() {
 				return pod.Object["status"].(map[string]interface{})["phase"]
 			}, defaultTimeoutSeconds, 1).Should(Equal("Pending"))
 		})
-		It("should be able to apply spec change and status does not interfere", func() {
-			By("Merging change to " + case8ConfigPolicyStatusPod + " on managed")
-			utils.Kubectl("apply", "-f", case8PolicyYamlSpecChange, "-n", testNamespace)
-			plc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
-				case8ConfigPolicyStatusPod, testNamespace, true, defaultTimeoutSeconds)
-			Expect(plc).NotTo(BeNil())
+		It("should return nonCompliant if the pod status does not match (inform)", func() {
+			By("Creating " + case8ConfigPolicyNamePodStatus + " on managed")
+			utils.Kubectl("apply", "-f", case8PolicyYamlPodStatus, "-n", testNamespace)
 			Eventually(func() interface{} {
 				managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
-					case8ConfigPolicyStatusPod, testNamespace, true, defaultTimeoutSeconds)
+					case8ConfigPolicyNamePodStatus, testNamespace, true, defaultTimeoutSeconds)
 
 				return utils.GetComplianceState(managedPlc)
 			}, defaultTimeoutSeconds, 1).Should(Equal("NonCompliant"))
+		})
+		It("should be able to apply spec change and the status is then compliant", func() {
+			By("Merging change to " + case8ConfigPolicyStatusPod + " on managed")
+			utils.Kubectl("apply", "-f", case8PolicyYamlSpecChange, "-n", testNamespace)
 			Eventually(func() interface{} {
 				pod := utils.GetWithTimeout(clientManagedDynamic, gvrPod,
 					"nginx-badpod-e2e-8", "default", true, defaultTimeoutSeconds)
@@ -135,7 +146,7 @@ var _ = Describe("Test pod obj template handling", func() {
 			}, defaultTimeoutSeconds, 1).Should(Equal("Failed"))
 			Eventually(func() interface{} {
 				managedPlc := utils.GetWithTimeout(clientManagedDynamic, gvrConfigPolicy,
-					case8ConfigPolicyStatusPod, testNamespace, true, defaultTimeoutSeconds)
+					case8ConfigPolicyNamePodStatus, testNamespace, true, defaultTimeoutSeconds)
 
 				return utils.GetComplianceState(managedPlc)
 			}, defaultTimeoutSeconds, 1).Should(Equal("Compliant"))
@@ -143,6 +154,7 @@ var _ = Describe("Test pod obj template handling", func() {
 		AfterAll(func() {
 			policies := []string{
 				case8ConfigPolicyStatusPod,
+				case8ConfigPolicyNamePodStatus,
 			}
 			deleteConfigPolicies(policies)
 
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +23,8 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+
+	"open-cluster-management.io/config-policy-controller/pkg/common"
 )
 
 // Pause sleep for given seconds
@@ -148,6 +151,20 @@ func GetMatchingEvents(
 	return matchingEvents
 }
 
+// FilterEventsByGeneration returns the compliance events emitted for the input generation of the policy, so that the
+// events of the previous generations don't match after the policy is updated.
+func FilterEventsByGeneration(events []corev1.Event, generation int64) []corev1.Event {
+	filtered := make([]corev1.Event, 0, len(events))
+
+	for _, event := range events {
+		if event.Annotations[common.PolicyGenerationAnnotation] == strconv.FormatInt(generation, 10) {
+			filtered = append(filtered, event)
+		}
+	}
+
+	return filtered
+}
+
 // Kubectl executes kubectl commands
 func Kubectl(args ...string) {
 	cmd := exec.Command("kubectl", args...)
@@ -181,6 +198,21 @@ func GetStatusMessage(managedPlc *unstructured.Unstructured) (result interface{}
 	return nil
 }
 
+// GetCondition returns the status condition of the input type of a ConfigurationPolicy or an OperatorPolicy, or nil
+// if the condition isn't set.
+func GetCondition(policy *unstructured.Unstructured, condType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(policy.Object, "status", "conditions")
+
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if ok && condition["type"] == condType {
+			return condition
+		}
+	}
+
+	return nil
+}
+
 // GetFieldFromSecret parses data field of secrets for the specified field
 func GetFieldFromSecret(secret *unstructured.Unstructured, field string) (result interface{}) {
 	if secret.Object["data"] != nil {